@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cleanupmanager
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides methods that the Juju client command uses to inspect
+// and manage the cleanups pending in the environments of a system.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new `Client` based on an existing authenticated API
+// connection.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "CleanupManager")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// ListCleanups returns the cleanups pending in all environments of
+// the system.
+func (c *Client) ListCleanups() ([]params.CleanupInfo, error) {
+	var result params.CleanupInfoList
+	if err := c.facade.FacadeCall("ListCleanups", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Cleanups, nil
+}
+
+// RetryCleanup immediately runs the cleanup with the given id in the
+// environment with the given UUID.
+func (c *Client) RetryCleanup(envUUID, id string) error {
+	return c.cleanupCall("RetryCleanups", envUUID, id)
+}
+
+// SkipCleanup removes the cleanup with the given id in the environment
+// with the given UUID, without running it.
+func (c *Client) SkipCleanup(envUUID, id string) error {
+	return c.cleanupCall("SkipCleanups", envUUID, id)
+}
+
+func (c *Client) cleanupCall(method, envUUID, id string) error {
+	args := params.CleanupIds{
+		Cleanups: []params.CleanupId{{
+			EnvironTag: names.NewEnvironTag(envUUID).String(),
+			Id:         id,
+		}},
+	}
+	var result params.ErrorResults
+	if err := c.facade.FacadeCall(method, args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cleanupmanager_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/cleanupmanager"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type cleanupManagerSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&cleanupManagerSuite{})
+
+func (s *cleanupManagerSuite) SetUpTest(c *gc.C) {
+	s.SetInitialFeatureFlags(feature.JES)
+	s.JujuConnSuite.SetUpTest(c)
+}
+
+func (s *cleanupManagerSuite) OpenAPI(c *gc.C) *cleanupmanager.Client {
+	conn, err := juju.NewAPIState(s.AdminUserTag(c), s.Environ, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) { conn.Close() })
+	return cleanupmanager.NewClient(conn)
+}
+
+func (s *cleanupManagerSuite) scheduleCleanup(c *gc.C) params.CleanupInfo {
+	unit := s.Factory.MakeUnit(c, nil)
+	service, err := unit.Service()
+	c.Assert(err, jc.ErrorIsNil)
+	err = service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	cleanups, err := s.OpenAPI(c).ListCleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cleanups, gc.HasLen, 1)
+	c.Assert(cleanups[0].EnvironTag, gc.Equals, s.State.EnvironTag().String())
+	c.Assert(cleanups[0].Kind, gc.Equals, "units")
+	c.Assert(cleanups[0].Prefix, gc.Equals, "mysql")
+	return cleanups[0]
+}
+
+func (s *cleanupManagerSuite) TestRetryCleanup(c *gc.C) {
+	cleanup := s.scheduleCleanup(c)
+	err := s.OpenAPI(c).RetryCleanup(s.State.EnvironUUID(), cleanup.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.OpenAPI(c).RetryCleanup(s.State.EnvironUUID(), cleanup.Id)
+	c.Assert(err, gc.ErrorMatches, `cleanup ".*" not found`)
+}
+
+func (s *cleanupManagerSuite) TestSkipCleanup(c *gc.C) {
+	cleanup := s.scheduleCleanup(c)
+	err := s.OpenAPI(c).SkipCleanup(s.State.EnvironUUID(), cleanup.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	cleanups, err := s.OpenAPI(c).ListCleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cleanups, gc.HasLen, 0)
+}
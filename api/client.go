@@ -612,6 +612,29 @@ func (c *Client) EnvironmentUserInfo() ([]params.EnvUserInfo, error) {
 	return info, nil
 }
 
+// WebhookDeliveries returns the recent deliveries of environment
+// events to webhooks, most recent first.
+func (c *Client) WebhookDeliveries() ([]params.WebhookDelivery, error) {
+	var results params.WebhookDeliveryResults
+	err := c.facade.FacadeCall("WebhookDeliveries", nil, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Deliveries, nil
+}
+
+// QuotaPreflight reports whether provisioning the new machines
+// described by args would exceed the environment's remaining
+// provider quotas.
+func (c *Client) QuotaPreflight(args params.QuotaPreflight) (params.QuotaPreflightResult, error) {
+	var result params.QuotaPreflightResult
+	err := c.facade.FacadeCall("QuotaPreflight", args, &result)
+	if err != nil {
+		return params.QuotaPreflightResult{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
 // UnshareEnvironment removes access to the environment for the given users.
 func (c *Client) UnshareEnvironment(users ...names.UserTag) error {
 	var args params.ModifyEnvironUsers
@@ -639,6 +662,44 @@ func (c *Client) UnshareEnvironment(users ...names.UserTag) error {
 	return result.Combine()
 }
 
+// ShareEnvironmentWithGroups allows the members of the given groups
+// access to the environment.
+func (c *Client) ShareEnvironmentWithGroups(groups ...string) error {
+	return c.modifyEnvironGroups(params.AddEnvUser, groups, params.CodeAlreadyExists,
+		"environment is already shared with group %s")
+}
+
+// UnshareEnvironmentWithGroups removes the access given to the members
+// of the given groups by ShareEnvironmentWithGroups.
+func (c *Client) UnshareEnvironmentWithGroups(groups ...string) error {
+	return c.modifyEnvironGroups(params.RemoveEnvUser, groups, params.CodeNotFound,
+		"environment was not previously shared with group %s")
+}
+
+func (c *Client) modifyEnvironGroups(action params.EnvironAction, groups []string, ignoreCode, warning string) error {
+	var args params.ModifyEnvironUsers
+	for _, group := range groups {
+		args.Changes = append(args.Changes, params.ModifyEnvironUser{
+			Group:  group,
+			Action: action,
+		})
+	}
+
+	var result params.ErrorResults
+	err := c.facade.FacadeCall("ShareEnvironment", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	for i, r := range result.Results {
+		if r.Error != nil && r.Error.Code == ignoreCode {
+			logger.Warningf(warning, groups[i])
+			result.Results[i].Error = nil
+		}
+	}
+	return result.Combine()
+}
+
 // WatchAll holds the id of the newly-created AllWatcher.
 type WatchAll struct {
 	AllWatcherId string
@@ -654,6 +715,18 @@ func (c *Client) WatchAll() (*AllWatcher, error) {
 	return newAllWatcher(c.st, &info.AllWatcherId), nil
 }
 
+// WatchAllFiltered returns an AllWatcher, from which you can request
+// the Next collection of Deltas for the entities matching the given
+// filter. Entities that stop matching the filter are reported as
+// removed.
+func (c *Client) WatchAllFiltered(filter params.AllWatcherFilter) (*AllWatcher, error) {
+	info := new(WatchAll)
+	if err := c.facade.FacadeCall("WatchAllFiltered", filter, info); err != nil {
+		return nil, err
+	}
+	return newAllWatcher(c.st, &info.AllWatcherId), nil
+}
+
 // GetAnnotations returns annotations that have been set on the given entity.
 // This API is now deprecated - "Annotations" client should be used instead.
 // TODO(anastasiamac) remove for Juju 2.x
@@ -759,9 +832,24 @@ func (c *Client) DestroyEnvironment() error {
 // charm URL. If the API server does not support charm uploads, an
 // error satisfying params.IsCodeNotImplemented() is returned.
 func (c *Client) AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, error) {
+	return c.AddLocalCharmWithSignature(curl, ch, "")
+}
+
+// AddLocalCharmWithSignature is like AddLocalCharm except it also
+// uploads the given armored detached signature of the charm archive,
+// for the API server to verify against the trusted charm keys of the
+// environment. A signature can only be given for a charm archive.
+func (c *Client) AddLocalCharmWithSignature(curl *charm.URL, ch charm.Charm, signature string) (*charm.URL, error) {
 	if curl.Schema != "local" {
 		return nil, errors.Errorf("expected charm URL with local: schema, got %q", curl.String())
 	}
+	query := url.Values{"series": {curl.Series}}
+	if signature != "" {
+		if _, ok := ch.(*charm.CharmArchive); !ok {
+			return nil, errors.Errorf("cannot sign charm of type %T, only charm archives can be signed", ch)
+		}
+		query.Set("signature", signature)
+	}
 	// Package the charm for uploading.
 	var archive *os.File
 	switch ch := ch.(type) {
@@ -788,7 +876,7 @@ func (c *Client) AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, err
 		return nil, errors.Errorf("unknown charm type %T", ch)
 	}
 
-	endPoint, err := c.apiEndpoint("charms", "series="+curl.Series)
+	endPoint, err := c.apiEndpoint("charms", query.Encode())
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -854,6 +942,45 @@ func (n *noopCloser) Close() error {
 	return nil
 }
 
+// AttachResource uploads the given content as a new revision of the
+// named resource of a service, and returns the new revision.
+func (c *Client) AttachResource(service, name string, content io.Reader) (int, error) {
+	query := url.Values{"service": {service}, "name": {name}}.Encode()
+	endPoint, err := c.apiEndpoint("resources", query)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	// As with charms, prevent the transport from closing the
+	// caller's reader.
+	req, err := http.NewRequest("POST", endPoint, ioutil.NopCloser(content))
+	if err != nil {
+		return 0, errors.Annotate(err, "cannot create upload request")
+	}
+	req.SetBasicAuth(c.st.tag, c.st.password)
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	// See the comment in AddLocalCharm about the non-validating client.
+	resp, err := utils.GetNonValidatingHTTPClient().Do(req)
+	if err != nil {
+		return 0, errors.Annotate(err, "cannot upload resource")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.Annotate(err, "cannot read resource upload response")
+	}
+	var jsonResponse params.ResourcesResponse
+	if err := json.Unmarshal(body, &jsonResponse); err != nil {
+		return 0, errors.Errorf("resource upload failed: %v (%s)", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if jsonResponse.Error != "" {
+		return 0, errors.Errorf("error uploading resource: %v", jsonResponse.Error)
+	}
+	return jsonResponse.Revision, nil
+}
+
 func (c *Client) apiEndpoint(destination, query string) (string, error) {
 	root, err := c.apiRoot()
 	if err != nil {
@@ -918,6 +1045,19 @@ func (c *Client) AddCharmWithAuthorization(curl *charm.URL, csMac *macaroon.Maca
 	return c.facade.FacadeCall("AddCharmWithAuthorization", args, nil)
 }
 
+// AddCharmWithSignature is like AddCharmWithAuthorization except it
+// also provides the given armored detached signature of the charm
+// archive, for the juju server to verify against the trusted charm
+// keys of the environment. The macaroon may be nil.
+func (c *Client) AddCharmWithSignature(curl *charm.URL, csMac *macaroon.Macaroon, signature string) error {
+	args := params.AddCharmWithAuthorization{
+		URL:                curl.String(),
+		CharmStoreMacaroon: csMac,
+		Signature:          signature,
+	}
+	return c.facade.FacadeCall("AddCharmWithAuthorization", args, nil)
+}
+
 // ResolveCharm resolves the best available charm URLs with series, for charm
 // locations without a series specified.
 func (c *Client) ResolveCharm(ref *charm.Reference) (*charm.URL, error) {
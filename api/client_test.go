@@ -13,6 +13,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"strings"
 
@@ -26,6 +27,9 @@ import (
 
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/charmsig"
+	charmsigtesting "github.com/juju/juju/charmsig/testing"
+	"github.com/juju/juju/constraints"
 	jujunames "github.com/juju/juju/juju/names"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
@@ -116,6 +120,59 @@ func (s *clientSuite) TestAddLocalCharm(c *gc.C) {
 	c.Assert(savedURL.String(), gc.Equals, curl.WithRevision(43).String())
 }
 
+func (s *clientSuite) TestAddLocalCharmWithSignature(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"trusted-charm-keys": key.Public}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	charmArchive := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	curl := charm.MustParseURL("local:quantal/dummy-1")
+	f, err := os.Open(charmArchive.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	signature, err := charmsig.Sign(f, key.Private, "")
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.APIState.Client()
+
+	// Only archives can be signed.
+	charmDir := testcharms.Repo.ClonedDir(c.MkDir(), "dummy")
+	_, err = client.AddLocalCharmWithSignature(curl, charmDir, signature)
+	c.Assert(err, gc.ErrorMatches, `cannot sign charm of type \*charm.CharmDir, only charm archives can be signed`)
+
+	_, err = client.AddLocalCharm(curl, charmArchive)
+	c.Assert(err, gc.ErrorMatches, `charm upload failed: 400 .*charm is not signed.*`)
+
+	savedURL, err := client.AddLocalCharmWithSignature(curl, charmArchive, signature)
+	c.Assert(err, jc.ErrorIsNil)
+	sch, err := s.State.Charm(savedURL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.SignedBy(), gc.Equals, key.Fingerprint)
+}
+
+func (s *clientSuite) TestAttachResource(c *gc.C) {
+	s.AddTestingService(c, "site", s.AddTestingCharm(c, "resources"))
+	client := s.APIState.Client()
+
+	revision, err := client.AttachResource("site", "site", strings.NewReader("hello"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revision, gc.Equals, 1)
+	revision, err = client.AttachResource("site", "site", strings.NewReader("goodbye"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revision, gc.Equals, 2)
+
+	resource, err := s.State.Resource("site", "site")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resource.Revision(), gc.Equals, 2)
+	c.Assert(resource.Size(), gc.Equals, int64(7))
+}
+
+func (s *clientSuite) TestAttachResourceUndeclared(c *gc.C) {
+	s.AddTestingService(c, "site", s.AddTestingCharm(c, "resources"))
+	client := s.APIState.Client()
+
+	_, err := client.AttachResource("site", "tools", strings.NewReader("hello"))
+	c.Assert(err, gc.ErrorMatches, `error uploading resource: resource "tools" in charm "local:quantal/resources-1" not found`)
+}
+
 func (s *clientSuite) TestAddLocalCharmOtherEnvironment(c *gc.C) {
 	charmArchive := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
 	curl := charm.MustParseURL(
@@ -227,6 +284,64 @@ func (s *clientSuite) TestClientEnvironmentUsers(c *gc.C) {
 	})
 }
 
+func (s *clientSuite) TestWebhookDeliveries(c *gc.C) {
+	client := s.APIState.Client()
+	cleanup := api.PatchClientFacadeCall(client,
+		func(request string, paramsIn interface{}, response interface{}) error {
+			c.Assert(request, gc.Equals, "WebhookDeliveries")
+			c.Assert(paramsIn, gc.IsNil)
+			if response, ok := response.(*params.WebhookDeliveryResults); ok {
+				response.Deliveries = []params.WebhookDelivery{
+					{Event: "unit-error", Delivered: true},
+					{Event: "machine-error"},
+				}
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	obtained, err := client.WebhookDeliveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(obtained, jc.DeepEquals, []params.WebhookDelivery{
+		{Event: "unit-error", Delivered: true},
+		{Event: "machine-error"},
+	})
+}
+
+func (s *clientSuite) TestQuotaPreflight(c *gc.C) {
+	client := s.APIState.Client()
+	args := params.QuotaPreflight{
+		Machines:    3,
+		ServiceName: "wordpress",
+		Constraints: constraints.MustParse("cpu-cores=2"),
+		Volumes:     3,
+	}
+	cleanup := api.PatchClientFacadeCall(client,
+		func(request string, paramsIn interface{}, response interface{}) error {
+			c.Assert(request, gc.Equals, "QuotaPreflight")
+			c.Assert(paramsIn, jc.DeepEquals, args)
+			if response, ok := response.(*params.QuotaPreflightResult); ok {
+				response.Exceeded = []string{"3 instances requested, 2 remaining in quota"}
+				response.Refuse = true
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := client.QuotaPreflight(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.QuotaPreflightResult{
+		Exceeded: []string{"3 instances requested, 2 remaining in quota"},
+		Refuse:   true,
+	})
+}
+
 func (s *clientSuite) TestShareEnvironmentExistingUser(c *gc.C) {
 	client := s.APIState.Client()
 	user := s.Factory.MakeEnvUser(c, nil)
@@ -310,6 +425,52 @@ func (s *clientSuite) TestShareEnvironmentThreeUsers(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `existing user`)
 }
 
+func (s *clientSuite) TestShareEnvironmentWithGroups(c *gc.C) {
+	client := s.APIState.Client()
+	cleanup := api.PatchClientFacadeCall(client,
+		func(request string, paramsIn interface{}, response interface{}) error {
+			c.Assert(request, gc.Equals, "ShareEnvironment")
+			c.Assert(paramsIn, jc.DeepEquals, params.ModifyEnvironUsers{
+				Changes: []params.ModifyEnvironUser{{
+					Group:  "ops",
+					Action: params.AddEnvUser,
+				}, {
+					Group:  "dev",
+					Action: params.AddEnvUser,
+				}},
+			})
+			result := response.(*params.ErrorResults)
+			*result = params.ErrorResults{Results: []params.ErrorResult{
+				{Error: &params.Error{Message: "already shared", Code: params.CodeAlreadyExists}},
+				{Error: &params.Error{Message: "no such group"}},
+			}}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	err := client.ShareEnvironmentWithGroups("ops", "dev")
+	c.Assert(err, gc.ErrorMatches, "no such group")
+}
+
+func (s *clientSuite) TestUnshareEnvironmentWithGroups(c *gc.C) {
+	_, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.APIState.Client()
+	err = client.ShareEnvironmentWithGroups("ops")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = client.UnshareEnvironmentWithGroups("ops")
+	c.Assert(err, jc.ErrorIsNil)
+	// Unsharing again is not an error.
+	err = client.UnshareEnvironmentWithGroups("ops")
+	c.Assert(err, jc.ErrorIsNil)
+
+	groups, err := s.State.EnvironmentGroups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(groups, gc.HasLen, 0)
+}
+
 func (s *clientSuite) TestUnshareEnvironmentThreeUsers(c *gc.C) {
 	client := s.APIState.Client()
 	missingUser := s.Factory.MakeEnvUser(c, nil)
@@ -95,3 +95,146 @@ func (c *Client) ListEnvironments(user string) ([]base.UserEnvironment, error) {
 	}
 	return result, nil
 }
+
+// ExportEnvironment returns the serialized model description of the
+// environment with the given UUID.
+func (c *Client) ExportEnvironment(envUUID string) ([]byte, error) {
+	var results params.SerializedModelResults
+	if !names.IsValidEnvironment(envUUID) {
+		return nil, errors.Errorf("invalid environment UUID %q", envUUID)
+	}
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewEnvironTag(envUUID).String()}},
+	}
+	err := c.facade.FacadeCall("ExportEnvironments", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Results[0].Model, nil
+}
+
+// ImportEnvironment creates a new environment from the serialized model
+// description. The account and config values override those in the
+// description.
+func (c *Client) ImportEnvironment(owner string, account, config map[string]interface{}, model []byte) (params.Environment, error) {
+	return c.importEnvironment(owner, params.EnvironmentImportArgs{
+		Account: account,
+		Config:  config,
+		Model:   model,
+	})
+}
+
+// MigrateEnvironment recreates an environment that is being migrated
+// from another system. The environment keeps its UUID and the
+// identities of its machines and units, so that its agents can connect
+// to this system once they are redirected.
+func (c *Client) MigrateEnvironment(owner string, account, config map[string]interface{}, env params.SerializedEnvironment) (params.Environment, error) {
+	return c.importEnvironment(owner, params.EnvironmentImportArgs{
+		Account:   account,
+		Config:    config,
+		Model:     env.Model,
+		Charms:    env.Charms,
+		Migration: true,
+	})
+}
+
+func (c *Client) importEnvironment(owner string, importArgs params.EnvironmentImportArgs) (params.Environment, error) {
+	var result params.Environment
+	if !names.IsValidUser(owner) {
+		return result, errors.Errorf("invalid owner name %q", owner)
+	}
+	importArgs.OwnerTag = names.NewUserTag(owner).String()
+	err := c.facade.FacadeCall("ImportEnvironment", importArgs, &result)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	logger.Infof("imported environment %s (%s)", result.Name, result.UUID)
+	return result, nil
+}
+
+// MissingAgents returns the tags of the agents of the environment with
+// the given UUID that should be running but are not connected to the
+// system.
+func (c *Client) MissingAgents(envUUID string) ([]string, error) {
+	if !names.IsValidEnvironment(envUUID) {
+		return nil, errors.Errorf("invalid environment UUID %q", envUUID)
+	}
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewEnvironTag(envUUID).String()}},
+	}
+	var results params.StringsResults
+	err := c.facade.FacadeCall("MissingAgents", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Results[0].Result, nil
+}
+
+// RemoveImportedEnvironment removes an environment that was imported
+// by a migration that has since been aborted.
+func (c *Client) RemoveImportedEnvironment(envUUID string) error {
+	if !names.IsValidEnvironment(envUUID) {
+		return errors.Errorf("invalid environment UUID %q", envUUID)
+	}
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewEnvironTag(envUUID).String()}},
+	}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("RemoveImportedEnvironments", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// ActivateImportedEnvironment activates an environment that was
+// imported by a migration that has since succeeded, so that its
+// workers are run.
+func (c *Client) ActivateImportedEnvironment(envUUID string) error {
+	if !names.IsValidEnvironment(envUUID) {
+		return errors.Errorf("invalid environment UUID %q", envUUID)
+	}
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewEnvironTag(envUUID).String()}},
+	}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("ActivateImportedEnvironments", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// ReturnImportedAgents sends the agents of an environment that was
+// imported by a migration that has since been aborted back to the
+// system with the given API addresses and CA certificate.
+func (c *Client) ReturnImportedAgents(envUUID string, addrs []string, caCert string) error {
+	if !names.IsValidEnvironment(envUUID) {
+		return errors.Errorf("invalid environment UUID %q", envUUID)
+	}
+	args := params.ReturnImportedAgentsArgs{
+		Environments: []params.ReturnImportedAgentsArg{{
+			EnvironTag: names.NewEnvironTag(envUUID).String(),
+			Addrs:      addrs,
+			CACert:     caCert,
+		}},
+	}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("ReturnImportedAgents", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
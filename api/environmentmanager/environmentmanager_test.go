@@ -117,3 +117,58 @@ func (s *environmentmanagerSuite) TestListEnvironments(c *gc.C) {
 	ownerNames := []string{envs[0].Owner, envs[1].Owner}
 	c.Assert(ownerNames, jc.DeepEquals, []string{"user@remote", "user@remote"})
 }
+
+func (s *environmentmanagerSuite) TestExportEnvironmentBadUUID(c *gc.C) {
+	envManager := s.OpenAPI(c)
+	_, err := envManager.ExportEnvironment("not a uuid")
+	c.Assert(err, gc.ErrorMatches, `invalid environment UUID "not a uuid"`)
+}
+
+func (s *environmentmanagerSuite) TestExportImportEnvironment(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	s.Factory.MakeMachine(c, nil)
+	envManager := s.OpenAPI(c)
+	model, err := envManager.ExportEnvironment(s.State.EnvironUUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(model), jc.Contains, s.State.EnvironUUID())
+
+	user := s.Factory.MakeUser(c, nil)
+	owner := user.UserTag().Username()
+	newEnv, err := envManager.ImportEnvironment(owner, nil, map[string]interface{}{
+		"name":         "imported",
+		"state-server": false,
+	}, model)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(newEnv.Name, gc.Equals, "imported")
+	c.Assert(newEnv.OwnerTag, gc.Equals, user.Tag().String())
+	c.Assert(newEnv.UUID, gc.Not(gc.Equals), s.State.EnvironUUID())
+}
+
+func (s *environmentmanagerSuite) TestMissingAgents(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	machine := s.Factory.MakeMachine(c, nil)
+	envManager := s.OpenAPI(c)
+	missing, err := envManager.MissingAgents(s.State.EnvironUUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(missing, jc.DeepEquals, []string{machine.Tag().String()})
+}
+
+func (s *environmentmanagerSuite) TestReturnImportedAgentsNotImported(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	envManager := s.OpenAPI(c)
+	err := envManager.ReturnImportedAgents(s.State.EnvironUUID(), []string{"10.0.0.1:17070"}, "cert")
+	c.Assert(err, gc.ErrorMatches, `environment ".*" was not imported by a migration`)
+}
+
+func (s *environmentmanagerSuite) TestActivateImportedEnvironmentNotImported(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	envManager := s.OpenAPI(c)
+	err := envManager.ActivateImportedEnvironment(s.State.EnvironUUID())
+	c.Assert(err, gc.ErrorMatches, `environment ".*" was not imported by a migration`)
+}
+
+func (s *environmentmanagerSuite) TestImportEnvironmentBadUser(c *gc.C) {
+	envManager := s.OpenAPI(c)
+	_, err := envManager.ImportEnvironment("not a user", nil, nil, nil)
+	c.Assert(err, gc.ErrorMatches, `invalid owner name "not a user"`)
+}
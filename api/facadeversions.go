@@ -21,6 +21,7 @@ var facadeVersions = map[string]int{
 	"CharmRevisionUpdater":         0,
 	"Client":                       0,
 	"Cleaner":                      1,
+	"CleanupManager":               1,
 	"Deployer":                     0,
 	"DiskManager":                  1,
 	"EntityWatcher":                1,
@@ -28,16 +29,19 @@ var facadeVersions = map[string]int{
 	"EnvironmentManager":           1,
 	"FilesystemAttachmentsWatcher": 1,
 	"Firewaller":                   1,
-	"HighAvailability":             1,
+	"GarbageCollector":             1,
+	"HighAvailability":             2,
 	"ImageManager":                 1,
 	"InstancePoller":               1,
 	"KeyManager":                   0,
 	"KeyUpdater":                   0,
 	"LeadershipService":            1,
 	"Logger":                       0,
+	"MachineActions":               1,
 	"MachineManager":               1,
 	"Machiner":                     0,
 	"MetricsManager":               0,
+	"MigrationMaster":              1,
 	"Networker":                    0,
 	"NotifyWatcher":                0,
 	"Pinger":                       0,
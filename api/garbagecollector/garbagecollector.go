@@ -0,0 +1,47 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package garbagecollector
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides methods that the Juju client command uses to find
+// and remove the charm archives and tools no longer used by any
+// environment of a system.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new `Client` based on an existing authenticated API
+// connection.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "GarbageCollector")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// ListGarbage returns the charm archives and tools that are not used
+// by any environment of the system.
+func (c *Client) ListGarbage() ([]params.GarbageInfo, error) {
+	return c.garbageCall("ListGarbage")
+}
+
+// CollectGarbage removes the charm archives and tools that are not
+// used by any environment of the system, and returns them. Items that
+// could not be removed have their Error field set.
+func (c *Client) CollectGarbage() ([]params.GarbageInfo, error) {
+	return c.garbageCall("CollectGarbage")
+}
+
+func (c *Client) garbageCall(method string) ([]params.GarbageInfo, error) {
+	var result params.GarbageList
+	if err := c.facade.FacadeCall(method, nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Garbage, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package garbagecollector_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/garbagecollector"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type garbageCollectorSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&garbageCollectorSuite{})
+
+func (s *garbageCollectorSuite) SetUpTest(c *gc.C) {
+	s.SetInitialFeatureFlags(feature.JES)
+	s.JujuConnSuite.SetUpTest(c)
+}
+
+func (s *garbageCollectorSuite) OpenAPI(c *gc.C) *garbagecollector.Client {
+	conn, err := juju.NewAPIState(s.AdminUserTag(c), s.Environ, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) { conn.Close() })
+	return garbagecollector.NewClient(conn)
+}
+
+// charmGarbage returns the charms in the supplied list, ignoring the
+// tools uploaded by the test suite.
+func charmGarbage(garbage []params.GarbageInfo) []params.GarbageInfo {
+	var result []params.GarbageInfo
+	for _, info := range garbage {
+		if info.Kind == "charm" {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
+// addOldCharm adds a charm that was uploaded long enough ago to be
+// collected.
+func (s *garbageCollectorSuite) addOldCharm(c *gc.C) *state.Charm {
+	ch := s.addOldCharm(c)
+	charms := s.State.MongoSession().DB("juju").C("charms")
+	id := s.State.EnvironUUID() + ":" + ch.URL().String()
+	err := charms.UpdateId(id, bson.D{{"$set", bson.D{{"uploadedat", time.Now().Add(-48 * time.Hour)}}}})
+	c.Assert(err, jc.ErrorIsNil)
+	return ch
+}
+
+func (s *garbageCollectorSuite) TestListGarbage(c *gc.C) {
+	ch := s.addOldCharm(c)
+	garbage, err := s.OpenAPI(c).ListGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	garbage = charmGarbage(garbage)
+	c.Assert(garbage, gc.HasLen, 1)
+	c.Assert(garbage[0].Id, gc.Equals, ch.URL().String())
+	c.Assert(garbage[0].EnvironTag, gc.Equals, s.State.EnvironTag().String())
+	c.Assert(garbage[0].EnvironName, gc.Equals, "dummyenv")
+
+	_, err = s.State.Charm(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *garbageCollectorSuite) TestCollectGarbage(c *gc.C) {
+	ch := s.AddTestingCharm(c, "dummy")
+	garbage, err := s.OpenAPI(c).CollectGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	garbage = charmGarbage(garbage)
+	c.Assert(garbage, gc.HasLen, 1)
+	c.Assert(garbage[0].Id, gc.Equals, ch.URL().String())
+	c.Assert(garbage[0].Error, gc.IsNil)
+
+	_, err = s.State.Charm(ch.URL())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
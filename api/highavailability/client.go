@@ -71,3 +71,36 @@ func (c *Client) EnsureAvailability(
 	}
 	return result.Result, nil
 }
+
+// RemoveStateServer removes the state server running on the machine
+// with the given id, providing a replacement if replace is true. The
+// removal happens in stages: once the machine has left the replica
+// set, RemoveStateServer must be called again to finish it.
+func (c *Client) RemoveStateServer(
+	machineId string, replace bool, cons constraints.Value, series string, placement []string,
+) (params.StateServersChanges, error) {
+	if c.facade.BestAPIVersion() < 2 {
+		return params.StateServersChanges{}, errors.NotSupportedf("removing state servers with this version of Juju")
+	}
+	var results params.StateServersChangeResults
+	arg := params.RemoveStateServerSpecs{
+		Specs: []params.RemoveStateServerSpec{{
+			EnvironTag:  c.environTag.String(),
+			MachineTag:  names.NewMachineTag(machineId).String(),
+			Replace:     replace,
+			Constraints: cons,
+			Series:      series,
+			Placement:   placement,
+		}}}
+	if err := c.facade.FacadeCall("RemoveStateServer", arg, &results); err != nil {
+		return params.StateServersChanges{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.StateServersChanges{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.StateServersChanges{}, result.Error
+	}
+	return result.Result, nil
+}
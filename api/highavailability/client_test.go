@@ -78,7 +78,21 @@ func (s *clientSuite) TestClientEnsureAvailability(c *gc.C) {
 
 func (s *clientSuite) TestClientEnsureAvailabilityVersion(c *gc.C) {
 	client := highavailability.NewClient(s.APIState)
-	c.Assert(client.BestAPIVersion(), gc.Equals, 1)
+	c.Assert(client.BestAPIVersion(), gc.Equals, 2)
+}
+
+func (s *clientSuite) TestClientRemoveStateServer(c *gc.C) {
+	assertEnsureAvailability(c, &s.JujuConnSuite)
+	for _, id := range []string{"0", "1", "2"} {
+		pinger := setAgentPresence(c, &s.JujuConnSuite, id)
+		defer assertKill(c, pinger)
+	}
+
+	client := highavailability.NewClient(s.APIState)
+	result, err := client.RemoveStateServer("2", true, constraints.Value{}, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Demoted, gc.DeepEquals, []string{"machine-2"})
+	c.Assert(result.Added, gc.DeepEquals, []string{"machine-3"})
 }
 
 type clientLegacySuite struct {
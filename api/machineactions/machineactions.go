@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package machineactions provides the client side of the API facade
+// through which machine agents run the actions queued for them.
+package machineactions
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+const machineActionsFacade = "MachineActions"
+
+// Action represents a single action queued for a machine, by name and
+// params.
+type Action struct {
+	name   string
+	params map[string]interface{}
+}
+
+// NewAction makes a new Action with the given name and params.
+func NewAction(name string, params map[string]interface{}) *Action {
+	return &Action{name: name, params: params}
+}
+
+// Name returns the name of the action.
+func (a *Action) Name() string {
+	return a.name
+}
+
+// Params returns the params map of the action.
+func (a *Action) Params() map[string]interface{} {
+	return a.params
+}
+
+// State provides access to the MachineActions API facade for a
+// single machine.
+type State struct {
+	facade     base.FacadeCaller
+	machineTag names.MachineTag
+}
+
+// NewState returns a new State for the given machine.
+func NewState(caller base.APICaller, machineTag names.MachineTag) *State {
+	return &State{
+		facade:     base.NewFacadeCaller(caller, machineActionsFacade),
+		machineTag: machineTag,
+	}
+}
+
+// WatchActionNotifications returns a StringsWatcher that notifies of
+// the ids of the actions queued for the machine.
+func (st *State) WatchActionNotifications() (watcher.StringsWatcher, error) {
+	var results params.StringsWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: st.machineTag.String()}},
+	}
+	if err := st.facade.FacadeCall("WatchActionNotifications", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewStringsWatcher(st.facade.RawAPICaller(), result), nil
+}
+
+// Action returns the pending action with the given tag.
+func (st *State) Action(tag names.ActionTag) (*Action, error) {
+	var results params.ActionsQueryResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: tag.String()}},
+	}
+	if err := st.facade.FacadeCall("Actions", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &Action{
+		name:   result.Action.Action.Name,
+		params: result.Action.Action.Parameters,
+	}, nil
+}
+
+// ActionBegin marks an action as running.
+func (st *State) ActionBegin(tag names.ActionTag) error {
+	var results params.ErrorResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: tag.String()}},
+	}
+	if err := st.facade.FacadeCall("BeginActions", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// ActionFinish records the outcome of an action.
+func (st *State) ActionFinish(tag names.ActionTag, status string, results map[string]interface{}, message string) error {
+	var outcome params.ErrorResults
+	args := params.ActionExecutionResults{
+		Results: []params.ActionExecutionResult{{
+			ActionTag: tag.String(),
+			Status:    status,
+			Results:   results,
+			Message:   message,
+		}},
+	}
+	if err := st.facade.FacadeCall("FinishActions", args, &outcome); err != nil {
+		return errors.Trace(err)
+	}
+	return outcome.OneError()
+}
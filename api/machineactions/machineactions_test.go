@@ -0,0 +1,108 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machineactions_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apitesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/machineactions"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type MachineActionsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&MachineActionsSuite{})
+
+var actionTag = names.NewActionTag("c5ec8ad4-7bb5-4a2a-8e49-4e3b09bbd2a9")
+
+func (s *MachineActionsSuite) TestAction(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MachineActions")
+		c.Check(request, gc.Equals, "Actions")
+		c.Check(arg, jc.DeepEquals, params.Entities{
+			Entities: []params.Entity{{Tag: actionTag.String()}},
+		})
+		*(result.(*params.ActionsQueryResults)) = params.ActionsQueryResults{
+			Results: []params.ActionsQueryResult{{
+				Action: params.ActionResult{
+					Action: &params.Action{
+						Name:       "juju-run",
+						Parameters: map[string]interface{}{"command": "hostname"},
+					},
+				},
+			}},
+		}
+		return nil
+	})
+	st := machineactions.NewState(apiCaller, names.NewMachineTag("0"))
+	action, err := st.Action(actionTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(action.Name(), gc.Equals, "juju-run")
+	c.Assert(action.Params(), jc.DeepEquals, map[string]interface{}{"command": "hostname"})
+}
+
+func (s *MachineActionsSuite) TestActionError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		*(result.(*params.ActionsQueryResults)) = params.ActionsQueryResults{
+			Results: []params.ActionsQueryResult{{
+				Error: &params.Error{Message: "action no longer available"},
+			}},
+		}
+		return nil
+	})
+	st := machineactions.NewState(apiCaller, names.NewMachineTag("0"))
+	_, err := st.Action(actionTag)
+	c.Assert(err, gc.ErrorMatches, "action no longer available")
+}
+
+func (s *MachineActionsSuite) TestActionBegin(c *gc.C) {
+	var called bool
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MachineActions")
+		c.Check(request, gc.Equals, "BeginActions")
+		c.Check(arg, jc.DeepEquals, params.Entities{
+			Entities: []params.Entity{{Tag: actionTag.String()}},
+		})
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{}},
+		}
+		called = true
+		return nil
+	})
+	st := machineactions.NewState(apiCaller, names.NewMachineTag("0"))
+	err := st.ActionBegin(actionTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *MachineActionsSuite) TestActionFinish(c *gc.C) {
+	var called bool
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MachineActions")
+		c.Check(request, gc.Equals, "FinishActions")
+		c.Check(arg, jc.DeepEquals, params.ActionExecutionResults{
+			Results: []params.ActionExecutionResult{{
+				ActionTag: actionTag.String(),
+				Status:    params.ActionFailed,
+				Results:   map[string]interface{}{"Code": 1},
+				Message:   "oops",
+			}},
+		})
+		*(result.(*params.ErrorResults)) = params.ErrorResults{
+			Results: []params.ErrorResult{{}},
+		}
+		called = true
+		return nil
+	})
+	st := machineactions.NewState(apiCaller, names.NewMachineTag("0"))
+	err := st.ActionFinish(actionTag, params.ActionFailed, map[string]interface{}{"Code": 1}, "oops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
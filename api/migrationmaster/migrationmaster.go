@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+const migrationMasterFacade = "MigrationMaster"
+
+// TargetInfo holds the details required to connect to and
+// authenticate with the system that an environment is migrated to.
+type TargetInfo struct {
+	Addrs    []string
+	CACert   string
+	AuthTag  names.UserTag
+	Password string
+}
+
+// SourceInfo holds the details the environment's agents need to
+// connect to the system it is being migrated from.
+type SourceInfo struct {
+	Addrs  []string
+	CACert string
+}
+
+// MigrationStatus holds the details of an environment migration.
+type MigrationStatus struct {
+	Phase         string
+	StatusMessage string
+	TargetInfo    TargetInfo
+}
+
+// API provides access to the MigrationMaster API facade.
+type API struct {
+	facade base.FacadeCaller
+}
+
+// NewAPI creates a new client-side MigrationMaster facade.
+func NewAPI(caller base.APICaller) *API {
+	facadeCaller := base.NewFacadeCaller(caller, migrationMasterFacade)
+	return &API{facade: facadeCaller}
+}
+
+// Watch calls the server-side Watch method.
+func (api *API) Watch() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := api.facade.FacadeCall("Watch", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Error; err != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewNotifyWatcher(api.facade.RawAPICaller(), result)
+	return w, nil
+}
+
+// GetMigrationStatus returns the details of the environment's
+// migration.
+func (api *API) GetMigrationStatus() (MigrationStatus, error) {
+	var result params.MigrationStatus
+	err := api.facade.FacadeCall("GetMigrationStatus", nil, &result)
+	if err != nil {
+		return MigrationStatus{}, errors.Trace(err)
+	}
+	authTag, err := names.ParseUserTag(result.TargetInfo.AuthTag)
+	if err != nil {
+		return MigrationStatus{}, errors.Trace(err)
+	}
+	return MigrationStatus{
+		Phase:         result.Phase,
+		StatusMessage: result.StatusMessage,
+		TargetInfo: TargetInfo{
+			Addrs:    result.TargetInfo.Addrs,
+			CACert:   result.TargetInfo.CACert,
+			AuthTag:  authTag,
+			Password: result.TargetInfo.Password,
+		},
+	}, nil
+}
+
+// SetPhase moves the environment's migration to the given phase.
+func (api *API) SetPhase(phase string) error {
+	args := params.SetMigrationPhaseArgs{Phase: phase}
+	return api.facade.FacadeCall("SetPhase", args, nil)
+}
+
+// SetStatusMessage records the progress of the environment's
+// migration.
+func (api *API) SetStatusMessage(message string) error {
+	args := params.SetMigrationStatusMessageArgs{Message: message}
+	return api.facade.FacadeCall("SetStatusMessage", args, nil)
+}
+
+// Export returns the environment's serialized model and the archives
+// of the charms it uses.
+func (api *API) Export() (params.SerializedEnvironment, error) {
+	var result params.SerializedEnvironment
+	err := api.facade.FacadeCall("Export", nil, &result)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// SourceInfo returns the API addresses and CA certificate of the
+// system the environment is being migrated from.
+func (api *API) SourceInfo() (SourceInfo, error) {
+	var result params.MigrationSourceInfo
+	err := api.facade.FacadeCall("SourceInfo", nil, &result)
+	if err != nil {
+		return SourceInfo{}, errors.Trace(err)
+	}
+	return SourceInfo{
+		Addrs:  result.Addrs,
+		CACert: result.CACert,
+	}, nil
+}
+
+// MissingAgents returns the tags of the environment's agents that are
+// not connected to the system it is being migrated from.
+func (api *API) MissingAgents() ([]string, error) {
+	var result params.StringsResult
+	err := api.facade.FacadeCall("MissingAgents", nil, &result)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return nil, errors.Trace(result.Error)
+	}
+	return result.Result, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apitesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/migrationmaster"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type MigrationMasterSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&MigrationMasterSuite{})
+
+func (s *MigrationMasterSuite) TestGetMigrationStatus(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MigrationMaster")
+		c.Check(request, gc.Equals, "GetMigrationStatus")
+		c.Check(arg, gc.IsNil)
+		*(result.(*params.MigrationStatus)) = params.MigrationStatus{
+			Phase:         "IMPORT",
+			StatusMessage: "importing",
+			TargetInfo: params.MigrationTargetInfo{
+				Addrs:    []string{"10.0.0.1:17070"},
+				CACert:   "cert",
+				AuthTag:  "user-admin",
+				Password: "secret",
+			},
+		}
+		return nil
+	})
+	status, err := migrationmaster.NewAPI(apiCaller).GetMigrationStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, migrationmaster.MigrationStatus{
+		Phase:         "IMPORT",
+		StatusMessage: "importing",
+		TargetInfo: migrationmaster.TargetInfo{
+			Addrs:    []string{"10.0.0.1:17070"},
+			CACert:   "cert",
+			AuthTag:  names.NewUserTag("admin"),
+			Password: "secret",
+		},
+	})
+}
+
+func (s *MigrationMasterSuite) TestSetPhase(c *gc.C) {
+	var called bool
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MigrationMaster")
+		c.Check(request, gc.Equals, "SetPhase")
+		c.Check(arg, jc.DeepEquals, params.SetMigrationPhaseArgs{Phase: "ABORT"})
+		called = true
+		return nil
+	})
+	err := migrationmaster.NewAPI(apiCaller).SetPhase("ABORT")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *MigrationMasterSuite) TestSetStatusMessage(c *gc.C) {
+	var called bool
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MigrationMaster")
+		c.Check(request, gc.Equals, "SetStatusMessage")
+		c.Check(arg, jc.DeepEquals, params.SetMigrationStatusMessageArgs{Message: "exporting"})
+		called = true
+		return nil
+	})
+	err := migrationmaster.NewAPI(apiCaller).SetStatusMessage("exporting")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *MigrationMasterSuite) TestExport(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MigrationMaster")
+		c.Check(request, gc.Equals, "Export")
+		*(result.(*params.SerializedEnvironment)) = params.SerializedEnvironment{
+			Model:  []byte("model"),
+			Charms: []params.SerializedCharm{{URL: "cs:quantal/mysql-1", Archive: []byte("archive")}},
+		}
+		return nil
+	})
+	env, err := migrationmaster.NewAPI(apiCaller).Export()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(env.Model), gc.Equals, "model")
+	c.Assert(env.Charms, gc.HasLen, 1)
+}
+
+func (s *MigrationMasterSuite) TestSourceInfo(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MigrationMaster")
+		c.Check(request, gc.Equals, "SourceInfo")
+		*(result.(*params.MigrationSourceInfo)) = params.MigrationSourceInfo{
+			Addrs:  []string{"10.0.0.5:17070"},
+			CACert: "cert",
+		}
+		return nil
+	})
+	info, err := migrationmaster.NewAPI(apiCaller).SourceInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info, jc.DeepEquals, migrationmaster.SourceInfo{
+		Addrs:  []string{"10.0.0.5:17070"},
+		CACert: "cert",
+	})
+}
+
+func (s *MigrationMasterSuite) TestMissingAgents(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "MigrationMaster")
+		c.Check(request, gc.Equals, "MissingAgents")
+		*(result.(*params.StringsResult)) = params.StringsResult{
+			Result: []string{"machine-1"},
+		}
+		return nil
+	})
+	missing, err := migrationmaster.NewAPI(apiCaller).MissingAgents()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(missing, jc.DeepEquals, []string{"machine-1"})
+}
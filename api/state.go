@@ -21,7 +21,9 @@ import (
 	"github.com/juju/juju/api/instancepoller"
 	"github.com/juju/juju/api/keyupdater"
 	apilogger "github.com/juju/juju/api/logger"
+	"github.com/juju/juju/api/machineactions"
 	"github.com/juju/juju/api/machiner"
+	"github.com/juju/juju/api/migrationmaster"
 	"github.com/juju/juju/api/networker"
 	"github.com/juju/juju/api/provisioner"
 	"github.com/juju/juju/api/reboot"
@@ -315,6 +317,16 @@ func (st *State) Reboot() (*reboot.State, error) {
 	}
 }
 
+// MachineActions returns access to the MachineActions API.
+func (st *State) MachineActions() (*machineactions.State, error) {
+	switch tag := st.authTag.(type) {
+	case names.MachineTag:
+		return machineactions.NewState(st, tag), nil
+	default:
+		return nil, errors.Errorf("expected names.MachineTag, got %T", tag)
+	}
+}
+
 // Deployer returns access to the Deployer API
 func (st *State) Deployer() *deployer.State {
 	return deployer.NewState(st)
@@ -350,6 +362,11 @@ func (st *State) Cleaner() *cleaner.API {
 	return cleaner.NewAPI(st)
 }
 
+// MigrationMaster returns access to the MigrationMaster API.
+func (st *State) MigrationMaster() *migrationmaster.API {
+	return migrationmaster.NewAPI(st)
+}
+
 // Rsyslog returns access to the Rsyslog API
 func (st *State) Rsyslog() *rsyslog.State {
 	return rsyslog.NewState(st)
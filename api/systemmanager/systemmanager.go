@@ -86,3 +86,37 @@ func (c *Client) RemoveBlocks() error {
 	args := params.RemoveBlocksArgs{All: true}
 	return c.facade.FacadeCall("RemoveBlocks", args, nil)
 }
+
+// MigrationTargetInfo holds the details required to connect to and
+// authenticate with the system that an environment is migrated to.
+type MigrationTargetInfo struct {
+	Addrs    []string
+	CACert   string
+	AuthTag  names.UserTag
+	Password string
+}
+
+// InitiateEnvironmentMigration starts the migration of the specified
+// environment to the system described by target.
+func (c *Client) InitiateEnvironmentMigration(envUUID string, target MigrationTargetInfo) error {
+	if !names.IsValidEnvironment(envUUID) {
+		return errors.Errorf("invalid environment UUID %q", envUUID)
+	}
+	args := params.InitiateEnvironmentMigrationArgs{
+		Specs: []params.EnvironmentMigrationSpec{{
+			EnvironTag: names.NewEnvironTag(envUUID).String(),
+			TargetInfo: params.MigrationTargetInfo{
+				Addrs:    target.Addrs,
+				CACert:   target.CACert,
+				AuthTag:  target.AuthTag.String(),
+				Password: target.Password,
+			},
+		}},
+	}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("InitiateEnvironmentMigration", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
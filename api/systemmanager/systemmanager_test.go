@@ -112,3 +112,27 @@ func (s *systemManagerSuite) TestRemoveBlocks(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(blocks, gc.HasLen, 0)
 }
+
+func (s *systemManagerSuite) TestInitiateEnvironmentMigration(c *gc.C) {
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+
+	sysManager := s.OpenAPI(c)
+	err := sysManager.InitiateEnvironmentMigration(st.EnvironUUID(), systemmanager.MigrationTargetInfo{
+		Addrs:    []string{"10.0.0.1:17070"},
+		CACert:   s.State.CACert(),
+		AuthTag:  names.NewUserTag("admin"),
+		Password: "secret",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	migration, err := st.EnvMigration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.Phase(), gc.Equals, state.MigrationExport)
+}
+
+func (s *systemManagerSuite) TestInitiateEnvironmentMigrationInvalidUUID(c *gc.C) {
+	sysManager := s.OpenAPI(c)
+	err := sysManager.InitiateEnvironmentMigration("not-a-uuid", systemmanager.MigrationTargetInfo{})
+	c.Assert(err, gc.ErrorMatches, `invalid environment UUID "not-a-uuid"`)
+}
@@ -0,0 +1,133 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	apiserverhttp "github.com/juju/juju/apiserver/http"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// httpClient represents the methods of api.State (see api/http.go)
+// needed by the uniter for direct HTTP requests.
+type httpClient interface {
+	// NewHTTPRequest returns a new API-supporting HTTP request.
+	NewHTTPRequest(method, path string) (*http.Request, error)
+	// NewHTTPClient returns an HTTP client suitable for sending
+	// requests made by NewHTTPRequest.
+	NewHTTPClient() *http.Client
+}
+
+// resourcesVersion requests the resources version of the given unit
+// or service from the server.
+func (st *State) resourcesVersion(tag names.Tag) (int, error) {
+	if st.facade.BestAPIVersion() < 2 {
+		return 0, errors.NotImplementedf("ResourcesVersion")
+	}
+	var results params.IntResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: tag.String()}},
+	}
+	err := st.facade.FacadeCall("ResourcesVersion", args, &results)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return 0, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.Result, nil
+}
+
+// ResourcesVersion returns a number that is incremented every time a
+// resource attached to the service changes.
+func (s *Service) ResourcesVersion() (int, error) {
+	return s.st.resourcesVersion(s.tag)
+}
+
+// ResourcesVersion returns the version of the service's resources that
+// the unit last reacted to.
+func (u *Unit) ResourcesVersion() (int, error) {
+	return u.st.resourcesVersion(u.tag)
+}
+
+// SetResourcesVersion records that the unit has reacted to the given
+// version of the service's resources.
+func (u *Unit) SetResourcesVersion(version int) error {
+	if u.st.facade.BestAPIVersion() < 2 {
+		return errors.NotImplementedf("SetResourcesVersion")
+	}
+	var result params.ErrorResults
+	args := params.EntitiesResourcesVersion{
+		Entities: []params.EntityResourcesVersion{
+			{Tag: u.tag.String(), Version: version},
+		},
+	}
+	err := u.st.facade.FacadeCall("SetResourcesVersion", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// DownloadResource returns a reader for the content of the named
+// resource attached to the unit's service, along with the hex-encoded
+// SHA-256 hash of that content. If the content's hash matches
+// currentSHA256, no content is transferred and a nil reader is
+// returned. The caller is responsible for closing the reader.
+func (st *State) DownloadResource(name, currentSHA256 string) (io.ReadCloser, string, error) {
+	if st.http == nil {
+		return nil, "", errors.NotSupportedf("resource download")
+	}
+	serviceName, err := names.UnitService(st.unitTag.Id())
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	req, err := st.http.NewHTTPRequest("GET", "resources")
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	req.URL.RawQuery = url.Values{"service": {serviceName}, "name": {name}}.Encode()
+	if currentSHA256 != "" {
+		req.Header.Set("If-None-Match", fmt.Sprintf("%q", currentSHA256))
+	}
+	resp, err := st.http.NewHTTPClient().Do(req)
+	if err != nil {
+		return nil, "", errors.Annotatef(err, "cannot download resource %q", name)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		digest := resp.Header.Get("Digest")
+		prefix := string(apiserverhttp.DigestSHA) + "="
+		if !strings.HasPrefix(digest, prefix) {
+			resp.Body.Close()
+			return nil, "", errors.Errorf("cannot download resource %q: unexpected digest %q", name, digest)
+		}
+		return resp.Body, strings.TrimPrefix(digest, prefix), nil
+	case http.StatusNotModified:
+		resp.Body.Close()
+		return nil, currentSHA256, nil
+	}
+	defer resp.Body.Close()
+	var result params.ResourcesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", errors.Annotatef(err, "cannot download resource %q: %s", name, resp.Status)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", errors.NewNotFound(nil, result.Error)
+	}
+	return nil, "", errors.Errorf("cannot download resource %q: %s", name, result.Error)
+}
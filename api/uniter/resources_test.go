@@ -0,0 +1,93 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/uniter"
+)
+
+type resourcesSuite struct {
+	uniterSuite
+
+	apiService *uniter.Service
+	apiUnit    *uniter.Unit
+}
+
+var _ = gc.Suite(&resourcesSuite{})
+
+// sha256 of "hello"
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func (s *resourcesSuite) SetUpTest(c *gc.C) {
+	s.uniterSuite.SetUpTest(c)
+
+	var err error
+	s.apiService, err = s.uniter.Service(s.wordpressService.Tag().(names.ServiceTag))
+	c.Assert(err, jc.ErrorIsNil)
+	s.apiUnit, err = s.uniter.Unit(s.wordpressUnit.Tag().(names.UnitTag))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *resourcesSuite) setResource(c *gc.C, content, sha256 string) {
+	_, err := s.State.SetResource(
+		"wordpress", "site", strings.NewReader(content), int64(len(content)), sha256, s.AdminUserTag(c),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *resourcesSuite) TestResourcesVersion(c *gc.C) {
+	s.setResource(c, "hello", helloSHA256)
+
+	version, err := s.apiService.ResourcesVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, 1)
+
+	version, err = s.apiUnit.ResourcesVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, 0)
+}
+
+func (s *resourcesSuite) TestSetResourcesVersion(c *gc.C) {
+	err := s.apiUnit.SetResourcesVersion(5)
+	c.Assert(err, jc.ErrorIsNil)
+
+	version, err := s.apiUnit.ResourcesVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, 5)
+}
+
+func (s *resourcesSuite) TestDownloadResource(c *gc.C) {
+	s.setResource(c, "hello", helloSHA256)
+
+	reader, sha256, err := s.uniter.DownloadResource("site", "")
+	c.Assert(err, jc.ErrorIsNil)
+	defer reader.Close()
+	c.Assert(sha256, gc.Equals, helloSHA256)
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "hello")
+}
+
+func (s *resourcesSuite) TestDownloadResourceNotModified(c *gc.C) {
+	s.setResource(c, "hello", helloSHA256)
+
+	reader, sha256, err := s.uniter.DownloadResource("site", helloSHA256)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(reader, gc.IsNil)
+	c.Assert(sha256, gc.Equals, helloSHA256)
+}
+
+func (s *resourcesSuite) TestDownloadResourceNotFound(c *gc.C) {
+	_, _, err := s.uniter.DownloadResource("site", "")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `resource "site" of service "wordpress" not found`)
+}
@@ -27,6 +27,7 @@ type State struct {
 
 	LeadershipSettings *LeadershipSettingsAccessor
 	facade             base.FacadeCaller
+	http               httpClient
 	// unitTag contains the authenticated unit's tag.
 	unitTag names.UnitTag
 }
@@ -50,6 +51,9 @@ func newStateForVersion(
 		facade:          facadeCaller,
 		unitTag:         authTag,
 	}
+	if http, ok := caller.(httpClient); ok {
+		state.http = http
+	}
 
 	if version >= 2 {
 		newWatcher := func(result params.NotifyWatchResult) watcher.NotifyWatcher {
@@ -6,6 +6,7 @@ package usermanager
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -89,6 +90,12 @@ func (c *Client) EnableUser(username string) error {
 	return c.userCall(username, "EnableUser")
 }
 
+// UnlockUser ends the lockout of a user after too many failed logins.
+// If the user is not locked out, the action is considered a success.
+func (c *Client) UnlockUser(username string) error {
+	return c.userCall(username, "UnlockUser")
+}
+
 // IncludeDisabled is a type alias to avoid bare true/false values
 // in calls to the client method.
 type IncludeDisabled bool
@@ -166,3 +173,86 @@ func (c *Client) SetPassword(username, password string) error {
 	}
 	return results.OneError()
 }
+
+// AddGroup creates a new user group with no members.
+func (c *Client) AddGroup(name string) error {
+	if !names.IsValidUserName(name) {
+		return errors.Errorf("%q is not a valid group name", name)
+	}
+	args := params.AddGroups{
+		Groups: []params.AddGroup{{Name: name}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("AddGroup", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// AddGroupMembers adds the given users to a user group.
+func (c *Client) AddGroupMembers(group string, users ...names.UserTag) error {
+	return c.groupMembersCall("AddGroupMembers", group, users)
+}
+
+// RemoveGroupMembers removes the given users from a user group.
+func (c *Client) RemoveGroupMembers(group string, users ...names.UserTag) error {
+	return c.groupMembersCall("RemoveGroupMembers", group, users)
+}
+
+func (c *Client) groupMembersCall(methodCall, group string, users []names.UserTag) error {
+	args := params.GroupMembers{
+		Members: make([]params.GroupMember, len(users)),
+	}
+	for i, user := range users {
+		args.Members[i] = params.GroupMember{
+			Group:   group,
+			UserTag: user.String(),
+		}
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall(methodCall, args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.Combine()
+}
+
+// AddAPIToken creates an API token that lets the logged in user log in
+// to the environment of the API connection, without their password,
+// until it expires.
+func (c *Client) AddAPIToken(expiresIn time.Duration) (params.AddAPITokenResult, error) {
+	args := params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{ExpiresIn: expiresIn}},
+	}
+	var results params.AddAPITokenResults
+	if err := c.facade.FacadeCall("AddAPIToken", args, &results); err != nil {
+		return params.AddAPITokenResult{}, errors.Trace(err)
+	}
+	if count := len(results.Results); count != 1 {
+		return params.AddAPITokenResult{}, errors.Errorf("expected 1 result, got %d", count)
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.AddAPITokenResult{}, errors.Trace(result.Error)
+	}
+	return result, nil
+}
+
+// APITokens returns information about the API tokens of the logged in
+// user.
+func (c *Client) APITokens() ([]params.APITokenInfo, error) {
+	var results params.APITokenInfoResults
+	if err := c.facade.FacadeCall("APITokens", nil, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Results, nil
+}
+
+// RevokeAPITokens revokes the API tokens with the given ids.
+func (c *Client) RevokeAPITokens(ids ...string) error {
+	args := params.APITokenIds{Ids: ids}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("RevokeAPITokens", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.Combine()
+}
@@ -4,13 +4,17 @@
 package usermanager_test
 
 import (
+	"time"
+
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/api/usermanager"
 	"github.com/juju/juju/apiserver/params"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing/factory"
 )
 
@@ -97,6 +101,19 @@ func (s *usermanagerSuite) TestEnableUser(c *gc.C) {
 	c.Assert(user.IsDisabled(), jc.IsFalse)
 }
 
+func (s *usermanagerSuite) TestUnlockUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar"})
+	err := user.RecordFailedLogin(state.LoginPolicy{FailureLimit: 1, Lockout: time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.usermanager.UnlockUser(user.Name())
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+}
+
 func (s *usermanagerSuite) TestEnableUserBadName(c *gc.C) {
 	err := s.usermanager.EnableUser("not@home")
 	c.Assert(err, gc.ErrorMatches, `"not@home" is not a valid username`)
@@ -177,3 +194,65 @@ func (s *usermanagerSuite) TestSetUserPasswordBadName(c *gc.C) {
 	err := s.usermanager.SetPassword("not@home", "new-password")
 	c.Assert(err, gc.ErrorMatches, `"not@home" is not a valid username`)
 }
+
+func (s *usermanagerSuite) TestAddGroup(c *gc.C) {
+	err := s.usermanager.AddGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+
+	group, err := s.State.UserGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Members(), gc.HasLen, 0)
+
+	err = s.usermanager.AddGroup("ops")
+	c.Assert(err, gc.ErrorMatches, `failed to create group: group "ops" already exists`)
+}
+
+func (s *usermanagerSuite) TestAddGroupBadName(c *gc.C) {
+	err := s.usermanager.AddGroup("not valid")
+	c.Assert(err, gc.ErrorMatches, `"not valid" is not a valid group name`)
+}
+
+func (s *usermanagerSuite) TestGroupMembers(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	remote := names.NewUserTag("fred@remote")
+	err := s.usermanager.AddGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.usermanager.AddGroupMembers("ops", alex.UserTag(), remote)
+	c.Assert(err, jc.ErrorIsNil)
+	group, err := s.State.UserGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Members(), jc.DeepEquals, []names.UserTag{alex.UserTag(), remote})
+
+	err = s.usermanager.RemoveGroupMembers("ops", alex.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = group.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Members(), jc.DeepEquals, []names.UserTag{remote})
+}
+
+func (s *usermanagerSuite) TestAPITokens(c *gc.C) {
+	result, err := s.usermanager.AddAPIToken(time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Credential, gc.Not(gc.Equals), "")
+
+	tokens, err := s.usermanager.APITokens()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 1)
+	c.Assert(tokens[0].Id, gc.Equals, result.Id)
+	c.Assert(tokens[0].EnvironUUID, gc.Equals, s.State.EnvironUUID())
+
+	err = s.usermanager.RevokeAPITokens(result.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	tokens, err = s.usermanager.APITokens()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 0)
+
+	err = s.usermanager.RevokeAPITokens(result.Id)
+	c.Assert(err, gc.ErrorMatches, `API token ".*" not found`)
+}
+
+func (s *usermanagerSuite) TestAddAPITokenBadExpiry(c *gc.C) {
+	_, err := s.usermanager.AddAPIToken(-time.Hour)
+	c.Assert(err, gc.ErrorMatches, "token expiry must be positive")
+}
@@ -239,6 +239,14 @@ func checkCreds(st *state.State, req params.LoginRequest, lookForEnvUser bool) (
 	if err != nil {
 		return nil, nil, err
 	}
+	if userTag, ok := tag.(names.UserTag); ok {
+		if id, secret, ok := state.ParseAPIToken(req.Credentials); ok {
+			return checkAPITokenCreds(st, userTag, id, secret, lookForEnvUser)
+		}
+		if !userTag.IsLocal() {
+			return checkExternalCreds(st, userTag, req.Credentials, lookForEnvUser)
+		}
+	}
 	entity, err := st.FindEntity(tag)
 	if errors.IsNotFound(err) {
 		// We return the same error when an entity does not exist as for a bad
@@ -256,27 +264,42 @@ func checkCreds(st *state.State, req params.LoginRequest, lookForEnvUser bool) (
 		return nil, nil, err
 	}
 
+	user, isUser := entity.(*state.User)
+	if isUser && user.IsLockedOut() {
+		// Locked out users are refused without checking their
+		// password, so that it cannot be guessed during the lockout.
+		logger.Debugf("user %q is locked out until %v", user.Name(), user.LockedUntil())
+		return nil, nil, common.ErrBadCreds
+	}
 	if err = authenticator.Authenticate(entity, req.Credentials, req.Nonce); err != nil {
 		logger.Debugf("bad credentials")
+		if isUser && err == common.ErrBadCreds {
+			recordFailedLogin(st, user)
+		}
 		return nil, nil, err
 	}
 
 	// For user logins, update the last login time.
-	// NOTE: this code path is only for local users. When we support remote
-	// user logins with bearer tokens, we will need to make sure that we also
-	// update the last connection times for the environment users there.
+	// NOTE: this code path is only for local users; the last connection
+	// times of external users are updated by checkExternalCreds.
 	var lastLogin *time.Time
-	if user, ok := entity.(*state.User); ok {
+	if isUser {
+		if err := user.ResetFailedLogins(); err != nil {
+			logger.Errorf("cannot reset failed logins: %v", err)
+		}
 		lastLogin = user.LastLogin()
 		if lookForEnvUser {
-			envUser, err := st.EnvironmentUser(user.UserTag())
+			envUser, err := findEnvironmentUser(st, user.UserTag())
 			if err != nil {
 				return nil, nil, errors.Wrap(err, common.ErrBadCreds)
 			}
 			// The last connection for the environment takes precedence over
-			// the local user last login time.
-			lastLogin = envUser.LastConnection()
-			envUser.UpdateLastConnection()
+			// the local user last login time. Users with access through
+			// a group have no environment user to record it against.
+			if envUser != nil {
+				lastLogin = envUser.LastConnection()
+				envUser.UpdateLastConnection()
+			}
 		}
 		// Only update the user's last login time if it is a successful
 		// login, meaning that if we are logging into an environment, make
@@ -288,6 +311,42 @@ func checkCreds(st *state.State, req params.LoginRequest, lookForEnvUser bool) (
 	return entity, lastLogin, nil
 }
 
+// recordFailedLogin counts a failed login for the given user, locking
+// them out if they have failed to log in too many times in a row.
+func recordFailedLogin(st *state.State, user *state.User) {
+	policy, err := st.LoginPolicy()
+	if err != nil {
+		logger.Errorf("cannot read login policy: %v", err)
+		return
+	}
+	if err := user.RecordFailedLogin(policy); err != nil {
+		logger.Errorf("%v", err)
+		return
+	}
+	if lockedUntil := user.LockedUntil(); lockedUntil != nil {
+		logger.Warningf("user %q locked out until %v after too many failed logins", user.Name(), lockedUntil)
+	}
+}
+
+// findEnvironmentUser returns the environment user for the given user,
+// or nil if the user has no environment user but may access the
+// environment as a member of a group. It returns an error satisfying
+// errors.IsNotFound if the user may not access the environment.
+func findEnvironmentUser(st *state.State, user names.UserTag) (*state.EnvironmentUser, error) {
+	envUser, err := st.EnvironmentUser(user)
+	if !errors.IsNotFound(err) {
+		return envUser, err
+	}
+	hasAccess, accessErr := st.HasEnvironmentAccess(user)
+	if accessErr != nil {
+		return nil, errors.Trace(accessErr)
+	}
+	if !hasAccess {
+		return nil, err
+	}
+	return nil, nil
+}
+
 func checkForValidMachineAgent(entity state.Entity, req params.LoginRequest) error {
 	// If this is a machine agent connecting, we need to check the
 	// nonce matches, otherwise the wrong agent might be trying to
@@ -498,6 +498,68 @@ func (s *loginSuite) TestNonEnvironUserLoginFails(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
 }
 
+func (s *loginSuite) TestGroupMemberLogin(c *gc.C) {
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "dummy-password", NoEnvUser: true})
+	group, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	err = group.AddMember(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.AddEnvironmentGroup("ops", s.AdminUserTag(c))
+	c.Assert(err, jc.ErrorIsNil)
+
+	info.Password = "dummy-password"
+	info.Tag = user.UserTag()
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+
+	// Once the user leaves the group, they may no longer log in.
+	err = group.RemoveMember(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginSuite) TestLoginLockout(c *gc.C) {
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"login-failure-limit": 2,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "dummy-password"})
+	info.Tag = user.UserTag()
+
+	login := func(password string) error {
+		info.Password = password
+		st, err := api.Open(info, fastDialOpts)
+		if err == nil {
+			st.Close()
+		}
+		return err
+	}
+
+	// A successful login forgets earlier failures.
+	c.Assert(login("wrong"), gc.ErrorMatches, "invalid entity name or password")
+	c.Assert(login("dummy-password"), jc.ErrorIsNil)
+	c.Assert(login("wrong"), gc.ErrorMatches, "invalid entity name or password")
+	c.Assert(login("dummy-password"), jc.ErrorIsNil)
+
+	// Once locked out, even the right password is refused.
+	c.Assert(login("wrong"), gc.ErrorMatches, "invalid entity name or password")
+	c.Assert(login("wrong"), gc.ErrorMatches, "invalid entity name or password")
+	c.Assert(login("dummy-password"), gc.ErrorMatches, "invalid entity name or password")
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+
+	err = user.Unlock()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(login("dummy-password"), jc.ErrorIsNil)
+}
+
 func (s *loginV0Suite) TestLoginReportsEnvironTag(c *gc.C) {
 	st, cleanup := s.setupServer(c)
 	defer cleanup()
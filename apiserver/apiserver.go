@@ -22,6 +22,7 @@ import (
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/metricsender"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/rpc"
@@ -327,6 +328,9 @@ func (srv *Server) run(lis net.Listener) {
 			httpHandler: httpHandler{ssState: srv.state},
 			dataDir:     srv.dataDir},
 	)
+	handleAll(mux, "/environment/:envuuid/resources",
+		&resourcesHandler{httpHandler{ssState: srv.state}},
+	)
 	// TODO: We can switch from handleAll to mux.Post/Get/etc for entries
 	// where we only want to support specific request methods. However, our
 	// tests currently assert that errors come back as application/json and
@@ -377,6 +381,17 @@ func (srv *Server) run(lis net.Listener) {
 			httpHandler{ssState: srv.state},
 		}},
 	)
+	// The metrics endpoint serves the latest charm metric values in
+	// the Prometheus text format, to system administrators only.
+	handleAll(mux, "/metrics",
+		&metricsHandler{
+			httpHandler: httpHandler{
+				ssState:            srv.state,
+				stateServerEnvOnly: true,
+			},
+			sender: metricsender.DefaultPrometheusSender,
+		},
+	)
 	handleAll(mux, "/", http.HandlerFunc(srv.apiHandler))
 
 	go func() {
@@ -0,0 +1,44 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication
+
+import (
+	"github.com/juju/names"
+)
+
+// ExternalIdentity describes a user whose credentials have been
+// checked by an external identity provider.
+type ExternalIdentity struct {
+	User        names.UserTag
+	DisplayName string
+
+	// Groups holds the names of the groups the user belongs to
+	// in the identity provider.
+	Groups []string
+}
+
+// ExternalAuthenticator authenticates users whose identity is held by
+// a service outside Juju, rather than as a state.User.
+type ExternalAuthenticator interface {
+	// Domain returns the user domain the authenticator is
+	// responsible for. Users in the domain log in with tags like
+	// "user-bob@domain".
+	Domain() string
+
+	// AuthenticateUser checks the password of the given user,
+	// returning their identity if it is valid. It returns
+	// common.ErrBadCreds if the credentials are invalid.
+	AuthenticateUser(user names.UserTag, password string) (*ExternalIdentity, error)
+}
+
+// ExternalUser is the entity representing an externally authenticated
+// user that has logged in to the API.
+type ExternalUser struct {
+	ExternalIdentity
+}
+
+// Tag implements state.Entity.
+func (u *ExternalUser) Tag() names.Tag {
+	return u.User
+}
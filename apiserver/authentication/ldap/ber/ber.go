@@ -0,0 +1,209 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The ber package implements the subset of the ASN.1 Basic Encoding
+// Rules needed to speak LDAPv3: definite lengths and single-octet
+// identifiers only.
+package ber
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/juju/errors"
+)
+
+// Identifier classes and flags.
+const (
+	ClassUniversal   = 0x00
+	ClassApplication = 0x40
+	ClassContext     = 0x80
+	Constructed      = 0x20
+)
+
+// Universal tags, including class and constructed bits where
+// applicable.
+const (
+	TagBoolean     = 0x01
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagEnumerated  = 0x0a
+	TagSequence    = Constructed | 0x10
+	TagSet         = Constructed | 0x11
+)
+
+// maxLength limits the size of any single element read, to bound
+// the memory a misbehaving peer can make us allocate.
+const maxLength = 16 * 1024 * 1024
+
+// Packet is a single BER element. Constructed packets hold their
+// elements in Children; primitive packets hold their content in Value.
+type Packet struct {
+	Tag      byte
+	Value    []byte
+	Children []*Packet
+}
+
+// IsConstructed reports whether the packet holds other packets.
+func (p *Packet) IsConstructed() bool {
+	return p.Tag&Constructed != 0
+}
+
+// NewConstructed returns a constructed packet with the given tag
+// holding the given children.
+func NewConstructed(tag byte, children ...*Packet) *Packet {
+	return &Packet{Tag: tag | Constructed, Children: children}
+}
+
+// NewSequence returns a universal SEQUENCE holding the given children.
+func NewSequence(children ...*Packet) *Packet {
+	return NewConstructed(TagSequence, children...)
+}
+
+// NewString returns a primitive packet with the given tag holding s.
+func NewString(tag byte, s string) *Packet {
+	return &Packet{Tag: tag, Value: []byte(s)}
+}
+
+// NewOctetString returns a universal OCTET STRING holding s.
+func NewOctetString(s string) *Packet {
+	return NewString(TagOctetString, s)
+}
+
+// NewInteger returns a primitive packet with the given tag holding
+// the two's complement encoding of n.
+func NewInteger(tag byte, n int64) *Packet {
+	var content []byte
+	for {
+		content = append([]byte{byte(n)}, content...)
+		n >>= 8
+		// Stop once the remaining bits are all sign extension of
+		// the most significant bit written.
+		if (n == 0 && content[0]&0x80 == 0) || (n == -1 && content[0]&0x80 != 0) {
+			break
+		}
+	}
+	return &Packet{Tag: tag, Value: content}
+}
+
+// NewBoolean returns a universal BOOLEAN holding b.
+func NewBoolean(b bool) *Packet {
+	if b {
+		return &Packet{Tag: TagBoolean, Value: []byte{0xff}}
+	}
+	return &Packet{Tag: TagBoolean, Value: []byte{0x00}}
+}
+
+// Int returns the integer held by a primitive packet.
+func (p *Packet) Int() (int64, error) {
+	if p.IsConstructed() || len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, errors.Errorf("invalid integer element")
+	}
+	n := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		n = n<<8 | int64(b)
+	}
+	return n, nil
+}
+
+// String returns the content of a primitive packet as a string.
+func (p *Packet) String() string {
+	return string(p.Value)
+}
+
+// Bytes returns the BER encoding of the packet.
+func (p *Packet) Bytes() []byte {
+	content := p.Value
+	if p.IsConstructed() {
+		var buf bytes.Buffer
+		for _, child := range p.Children {
+			buf.Write(child.Bytes())
+		}
+		content = buf.Bytes()
+	}
+	result := append([]byte{p.Tag}, encodeLength(len(content))...)
+	return append(result, content...)
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var octets []byte
+	for ; n > 0; n >>= 8 {
+		octets = append([]byte{byte(n)}, octets...)
+	}
+	return append([]byte{0x80 | byte(len(octets))}, octets...)
+}
+
+// Read reads a single packet from r.
+func Read(r io.Reader) (*Packet, error) {
+	var header [1]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	tag := header[0]
+	if tag&0x1f == 0x1f {
+		return nil, errors.Errorf("multi-octet identifiers not supported")
+	}
+	length, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, noEOF(err)
+	}
+	p := &Packet{Tag: tag}
+	if !p.IsConstructed() {
+		p.Value = content
+		return p, nil
+	}
+	contentReader := bytes.NewReader(content)
+	for contentReader.Len() > 0 {
+		child, err := Read(contentReader)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		p.Children = append(p.Children, child)
+	}
+	return p, nil
+}
+
+func readLength(r io.Reader) (int, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, noEOF(err)
+	}
+	if b[0] < 0x80 {
+		return int(b[0]), nil
+	}
+	count := int(b[0] & 0x7f)
+	if count == 0 {
+		return 0, errors.Errorf("indefinite lengths not supported")
+	}
+	if count > 4 {
+		return 0, errors.Errorf("element too long")
+	}
+	octets := make([]byte, count)
+	if _, err := io.ReadFull(r, octets); err != nil {
+		return 0, noEOF(err)
+	}
+	length := 0
+	for _, o := range octets {
+		length = length<<8 | int(o)
+	}
+	if length > maxLength {
+		return 0, errors.Errorf("element too long")
+	}
+	return length, nil
+}
+
+// noEOF converts io.EOF to io.ErrUnexpectedEOF, because an EOF part
+// way through an element is always unexpected.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ber_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication/ldap/ber"
+	"github.com/juju/juju/testing"
+)
+
+type berSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&berSuite{})
+
+func roundTrip(c *gc.C, p *ber.Packet) *ber.Packet {
+	result, err := ber.Read(bytes.NewReader(p.Bytes()))
+	c.Assert(err, jc.ErrorIsNil)
+	return result
+}
+
+func (s *berSuite) TestInteger(c *gc.C) {
+	for _, n := range []int64{0, 1, 127, 128, 255, 256, -1, -128, -129, 1 << 40, -(1 << 40)} {
+		c.Logf("%d", n)
+		result, err := roundTrip(c, ber.NewInteger(ber.TagInteger, n)).Int()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(result, gc.Equals, n)
+	}
+}
+
+func (s *berSuite) TestIntegerEncoding(c *gc.C) {
+	c.Assert(ber.NewInteger(ber.TagInteger, 3).Bytes(), gc.DeepEquals, []byte{0x02, 0x01, 0x03})
+	c.Assert(ber.NewInteger(ber.TagInteger, 128).Bytes(), gc.DeepEquals, []byte{0x02, 0x02, 0x00, 0x80})
+	c.Assert(ber.NewInteger(ber.TagEnumerated, -1).Bytes(), gc.DeepEquals, []byte{0x0a, 0x01, 0xff})
+}
+
+func (s *berSuite) TestLongLength(c *gc.C) {
+	value := strings.Repeat("x", 70000)
+	p := ber.NewOctetString(value)
+	c.Assert(p.Bytes()[:5], gc.DeepEquals, []byte{0x04, 0x83, 0x01, 0x11, 0x70})
+	c.Assert(roundTrip(c, p).String(), gc.Equals, value)
+}
+
+func (s *berSuite) TestConstructed(c *gc.C) {
+	p := ber.NewSequence(
+		ber.NewInteger(ber.TagInteger, 1),
+		ber.NewConstructed(ber.ClassApplication|1, ber.NewOctetString("a"), ber.NewBoolean(true)),
+	)
+	result := roundTrip(c, p)
+	c.Assert(result, jc.DeepEquals, &ber.Packet{
+		Tag: ber.TagSequence,
+		Children: []*ber.Packet{
+			{Tag: ber.TagInteger, Value: []byte{1}},
+			{Tag: ber.ClassApplication | ber.Constructed | 1, Children: []*ber.Packet{
+				{Tag: ber.TagOctetString, Value: []byte("a")},
+				{Tag: ber.TagBoolean, Value: []byte{0xff}},
+			}},
+		},
+	})
+}
+
+func (s *berSuite) TestReadErrors(c *gc.C) {
+	for i, t := range []struct {
+		data []byte
+		err  string
+	}{{
+		data: []byte{0x04, 0x80},
+		err:  "indefinite lengths not supported",
+	}, {
+		data: []byte{0x1f, 0x01},
+		err:  "multi-octet identifiers not supported",
+	}, {
+		data: []byte{0x04, 0x85, 1, 2, 3, 4, 5},
+		err:  "element too long",
+	}, {
+		data: []byte{0x04, 0x84, 0x7f, 0xff, 0xff, 0xff},
+		err:  "element too long",
+	}, {
+		data: []byte{0x04, 0x03, 'a'},
+		err:  "unexpected EOF",
+	}, {
+		data: []byte{0x30, 0x03, 0x04, 0x05, 'a'},
+		err:  "unexpected EOF",
+	}} {
+		c.Logf("test %d", i)
+		_, err := ber.Read(bytes.NewReader(t.data))
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+	_, err := ber.Read(bytes.NewReader(nil))
+	c.Assert(err, gc.Equals, io.EOF)
+}
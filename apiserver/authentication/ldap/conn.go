@@ -0,0 +1,277 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/authentication/ldap/ber"
+)
+
+// LDAPv3 protocol operations (RFC 4511, section 4.2 onwards).
+const (
+	opBindRequest           = ber.ClassApplication | ber.Constructed | 0
+	opBindResponse          = ber.ClassApplication | ber.Constructed | 1
+	opUnbindRequest         = ber.ClassApplication | 2
+	opSearchRequest         = ber.ClassApplication | ber.Constructed | 3
+	opSearchResultEntry     = ber.ClassApplication | ber.Constructed | 4
+	opSearchResultDone      = ber.ClassApplication | ber.Constructed | 5
+	opSearchResultReference = ber.ClassApplication | ber.Constructed | 19
+)
+
+// Search filter choices.
+const (
+	filterEqualityMatch = ber.ClassContext | ber.Constructed | 3
+	filterPresent       = ber.ClassContext | 7
+)
+
+// Search scopes and alias dereferencing.
+const (
+	scopeBaseObject   = 0
+	scopeWholeSubtree = 2
+	derefNever        = 0
+)
+
+// simpleAuthTag identifies simple (password) authentication in a
+// bind request.
+const simpleAuthTag = ber.ClassContext | 0
+
+// Result codes.
+const (
+	resultSuccess      = 0
+	resultNoSuchObject = 32
+	resultInvalidCreds = 49
+)
+
+// resultError holds a non-success LDAPResult.
+type resultError struct {
+	code    int64
+	message string
+}
+
+func (e *resultError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("LDAP result code %d", e.code)
+	}
+	return fmt.Sprintf("LDAP result code %d: %s", e.code, e.message)
+}
+
+// isResultCode reports whether err is an LDAP result with the given code.
+func isResultCode(err error, code int64) bool {
+	e, ok := errors.Cause(err).(*resultError)
+	return ok && e.code == code
+}
+
+// entry holds a single search result.
+type entry struct {
+	dn    string
+	attrs map[string][]string
+}
+
+// conn is a connection to an LDAP server. It is not safe for
+// concurrent use.
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	msgID   int64
+}
+
+// dial connects to the LDAP server at the given ldap:// or ldaps://
+// URL. All operations on the connection must complete within the
+// given timeout.
+func dial(serverURL string, timeout time.Duration) (*conn, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	host := u.Host
+	var netConn net.Conn
+	dialer := &net.Dialer{Timeout: timeout}
+	switch u.Scheme {
+	case "ldap":
+		if !hasPort(host) {
+			host = net.JoinHostPort(host, "389")
+		}
+		logger.Warningf("connecting to LDAP server %s without TLS: passwords are sent in the clear; use an ldaps:// URL", host)
+		netConn, err = dialer.Dial("tcp", host)
+	case "ldaps":
+		if !hasPort(host) {
+			host = net.JoinHostPort(host, "636")
+		}
+		netConn, err = tls.DialWithDialer(dialer, "tcp", host, nil)
+	default:
+		return nil, errors.NotValidf("LDAP URL scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot connect to LDAP server")
+	}
+	if err := netConn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		netConn.Close()
+		return nil, errors.Trace(err)
+	}
+	return &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+	}, nil
+}
+
+func hasPort(host string) bool {
+	return strings.LastIndex(host, ":") > strings.LastIndex(host, "]")
+}
+
+// Close unbinds and closes the connection.
+func (c *conn) Close() error {
+	c.send(&ber.Packet{Tag: opUnbindRequest})
+	return c.netConn.Close()
+}
+
+// bind authenticates the connection as the given DN, using simple
+// authentication.
+func (c *conn) bind(dn, password string) error {
+	request := ber.NewConstructed(opBindRequest,
+		ber.NewInteger(ber.TagInteger, 3),
+		ber.NewOctetString(dn),
+		ber.NewString(simpleAuthTag, password),
+	)
+	if err := c.send(request); err != nil {
+		return errors.Trace(err)
+	}
+	response, err := c.receive(opBindResponse)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return resultFromPacket(response)
+}
+
+// search returns the entries under base that match filter, with the
+// given attributes.
+func (c *conn) search(base string, scope int64, filter *ber.Packet, attrs ...string) ([]entry, error) {
+	attrPackets := make([]*ber.Packet, len(attrs))
+	for i, attr := range attrs {
+		attrPackets[i] = ber.NewOctetString(attr)
+	}
+	request := ber.NewConstructed(opSearchRequest,
+		ber.NewOctetString(base),
+		ber.NewInteger(ber.TagEnumerated, scope),
+		ber.NewInteger(ber.TagEnumerated, derefNever),
+		ber.NewInteger(ber.TagInteger, 0),
+		ber.NewInteger(ber.TagInteger, 0),
+		ber.NewBoolean(false),
+		filter,
+		ber.NewSequence(attrPackets...),
+	)
+	if err := c.send(request); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var entries []entry
+	for {
+		response, err := c.receive(opSearchResultEntry, opSearchResultReference, opSearchResultDone)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		switch response.Tag {
+		case opSearchResultDone:
+			if err := resultFromPacket(response); err != nil {
+				return nil, err
+			}
+			return entries, nil
+		case opSearchResultEntry:
+			e, err := entryFromPacket(response)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			entries = append(entries, e)
+		}
+		// References to other servers are ignored.
+	}
+}
+
+// equalityFilter returns a filter matching entries whose attribute
+// attr has the given value.
+func equalityFilter(attr, value string) *ber.Packet {
+	return ber.NewConstructed(filterEqualityMatch, ber.NewOctetString(attr), ber.NewOctetString(value))
+}
+
+// presentFilter returns a filter matching entries that have the
+// attribute attr.
+func presentFilter(attr string) *ber.Packet {
+	return ber.NewString(filterPresent, attr)
+}
+
+func (c *conn) send(op *ber.Packet) error {
+	c.msgID++
+	message := ber.NewSequence(ber.NewInteger(ber.TagInteger, c.msgID), op)
+	_, err := c.netConn.Write(message.Bytes())
+	return err
+}
+
+// receive reads the next message, which must be a response to the
+// most recent request with one of the given operation tags.
+func (c *conn) receive(tags ...byte) (*ber.Packet, error) {
+	message, err := ber.Read(c.reader)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read LDAP response")
+	}
+	if message.Tag != ber.TagSequence || len(message.Children) < 2 {
+		return nil, errors.New("malformed LDAP message")
+	}
+	msgID, err := message.Children[0].Int()
+	if err != nil {
+		return nil, errors.Annotate(err, "malformed LDAP message")
+	}
+	if msgID != c.msgID {
+		return nil, errors.Errorf("unexpected LDAP message id %d", msgID)
+	}
+	op := message.Children[1]
+	for _, tag := range tags {
+		if op.Tag == tag {
+			return op, nil
+		}
+	}
+	return nil, errors.Errorf("unexpected LDAP operation 0x%x", op.Tag)
+}
+
+// resultFromPacket returns the error described by an LDAPResult, or
+// nil if it describes success.
+func resultFromPacket(p *ber.Packet) error {
+	if len(p.Children) < 3 {
+		return errors.New("malformed LDAP result")
+	}
+	code, err := p.Children[0].Int()
+	if err != nil {
+		return errors.Annotate(err, "malformed LDAP result")
+	}
+	if code == resultSuccess {
+		return nil
+	}
+	return &resultError{code: code, message: p.Children[2].String()}
+}
+
+func entryFromPacket(p *ber.Packet) (entry, error) {
+	if len(p.Children) < 2 {
+		return entry{}, errors.New("malformed LDAP search result")
+	}
+	e := entry{
+		dn:    p.Children[0].String(),
+		attrs: make(map[string][]string),
+	}
+	for _, attr := range p.Children[1].Children {
+		if len(attr.Children) < 2 {
+			return entry{}, errors.New("malformed LDAP attribute")
+		}
+		name := strings.ToLower(attr.Children[0].String())
+		for _, value := range attr.Children[1].Children {
+			e.attrs[name] = append(e.attrs[name], value.String())
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The ldap package implements an authentication.ExternalAuthenticator
+// that checks user credentials against an LDAPv3 directory, using
+// simple bind authentication.
+package ldap
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/common"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.authentication.ldap")
+
+// DefaultTimeout is used when the Timeout in Config is zero.
+const DefaultTimeout = 10 * time.Second
+
+// Config holds the parameters needed to authenticate users against
+// an LDAP directory.
+type Config struct {
+	// URL holds the ldap:// or ldaps:// URL of the directory server.
+	URL string
+
+	// Domain holds the Juju user domain of directory users. A user
+	// logging in as "bob@Domain" is authenticated as the directory
+	// entry named by UserDN.
+	Domain string
+
+	// UserDN holds a template for the distinguished name of a user's
+	// entry, in which "%s" is replaced by the user name; for example
+	// "uid=%s,ou=people,dc=example,dc=com".
+	UserDN string
+
+	// GroupBase, if set, holds the distinguished name under which
+	// groups are found. A user belongs to each group beneath it that
+	// lists the user's DN as a "member".
+	GroupBase string
+
+	// Timeout bounds the time taken to authenticate a user.
+	Timeout time.Duration
+}
+
+// Validate checks that the configuration is complete.
+func (cfg Config) Validate() error {
+	if cfg.URL == "" {
+		return errors.NotValidf("empty LDAP URL")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return errors.Annotate(err, "invalid LDAP URL")
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return errors.NotValidf("LDAP URL scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.NotValidf("LDAP URL %q without host", cfg.URL)
+	}
+	if cfg.Domain == "" || cfg.Domain == "local" {
+		return errors.NotValidf("LDAP user domain %q", cfg.Domain)
+	}
+	if strings.Count(cfg.UserDN, "%s") != 1 || strings.Count(cfg.UserDN, "%") != 1 {
+		return errors.NotValidf(`LDAP user DN template %q (must contain "%%s" once)`, cfg.UserDN)
+	}
+	return nil
+}
+
+// Authenticator authenticates users against an LDAP directory.
+type Authenticator struct {
+	config Config
+}
+
+var _ authentication.ExternalAuthenticator = (*Authenticator)(nil)
+
+// NewAuthenticator returns an Authenticator using the given
+// configuration.
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	return &Authenticator{config: cfg}, nil
+}
+
+// Domain is part of the authentication.ExternalAuthenticator interface.
+func (a *Authenticator) Domain() string {
+	return a.config.Domain
+}
+
+// AuthenticateUser is part of the authentication.ExternalAuthenticator
+// interface. The user is authenticated by binding to the directory as
+// the user's entry; the user's display name and groups are then read
+// with the user's own credentials.
+func (a *Authenticator) AuthenticateUser(user names.UserTag, password string) (*authentication.ExternalIdentity, error) {
+	if user.Domain() != a.config.Domain {
+		return nil, common.ErrBadCreds
+	}
+	// An LDAP bind with an empty password is an unauthenticated
+	// bind, which most servers accept; it must never be taken
+	// as proof of identity.
+	if password == "" {
+		return nil, common.ErrBadCreds
+	}
+	userDN := fmt.Sprintf(a.config.UserDN, escapeDNValue(user.Name()))
+
+	c, err := dial(a.config.URL, a.config.Timeout)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer c.Close()
+
+	if err := c.bind(userDN, password); err != nil {
+		if isResultCode(err, resultInvalidCreds) {
+			logger.Debugf("invalid credentials for %q", userDN)
+			return nil, common.ErrBadCreds
+		}
+		return nil, errors.Annotatef(err, "cannot bind as %q", userDN)
+	}
+
+	identity := &authentication.ExternalIdentity{User: user}
+	entries, err := c.search(userDN, scopeBaseObject, presentFilter("objectClass"), "cn", "displayName")
+	if err != nil && !isResultCode(err, resultNoSuchObject) {
+		return nil, errors.Annotatef(err, "cannot read %q", userDN)
+	}
+	if len(entries) > 0 {
+		identity.DisplayName = firstValue(entries[0], "displayname", "cn")
+	}
+
+	if a.config.GroupBase != "" {
+		entries, err := c.search(a.config.GroupBase, scopeWholeSubtree, equalityFilter("member", userDN), "cn")
+		if err != nil && !isResultCode(err, resultNoSuchObject) {
+			return nil, errors.Annotatef(err, "cannot read groups of %q", userDN)
+		}
+		for _, e := range entries {
+			if group := firstValue(e, "cn"); group != "" {
+				identity.Groups = append(identity.Groups, group)
+			}
+		}
+	}
+	return identity, nil
+}
+
+// firstValue returns the first value of the first of the given
+// attributes that the entry has.
+func firstValue(e entry, attrs ...string) string {
+	for _, attr := range attrs {
+		if values := e.attrs[attr]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// escapeDNValue escapes the characters that are special in an
+// attribute value of a distinguished name (RFC 4514, section 2.4).
+func escapeDNValue(s string) string {
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case strings.IndexByte(`,+"\<>;=`, c) >= 0,
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(s)-1 && c == ' ':
+			buf = append(buf, '\\', c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return string(buf)
+}
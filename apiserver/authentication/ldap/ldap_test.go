@@ -0,0 +1,155 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ldap_test
+
+import (
+	"net"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/authentication/ldap"
+	"github.com/juju/juju/apiserver/authentication/ldap/ldaptest"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/testing"
+)
+
+type ldapSuite struct {
+	testing.BaseSuite
+	server *ldaptest.Server
+	config ldap.Config
+}
+
+var _ = gc.Suite(&ldapSuite{})
+
+func (s *ldapSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	server, err := ldaptest.NewServer()
+	c.Assert(err, jc.ErrorIsNil)
+	s.server = server
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	server.AddEntry(ldaptest.Entry{DN: "ou=people,dc=example,dc=com"})
+	server.AddEntry(ldaptest.Entry{DN: "ou=groups,dc=example,dc=com"})
+	server.AddEntry(ldaptest.Entry{
+		DN:       "uid=bob,ou=people,dc=example,dc=com",
+		Password: "bob-secret",
+		Attrs:    map[string][]string{"cn": {"Bob Brown"}},
+	})
+	server.AddEntry(ldaptest.Entry{
+		DN: "cn=ops,ou=groups,dc=example,dc=com",
+		Attrs: map[string][]string{
+			"cn":     {"ops"},
+			"member": {"uid=bob,ou=people,dc=example,dc=com", "uid=alice,ou=people,dc=example,dc=com"},
+		},
+	})
+	server.AddEntry(ldaptest.Entry{
+		DN: "cn=dev,ou=groups,dc=example,dc=com",
+		Attrs: map[string][]string{
+			"cn":     {"dev"},
+			"member": {"uid=alice,ou=people,dc=example,dc=com"},
+		},
+	})
+	s.config = ldap.Config{
+		URL:       server.URL(),
+		Domain:    "example",
+		UserDN:    "uid=%s,ou=people,dc=example,dc=com",
+		GroupBase: "ou=groups,dc=example,dc=com",
+	}
+}
+
+func (s *ldapSuite) authenticate(c *gc.C, user, password string) (*authentication.ExternalIdentity, error) {
+	authenticator, err := ldap.NewAuthenticator(s.config)
+	c.Assert(err, jc.ErrorIsNil)
+	return authenticator.AuthenticateUser(names.NewUserTag(user), password)
+}
+
+func (s *ldapSuite) TestAuthenticateUser(c *gc.C) {
+	identity, err := s.authenticate(c, "bob@example", "bob-secret")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(identity, jc.DeepEquals, &authentication.ExternalIdentity{
+		User:        names.NewUserTag("bob@example"),
+		DisplayName: "Bob Brown",
+		Groups:      []string{"ops"},
+	})
+	c.Assert(s.server.Binds(), jc.DeepEquals, []string{"uid=bob,ou=people,dc=example,dc=com"})
+}
+
+func (s *ldapSuite) TestAuthenticateUserNoGroupBase(c *gc.C) {
+	s.config.GroupBase = ""
+	identity, err := s.authenticate(c, "bob@example", "bob-secret")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(identity.Groups, gc.HasLen, 0)
+}
+
+func (s *ldapSuite) TestAuthenticateUserBadCredentials(c *gc.C) {
+	for i, t := range []struct {
+		user     string
+		password string
+	}{
+		{"bob@example", "wrong"},
+		{"bob@example", ""},
+		{"alice@example", "bob-secret"},
+		{"bob@elsewhere", "bob-secret"},
+	} {
+		c.Logf("test %d: %s", i, t.user)
+		_, err := s.authenticate(c, t.user, t.password)
+		c.Check(err, gc.Equals, common.ErrBadCreds)
+	}
+	c.Assert(s.server.Binds(), gc.HasLen, 0)
+}
+
+func (s *ldapSuite) TestAuthenticateUserServerDown(c *gc.C) {
+	// Find a port with nothing listening on it.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	s.config.URL = "ldap://" + listener.Addr().String()
+	listener.Close()
+
+	_, err = s.authenticate(c, "bob@example", "bob-secret")
+	c.Assert(err, gc.ErrorMatches, "cannot connect to LDAP server: .*")
+	c.Assert(err, gc.Not(gc.Equals), common.ErrBadCreds)
+}
+
+func (s *ldapSuite) TestValidate(c *gc.C) {
+	for i, t := range []struct {
+		modify func(*ldap.Config)
+		err    string
+	}{{
+		modify: func(cfg *ldap.Config) { cfg.URL = "" },
+		err:    "empty LDAP URL not valid",
+	}, {
+		modify: func(cfg *ldap.Config) { cfg.URL = "http://example.com" },
+		err:    `LDAP URL scheme "http" not valid`,
+	}, {
+		modify: func(cfg *ldap.Config) { cfg.URL = "ldap://" },
+		err:    `LDAP URL "ldap://" without host not valid`,
+	}, {
+		modify: func(cfg *ldap.Config) { cfg.Domain = "" },
+		err:    `LDAP user domain "" not valid`,
+	}, {
+		modify: func(cfg *ldap.Config) { cfg.Domain = "local" },
+		err:    `LDAP user domain "local" not valid`,
+	}, {
+		modify: func(cfg *ldap.Config) { cfg.UserDN = "ou=people,dc=example,dc=com" },
+		err:    `LDAP user DN template .* not valid`,
+	}, {
+		modify: func(cfg *ldap.Config) { cfg.UserDN = "uid=%s,ou=%d" },
+		err:    `LDAP user DN template .* not valid`,
+	}} {
+		c.Logf("test %d", i)
+		cfg := s.config
+		t.modify(&cfg)
+		_, err := ldap.NewAuthenticator(cfg)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *ldapSuite) TestEscapeDNValue(c *gc.C) {
+	c.Assert(ldap.EscapeDNValue("bob"), gc.Equals, "bob")
+	c.Assert(ldap.EscapeDNValue("a+b,c=d"), gc.Equals, `a\+b\,c\=d`)
+	c.Assert(ldap.EscapeDNValue("#x "), gc.Equals, `\#x\ `)
+}
@@ -0,0 +1,275 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The ldaptest package provides a stand-in LDAP directory server for
+// testing. It implements just enough of LDAPv3 to serve simple binds
+// and searches with equality and presence filters.
+package ldaptest
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/authentication/ldap/ber"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.authentication.ldap.ldaptest")
+
+const (
+	opBindRequest       = ber.ClassApplication | ber.Constructed | 0
+	opBindResponse      = ber.ClassApplication | ber.Constructed | 1
+	opUnbindRequest     = ber.ClassApplication | 2
+	opSearchRequest     = ber.ClassApplication | ber.Constructed | 3
+	opSearchResultEntry = ber.ClassApplication | ber.Constructed | 4
+	opSearchResultDone  = ber.ClassApplication | ber.Constructed | 5
+
+	filterEqualityMatch = ber.ClassContext | ber.Constructed | 3
+	filterPresent       = ber.ClassContext | 7
+
+	scopeBaseObject = 0
+
+	resultSuccess            = 0
+	resultProtocolError      = 2
+	resultNoSuchObject       = 32
+	resultInvalidCredentials = 49
+	resultUnwillingToPerform = 53
+)
+
+// Entry is a directory entry.
+type Entry struct {
+	DN       string
+	Password string
+	Attrs    map[string][]string
+}
+
+// Server is a stand-in LDAP server listening on the loopback
+// interface.
+type Server struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	binds   []string
+}
+
+// NewServer starts a server with no entries.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	s := &Server{
+		listener: listener,
+		entries:  make(map[string]Entry),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// URL returns the ldap:// URL of the server.
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// Close stops the server and waits for its connections to finish.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// AddEntry adds or replaces an entry. If password is not empty,
+// clients may bind as the entry with that password.
+func (s *Server) AddEntry(entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[normalizeDN(entry.DN)] = entry
+}
+
+// Binds returns the DNs that clients have successfully bound as.
+func (s *Server) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			if err := s.serveConn(conn); err != nil {
+				logger.Debugf("connection closed: %v", err)
+			}
+		}()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	for {
+		message, err := ber.Read(reader)
+		if err != nil {
+			return err
+		}
+		if message.Tag != ber.TagSequence || len(message.Children) < 2 {
+			return errors.New("malformed message")
+		}
+		msgID, err := message.Children[0].Int()
+		if err != nil {
+			return err
+		}
+		op := message.Children[1]
+		var responses []*ber.Packet
+		switch op.Tag {
+		case opBindRequest:
+			responses = []*ber.Packet{s.bind(op)}
+		case opSearchRequest:
+			responses = s.search(op)
+		case opUnbindRequest:
+			return nil
+		default:
+			return errors.Errorf("unsupported operation 0x%x", op.Tag)
+		}
+		for _, response := range responses {
+			reply := ber.NewSequence(ber.NewInteger(ber.TagInteger, msgID), response)
+			if _, err := conn.Write(reply.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func result(tag byte, code int64, message string) *ber.Packet {
+	return ber.NewConstructed(tag,
+		ber.NewInteger(ber.TagEnumerated, code),
+		ber.NewOctetString(""),
+		ber.NewOctetString(message),
+	)
+}
+
+func (s *Server) bind(op *ber.Packet) *ber.Packet {
+	if len(op.Children) < 3 {
+		return result(opBindResponse, resultProtocolError, "malformed bind request")
+	}
+	dn, password := op.Children[1].String(), op.Children[2].String()
+	if password == "" {
+		return result(opBindResponse, resultUnwillingToPerform, "unauthenticated bind not allowed")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[normalizeDN(dn)]
+	if !ok || entry.Password == "" || entry.Password != password {
+		return result(opBindResponse, resultInvalidCredentials, "invalid credentials")
+	}
+	s.binds = append(s.binds, dn)
+	return result(opBindResponse, resultSuccess, "")
+}
+
+func (s *Server) search(op *ber.Packet) []*ber.Packet {
+	if len(op.Children) < 8 {
+		return []*ber.Packet{result(opSearchResultDone, resultProtocolError, "malformed search request")}
+	}
+	base := normalizeDN(op.Children[0].String())
+	scope, _ := op.Children[1].Int()
+	filter := op.Children[6]
+	var attrs []string
+	for _, attr := range op.Children[7].Children {
+		attrs = append(attrs, attr.String())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[base]; !ok {
+		return []*ber.Packet{result(opSearchResultDone, resultNoSuchObject, "no such object")}
+	}
+	var responses []*ber.Packet
+	for dn, entry := range s.entries {
+		if scope == scopeBaseObject {
+			if dn != base {
+				continue
+			}
+		} else if dn != base && !strings.HasSuffix(dn, ","+base) {
+			continue
+		}
+		if !matches(entry, filter) {
+			continue
+		}
+		responses = append(responses, entryPacket(entry, attrs))
+	}
+	return append(responses, result(opSearchResultDone, resultSuccess, ""))
+}
+
+func matches(entry Entry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case filterPresent:
+		return len(attrValues(entry, filter.String())) > 0
+	case filterEqualityMatch:
+		if len(filter.Children) < 2 {
+			return false
+		}
+		want := filter.Children[1].String()
+		for _, value := range attrValues(entry, filter.Children[0].String()) {
+			if strings.EqualFold(value, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attrValues(entry Entry, name string) []string {
+	if strings.EqualFold(name, "objectClass") {
+		// Every entry has an object class.
+		return []string{"top"}
+	}
+	for attr, values := range entry.Attrs {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func entryPacket(entry Entry, attrs []string) *ber.Packet {
+	var attrPackets []*ber.Packet
+	for _, attr := range attrs {
+		values := attrValues(entry, attr)
+		if len(values) == 0 {
+			continue
+		}
+		valuePackets := make([]*ber.Packet, len(values))
+		for i, value := range values {
+			valuePackets[i] = ber.NewOctetString(value)
+		}
+		attrPackets = append(attrPackets, ber.NewSequence(
+			ber.NewOctetString(attr),
+			ber.NewConstructed(ber.TagSet, valuePackets...),
+		))
+	}
+	return ber.NewConstructed(opSearchResultEntry,
+		ber.NewOctetString(entry.DN),
+		ber.NewSequence(attrPackets...),
+	)
+}
+
+// normalizeDN returns a canonical form of dn for comparison.
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, ",")
+}
@@ -211,6 +211,16 @@ func (h *charmsHandler) processPost(r *http.Request, st *state.State) (*charm.UR
 	if _, err := io.Copy(tempFile, r.Body); err != nil {
 		return nil, fmt.Errorf("error processing file upload: %v", err)
 	}
+	// The optional "signature" query holds a detached signature of
+	// the archive as uploaded, so it is verified before the archive
+	// is repackaged.
+	if _, err := tempFile.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("cannot rewind uploaded file: %v", err)
+	}
+	signedBy, err := service.VerifyCharmSignature(st, tempFile, query.Get("signature"))
+	if err != nil {
+		return nil, err
+	}
 	err = h.processUploadedArchive(tempFile.Name())
 	if err != nil {
 		return nil, err
@@ -236,6 +246,9 @@ func (h *charmsHandler) processPost(r *http.Request, st *state.State) (*charm.UR
 	if err != nil {
 		return nil, err
 	}
+	if err := service.SetCharmSignedBy(st, preparedURL, signedBy); err != nil {
+		return nil, err
+	}
 	// All done.
 	return preparedURL, nil
 }
@@ -21,6 +21,8 @@ import (
 
 	apihttp "github.com/juju/juju/apiserver/http"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/charmsig"
+	charmsigtesting "github.com/juju/juju/charmsig/testing"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/storage"
 	"github.com/juju/juju/testcharms"
@@ -276,6 +278,76 @@ func (s *charmsSuite) TestUploadRepackagesNestedArchives(c *gc.C) {
 	c.Assert(bundle.Config(), jc.DeepEquals, sch.Config())
 }
 
+func (s *charmsSuite) signArchive(c *gc.C, key charmsigtesting.Key, path string) string {
+	f, err := os.Open(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	signature, err := charmsig.Sign(f, key.Private, "")
+	c.Assert(err, jc.ErrorIsNil)
+	return signature
+}
+
+func (s *charmsSuite) trustCharmKeys(c *gc.C, keys string) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"trusted-charm-keys": keys}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *charmsSuite) TestUploadSignedCharm(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	s.trustCharmKeys(c, key.Public)
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	query := url.Values{
+		"series":    {"quantal"},
+		"signature": {s.signArchive(c, key, ch.Path)},
+	}
+
+	resp, err := s.uploadRequest(c, s.charmsURI(c, query.Encode()), true, ch.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	expectedURL := charm.MustParseURL("local:quantal/dummy-1")
+	s.assertUploadResponse(c, resp, expectedURL.String())
+	sch, err := s.State.Charm(expectedURL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.SignedBy(), gc.Equals, key.Fingerprint)
+}
+
+func (s *charmsSuite) TestUploadUnsignedCharmWithTrustedKeys(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	s.trustCharmKeys(c, key.Public)
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+
+	resp, err := s.uploadRequest(c, s.charmsURI(c, "?series=quantal"), true, ch.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "charm is not signed, but the environment only accepts signed charms")
+}
+
+func (s *charmsSuite) TestUploadCharmSignedByUntrustedKey(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	other := charmsigtesting.NewKey(c, "other")
+	s.trustCharmKeys(c, key.Public)
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	query := url.Values{
+		"series":    {"quantal"},
+		"signature": {s.signArchive(c, other, ch.Path)},
+	}
+
+	resp, err := s.uploadRequest(c, s.charmsURI(c, query.Encode()), true, ch.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "charm signature was not made by a trusted key")
+}
+
+func (s *charmsSuite) TestUploadSignedCharmWithoutTrustedKeys(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	query := url.Values{
+		"series":    {"quantal"},
+		"signature": {s.signArchive(c, key, ch.Path)},
+	}
+
+	resp, err := s.uploadRequest(c, s.charmsURI(c, query.Encode()), true, ch.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "cannot verify charm signature: no trusted charm keys configured")
+}
+
 func (s *charmsSuite) TestGetRequiresCharmURL(c *gc.C) {
 	uri := s.charmsURI(c, "?file=hooks/install")
 	resp, err := s.authRequest(c, "GET", uri, "", nil)
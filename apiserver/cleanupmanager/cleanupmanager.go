@@ -0,0 +1,189 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The cleanupmanager package defines an API end point which allows
+// system administrators to inspect the cleanups pending in all
+// environments of a system, and to retry or skip them.
+package cleanupmanager
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/state"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.cleanupmanager")
+
+func init() {
+	common.RegisterStandardFacadeForFeature("CleanupManager", 1, NewCleanupManagerAPI, feature.JES)
+}
+
+// CleanupManager defines the methods on the cleanupmanager API end point.
+type CleanupManager interface {
+	ListCleanups() (params.CleanupInfoList, error)
+	RetryCleanups(args params.CleanupIds) (params.ErrorResults, error)
+	SkipCleanups(args params.CleanupIds) (params.ErrorResults, error)
+}
+
+// CleanupManagerAPI implements the CleanupManager interface and is the
+// concrete implementation of the api end point.
+type CleanupManagerAPI struct {
+	state      *state.State
+	authorizer common.Authorizer
+}
+
+var _ CleanupManager = (*CleanupManagerAPI)(nil)
+
+// NewCleanupManagerAPI creates a new api server endpoint for managing
+// cleanups.
+func NewCleanupManagerAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*CleanupManagerAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, errors.Trace(common.ErrPerm)
+	}
+
+	// Since we know this is a user tag (because AuthClient is true),
+	// we just do the type assertion to the UserTag.
+	apiUser, _ := authorizer.GetAuthTag().(names.UserTag)
+	isAdmin, err := st.IsSystemAdministrator(apiUser)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The entire end point is only accessible to system administrators.
+	if !isAdmin {
+		return nil, errors.Trace(common.ErrPerm)
+	}
+
+	return &CleanupManagerAPI{
+		state:      st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// ListCleanups returns the cleanups pending in all environments of the
+// system, sorted by environment name and then by the time they were
+// scheduled.
+func (api *CleanupManagerAPI) ListCleanups() (params.CleanupInfoList, error) {
+	result := params.CleanupInfoList{}
+	envs, err := api.state.AllEnvironments()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, env := range envs {
+		cleanups, err := api.envCleanups(env)
+		if err != nil {
+			return params.CleanupInfoList{}, errors.Annotatef(err, "environment %q", env.Name())
+		}
+		result.Cleanups = append(result.Cleanups, cleanups...)
+	}
+	sort.Stable(orderedCleanups(result.Cleanups))
+	return result, nil
+}
+
+func (api *CleanupManagerAPI) envCleanups(env *state.Environment) ([]params.CleanupInfo, error) {
+	st, err := api.state.ForEnviron(env.EnvironTag())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer st.Close()
+	cleanups, err := st.Cleanups()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]params.CleanupInfo, len(cleanups))
+	for i, cleanup := range cleanups {
+		result[i] = params.CleanupInfo{
+			EnvironTag:  env.EnvironTag().String(),
+			EnvironName: env.Name(),
+			Id:          cleanup.Id,
+			Kind:        cleanup.Kind,
+			Prefix:      cleanup.Prefix,
+			Created:     cleanup.Created,
+			Attempts:    cleanup.Attempts,
+			LastError:   cleanup.LastError,
+		}
+		if !cleanup.LastAttempt.IsZero() {
+			lastAttempt := cleanup.LastAttempt
+			result[i].LastAttempt = &lastAttempt
+		}
+	}
+	return result, nil
+}
+
+// RetryCleanups immediately runs each of the specified cleanups,
+// returning the error of each one that fails.
+func (api *CleanupManagerAPI) RetryCleanups(args params.CleanupIds) (params.ErrorResults, error) {
+	return api.forEachCleanup(args, func(st *state.State, id string) error {
+		return st.RetryCleanup(id)
+	})
+}
+
+// SkipCleanups removes each of the specified cleanups without running
+// them.
+func (api *CleanupManagerAPI) SkipCleanups(args params.CleanupIds) (params.ErrorResults, error) {
+	return api.forEachCleanup(args, func(st *state.State, id string) error {
+		logger.Infof("skipping cleanup %q in environment %s", id, st.EnvironUUID())
+		return st.SkipCleanup(id)
+	})
+}
+
+func (api *CleanupManagerAPI) forEachCleanup(
+	args params.CleanupIds, f func(st *state.State, id string) error,
+) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Cleanups)),
+	}
+	for i, arg := range args.Cleanups {
+		err := api.withEnviron(arg.EnvironTag, func(st *state.State) error {
+			return f(st, arg.Id)
+		})
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (api *CleanupManagerAPI) withEnviron(environTag string, f func(st *state.State) error) error {
+	tag, err := names.ParseEnvironTag(environTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := api.state.GetEnvironment(tag); err != nil {
+		return errors.Trace(err)
+	}
+	st, err := api.state.ForEnviron(tag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer st.Close()
+	return f(st)
+}
+
+type orderedCleanups []params.CleanupInfo
+
+func (o orderedCleanups) Len() int {
+	return len(o)
+}
+
+func (o orderedCleanups) Less(i, j int) bool {
+	if o[i].EnvironName != o[j].EnvironName {
+		return o[i].EnvironName < o[j].EnvironName
+	}
+	if o[i].EnvironTag != o[j].EnvironTag {
+		return o[i].EnvironTag < o[j].EnvironTag
+	}
+	return o[i].Created.Before(o[j].Created)
+}
+
+func (o orderedCleanups) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+}
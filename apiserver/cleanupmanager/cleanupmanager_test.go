@@ -0,0 +1,136 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cleanupmanager_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/cleanupmanager"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type cleanupManagerSuite struct {
+	jujutesting.JujuConnSuite
+
+	cleanupManager *cleanupmanager.CleanupManagerAPI
+	resources      *common.Resources
+}
+
+var _ = gc.Suite(&cleanupManagerSuite{})
+
+func (s *cleanupManagerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	authoriser := apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	}
+	cleanupManager, err := cleanupmanager.NewCleanupManagerAPI(s.State, s.resources, authoriser)
+	c.Assert(err, jc.ErrorIsNil)
+	s.cleanupManager = cleanupManager
+}
+
+func (s *cleanupManagerSuite) TestNewAPIRefusesNonClient(c *gc.C) {
+	anAuthoriser := apiservertesting.FakeAuthorizer{
+		Tag: names.NewUnitTag("mysql/0"),
+	}
+	endPoint, err := cleanupmanager.NewCleanupManagerAPI(s.State, s.resources, anAuthoriser)
+	c.Assert(endPoint, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *cleanupManagerSuite) TestNewAPIRefusesNonAdmins(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	anAuthoriser := apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	}
+	endPoint, err := cleanupmanager.NewCleanupManagerAPI(s.State, s.resources, anAuthoriser)
+	c.Assert(endPoint, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+// destroyServiceWithUnit schedules a cleanup of the units of a dying
+// service in the given environment.
+func destroyServiceWithUnit(c *gc.C, f *factory.Factory) {
+	unit := f.MakeUnit(c, nil)
+	service, err := unit.Service()
+	c.Assert(err, jc.ErrorIsNil)
+	err = service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *cleanupManagerSuite) TestListCleanups(c *gc.C) {
+	st := s.Factory.MakeEnvironment(c, &factory.EnvParams{Name: "another"})
+	defer st.Close()
+	destroyServiceWithUnit(c, factory.NewFactory(st))
+	destroyServiceWithUnit(c, s.Factory)
+
+	result, err := s.cleanupManager.ListCleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Cleanups, gc.HasLen, 2)
+
+	another := result.Cleanups[0]
+	c.Assert(another.EnvironName, gc.Equals, "another")
+	c.Assert(another.EnvironTag, gc.Equals, names.NewEnvironTag(st.EnvironUUID()).String())
+	c.Assert(another.Kind, gc.Equals, "units")
+	c.Assert(another.Prefix, gc.Equals, "mysql")
+	c.Assert(another.Attempts, gc.Equals, 0)
+	c.Assert(another.LastAttempt, gc.IsNil)
+	c.Assert(another.Created.IsZero(), jc.IsFalse)
+
+	c.Assert(result.Cleanups[1].EnvironName, gc.Equals, "dummyenv")
+	c.Assert(result.Cleanups[1].EnvironTag, gc.Equals, s.State.EnvironTag().String())
+}
+
+func (s *cleanupManagerSuite) TestRetryCleanups(c *gc.C) {
+	destroyServiceWithUnit(c, s.Factory)
+	list, err := s.cleanupManager.ListCleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list.Cleanups, gc.HasLen, 1)
+	cleanup := list.Cleanups[0]
+
+	result, err := s.cleanupManager.RetryCleanups(params.CleanupIds{
+		Cleanups: []params.CleanupId{
+			{EnvironTag: cleanup.EnvironTag, Id: cleanup.Id},
+			{EnvironTag: cleanup.EnvironTag, Id: "missing"},
+			{EnvironTag: "bad-tag", Id: cleanup.Id},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, jc.Satisfies, params.IsCodeNotFound)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, `"bad-tag" is not a valid tag`)
+
+	list, err = s.cleanupManager.ListCleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	for _, remaining := range list.Cleanups {
+		c.Assert(remaining.Id, gc.Not(gc.Equals), cleanup.Id)
+	}
+}
+
+func (s *cleanupManagerSuite) TestSkipCleanups(c *gc.C) {
+	destroyServiceWithUnit(c, s.Factory)
+	list, err := s.cleanupManager.ListCleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list.Cleanups, gc.HasLen, 1)
+	cleanup := list.Cleanups[0]
+
+	result, err := s.cleanupManager.SkipCleanups(params.CleanupIds{
+		Cleanups: []params.CleanupId{{EnvironTag: cleanup.EnvironTag, Id: cleanup.Id}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), jc.ErrorIsNil)
+
+	list, err = s.cleanupManager.ListCleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list.Cleanups, gc.HasLen, 0)
+}
@@ -23,6 +23,7 @@ import (
 	jjj "github.com/juju/juju/juju"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/version"
 )
 
@@ -72,6 +73,19 @@ func (c *Client) WatchAll() (params.AllWatcherId, error) {
 	}, nil
 }
 
+// WatchAllFiltered returns an AllWatcher that only returns deltas
+// for the entities matching the given filter.
+func (c *Client) WatchAllFiltered(args params.AllWatcherFilter) (params.AllWatcherId, error) {
+	filter := multiwatcher.Filter(args)
+	if err := filter.Validate(); err != nil {
+		return params.AllWatcherId{}, errors.Trace(err)
+	}
+	w := c.api.state.WatchFiltered(filter)
+	return params.AllWatcherId{
+		AllWatcherId: c.api.resources.Register(w),
+	}, nil
+}
+
 // ServiceSet implements the server side of Client.ServiceSet. Values set to an
 // empty string will be unset.
 //
@@ -257,6 +271,10 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 	if err := c.check.ChangeAllowed(); err != nil {
 		return errors.Trace(err)
 	}
+	quotas := c.quotaChecker()
+	if err := quotas.Enforce(quotas.ServiceDeployRequest(args)); err != nil {
+		return errors.Trace(err)
+	}
 	return service.DeployService(c.api.state, c.api.auth.GetAuthTag().String(), args)
 }
 
@@ -311,7 +329,7 @@ func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
 }
 
 // serviceSetCharm sets the charm for the given service.
-func (c *Client) serviceSetCharm(service *state.Service, url string, force bool) error {
+func (c *Client) serviceSetCharm(svc *state.Service, url string, force bool) error {
 	curl, err := charm.ParseURL(url)
 	if err != nil {
 		return err
@@ -321,12 +339,15 @@ func (c *Client) serviceSetCharm(service *state.Service, url string, force bool)
 		// Charms should be added before trying to use them, with
 		// AddCharm or AddLocalCharm API calls. When they're not,
 		// we're reverting to 1.16 compatibility mode.
-		return c.serviceSetCharm1dot16(service, curl, force)
+		return c.serviceSetCharm1dot16(svc, curl, force)
 	}
 	if err != nil {
 		return err
 	}
-	return service.SetCharm(sch, force)
+	if err := service.CheckCharmTrusted(c.api.state, sch); err != nil {
+		return err
+	}
+	return svc.SetCharm(sch, force)
 }
 
 // serviceSetCharm1dot16 sets the charm for the given service in 1.16
@@ -440,6 +461,10 @@ func (c *Client) AddServiceUnitsWithPlacement(args params.AddServiceUnits) (para
 	if err := c.check.ChangeAllowed(); err != nil {
 		return params.AddServiceUnitsResults{}, errors.Trace(err)
 	}
+	quotas := c.quotaChecker()
+	if err := quotas.Enforce(quotas.AddUnitsRequest(args)); err != nil {
+		return params.AddServiceUnitsResults{}, errors.Trace(err)
+	}
 	units, err := addServiceUnits(c.api.state, args)
 	if err != nil {
 		return params.AddServiceUnitsResults{}, err
@@ -584,6 +609,10 @@ func (c *Client) AddMachinesV2(args params.AddMachines) (params.AddMachinesResul
 	if err := c.check.ChangeAllowed(); err != nil {
 		return results, errors.Trace(err)
 	}
+	quotas := c.quotaChecker()
+	if err := quotas.Enforce(quotas.AddMachinesRequests(args.MachineParams)...); err != nil {
+		return results, errors.Trace(err)
+	}
 	for i, p := range args.MachineParams {
 		m, err := c.addOneMachine(p)
 		results.Machines[i].Error = common.ServerError(err)
@@ -787,6 +816,10 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 	}
 
 	for i, arg := range args.Changes {
+		if arg.Group != "" {
+			result.Results[i].Error = common.ServerError(c.shareEnvironmentWithGroup(arg, createdBy))
+			continue
+		}
 		userTagString := arg.UserTag
 		user, err := names.ParseUserTag(userTagString)
 		if err != nil {
@@ -813,6 +846,20 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 	return result, nil
 }
 
+// shareEnvironmentWithGroup shares the environment with, or unshares
+// it from, a group of users.
+func (c *Client) shareEnvironmentWithGroup(arg params.ModifyEnvironUser, createdBy names.UserTag) error {
+	switch arg.Action {
+	case params.AddEnvUser:
+		err := c.api.state.AddEnvironmentGroup(arg.Group, createdBy)
+		return errors.Annotate(err, "could not share environment")
+	case params.RemoveEnvUser:
+		err := c.api.state.RemoveEnvironmentGroup(arg.Group)
+		return errors.Annotate(err, "could not unshare environment")
+	}
+	return errors.Errorf("unknown action %q", arg.Action)
+}
+
 // EnvUserInfo returns information on all users in the environment.
 func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 	var results params.EnvUserInfoResults
@@ -839,6 +886,29 @@ func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 	return results, nil
 }
 
+// WebhookDeliveries returns the recent deliveries of environment
+// events to webhooks, most recent first.
+func (c *Client) WebhookDeliveries() (params.WebhookDeliveryResults, error) {
+	var results params.WebhookDeliveryResults
+	deliveries, err := c.api.state.WebhookDeliveries()
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	results.Deliveries = make([]params.WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		results.Deliveries[i] = params.WebhookDelivery{
+			Event:     d.Event,
+			Entity:    d.Entity,
+			URL:       d.URL,
+			Time:      d.Time,
+			Attempts:  d.Attempts,
+			Delivered: d.Delivered,
+			Error:     d.Error,
+		}
+	}
+	return results, nil
+}
+
 // GetAnnotations returns annotations about a given entity.
 // This API is now deprecated - "Annotations" client should be used instead.
 // TODO(anastasiamac) remove for Juju 2.x
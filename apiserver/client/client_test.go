@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -184,6 +185,33 @@ func (s *serverSuite) TestEnvUsersInfo(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, expected)
 }
 
+func (s *serverSuite) TestWebhookDeliveries(c *gc.C) {
+	now := time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC)
+	err := s.State.AddWebhookDelivery(state.WebhookDelivery{
+		Event:     "unit-error",
+		Entity:    "unit-wordpress-0",
+		URL:       "https://example.com/hook",
+		Time:      now,
+		Attempts:  3,
+		Delivered: false,
+		Error:     "500 Internal Server Error",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.client.WebhookDeliveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.WebhookDeliveryResults{
+		Deliveries: []params.WebhookDelivery{{
+			Event:    "unit-error",
+			Entity:   "unit-wordpress-0",
+			URL:      "https://example.com/hook",
+			Time:     now,
+			Attempts: 3,
+			Error:    "500 Internal Server Error",
+		}},
+	})
+}
+
 // ByUserName implements sort.Interface for []params.EnvUserInfoResult based on
 // the UserName field.
 type ByUserName []params.EnvUserInfoResult
@@ -297,6 +325,45 @@ func (s *serverSuite) TestShareEnvironmentAddRemoteUser(c *gc.C) {
 	c.Assert(envUser.LastConnection(), gc.IsNil)
 }
 
+func (s *serverSuite) TestShareEnvironmentWithGroup(c *gc.C) {
+	_, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	args := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			Group:  "ops",
+			Action: params.AddEnvUser,
+		}}}
+
+	result, err := s.client.ShareEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), gc.IsNil)
+
+	groups, err := s.State.EnvironmentGroups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(groups, jc.DeepEquals, []string{"ops"})
+
+	args.Changes[0].Action = params.RemoveEnvUser
+	result, err = s.client.ShareEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), gc.IsNil)
+
+	groups, err = s.State.EnvironmentGroups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(groups, gc.HasLen, 0)
+}
+
+func (s *serverSuite) TestShareEnvironmentWithMissingGroup(c *gc.C) {
+	args := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			Group:  "ops",
+			Action: params.AddEnvUser,
+		}}}
+
+	result, err := s.client.ShareEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), gc.ErrorMatches, `could not share environment: group "ops" not found`)
+}
+
 func (s *serverSuite) TestShareEnvironmentAddUserTwice(c *gc.C) {
 	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar"})
 	args := params.ModifyEnvironUsers{
@@ -2474,6 +2541,34 @@ func (s *clientSuite) TestClientWatchAll(c *gc.C) {
 	}
 }
 
+func (s *clientSuite) TestClientWatchAllFiltered(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	watcher, err := s.APIState.Client().WatchAllFiltered(params.AllWatcherFilter{
+		Kinds:    []string{"service"},
+		Services: []string{"mysql"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer func() {
+		err := watcher.Stop()
+		c.Assert(err, jc.ErrorIsNil)
+	}()
+	deltas, err := watcher.Next()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deltas, gc.HasLen, 1)
+	c.Assert(deltas[0].Removed, jc.IsFalse)
+	info, ok := deltas[0].Entity.(*multiwatcher.ServiceInfo)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(info.Name, gc.Equals, "mysql")
+}
+
+func (s *clientSuite) TestClientWatchAllFilteredInvalid(c *gc.C) {
+	_, err := s.APIState.Client().WatchAllFiltered(params.AllWatcherFilter{
+		Kinds: []string{"widget"},
+	})
+	c.Assert(err, gc.ErrorMatches, `unknown entity kind "widget"`)
+}
+
 func (s *clientSuite) TestClientSetServiceConstraints(c *gc.C) {
 	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
 
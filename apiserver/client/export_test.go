@@ -4,8 +4,8 @@
 package client
 
 var (
-	RemoteParamsForMachine = remoteParamsForMachine
-	GetAllUnitNames        = getAllUnitNames
+	GetAllUnitNames      = getAllUnitNames
+	RunResultGracePeriod = &runResultGracePeriod
 )
 
 // Filtering exports
@@ -21,3 +21,6 @@ var (
 )
 
 type MachineAndContainers machineAndContainers
+
+// Quota preflight exports
+var NewEnviron = &newEnviron
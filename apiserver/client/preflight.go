@@ -0,0 +1,27 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+)
+
+// newEnviron is called to obtain the environ whose quotas are checked
+// by QuotaPreflight; it's a variable so it can be patched in tests.
+var newEnviron = environs.New
+
+// QuotaPreflight reports whether provisioning the new machines
+// described by args would exceed the environment's remaining provider
+// quotas. Environments whose providers do not report quotas, or that
+// have the quota-preflight setting turned off, are never reported as
+// exceeding them.
+func (c *Client) QuotaPreflight(args params.QuotaPreflight) (params.QuotaPreflightResult, error) {
+	return c.quotaChecker().Check(args)
+}
+
+func (c *Client) quotaChecker() *common.QuotaChecker {
+	return common.NewQuotaChecker(common.NewQuotaBackend(c.api.state), newEnviron)
+}
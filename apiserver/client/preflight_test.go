@@ -0,0 +1,220 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/client"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+type preflightSuite struct {
+	baseSuite
+	client *client.Client
+	env    *mockQuotaEnviron
+}
+
+var _ = gc.Suite(&preflightSuite{})
+
+func (s *preflightSuite) SetUpTest(c *gc.C) {
+	s.baseSuite.SetUpTest(c)
+
+	var err error
+	auth := testing.FakeAuthorizer{
+		Tag:            s.AdminUserTag(c),
+		EnvironManager: true,
+	}
+	s.client, err = client.NewClient(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.env = &mockQuotaEnviron{}
+	s.PatchValue(client.NewEnviron, func(cfg *config.Config) (environs.Environ, error) {
+		env, err := environs.New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.env.Environ = env
+		return s.env, nil
+	})
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func (s *preflightSuite) TestQuotaPreflightWithinQuotas(c *gc.C) {
+	s.env.quotas = environs.Quotas{Instances: intPtr(2)}
+	result, err := s.client.QuotaPreflight(params.QuotaPreflight{Machines: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.QuotaPreflightResult{})
+}
+
+func (s *preflightSuite) TestQuotaPreflightWarn(c *gc.C) {
+	s.env.quotas = environs.Quotas{
+		Instances: intPtr(2),
+		Cores:     intPtr(10),
+		Volumes:   intPtr(1),
+	}
+	result, err := s.client.QuotaPreflight(params.QuotaPreflight{
+		Machines:    3,
+		Constraints: constraints.MustParse("cpu-cores=4"),
+		Volumes:     3,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.QuotaPreflightResult{
+		Exceeded: []string{
+			"3 instances requested, 2 remaining in quota",
+			"12 cores requested, 10 remaining in quota",
+			"3 volumes requested, 1 remaining in quota",
+		},
+	})
+}
+
+func (s *preflightSuite) TestQuotaPreflightRefuse(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"quota-preflight": "refuse"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.env.quotas = environs.Quotas{Instances: intPtr(0)}
+	result, err := s.client.QuotaPreflight(params.QuotaPreflight{Machines: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.QuotaPreflightResult{
+		Exceeded: []string{"1 instances requested, 0 remaining in quota"},
+		Refuse:   true,
+	})
+}
+
+func (s *preflightSuite) TestQuotaPreflightOff(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"quota-preflight": "off"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.env.quotas = environs.Quotas{Instances: intPtr(0)}
+	result, err := s.client.QuotaPreflight(params.QuotaPreflight{Machines: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.QuotaPreflightResult{})
+	c.Assert(s.env.called, jc.IsFalse)
+}
+
+func (s *preflightSuite) TestQuotaPreflightServiceConstraints(c *gc.C) {
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	err := service.SetConstraints(constraints.MustParse("cpu-cores=2"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetEnvironConstraints(constraints.MustParse("cpu-cores=8"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.env.quotas = environs.Quotas{Cores: intPtr(3)}
+	result, err := s.client.QuotaPreflight(params.QuotaPreflight{
+		Machines:    2,
+		ServiceName: "dummy",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Exceeded, jc.DeepEquals, []string{"4 cores requested, 3 remaining in quota"})
+}
+
+func (s *preflightSuite) TestQuotaPreflightQuotasError(c *gc.C) {
+	s.env.err = errors.New("boom")
+	_, err := s.client.QuotaPreflight(params.QuotaPreflight{Machines: 1})
+	c.Assert(err, gc.ErrorMatches, "cannot get provider quotas: boom")
+}
+
+func (s *preflightSuite) TestQuotaPreflightNotSupported(c *gc.C) {
+	// The dummy provider does not report quotas.
+	s.PatchValue(client.NewEnviron, environs.New)
+	result, err := s.client.QuotaPreflight(params.QuotaPreflight{Machines: 100})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.QuotaPreflightResult{})
+}
+
+func (s *preflightSuite) setRefuseNoInstances(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"quota-preflight": "refuse"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.env.quotas = environs.Quotas{Instances: intPtr(0)}
+}
+
+func (s *preflightSuite) TestServiceDeployRefused(c *gc.C) {
+	s.setRefuseNoInstances(c)
+	ch := s.AddTestingCharm(c, "dummy")
+	err := s.client.ServiceDeploy(params.ServiceDeploy{
+		ServiceName: "dummy",
+		CharmUrl:    ch.URL().String(),
+		NumUnits:    1,
+	})
+	c.Assert(err, gc.ErrorMatches, `request would exceed provider quotas: 1 instances requested, 0 remaining in quota`)
+	_, err = s.State.Service("dummy")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *preflightSuite) TestAddServiceUnitsRefused(c *gc.C) {
+	s.setRefuseNoInstances(c)
+	s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	_, err := s.client.AddServiceUnits(params.AddServiceUnits{
+		ServiceName: "dummy",
+		NumUnits:    1,
+	})
+	c.Assert(err, gc.ErrorMatches, `request would exceed provider quotas: 1 instances requested, 0 remaining in quota`)
+
+	// Units placed on existing machines need no new instances.
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.client.AddServiceUnits(params.AddServiceUnits{
+		ServiceName:   "dummy",
+		NumUnits:      1,
+		ToMachineSpec: m.Id(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *preflightSuite) TestAddMachinesRefused(c *gc.C) {
+	s.setRefuseNoInstances(c)
+	jobs := []multiwatcher.MachineJob{multiwatcher.JobHostUnits}
+	_, err := s.client.AddMachines(params.AddMachines{
+		MachineParams: []params.AddMachineParams{{Jobs: jobs}},
+	})
+	c.Assert(err, gc.ErrorMatches, `request would exceed provider quotas: 1 instances requested, 0 remaining in quota`)
+
+	// Containers on existing machines need no new instances.
+	m, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	results, err := s.client.AddMachines(params.AddMachines{
+		MachineParams: []params.AddMachineParams{{
+			Jobs:          jobs,
+			ContainerType: instance.LXC,
+			ParentId:      m.Id(),
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Machines, gc.HasLen, 1)
+	c.Assert(results.Machines[0].Error, gc.IsNil)
+}
+
+func (s *preflightSuite) TestWarnModeNotEnforced(c *gc.C) {
+	s.env.quotas = environs.Quotas{Instances: intPtr(0)}
+	results, err := s.client.AddMachines(params.AddMachines{
+		MachineParams: []params.AddMachineParams{{
+			Jobs: []multiwatcher.MachineJob{multiwatcher.JobHostUnits},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Machines[0].Error, gc.IsNil)
+	c.Assert(s.env.called, jc.IsFalse)
+}
+
+type mockQuotaEnviron struct {
+	environs.Environ
+	quotas environs.Quotas
+	err    error
+	called bool
+}
+
+func (e *mockQuotaEnviron) Quotas() (environs.Quotas, error) {
+	e.called = true
+	return e.quotas, e.err
+}
@@ -5,43 +5,18 @@ package client
 
 import (
 	"fmt"
-	"path/filepath"
 	"sort"
-	"sync"
 	"time"
 
 	"github.com/juju/errors"
-	"github.com/juju/utils"
 	"github.com/juju/utils/set"
 
-	"github.com/juju/juju/agent"
-	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
-	"github.com/juju/juju/network"
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/state"
-	"github.com/juju/juju/utils/ssh"
+	"github.com/juju/juju/state/watcher"
 )
 
-// remoteParamsForMachine returns a filled in RemoteExec instance
-// based on the machine, command and timeout params.  If the machine
-// does not have an internal address, the Host is empty. This is caught
-// by the function that actually tries to execute the command.
-func remoteParamsForMachine(machine *state.Machine, command string, timeout time.Duration) *RemoteExec {
-	// magic boolean parameters are bad :-(
-	address := network.SelectInternalAddress(machine.Addresses(), false)
-	execParams := &RemoteExec{
-		ExecParams: ssh.ExecParams{
-			Command: command,
-			Timeout: timeout,
-		},
-		MachineId: machine.Id(),
-	}
-	if address != "" {
-		execParams.Host = fmt.Sprintf("ubuntu@%s", address)
-	}
-	return execParams
-}
-
 // getAllUnitNames returns a sequence of valid Unit objects from state. If any
 // of the service names or unit names are not found, an error is returned.
 func getAllUnitNames(st *state.State, units, services []string) (result []*state.Unit, err error) {
@@ -73,12 +48,20 @@ func getAllUnitNames(st *state.State, units, services []string) (result []*state
 	return result, nil
 }
 
-func (c *Client) getDataDir() string {
-	dataResource, ok := c.api.resources.Get("dataDir").(common.StringResource)
-	if !ok {
-		return ""
-	}
-	return dataResource.String()
+// defaultRunTimeout is the time commands are allowed to run for when
+// no timeout is given.
+const defaultRunTimeout = 5 * time.Minute
+
+// runResultGracePeriod is how long to wait for an agent to report the
+// outcome of its commands beyond their timeout. It allows for the time
+// taken to deliver the commands and to acquire the hook execution lock.
+var runResultGracePeriod = time.Minute
+
+// runTarget identifies a unit or machine on which to run commands.
+type runTarget struct {
+	receiver  state.ActionReceiver
+	machineId string
+	unitId    string
 }
 
 // Run the commands specified on the machines identified through the
@@ -91,35 +74,31 @@ func (c *Client) Run(run params.RunParams) (results params.RunResults, err error
 	if err != nil {
 		return results, err
 	}
-	// We want to create a RemoteExec for each unit and each machine.
-	// If we have both a unit and a machine request, we run it twice,
-	// once for the unit inside the exec context using juju-run, and
-	// the other outside the context just using bash.
-	var params []*RemoteExec
-	var quotedCommands = utils.ShQuote(run.Commands)
+	// Commands for units are run by the unit agents, in a hook
+	// context; commands for machines are run by the machine agents,
+	// outside of any context. If both a unit and its machine are
+	// requested, the commands run twice.
+	var targets []runTarget
 	for _, unit := range units {
-		// We know that the unit is both a principal unit, and that it has an
-		// assigned machine.
+		// We know that the unit has an assigned machine.
 		machineId, _ := unit.AssignedMachineId()
-		machine, err := c.api.state.Machine(machineId)
-		if err != nil {
-			return results, err
-		}
-		command := fmt.Sprintf("juju-run %s %s", unit.Name(), quotedCommands)
-		execParam := remoteParamsForMachine(machine, command, run.Timeout)
-		execParam.UnitId = unit.Name()
-		params = append(params, execParam)
+		targets = append(targets, runTarget{
+			receiver:  unit,
+			machineId: machineId,
+			unitId:    unit.Name(),
+		})
 	}
 	for _, machineId := range run.Machines {
 		machine, err := c.api.state.Machine(machineId)
 		if err != nil {
 			return results, err
 		}
-		command := fmt.Sprintf("juju-run --no-context %s", quotedCommands)
-		execParam := remoteParamsForMachine(machine, command, run.Timeout)
-		params = append(params, execParam)
+		targets = append(targets, runTarget{
+			receiver:  machine,
+			machineId: machine.Id(),
+		})
 	}
-	return ParallelExecute(c.getDataDir(), params), nil
+	return runOnTargets(c.api.state, targets, run.Commands, run.Timeout)
 }
 
 // RunOnAllMachines attempts to run the specified command on all the machines.
@@ -131,58 +110,115 @@ func (c *Client) RunOnAllMachines(run params.RunParams) (params.RunResults, erro
 	if err != nil {
 		return params.RunResults{}, err
 	}
-	var params []*RemoteExec
-	quotedCommands := utils.ShQuote(run.Commands)
-	command := fmt.Sprintf("juju-run --no-context %s", quotedCommands)
+	var targets []runTarget
 	for _, machine := range machines {
-		params = append(params, remoteParamsForMachine(machine, command, run.Timeout))
+		targets = append(targets, runTarget{
+			receiver:  machine,
+			machineId: machine.Id(),
+		})
 	}
-	return ParallelExecute(c.getDataDir(), params), nil
+	return runOnTargets(c.api.state, targets, run.Commands, run.Timeout)
 }
 
-// RemoteExec extends the standard ssh.ExecParams by providing the machine and
-// perhaps the unit ids.  These are then returned in the params.RunResult return
-// values.
-type RemoteExec struct {
-	ssh.ExecParams
-	MachineId string
-	UnitId    string
-}
+// runOnTargets queues a juju-run action for each target, delivered to
+// the agents over their API connections, and waits for the agents to
+// report the outcome. Targets that do not report within the timeout
+// are given an error result, and their actions are cancelled if they
+// have not yet started.
+func runOnTargets(st *state.State, targets []runTarget, commands string, timeout time.Duration) (params.RunResults, error) {
+	if len(targets) == 0 {
+		return params.RunResults{}, nil
+	}
+	if timeout <= 0 {
+		timeout = defaultRunTimeout
+	}
+	results := make([]params.RunResult, len(targets))
+	receivers := make([]state.ActionReceiver, len(targets))
+	for i, target := range targets {
+		results[i].MachineId = target.machineId
+		results[i].UnitId = target.unitId
+		receivers[i] = target.receiver
+	}
+
+	// Start watching before queueing the actions, so that no
+	// completions are missed.
+	w := st.WatchActionResultsFilteredBy(receivers...)
+	defer func() {
+		if err := w.Stop(); err != nil {
+			logger.Errorf("cannot stop action results watcher: %v", err)
+		}
+	}()
 
-// ParallelExecute executes all of the requests defined in the params,
-// using the system identity stored in the dataDir.
-func ParallelExecute(dataDir string, runParams []*RemoteExec) params.RunResults {
-	logger.Debugf("exec %#v", runParams)
-	var outstanding sync.WaitGroup
-	var lock sync.Mutex
-	var result []params.RunResult
-	identity := filepath.Join(dataDir, agent.SystemIdentity)
-	for _, param := range runParams {
-		outstanding.Add(1)
-		logger.Debugf("exec on %s: %#v", param.MachineId, *param)
-		param.IdentityFile = identity
-		go func(param *RemoteExec) {
-			response, err := ssh.ExecuteCommandOnMachine(param.ExecParams)
-			logger.Debugf("reponse from %s: %v (err:%v)", param.MachineId, response, err)
-			execResponse := params.RunResult{
-				ExecResponse: response,
-				MachineId:    param.MachineId,
-				UnitId:       param.UnitId,
+	pending := make(map[string]int)
+	actionParams := jujurun.ActionParams(commands, timeout)
+	for i, target := range targets {
+		action, err := target.receiver.AddAction(jujurun.ActionName, actionParams)
+		if err != nil {
+			results[i].Error = fmt.Sprintf("cannot queue commands: %v", err)
+			continue
+		}
+		pending[action.Id()] = i
+	}
+
+	deadline := time.After(timeout + runResultGracePeriod)
+	for len(pending) > 0 {
+		select {
+		case ids, ok := <-w.Changes():
+			if !ok {
+				return params.RunResults{}, watcher.EnsureErr(w)
 			}
-			if err != nil {
-				execResponse.Error = fmt.Sprint(err)
+			for _, id := range ids {
+				i, ok := pending[id]
+				if !ok {
+					continue
+				}
+				action, err := st.Action(id)
+				if err != nil {
+					return params.RunResults{}, errors.Trace(err)
+				}
+				setRunResult(&results[i], action)
+				delete(pending, id)
 			}
+		case <-deadline:
+			for id, i := range pending {
+				results[i].Error = "timed out waiting for result"
+				if err := cancelPendingAction(st, targets[i].receiver, id); err != nil {
+					logger.Warningf("cannot cancel action %q: %v", id, err)
+				}
+			}
+			pending = nil
+		}
+	}
+	sort.Sort(MachineOrder(results))
+	return params.RunResults{Results: results}, nil
+}
 
-			lock.Lock()
-			defer lock.Unlock()
-			result = append(result, execResponse)
-			outstanding.Done()
-		}(param)
+// setRunResult records the outcome of a finished juju-run action.
+func setRunResult(result *params.RunResult, action *state.Action) {
+	actionResults, message := action.Results()
+	if response, err := jujurun.ParseResults(actionResults); err == nil {
+		result.ExecResponse = *response
+	}
+	if action.Status() != state.ActionCompleted {
+		if message == "" {
+			message = string(action.Status())
+		}
+		result.Error = message
 	}
+}
 
-	outstanding.Wait()
-	sort.Sort(MachineOrder(result))
-	return params.RunResults{result}
+// cancelPendingAction cancels the action with the given id, unless an
+// agent has already started running it.
+func cancelPendingAction(st *state.State, receiver state.ActionReceiver, id string) error {
+	action, err := st.Action(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if action.Status() != state.ActionPending {
+		return nil
+	}
+	_, err = receiver.CancelAction(action)
+	return errors.Trace(err)
 }
 
 // MachineOrder is used to provide the api to sort the results by the machine
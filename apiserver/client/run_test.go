@@ -7,17 +7,15 @@ import (
 	"fmt"
 	"time"
 
-	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils/exec"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/client"
 	"github.com/juju/juju/apiserver/params"
-	"github.com/juju/juju/network"
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
-	"github.com/juju/juju/utils/ssh"
 )
 
 type runSuite struct {
@@ -32,44 +30,11 @@ func (s *runSuite) addMachine(c *gc.C) *state.Machine {
 	return machine
 }
 
-func (s *runSuite) addMachineWithAddress(c *gc.C, address string) *state.Machine {
-	machine := s.addMachine(c)
-	machine.SetProviderAddresses(network.NewAddress(address))
-	return machine
-}
-
-func (s *runSuite) TestRemoteParamsForMachinePopulates(c *gc.C) {
-	machine := s.addMachine(c)
-	result := client.RemoteParamsForMachine(machine, "command", time.Minute)
-	c.Assert(result.Command, gc.Equals, "command")
-	c.Assert(result.Timeout, gc.Equals, time.Minute)
-	c.Assert(result.MachineId, gc.Equals, machine.Id())
-	// Now an empty host isn't particularly useful, but the machine doesn't
-	// have an address to use.
-	c.Assert(machine.Addresses(), gc.HasLen, 0)
-	c.Assert(result.Host, gc.Equals, "")
-}
-
-func (s *runSuite) TestRemoteParamsForMachinePopulatesWithAddress(c *gc.C) {
-	machine := s.addMachineWithAddress(c, "10.3.2.1")
-
-	result := client.RemoteParamsForMachine(machine, "command", time.Minute)
-	c.Assert(result.Command, gc.Equals, "command")
-	c.Assert(result.Timeout, gc.Equals, time.Minute)
-	c.Assert(result.MachineId, gc.Equals, machine.Id())
-	c.Assert(result.Host, gc.Equals, "ubuntu@10.3.2.1")
-}
-
 func (s *runSuite) addUnit(c *gc.C, service *state.Service) *state.Unit {
 	unit, err := service.AddUnit()
 	c.Assert(err, jc.ErrorIsNil)
 	err = unit.AssignToNewMachine()
 	c.Assert(err, jc.ErrorIsNil)
-	mId, err := unit.AssignedMachineId()
-	c.Assert(err, jc.ErrorIsNil)
-	machine, err := s.State.Machine(mId)
-	c.Assert(err, jc.ErrorIsNil)
-	machine.SetProviderAddresses(network.NewAddress("10.3.2.1"))
 	return unit
 }
 
@@ -159,81 +124,67 @@ func (s *runSuite) TestGetAllUnitNames(c *gc.C) {
 	}
 }
 
-func (s *runSuite) mockSSH(c *gc.C, cmd string) {
-	gitjujutesting.PatchExecutable(c, s, "ssh", cmd)
-	gitjujutesting.PatchExecutable(c, s, "scp", cmd)
-	client, _ := ssh.NewOpenSSHClient()
-	s.PatchValue(&ssh.DefaultClient, client)
-}
-
-func (s *runSuite) TestParallelExecuteErrorsOnBlankHost(c *gc.C) {
-	s.mockSSH(c, echoInputShowArgs)
-
-	params := []*client.RemoteExec{
-		{
-			ExecParams: ssh.ExecParams{
-				Command: "foo",
-				Timeout: testing.LongWait,
-			},
-		},
+// fakeAgents stands in for the unit and machine agents, completing the
+// juju-run actions queued for the given receivers with the receivers'
+// ids as output. The returned function stops the fake agents.
+func (s *runSuite) fakeAgents(c *gc.C, receivers ...state.ActionReceiver) func() {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			for _, receiver := range receivers {
+				actions, err := receiver.PendingActions()
+				c.Check(err, jc.ErrorIsNil)
+				for _, action := range actions {
+					c.Check(action.Name(), gc.Equals, jujurun.ActionName)
+					response := &exec.ExecResponse{Stdout: []byte(receiver.Tag().Id())}
+					_, err := action.Finish(state.ActionResults{
+						Status:  state.ActionCompleted,
+						Results: jujurun.ActionResults(response),
+					})
+					c.Check(err, jc.ErrorIsNil)
+				}
+			}
+			s.BackingState.StartSync()
+			select {
+			case <-done:
+				return
+			case <-time.After(testing.ShortWait):
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
 	}
-
-	runResults := client.ParallelExecute("/some/dir", params)
-	c.Assert(runResults.Results, gc.HasLen, 1)
-	result := runResults.Results[0]
-	c.Assert(result.Error, gc.Equals, "missing host address")
 }
 
-func (s *runSuite) TestParallelExecuteAddsIdentity(c *gc.C) {
-	s.mockSSH(c, echoInputShowArgs)
-
-	params := []*client.RemoteExec{
-		{
-			ExecParams: ssh.ExecParams{
-				Host:    "localhost",
-				Command: "foo",
-				Timeout: testing.LongWait,
-			},
-		},
-	}
-
-	runResults := client.ParallelExecute("/some/dir", params)
-	c.Assert(runResults.Results, gc.HasLen, 1)
-	result := runResults.Results[0]
-	c.Assert(result.Error, gc.Equals, "")
-	c.Assert(string(result.Stderr), jc.Contains, "system-identity")
+type expectedRunResult struct {
+	machineId string
+	unitId    string
+	stdout    string
+	err       string
 }
 
-func (s *runSuite) TestParallelExecuteCopiesAcrossMachineAndUnit(c *gc.C) {
-	s.mockSSH(c, echoInputShowArgs)
-
-	params := []*client.RemoteExec{
-		{
-			ExecParams: ssh.ExecParams{
-				Host:    "localhost",
-				Command: "foo",
-				Timeout: testing.LongWait,
-			},
-			MachineId: "machine-id",
-			UnitId:    "unit-id",
-		},
+func assertRunResults(c *gc.C, results []params.RunResult, expected []expectedRunResult) {
+	c.Assert(results, gc.HasLen, len(expected))
+	for i, result := range results {
+		c.Check(result.MachineId, gc.Equals, expected[i].machineId)
+		c.Check(result.UnitId, gc.Equals, expected[i].unitId)
+		c.Check(string(result.Stdout), gc.Equals, expected[i].stdout)
+		c.Check(result.Error, gc.Equals, expected[i].err)
 	}
-
-	runResults := client.ParallelExecute("/some/dir", params)
-	c.Assert(runResults.Results, gc.HasLen, 1)
-	result := runResults.Results[0]
-	c.Assert(result.Error, gc.Equals, "")
-	c.Assert(result.MachineId, gc.Equals, "machine-id")
-	c.Assert(result.UnitId, gc.Equals, "unit-id")
 }
 
 func (s *runSuite) TestRunOnAllMachines(c *gc.C) {
 	// Make three machines.
-	s.addMachineWithAddress(c, "10.3.2.1")
-	s.addMachineWithAddress(c, "10.3.2.2")
-	s.addMachineWithAddress(c, "10.3.2.3")
-
-	s.mockSSH(c, echoInput)
+	var receivers []state.ActionReceiver
+	for i := 0; i < 3; i++ {
+		receivers = append(receivers, s.addMachine(c))
+	}
+	stop := s.fakeAgents(c, receivers...)
+	defer stop()
 
 	// hmm... this seems to be going through the api client, and from there
 	// through to the apiserver implementation. Not ideal, but it is how the
@@ -241,28 +192,78 @@ func (s *runSuite) TestRunOnAllMachines(c *gc.C) {
 	client := s.APIState.Client()
 	results, err := client.RunOnAllMachines("hostname", testing.LongWait)
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(results, gc.HasLen, 3)
 
-	var expectedResults []params.RunResult
+	var expected []expectedRunResult
 	for i := 0; i < 3; i++ {
-		expectedResults = append(expectedResults,
-			params.RunResult{
-				ExecResponse: exec.ExecResponse{Stdout: []byte(expectedCommand[0])},
-
-				MachineId: fmt.Sprint(i),
-			})
+		id := fmt.Sprint(i)
+		expected = append(expected, expectedRunResult{machineId: id, stdout: id})
 	}
+	assertRunResults(c, results, expected)
+}
+
+func (s *runSuite) TestRunOnAllMachinesTimesOut(c *gc.C) {
+	s.PatchValue(client.RunResultGracePeriod, time.Duration(0))
+	machine := s.addMachine(c)
+
+	results, err := s.APIState.Client().RunOnAllMachines("hostname", testing.ShortWait)
+	c.Assert(err, jc.ErrorIsNil)
+	assertRunResults(c, results, []expectedRunResult{{
+		machineId: "0",
+		err:       "timed out waiting for result",
+	}})
 
-	c.Assert(results, jc.DeepEquals, expectedResults)
+	// The action that was never started has been cancelled.
+	pending, err := machine.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 0)
+	completed, err := machine.CompletedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(completed, gc.HasLen, 1)
+	c.Assert(completed[0].Status(), gc.Equals, state.ActionCancelled)
+}
+
+func (s *runSuite) TestRunReportsFailedCommands(c *gc.C) {
+	machine := s.addMachine(c)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for a := testing.LongAttempt.Start(); a.Next(); {
+			s.BackingState.StartSync()
+			actions, err := machine.PendingActions()
+			c.Check(err, jc.ErrorIsNil)
+			if len(actions) == 0 {
+				continue
+			}
+			response := &exec.ExecResponse{Code: 1, Stderr: []byte("partial")}
+			_, err = actions[0].Finish(state.ActionResults{
+				Status:  state.ActionFailed,
+				Results: jujurun.ActionResults(response),
+				Message: "commands timed out",
+			})
+			c.Check(err, jc.ErrorIsNil)
+			s.BackingState.StartSync()
+			return
+		}
+	}()
+	defer func() { <-done }()
+
+	results, err := s.APIState.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Timeout:  testing.LongWait,
+		Machines: []string{machine.Id()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Code, gc.Equals, 1)
+	c.Assert(string(results[0].Stderr), gc.Equals, "partial")
+	c.Assert(results[0].Error, gc.Equals, "commands timed out")
 }
 
 func (s *runSuite) TestBlockRunOnAllMachines(c *gc.C) {
 	// Make three machines.
-	s.addMachineWithAddress(c, "10.3.2.1")
-	s.addMachineWithAddress(c, "10.3.2.2")
-	s.addMachineWithAddress(c, "10.3.2.3")
-
-	s.mockSSH(c, echoInput)
+	s.addMachine(c)
+	s.addMachine(c)
+	s.addMachine(c)
 
 	// block all changes
 	s.BlockAllChanges(c, "TestBlockRunOnAllMachines")
@@ -272,16 +273,17 @@ func (s *runSuite) TestBlockRunOnAllMachines(c *gc.C) {
 
 func (s *runSuite) TestRunMachineAndService(c *gc.C) {
 	// Make three machines.
-	s.addMachineWithAddress(c, "10.3.2.1")
+	machine := s.addMachine(c)
 
 	charm := s.AddTestingCharm(c, "dummy")
 	owner := s.Factory.MakeUser(c, nil).Tag()
 	magic, err := s.State.AddService("magic", owner.String(), charm, nil, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	s.addUnit(c, magic)
-	s.addUnit(c, magic)
+	unit0 := s.addUnit(c, magic)
+	unit1 := s.addUnit(c, magic)
 
-	s.mockSSH(c, echoInput)
+	stop := s.fakeAgents(c, machine, unit0, unit1)
+	defer stop()
 
 	// hmm... this seems to be going through the api client, and from there
 	// through to the apiserver implementation. Not ideal, but it is how the
@@ -295,31 +297,16 @@ func (s *runSuite) TestRunMachineAndService(c *gc.C) {
 			Services: []string{"magic"},
 		})
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(results, gc.HasLen, 3)
-
-	expectedResults := []params.RunResult{
-		{
-			ExecResponse: exec.ExecResponse{Stdout: []byte(expectedCommand[0])},
-			MachineId:    "0",
-		},
-		{
-			ExecResponse: exec.ExecResponse{Stdout: []byte(expectedCommand[1])},
-			MachineId:    "1",
-			UnitId:       "magic/0",
-		},
-		{
-			ExecResponse: exec.ExecResponse{Stdout: []byte(expectedCommand[2])},
-			MachineId:    "2",
-			UnitId:       "magic/1",
-		},
-	}
-
-	c.Assert(results, jc.DeepEquals, expectedResults)
+	assertRunResults(c, results, []expectedRunResult{
+		{machineId: "0", stdout: "0"},
+		{machineId: "1", unitId: "magic/0", stdout: "magic/0"},
+		{machineId: "2", unitId: "magic/1", stdout: "magic/1"},
+	})
 }
 
 func (s *runSuite) TestBlockRunMachineAndService(c *gc.C) {
 	// Make three machines.
-	s.addMachineWithAddress(c, "10.3.2.1")
+	s.addMachine(c)
 
 	charm := s.AddTestingCharm(c, "dummy")
 	owner := s.Factory.MakeUser(c, nil).Tag()
@@ -328,8 +315,6 @@ func (s *runSuite) TestBlockRunMachineAndService(c *gc.C) {
 	s.addUnit(c, magic)
 	s.addUnit(c, magic)
 
-	s.mockSSH(c, echoInput)
-
 	// hmm... this seems to be going through the api client, and from there
 	// through to the apiserver implementation. Not ideal, but it is how the
 	// other client tests are written.
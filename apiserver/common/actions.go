@@ -0,0 +1,34 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// ParamsActionExecutionResultsToStateActionResults does exactly what
+// the name implies.
+func ParamsActionExecutionResultsToStateActionResults(arg params.ActionExecutionResult) (state.ActionResults, error) {
+	var status state.ActionStatus
+	switch arg.Status {
+	case params.ActionCancelled:
+		status = state.ActionCancelled
+	case params.ActionCompleted:
+		status = state.ActionCompleted
+	case params.ActionFailed:
+		status = state.ActionFailed
+	case params.ActionPending:
+		status = state.ActionPending
+	default:
+		return state.ActionResults{}, errors.Errorf("unrecognized action status '%s'", arg.Status)
+	}
+	return state.ActionResults{
+		Status:  status,
+		Results: arg.Results,
+		Message: arg.Message,
+	}, nil
+}
@@ -12,13 +12,16 @@ import (
 
 // AddressAndCertGetter can be used to find out
 // state server addresses and the CA public certificate.
+// The API addresses and CA certificate are those the
+// environment's agents should use, which are the target
+// system's while the environment is being migrated.
 type AddressAndCertGetter interface {
 	Addresses() ([]string, error)
 	APIAddressesFromMachines() ([]string, error)
-	CACert() string
+	AgentCACert() (string, error)
 	EnvironUUID() string
-	APIHostPorts() ([][]network.HostPort, error)
-	WatchAPIHostPorts() state.NotifyWatcher
+	AgentAPIHostPorts() ([][]network.HostPort, error)
+	WatchAgentAPIHostPorts() state.NotifyWatcher
 }
 
 // APIAddresser implements the APIAddresses method
@@ -38,7 +41,7 @@ func NewAPIAddresser(getter AddressAndCertGetter, resources *Resources) *APIAddr
 
 // APIHostPorts returns the API server addresses.
 func (api *APIAddresser) APIHostPorts() (params.APIHostPortsResult, error) {
-	servers, err := api.getter.APIHostPorts()
+	servers, err := api.getter.AgentAPIHostPorts()
 	if err != nil {
 		return params.APIHostPortsResult{}, err
 	}
@@ -49,7 +52,7 @@ func (api *APIAddresser) APIHostPorts() (params.APIHostPortsResult, error) {
 
 // WatchAPIHostPorts watches the API server addresses.
 func (api *APIAddresser) WatchAPIHostPorts() (params.NotifyWatchResult, error) {
-	watch := api.getter.WatchAPIHostPorts()
+	watch := api.getter.WatchAgentAPIHostPorts()
 	if _, ok := <-watch.Changes(); ok {
 		return params.NotifyWatchResult{
 			NotifyWatcherId: api.resources.Register(watch),
@@ -60,7 +63,7 @@ func (api *APIAddresser) WatchAPIHostPorts() (params.NotifyWatchResult, error) {
 
 // APIAddresses returns the list of addresses used to connect to the API.
 func (api *APIAddresser) APIAddresses() (params.StringsResult, error) {
-	apiHostPorts, err := api.getter.APIHostPorts()
+	apiHostPorts, err := api.getter.AgentAPIHostPorts()
 	if err != nil {
 		return params.StringsResult{}, err
 	}
@@ -77,10 +80,14 @@ func (api *APIAddresser) APIAddresses() (params.StringsResult, error) {
 }
 
 // CACert returns the certificate used to validate the state connection.
-func (a *APIAddresser) CACert() params.BytesResult {
-	return params.BytesResult{
-		Result: []byte(a.getter.CACert()),
+func (a *APIAddresser) CACert() (params.BytesResult, error) {
+	cert, err := a.getter.AgentCACert()
+	if err != nil {
+		return params.BytesResult{}, err
 	}
+	return params.BytesResult{
+		Result: []byte(cert),
+	}, nil
 }
 
 // EnvironUUID returns the environment UUID to connect to the environment
@@ -47,7 +47,8 @@ func (s *apiAddresserSuite) TestAPIAddresses(c *gc.C) {
 }
 
 func (s *apiAddresserSuite) TestCACert(c *gc.C) {
-	result := s.addresser.CACert()
+	result, err := s.addresser.CACert()
+	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(string(result.Result), gc.Equals, "a cert")
 }
 
@@ -68,21 +69,21 @@ func (fakeAddresses) APIAddressesFromMachines() ([]string, error) {
 	panic("should never be called")
 }
 
-func (fakeAddresses) CACert() string {
-	return "a cert"
+func (fakeAddresses) AgentCACert() (string, error) {
+	return "a cert", nil
 }
 
 func (fakeAddresses) EnvironUUID() string {
 	return "the environ uuid"
 }
 
-func (fakeAddresses) APIHostPorts() ([][]network.HostPort, error) {
+func (fakeAddresses) AgentAPIHostPorts() ([][]network.HostPort, error) {
 	return [][]network.HostPort{
 		network.NewHostPorts(1, "apiaddresses"),
 		network.NewHostPorts(2, "apiaddresses"),
 	}, nil
 }
 
-func (fakeAddresses) WatchAPIHostPorts() state.NotifyWatcher {
+func (fakeAddresses) WatchAgentAPIHostPorts() state.NotifyWatcher {
 	panic("should never be called")
 }
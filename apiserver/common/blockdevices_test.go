@@ -0,0 +1,64 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+)
+
+type blockDevicesSuite struct{}
+
+var _ = gc.Suite(&blockDevicesSuite{})
+
+var testBlockDevices = []state.BlockDeviceInfo{{
+	DeviceName: "sda",
+	BusAddress: "scsi@2:0.0.0",
+}, {
+	DeviceName: "sdb",
+	HardwareId: "serial-1",
+}, {
+	DeviceName: "sdc",
+	BusAddress: "scsi@5:0.0.1",
+}}
+
+func (s *blockDevicesSuite) TestMatchingBlockDeviceHardwareId(c *gc.C) {
+	dev, ok := common.MatchingBlockDevice(
+		testBlockDevices,
+		state.VolumeInfo{HardwareId: "serial-1"},
+		state.VolumeAttachmentInfo{BusAddress: "scsi@5:0.0.1"},
+	)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(dev.DeviceName, gc.Equals, "sdb")
+}
+
+func (s *blockDevicesSuite) TestMatchingBlockDeviceBusAddress(c *gc.C) {
+	dev, ok := common.MatchingBlockDevice(
+		testBlockDevices,
+		state.VolumeInfo{},
+		state.VolumeAttachmentInfo{DeviceName: "sda", BusAddress: "scsi@5:0.0.1"},
+	)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(dev.DeviceName, gc.Equals, "sdc")
+
+	_, ok = common.MatchingBlockDevice(
+		testBlockDevices,
+		state.VolumeInfo{},
+		state.VolumeAttachmentInfo{BusAddress: "scsi@5:0.0.2"},
+	)
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *blockDevicesSuite) TestMatchingBlockDeviceDeviceName(c *gc.C) {
+	dev, ok := common.MatchingBlockDevice(
+		testBlockDevices,
+		state.VolumeInfo{},
+		state.VolumeAttachmentInfo{DeviceName: "sda"},
+	)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(dev.DeviceName, gc.Equals, "sda")
+}
@@ -39,6 +39,10 @@ type Authorizer interface {
 	// is a client user.
 	AuthClient() bool
 
+	// AuthAPIToken returns whether the authenticated entity logged
+	// in with an API token rather than its password.
+	AuthAPIToken() bool
+
 	// GetAuthTag returns the tag of the authenticated entity.
 	GetAuthTag() names.Tag
 }
@@ -0,0 +1,276 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/poolmanager"
+	"github.com/juju/juju/storage/provider/registry"
+)
+
+// QuotaBackend defines the state methods needed to check requests for
+// new machines against an environment's provider quotas.
+type QuotaBackend interface {
+	EnvironConfig() (*config.Config, error)
+	EnvironConstraints() (constraints.Value, error)
+
+	// ServiceConstraints returns the constraints of the named
+	// service.
+	ServiceConstraints(serviceName string) (constraints.Value, error)
+
+	// StoragePoolManager returns the manager of the environment's
+	// storage pools.
+	StoragePoolManager() poolmanager.PoolManager
+}
+
+// NewQuotaBackend returns a QuotaBackend that reads from st.
+func NewQuotaBackend(st *state.State) QuotaBackend {
+	return quotaStateShim{st}
+}
+
+type quotaStateShim struct {
+	*state.State
+}
+
+func (s quotaStateShim) ServiceConstraints(serviceName string) (constraints.Value, error) {
+	service, err := s.State.Service(serviceName)
+	if err != nil {
+		return constraints.Value{}, errors.Trace(err)
+	}
+	return service.Constraints()
+}
+
+func (s quotaStateShim) StoragePoolManager() poolmanager.PoolManager {
+	return poolmanager.New(state.NewStateSettings(s.State))
+}
+
+// QuotaChecker checks requests for new machines against the provider
+// quotas of an environment.
+type QuotaChecker struct {
+	backend    QuotaBackend
+	newEnviron func(*config.Config) (environs.Environ, error)
+}
+
+// NewQuotaChecker returns a QuotaChecker for the environment of the
+// given backend, whose quotas are read from the environ returned by
+// newEnviron.
+func NewQuotaChecker(backend QuotaBackend, newEnviron func(*config.Config) (environs.Environ, error)) *QuotaChecker {
+	return &QuotaChecker{backend, newEnviron}
+}
+
+// Check reports whether provisioning all of the new machines described
+// by the given requests would exceed the environment's remaining
+// provider quotas. Environments whose providers do not report quotas,
+// or that have the quota-preflight setting turned off, are never
+// reported as exceeding them.
+func (q *QuotaChecker) Check(requests ...params.QuotaPreflight) (params.QuotaPreflightResult, error) {
+	var result params.QuotaPreflightResult
+	var quotaRequest environs.QuotaRequest
+	for _, args := range requests {
+		if args.Machines <= 0 {
+			continue
+		}
+		cores, err := q.coresPerMachine(args)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		quotaRequest.Instances += args.Machines
+		quotaRequest.Cores += args.Machines * cores
+		quotaRequest.Volumes += args.Volumes
+		quotaRequest.IPAddresses += args.Machines
+	}
+	if quotaRequest.Instances == 0 {
+		return result, nil
+	}
+	cfg, err := q.backend.EnvironConfig()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	mode := cfg.QuotaPreflight()
+	if mode == config.QuotaPreflightOff {
+		return result, nil
+	}
+	env, err := q.newEnviron(cfg)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	reporter, ok := env.(environs.QuotaReporter)
+	if !ok {
+		logger.Debugf("environment does not support reporting quotas")
+		return result, nil
+	}
+	quotas, err := reporter.Quotas()
+	if err != nil {
+		return result, errors.Annotate(err, "cannot get provider quotas")
+	}
+	result.Exceeded = quotas.Exceeded(quotaRequest)
+	result.Refuse = len(result.Exceeded) > 0 && mode == config.QuotaPreflightRefuse
+	return result, nil
+}
+
+// Enforce returns an error if the environment is configured to refuse
+// requests that exceed the provider quotas, and provisioning the new
+// machines described by the given requests would exceed them. Clients
+// run the preflight themselves, so that they can warn before making a
+// request; every API call that provisions machines enforces it too,
+// so that the refusal holds whichever client is used. As for clients,
+// failing to check the quotas is logged, and does not prevent the
+// request.
+func (q *QuotaChecker) Enforce(requests ...params.QuotaPreflight) error {
+	cfg, err := q.backend.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.QuotaPreflight() != config.QuotaPreflightRefuse {
+		// Only clients warn about exceeding the quotas.
+		return nil
+	}
+	result, err := q.Check(requests...)
+	if err != nil {
+		logger.Warningf("cannot check provider quotas: %v", err)
+		return nil
+	}
+	if !result.Refuse {
+		return nil
+	}
+	return errors.Errorf("request would exceed provider quotas: %s", strings.Join(result.Exceeded, "; "))
+}
+
+// ServiceDeployRequest returns the quota request for deploying a
+// service with the given arguments. The service does not exist yet,
+// so only the given constraints are combined with the environment's.
+func (q *QuotaChecker) ServiceDeployRequest(args params.ServiceDeploy) params.QuotaPreflight {
+	storageCons := make([]storage.Constraints, 0, len(args.Storage))
+	for _, cons := range args.Storage {
+		storageCons = append(storageCons, cons)
+	}
+	return params.QuotaPreflight{
+		Machines:    unitMachines(args.NumUnits, args.ToMachineSpec, args.Placement),
+		Constraints: args.Constraints,
+		Volumes:     q.providerVolumes(args.NumUnits, storageCons),
+	}
+}
+
+// AddUnitsRequest returns the quota request for adding units to an
+// existing service.
+func (q *QuotaChecker) AddUnitsRequest(args params.AddServiceUnits) params.QuotaPreflight {
+	return params.QuotaPreflight{
+		Machines:    unitMachines(args.NumUnits, args.ToMachineSpec, args.Placement),
+		ServiceName: args.ServiceName,
+	}
+}
+
+// AddMachinesRequests returns the quota requests for adding machines
+// with the given parameters.
+func (q *QuotaChecker) AddMachinesRequests(machineParams []params.AddMachineParams) []params.QuotaPreflight {
+	var requests []params.QuotaPreflight
+	for _, p := range machineParams {
+		if needsInstance(p) {
+			requests = append(requests, params.QuotaPreflight{
+				Machines:    1,
+				Constraints: p.Constraints,
+				Volumes:     q.providerVolumes(1, p.Disks),
+			})
+		}
+	}
+	return requests
+}
+
+// unitMachines returns the number of new machines that are provisioned
+// when numUnits units are added according to the given placement.
+// Units placed on existing machines, or in new containers on existing
+// machines, do not require new machines.
+func unitMachines(numUnits int, toMachineSpec string, placement []*instance.Placement) int {
+	if toMachineSpec != "" && len(placement) == 0 {
+		return 0
+	}
+	machines := numUnits
+	for i, p := range placement {
+		if i >= numUnits {
+			break
+		}
+		if p.Directive == "" {
+			continue
+		}
+		if _, err := instance.ParseContainerType(p.Scope); err == nil || p.Scope == instance.MachineScope {
+			machines--
+		}
+	}
+	return machines
+}
+
+// needsInstance reports whether adding a machine with the given
+// parameters provisions a new instance. Machines that already have an
+// instance, and containers on existing machines, do not.
+func needsInstance(p params.AddMachineParams) bool {
+	if p.InstanceId != "" {
+		return false
+	}
+	if p.Placement != nil {
+		if _, err := instance.ParseContainerType(p.Placement.Scope); err == nil {
+			return false
+		}
+	}
+	return p.ContainerType == "" || p.ParentId == ""
+}
+
+// providerVolumes returns the number of provider volumes that may be
+// created for n units or machines, each with storage according to the
+// given constraints. Storage from pools whose provider is scoped to
+// the machine is not counted; storage from pools that cannot be
+// resolved is.
+func (q *QuotaChecker) providerVolumes(n int, storageCons []storage.Constraints) int {
+	poolManager := q.backend.StoragePoolManager()
+	var volumes int
+	for _, cons := range storageCons {
+		if cons.Pool != "" {
+			providerType, _, err := StoragePoolConfig(cons.Pool, poolManager)
+			if err == nil {
+				provider, err := registry.StorageProvider(providerType)
+				if err == nil && provider.Scope() == storage.ScopeMachine {
+					continue
+				}
+			}
+		}
+		volumes += int(cons.Count)
+	}
+	return n * volumes
+}
+
+// coresPerMachine returns the number of CPU cores that each of the new
+// machines described by args is expected to have, taking the first
+// cpu-cores constraint found in the machine, service and environment
+// constraints, in that order. Machines without such a constraint are
+// counted as having a single core.
+func (q *QuotaChecker) coresPerMachine(args params.QuotaPreflight) (int, error) {
+	consList := []constraints.Value{args.Constraints}
+	if args.ServiceName != "" {
+		serviceCons, err := q.backend.ServiceConstraints(args.ServiceName)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		consList = append(consList, serviceCons)
+	}
+	envCons, err := q.backend.EnvironConstraints()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	consList = append(consList, envCons)
+	for _, cons := range consList {
+		if cons.CpuCores != nil && *cons.CpuCores > 0 {
+			return int(*cons.CpuCores), nil
+		}
+	}
+	return 1, nil
+}
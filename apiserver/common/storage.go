@@ -49,6 +49,14 @@ type StorageInterface interface {
 	// WatchVolumeAttachment watches for changes to the volume attachment
 	// corresponding to the identfified machien and volume.
 	WatchVolumeAttachment(names.MachineTag, names.VolumeTag) state.NotifyWatcher
+
+	// BlockDevices returns information about block devices published
+	// for the specified machine.
+	BlockDevices(names.MachineTag) ([]state.BlockDeviceInfo, error)
+
+	// WatchBlockDevices watches for changes to the block devices
+	// published for the specified machine.
+	WatchBlockDevices(names.MachineTag) state.NotifyWatcher
 }
 
 // StorageAttachmentInfo returns the StorageAttachmentInfo for the specified
@@ -95,6 +103,8 @@ func volumeStorageAttachmentInfo(
 		return nil, errors.Annotate(err, "getting volume attachment info")
 	}
 	devicePath, err := volumeAttachmentDevicePath(
+		st,
+		machineTag,
 		volumeInfo,
 		volumeAttachmentInfo,
 	)
@@ -133,7 +143,8 @@ func filesystemStorageAttachmentInfo(
 
 // WatchStorageAttachment returns a state.NotifyWatcher that reacts to changes
 // to the VolumeAttachmentInfo or FilesystemAttachmentInfo corresponding to the tags
-// specified.
+// specified. Volume attachments may be identified by the block devices that
+// the machine publishes, so changes to those are reported too.
 func WatchStorageAttachment(
 	st StorageInterface,
 	storageTag names.StorageTag,
@@ -144,25 +155,28 @@ func WatchStorageAttachment(
 	if err != nil {
 		return nil, errors.Annotate(err, "getting storage instance")
 	}
-	var w state.NotifyWatcher
+	var w []state.NotifyWatcher
 	switch storageInstance.Kind() {
 	case state.StorageKindBlock:
 		volume, err := st.StorageInstanceVolume(storageTag)
 		if err != nil {
 			return nil, errors.Annotate(err, "getting storage volume")
 		}
-		w = st.WatchVolumeAttachment(machineTag, volume.VolumeTag())
+		w = append(w,
+			st.WatchVolumeAttachment(machineTag, volume.VolumeTag()),
+			st.WatchBlockDevices(machineTag),
+		)
 	case state.StorageKindFilesystem:
 		filesystem, err := st.StorageInstanceFilesystem(storageTag)
 		if err != nil {
 			return nil, errors.Annotate(err, "getting storage filesystem")
 		}
-		w = st.WatchFilesystemAttachment(machineTag, filesystem.FilesystemTag())
+		w = append(w, st.WatchFilesystemAttachment(machineTag, filesystem.FilesystemTag()))
 	default:
 		return nil, errors.Errorf("invalid storage kind %v", storageInstance.Kind())
 	}
-	w2 := st.WatchStorageAttachment(storageTag, unitTag)
-	return newMultiNotifyWatcher(w, w2), nil
+	w = append(w, st.WatchStorageAttachment(storageTag, unitTag))
+	return newMultiNotifyWatcher(w...), nil
 }
 
 var errNoDevicePath = errors.New("cannot determine device path: no serial or persistent device name")
@@ -170,12 +184,30 @@ var errNoDevicePath = errors.New("cannot determine device path: no serial or per
 // volumeAttachmentDevicePath returns the absolute device path for
 // a volume attachment. The value is only meaningful in the context
 // of the machine that the volume is attached to.
+//
+// Volume attachments identified only by bus address are resolved to
+// the block device at that address; until the machine has published
+// such a block device, a NotProvisioned error is returned.
 func volumeAttachmentDevicePath(
+	st StorageInterface,
+	machineTag names.MachineTag,
 	volumeInfo state.VolumeInfo,
 	volumeAttachmentInfo state.VolumeAttachmentInfo,
 ) (string, error) {
 	if volumeInfo.HardwareId != "" {
 		return path.Join("/dev/disk/by-id", volumeInfo.HardwareId), nil
+	} else if volumeAttachmentInfo.BusAddress != "" {
+		blockDevices, err := st.BlockDevices(machineTag)
+		if err != nil {
+			return "", errors.Annotate(err, "getting block devices")
+		}
+		blockDevice, ok := MatchingBlockDevice(blockDevices, volumeInfo, volumeAttachmentInfo)
+		if !ok {
+			return "", errors.NotProvisionedf(
+				"block device at %q", volumeAttachmentInfo.BusAddress,
+			)
+		}
+		return path.Join("/dev", blockDevice.DeviceName), nil
 	} else if volumeAttachmentInfo.DeviceName != "" {
 		return path.Join("/dev", volumeAttachmentInfo.DeviceName), nil
 	}
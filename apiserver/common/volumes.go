@@ -118,6 +118,7 @@ func VolumeToState(v params.Volume) (names.VolumeTag, state.VolumeInfo, error) {
 		"", // pool is set by state
 		v.Info.VolumeId,
 		v.Info.Persistent,
+		v.Info.Zone,
 	}, nil
 }
 
@@ -134,6 +135,7 @@ func VolumeFromState(v state.Volume) (params.Volume, error) {
 			info.HardwareId,
 			info.Size,
 			info.Persistent,
+			info.Zone,
 		},
 	}, nil
 }
@@ -149,6 +151,7 @@ func VolumeAttachmentFromState(v state.VolumeAttachment) (params.VolumeAttachmen
 		v.Machine().String(),
 		params.VolumeAttachmentInfo{
 			info.DeviceName,
+			info.BusAddress,
 			info.ReadOnly,
 		},
 	}, nil
@@ -189,6 +192,7 @@ func VolumeAttachmentToState(in params.VolumeAttachment) (names.MachineTag, name
 func VolumeAttachmentInfoToState(in params.VolumeAttachmentInfo) state.VolumeAttachmentInfo {
 	return state.VolumeAttachmentInfo{
 		in.DeviceName,
+		in.BusAddress,
 		in.ReadOnly,
 	}
 }
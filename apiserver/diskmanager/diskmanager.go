@@ -100,6 +100,7 @@ func stateBlockDeviceInfo(devices []storage.BlockDevice) []state.BlockDeviceInfo
 			dev.Label,
 			dev.UUID,
 			dev.HardwareId,
+			dev.BusAddress,
 			dev.Size,
 			dev.FilesystemType,
 			dev.InUse,
@@ -6,10 +6,16 @@
 package environmentmanager
 
 import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
 	"github.com/juju/utils"
+	"github.com/juju/utils/set"
+	"gopkg.in/juju/charm.v5"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
@@ -17,6 +23,8 @@ import (
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/state/storage"
 	"github.com/juju/juju/version"
 )
 
@@ -32,6 +40,12 @@ type EnvironmentManager interface {
 	ConfigSkeleton(args params.EnvironmentSkeletonConfigArgs) (params.EnvironConfigResult, error)
 	CreateEnvironment(args params.EnvironmentCreateArgs) (params.Environment, error)
 	ListEnvironments(user params.Entity) (params.UserEnvironmentList, error)
+	ExportEnvironments(args params.Entities) (params.SerializedModelResults, error)
+	ImportEnvironment(args params.EnvironmentImportArgs) (params.Environment, error)
+	RemoveImportedEnvironments(args params.Entities) (params.ErrorResults, error)
+	ActivateImportedEnvironments(args params.Entities) (params.ErrorResults, error)
+	MissingAgents(args params.Entities) (params.StringsResults, error)
+	ReturnImportedAgents(args params.ReturnImportedAgentsArgs) (params.ErrorResults, error)
 }
 
 // EnvironmentManagerAPI implements the environment manager interface and is
@@ -367,3 +381,435 @@ func (em *EnvironmentManagerAPI) ListEnvironments(user params.Entity) (params.Us
 
 	return result, nil
 }
+
+// ExportEnvironments returns the serialized model descriptions of the
+// specified environments, including the archives of the charms they
+// use. Only the owner of an environment, or a system administrator, may
+// export it.
+func (em *EnvironmentManagerAPI) ExportEnvironments(args params.Entities) (params.SerializedModelResults, error) {
+	results := params.SerializedModelResults{
+		Results: make([]params.SerializedModelResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		model, err := em.exportEnvironment(entity.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Model = model
+	}
+	return results, nil
+}
+
+func (em *EnvironmentManagerAPI) exportEnvironment(tag string) ([]byte, error) {
+	envTag, err := names.ParseEnvironTag(tag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	env, err := em.state.GetEnvironment(envTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := em.authCheck(env.Owner()); err != nil {
+		return nil, errors.Trace(err)
+	}
+	st, err := em.state.ForEnviron(envTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer st.Close()
+	model, err := st.Export()
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot export environment %q", env.Name())
+	}
+	if err := addCharmArchives(st, model); err != nil {
+		return nil, errors.Annotatef(err, "cannot export environment %q", env.Name())
+	}
+	return description.Serialize(model)
+}
+
+// ImportEnvironment creates a new environment from a serialized model
+// description, as produced by ExportEnvironments. The environment config
+// in the description is used as the basis of the new environment's
+// config; the values in args override it, and the values which must
+// match the state server are always taken from the state server. The
+// charm archives supplied in args, or else those included in the
+// description, are used; descriptions without charm archives can only
+// be imported while the environment they were exported from still
+// exists in this system, as the charms are copied from it.
+func (em *EnvironmentManagerAPI) ImportEnvironment(args params.EnvironmentImportArgs) (params.Environment, error) {
+	result := params.Environment{}
+	stateServerEnv, err := em.state.StateServerEnvironment()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+
+	ownerTag, err := names.ParseUserTag(args.OwnerTag)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if err := em.authCheck(ownerTag); err != nil {
+		return result, errors.Trace(err)
+	}
+
+	model, err := description.Deserialize(args.Model)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	createArgs, err := em.importCreateArgs(model, args, stateServerEnv)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	newConfig, err := em.newEnvironmentConfig(createArgs, stateServerEnv)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+
+	if args.Migration {
+		// A migrated environment keeps its UUID, so that its agents
+		// can carry on using it.
+		if _, err := em.state.GetEnvironment(names.NewEnvironTag(model.UUID)); err == nil {
+			return result, errors.AlreadyExistsf("environment %q", model.UUID)
+		} else if !errors.IsNotFound(err) {
+			return result, errors.Trace(err)
+		}
+		newConfig, err = newConfig.Apply(map[string]interface{}{"uuid": model.UUID})
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+	}
+
+	// Check that the charms are available before creating anything.
+	archives, err := charmArchives(model.Charms, args.Charms)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	var source *state.State
+	if len(model.Charms) > 0 && archives == nil {
+		sourceTag := names.NewEnvironTag(model.UUID)
+		if _, err := em.state.GetEnvironment(sourceTag); errors.IsNotFound(err) {
+			return result, errors.Errorf("cannot copy charms: source environment %q not found", model.UUID)
+		} else if err != nil {
+			return result, errors.Trace(err)
+		}
+		source, err = em.state.ForEnviron(sourceTag)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		defer source.Close()
+	}
+
+	// The workers of a migrated environment are not run until the
+	// migration succeeds, as the source system still manages it.
+	newEnvironment := em.state.NewEnvironment
+	if args.Migration {
+		newEnvironment = em.state.NewImportingEnvironment
+	}
+	env, st, err := newEnvironment(newConfig, ownerTag)
+	if err != nil {
+		return result, errors.Annotate(err, "failed to create new environment")
+	}
+	defer st.Close()
+
+	if source != nil {
+		err = copyCharms(source, st, model.Charms)
+	} else {
+		err = addCharms(st, model.Charms, archives)
+	}
+	if err != nil {
+		return result, errors.Annotatef(err, "environment %q created, but importing the model failed", env.Name())
+	}
+	if args.Migration {
+		err = st.ImportMigration(model)
+	} else {
+		err = st.Import(model)
+	}
+	if err != nil {
+		return result, errors.Annotatef(err, "environment %q created, but importing the model failed", env.Name())
+	}
+
+	result.Name = env.Name()
+	result.UUID = env.UUID()
+	result.OwnerTag = env.Owner().String()
+	return result, nil
+}
+
+// RemoveImportedEnvironments removes environments that were imported
+// as part of migrations that have since been aborted. The environments'
+// provider resources are left alone, as they are still in use by the
+// systems the environments were migrated from.
+func (em *EnvironmentManagerAPI) RemoveImportedEnvironments(args params.Entities) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		err := em.removeImportedEnvironment(entity.Tag)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (em *EnvironmentManagerAPI) removeImportedEnvironment(tag string) error {
+	envTag, err := names.ParseEnvironTag(tag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	env, err := em.state.GetEnvironment(envTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := em.authCheck(env.Owner()); err != nil {
+		return errors.Trace(err)
+	}
+	st, err := em.state.ForEnviron(envTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer st.Close()
+	return errors.Trace(st.RemoveImportedEnvironment())
+}
+
+// ActivateImportedEnvironments activates environments that were
+// imported as part of migrations that have since succeeded, so that
+// their workers are run by this system.
+func (em *EnvironmentManagerAPI) ActivateImportedEnvironments(args params.Entities) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		err := em.activateImportedEnvironment(entity.Tag)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (em *EnvironmentManagerAPI) activateImportedEnvironment(tag string) error {
+	envTag, err := names.ParseEnvironTag(tag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	env, err := em.state.GetEnvironment(envTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := em.authCheck(env.Owner()); err != nil {
+		return errors.Trace(err)
+	}
+	st, err := em.state.ForEnviron(envTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer st.Close()
+	return errors.Trace(st.ActivateImportedEnvironment())
+}
+
+// MissingAgents returns, for each of the specified environments, the
+// tags of the agents that should be running but are not connected to
+// this system. Only the agents of machines that have been provisioned,
+// and of units assigned to them, are expected to be running. It is
+// used to check that the agents of a migrated environment have
+// connected to this system.
+func (em *EnvironmentManagerAPI) MissingAgents(args params.Entities) (params.StringsResults, error) {
+	results := params.StringsResults{
+		Results: make([]params.StringsResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		missing, err := em.missingAgents(entity.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Result = missing
+	}
+	return results, nil
+}
+
+func (em *EnvironmentManagerAPI) missingAgents(tag string) ([]string, error) {
+	envTag, err := names.ParseEnvironTag(tag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	env, err := em.state.GetEnvironment(envTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := em.authCheck(env.Owner()); err != nil {
+		return nil, errors.Trace(err)
+	}
+	st, err := em.state.ForEnviron(envTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer st.Close()
+	return st.MissingAgents()
+}
+
+// ReturnImportedAgents sends the agents of environments that were
+// imported as part of migrations that have since been aborted back to
+// the systems the environments were migrated from.
+func (em *EnvironmentManagerAPI) ReturnImportedAgents(args params.ReturnImportedAgentsArgs) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Environments)),
+	}
+	for i, arg := range args.Environments {
+		err := em.returnImportedAgents(arg)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (em *EnvironmentManagerAPI) returnImportedAgents(arg params.ReturnImportedAgentsArg) error {
+	envTag, err := names.ParseEnvironTag(arg.EnvironTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	env, err := em.state.GetEnvironment(envTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := em.authCheck(env.Owner()); err != nil {
+		return errors.Trace(err)
+	}
+	st, err := em.state.ForEnviron(envTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer st.Close()
+	return errors.Trace(st.ReturnImportedEnvironmentAgents(arg.Addrs, arg.CACert))
+}
+
+// importCreateArgs returns the arguments with which to create the
+// environment that the model will be imported into. The uuid and
+// agent-version of the exported environment, and the values that must
+// match the state server, are not carried over.
+func (em *EnvironmentManagerAPI) importCreateArgs(
+	model *description.Model,
+	args params.EnvironmentImportArgs,
+	source ConfigSource,
+) (params.EnvironmentCreateArgs, error) {
+	baseConfig, err := source.Config()
+	if err != nil {
+		return params.EnvironmentCreateArgs{}, errors.Trace(err)
+	}
+	fields, err := em.restrictedProviderFields(baseConfig.Type())
+	if err != nil {
+		return params.EnvironmentCreateArgs{}, errors.Trace(err)
+	}
+	skip := set.NewStrings(fields...)
+	skip.Add("uuid")
+	skip.Add("agent-version")
+
+	attrs := make(map[string]interface{})
+	for key, value := range model.Config {
+		if !skip.Contains(key) {
+			attrs[key] = value
+		}
+	}
+	for key, value := range args.Config {
+		attrs[key] = value
+	}
+	return params.EnvironmentCreateArgs{
+		OwnerTag: args.OwnerTag,
+		Account:  args.Account,
+		Config:   attrs,
+	}, nil
+}
+
+// copyCharms copies the archives of the given charms from the source
+// environment's storage into the target environment, and adds the
+// charms to the target environment's state.
+func copyCharms(source, target *state.State, charms []description.Charm) error {
+	sourceStorage := storage.NewStorage(source.EnvironUUID(), source.MongoSession())
+	archives := make(map[string][]byte)
+	for _, ch := range charms {
+		data, err := readCharmArchive(sourceStorage, ch)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		archives[ch.URL] = data
+	}
+	return addCharms(target, charms, archives)
+}
+
+// addCharmArchives includes the archives of the model's charms, read
+// from the environment's storage, in the model description.
+func addCharmArchives(st *state.State, model *description.Model) error {
+	stor := storage.NewStorage(st.EnvironUUID(), st.MongoSession())
+	for i, ch := range model.Charms {
+		data, err := readCharmArchive(stor, ch)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		model.Charms[i].Archive = base64.StdEncoding.EncodeToString(data)
+	}
+	return nil
+}
+
+// readCharmArchive reads the archive of the given charm from storage.
+func readCharmArchive(stor storage.Storage, ch description.Charm) ([]byte, error) {
+	reader, _, err := stor.Get(ch.StoragePath)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read charm %q", ch.URL)
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read charm %q", ch.URL)
+	}
+	return data, nil
+}
+
+// charmArchives matches the supplied charm archives, or else those
+// included in the model description, to the charms of a model, keyed
+// by charm URL. It returns nil if there are no archives.
+func charmArchives(charms []description.Charm, supplied []params.SerializedCharm) (map[string][]byte, error) {
+	archives := make(map[string][]byte)
+	for _, ch := range supplied {
+		archives[ch.URL] = ch.Archive
+	}
+	if len(supplied) == 0 {
+		for _, ch := range charms {
+			if ch.Archive == "" {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(ch.Archive)
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot decode archive for charm %q", ch.URL)
+			}
+			archives[ch.URL] = data
+		}
+	}
+	if len(archives) == 0 {
+		return nil, nil
+	}
+	for _, ch := range charms {
+		if _, ok := archives[ch.URL]; !ok {
+			return nil, errors.Errorf("archive for charm %q not supplied", ch.URL)
+		}
+	}
+	return archives, nil
+}
+
+// addCharms stores the charm archives in the target environment and
+// adds the charms to its state.
+func addCharms(target *state.State, charms []description.Charm, archives map[string][]byte) error {
+	targetStorage := storage.NewStorage(target.EnvironUUID(), target.MongoSession())
+	for _, ch := range charms {
+		curl, err := charm.ParseURL(ch.URL)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		data := archives[ch.URL]
+		archive, err := charm.ReadCharmArchiveBytes(data)
+		if err != nil {
+			return errors.Annotatef(err, "cannot read charm %q", ch.URL)
+		}
+		if err := targetStorage.Put(ch.StoragePath, bytes.NewReader(data), int64(len(data))); err != nil {
+			return errors.Annotatef(err, "cannot store charm %q", ch.URL)
+		}
+		if _, err := target.AddCharm(archive, curl, ch.StoragePath, ch.BundleSha256); err != nil {
+			return errors.Annotatef(err, "cannot add charm %q", ch.URL)
+		}
+	}
+	return nil
+}
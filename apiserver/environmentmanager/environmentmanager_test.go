@@ -4,6 +4,11 @@
 package environmentmanager_test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+
 	"github.com/juju/loggo"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -24,7 +29,11 @@ import (
 	_ "github.com/juju/juju/provider/maas"
 	_ "github.com/juju/juju/provider/openstack"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/testcharms"
 	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
 	"github.com/juju/juju/version"
 )
 
@@ -362,6 +371,255 @@ func (s *envManagerSuite) TestListEnvironmentsDenied(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "permission denied")
 }
 
+func (s *envManagerSuite) TestExportEnvironments(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	s.Factory.MakeMachine(c, nil)
+	results, err := s.envmanager.ExportEnvironments(params.Entities{
+		Entities: []params.Entity{
+			{Tag: s.State.EnvironTag().String()},
+			{Tag: "environment-deadbeef-0bad-400d-8000-4b1d0d06f00d"},
+			{Tag: "machine-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	model, err := description.Deserialize(results.Results[0].Model)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(model.UUID, gc.Equals, s.State.EnvironUUID())
+	c.Assert(model.Machines, gc.HasLen, 1)
+
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `environment not found`)
+	c.Assert(results.Results[2].Error, gc.ErrorMatches, `"machine-0" is not a valid environment tag`)
+}
+
+func (s *envManagerSuite) TestExportEnvironmentsDenied(c *gc.C) {
+	s.setAPIUser(c, names.NewUserTag("external@remote"))
+	results, err := s.envmanager.ExportEnvironments(params.Entities{
+		Entities: []params.Entity{{Tag: s.State.EnvironTag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+}
+
+func (s *envManagerSuite) TestMissingAgents(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	present := s.Factory.MakeMachine(c, nil)
+	pinger, err := present.SetAgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	defer pinger.Kill()
+	s.State.StartSync()
+	err = present.WaitAgentPresence(coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+	absent := s.Factory.MakeMachine(c, nil)
+	// Unprovisioned machines are not expected to have agents running.
+	_, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Machine: present})
+
+	results, err := s.envmanager.MissingAgents(params.Entities{
+		Entities: []params.Entity{
+			{Tag: s.State.EnvironTag().String()},
+			{Tag: "machine-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Result, jc.SameContents, []string{
+		absent.Tag().String(),
+		unit.Tag().String(),
+	})
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `"machine-0" is not a valid environment tag`)
+}
+
+func (s *envManagerSuite) TestMissingAgentsDenied(c *gc.C) {
+	s.setAPIUser(c, names.NewUserTag("external@remote"))
+	results, err := s.envmanager.MissingAgents(params.Entities{
+		Entities: []params.Entity{{Tag: s.State.EnvironTag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+}
+
+func (s *envManagerSuite) TestReturnImportedAgents(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	results, err := s.envmanager.ReturnImportedAgents(params.ReturnImportedAgentsArgs{
+		Environments: []params.ReturnImportedAgentsArg{{
+			EnvironTag: s.State.EnvironTag().String(),
+			Addrs:      []string{"10.0.0.5:17070"},
+			CACert:     "cert",
+		}, {
+			EnvironTag: "machine-0",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, `environment ".*" was not imported by a migration`)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `"machine-0" is not a valid environment tag`)
+}
+
+func (s *envManagerSuite) TestReturnImportedAgentsDenied(c *gc.C) {
+	s.setAPIUser(c, names.NewUserTag("external@remote"))
+	results, err := s.envmanager.ReturnImportedAgents(params.ReturnImportedAgentsArgs{
+		Environments: []params.ReturnImportedAgentsArg{{
+			EnvironTag: s.State.EnvironTag().String(),
+			Addrs:      []string{"10.0.0.5:17070"},
+			CACert:     "cert",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+}
+
+func (s *envManagerSuite) TestActivateImportedEnvironments(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	err := st.ImportMigration(&description.Model{UUID: st.EnvironUUID()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.envmanager.ActivateImportedEnvironments(params.Entities{
+		Entities: []params.Entity{
+			{Tag: st.EnvironTag().String()},
+			{Tag: s.State.EnvironTag().String()},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `environment ".*" was not imported by a migration`)
+
+	env, err := st.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Importing(), jc.IsFalse)
+}
+
+func (s *envManagerSuite) TestImportEnvironment(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	s.Factory.MakeMachine(c, nil)
+	results, err := s.envmanager.ExportEnvironments(params.Entities{
+		Entities: []params.Entity{{Tag: s.State.EnvironTag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+
+	owner := names.NewUserTag("external@remote")
+	env, err := s.envmanager.ImportEnvironment(params.EnvironmentImportArgs{
+		OwnerTag: owner.String(),
+		Account:  make(map[string]interface{}),
+		Config: map[string]interface{}{
+			"name":         "imported",
+			"state-server": false,
+		},
+		Model: results.Results[0].Model,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Name, gc.Equals, "imported")
+	c.Assert(env.OwnerTag, gc.Equals, owner.String())
+	c.Assert(env.UUID, gc.Not(gc.Equals), s.State.EnvironUUID())
+
+	newState, err := s.State.ForEnviron(names.NewEnvironTag(env.UUID))
+	c.Assert(err, jc.ErrorIsNil)
+	defer newState.Close()
+	machines, err := newState.AllMachines()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+}
+
+func (s *envManagerSuite) TestImportEnvironmentBadModel(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	_, err := s.envmanager.ImportEnvironment(params.EnvironmentImportArgs{
+		OwnerTag: s.AdminUserTag(c).String(),
+		Model:    []byte("version: 99\n"),
+	})
+	c.Assert(err, gc.ErrorMatches, "model description version 99 not supported")
+}
+
+func (s *envManagerSuite) exportStateServerEnvironment(c *gc.C) []byte {
+	results, err := s.envmanager.ExportEnvironments(params.Entities{
+		Entities: []params.Entity{{Tag: s.State.EnvironTag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	return results.Results[0].Model
+}
+
+func (s *envManagerSuite) TestImportEnvironmentMigrationExisting(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	_, err := s.envmanager.ImportEnvironment(params.EnvironmentImportArgs{
+		OwnerTag:  s.AdminUserTag(c).String(),
+		Account:   make(map[string]interface{}),
+		Config:    map[string]interface{}{"name": "migrated"},
+		Model:     s.exportStateServerEnvironment(c),
+		Migration: true,
+	})
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf("environment %q already exists", s.State.EnvironUUID()))
+}
+
+func (s *envManagerSuite) TestImportEnvironmentMissingCharmArchive(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	ch := s.Factory.MakeCharm(c, nil)
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := description.Serialize(model)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.envmanager.ImportEnvironment(params.EnvironmentImportArgs{
+		OwnerTag: s.AdminUserTag(c).String(),
+		Account:  make(map[string]interface{}),
+		Config:   map[string]interface{}{"name": "imported"},
+		Model:    data,
+		Charms:   []params.SerializedCharm{{URL: "cs:quantal/other-1"}},
+	})
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf("archive for charm %q not supplied", ch.URL()))
+}
+
+func (s *envManagerSuite) TestImportEnvironmentCharmArchivesInDescription(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	ch := s.Factory.MakeCharm(c, &factory.CharmParams{Name: "dummy"})
+	archive, err := ioutil.ReadFile(testcharms.Repo.CharmArchivePath(c.MkDir(), "dummy"))
+	c.Assert(err, jc.ErrorIsNil)
+	stor := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession())
+	err = stor.Put(ch.StoragePath(), bytes.NewReader(archive), int64(len(archive)))
+	c.Assert(err, jc.ErrorIsNil)
+
+	model, err := description.Deserialize(s.exportStateServerEnvironment(c))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(model.Charms, gc.HasLen, 1)
+	c.Assert(model.Charms[0].Archive, gc.Equals, base64.StdEncoding.EncodeToString(archive))
+
+	// The charms are not copied from the exported environment, so
+	// the description can be imported even if it no longer exists.
+	model.UUID = "deadbeef-0bad-400d-8000-4b1d0d06f00d"
+	data, err := description.Serialize(model)
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := s.envmanager.ImportEnvironment(params.EnvironmentImportArgs{
+		OwnerTag: s.AdminUserTag(c).String(),
+		Account:  make(map[string]interface{}),
+		Config:   map[string]interface{}{"name": "imported"},
+		Model:    data,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	newState, err := s.State.ForEnviron(names.NewEnvironTag(env.UUID))
+	c.Assert(err, jc.ErrorIsNil)
+	defer newState.Close()
+	_, err = newState.Charm(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *envManagerSuite) TestNonAdminCannotImportEnvironmentForSomeoneElse(c *gc.C) {
+	s.setAPIUser(c, names.NewUserTag("non-admin@remote"))
+	_, err := s.envmanager.ImportEnvironment(params.EnvironmentImportArgs{
+		OwnerTag: names.NewUserTag("external@remote").String(),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
 type fakeProvider struct {
 	environs.EnvironProvider
 }
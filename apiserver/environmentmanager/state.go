@@ -16,8 +16,11 @@ var getState = func(st *state.State) stateInterface {
 
 type stateInterface interface {
 	EnvironmentsForUser(names.UserTag) ([]*state.UserEnvironment, error)
+	ForEnviron(names.EnvironTag) (*state.State, error)
+	GetEnvironment(names.EnvironTag) (*state.Environment, error)
 	IsSystemAdministrator(user names.UserTag) (bool, error)
 	NewEnvironment(*config.Config, names.UserTag) (*state.Environment, *state.State, error)
+	NewImportingEnvironment(*config.Config, names.UserTag) (*state.Environment, *state.State, error)
 	StateServerEnvironment() (*state.Environment, error)
 }
 
@@ -0,0 +1,141 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/authentication/ldap"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+)
+
+// newExternalAuthenticator returns the authenticator for users outside
+// the local domain configured for an environment, or nil if there is
+// none. It is a variable so that tests can replace it.
+var newExternalAuthenticator = func(cfg *config.Config) (authentication.ExternalAuthenticator, error) {
+	url, ok := cfg.LDAPURL()
+	if !ok {
+		return nil, nil
+	}
+	return ldap.NewAuthenticator(ldap.Config{
+		URL:       url,
+		Domain:    cfg.LDAPDomain(),
+		UserDN:    cfg.LDAPUserDN(),
+		GroupBase: cfg.LDAPGroupBase(),
+	})
+}
+
+// checkExternalCreds validates the credentials of a user outside the
+// local domain against the identity provider configured for the
+// environment. External users have no state.User; they may use the
+// environment if they have an environment user there, which is
+// created on their first login if they belong to one of the
+// environment's access groups, or if they are a member of a Juju
+// user group the environment has been shared with. Group membership
+// is checked on every login, and an environment user created for an
+// access group is removed once the user no longer belongs to one. If
+// lookForEnvUser is false, a member of an access group is allowed in
+// without an environment user.
+func checkExternalCreds(st *state.State, tag names.UserTag, password string, lookForEnvUser bool) (state.Entity, *time.Time, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	authenticator, err := newExternalAuthenticator(cfg)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot create external authenticator")
+	}
+	if authenticator == nil || authenticator.Domain() != tag.Domain() {
+		logger.Debugf("no authenticator for user %q", tag.Username())
+		return nil, nil, common.ErrBadCreds
+	}
+	identity, err := authenticator.AuthenticateUser(tag, password)
+	if err != nil {
+		if err != common.ErrBadCreds {
+			logger.Errorf("cannot authenticate %q: %v", tag.Username(), err)
+		}
+		return nil, nil, err
+	}
+	entity := &authentication.ExternalUser{ExternalIdentity: *identity}
+
+	inAccessGroup := hasAccessGroup(identity.Groups, cfg.LDAPAccessGroups())
+	envUser, err := findEnvironmentUser(st, tag)
+	if err == nil && envUser != nil && envUser.AddedForAccessGroup() && !inAccessGroup {
+		if err := removeExternalEnvironmentUser(st, tag); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		// The user may still have access through a Juju user group.
+		envUser, err = findEnvironmentUser(st, tag)
+	}
+	if errors.IsNotFound(err) && inAccessGroup {
+		if !lookForEnvUser {
+			return entity, nil, nil
+		}
+		envUser, err = addExternalEnvironmentUser(st, identity)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, common.ErrBadCreds)
+	}
+	if envUser == nil {
+		// The user has access through a Juju user group.
+		return entity, nil, nil
+	}
+	lastLogin := envUser.LastConnection()
+	envUser.UpdateLastConnection()
+	return entity, lastLogin, nil
+}
+
+// addExternalEnvironmentUser grants the given user access to the
+// environment on behalf of the environment's owner.
+func addExternalEnvironmentUser(st *state.State, identity *authentication.ExternalIdentity) (*state.EnvironmentUser, error) {
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	envUser, err := st.AddAccessGroupEnvironmentUser(identity.User, env.Owner(), identity.DisplayName)
+	if errors.IsAlreadyExists(err) {
+		// The user logged in concurrently elsewhere.
+		return st.EnvironmentUser(identity.User)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	logger.Infof("added environment user %q as a member of an access group", identity.User.Username())
+	return envUser, nil
+}
+
+// removeExternalEnvironmentUser revokes the access of a user who was
+// added to the environment as a member of an access group, but no
+// longer belongs to one.
+func removeExternalEnvironmentUser(st *state.State, user names.UserTag) error {
+	err := st.RemoveAccessGroupEnvironmentUser(user)
+	if errors.IsNotFound(err) {
+		// The user was removed concurrently elsewhere.
+		return nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("removed environment user %q who is no longer a member of an access group", user.Username())
+	return nil
+}
+
+// hasAccessGroup reports whether any of the given groups is one of
+// the access groups.
+func hasAccessGroup(groups, accessGroups []string) bool {
+	access := set.NewStrings(accessGroups...)
+	for _, group := range groups {
+		if access.Contains(group) {
+			return true
+		}
+	}
+	return false
+}
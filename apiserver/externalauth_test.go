@@ -0,0 +1,165 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver/authentication/ldap/ldaptest"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type externalAuthSuite struct {
+	jujutesting.JujuConnSuite
+	server *ldaptest.Server
+}
+
+var _ = gc.Suite(&externalAuthSuite{})
+
+func (s *externalAuthSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	server, err := ldaptest.NewServer()
+	c.Assert(err, jc.ErrorIsNil)
+	s.server = server
+	s.AddCleanup(func(*gc.C) { server.Close() })
+
+	server.AddEntry(ldaptest.Entry{
+		DN:       "uid=bob,ou=people,dc=example,dc=com",
+		Password: "bob-secret",
+		Attrs:    map[string][]string{"cn": {"Bob Brown"}},
+	})
+	server.AddEntry(ldaptest.Entry{
+		DN:       "uid=alice,ou=people,dc=example,dc=com",
+		Password: "alice-secret",
+	})
+	server.AddEntry(ldaptest.Entry{
+		DN: "cn=ops,ou=groups,dc=example,dc=com",
+		Attrs: map[string][]string{
+			"cn":     {"ops"},
+			"member": {"uid=bob,ou=people,dc=example,dc=com"},
+		},
+	})
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-url":           server.URL(),
+		"ldap-domain":        "example",
+		"ldap-user-dn":       "uid=%s,ou=people,dc=example,dc=com",
+		"ldap-group-base":    "ou=groups,dc=example,dc=com",
+		"ldap-access-groups": "ops",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *externalAuthSuite) openAs(c *gc.C, tag names.Tag, password string) (*api.State, error) {
+	info := s.APIInfo(c)
+	info.Tag = tag
+	info.Password = password
+	st, err := api.Open(info, fastDialOpts)
+	if err == nil {
+		s.AddCleanup(func(*gc.C) { st.Close() })
+	}
+	return st, err
+}
+
+func (s *externalAuthSuite) TestLoginAddsEnvironmentUserForAccessGroup(c *gc.C) {
+	startTime := time.Now().Add(-time.Second)
+	bob := names.NewUserTag("bob@example")
+
+	st, err := s.openAs(c, bob, "bob-secret")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = st.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	envUser, err := s.State.EnvironmentUser(bob)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.DisplayName(), gc.Equals, "Bob Brown")
+	c.Assert(envUser.CreatedBy(), gc.Equals, s.AdminUserTag(c).Username())
+	c.Assert(envUser.LastConnection(), gc.NotNil)
+	c.Assert(envUser.LastConnection().After(startTime), jc.IsTrue)
+
+	// A second login finds the existing environment user.
+	_, err = s.openAs(c, bob, "bob-secret")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *externalAuthSuite) TestLoginAfterLeavingAccessGroup(c *gc.C) {
+	bob := names.NewUserTag("bob@example")
+	_, err := s.openAs(c, bob, "bob-secret")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.server.AddEntry(ldaptest.Entry{
+		DN:    "cn=ops,ou=groups,dc=example,dc=com",
+		Attrs: map[string][]string{"cn": {"ops"}},
+	})
+	_, err = s.openAs(c, bob, "bob-secret")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	_, err = s.State.EnvironmentUser(bob)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *externalAuthSuite) TestServerLogin(c *gc.C) {
+	info := s.APIInfo(c)
+	info.EnvironTag = names.EnvironTag{}
+
+	// Members of an access group may log in to the server, without
+	// being added to the state server environment.
+	bob := names.NewUserTag("bob@example")
+	info.Tag, info.Password = bob, "bob-secret"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+	_, err = s.State.EnvironmentUser(bob)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// Other directory users may not.
+	info.Tag, info.Password = names.NewUserTag("alice@example"), "alice-secret"
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *externalAuthSuite) TestLoginBadPassword(c *gc.C) {
+	_, err := s.openAs(c, names.NewUserTag("bob@example"), "wrong")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+
+	_, err = s.State.EnvironmentUser(names.NewUserTag("bob@example"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *externalAuthSuite) TestLoginOutsideAccessGroups(c *gc.C) {
+	alice := names.NewUserTag("alice@example")
+	_, err := s.openAs(c, alice, "alice-secret")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+
+	// Once granted access explicitly, alice may log in.
+	_, err = s.State.AddEnvironmentUser(alice, s.AdminUserTag(c), "")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.openAs(c, alice, "alice-secret")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *externalAuthSuite) TestLoginUnknownDomain(c *gc.C) {
+	_, err := s.openAs(c, names.NewUserTag("bob@elsewhere"), "bob-secret")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	c.Assert(s.server.Binds(), gc.HasLen, 0)
+}
+
+func (s *externalAuthSuite) TestLoginWithoutLDAP(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(nil, []string{"ldap-url"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.openAs(c, names.NewUserTag("bob@example"), "bob-secret")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *externalAuthSuite) TestLocalUsersUnaffected(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "local-secret"})
+	_, err := s.openAs(c, user.Tag(), "local-secret")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.server.Binds(), gc.HasLen, 0)
+}
@@ -0,0 +1,6 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package garbagecollector
+
+var CharmGracePeriod = &charmGracePeriod
@@ -0,0 +1,173 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The garbagecollector package defines an API end point which allows
+// system administrators to see which charm archives and tools are no
+// longer used by any environment of a system, and to remove them.
+package garbagecollector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker/blobgc"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.garbagecollector")
+
+// charmGracePeriod is how long after a charm archive is uploaded it
+// is left alone, as it may be about to be deployed.
+var charmGracePeriod = blobgc.DefaultGracePeriod
+
+func init() {
+	common.RegisterStandardFacadeForFeature("GarbageCollector", 1, NewGarbageCollectorAPI, feature.JES)
+}
+
+// GarbageCollector defines the methods on the garbagecollector API
+// end point.
+type GarbageCollector interface {
+	ListGarbage() (params.GarbageList, error)
+	CollectGarbage() (params.GarbageList, error)
+}
+
+// GarbageCollectorAPI implements the GarbageCollector interface and
+// is the concrete implementation of the api end point.
+type GarbageCollectorAPI struct {
+	state      *state.State
+	authorizer common.Authorizer
+}
+
+var _ GarbageCollector = (*GarbageCollectorAPI)(nil)
+
+// NewGarbageCollectorAPI creates a new api server endpoint for
+// collecting unused charm archives and tools.
+func NewGarbageCollectorAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*GarbageCollectorAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, errors.Trace(common.ErrPerm)
+	}
+
+	// Since we know this is a user tag (because AuthClient is true),
+	// we just do the type assertion to the UserTag.
+	apiUser, _ := authorizer.GetAuthTag().(names.UserTag)
+	isAdmin, err := st.IsSystemAdministrator(apiUser)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The entire end point is only accessible to system administrators.
+	if !isAdmin {
+		return nil, errors.Trace(common.ErrPerm)
+	}
+
+	return &GarbageCollectorAPI{
+		state:      st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// ListGarbage returns the charm archives and tools that are not used
+// by any environment of the system, without removing them.
+func (api *GarbageCollectorAPI) ListGarbage() (params.GarbageList, error) {
+	garbage, err := api.findGarbage()
+	if err != nil {
+		return params.GarbageList{}, errors.Trace(err)
+	}
+	return params.GarbageList{Garbage: garbage}, nil
+}
+
+// CollectGarbage immediately removes the charm archives and tools
+// that are not used by any environment of the system, regardless of
+// how long they have been unused; only charms uploaded within the
+// grace period are kept. The result holds each item that was found,
+// along with the error if it could not be removed.
+func (api *GarbageCollectorAPI) CollectGarbage() (params.GarbageList, error) {
+	garbage, err := api.findGarbage()
+	if err != nil {
+		return params.GarbageList{}, errors.Trace(err)
+	}
+	for i, info := range garbage {
+		g := state.Garbage{
+			Kind: state.GarbageKind(info.Kind),
+			Id:   info.Id,
+			Size: info.Size,
+		}
+		if info.EnvironTag != "" {
+			tag, err := names.ParseEnvironTag(info.EnvironTag)
+			if err != nil {
+				return params.GarbageList{}, errors.Trace(err)
+			}
+			g.EnvUUID = tag.Id()
+		}
+		if err := api.state.RemoveGarbage(g); err != nil {
+			garbage[i].Error = common.ServerError(err)
+			continue
+		}
+		logger.Infof("removed unused %s %q", info.Kind, info.Id)
+	}
+	return params.GarbageList{Garbage: garbage}, nil
+}
+
+func (api *GarbageCollectorAPI) findGarbage() ([]params.GarbageInfo, error) {
+	envs, err := api.state.AllEnvironments()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	envNames := make(map[string]string)
+	for _, env := range envs {
+		envNames[env.UUID()] = env.Name()
+	}
+	garbage, err := api.state.FindGarbage()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	now := time.Now()
+	var result []params.GarbageInfo
+	for _, g := range garbage {
+		if !g.UploadedAt.IsZero() && now.Sub(g.UploadedAt) < charmGracePeriod {
+			continue
+		}
+		info := params.GarbageInfo{
+			Kind: string(g.Kind),
+			Id:   g.Id,
+			Size: g.Size,
+		}
+		if g.EnvUUID != "" {
+			info.EnvironTag = names.NewEnvironTag(g.EnvUUID).String()
+			info.EnvironName = envNames[g.EnvUUID]
+		}
+		result = append(result, info)
+	}
+	sort.Sort(orderedGarbage(result))
+	return result, nil
+}
+
+type orderedGarbage []params.GarbageInfo
+
+func (o orderedGarbage) Len() int {
+	return len(o)
+}
+
+func (o orderedGarbage) Less(i, j int) bool {
+	if o[i].Kind != o[j].Kind {
+		return o[i].Kind < o[j].Kind
+	}
+	if o[i].EnvironName != o[j].EnvironName {
+		return o[i].EnvironName < o[j].EnvironName
+	}
+	return o[i].Id < o[j].Id
+}
+
+func (o orderedGarbage) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+}
@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package garbagecollector_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/garbagecollector"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type garbageCollectorSuite struct {
+	jujutesting.JujuConnSuite
+
+	garbageCollector *garbagecollector.GarbageCollectorAPI
+	resources        *common.Resources
+}
+
+var _ = gc.Suite(&garbageCollectorSuite{})
+
+func (s *garbageCollectorSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(garbagecollector.CharmGracePeriod, time.Duration(0))
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	authoriser := apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	}
+	garbageCollector, err := garbagecollector.NewGarbageCollectorAPI(s.State, s.resources, authoriser)
+	c.Assert(err, jc.ErrorIsNil)
+	s.garbageCollector = garbageCollector
+}
+
+func (s *garbageCollectorSuite) TestNewAPIRefusesNonClient(c *gc.C) {
+	anAuthoriser := apiservertesting.FakeAuthorizer{
+		Tag: names.NewUnitTag("mysql/0"),
+	}
+	endPoint, err := garbagecollector.NewGarbageCollectorAPI(s.State, s.resources, anAuthoriser)
+	c.Assert(endPoint, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *garbageCollectorSuite) TestNewAPIRefusesNonAdmins(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	anAuthoriser := apiservertesting.FakeAuthorizer{
+		Tag: user.Tag(),
+	}
+	endPoint, err := garbagecollector.NewGarbageCollectorAPI(s.State, s.resources, anAuthoriser)
+	c.Assert(endPoint, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+// charmGarbage returns the charms in the supplied list, ignoring the
+// tools uploaded by the test suite.
+func charmGarbage(list params.GarbageList) []params.GarbageInfo {
+	var result []params.GarbageInfo
+	for _, info := range list.Garbage {
+		if info.Kind == "charm" {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
+func (s *garbageCollectorSuite) TestListGarbage(c *gc.C) {
+	st := s.Factory.MakeEnvironment(c, &factory.EnvParams{Name: "another"})
+	defer st.Close()
+	factory.NewFactory(st).MakeCharm(c, &factory.CharmParams{URL: "cs:quantal/mysql-1"})
+	used := s.AddTestingCharm(c, "wordpress")
+	s.AddTestingService(c, "wordpress", used)
+	unused := s.AddTestingCharm(c, "dummy")
+
+	result, err := s.garbageCollector.ListGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	garbage := charmGarbage(result)
+	c.Assert(garbage, gc.HasLen, 2)
+
+	c.Assert(garbage[0].EnvironName, gc.Equals, "another")
+	c.Assert(garbage[0].EnvironTag, gc.Equals, names.NewEnvironTag(st.EnvironUUID()).String())
+	c.Assert(garbage[0].Id, gc.Equals, "cs:quantal/mysql-1")
+
+	c.Assert(garbage[1].EnvironName, gc.Equals, "dummyenv")
+	c.Assert(garbage[1].EnvironTag, gc.Equals, s.State.EnvironTag().String())
+	c.Assert(garbage[1].Id, gc.Equals, unused.URL().String())
+	c.Assert(garbage[1].Size > 0, jc.IsTrue)
+	c.Assert(garbage[1].Error, gc.IsNil)
+
+	// Nothing is removed.
+	_, err = s.State.Charm(unused.URL())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *garbageCollectorSuite) TestCollectGarbage(c *gc.C) {
+	used := s.AddTestingCharm(c, "wordpress")
+	s.AddTestingService(c, "wordpress", used)
+	unused := s.AddTestingCharm(c, "dummy")
+
+	result, err := s.garbageCollector.CollectGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	garbage := charmGarbage(result)
+	c.Assert(garbage, gc.HasLen, 1)
+	c.Assert(garbage[0].Id, gc.Equals, unused.URL().String())
+	c.Assert(garbage[0].Error, gc.IsNil)
+
+	_, err = s.State.Charm(unused.URL())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Charm(used.URL())
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err = s.garbageCollector.ListGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmGarbage(result), gc.HasLen, 0)
+}
+
+func (s *garbageCollectorSuite) TestRecentCharmsKept(c *gc.C) {
+	s.PatchValue(garbagecollector.CharmGracePeriod, time.Hour)
+	recent := s.AddTestingCharm(c, "dummy")
+
+	result, err := s.garbageCollector.ListGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmGarbage(result), gc.HasLen, 0)
+
+	result, err = s.garbageCollector.CollectGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charmGarbage(result), gc.HasLen, 0)
+	_, err = s.State.Charm(recent.URL())
+	c.Assert(err, jc.ErrorIsNil)
+}
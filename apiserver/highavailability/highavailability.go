@@ -15,12 +15,16 @@ import (
 )
 
 func init() {
-	common.RegisterStandardFacade("HighAvailability", 1, NewHighAvailabilityAPI)
+	common.RegisterStandardFacade("HighAvailability", 1, NewHighAvailabilityAPIV1)
+
+	// Version 2 adds RemoveStateServer.
+	common.RegisterStandardFacade("HighAvailability", 2, NewHighAvailabilityAPI)
 }
 
 // HighAvailability defines the methods on the highavailability API end point.
 type HighAvailability interface {
 	EnsureAvailability(args params.StateServersSpecs) (params.StateServersChangeResults, error)
+	RemoveStateServer(args params.RemoveStateServerSpecs) (params.StateServersChangeResults, error)
 }
 
 // HighAvailabilityAPI implements the HighAvailability interface and is the concrete
@@ -46,6 +50,27 @@ func NewHighAvailabilityAPI(st *state.State, resources *common.Resources, author
 	}, nil
 }
 
+// HighAvailabilityAPIV1 implements version 1 of the highavailability
+// API end point, which does not support RemoveStateServer.
+type HighAvailabilityAPIV1 struct {
+	api *HighAvailabilityAPI
+}
+
+// NewHighAvailabilityAPIV1 creates a new server-side highavailability
+// API end point, at version 1.
+func NewHighAvailabilityAPIV1(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*HighAvailabilityAPIV1, error) {
+	api, err := NewHighAvailabilityAPI(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &HighAvailabilityAPIV1{api}, nil
+}
+
+// EnsureAvailability ensures the availability of Juju state servers.
+func (api *HighAvailabilityAPIV1) EnsureAvailability(args params.StateServersSpecs) (params.StateServersChangeResults, error) {
+	return api.api.EnsureAvailability(args)
+}
+
 func (api *HighAvailabilityAPI) EnsureAvailability(args params.StateServersSpecs) (params.StateServersChangeResults, error) {
 	results := params.StateServersChangeResults{Results: make([]params.StateServersChangeResult, len(args.Specs))}
 	for i, stateServersSpec := range args.Specs {
@@ -56,6 +81,20 @@ func (api *HighAvailabilityAPI) EnsureAvailability(args params.StateServersSpecs
 	return results, nil
 }
 
+// RemoveStateServer removes, and optionally replaces, specific state
+// server machines. The removal happens in stages as the machine's vote
+// is removed from the replica set by the peergrouper, so the call must
+// be repeated once that has happened to finish the removal.
+func (api *HighAvailabilityAPI) RemoveStateServer(args params.RemoveStateServerSpecs) (params.StateServersChangeResults, error) {
+	results := params.StateServersChangeResults{Results: make([]params.StateServersChangeResult, len(args.Specs))}
+	for i, spec := range args.Specs {
+		result, err := removeStateServerSingle(api.state, spec)
+		results.Results[i].Result = result
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
 // Convert machine ids to tags.
 func machineIdsToTags(ids ...string) []string {
 	var result []string
@@ -99,26 +138,9 @@ func EnsureAvailabilitySingle(st *state.State, spec params.StateServersSpec) (pa
 		}
 	}
 
-	series := spec.Series
-	if series == "" {
-		ssi, err := st.StateServerInfo()
-		if err != nil {
-			return params.StateServersChanges{}, err
-		}
-
-		// We should always have at least one voting machine
-		// If we *really* wanted we could just pick whatever series is
-		// in the majority, but really, if we always copy the value of
-		// the first one, then they'll stay in sync.
-		if len(ssi.VotingMachineIds) == 0 {
-			// Better than a panic()?
-			return params.StateServersChanges{}, fmt.Errorf("internal error, failed to find any voting machines")
-		}
-		templateMachine, err := st.Machine(ssi.VotingMachineIds[0])
-		if err != nil {
-			return params.StateServersChanges{}, err
-		}
-		series = templateMachine.Series()
+	series, err := stateServerSeries(st, spec.Series)
+	if err != nil {
+		return params.StateServersChanges{}, err
 	}
 	changes, err := st.EnsureAvailability(spec.NumStateServers, spec.Constraints, series, spec.Placement)
 	if err != nil {
@@ -126,3 +148,56 @@ func EnsureAvailabilitySingle(st *state.State, spec params.StateServersSpec) (pa
 	}
 	return stateServersChanges(changes), nil
 }
+
+// removeStateServerSingle applies a single RemoveStateServerSpec to the
+// current environment.
+func removeStateServerSingle(st *state.State, spec params.RemoveStateServerSpec) (params.StateServersChanges, error) {
+	if !st.IsStateServer() {
+		return params.StateServersChanges{}, errors.New("unsupported with hosted environments")
+	}
+	// Check if changes are allowed and the command may proceed.
+	blockChecker := common.NewBlockChecker(st)
+	if err := blockChecker.ChangeAllowed(); err != nil {
+		return params.StateServersChanges{}, errors.Trace(err)
+	}
+	tag, err := names.ParseMachineTag(spec.MachineTag)
+	if err != nil {
+		return params.StateServersChanges{}, errors.Trace(err)
+	}
+	series, err := stateServerSeries(st, spec.Series)
+	if err != nil {
+		return params.StateServersChanges{}, err
+	}
+	changes, err := st.RemoveStateServer(tag.Id(), spec.Replace, spec.Constraints, series, spec.Placement)
+	if err != nil {
+		return params.StateServersChanges{}, err
+	}
+	return stateServersChanges(changes), nil
+}
+
+// stateServerSeries returns the series to use for new state server
+// machines: the given series if not empty, or otherwise the series of
+// the first voting state server.
+func stateServerSeries(st *state.State, series string) (string, error) {
+	if series != "" {
+		return series, nil
+	}
+	ssi, err := st.StateServerInfo()
+	if err != nil {
+		return "", err
+	}
+
+	// We should always have at least one voting machine
+	// If we *really* wanted we could just pick whatever series is
+	// in the majority, but really, if we always copy the value of
+	// the first one, then they'll stay in sync.
+	if len(ssi.VotingMachineIds) == 0 {
+		// Better than a panic()?
+		return "", fmt.Errorf("internal error, failed to find any voting machines")
+	}
+	templateMachine, err := st.Machine(ssi.VotingMachineIds[0])
+	if err != nil {
+		return "", err
+	}
+	return templateMachine.Series(), nil
+}
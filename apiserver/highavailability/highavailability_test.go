@@ -341,6 +341,56 @@ func (s *clientSuite) TestEnsureAvailabilityErrors(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "failed to create new state server machines: cannot reduce state server count")
 }
 
+func (s *clientSuite) removeStateServer(c *gc.C, machineTag string, replace bool) (params.StateServersChanges, error) {
+	arg := params.RemoveStateServerSpecs{
+		Specs: []params.RemoveStateServerSpec{{
+			MachineTag: machineTag,
+			Replace:    replace,
+		}}}
+	results, err := s.haServer.RemoveStateServer(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	result := results.Results[0]
+	err = nil
+	if result.Error != nil {
+		err = result.Error
+	}
+	return result.Result, err
+}
+
+func (s *clientSuite) TestRemoveStateServerReplace(c *gc.C) {
+	_, err := s.ensureAvailability(c, 3, emptyCons, defaultSeries, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.pingers = append(s.pingers, s.setAgentPresence(c, "1"), s.setAgentPresence(c, "2"))
+
+	result, err := s.removeStateServer(c, "machine-2", true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Maintained, gc.DeepEquals, []string{"machine-0", "machine-1"})
+	c.Assert(result.Demoted, gc.DeepEquals, []string{"machine-2"})
+	c.Assert(result.Added, gc.DeepEquals, []string{"machine-3"})
+
+	m3, err := s.State.Machine("3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m3.Series(), gc.Equals, "quantal")
+}
+
+func (s *clientSuite) TestRemoveStateServerErrors(c *gc.C) {
+	_, err := s.removeStateServer(c, "machine-0", false)
+	c.Assert(err, gc.ErrorMatches, "failed to remove state server machine 0: removing machine 0 would leave an even number of voting state servers; use replace instead")
+
+	_, err = s.removeStateServer(c, "machine-42", true)
+	c.Assert(err, gc.ErrorMatches, `failed to remove state server machine 42: state server machine "42" not found`)
+
+	_, err = s.removeStateServer(c, "unit-foo-0", true)
+	c.Assert(err, gc.ErrorMatches, `"unit-foo-0" is not a valid machine tag`)
+}
+
+func (s *clientSuite) TestBlockRemoveStateServer(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockRemoveStateServer")
+	_, err := s.removeStateServer(c, "machine-0", true)
+	s.AssertBlocked(c, err, "TestBlockRemoveStateServer")
+}
+
 func (s *clientSuite) TestEnsureAvailabilityHostedEnvErrors(c *gc.C) {
 	st2 := s.Factory.MakeEnvironment(c, &factory.EnvParams{ConfigAttrs: coretesting.Attrs{"state-server": false}})
 	defer st2.Close()
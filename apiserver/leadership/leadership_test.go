@@ -72,6 +72,7 @@ func (m *stubAuthorizer) AuthOwner(tag names.Tag) bool {
 }
 func (m *stubAuthorizer) AuthEnvironManager() bool { return true }
 func (m *stubAuthorizer) AuthClient() bool         { return true }
+func (m *stubAuthorizer) AuthAPIToken() bool       { return false }
 func (m *stubAuthorizer) GetAuthTag() names.Tag    { return names.NewServiceTag(StubUnitNm) }
 
 func checkDurationEquals(c *gc.C, actual, expect time.Duration) {
@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package machineactions implements the API facade through which
+// machine agents receive and complete the actions queued for their
+// machines.
+package machineactions
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("MachineActions", 1, NewMachineActionsAPI)
+}
+
+// MachineActionsAPI implements the API used by machine agents to run
+// the actions queued for their machines.
+type MachineActionsAPI struct {
+	st        *state.State
+	resources *common.Resources
+	auth      common.Authorizer
+}
+
+// NewMachineActionsAPI creates a new server-side MachineActions API
+// facade.
+func NewMachineActionsAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*MachineActionsAPI, error) {
+	if !auth.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	return &MachineActionsAPI{
+		st:        st,
+		resources: resources,
+		auth:      auth,
+	}, nil
+}
+
+// WatchActionNotifications returns a StringsWatcher for observing the
+// actions queued for each given machine.
+func (api *MachineActionsAPI) WatchActionNotifications(args params.Entities) (params.StringsWatchResults, error) {
+	result := params.StringsWatchResults{
+		Results: make([]params.StringsWatchResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseMachineTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if api.auth.AuthOwner(tag) {
+			result.Results[i], err = api.watchOneMachineActionNotifications(tag)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (api *MachineActionsAPI) watchOneMachineActionNotifications(tag names.MachineTag) (params.StringsWatchResult, error) {
+	nothing := params.StringsWatchResult{}
+	machine, err := api.st.Machine(tag.Id())
+	if err != nil {
+		return nothing, err
+	}
+	watch := machine.WatchActionNotifications()
+	if changes, ok := <-watch.Changes(); ok {
+		return params.StringsWatchResult{
+			StringsWatcherId: api.resources.Register(watch),
+			Changes:          changes,
+		}, nil
+	}
+	return nothing, watcher.EnsureErr(watch)
+}
+
+// Actions returns the pending actions with the given tags.
+func (api *MachineActionsAPI) Actions(args params.Entities) (params.ActionsQueryResults, error) {
+	results := params.ActionsQueryResults{
+		Results: make([]params.ActionsQueryResult, len(args.Entities)),
+	}
+	for i, arg := range args.Entities {
+		action, err := api.ownAction(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		if action.Status() != state.ActionPending {
+			results.Results[i].Error = common.ServerError(common.ErrActionNotAvailable)
+			continue
+		}
+		results.Results[i].Action.Action = &params.Action{
+			Name:       action.Name(),
+			Parameters: action.Parameters(),
+		}
+	}
+	return results, nil
+}
+
+// BeginActions marks the actions with the given tags as running.
+func (api *MachineActionsAPI) BeginActions(args params.Entities) (params.ErrorResults, error) {
+	results := params.ErrorResults{Results: make([]params.ErrorResult, len(args.Entities))}
+	for i, arg := range args.Entities {
+		action, err := api.ownAction(arg.Tag)
+		if err == nil {
+			_, err = action.Begin()
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// FinishActions records the results of completed actions.
+func (api *MachineActionsAPI) FinishActions(args params.ActionExecutionResults) (params.ErrorResults, error) {
+	results := params.ErrorResults{Results: make([]params.ErrorResult, len(args.Results))}
+	for i, arg := range args.Results {
+		action, err := api.ownAction(arg.ActionTag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		actionResults, err := common.ParamsActionExecutionResultsToStateActionResults(arg)
+		if err == nil {
+			_, err = action.Finish(actionResults)
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// ownAction returns the action with the given tag, provided that it
+// is queued for the authenticated machine.
+func (api *MachineActionsAPI) ownAction(tag string) (*state.Action, error) {
+	actionTag, err := names.ParseActionTag(tag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	action, err := api.st.ActionByTag(actionTag)
+	if err != nil {
+		return nil, err
+	}
+	receiverTag, err := names.ActionReceiverTag(action.Receiver())
+	if err != nil {
+		return nil, err
+	}
+	if !api.auth.AuthOwner(receiverTag) {
+		return nil, common.ErrPerm
+	}
+	return action, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machineactions_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/machineactions"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/jujurun"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type machineActionsSuite struct {
+	jujutesting.JujuConnSuite
+
+	machine   *state.Machine
+	other     *state.Machine
+	resources *common.Resources
+	api       *machineactions.MachineActionsAPI
+}
+
+var _ = gc.Suite(&machineActionsSuite{})
+
+func (s *machineActionsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	s.other, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
+	authorizer := apiservertesting.FakeAuthorizer{Tag: s.machine.Tag()}
+	s.api, err = machineactions.NewMachineActionsAPI(s.State, s.resources, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *machineActionsSuite) addAction(c *gc.C, machine *state.Machine) *state.Action {
+	action, err := machine.AddAction(jujurun.ActionName, jujurun.ActionParams("hostname", time.Minute))
+	c.Assert(err, jc.ErrorIsNil)
+	return action
+}
+
+func (s *machineActionsSuite) TestRequiresMachineAgent(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	_, err := machineactions.NewMachineActionsAPI(s.State, s.resources, authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *machineActionsSuite) TestWatchActionNotifications(c *gc.C) {
+	action := s.addAction(c, s.machine)
+	results, err := s.api.WatchActionNotifications(params.Entities{Entities: []params.Entity{
+		{Tag: s.machine.Tag().String()},
+		{Tag: s.other.Tag().String()},
+		{Tag: "unit-mysql-0"},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Changes, jc.DeepEquals, []string{action.Id()})
+	c.Assert(results.Results[1].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+	c.Assert(results.Results[2].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	w := s.resources.Get(results.Results[0].StringsWatcherId)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w.(state.StringsWatcher))
+	wc.AssertNoChange()
+	next := s.addAction(c, s.machine)
+	wc.AssertChange(next.Id())
+}
+
+func (s *machineActionsSuite) TestActions(c *gc.C) {
+	action := s.addAction(c, s.machine)
+	otherAction := s.addAction(c, s.other)
+	results, err := s.api.Actions(params.Entities{Entities: []params.Entity{
+		{Tag: action.Tag().String()},
+		{Tag: otherAction.Tag().String()},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Action.Action, jc.DeepEquals, &params.Action{
+		Name:       jujurun.ActionName,
+		Parameters: action.Parameters(),
+	})
+	c.Assert(results.Results[1].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+}
+
+func (s *machineActionsSuite) TestBeginAndFinishActions(c *gc.C) {
+	action := s.addAction(c, s.machine)
+	args := params.Entities{Entities: []params.Entity{{Tag: action.Tag().String()}}}
+	results, err := s.api.BeginActions(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+
+	running, err := s.machine.RunningActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(running, gc.HasLen, 1)
+
+	queried, err := s.api.Actions(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queried.Results[0].Error, gc.ErrorMatches, "action no longer available")
+
+	results, err = s.api.FinishActions(params.ActionExecutionResults{
+		Results: []params.ActionExecutionResult{{
+			ActionTag: action.Tag().String(),
+			Status:    params.ActionCompleted,
+			Results:   map[string]interface{}{"Code": 0, "Stdout": "host", "Stderr": ""},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+
+	action, err = s.State.Action(action.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(action.Status(), gc.Equals, state.ActionCompleted)
+}
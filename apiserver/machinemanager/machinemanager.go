@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
@@ -30,6 +31,10 @@ var getState = func(st *state.State) stateInterface {
 	return stateShim{st}
 }
 
+// newEnviron is called to obtain the environ whose quotas are enforced
+// when adding machines.
+var newEnviron = environs.New
+
 // NewMachineManagerAPI creates a new server-side MachineManager API facade.
 func NewMachineManagerAPI(
 	st *state.State,
@@ -57,6 +62,10 @@ func (mm *MachineManagerAPI) AddMachines(args params.AddMachines) (params.AddMac
 	if err := mm.check.ChangeAllowed(); err != nil {
 		return results, errors.Trace(err)
 	}
+	quotas := common.NewQuotaChecker(mm.st, newEnviron)
+	if err := quotas.Enforce(quotas.AddMachinesRequests(args.MachineParams)...); err != nil {
+		return results, errors.Trace(err)
+	}
 	for i, p := range args.MachineParams {
 		m, err := mm.addOneMachine(p)
 		results.Machines[i].Error = common.ServerError(err)
@@ -6,6 +6,7 @@ package machinemanager_test
 import (
 	"errors"
 
+	jujuerrors "github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -14,11 +15,14 @@ import (
 	"github.com/juju/juju/apiserver/machinemanager"
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/poolmanager"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -37,7 +41,7 @@ func (s *MachineManagerSuite) SetUpTest(c *gc.C) {
 	s.resources = common.NewResources()
 	tag := names.NewUserTag("admin")
 	s.authorizer = &apiservertesting.FakeAuthorizer{Tag: tag}
-	s.st = &mockState{}
+	s.st = &mockState{cfg: coretesting.EnvironConfig(c)}
 	machinemanager.PatchState(s, s.st)
 
 	var err error
@@ -118,10 +122,41 @@ func (s *MachineManagerSuite) TestAddMachinesStateError(c *gc.C) {
 	c.Assert(s.st.calls, gc.Equals, 1)
 }
 
+func (s *MachineManagerSuite) TestAddMachinesQuotasRefused(c *gc.C) {
+	s.st.cfg = coretesting.CustomEnvironConfig(c, coretesting.Attrs{"quota-preflight": "refuse"})
+	instances := 1
+	s.PatchValue(machinemanager.NewEnviron, func(*config.Config) (environs.Environ, error) {
+		return &mockQuotaEnviron{quotas: environs.Quotas{Instances: &instances}}, nil
+	})
+	jobs := []multiwatcher.MachineJob{multiwatcher.JobHostUnits}
+	_, err := s.api.AddMachines(params.AddMachines{
+		MachineParams: []params.AddMachineParams{{Jobs: jobs}, {Jobs: jobs}},
+	})
+	c.Assert(err, gc.ErrorMatches, `request would exceed provider quotas: 2 instances requested, 1 remaining in quota`)
+	c.Assert(s.st.calls, gc.Equals, 0)
+
+	results, err := s.api.AddMachines(params.AddMachines{
+		MachineParams: []params.AddMachineParams{{Jobs: jobs}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Machines[0].Error, gc.IsNil)
+	c.Assert(s.st.calls, gc.Equals, 1)
+}
+
+type mockQuotaEnviron struct {
+	environs.Environ
+	quotas environs.Quotas
+}
+
+func (e *mockQuotaEnviron) Quotas() (environs.Quotas, error) {
+	return e.quotas, nil
+}
+
 type mockState struct {
 	calls    int
 	machines []state.MachineTemplate
 	err      error
+	cfg      *config.Config
 }
 
 func (st *mockState) AddOneMachine(template state.MachineTemplate) (*state.Machine, error) {
@@ -136,9 +171,21 @@ func (st *mockState) GetBlockForType(t state.BlockType) (state.Block, bool, erro
 }
 
 func (st *mockState) EnvironConfig() (*config.Config, error) {
+	return st.cfg, nil
+}
+
+func (st *mockState) EnvironConstraints() (constraints.Value, error) {
+	return constraints.Value{}, nil
+}
+
+func (st *mockState) ServiceConstraints(serviceName string) (constraints.Value, error) {
 	panic("not implemented")
 }
 
+func (st *mockState) StoragePoolManager() poolmanager.PoolManager {
+	return mockPoolManager{}
+}
+
 func (st *mockState) Environment() (*state.Environment, error) {
 	panic("not implemented")
 }
@@ -151,6 +198,14 @@ func (st *mockState) AddMachineInsideMachine(template state.MachineTemplate, par
 	panic("not implemented")
 }
 
+type mockPoolManager struct {
+	poolmanager.PoolManager
+}
+
+func (mockPoolManager) Get(name string) (*storage.Config, error) {
+	return nil, jujuerrors.NotFoundf("pool %q", name)
+}
+
 type mockBlock struct {
 	state.Block
 }
@@ -4,13 +4,18 @@
 package machinemanager
 
 import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/storage/poolmanager"
 )
 
 type stateInterface interface {
-	EnvironConfig() (*config.Config, error)
+	common.QuotaBackend
 	Environment() (*state.Environment, error)
 	GetBlockForType(t state.BlockType) (state.Block, bool, error)
 	AddOneMachine(template state.MachineTemplate) (*state.Machine, error)
@@ -26,6 +31,22 @@ func (s stateShim) EnvironConfig() (*config.Config, error) {
 	return s.State.EnvironConfig()
 }
 
+func (s stateShim) EnvironConstraints() (constraints.Value, error) {
+	return s.State.EnvironConstraints()
+}
+
+func (s stateShim) ServiceConstraints(serviceName string) (constraints.Value, error) {
+	service, err := s.State.Service(serviceName)
+	if err != nil {
+		return constraints.Value{}, errors.Trace(err)
+	}
+	return service.Constraints()
+}
+
+func (s stateShim) StoragePoolManager() poolmanager.PoolManager {
+	return poolmanager.New(state.NewStateSettings(s.State))
+}
+
 func (s stateShim) Environment() (*state.Environment, error) {
 	return s.State.Environment()
 }
@@ -0,0 +1,55 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/juju/names"
+)
+
+// metricsHandler serves the latest charm metric values held by the
+// Prometheus metric sender. The values include the UUIDs, unit names
+// and charm URLs of every hosted environment, so they are only served
+// to system administrators, authenticated with HTTP basic auth.
+type metricsHandler struct {
+	httpHandler
+	sender http.Handler
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	stateWrapper, err := h.validateEnvironUUID(req)
+	if err != nil {
+		h.sendError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer stateWrapper.cleanup()
+
+	tag, err := stateWrapper.authenticate(req)
+	if err != nil {
+		h.authError(w, h)
+		return
+	}
+	userTag, ok := tag.(names.UserTag)
+	if !ok {
+		h.authError(w, h)
+		return
+	}
+	isAdmin, err := h.ssState.IsSystemAdministrator(userTag)
+	if err != nil {
+		h.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !isAdmin {
+		h.authError(w, h)
+		return
+	}
+	h.sender.ServeHTTP(w, req)
+}
+
+// sendError sends a plain text error response; Prometheus expects
+// text, rather than JSON, from the endpoints it scrapes.
+func (h *metricsHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
+	http.Error(w, message, statusCode)
+}
@@ -0,0 +1,180 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsender
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/names"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
+)
+
+// PrometheusSenderName is the name with which the default
+// PrometheusSender is registered.
+const PrometheusSenderName = "prometheus"
+
+// DefaultPrometheusSender holds the latest metric values sent by units
+// of all environments, and is served to system administrators on the
+// API server's /metrics endpoint.
+var DefaultPrometheusSender = NewPrometheusSender()
+
+func init() {
+	if err := RegisterSender(PrometheusSenderName, DefaultPrometheusSender); err != nil {
+		panic(err)
+	}
+}
+
+// prometheusMetricPrefix is prepended to every charm metric key to
+// form the name of the exported metric.
+const prometheusMetricPrefix = "juju_charm_"
+
+// prometheusKey identifies a single exported time series.
+type prometheusKey struct {
+	envUUID  string
+	unitName string
+	key      string
+}
+
+// prometheusValue holds the latest value of a single time series.
+type prometheusValue struct {
+	charmURL string
+	value    float64
+	time     time.Time
+}
+
+// PrometheusSender is a MetricSender which keeps the latest value of
+// each metric reported by each unit, and serves them over HTTP in the
+// Prometheus text exposition format. Each series is labelled with the
+// environment UUID, service, unit and charm URL it was reported for.
+//
+// The values are only held in memory, so they are lost when the API
+// server restarts, and each API server only knows the values from the
+// batches that it sent itself. In a highly available system, every
+// API server should be scraped and the results combined.
+type PrometheusSender struct {
+	mu     sync.Mutex
+	values map[prometheusKey]prometheusValue
+}
+
+var _ MetricSender = (*PrometheusSender)(nil)
+var _ http.Handler = (*PrometheusSender)(nil)
+
+// NewPrometheusSender returns a new, empty PrometheusSender.
+func NewPrometheusSender() *PrometheusSender {
+	return &PrometheusSender{
+		values: make(map[prometheusKey]prometheusValue),
+	}
+}
+
+// Send implements MetricSender. Every batch is acknowledged; metric
+// values which cannot be parsed as numbers are ignored, as they cannot
+// be represented by Prometheus.
+func (p *PrometheusSender) Send(batches []*wireformat.MetricBatch) (*wireformat.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	resp := make(wireformat.EnvironmentResponses)
+	for _, batch := range batches {
+		for _, metric := range batch.Metrics {
+			value, err := strconv.ParseFloat(metric.Value, 64)
+			if err != nil {
+				logger.Debugf("ignoring non-numeric value %q of metric %q", metric.Value, metric.Key)
+				continue
+			}
+			key := prometheusKey{batch.EnvUUID, batch.UnitName, metric.Key}
+			if existing, ok := p.values[key]; ok && existing.time.After(metric.Time) {
+				continue
+			}
+			p.values[key] = prometheusValue{
+				charmURL: batch.CharmUrl,
+				value:    value,
+				time:     metric.Time,
+			}
+		}
+		resp.Ack(batch.EnvUUID, batch.UUID)
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	return &wireformat.Response{UUID: uuid.String(), EnvResponses: resp}, nil
+}
+
+// ServeHTTP implements http.Handler, writing the latest metric values
+// in the Prometheus text exposition format.
+func (p *PrometheusSender) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, fmt.Sprintf("unsupported method: %q", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(p.exposition())
+}
+
+// exposition returns the latest metric values in the Prometheus text
+// exposition format, grouped by metric name.
+func (p *PrometheusSender) exposition() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	families := make(map[string][]string)
+	for key, value := range p.values {
+		name := prometheusMetricName(key.key)
+		serviceName, _ := names.UnitService(key.unitName)
+		line := fmt.Sprintf("%s{env_uuid=%s,service=%s,unit=%s,charm_url=%s} %s %d",
+			name,
+			prometheusLabelValue(key.envUUID),
+			prometheusLabelValue(serviceName),
+			prometheusLabelValue(key.unitName),
+			prometheusLabelValue(value.charmURL),
+			strconv.FormatFloat(value.value, 'g', -1, 64),
+			value.time.UnixNano()/int64(time.Millisecond),
+		)
+		families[name] = append(families[name], line)
+	}
+	familyNames := make([]string, 0, len(families))
+	for name := range families {
+		familyNames = append(familyNames, name)
+	}
+	sort.Strings(familyNames)
+	var buf bytes.Buffer
+	for _, name := range familyNames {
+		lines := families[name]
+		sort.Strings(lines)
+		fmt.Fprintf(&buf, "# TYPE %s gauge\n", name)
+		for _, line := range lines {
+			fmt.Fprintln(&buf, line)
+		}
+	}
+	return buf.Bytes()
+}
+
+// prometheusMetricName returns the name of the exported metric for the
+// given charm metric key. Characters not allowed in Prometheus metric
+// names are replaced with underscores.
+func prometheusMetricName(key string) string {
+	name := []byte(prometheusMetricPrefix + key)
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == ':':
+		default:
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// prometheusLabelValue returns the quoted form of a label value.
+func prometheusLabelValue(value string) string {
+	return `"` + labelValueReplacer.Replace(value) + `"`
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsender_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/metricsender"
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type PrometheusSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&PrometheusSuite{})
+
+func (s *PrometheusSuite) scrape(c *gc.C, sender *metricsender.PrometheusSender) string {
+	req, err := http.NewRequest("GET", "/metrics", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	rec := httptest.NewRecorder()
+	sender.ServeHTTP(rec, req)
+	c.Assert(rec.Code, gc.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), gc.Equals, "text/plain; version=0.0.4")
+	return rec.Body.String()
+}
+
+func (s *PrometheusSuite) TestLatestValues(c *gc.C) {
+	sender := metricsender.NewPrometheusSender()
+	t0 := time.Unix(1000, 0)
+	t1 := time.Unix(2000, 0)
+	resp, err := sender.Send([]*wireformat.MetricBatch{{
+		UUID:     "batch-1",
+		EnvUUID:  "env-1",
+		UnitName: "metered/0",
+		CharmUrl: "cs:quantal/metered-1",
+		Metrics: []wireformat.Metric{
+			{Key: "pings", Value: "5", Time: t1},
+			{Key: "juju-units", Value: "1", Time: t0},
+			{Key: "status", Value: "not-a-number", Time: t0},
+		},
+	}, {
+		UUID:     "batch-2",
+		EnvUUID:  "env-1",
+		UnitName: "metered/0",
+		CharmUrl: "cs:quantal/metered-1",
+		Metrics: []wireformat.Metric{
+			// An older value does not replace a newer one.
+			{Key: "pings", Value: "3", Time: t0},
+		},
+	}, {
+		UUID:     "batch-3",
+		EnvUUID:  "env-1",
+		UnitName: "metered/1",
+		CharmUrl: "cs:quantal/metered-1",
+		Metrics: []wireformat.Metric{
+			{Key: "pings", Value: "2.5", Time: t1},
+		},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resp.EnvResponses["env-1"].AcknowledgedBatches, jc.SameContents, []string{"batch-1", "batch-2", "batch-3"})
+
+	c.Assert(s.scrape(c, sender), gc.Equals, `# TYPE juju_charm_juju_units gauge
+juju_charm_juju_units{env_uuid="env-1",service="metered",unit="metered/0",charm_url="cs:quantal/metered-1"} 1 1000000
+# TYPE juju_charm_pings gauge
+juju_charm_pings{env_uuid="env-1",service="metered",unit="metered/0",charm_url="cs:quantal/metered-1"} 5 2000000
+juju_charm_pings{env_uuid="env-1",service="metered",unit="metered/1",charm_url="cs:quantal/metered-1"} 2.5 2000000
+`)
+}
+
+func (s *PrometheusSuite) TestEmpty(c *gc.C) {
+	c.Assert(s.scrape(c, metricsender.NewPrometheusSender()), gc.Equals, "")
+}
+
+func (s *PrometheusSuite) TestMethodNotAllowed(c *gc.C) {
+	req, err := http.NewRequest("POST", "/metrics", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	rec := httptest.NewRecorder()
+	metricsender.NewPrometheusSender().ServeHTTP(rec, req)
+	c.Assert(rec.Code, gc.Equals, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsender
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
+)
+
+var (
+	sendersMu sync.Mutex
+	senders   = make(map[string]MetricSender)
+)
+
+// RegisterSender registers a MetricSender with the given name. Every
+// metric batch sent through RegisteredSender is passed to all
+// registered senders.
+func RegisterSender(name string, sender MetricSender) error {
+	sendersMu.Lock()
+	defer sendersMu.Unlock()
+	if _, ok := senders[name]; ok {
+		return errors.AlreadyExistsf("metric sender %q", name)
+	}
+	senders[name] = sender
+	return nil
+}
+
+// UnregisterSender removes the MetricSender registered with the
+// given name, if any.
+func UnregisterSender(name string) {
+	sendersMu.Lock()
+	defer sendersMu.Unlock()
+	delete(senders, name)
+}
+
+// RegisteredSenders returns the names of all registered senders,
+// in sorted order.
+func RegisteredSenders() []string {
+	sendersMu.Lock()
+	defer sendersMu.Unlock()
+	names := make([]string, 0, len(senders))
+	for name := range senders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisteredSender returns a MetricSender which forwards metric
+// batches to every registered sender.
+func RegisteredSender() MetricSender {
+	return registeredSender{}
+}
+
+// registeredSender sends metrics to all registered senders. A batch is
+// only acknowledged once every sender has acknowledged it, so that it
+// is sent again later if any sender failed to process it. When no
+// senders are registered all batches are acknowledged, as NopSender
+// does.
+type registeredSender struct{}
+
+// Send implements MetricSender.
+func (registeredSender) Send(batches []*wireformat.MetricBatch) (*wireformat.Response, error) {
+	sendersMu.Lock()
+	active := make([]MetricSender, 0, len(senders))
+	names := make([]string, 0, len(senders))
+	for name := range senders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		active = append(active, senders[name])
+	}
+	sendersMu.Unlock()
+
+	if len(active) == 0 {
+		return NopSender{}.Send(batches)
+	}
+
+	type batchKey struct {
+		envUUID   string
+		batchUUID string
+	}
+	acks := make(map[batchKey]int)
+	result := make(wireformat.EnvironmentResponses)
+	responded := false
+	response := &wireformat.Response{}
+	for i, sender := range active {
+		resp, err := sender.Send(batches)
+		if err != nil {
+			return nil, errors.Annotatef(err, "metric sender %q", names[i])
+		}
+		if resp == nil {
+			continue
+		}
+		responded = true
+		for envUUID, envResp := range resp.EnvResponses {
+			for _, batchUUID := range envResp.AcknowledgedBatches {
+				acks[batchKey{envUUID, batchUUID}]++
+			}
+			for unitName, status := range envResp.UnitStatuses {
+				result.SetStatus(envUUID, unitName, status.Status, status.Info)
+			}
+		}
+		if resp.NewGracePeriod > response.NewGracePeriod {
+			response.NewGracePeriod = resp.NewGracePeriod
+		}
+	}
+	if !responded {
+		return nil, nil
+	}
+	for _, batch := range batches {
+		if acks[batchKey{batch.EnvUUID, batch.UUID}] == len(active) {
+			result.Ack(batch.EnvUUID, batch.UUID)
+		}
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	response.UUID = uuid.String()
+	response.EnvResponses = result
+	return response, nil
+}
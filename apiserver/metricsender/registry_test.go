@@ -0,0 +1,102 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsender_test
+
+import (
+	"errors"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/metricsender"
+	"github.com/juju/juju/apiserver/metricsender/testing"
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type RegistrySuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&RegistrySuite{})
+
+func (s *RegistrySuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	metricsender.UnregisterSender(metricsender.PrometheusSenderName)
+	s.AddCleanup(func(c *gc.C) {
+		for _, name := range metricsender.RegisteredSenders() {
+			metricsender.UnregisterSender(name)
+		}
+		err := metricsender.RegisterSender(metricsender.PrometheusSenderName, metricsender.DefaultPrometheusSender)
+		c.Assert(err, jc.ErrorIsNil)
+	})
+}
+
+var testBatches = []*wireformat.MetricBatch{
+	{UUID: "batch-1", EnvUUID: "env-1", UnitName: "metered/0"},
+	{UUID: "batch-2", EnvUUID: "env-1", UnitName: "metered/1"},
+}
+
+func acknowledged(resp *wireformat.Response) []string {
+	var acks []string
+	for _, envResp := range resp.EnvResponses {
+		acks = append(acks, envResp.AcknowledgedBatches...)
+	}
+	return acks
+}
+
+func (s *RegistrySuite) TestRegisterDuplicate(c *gc.C) {
+	err := metricsender.RegisterSender("mock", &testing.MockSender{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = metricsender.RegisterSender("mock", &testing.MockSender{})
+	c.Assert(err, gc.ErrorMatches, `metric sender "mock" already exists`)
+	c.Assert(metricsender.RegisteredSenders(), jc.DeepEquals, []string{"mock"})
+}
+
+func (s *RegistrySuite) TestNoSendersAcknowledgesAll(c *gc.C) {
+	resp, err := metricsender.RegisteredSender().Send(testBatches)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(acknowledged(resp), jc.SameContents, []string{"batch-1", "batch-2"})
+}
+
+func (s *RegistrySuite) TestSendsToAllSenders(c *gc.C) {
+	var sender1, sender2 testing.MockSender
+	c.Assert(metricsender.RegisterSender("one", &sender1), jc.ErrorIsNil)
+	c.Assert(metricsender.RegisterSender("two", &sender2), jc.ErrorIsNil)
+	resp, err := metricsender.RegisteredSender().Send(testBatches)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sender1.Data, gc.HasLen, 1)
+	c.Assert(sender2.Data, gc.HasLen, 1)
+	c.Assert(acknowledged(resp), jc.SameContents, []string{"batch-1", "batch-2"})
+}
+
+type partialSender struct{}
+
+func (partialSender) Send(batches []*wireformat.MetricBatch) (*wireformat.Response, error) {
+	resp := make(wireformat.EnvironmentResponses)
+	resp.Ack(batches[0].EnvUUID, batches[0].UUID)
+	resp.SetStatus(batches[0].EnvUUID, batches[0].UnitName, "RED", "out of credit")
+	return &wireformat.Response{EnvResponses: resp, NewGracePeriod: 10 * time.Second}, nil
+}
+
+func (s *RegistrySuite) TestAcknowledgesOnlyCommonBatches(c *gc.C) {
+	c.Assert(metricsender.RegisterSender("mock", &testing.MockSender{}), jc.ErrorIsNil)
+	c.Assert(metricsender.RegisterSender("partial", partialSender{}), jc.ErrorIsNil)
+	resp, err := metricsender.RegisteredSender().Send(testBatches)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(acknowledged(resp), jc.SameContents, []string{"batch-1"})
+	c.Assert(resp.EnvResponses["env-1"].UnitStatuses, jc.DeepEquals, map[string]wireformat.UnitStatus{
+		"metered/0": {Status: "RED", Info: "out of credit"},
+	})
+	c.Assert(resp.NewGracePeriod, gc.Equals, 10*time.Second)
+}
+
+func (s *RegistrySuite) TestSenderError(c *gc.C) {
+	c.Assert(metricsender.RegisterSender("mock", &testing.MockSender{}), jc.ErrorIsNil)
+	c.Assert(metricsender.RegisterSender("broken", &testing.ErrorSender{Err: errors.New("boom")}), jc.ErrorIsNil)
+	resp, err := metricsender.RegisteredSender().Send(testBatches)
+	c.Assert(err, gc.ErrorMatches, `metric sender "broken": boom`)
+	c.Assert(resp, gc.IsNil)
+}
@@ -20,7 +20,7 @@ var (
 	logger            = loggo.GetLogger("juju.apiserver.metricsmanager")
 	maxBatchesPerSend = 1000
 
-	sender metricsender.MetricSender = &metricsender.NopSender{}
+	sender metricsender.MetricSender = metricsender.RegisteredSender()
 )
 
 func init() {
@@ -0,0 +1,163 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The migrationmaster package implements the API interface used by
+// the migration master worker, which carries out the migration of an
+// environment to another system.
+package migrationmaster
+
+import (
+	"io/ioutil"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("MigrationMaster", 1, NewMigrationMasterAPI)
+}
+
+// MigrationMasterAPI implements the API used by the migration master
+// worker.
+type MigrationMasterAPI struct {
+	st        *state.State
+	resources *common.Resources
+}
+
+// NewMigrationMasterAPI creates a new instance of the MigrationMaster
+// API.
+func NewMigrationMasterAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*MigrationMasterAPI, error) {
+	if !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &MigrationMasterAPI{
+		st:        st,
+		resources: resources,
+	}, nil
+}
+
+// Watch starts watching for the environment's migration to be created
+// or to change phase.
+func (api *MigrationMasterAPI) Watch() (params.NotifyWatchResult, error) {
+	watch := api.st.WatchEnvMigration()
+	if _, ok := <-watch.Changes(); ok {
+		return params.NotifyWatchResult{
+			NotifyWatcherId: api.resources.Register(watch),
+		}, nil
+	}
+	return params.NotifyWatchResult{
+		Error: common.ServerError(watcher.EnsureErr(watch)),
+	}, nil
+}
+
+// GetMigrationStatus returns the details of the environment's
+// migration.
+func (api *MigrationMasterAPI) GetMigrationStatus() (params.MigrationStatus, error) {
+	migration, err := api.st.EnvMigration()
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	target := migration.TargetInfo()
+	return params.MigrationStatus{
+		Phase:         string(migration.Phase()),
+		StatusMessage: migration.StatusMessage(),
+		TargetInfo: params.MigrationTargetInfo{
+			Addrs:    target.Addrs,
+			CACert:   target.CACert,
+			AuthTag:  target.AuthTag.String(),
+			Password: target.Password,
+		},
+	}, nil
+}
+
+// SetPhase moves the environment's migration to a new phase.
+func (api *MigrationMasterAPI) SetPhase(args params.SetMigrationPhaseArgs) error {
+	migration, err := api.st.EnvMigration()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(migration.SetPhase(state.MigrationPhase(args.Phase)))
+}
+
+// SetStatusMessage records the progress of the environment's
+// migration.
+func (api *MigrationMasterAPI) SetStatusMessage(args params.SetMigrationStatusMessageArgs) error {
+	migration, err := api.st.EnvMigration()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(migration.SetStatusMessage(args.Message))
+}
+
+// SourceInfo returns the API addresses and CA certificate of this
+// system, which the environment's agents are sent back to if the
+// migration is aborted after they were redirected.
+func (api *MigrationMasterAPI) SourceInfo() (params.MigrationSourceInfo, error) {
+	hostPorts, err := api.st.APIHostPorts()
+	if err != nil {
+		return params.MigrationSourceInfo{}, errors.Trace(err)
+	}
+	var addrs []string
+	for _, server := range hostPorts {
+		for _, hostPort := range server {
+			addrs = append(addrs, hostPort.NetAddr())
+		}
+	}
+	return params.MigrationSourceInfo{
+		Addrs:  addrs,
+		CACert: api.st.CACert(),
+	}, nil
+}
+
+// MissingAgents returns the tags of the environment's agents that are
+// not connected to this system, so that an aborted migration can wait
+// for the agents to return before removing the environment imported
+// into the target system.
+func (api *MigrationMasterAPI) MissingAgents() (params.StringsResult, error) {
+	missing, err := api.st.MissingAgents()
+	if err != nil {
+		return params.StringsResult{}, errors.Trace(err)
+	}
+	return params.StringsResult{Result: missing}, nil
+}
+
+// Export serializes the environment's model, and returns it together
+// with the archives of the charms it uses.
+func (api *MigrationMasterAPI) Export() (params.SerializedEnvironment, error) {
+	var result params.SerializedEnvironment
+	model, err := api.st.Export()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Model, err = description.Serialize(model)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	stor := storage.NewStorage(api.st.EnvironUUID(), api.st.MongoSession())
+	for _, ch := range model.Charms {
+		reader, _, err := stor.Get(ch.StoragePath)
+		if err != nil {
+			return result, errors.Annotatef(err, "cannot read charm %q", ch.URL)
+		}
+		archive, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return result, errors.Annotatef(err, "cannot read charm %q", ch.URL)
+		}
+		result.Charms = append(result.Charms, params.SerializedCharm{
+			URL:     ch.URL,
+			Archive: archive,
+		})
+	}
+	return result, nil
+}
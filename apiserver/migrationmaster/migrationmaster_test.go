@@ -0,0 +1,174 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/migrationmaster"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/state/storage"
+	statetesting "github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type migrationMasterSuite struct {
+	jujutesting.JujuConnSuite
+
+	st         *state.State
+	api        *migrationmaster.MigrationMasterAPI
+	resources  *common.Resources
+	authoriser apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&migrationMasterSuite{})
+
+func (s *migrationMasterSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
+
+	s.st = s.Factory.MakeEnvironment(c, nil)
+	s.AddCleanup(func(*gc.C) { s.st.Close() })
+
+	s.authoriser = apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	api, err := migrationmaster.NewMigrationMasterAPI(s.st, s.resources, s.authoriser)
+	c.Assert(err, jc.ErrorIsNil)
+	s.api = api
+}
+
+func (s *migrationMasterSuite) createMigration(c *gc.C) *state.EnvMigration {
+	migration, err := s.st.CreateEnvMigration(state.EnvMigrationSpec{
+		InitiatedBy: s.AdminUserTag(c),
+		TargetInfo: state.MigrationTargetInfo{
+			Addrs:    []string{"10.0.0.1:17070"},
+			CACert:   coretesting.CACert,
+			AuthTag:  names.NewUserTag("admin"),
+			Password: "secret",
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return migration
+}
+
+func (s *migrationMasterSuite) TestRequiresEnvironManager(c *gc.C) {
+	s.authoriser.EnvironManager = false
+	api, err := migrationmaster.NewMigrationMasterAPI(s.st, s.resources, s.authoriser)
+	c.Assert(api, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *migrationMasterSuite) TestWatch(c *gc.C) {
+	result, err := s.api.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+
+	w := s.resources.Get(result.NotifyWatcherId).(state.NotifyWatcher)
+	wc := statetesting.NewNotifyWatcherC(c, s.st, w)
+	wc.AssertNoChange()
+	s.createMigration(c)
+	wc.AssertOneChange()
+}
+
+func (s *migrationMasterSuite) TestGetMigrationStatus(c *gc.C) {
+	migration := s.createMigration(c)
+	c.Assert(migration.SetStatusMessage("exporting"), jc.ErrorIsNil)
+
+	status, err := s.api.GetMigrationStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, params.MigrationStatus{
+		Phase:         "EXPORT",
+		StatusMessage: "exporting",
+		TargetInfo: params.MigrationTargetInfo{
+			Addrs:    []string{"10.0.0.1:17070"},
+			CACert:   coretesting.CACert,
+			AuthTag:  "user-admin",
+			Password: "secret",
+		},
+	})
+}
+
+func (s *migrationMasterSuite) TestGetMigrationStatusNotFound(c *gc.C) {
+	_, err := s.api.GetMigrationStatus()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *migrationMasterSuite) TestSetPhase(c *gc.C) {
+	migration := s.createMigration(c)
+	err := s.api.SetPhase(params.SetMigrationPhaseArgs{Phase: "IMPORT"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.Refresh(), jc.ErrorIsNil)
+	c.Assert(migration.Phase(), gc.Equals, state.MigrationImport)
+
+	err = s.api.SetPhase(params.SetMigrationPhaseArgs{Phase: "DONE"})
+	c.Assert(err, gc.ErrorMatches, "cannot move migration from IMPORT to DONE")
+}
+
+func (s *migrationMasterSuite) TestSetStatusMessage(c *gc.C) {
+	migration := s.createMigration(c)
+	err := s.api.SetStatusMessage(params.SetMigrationStatusMessageArgs{Message: "importing"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.Refresh(), jc.ErrorIsNil)
+	c.Assert(migration.StatusMessage(), gc.Equals, "importing")
+}
+
+func (s *migrationMasterSuite) TestSourceInfo(c *gc.C) {
+	err := s.State.SetAPIHostPorts([][]network.HostPort{
+		network.NewHostPorts(17070, "10.0.0.5", "192.168.0.5"),
+		network.NewHostPorts(17070, "10.0.0.6"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	info, err := s.api.SourceInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info, jc.DeepEquals, params.MigrationSourceInfo{
+		Addrs:  []string{"10.0.0.5:17070", "192.168.0.5:17070", "10.0.0.6:17070"},
+		CACert: s.st.CACert(),
+	})
+}
+
+func (s *migrationMasterSuite) TestMissingAgents(c *gc.C) {
+	f := factory.NewFactory(s.st)
+	machine := f.MakeMachine(c, nil)
+
+	result, err := s.api.MissingAgents()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Result, jc.DeepEquals, []string{machine.Tag().String()})
+}
+
+func (s *migrationMasterSuite) TestExport(c *gc.C) {
+	f := factory.NewFactory(s.st)
+	ch := f.MakeCharm(c, nil)
+	f.MakeService(c, &factory.ServiceParams{Charm: ch})
+	stor := storage.NewStorage(s.st.EnvironUUID(), s.st.MongoSession())
+	err := stor.Put(ch.StoragePath(), strings.NewReader("archive"), 7)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	model, err := description.Deserialize(result.Model)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(model.UUID, gc.Equals, s.st.EnvironUUID())
+	c.Assert(model.Services, gc.HasLen, 1)
+
+	c.Assert(result.Charms, gc.HasLen, 1)
+	c.Assert(result.Charms[0].URL, gc.Equals, ch.URL().String())
+	c.Assert(string(result.Charms[0].Archive), gc.Equals, "archive")
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// CleanupInfo holds information about a pending cleanup in an
+// environment.
+type CleanupInfo struct {
+	EnvironTag  string     `json:"environ-tag"`
+	EnvironName string     `json:"environ-name"`
+	Id          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Prefix      string     `json:"prefix"`
+	Created     time.Time  `json:"created"`
+	Attempts    int        `json:"attempts"`
+	LastAttempt *time.Time `json:"last-attempt,omitempty"`
+	LastError   string     `json:"last-error,omitempty"`
+}
+
+// CleanupInfoList holds the pending cleanups of all environments
+// in a system.
+type CleanupInfoList struct {
+	Cleanups []CleanupInfo `json:"cleanups,omitempty"`
+}
+
+// CleanupId identifies a single cleanup in an environment.
+type CleanupId struct {
+	EnvironTag string `json:"environ-tag"`
+	Id         string `json:"id"`
+}
+
+// CleanupIds holds the arguments for the RetryCleanups and
+// SkipCleanups API calls.
+type CleanupIds struct {
+	Cleanups []CleanupId `json:"cleanups"`
+}
@@ -42,8 +42,11 @@ const (
 )
 
 // ModifyEnvironUser stores the parameters used for a Client.ShareEnvironment call.
+// If Group is set, the environment is shared with, or unshared from,
+// the named user group rather than the user.
 type ModifyEnvironUser struct {
 	UserTag string        `json:"user-tag"`
+	Group   string        `json:"group,omitempty"`
 	Action  EnvironAction `json:"action"`
 }
 
@@ -72,3 +75,21 @@ type EnvUserInfoResult struct {
 type EnvUserInfoResults struct {
 	Results []EnvUserInfoResult `json:"results"`
 }
+
+// WebhookDelivery holds the outcome of posting an environment event
+// to a webhook.
+type WebhookDelivery struct {
+	Event     string    `json:"event"`
+	Entity    string    `json:"entity"`
+	URL       string    `json:"url"`
+	Time      time.Time `json:"time"`
+	Attempts  int       `json:"attempts"`
+	Delivered bool      `json:"delivered"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// WebhookDeliveryResults holds the result of a WebhookDeliveries
+// API call.
+type WebhookDeliveryResults struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// GarbageInfo describes a charm archive or tools tarball that is no
+// longer used by anything in the system.
+type GarbageInfo struct {
+	Kind string `json:"kind"`
+
+	// EnvironTag and EnvironName identify the environment a charm
+	// was added to. They are empty for tools, which are shared by
+	// all environments.
+	EnvironTag  string `json:"environ-tag,omitempty"`
+	EnvironName string `json:"environ-name,omitempty"`
+
+	Id    string `json:"id"`
+	Size  int64  `json:"size"`
+	Error *Error `json:"error,omitempty"`
+}
+
+// GarbageList holds the results of the ListGarbage and
+// CollectGarbage API calls.
+type GarbageList struct {
+	Garbage []GarbageInfo `json:"garbage,omitempty"`
+}
@@ -75,6 +75,19 @@ type StringResults struct {
 	Results []StringResult
 }
 
+// IntResult holds the result of an API call that returns an
+// int or an error.
+type IntResult struct {
+	Error  *Error
+	Result int
+}
+
+// IntResults holds the bulk operation result of an API call
+// that returns an int or an error.
+type IntResults struct {
+	Results []IntResult
+}
+
 // EnvironmentResult holds the result of an API call returning a name and UUID
 // for an environment.
 type EnvironmentResult struct {
@@ -107,6 +120,49 @@ type EnvironmentCreateArgs struct {
 	Config map[string]interface{}
 }
 
+// EnvironmentImportArgs holds the arguments that are necessary to
+// create an environment from an exported model description.
+type EnvironmentImportArgs struct {
+	// OwnerTag represents the user that will own the new environment.
+	OwnerTag string
+
+	// Account holds the provider specific account details necessary to
+	// interact with the provider to create, list and destroy machines.
+	Account map[string]interface{}
+
+	// Config holds environment config values which override those
+	// in the model description, such as the name of the new
+	// environment.
+	Config map[string]interface{}
+
+	// Model holds the serialized model description.
+	Model []byte
+
+	// Charms holds the archives of the charms used by the model. When
+	// empty, the archives included in the model description are used,
+	// or else the charms are copied from the environment the model was
+	// exported from, which must be hosted by the same system.
+	Charms []SerializedCharm
+
+	// Migration specifies that the environment is being migrated from
+	// another system, so it keeps its UUID and the identities of its
+	// machines and units.
+	Migration bool
+}
+
+// SerializedModelResult holds the serialized model description of an
+// environment, or an error.
+type SerializedModelResult struct {
+	Model []byte
+	Error *Error
+}
+
+// SerializedModelResults holds the result of an API call returning
+// serialized model descriptions.
+type SerializedModelResults struct {
+	Results []SerializedModelResult
+}
+
 // Environment holds the result of an API call returning a name and UUID
 // for an environment and the tag of the server in which it is running.
 type Environment struct {
@@ -277,6 +333,19 @@ type EntitiesCharmURL struct {
 	Entities []EntityCharmURL
 }
 
+// EntityResourcesVersion holds an entity's tag and the version of the
+// charm resources it has reacted to.
+type EntityResourcesVersion struct {
+	Tag     string
+	Version int
+}
+
+// EntitiesResourcesVersion holds the parameters for making a
+// SetResourcesVersion API call.
+type EntitiesResourcesVersion struct {
+	Entities []EntityResourcesVersion
+}
+
 // BytesResult holds the result of an API call that returns a slice
 // of bytes.
 type BytesResult struct {
@@ -553,6 +622,15 @@ type CharmsResponse struct {
 	Files    []string `json:",omitempty"`
 }
 
+// ResourcesResponse is the server response to resource upload requests,
+// or to failed resource download requests.
+type ResourcesResponse struct {
+	Error    string `json:",omitempty"`
+	Service  string `json:",omitempty"`
+	Name     string `json:",omitempty"`
+	Revision int    `json:",omitempty"`
+}
+
 // RunParams is used to provide the parameters to the Run method.
 // Commands and Timeout are expected to have values, and one or more
 // values should be in the Machines, Services, or Units slices.
@@ -594,6 +672,10 @@ type ProvisioningInfo struct {
 	Jobs        []multiwatcher.MachineJob
 	Volumes     []VolumeParams
 	Tags        map[string]string
+
+	// VolumeZones holds the availability zones of the existing
+	// volumes that are to be attached to the machine.
+	VolumeZones []string
 }
 
 // ProvisioningInfoResult holds machine provisioning info or an error.
@@ -119,6 +119,11 @@ type DestroyRelation struct {
 type AddCharmWithAuthorization struct {
 	URL                string
 	CharmStoreMacaroon *macaroon.Macaroon
+
+	// Signature optionally holds an armored detached signature of
+	// the charm archive, which is verified against the trusted charm
+	// keys of the environment.
+	Signature string `json:",omitempty"`
 }
 
 // AddMachineParams encapsulates the parameters used to create a new machine.
@@ -402,6 +407,36 @@ type SetConstraints struct {
 	Constraints constraints.Value
 }
 
+// QuotaPreflight holds the parameters for making the QuotaPreflight
+// call, describing the new machines that a request would provision.
+type QuotaPreflight struct {
+	// Machines is the number of new machines to be provisioned.
+	Machines int
+
+	// ServiceName optionally names the service whose units the new
+	// machines will host; its constraints apply to the machines.
+	ServiceName string
+
+	// Constraints holds the constraints of the new machines, which
+	// take precedence over those of the service and environment.
+	Constraints constraints.Value
+
+	// Volumes is the total number of volumes to be created for the
+	// new machines.
+	Volumes int
+}
+
+// QuotaPreflightResult holds the result of a QuotaPreflight call.
+type QuotaPreflightResult struct {
+	// Exceeded describes each provider quota that the request
+	// would exceed.
+	Exceeded []string
+
+	// Refuse reports whether the environment is configured to
+	// refuse requests that would exceed the quotas.
+	Refuse bool
+}
+
 // ResolveCharms stores charm references for a ResolveCharms call.
 type ResolveCharms struct {
 	References []charm.Reference
@@ -423,6 +458,10 @@ type AllWatcherId struct {
 	AllWatcherId string
 }
 
+// AllWatcherFilter holds the filter passed to Client.WatchAllFiltered,
+// restricting the deltas returned by the AllWatcher.
+type AllWatcherFilter multiwatcher.Filter
+
 // AllWatcherNextResults holds deltas returned from calling AllWatcher.Next().
 type AllWatcherNextResults struct {
 	Deltas []multiwatcher.Delta
@@ -659,6 +698,32 @@ type StateServersSpecs struct {
 	Specs []StateServersSpec
 }
 
+// RemoveStateServerSpec contains arguments for
+// the RemoveStateServer API call.
+type RemoveStateServerSpec struct {
+	// EnvironTag identifies the environment of the state server.
+	EnvironTag string `json:"environ-tag"`
+	// MachineTag identifies the state server machine to remove.
+	MachineTag string `json:"machine-tag"`
+	// Replace specifies whether a replacement state server
+	// should be provided for the removed one.
+	Replace     bool              `json:"replace,omitempty"`
+	Constraints constraints.Value `json:"constraints,omitempty"`
+	// Series is the series to associate with a new replacement
+	// state server machine. If this is empty, then the series of
+	// an existing state server is used.
+	Series string `json:"series,omitempty"`
+	// Placement defines specific machines to become the
+	// replacement state server machine.
+	Placement []string `json:"placement,omitempty"`
+}
+
+// RemoveStateServerSpecs contains all the arguments
+// for the RemoveStateServer API call.
+type RemoveStateServerSpecs struct {
+	Specs []RemoveStateServerSpec
+}
+
 // StateServersChangeResult contains the results
 // of a single EnsureAvailability API call or
 // an error.
@@ -130,6 +130,78 @@ var marshalTestCases = []struct {
 		},
 	},
 	json: `["annotation","change",{"Tag":"machine-0","Annotations":{"foo":"bar","arble":"2 4"}}]`,
+}, {
+	about: "StorageInfo Delta",
+	value: multiwatcher.Delta{
+		Entity: &multiwatcher.StorageInfo{
+			Id:              "data/0",
+			Kind:            "block",
+			Name:            "data",
+			OwnerTag:        "unit-mysql-0",
+			Life:            "alive",
+			AttachmentCount: 1,
+		},
+	},
+	json: `["storage","change",{"Id":"data/0","Kind":"block","Name":"data","OwnerTag":"unit-mysql-0","Life":"alive","AttachmentCount":1}]`,
+}, {
+	about: "StorageAttachmentInfo Delta",
+	value: multiwatcher.Delta{
+		Entity: &multiwatcher.StorageAttachmentInfo{
+			StorageId: "data/0",
+			UnitName:  "mysql/0",
+			Life:      "dying",
+		},
+	},
+	json: `["storageattachment","change",{"StorageId":"data/0","UnitName":"mysql/0","Life":"dying"}]`,
+}, {
+	about: "VolumeInfo Delta",
+	value: multiwatcher.Delta{
+		Entity: &multiwatcher.VolumeInfo{
+			Name:        "0",
+			StorageId:   "data/0",
+			Life:        "alive",
+			Pool:        "ebs",
+			Size:        1024,
+			Provisioned: true,
+			VolumeId:    "vol-123",
+			Persistent:  true,
+		},
+	},
+	json: `["volume","change",{"Name":"0","StorageId":"data/0","Life":"alive","Pool":"ebs","Size":1024,"Provisioned":true,"VolumeId":"vol-123","Persistent":true}]`,
+}, {
+	about: "VolumeAttachmentInfo Delta",
+	value: multiwatcher.Delta{
+		Entity: &multiwatcher.VolumeAttachmentInfo{
+			VolumeName:  "0",
+			MachineId:   "1",
+			Life:        "alive",
+			Provisioned: true,
+			DeviceName:  "xvdf",
+		},
+	},
+	json: `["volumeattachment","change",{"VolumeName":"0","MachineId":"1","Life":"alive","Provisioned":true,"DeviceName":"xvdf","ReadOnly":false}]`,
+}, {
+	about: "FilesystemInfo Delta",
+	value: multiwatcher.Delta{
+		Entity: &multiwatcher.FilesystemInfo{
+			Name:       "0",
+			VolumeName: "1",
+			Life:       "alive",
+			Pool:       "rootfs",
+			Size:       2048,
+		},
+	},
+	json: `["filesystem","change",{"Name":"0","VolumeName":"1","Life":"alive","Pool":"rootfs","Size":2048,"Provisioned":false}]`,
+}, {
+	about: "FilesystemAttachmentInfo Delta",
+	value: multiwatcher.Delta{
+		Removed: true,
+		Entity: &multiwatcher.FilesystemAttachmentInfo{
+			FilesystemName: "0",
+			MachineId:      "1",
+		},
+	},
+	json: `["filesystemattachment","remove",{"FilesystemName":"0","MachineId":"1","Life":"","Provisioned":false,"ReadOnly":false}]`,
 }, {
 	about: "Delta Removed True",
 	value: multiwatcher.Delta{
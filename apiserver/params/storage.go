@@ -175,6 +175,7 @@ type VolumeInfo struct {
 	// Size is the size of the volume in MiB.
 	Size       uint64 `json:"size"`
 	Persistent bool   `json:"persistent"`
+	Zone       string `json:"zone,omitempty"`
 }
 
 // Volumes describes a set of storage volumes in the environment.
@@ -192,6 +193,7 @@ type VolumeAttachment struct {
 // VolumeAttachmentInfo describes a volume attachment.
 type VolumeAttachmentInfo struct {
 	DeviceName string `json:"devicename,omitempty"`
+	BusAddress string `json:"busaddress,omitempty"`
 	ReadOnly   bool   `json:"read-only,omitempty"`
 }
 
@@ -498,6 +500,10 @@ type VolumeInstance struct {
 	// machine to which it is attached.
 	Persistent bool `json:"persistent"`
 
+	// Zone is the availability zone in which the volume resides,
+	// if the provider has availability zones.
+	Zone string `json:"zone,omitempty"`
+
 	// StorageInstance returns the tag of the storage instance that this
 	// volume is assigned to, if any.
 	StorageTag string `json:"storage,omitempty"`
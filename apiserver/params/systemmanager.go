@@ -36,3 +36,80 @@ type EnvironmentBlockInfoList struct {
 type RemoveBlocksArgs struct {
 	All bool `json:"all"`
 }
+
+// MigrationTargetInfo holds the details required to connect to and
+// authenticate with the system that an environment is migrated to.
+type MigrationTargetInfo struct {
+	Addrs    []string `json:"addrs"`
+	CACert   string   `json:"ca-cert"`
+	AuthTag  string   `json:"auth-tag"`
+	Password string   `json:"password"`
+}
+
+// EnvironmentMigrationSpec holds the details of a single environment
+// migration to be initiated.
+type EnvironmentMigrationSpec struct {
+	EnvironTag string              `json:"environ-tag"`
+	TargetInfo MigrationTargetInfo `json:"target-info"`
+}
+
+// InitiateEnvironmentMigrationArgs holds the arguments for the
+// InitiateEnvironmentMigration call.
+type InitiateEnvironmentMigrationArgs struct {
+	Specs []EnvironmentMigrationSpec `json:"specs"`
+}
+
+// MigrationStatus holds the details of an environment migration, as
+// reported to the migration master worker.
+type MigrationStatus struct {
+	Phase         string              `json:"phase"`
+	StatusMessage string              `json:"status-message"`
+	TargetInfo    MigrationTargetInfo `json:"target-info"`
+}
+
+// SetMigrationPhaseArgs holds the arguments for moving an environment
+// migration to a new phase.
+type SetMigrationPhaseArgs struct {
+	Phase string `json:"phase"`
+}
+
+// SetMigrationStatusMessageArgs holds the arguments for recording the
+// progress of an environment migration.
+type SetMigrationStatusMessageArgs struct {
+	Message string `json:"message"`
+}
+
+// SerializedCharm holds the archive of a charm used by an environment
+// being migrated.
+type SerializedCharm struct {
+	URL     string `json:"url"`
+	Archive []byte `json:"archive"`
+}
+
+// SerializedEnvironment holds an exported environment being migrated:
+// its model description and the archives of the charms it uses.
+type SerializedEnvironment struct {
+	Model  []byte            `json:"model"`
+	Charms []SerializedCharm `json:"charms"`
+}
+
+// MigrationSourceInfo holds the details the agents of an environment
+// need to connect to the system it is being migrated from.
+type MigrationSourceInfo struct {
+	Addrs  []string `json:"addrs"`
+	CACert string   `json:"ca-cert"`
+}
+
+// ReturnImportedAgentsArg holds the details of the system that the
+// agents of an imported environment are to be sent back to.
+type ReturnImportedAgentsArg struct {
+	EnvironTag string   `json:"environ-tag"`
+	Addrs      []string `json:"addrs"`
+	CACert     string   `json:"ca-cert"`
+}
+
+// ReturnImportedAgentsArgs holds the arguments for the
+// ReturnImportedAgents call.
+type ReturnImportedAgentsArgs struct {
+	Environments []ReturnImportedAgentsArg `json:"environments"`
+}
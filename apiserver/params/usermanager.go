@@ -57,3 +57,71 @@ type AddUserResult struct {
 	Tag   string `json:"tag,omitempty"`
 	Error *Error `json:"error,omitempty"`
 }
+
+// AddGroups holds the parameters for adding new user groups.
+type AddGroups struct {
+	Groups []AddGroup `json:"groups"`
+}
+
+// AddGroup stores the parameters to add one user group.
+type AddGroup struct {
+	Name string `json:"name"`
+}
+
+// GroupMembers holds the parameters for adding users to, or removing
+// users from, user groups.
+type GroupMembers struct {
+	Members []GroupMember `json:"members"`
+}
+
+// GroupMember identifies a user in a user group.
+type GroupMember struct {
+	Group   string `json:"group"`
+	UserTag string `json:"user-tag"`
+}
+
+// AddAPITokens holds the parameters for creating API tokens.
+type AddAPITokens struct {
+	Tokens []AddAPIToken `json:"tokens"`
+}
+
+// AddAPIToken stores the parameters to create one API token for the
+// logged in user, scoped to the environment of the API connection.
+type AddAPIToken struct {
+	ExpiresIn time.Duration `json:"expires-in"`
+}
+
+// AddAPITokenResults holds the results of the bulk AddAPIToken API call.
+type AddAPITokenResults struct {
+	Results []AddAPITokenResult `json:"results"`
+}
+
+// AddAPITokenResult holds a newly created API token, or an error. The
+// token's credential is only ever returned here.
+type AddAPITokenResult struct {
+	Id         string    `json:"id,omitempty"`
+	Credential string    `json:"credential,omitempty"`
+	Expires    time.Time `json:"expires"`
+	Error      *Error    `json:"error,omitempty"`
+}
+
+// APITokenInfo holds information on an API token.
+type APITokenInfo struct {
+	Id          string     `json:"id"`
+	Environment string     `json:"environment"`
+	EnvironUUID string     `json:"environ-uuid"`
+	DateCreated time.Time  `json:"date-created"`
+	Expires     time.Time  `json:"expires"`
+	LastUsed    *time.Time `json:"last-used,omitempty"`
+	Expired     bool       `json:"expired"`
+}
+
+// APITokenInfoResults holds the result of an APITokens API call.
+type APITokenInfoResults struct {
+	Results []APITokenInfo `json:"results"`
+}
+
+// APITokenIds holds the ids of API tokens.
+type APITokenIds struct {
+	Ids []string `json:"ids"`
+}
@@ -413,6 +413,10 @@ func (p *ProvisionerAPI) getProvisioningInfo(m *state.Machine) (*params.Provisio
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	volumeZones, err := p.machineVolumeZones(m)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	// TODO(dimitern) For now, since network names and
 	// provider ids are the same, we return what we got
 	// from state. In the future, when networks can be
@@ -439,6 +443,7 @@ func (p *ProvisionerAPI) getProvisioningInfo(m *state.Machine) (*params.Provisio
 		Jobs:        jobs,
 		Volumes:     volumes,
 		Tags:        tags,
+		VolumeZones: volumeZones,
 	}, nil
 }
 
@@ -555,6 +560,38 @@ func (p *ProvisionerAPI) Constraints(args params.Entities) (params.ConstraintsRe
 	return result, nil
 }
 
+// machineVolumeZones returns the sorted, distinct availability zones
+// of the provisioned volumes that are to be attached to the machine.
+// Volumes can only be attached to machines in the same zone, so the
+// machine's instance must be started in one of these zones.
+func (p *ProvisionerAPI) machineVolumeZones(m *state.Machine) ([]string, error) {
+	volumeAttachments, err := m.VolumeAttachments()
+	if err != nil {
+		return nil, err
+	}
+	zones := set.NewStrings()
+	for _, volumeAttachment := range volumeAttachments {
+		volumeTag := volumeAttachment.Volume()
+		volume, err := p.st.Volume(volumeTag)
+		if err != nil {
+			return nil, errors.Annotatef(err, "getting volume %q", volumeTag.Id())
+		}
+		info, err := volume.Info()
+		if errors.IsNotProvisioned(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "getting volume %q info", volumeTag.Id())
+		}
+		if info.Zone != "" {
+			zones.Add(info.Zone)
+		}
+	}
+	if zones.IsEmpty() {
+		return nil, nil
+	}
+	return zones.SortedValues(), nil
+}
+
 // machineVolumeParams retrieves VolumeParams for the volumes that should be
 // provisioned with, and attached to, the machine. The client should ignore
 // parameters that it does not know how to handle.
@@ -578,6 +615,14 @@ func (p *ProvisionerAPI) machineVolumeParams(m *state.Machine) ([]params.VolumeP
 		if err != nil {
 			return nil, errors.Annotatef(err, "getting volume %q", volumeTag.Id())
 		}
+		if _, err := volume.Info(); err == nil {
+			// The volume already exists, so it must not be created
+			// with the machine; it constrains the machine's zone
+			// instead (see machineVolumeZones).
+			continue
+		} else if !errors.IsNotProvisioned(err) {
+			return nil, errors.Annotatef(err, "getting volume %q info", volumeTag.Id())
+		}
 		storageInstance, err := common.MaybeAssignedStorageInstance(
 			volume.StorageInstance, p.st.StorageInstance,
 		)
@@ -653,6 +698,7 @@ func volumeAttachmentsToState(in []params.VolumeAttachment) (map[names.VolumeTag
 		}
 		m[volumeTag] = state.VolumeAttachmentInfo{
 			v.Info.DeviceName,
+			v.Info.BusAddress,
 			v.Info.ReadOnly,
 		}
 	}
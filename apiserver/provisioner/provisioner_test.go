@@ -893,6 +893,41 @@ func (s *withoutStateServerSuite) TestStorageProviderFallbackToType(c *gc.C) {
 	})
 }
 
+func (s *withoutStateServerSuite) TestProvisioningInfoVolumeZones(c *gc.C) {
+	registry.RegisterProvider("dynamic", &storagedummy.StorageProvider{IsDynamic: true})
+	defer registry.RegisterProvider("dynamic", nil)
+	registry.RegisterEnvironStorageProviders("dummy", "dynamic")
+
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+		Volumes: []state.MachineVolumeParams{
+			{Volume: state.VolumeParams{Size: 1000, Pool: "dynamic"}},
+			{Volume: state.VolumeParams{Size: 1000, Pool: "dynamic"}},
+		},
+	}
+	m, err := s.State.AddOneMachine(template)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Only provisioned volumes have zones.
+	err = s.State.SetVolumeInfo(names.NewVolumeTag("0"), state.VolumeInfo{
+		VolumeId: "vol-0",
+		Size:     1000,
+		Zone:     "az-1",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: m.Tag().String()},
+	}}
+	result, err := s.provisioner.ProvisioningInfo(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Check(result.Results[0].Result.VolumeZones, jc.DeepEquals, []string{"az-1"})
+	c.Check(result.Results[0].Result.Volumes, gc.HasLen, 0)
+}
+
 func (s *withoutStateServerSuite) TestProvisioningInfoPermissions(c *gc.C) {
 	// Login as a machine agent for machine 0.
 	anAuthorizer := s.authorizer
@@ -1496,7 +1531,8 @@ func (s *withStateServerSuite) TestStateAddresses(c *gc.C) {
 }
 
 func (s *withStateServerSuite) TestCACert(c *gc.C) {
-	result := s.provisioner.CACert()
+	result, err := s.provisioner.CACert()
+	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, params.BytesResult{
 		Result: []byte(s.State.CACert()),
 	})
@@ -0,0 +1,226 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	apihttp "github.com/juju/juju/apiserver/http"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+)
+
+// resourcesHandler handles the upload and download of charm resources
+// through HTTPS in the API server.
+type resourcesHandler struct {
+	httpHandler
+}
+
+func (h *resourcesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stateWrapper, err := h.validateEnvironUUID(r)
+	if err != nil {
+		h.sendError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer stateWrapper.cleanup()
+
+	query := r.URL.Query()
+	serviceName := query.Get("service")
+	if !names.IsValidService(serviceName) {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("invalid service name %q", serviceName))
+		return
+	}
+	name := query.Get("name")
+	if !resource.IsValidName(name) {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("invalid resource name %q", name))
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		// Attach new content to a service's resource. Only users
+		// may do so.
+		tag, err := stateWrapper.authenticate(r)
+		if err == nil {
+			if _, ok := tag.(names.UserTag); !ok {
+				err = common.ErrBadCreds
+			}
+		}
+		if err != nil {
+			h.authError(w, h)
+			return
+		}
+		res, err := h.processPost(r, stateWrapper.state, serviceName, name, tag.(names.UserTag))
+		if errors.IsNotFound(err) {
+			h.sendError(w, http.StatusNotFound, err.Error())
+			return
+		} else if err != nil {
+			h.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.sendJSON(w, http.StatusOK, &params.ResourcesResponse{
+			Service:  res.Service(),
+			Name:     res.Name(),
+			Revision: res.Revision(),
+		})
+	case "GET":
+		// Retrieve the content of a service's resource. Users and
+		// the agents of the service's units may do so.
+		if !h.canRead(r, stateWrapper, serviceName) {
+			h.authError(w, h)
+			return
+		}
+		if err := h.processGet(w, r, stateWrapper.state, serviceName, name); errors.IsNotFound(err) {
+			h.sendError(w, http.StatusNotFound, err.Error())
+		} else if err != nil {
+			h.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+	default:
+		h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("unsupported method: %q", r.Method))
+	}
+}
+
+// canRead reports whether the request is authenticated as a user, or
+// as the agent of one of the named service's units.
+func (h *resourcesHandler) canRead(r *http.Request, stateWrapper *httpStateWrapper, serviceName string) bool {
+	tag, err := stateWrapper.authenticate(r)
+	if err != nil {
+		return false
+	}
+	switch tag := tag.(type) {
+	case names.UserTag:
+		return true
+	case names.UnitTag:
+		unitService, err := names.UnitService(tag.Id())
+		return err == nil && unitService == serviceName
+	}
+	return false
+}
+
+// sendJSON sends a JSON-encoded response to the client.
+func (h *resourcesHandler) sendJSON(w http.ResponseWriter, statusCode int, response *params.ResourcesResponse) error {
+	w.Header().Set("Content-Type", apihttp.CTypeJSON)
+	w.WriteHeader(statusCode)
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	w.Write(body)
+	return nil
+}
+
+// sendError sends a JSON-encoded error response.
+func (h *resourcesHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
+	if err := h.sendJSON(w, statusCode, &params.ResourcesResponse{Error: message}); err != nil {
+		logger.Errorf("failed to send error: %v", err)
+	}
+}
+
+// processPost handles a resource upload POST request after
+// authentication.
+func (h *resourcesHandler) processPost(r *http.Request, st *state.State, serviceName, name string, user names.UserTag) (*state.Resource, error) {
+	if contentType := r.Header.Get("Content-Type"); contentType != apihttp.CTypeRaw {
+		return nil, errors.Errorf("expected Content-Type: %s, got: %v", apihttp.CTypeRaw, contentType)
+	}
+	service, err := st.Service(serviceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := h.checkDeclared(st, service, name); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// Spool the upload to a temporary file so that its size and hash
+	// are known before it is stored.
+	tempFile, err := ioutil.TempFile("", "resource")
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create temp file")
+	}
+	defer tempFile.Close()
+	defer os.Remove(tempFile.Name())
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tempFile, hash), r.Body)
+	if err != nil {
+		return nil, errors.Annotate(err, "error processing file upload")
+	}
+	if _, err := tempFile.Seek(0, 0); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return st.SetResource(serviceName, name, tempFile, size, hex.EncodeToString(hash.Sum(nil)), user)
+}
+
+// checkDeclared returns an error if the named resource is not declared
+// by the charm of the given service.
+func (h *resourcesHandler) checkDeclared(st *state.State, service *state.Service, name string) error {
+	ch, _, err := service.Charm()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	reader, _, err := storage.NewStorage(st.EnvironUUID(), st.MongoSession()).Get(ch.StoragePath())
+	if err != nil {
+		return errors.Annotate(err, "cannot get charm from environment storage")
+	}
+	defer reader.Close()
+	// Charm archives can be large, and reading the metadata needs
+	// random access to the archive, so spool it to a temporary file
+	// rather than holding it in memory.
+	archive, err := ioutil.TempFile("", "charm")
+	if err != nil {
+		return errors.Annotate(err, "cannot create temp file")
+	}
+	defer archive.Close()
+	defer os.Remove(archive.Name())
+	size, err := io.Copy(archive, reader)
+	if err != nil {
+		return errors.Annotate(err, "cannot read charm archive")
+	}
+	declared, err := resource.ReadArchiveMeta(archive, size)
+	if err != nil {
+		return errors.Annotatef(err, "cannot read resources of charm %q", ch.URL())
+	}
+	if _, ok := declared[name]; !ok {
+		return errors.NotFoundf("resource %q in charm %q", name, ch.URL())
+	}
+	return nil
+}
+
+// processGet handles a resource download GET request after
+// authentication. The resource's hash is sent as its entity tag, so
+// that clients that already hold the current content can avoid
+// downloading it again.
+func (h *resourcesHandler) processGet(w http.ResponseWriter, r *http.Request, st *state.State, serviceName, name string) error {
+	res, reader, err := st.OpenResource(serviceName, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer reader.Close()
+
+	etag := fmt.Sprintf("%q", res.SHA256())
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	w.Header().Set("Content-Type", apihttp.CTypeRaw)
+	w.Header().Set("Digest", fmt.Sprintf("%s=%s", apihttp.DigestSHA, res.SHA256()))
+	w.Header().Set("Content-Length", fmt.Sprint(res.Size()))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, reader); err != nil {
+		logger.Errorf("failed to send resource %q of service %q: %v", name, serviceName, err)
+	}
+	return nil
+}
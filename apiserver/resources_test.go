@@ -0,0 +1,165 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	apihttp "github.com/juju/juju/apiserver/http"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type resourcesSuite struct {
+	userAuthHttpSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&resourcesSuite{})
+
+func (s *resourcesSuite) SetUpTest(c *gc.C) {
+	s.userAuthHttpSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "site", s.AddTestingCharm(c, "resources"))
+}
+
+func (s *resourcesSuite) resourcesURI(c *gc.C, service, name string) string {
+	uri := s.baseURL(c)
+	uri.Path = fmt.Sprintf("/environment/%s/resources", s.envUUID)
+	uri.RawQuery = url.Values{"service": {service}, "name": {name}}.Encode()
+	return uri.String()
+}
+
+func (s *resourcesSuite) upload(c *gc.C, service, name, content string) *http.Response {
+	resp, err := s.authRequest(c, "POST", s.resourcesURI(c, service, name), apihttp.CTypeRaw, strings.NewReader(content))
+	c.Assert(err, jc.ErrorIsNil)
+	return resp
+}
+
+func (s *resourcesSuite) assertUploadResponse(c *gc.C, resp *http.Response, revision int) {
+	body := assertResponse(c, resp, http.StatusOK, apihttp.CTypeJSON)
+	var result params.ResourcesResponse
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ResourcesResponse{
+		Service:  "site",
+		Name:     "site",
+		Revision: revision,
+	})
+}
+
+func (s *resourcesSuite) TestPOSTRequiresAuth(c *gc.C) {
+	resp, err := s.sendRequest(c, "", "", "POST", s.resourcesURI(c, "site", "site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
+}
+
+func (s *resourcesSuite) TestPOSTRequiresUser(c *gc.C) {
+	unit, password := s.Factory.MakeUnitReturningPassword(c, &factory.UnitParams{Service: s.service})
+	resp, err := s.sendRequest(c, unit.Tag().String(), password, "POST", s.resourcesURI(c, "site", "site"), apihttp.CTypeRaw, strings.NewReader("hello"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
+}
+
+func (s *resourcesSuite) TestRequiresPOSTorGET(c *gc.C) {
+	resp, err := s.authRequest(c, "PUT", s.resourcesURI(c, "site", "site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusMethodNotAllowed, `unsupported method: "PUT"`)
+}
+
+func (s *resourcesSuite) TestInvalidNames(c *gc.C) {
+	resp, err := s.authRequest(c, "GET", s.resourcesURI(c, "", "site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `invalid service name ""`)
+	resp, err = s.authRequest(c, "GET", s.resourcesURI(c, "site", "Site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `invalid resource name "Site"`)
+}
+
+func (s *resourcesSuite) TestUpload(c *gc.C) {
+	s.assertUploadResponse(c, s.upload(c, "site", "site", "hello"), 1)
+	s.assertUploadResponse(c, s.upload(c, "site", "site", "goodbye"), 2)
+
+	res, err := s.State.Resource("site", "site")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.Revision(), gc.Equals, 2)
+	c.Assert(res.Size(), gc.Equals, int64(7))
+	c.Assert(res.UploadedBy(), gc.Equals, s.userTag)
+	// sha256 of "goodbye"
+	c.Assert(res.SHA256(), gc.Equals, "82e35a63ceba37e9646434c5dd412ea577147f1e4a41ccde1614253187e3dbf9")
+}
+
+func (s *resourcesSuite) TestUploadRequiresRawContent(c *gc.C) {
+	resp, err := s.authRequest(c, "POST", s.resourcesURI(c, "site", "site"), "application/zip", strings.NewReader("hello"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected Content-Type: application/octet-stream, got: application/zip")
+}
+
+func (s *resourcesSuite) TestUploadUndeclaredResource(c *gc.C) {
+	resp := s.upload(c, "site", "tools", "hello")
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `resource "tools" in charm "local:quantal/resources-1" not found`)
+}
+
+func (s *resourcesSuite) TestUploadUnknownService(c *gc.C) {
+	resp := s.upload(c, "nosuch", "site", "hello")
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `service "nosuch" not found`)
+}
+
+func (s *resourcesSuite) TestGET(c *gc.C) {
+	s.assertUploadResponse(c, s.upload(c, "site", "site", "hello"), 1)
+	resp, err := s.authRequest(c, "GET", s.resourcesURI(c, "site", "site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	body := assertResponse(c, resp, http.StatusOK, apihttp.CTypeRaw)
+	c.Assert(string(body), gc.Equals, "hello")
+	// sha256 of "hello"
+	sha := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	c.Assert(resp.Header.Get("Digest"), gc.Equals, "SHA="+sha)
+	c.Assert(resp.Header.Get("ETag"), gc.Equals, `"`+sha+`"`)
+}
+
+func (s *resourcesSuite) TestGETNotModified(c *gc.C) {
+	s.assertUploadResponse(c, s.upload(c, "site", "site", "hello"), 1)
+	req, err := http.NewRequest("GET", s.resourcesURI(c, "site", "site"), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	req.SetBasicAuth(s.userTag.String(), s.password)
+	req.Header.Set("If-None-Match", `"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"`)
+	resp, err := utils.GetNonValidatingHTTPClient().Do(req)
+	c.Assert(err, jc.ErrorIsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusNotModified)
+}
+
+func (s *resourcesSuite) TestGETNotFound(c *gc.C) {
+	resp, err := s.authRequest(c, "GET", s.resourcesURI(c, "site", "site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusNotFound, `resource "site" of service "site" not found`)
+}
+
+func (s *resourcesSuite) TestGETByUnitAgent(c *gc.C) {
+	s.assertUploadResponse(c, s.upload(c, "site", "site", "hello"), 1)
+	unit, password := s.Factory.MakeUnitReturningPassword(c, &factory.UnitParams{Service: s.service})
+	resp, err := s.sendRequest(c, unit.Tag().String(), password, "GET", s.resourcesURI(c, "site", "site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(body), gc.Equals, "hello")
+}
+
+func (s *resourcesSuite) TestGETByOtherServiceUnitAgent(c *gc.C) {
+	s.assertUploadResponse(c, s.upload(c, "site", "site", "hello"), 1)
+	unit, password := s.Factory.MakeUnitReturningPassword(c, nil)
+	resp, err := s.sendRequest(c, unit.Tag().String(), password, "GET", s.resourcesURI(c, "site", "site"), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
+}
@@ -26,7 +26,9 @@ func newRestrictedRoot(finder rpc.MethodFinder) *restrictedRoot {
 // of the API server. Any facade added here needs to work across environment
 // boundaries.
 var restrictedRootNames = set.NewStrings(
+	"CleanupManager",
 	"EnvironmentManager",
+	"GarbageCollector",
 	"SystemManager",
 	"UserManager",
 )
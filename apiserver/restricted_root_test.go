@@ -46,6 +46,13 @@ func (r *restrictedRootSuite) TestFindAllowedMethod(c *gc.C) {
 	r.assertMethodAllowed(c, "SystemManager", 1, "DestroySystem")
 	r.assertMethodAllowed(c, "SystemManager", 1, "EnvironmentConfig")
 	r.assertMethodAllowed(c, "SystemManager", 1, "ListBlockedEnvironments")
+
+	r.assertMethodAllowed(c, "CleanupManager", 1, "ListCleanups")
+	r.assertMethodAllowed(c, "CleanupManager", 1, "RetryCleanups")
+	r.assertMethodAllowed(c, "CleanupManager", 1, "SkipCleanups")
+
+	r.assertMethodAllowed(c, "GarbageCollector", 1, "ListGarbage")
+	r.assertMethodAllowed(c, "GarbageCollector", 1, "CollectGarbage")
 }
 
 func (r *restrictedRootSuite) TestFindDisallowedMethod(c *gc.C) {
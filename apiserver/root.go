@@ -320,6 +320,13 @@ func (r *apiHandler) AuthClient() bool {
 	return isUser
 }
 
+// AuthAPIToken returns whether the authenticated entity logged in with
+// an API token.
+func (r *apiHandler) AuthAPIToken() bool {
+	_, isToken := r.entity.(*apiTokenEntity)
+	return isToken
+}
+
 // GetAuthTag returns the tag of the authenticated entity.
 func (r *apiHandler) GetAuthTag() names.Tag {
 	return r.entity.Tag()
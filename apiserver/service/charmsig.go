@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v5"
+
+	"github.com/juju/juju/charmsig"
+	"github.com/juju/juju/state"
+)
+
+// VerifyCharmSignature checks the armored detached signature of the
+// charm archive read from r against the trusted charm keys of the
+// environment, and returns the fingerprint of the key that made it.
+//
+// If the environment has no trusted charm keys, charms need not be
+// signed and "" is returned; otherwise a signature made by one of the
+// trusted keys is required.
+func VerifyCharmSignature(st *state.State, r io.Reader, signature string) (string, error) {
+	envConfig, err := st.EnvironConfig()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	keys, ok := envConfig.TrustedCharmKeys()
+	if !ok {
+		if signature != "" {
+			return "", errors.New("cannot verify charm signature: no trusted charm keys configured")
+		}
+		return "", nil
+	}
+	if signature == "" {
+		return "", errors.New("charm is not signed, but the environment only accepts signed charms")
+	}
+	fingerprint, err := charmsig.Verify(r, signature, keys)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return fingerprint, nil
+}
+
+// SetCharmSignedBy records that the signature of the given charm's
+// archive was made by the key with the given fingerprint. It does
+// nothing if the fingerprint is empty.
+func SetCharmSignedBy(st *state.State, curl *charm.URL, fingerprint string) error {
+	if fingerprint == "" {
+		return nil
+	}
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(ch.SetSignedBy(fingerprint))
+}
+
+// CheckCharmTrusted returns an error if the environment has trusted
+// charm keys and the given charm was not signed by any of them. Keys
+// that have been removed from the trusted keys since the charm was
+// added are no longer trusted.
+func CheckCharmTrusted(st *state.State, ch *state.Charm) error {
+	envConfig, err := st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	keys, ok := envConfig.TrustedCharmKeys()
+	if !ok {
+		return nil
+	}
+	if ch.SignedBy() == "" {
+		return errors.Errorf("charm %q is not signed by a trusted key", ch)
+	}
+	trusted, err := charmsig.IsTrusted(ch.SignedBy(), keys)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !trusted {
+		return errors.Errorf("charm %q was signed by key %s, which is no longer trusted", ch, ch.SignedBy())
+	}
+	return nil
+}
+
+// verifyStoredCharmSignature verifies the signature of the archive of
+// a charm that has already been added to the environment, and records
+// the key that made it.
+func verifyStoredCharmSignature(st *state.State, ch *state.Charm, signature string) error {
+	storage := newStateStorage(st.EnvironUUID(), st.MongoSession())
+	reader, _, err := storage.Get(ch.StoragePath())
+	if err != nil {
+		return errors.Annotate(err, "cannot get charm from environment storage")
+	}
+	defer reader.Close()
+	fingerprint, err := VerifyCharmSignature(st, reader, signature)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return SetCharmSignedBy(st, ch.URL(), fingerprint)
+}
@@ -52,8 +52,12 @@ func AddCharmWithAuthorization(st *state.State, args params.AddCharmWithAuthoriz
 		return err
 	}
 	if stateCharm.IsUploaded() {
-		// Charm already in state (it was uploaded already).
-		return nil
+		// Charm already in state (it was uploaded already). A
+		// signature given now is only verified if none has been.
+		if args.Signature == "" || stateCharm.SignedBy() != "" {
+			return nil
+		}
+		return verifyStoredCharmSignature(st, stateCharm, args.Signature)
 	}
 
 	// Get the charm and its information from the store.
@@ -106,16 +110,26 @@ func AddCharmWithAuthorization(st *state.State, args params.AddCharmWithAuthoriz
 	if _, err := archive.Seek(0, 0); err != nil {
 		return errors.Annotate(err, "cannot rewind charm archive")
 	}
+	signedBy, err := VerifyCharmSignature(st, archive, args.Signature)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := archive.Seek(0, 0); err != nil {
+		return errors.Annotate(err, "cannot rewind charm archive")
+	}
 
 	// Store the charm archive in environment storage.
-	return StoreCharmArchive(
+	if err := StoreCharmArchive(
 		st,
 		charmURL,
 		downloadedCharm,
 		archive,
 		size,
 		bundleSHA256,
-	)
+	); err != nil {
+		return errors.Trace(err)
+	}
+	return SetCharmSignedBy(st, charmURL, signedBy)
 }
 
 // StoreCharmArchive stores a charm archive in environment storage.
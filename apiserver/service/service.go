@@ -14,6 +14,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
 	jjj "github.com/juju/juju/juju"
 	"github.com/juju/juju/state"
 	statestorage "github.com/juju/juju/state/storage"
@@ -23,6 +24,10 @@ var (
 	logger = loggo.GetLogger("juju.apiserver.service")
 
 	newStateStorage = statestorage.NewStorage
+
+	// newEnviron is called to obtain the environ whose quotas are
+	// enforced when deploying services.
+	newEnviron = environs.New
 )
 
 func init() {
@@ -95,6 +100,14 @@ func (api *API) ServicesDeployWithPlacement(args params.ServicesDeploy) (params.
 	if err := api.check.ChangeAllowed(); err != nil {
 		return result, errors.Trace(err)
 	}
+	quotas := common.NewQuotaChecker(common.NewQuotaBackend(api.state), newEnviron)
+	requests := make([]params.QuotaPreflight, len(args.Services))
+	for i, arg := range args.Services {
+		requests[i] = quotas.ServiceDeployRequest(arg)
+	}
+	if err := quotas.Enforce(requests...); err != nil {
+		return result, errors.Trace(err)
+	}
 	owner := api.authorizer.GetAuthTag().String()
 	for i, arg := range args.Services {
 		err := DeployService(api.state, owner, arg)
@@ -139,6 +152,9 @@ func DeployService(st *state.State, owner string, args params.ServiceDeploy) err
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if err := CheckCharmTrusted(st, ch); err != nil {
+		return errors.Trace(err)
+	}
 
 	var settings charm.Settings
 	if len(args.ConfigYAML) > 0 {
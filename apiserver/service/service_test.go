@@ -6,6 +6,7 @@ package service_test
 import (
 	"fmt"
 	"io"
+	"os"
 	"sync"
 
 	"github.com/juju/errors"
@@ -21,7 +22,11 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/apiserver/service"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/charmsig"
+	charmsigtesting "github.com/juju/juju/charmsig/testing"
 	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
@@ -261,6 +266,48 @@ func (s *serviceSuite) TestClientServiceDeployWithInvalidStoragePool(c *gc.C) {
 	c.Assert(results.Results[0].Error, gc.ErrorMatches, `.* pool "foo" not found`)
 }
 
+type mockQuotaEnviron struct {
+	environs.Environ
+	quotas environs.Quotas
+}
+
+func (e *mockQuotaEnviron) Quotas() (environs.Quotas, error) {
+	return e.quotas, nil
+}
+
+func (s *serviceSuite) TestServicesDeployQuotasRefused(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"quota-preflight": "refuse"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	instances := 1
+	s.PatchValue(service.NewEnviron, func(cfg *config.Config) (environs.Environ, error) {
+		env, err := environs.New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &mockQuotaEnviron{env, environs.Quotas{Instances: &instances}}, nil
+	})
+	curl, _ := s.UploadCharm(c, "precise/dummy-0", "dummy")
+
+	// Each service fits in the quota, but both do not.
+	results, err := s.serviceApi.ServicesDeploy(params.ServicesDeploy{
+		Services: []params.ServiceDeploy{{
+			ServiceName: "one",
+			CharmUrl:    curl.String(),
+			NumUnits:    1,
+		}, {
+			ServiceName: "two",
+			CharmUrl:    curl.String(),
+			NumUnits:    1,
+		}},
+	})
+	c.Assert(err, gc.ErrorMatches, `request would exceed provider quotas: 2 instances requested, 1 remaining in quota`)
+	c.Assert(results.Results, gc.HasLen, 2)
+	for _, name := range []string{"one", "two"} {
+		_, err = s.State.Service(name)
+		c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	}
+}
+
 func (s *serviceSuite) TestClientServiceDeployWithUnsupportedStoragePool(c *gc.C) {
 	registry.RegisterProvider("hostloop", &mockStorageProvider{kind: storage.StorageKindBlock})
 	pm := poolmanager.New(state.NewStateSettings(s.State))
@@ -458,6 +505,102 @@ func (s *serviceSuite) TestAddCharmWithAuthorization(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (s *serviceSuite) signCharm(c *gc.C, key charmsigtesting.Key, ch charm.Charm) string {
+	f, err := os.Open(ch.(*charm.CharmArchive).Path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	signature, err := charmsig.Sign(f, key.Private, "")
+	c.Assert(err, jc.ErrorIsNil)
+	return signature
+}
+
+func (s *serviceSuite) trustCharmKeys(c *gc.C, keys string) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"trusted-charm-keys": keys}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *serviceSuite) TestAddSignedCharm(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	s.trustCharmKeys(c, key.Public)
+	curl, ch := s.UploadCharm(c, "precise/wordpress-3", "wordpress")
+
+	err := service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{
+		URL:       curl.String(),
+		Signature: s.signCharm(c, key, ch),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.SignedBy(), gc.Equals, key.Fingerprint)
+}
+
+func (s *serviceSuite) TestAddUnsignedCharmWithTrustedKeys(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	s.trustCharmKeys(c, key.Public)
+	curl, _ := s.UploadCharm(c, "precise/wordpress-3", "wordpress")
+
+	err := service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{URL: curl.String()})
+	c.Assert(err, gc.ErrorMatches, "charm is not signed, but the environment only accepts signed charms")
+}
+
+func (s *serviceSuite) TestAddCharmSignsAlreadyAddedCharm(c *gc.C) {
+	curl, ch := s.UploadCharm(c, "precise/wordpress-3", "wordpress")
+	err := service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{URL: curl.String()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	key := charmsigtesting.NewKey(c, "signer")
+	s.trustCharmKeys(c, key.Public)
+	err = service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{
+		URL:       curl.String(),
+		Signature: s.signCharm(c, key, ch),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.SignedBy(), gc.Equals, key.Fingerprint)
+}
+
+func (s *serviceSuite) TestServiceDeployRequiresTrustedCharm(c *gc.C) {
+	curl, _ := s.UploadCharm(c, "precise/dummy-42", "dummy")
+	err := service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{URL: curl.String()})
+	c.Assert(err, jc.ErrorIsNil)
+	key := charmsigtesting.NewKey(c, "signer")
+	s.trustCharmKeys(c, key.Public)
+
+	results, err := s.serviceApi.ServicesDeploy(params.ServicesDeploy{
+		Services: []params.ServiceDeploy{{
+			ServiceName: "service",
+			CharmUrl:    curl.String(),
+			NumUnits:    1,
+		}}},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, `charm "cs:precise/dummy-42" is not signed by a trusted key`)
+}
+
+func (s *serviceSuite) TestCheckCharmTrusted(c *gc.C) {
+	curl, ch := s.UploadCharm(c, "precise/dummy-42", "dummy")
+	key := charmsigtesting.NewKey(c, "signer")
+	other := charmsigtesting.NewKey(c, "other")
+	s.trustCharmKeys(c, key.Public+other.Public)
+	err := service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{
+		URL:       curl.String(),
+		Signature: s.signCharm(c, key, ch),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = service.CheckCharmTrusted(s.State, sch)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Keys that are no longer trusted are not honoured.
+	s.trustCharmKeys(c, other.Public)
+	err = service.CheckCharmTrusted(s.State, sch)
+	c.Assert(err, gc.ErrorMatches, `charm "cs:precise/dummy-42" was signed by key [0-9A-F]+, which is no longer trusted`)
+}
+
 func (s *serviceSuite) TestAddCharmConcurrently(c *gc.C) {
 	var putBarrier sync.WaitGroup
 	var blobs blobs
@@ -265,6 +265,8 @@ type mockState struct {
 	watchStorageAttachment              func(names.StorageTag, names.UnitTag) state.NotifyWatcher
 	watchFilesystemAttachment           func(names.MachineTag, names.FilesystemTag) state.NotifyWatcher
 	watchVolumeAttachment               func(names.MachineTag, names.VolumeTag) state.NotifyWatcher
+	blockDevices                        func(names.MachineTag) ([]state.BlockDeviceInfo, error)
+	watchBlockDevices                   func(names.MachineTag) state.NotifyWatcher
 	envName                             string
 	volume                              func(tag names.VolumeTag) (state.Volume, error)
 	machineVolumeAttachments            func(machine names.MachineTag) ([]state.VolumeAttachment, error)
@@ -318,6 +320,14 @@ func (st *mockState) WatchVolumeAttachment(mtag names.MachineTag, v names.Volume
 	return st.watchVolumeAttachment(mtag, v)
 }
 
+func (st *mockState) BlockDevices(mtag names.MachineTag) ([]state.BlockDeviceInfo, error) {
+	return st.blockDevices(mtag)
+}
+
+func (st *mockState) WatchBlockDevices(mtag names.MachineTag) state.NotifyWatcher {
+	return st.watchBlockDevices(mtag)
+}
+
 func (st *mockState) EnvName() (string, error) {
 	return st.envName, nil
 }
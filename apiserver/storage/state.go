@@ -44,6 +44,12 @@ type storageAccess interface {
 	// WatchVolumeAttachment is required for storage functionality.
 	WatchVolumeAttachment(names.MachineTag, names.VolumeTag) state.NotifyWatcher
 
+	// BlockDevices is required for storage functionality.
+	BlockDevices(names.MachineTag) ([]state.BlockDeviceInfo, error)
+
+	// WatchBlockDevices is required for storage functionality.
+	WatchBlockDevices(names.MachineTag) state.NotifyWatcher
+
 	// EnvName is required for pool functionality.
 	EnvName() (string, error)
 
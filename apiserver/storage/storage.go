@@ -489,6 +489,7 @@ func (a *API) convertStateVolumeToParams(st state.Volume) (params.VolumeInstance
 		volume.Size = info.Size
 		volume.Persistent = info.Persistent
 		volume.VolumeId = info.VolumeId
+		volume.Zone = info.Zone
 	}
 	return volume, nil
 }
@@ -511,6 +512,7 @@ func convertStateVolumeAttachmentToParams(attachment state.VolumeAttachment) par
 	if info, err := attachment.Info(); err == nil {
 		result.Info = params.VolumeAttachmentInfo{
 			info.DeviceName,
+			info.BusAddress,
 			info.ReadOnly,
 		}
 	}
@@ -30,6 +30,7 @@ type SystemManager interface {
 	AllEnvironments() (params.UserEnvironmentList, error)
 	DestroySystem(args params.DestroySystemArgs) error
 	EnvironmentConfig() (params.EnvironmentConfigResults, error)
+	InitiateEnvironmentMigration(args params.InitiateEnvironmentMigrationArgs) (params.ErrorResults, error)
 	ListBlockedEnvironments() (params.EnvironmentBlockInfoList, error)
 	RemoveBlocks(args params.RemoveBlocksArgs) error
 }
@@ -245,6 +246,51 @@ func (s *SystemManagerAPI) EnvironmentConfig() (params.EnvironmentConfigResults,
 	return result, nil
 }
 
+// InitiateEnvironmentMigration starts the migration of the specified
+// environments to other systems. The migrations are carried out by
+// the migration master worker of each environment.
+func (s *SystemManagerAPI) InitiateEnvironmentMigration(args params.InitiateEnvironmentMigrationArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Specs)),
+	}
+	for i, spec := range args.Specs {
+		err := s.initiateOneMigration(spec)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (s *SystemManagerAPI) initiateOneMigration(spec params.EnvironmentMigrationSpec) error {
+	envTag, err := names.ParseEnvironTag(spec.EnvironTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	authTag, err := names.ParseUserTag(spec.TargetInfo.AuthTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The agents of a migrated environment are handed the target's CA
+	// certificate when they are redirected, so it must be known.
+	if spec.TargetInfo.CACert == "" {
+		return errors.New("target CA certificate not specified")
+	}
+	st, err := s.state.ForEnviron(envTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer st.Close()
+	_, err = st.CreateEnvMigration(state.EnvMigrationSpec{
+		InitiatedBy: s.apiUser,
+		TargetInfo: state.MigrationTargetInfo{
+			Addrs:    spec.TargetInfo.Addrs,
+			CACert:   spec.TargetInfo.CACert,
+			AuthTag:  authTag,
+			Password: spec.TargetInfo.Password,
+		},
+	})
+	return errors.Trace(err)
+}
+
 // RemoveBlocks removes all the blocks in the system.
 func (s *SystemManagerAPI) RemoveBlocks(args params.RemoveBlocksArgs) error {
 	if !args.All {
@@ -179,3 +179,56 @@ func (s *systemManagerSuite) TestRemoveBlocksNotAll(c *gc.C) {
 	err := s.systemManager.RemoveBlocks(params.RemoveBlocksArgs{})
 	c.Assert(err, gc.ErrorMatches, "not supported")
 }
+
+func (s *systemManagerSuite) migrationSpec(envTag names.EnvironTag) params.EnvironmentMigrationSpec {
+	return params.EnvironmentMigrationSpec{
+		EnvironTag: envTag.String(),
+		TargetInfo: params.MigrationTargetInfo{
+			Addrs:    []string{"10.0.0.1:17070"},
+			CACert:   s.State.CACert(),
+			AuthTag:  names.NewUserTag("admin").String(),
+			Password: "secret",
+		},
+	}
+}
+
+func (s *systemManagerSuite) TestInitiateEnvironmentMigration(c *gc.C) {
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+
+	results, err := s.systemManager.InitiateEnvironmentMigration(params.InitiateEnvironmentMigrationArgs{
+		Specs: []params.EnvironmentMigrationSpec{s.migrationSpec(st.EnvironTag())},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+
+	migration, err := st.EnvMigration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.Phase(), gc.Equals, state.MigrationExport)
+	c.Assert(migration.InitiatedBy(), gc.Equals, s.AdminUserTag(c))
+	c.Assert(migration.TargetInfo().Addrs, jc.DeepEquals, []string{"10.0.0.1:17070"})
+}
+
+func (s *systemManagerSuite) TestInitiateEnvironmentMigrationErrors(c *gc.C) {
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+
+	badCert := s.migrationSpec(st.EnvironTag())
+	badCert.TargetInfo.CACert = ""
+	badTag := s.migrationSpec(st.EnvironTag())
+	badTag.EnvironTag = "machine-0"
+
+	results, err := s.systemManager.InitiateEnvironmentMigration(params.InitiateEnvironmentMigrationArgs{
+		Specs: []params.EnvironmentMigrationSpec{
+			badCert,
+			badTag,
+			s.migrationSpec(s.State.EnvironTag()),
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Check(results.Results[0].Error, gc.ErrorMatches, "target CA certificate not specified")
+	c.Check(results.Results[1].Error, gc.ErrorMatches, `"machine-0" is not a valid environment tag`)
+	c.Check(results.Results[2].Error, gc.ErrorMatches, "cannot migrate the state server environment")
+}
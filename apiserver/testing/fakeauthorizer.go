@@ -11,6 +11,7 @@ import (
 type FakeAuthorizer struct {
 	Tag            names.Tag
 	EnvironManager bool
+	APIToken       bool
 }
 
 func (fa FakeAuthorizer) AuthOwner(tag names.Tag) bool {
@@ -40,6 +41,12 @@ func (fa FakeAuthorizer) AuthClient() bool {
 	return isUser
 }
 
+// AuthAPIToken returns whether the authenticated entity logged in with
+// an API token.
+func (fa FakeAuthorizer) AuthAPIToken() bool {
+	return fa.APIToken
+}
+
 func (fa FakeAuthorizer) GetAuthTag() names.Tag {
 	return fa.Tag
 }
@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+)
+
+// apiTokenEntity is the entity of a user that logged in with an API
+// token. Such connections may not change the user's credentials.
+type apiTokenEntity struct {
+	state.Entity
+}
+
+// checkAPITokenCreds validates a user logging in with an API token
+// instead of a password. Tokens are scoped to a single environment, so
+// they cannot be used to log in to the server without an environment,
+// and the user must still have access to the environment. The last use
+// time of the token is updated on a successful login.
+func checkAPITokenCreds(st *state.State, tag names.UserTag, id, secret string, lookForEnvUser bool) (state.Entity, *time.Time, error) {
+	if !lookForEnvUser {
+		logger.Debugf("API token %q used without an environment", id)
+		return nil, nil, common.ErrBadCreds
+	}
+	token, err := st.APIToken(id)
+	if errors.IsNotFound(err) {
+		logger.Debugf("API token %q not found", id)
+		return nil, nil, common.ErrBadCreds
+	}
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if token.Owner().Username() != strings.ToLower(tag.Username()) ||
+		token.EnvironUUID() != st.EnvironUUID() ||
+		!token.SecretValid(secret) {
+		logger.Debugf("bad credentials for API token %q", id)
+		return nil, nil, common.ErrBadCreds
+	}
+
+	var entity state.Entity = &authentication.ExternalUser{
+		ExternalIdentity: authentication.ExternalIdentity{User: tag},
+	}
+	if tag.IsLocal() {
+		user, err := st.User(tag)
+		if errors.IsNotFound(err) {
+			return nil, nil, common.ErrBadCreds
+		}
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if user.IsDisabled() {
+			return nil, nil, common.ErrBadCreds
+		}
+		entity = user
+	}
+
+	envUser, err := findEnvironmentUser(st, tag)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, common.ErrBadCreds)
+	}
+	var lastLogin *time.Time
+	if envUser != nil {
+		lastLogin = envUser.LastConnection()
+		envUser.UpdateLastConnection()
+	}
+	if user, ok := entity.(*state.User); ok {
+		user.UpdateLastLogin()
+	}
+	token.UpdateLastUsed()
+	return &apiTokenEntity{entity}, lastLogin, nil
+}
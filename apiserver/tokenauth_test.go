@@ -0,0 +1,137 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/usermanager"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type tokenAuthSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&tokenAuthSuite{})
+
+func (s *tokenAuthSuite) openAs(c *gc.C, tag names.Tag, credential string, envTag names.EnvironTag) (*api.State, error) {
+	info := s.APIInfo(c)
+	info.Tag = tag
+	info.Password = credential
+	info.EnvironTag = envTag
+	st, err := api.Open(info, fastDialOpts)
+	if err == nil {
+		s.AddCleanup(func(*gc.C) { st.Close() })
+	}
+	return st, err
+}
+
+func (s *tokenAuthSuite) addToken(c *gc.C, user names.UserTag, expires time.Time) (*state.APIToken, string) {
+	token, credential, err := s.State.AddAPIToken(user, expires)
+	c.Assert(err, jc.ErrorIsNil)
+	return token, credential
+}
+
+func (s *tokenAuthSuite) TestLoginWithToken(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "password"})
+	token, credential := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	startTime := state.NowToTheSecond()
+
+	st, err := s.openAs(c, user.Tag(), credential, s.State.EnvironTag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = st.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	token, err = s.State.APIToken(token.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.LastUsed(), gc.NotNil)
+	c.Assert(token.LastUsed().Before(startTime), jc.IsFalse)
+
+	// The password keeps working.
+	_, err = s.openAs(c, user.Tag(), "password", s.State.EnvironTag())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *tokenAuthSuite) TestTokenCannotChangeCredentials(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "password"})
+	_, credential := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	st, err := s.openAs(c, user.Tag(), credential, s.State.EnvironTag())
+	c.Assert(err, jc.ErrorIsNil)
+	client := usermanager.NewClient(st)
+
+	err = client.SetPassword(user.Name(), "stolen")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = client.AddAPIToken(24 * time.Hour)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	// The user's own password is unaffected.
+	_, err = s.openAs(c, user.Tag(), "password", s.State.EnvironTag())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *tokenAuthSuite) TestLoginWithBadSecret(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	token, _ := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	_, err := s.openAs(c, user.Tag(), state.FormatAPIToken(token.Id(), "wrong"), s.State.EnvironTag())
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthSuite) TestLoginWithOtherUsersToken(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	other := s.Factory.MakeUser(c, &factory.UserParams{Name: "mary"})
+	_, credential := s.addToken(c, other.UserTag(), time.Now().Add(time.Hour))
+	_, err := s.openAs(c, user.Tag(), credential, s.State.EnvironTag())
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthSuite) TestLoginWithRevokedToken(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	token, credential := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	err := s.State.RemoveAPIToken(token.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.openAs(c, user.Tag(), credential, s.State.EnvironTag())
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthSuite) TestLoginWithExpiredToken(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	_, credential := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	s.PatchValue(state.APITokenNow, func() time.Time {
+		return time.Now().Add(2 * time.Hour)
+	})
+	_, err := s.openAs(c, user.Tag(), credential, s.State.EnvironTag())
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthSuite) TestLoginToOtherEnvironment(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	_, credential := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	envState := s.Factory.MakeEnvironment(c, &factory.EnvParams{Owner: user.UserTag()})
+	defer envState.Close()
+
+	_, err := s.openAs(c, user.Tag(), credential, envState.EnvironTag())
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthSuite) TestLoginWithoutEnvironment(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	_, credential := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	_, err := s.openAs(c, user.Tag(), credential, names.EnvironTag{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *tokenAuthSuite) TestLoginWithTokenDisabledUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Disabled: true})
+	_, credential := s.addToken(c, user.UserTag(), time.Now().Add(time.Hour))
+	_, err := s.openAs(c, user.Tag(), credential, s.State.EnvironTag())
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
@@ -0,0 +1,74 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// ResourcesVersion returns the version of the charm resources of each
+// given service, or the version each given unit last reacted to.
+func (u *UniterAPIV2) ResourcesVersion(args params.Entities) (params.IntResults, error) {
+	result := params.IntResults{
+		Results: make([]params.IntResult, len(args.Entities)),
+	}
+	accessUnitOrService := common.AuthEither(u.accessUnit, u.accessService)
+	canAccess, err := accessUnitOrService()
+	if err != nil {
+		return params.IntResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unitOrService state.Entity
+			unitOrService, err = u.st.FindEntity(tag)
+			if err == nil {
+				versioner := unitOrService.(interface {
+					ResourcesVersion() int
+				})
+				result.Results[i].Result = versioner.ResourcesVersion()
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetResourcesVersion records, for each given unit, the version of its
+// service's charm resources that it has reacted to.
+func (u *UniterAPIV2) SetResourcesVersion(args params.EntitiesResourcesVersion) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(tag)
+			if err == nil {
+				err = unit.SetResourcesVersion(entity.Version)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
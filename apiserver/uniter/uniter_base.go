@@ -875,7 +875,7 @@ func (u *uniterBaseAPI) FinishActions(args params.ActionExecutionResults) (param
 			results.Results[i].Error = common.ServerError(err)
 			continue
 		}
-		actionResults, err := paramsActionExecutionResultsToStateActionResults(arg)
+		actionResults, err := common.ParamsActionExecutionResultsToStateActionResults(arg)
 		if err != nil {
 			results.Results[i].Error = common.ServerError(err)
 			continue
@@ -891,29 +891,6 @@ func (u *uniterBaseAPI) FinishActions(args params.ActionExecutionResults) (param
 	return results, nil
 }
 
-// paramsActionExecutionResultsToStateActionResults does exactly what
-// the name implies.
-func paramsActionExecutionResultsToStateActionResults(arg params.ActionExecutionResult) (state.ActionResults, error) {
-	var status state.ActionStatus
-	switch arg.Status {
-	case params.ActionCancelled:
-		status = state.ActionCancelled
-	case params.ActionCompleted:
-		status = state.ActionCompleted
-	case params.ActionFailed:
-		status = state.ActionFailed
-	case params.ActionPending:
-		status = state.ActionPending
-	default:
-		return state.ActionResults{}, errors.Errorf("unrecognized action status '%s'", arg.Status)
-	}
-	return state.ActionResults{
-		Status:  status,
-		Results: arg.Results,
-		Message: arg.Message,
-	}, nil
-}
-
 // RelationById returns information about all given relations,
 // specified by their ids, including their key and the local
 // endpoint.
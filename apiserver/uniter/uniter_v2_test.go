@@ -4,6 +4,7 @@
 package uniter_test
 
 import (
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
@@ -274,3 +275,54 @@ func (s *unitMetricBatchesSuite) TestAddMetricsBatchDiffTag(c *gc.C) {
 		c.Assert(err, jc.Satisfies, errors.IsNotFound)
 	}
 }
+
+func (s *uniterV2Suite) TestResourcesVersion(c *gc.C) {
+	_, err := s.State.SetResource(
+		"wordpress", "site", strings.NewReader("hello"), 5, "sha", s.AdminUserTag(c),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-wordpress-0"},
+		{Tag: "service-wordpress"},
+		{Tag: "unit-mysql-0"},
+		{Tag: "service-mysql"},
+		{Tag: "machine-0"},
+		{Tag: "invalid"},
+	}}
+	result, err := s.uniter.ResourcesVersion(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.IntResults{
+		Results: []params.IntResult{
+			{Result: 0},
+			{Result: 1},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV2Suite) TestSetResourcesVersion(c *gc.C) {
+	args := params.EntitiesResourcesVersion{Entities: []params.EntityResourcesVersion{
+		{Tag: "unit-wordpress-0", Version: 3},
+		{Tag: "unit-mysql-0", Version: 3},
+		{Tag: "service-wordpress", Version: 3},
+		{Tag: "invalid", Version: 3},
+	}}
+	result, err := s.uniter.SetResourcesVersion(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.wordpressUnit.ResourcesVersion(), gc.Equals, 3)
+}
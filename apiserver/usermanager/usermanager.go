@@ -4,6 +4,9 @@
 package usermanager
 
 import (
+	"strings"
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
@@ -22,9 +25,16 @@ func init() {
 // UserManager defines the methods on the usermanager API end point.
 type UserManager interface {
 	AddUser(args params.AddUsers) (params.AddUserResults, error)
+	AddAPIToken(args params.AddAPITokens) (params.AddAPITokenResults, error)
+	AddGroup(args params.AddGroups) (params.ErrorResults, error)
+	AddGroupMembers(args params.GroupMembers) (params.ErrorResults, error)
+	RemoveGroupMembers(args params.GroupMembers) (params.ErrorResults, error)
+	APITokens() (params.APITokenInfoResults, error)
 	DisableUser(args params.Entities) (params.ErrorResults, error)
 	EnableUser(args params.Entities) (params.ErrorResults, error)
+	RevokeAPITokens(args params.APITokenIds) (params.ErrorResults, error)
 	SetPassword(args params.EntityPasswords) (params.ErrorResults, error)
+	UnlockUser(args params.Entities) (params.ErrorResults, error)
 	UserInfo(args params.UserInfoRequest) (params.UserInfoResults, error)
 }
 
@@ -102,6 +112,207 @@ func (api *UserManagerAPI) AddUser(args params.AddUsers) (params.AddUserResults,
 	return result, nil
 }
 
+// adminCheck returns an error unless changes are allowed and the
+// logged in user may manage users.
+func (api *UserManagerAPI) adminCheck() (names.UserTag, error) {
+	if err := api.check.ChangeAllowed(); err != nil {
+		return names.UserTag{}, errors.Trace(err)
+	}
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return names.UserTag{}, errors.Wrap(err, common.ErrPerm)
+	}
+	// TODO(thumper): PERMISSIONS Change this permission check when we have
+	// real permissions. For now, only the owner of the initial environment is
+	// able to manage groups.
+	if err := api.permissionCheck(loggedInUser); err != nil {
+		return names.UserTag{}, errors.Trace(err)
+	}
+	return loggedInUser, nil
+}
+
+// AddGroup adds user groups with no members.
+func (api *UserManagerAPI) AddGroup(args params.AddGroups) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Groups)),
+	}
+	if len(args.Groups) == 0 {
+		return result, nil
+	}
+	loggedInUser, err := api.adminCheck()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for i, arg := range args.Groups {
+		if _, err := api.state.AddUserGroup(arg.Name, loggedInUser.Id()); err != nil {
+			err = errors.Annotate(err, "failed to create group")
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
+// AddGroupMembers adds users to user groups. Adding a user to a group
+// they already belong to is considered a success.
+func (api *UserManagerAPI) AddGroupMembers(args params.GroupMembers) (params.ErrorResults, error) {
+	return api.modifyGroupMembers(args, "add", (*state.UserGroup).AddMember)
+}
+
+// RemoveGroupMembers removes users from user groups.
+func (api *UserManagerAPI) RemoveGroupMembers(args params.GroupMembers) (params.ErrorResults, error) {
+	return api.modifyGroupMembers(args, "remove", (*state.UserGroup).RemoveMember)
+}
+
+func (api *UserManagerAPI) modifyGroupMembers(args params.GroupMembers, action string, method func(*state.UserGroup, names.UserTag) error) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Members)),
+	}
+	if len(args.Members) == 0 {
+		return result, nil
+	}
+	if _, err := api.adminCheck(); err != nil {
+		return result, errors.Trace(err)
+	}
+	for i, arg := range args.Members {
+		user, err := names.ParseUserTag(arg.UserTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		group, err := api.state.UserGroup(arg.Group)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		if err := method(group, user); err != nil {
+			err = errors.Annotatef(err, "failed to %s group member", action)
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
+// AddAPIToken creates API tokens that let the logged in user log in to
+// the environment of the API connection without their password.
+func (api *UserManagerAPI) AddAPIToken(args params.AddAPITokens) (params.AddAPITokenResults, error) {
+	result := params.AddAPITokenResults{
+		Results: make([]params.AddAPITokenResult, len(args.Tokens)),
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+	if len(args.Tokens) == 0 {
+		return result, nil
+	}
+	// A token must not be able to outlive itself by minting others.
+	if api.authorizer.AuthAPIToken() {
+		return result, common.ErrPerm
+	}
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	hasAccess, err := api.state.HasEnvironmentAccess(loggedInUser)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if !hasAccess {
+		return result, common.ErrPerm
+	}
+	for i, arg := range args.Tokens {
+		if arg.ExpiresIn <= 0 {
+			result.Results[i].Error = common.ServerError(errors.New("token expiry must be positive"))
+			continue
+		}
+		token, credential, err := api.state.AddAPIToken(loggedInUser, time.Now().Add(arg.ExpiresIn))
+		if err != nil {
+			err = errors.Annotate(err, "failed to create API token")
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i] = params.AddAPITokenResult{
+			Id:         token.Id(),
+			Credential: credential,
+			Expires:    token.Expires(),
+		}
+	}
+	return result, nil
+}
+
+// APITokens returns information on the API tokens of the logged in
+// user, across all environments.
+func (api *UserManagerAPI) APITokens() (params.APITokenInfoResults, error) {
+	var result params.APITokenInfoResults
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	tokens, err := api.state.APITokensForUser(loggedInUser)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	envNames := make(map[string]string)
+	for _, token := range tokens {
+		uuid := token.EnvironUUID()
+		envName, ok := envNames[uuid]
+		if !ok {
+			env, err := api.state.GetEnvironment(names.NewEnvironTag(uuid))
+			if err == nil {
+				envName = env.Name()
+			} else if !errors.IsNotFound(err) {
+				return result, errors.Trace(err)
+			}
+			envNames[uuid] = envName
+		}
+		result.Results = append(result.Results, params.APITokenInfo{
+			Id:          token.Id(),
+			Environment: envName,
+			EnvironUUID: uuid,
+			DateCreated: token.DateCreated(),
+			Expires:     token.Expires(),
+			LastUsed:    token.LastUsed(),
+			Expired:     token.IsExpired(),
+		})
+	}
+	return result, nil
+}
+
+// RevokeAPITokens removes API tokens so that they can no longer be used
+// to log in. Users may revoke their own tokens; the administrator may
+// revoke any token.
+func (api *UserManagerAPI) RevokeAPITokens(args params.APITokenIds) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Ids)),
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+	if len(args.Ids) == 0 {
+		return result, nil
+	}
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	adminUser := api.permissionCheck(loggedInUser) == nil
+	for i, id := range args.Ids {
+		token, err := api.state.APIToken(id)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		if !adminUser && token.Owner().Username() != strings.ToLower(loggedInUser.Username()) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		if err := api.state.RemoveAPIToken(id); err != nil {
+			err = errors.Annotate(err, "failed to revoke API token")
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
 func (api *UserManagerAPI) getUser(tag string) (*state.User, error) {
 	userTag, err := names.ParseUserTag(tag)
 	if err != nil {
@@ -132,6 +343,16 @@ func (api *UserManagerAPI) DisableUser(users params.Entities) (params.ErrorResul
 	return api.enableUserImpl(users, "disable", (*state.User).Disable)
 }
 
+// UnlockUser ends the lockout of one or more users after too many
+// failed logins. Unlocking a user that is not locked out is considered
+// a success.
+func (api *UserManagerAPI) UnlockUser(users params.Entities) (params.ErrorResults, error) {
+	if err := api.check.ChangeAllowed(); err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+	return api.enableUserImpl(users, "unlock", (*state.User).Unlock)
+}
+
 func (api *UserManagerAPI) enableUserImpl(args params.Entities, action string, method func(*state.User) error) (params.ErrorResults, error) {
 	result := params.ErrorResults{
 		Results: make([]params.ErrorResult, len(args.Entities)),
@@ -234,6 +455,10 @@ func (api *UserManagerAPI) SetPassword(args params.EntityPasswords) (params.Erro
 	if len(args.Changes) == 0 {
 		return result, nil
 	}
+	// A token must not be able to take over the user's account.
+	if api.authorizer.AuthAPIToken() {
+		return result, common.ErrPerm
+	}
 	loggedInUser, err := api.getLoggedInUser()
 	if err != nil {
 		return result, common.ErrPerm
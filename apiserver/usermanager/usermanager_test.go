@@ -4,6 +4,8 @@
 package usermanager_test
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -14,6 +16,7 @@ import (
 	apiservertesting "github.com/juju/juju/apiserver/testing"
 	"github.com/juju/juju/apiserver/usermanager"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing/factory"
 )
 
@@ -481,3 +484,266 @@ func (s *userManagerSuite) TestSetPasswordForOther(c *gc.C) {
 
 	c.Assert(barb.PasswordValid("new-password"), jc.IsFalse)
 }
+
+func (s *userManagerSuite) TestAddGroup(c *gc.C) {
+	_, err := s.State.AddUserGroup("dev", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.usermanager.AddGroup(params.AddGroups{
+		Groups: []params.AddGroup{{Name: "ops"}, {Name: "dev"}, {Name: "not valid"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, `failed to create group: group "dev" already exists`)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, `failed to create group: invalid group name "not valid"`)
+
+	group, err := s.State.UserGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.CreatedBy(), gc.Equals, s.adminName)
+}
+
+func (s *userManagerSuite) TestBlockAddGroup(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockAddGroup")
+	_, err := s.usermanager.AddGroup(params.AddGroups{
+		Groups: []params.AddGroup{{Name: "ops"}},
+	})
+	s.AssertBlocked(c, err, "TestBlockAddGroup")
+
+	_, err = s.State.UserGroup("ops")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *userManagerSuite) TestAddGroupAsNormalUser(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = usermanager.AddGroup(params.AddGroups{
+		Groups: []params.AddGroup{{Name: "ops"}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *userManagerSuite) TestAddAndRemoveGroupMembers(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	_, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	remote := names.NewUserTag("fred@remote")
+
+	result, err := s.usermanager.AddGroupMembers(params.GroupMembers{
+		Members: []params.GroupMember{
+			{Group: "ops", UserTag: alex.Tag().String()},
+			{Group: "ops", UserTag: remote.String()},
+			{Group: "ops", UserTag: names.NewLocalUserTag("ellie").String()},
+			{Group: "dev", UserTag: alex.Tag().String()},
+			{Group: "ops", UserTag: "not-a-tag"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 5)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.IsNil)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, `failed to add group member: user "ellie@local" not found`)
+	c.Assert(result.Results[3].Error, gc.ErrorMatches, `group "dev" not found`)
+	c.Assert(result.Results[4].Error, gc.ErrorMatches, `"not-a-tag" is not a valid tag`)
+
+	group, err := s.State.UserGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Members(), jc.DeepEquals, []names.UserTag{alex.UserTag(), remote})
+
+	result, err = s.usermanager.RemoveGroupMembers(params.GroupMembers{
+		Members: []params.GroupMember{
+			{Group: "ops", UserTag: remote.String()},
+			{Group: "ops", UserTag: remote.String()},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, `failed to remove group member: user "fred@remote" in group "ops" not found`)
+
+	err = group.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Members(), jc.DeepEquals, []names.UserTag{alex.UserTag()})
+}
+
+func (s *userManagerSuite) TestAddAPIToken(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := usermanager.AddAPIToken(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{ExpiresIn: time.Hour}, {ExpiresIn: 0}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, "token expiry must be positive")
+
+	token, err := s.State.APIToken(result.Results[0].Id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Owner(), gc.Equals, alex.UserTag())
+	c.Assert(token.EnvironUUID(), gc.Equals, s.State.EnvironUUID())
+	c.Assert(token.Expires(), gc.Equals, result.Results[0].Expires)
+	id, secret, ok := state.ParseAPIToken(result.Results[0].Credential)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(id, gc.Equals, token.Id())
+	c.Assert(token.SecretValid(secret), jc.IsTrue)
+}
+
+func (s *userManagerSuite) TestAddAPITokenWithToken(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag(), APIToken: true})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = usermanager.AddAPIToken(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{ExpiresIn: time.Hour}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *userManagerSuite) TestSetPasswordWithToken(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex", Password: "password"})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag(), APIToken: true})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = usermanager.SetPassword(params.EntityPasswords{
+		Changes: []params.EntityPassword{{
+			Tag:      alex.Tag().String(),
+			Password: "new-password",
+		}}})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	err = alex.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alex.PasswordValid("password"), jc.IsTrue)
+}
+
+func (s *userManagerSuite) TestAddAPITokenWithoutEnvironmentAccess(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex", NoEnvUser: true})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = usermanager.AddAPIToken(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{ExpiresIn: time.Hour}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *userManagerSuite) TestBlockAddAPIToken(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockAddAPIToken")
+	_, err := s.usermanager.AddAPIToken(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{ExpiresIn: time.Hour}},
+	})
+	s.AssertBlocked(c, err, "TestBlockAddAPIToken")
+}
+
+func (s *userManagerSuite) TestAPITokens(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	token, _, err := s.State.AddAPIToken(alex.UserTag(), time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.State.AddAPIToken(s.AdminUserTag(c), time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := usermanager.APITokens()
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.APITokenInfoResults{
+		Results: []params.APITokenInfo{{
+			Id:          token.Id(),
+			Environment: env.Name(),
+			EnvironUUID: env.UUID(),
+			DateCreated: token.DateCreated(),
+			Expires:     token.Expires(),
+		}},
+	})
+}
+
+func (s *userManagerSuite) TestRevokeAPITokens(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	own, _, err := s.State.AddAPIToken(alex.UserTag(), time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	admins, _, err := s.State.AddAPIToken(s.AdminUserTag(c), time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := usermanager.RevokeAPITokens(params.APITokenIds{
+		Ids: []string{own.Id(), admins.Id(), "missing"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, "permission denied")
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, `API token "missing" not found`)
+
+	_, err = s.State.APIToken(own.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.APIToken(admins.Id())
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The administrator may revoke any token.
+	result, err = s.usermanager.RevokeAPITokens(params.APITokenIds{
+		Ids: []string{admins.Id()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+}
+
+func (s *userManagerSuite) TestUnlockUser(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	err := alex.RecordFailedLogin(state.LoginPolicy{FailureLimit: 1, Lockout: time.Hour})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alex.IsLockedOut(), jc.IsTrue)
+
+	result, err := s.usermanager.UnlockUser(params.Entities{
+		Entities: []params.Entity{
+			{alex.Tag().String()},
+			{names.NewLocalUserTag("ellie").String()},
+		}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, "permission denied")
+
+	err = alex.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alex.IsLockedOut(), jc.IsFalse)
+}
+
+func (s *userManagerSuite) TestUnlockUserAsNormalUser(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = usermanager.UnlockUser(params.Entities{
+		Entities: []params.Entity{{alex.Tag().String()}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *userManagerSuite) TestSetPasswordFollowsPolicy(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-min-length": 10,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.usermanager.SetPassword(params.EntityPasswords{
+		Changes: []params.EntityPassword{{
+			Tag:      alex.Tag().String(),
+			Password: "short",
+		}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results[0].Error, gc.ErrorMatches, "failed to set password: password must be at least 10 characters long")
+}
@@ -0,0 +1,95 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The charmsig package signs charm archives, and verifies their
+// signatures, independently of any charm store. A signature is an
+// armored detached OpenPGP signature of the archive's bytes, made with
+// an offline key; it may equally be made with "gpg --armor
+// --detach-sign".
+package charmsig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/juju/errors"
+	"golang.org/x/crypto/openpgp"
+	pgperrors "golang.org/x/crypto/openpgp/errors"
+)
+
+// ErrUntrusted is returned by Verify when a signature was not made
+// by any of the trusted keys.
+var ErrUntrusted = errors.New("charm signature was not made by a trusted key")
+
+// Sign returns an armored detached signature of the charm archive read
+// from r, made with the first key of the armored private key ring.
+func Sign(r io.Reader, armoredPrivateKey, passphrase string) (string, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredPrivateKey))
+	if err != nil {
+		return "", errors.Annotate(err, "cannot parse private key")
+	}
+	signer := keyring[0]
+	if signer.PrivateKey == nil {
+		return "", errors.New("no private key found")
+	}
+	if signer.PrivateKey.Encrypted {
+		if err := signer.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return "", errors.Annotate(err, "cannot decrypt private key")
+		}
+	}
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, signer, r, nil); err != nil {
+		return "", errors.Annotate(err, "cannot sign charm archive")
+	}
+	return buf.String(), nil
+}
+
+// ParseKeyRing parses an armored key ring holding the public keys
+// that are trusted to sign charms.
+func ParseKeyRing(armoredPublicKeys string) (openpgp.EntityList, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredPublicKeys))
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot parse trusted charm keys")
+	}
+	return keyring, nil
+}
+
+// Verify checks the armored detached signature of the charm archive
+// read from r against the armored key ring of trusted public keys, and
+// returns the fingerprint of the key that made it.
+func Verify(r io.Reader, signature, armoredPublicKeys string) (string, error) {
+	keyring, err := ParseKeyRing(armoredPublicKeys)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, r, strings.NewReader(signature))
+	if err == pgperrors.ErrUnknownIssuer {
+		return "", ErrUntrusted
+	} else if err != nil {
+		return "", errors.Annotate(err, "invalid charm signature")
+	}
+	return Fingerprint(signer), nil
+}
+
+// IsTrusted reports whether the key with the given fingerprint is one
+// of the keys in the armored key ring of trusted public keys.
+func IsTrusted(fingerprint, armoredPublicKeys string) (bool, error) {
+	keyring, err := ParseKeyRing(armoredPublicKeys)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, entity := range keyring {
+		if Fingerprint(entity) == fingerprint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Fingerprint returns the fingerprint of the primary key of the given
+// entity, as upper case hex.
+func Fingerprint(entity *openpgp.Entity) string {
+	return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+}
@@ -0,0 +1,86 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmsig_test
+
+import (
+	"strings"
+	"testing"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/charmsig"
+	charmsigtesting "github.com/juju/juju/charmsig/testing"
+)
+
+func TestAll(t *testing.T) {
+	gc.TestingT(t)
+}
+
+type charmsigSuite struct {
+	signer, other charmsigtesting.Key
+}
+
+var _ = gc.Suite(&charmsigSuite{})
+
+func (s *charmsigSuite) SetUpSuite(c *gc.C) {
+	s.signer = charmsigtesting.NewKey(c, "signer")
+	s.other = charmsigtesting.NewKey(c, "other")
+}
+
+const archive = "not really a zip file"
+
+func sign(c *gc.C, key charmsigtesting.Key) string {
+	signature, err := charmsig.Sign(strings.NewReader(archive), key.Private, "")
+	c.Assert(err, jc.ErrorIsNil)
+	return signature
+}
+
+func (s *charmsigSuite) TestSignAndVerify(c *gc.C) {
+	signature := sign(c, s.signer)
+	c.Assert(signature, jc.HasPrefix, "-----BEGIN PGP SIGNATURE-----")
+
+	trusted := s.other.Public + s.signer.Public
+	fingerprint, err := charmsig.Verify(strings.NewReader(archive), signature, trusted)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fingerprint, gc.Equals, s.signer.Fingerprint)
+	c.Assert(fingerprint, gc.Matches, "[0-9A-F]{40}")
+}
+
+func (s *charmsigSuite) TestVerifyModifiedArchive(c *gc.C) {
+	signature := sign(c, s.signer)
+	_, err := charmsig.Verify(strings.NewReader(archive+"!"), signature, s.signer.Public)
+	c.Assert(err, gc.ErrorMatches, "invalid charm signature: .*")
+}
+
+func (s *charmsigSuite) TestVerifyUntrustedKey(c *gc.C) {
+	signature := sign(c, s.other)
+	_, err := charmsig.Verify(strings.NewReader(archive), signature, s.signer.Public)
+	c.Assert(err, gc.Equals, charmsig.ErrUntrusted)
+}
+
+func (s *charmsigSuite) TestVerifyBadSignature(c *gc.C) {
+	_, err := charmsig.Verify(strings.NewReader(archive), "rubbish", s.signer.Public)
+	c.Assert(err, gc.ErrorMatches, "invalid charm signature: .*")
+}
+
+func (s *charmsigSuite) TestVerifyBadKeyRing(c *gc.C) {
+	signature := sign(c, s.signer)
+	_, err := charmsig.Verify(strings.NewReader(archive), signature, "rubbish")
+	c.Assert(err, gc.ErrorMatches, "cannot parse trusted charm keys: .*")
+}
+
+func (s *charmsigSuite) TestSignWithPublicKey(c *gc.C) {
+	_, err := charmsig.Sign(strings.NewReader(archive), s.signer.Public, "")
+	c.Assert(err, gc.ErrorMatches, "no private key found")
+}
+
+func (s *charmsigSuite) TestIsTrusted(c *gc.C) {
+	ok, err := charmsig.IsTrusted(s.signer.Fingerprint, s.signer.Public)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+	ok, err = charmsig.IsTrusted(s.other.Fingerprint, s.signer.Public)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsFalse)
+}
@@ -0,0 +1,50 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package testing
+
+import (
+	"bytes"
+	"io"
+
+	jc "github.com/juju/testing/checkers"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/charmsig"
+)
+
+// Key holds a charm signing key generated for testing.
+type Key struct {
+	// Private holds the armored private key, which is not encrypted.
+	Private string
+
+	// Public holds the armored public key.
+	Public string
+
+	// Fingerprint holds the fingerprint of the key.
+	Fingerprint string
+}
+
+// NewKey generates a new charm signing key with the given name.
+func NewKey(c *gc.C, name string) Key {
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	return Key{
+		Private: armorKey(c, openpgp.PrivateKeyType, func(w io.Writer) error {
+			return entity.SerializePrivate(w, nil)
+		}),
+		Public:      armorKey(c, openpgp.PublicKeyType, entity.Serialize),
+		Fingerprint: charmsig.Fingerprint(entity),
+	}
+}
+
+func armorKey(c *gc.C, blockType string, serialize func(io.Writer) error) string {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, blockType, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(serialize(w), jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	return buf.String()
+}
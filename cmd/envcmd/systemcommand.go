@@ -9,7 +9,9 @@ import (
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/cleanupmanager"
 	"github.com/juju/juju/api/environmentmanager"
+	"github.com/juju/juju/api/garbagecollector"
 	"github.com/juju/juju/api/systemmanager"
 	"github.com/juju/juju/api/usermanager"
 	"github.com/juju/juju/environs/configstore"
@@ -64,6 +66,26 @@ func (c *SysCommandBase) NewEnvironmentManagerAPIClient() (*environmentmanager.C
 	return environmentmanager.NewClient(root), nil
 }
 
+// NewCleanupManagerAPIClient returns an API client for the CleanupManager
+// on the current system using the current credentials.
+func (c *SysCommandBase) NewCleanupManagerAPIClient() (*cleanupmanager.Client, error) {
+	root, err := c.newAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return cleanupmanager.NewClient(root), nil
+}
+
+// NewGarbageCollectorAPIClient returns an API client for the
+// GarbageCollector on the current system using the current credentials.
+func (c *SysCommandBase) NewGarbageCollectorAPIClient() (*garbagecollector.Client, error) {
+	root, err := c.newAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return garbagecollector.NewClient(root), nil
+}
+
 // NewSystemManagerAPIClient returns an API client for the SystemManager on
 // the current system using the current credentials.
 func (c *SysCommandBase) NewSystemManagerAPIClient() (*systemmanager.Client, error) {
@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"os"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/resource"
+)
+
+// AttachCommand uploads content for a charm resource of a service.
+type AttachCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName  string
+	ResourceName string
+	Path         string
+}
+
+var jujuAttachHelp = `
+Uploads a file as the new content of a resource declared by the charm
+of a service. The service's units run their upgrade-charm hook once the
+new content is available, and may fetch it with the resource-get hook
+tool.
+
+Example:
+
+    juju attach wordpress site=./site.tgz
+
+`
+
+func (c *AttachCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "attach",
+		Args:    "<service> <resource>=<path>",
+		Purpose: "upload content for a charm resource",
+		Doc:     jujuAttachHelp,
+	}
+}
+
+func (c *AttachCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no service name specified")
+	case 1:
+		return errors.New("no resource specified")
+	}
+	c.ServiceName = args[0]
+	if !names.IsValidService(c.ServiceName) {
+		return errors.Errorf("invalid service name %q", c.ServiceName)
+	}
+	parts := strings.SplitN(args[1], "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return errors.Errorf("expected <resource>=<path>, got %q", args[1])
+	}
+	c.ResourceName, c.Path = parts[0], parts[1]
+	if !resource.IsValidName(c.ResourceName) {
+		return errors.Errorf("invalid resource name %q", c.ResourceName)
+	}
+	return cmd.CheckEmpty(args[2:])
+}
+
+// Run uploads the file to the API server.
+func (c *AttachCommand) Run(ctx *cmd.Context) error {
+	f, err := os.Open(ctx.AbsPath(c.Path))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	revision, err := client.AttachResource(c.ServiceName, c.ResourceName, f)
+	if err != nil {
+		return err
+	}
+	ctx.Infof("uploaded resource %q of service %q as revision %d", c.ResourceName, c.ServiceName, revision)
+	return nil
+}
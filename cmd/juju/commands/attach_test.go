@@ -0,0 +1,78 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/envcmd"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/testing"
+)
+
+type AttachSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = gc.Suite(&AttachSuite{})
+
+func runAttach(c *gc.C, args ...string) error {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&AttachCommand{}), args...)
+	return err
+}
+
+func (s *AttachSuite) TestInitErrors(c *gc.C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no service name specified",
+	}, {
+		args: []string{"site"},
+		err:  "no resource specified",
+	}, {
+		args: []string{"Site", "site=foo"},
+		err:  `invalid service name "Site"`,
+	}, {
+		args: []string{"site", "site"},
+		err:  `expected <resource>=<path>, got "site"`,
+	}, {
+		args: []string{"site", "site="},
+		err:  `expected <resource>=<path>, got "site="`,
+	}, {
+		args: []string{"site", "Site=foo"},
+		err:  `invalid resource name "Site"`,
+	}, {
+		args: []string{"site", "site=foo", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		err := testing.InitCommand(envcmd.Wrap(&AttachCommand{}), t.args)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *AttachSuite) TestAttach(c *gc.C) {
+	s.AddTestingService(c, "site", s.AddTestingCharm(c, "resources"))
+	path := filepath.Join(c.MkDir(), "site.tgz")
+	err := ioutil.WriteFile(path, []byte("hello"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = runAttach(c, "site", "site="+path)
+	c.Assert(err, jc.ErrorIsNil)
+	resource, err := s.State.Resource("site", "site")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resource.Revision(), gc.Equals, 1)
+	c.Assert(resource.Size(), gc.Equals, int64(5))
+}
+
+func (s *AttachSuite) TestAttachMissingFile(c *gc.C) {
+	s.AddTestingService(c, "site", s.AddTestingCharm(c, "resources"))
+	err := runAttach(c, "site", "site="+filepath.Join(c.MkDir(), "missing"))
+	c.Assert(err, gc.ErrorMatches, "open .*missing: no such file or directory")
+}
@@ -165,21 +165,23 @@ func resolveCharmURL(curlStr string, csParams charmrepo.NewCharmStoreParams, rep
 // addCharmViaAPI calls the appropriate client API calls to add the
 // given charm URL to state. For non-public charm URLs, this function also
 // handles the macaroon authorization process using the given csClient.
-// The resulting charm URL of the added charm is displayed on stdout.
-func addCharmViaAPI(client *api.Client, ctx *cmd.Context, curl *charm.URL, repo charmrepo.Interface, csclient *csClient) (*charm.URL, error) {
+// The given armored detached signature of the charm archive, if any, is
+// provided for the server to verify. The resulting charm URL of the
+// added charm is displayed on stdout.
+func addCharmViaAPI(client *api.Client, ctx *cmd.Context, curl *charm.URL, repo charmrepo.Interface, csclient *csClient, signature string) (*charm.URL, error) {
 	switch curl.Schema {
 	case "local":
 		ch, err := repo.Get(curl)
 		if err != nil {
 			return nil, err
 		}
-		stateCurl, err := client.AddLocalCharm(curl, ch)
+		stateCurl, err := client.AddLocalCharmWithSignature(curl, ch, signature)
 		if err != nil {
 			return nil, err
 		}
 		curl = stateCurl
 	case "cs":
-		if err := client.AddCharm(curl); err != nil {
+		if err := addStoreCharm(client, curl, nil, signature); err != nil {
 			if !params.IsCodeUnauthorized(err) {
 				return nil, errors.Mask(err)
 			}
@@ -187,7 +189,7 @@ func addCharmViaAPI(client *api.Client, ctx *cmd.Context, curl *charm.URL, repo
 			if err != nil {
 				return nil, errors.Mask(err)
 			}
-			if err := client.AddCharmWithAuthorization(curl, m); err != nil {
+			if err := addStoreCharm(client, curl, m, signature); err != nil {
 				return nil, errors.Mask(err)
 			}
 		}
@@ -198,6 +200,31 @@ func addCharmViaAPI(client *api.Client, ctx *cmd.Context, curl *charm.URL, repo
 	return curl, nil
 }
 
+// addStoreCharm adds the given charm store charm to state, providing
+// the authorization macaroon and charm signature if they are given.
+func addStoreCharm(client *api.Client, curl *charm.URL, m *macaroon.Macaroon, signature string) error {
+	switch {
+	case signature != "":
+		return client.AddCharmWithSignature(curl, m, signature)
+	case m != nil:
+		return client.AddCharmWithAuthorization(curl, m)
+	}
+	return client.AddCharm(curl)
+}
+
+// readCharmSignature returns the contents of the given charm signature
+// file, or "" if no file was given.
+func readCharmSignature(ctx *cmd.Context, file cmd.FileVar) (string, error) {
+	if file.Path == "" {
+		return "", nil
+	}
+	data, err := file.Read(ctx)
+	if err != nil {
+		return "", errors.Annotate(err, "cannot read charm signature")
+	}
+	return string(data), nil
+}
+
 // csClient gives access to the charm store server and provides parameters
 // for connecting to the charm store.
 type csClient struct {
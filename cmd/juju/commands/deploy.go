@@ -19,6 +19,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
+	"github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/cmd/juju/service"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/juju/osenv"
@@ -36,6 +37,7 @@ type DeployCommand struct {
 	BumpRevision bool   // Remove this once the 1.16 support is dropped.
 	RepoPath     string // defaults to JUJU_REPOSITORY
 	RegisterURL  string
+	Signature    cmd.FileVar
 
 	// TODO(axw) move this to UnitCommandBase once we support --storage
 	// on add-unit too.
@@ -113,6 +115,14 @@ to configure on it. The --networks argument instructs juju to add all the
 networks specified with it to all new machines deployed to host units of
 the service. Not supported on all providers.
 
+If the environment's trusted-charm-keys setting is set, only charms signed by
+one of the trusted keys can be deployed. The --signature argument takes the
+path to an armored detached OpenPGP signature of the charm archive, made for
+instance with
+  gpg --armor --detach-sign mysql.charm
+Only charm archives, not charm directories, can be signed; to deploy a signed
+charm store charm, sign the archive downloaded from the charm store.
+
 See Also:
    juju help constraints
    juju help set-constraints
@@ -138,6 +148,7 @@ func (c *DeployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.Networks, "networks", "", "bind the service to specific networks")
 	f.StringVar(&c.RepoPath, "repository", os.Getenv(osenv.JujuRepositoryEnvKey), "local charm repository")
 	f.Var(storageFlag{&c.Storage}, "storage", "charm storage constraints")
+	f.Var(&c.Signature, "signature", "path to an armored detached signature of the charm archive")
 }
 
 func (c *DeployCommand) Init(args []string) error {
@@ -195,7 +206,11 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 		return errors.Trace(err)
 	}
 
-	curl, err = addCharmViaAPI(client, ctx, curl, repo, csClient)
+	signature, err := readCharmSignature(ctx, c.Signature)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	curl, err = addCharmViaAPI(client, ctx, curl, repo, csClient, signature)
 	if err != nil {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
@@ -240,6 +255,21 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 		serviceName = charmInfo.Meta.Name
 	}
 
+	storageCons := make([]storage.Constraints, 0, len(c.Storage))
+	for _, cons := range c.Storage {
+		storageCons = append(storageCons, cons)
+	}
+	// The service does not exist yet, so only the constraints given
+	// on the command line are combined with the environment's.
+	err = common.CheckQuotas(ctx, client, params.QuotaPreflight{
+		Machines:    common.NewMachines(numUnits, c.PlacementSpec, c.Placement),
+		Constraints: c.Constraints,
+		Volumes:     common.NewVolumes(numUnits, storageCons),
+	})
+	if err != nil {
+		return err
+	}
+
 	var configYAML []byte
 	if c.Config.Path != "" {
 		configYAML, err = c.Config.Read(ctx)
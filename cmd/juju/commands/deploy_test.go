@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/juju/errors"
@@ -26,6 +28,8 @@ import (
 	"gopkg.in/macaroon-bakery.v0/bakerytest"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/charmsig"
+	charmsigtesting "github.com/juju/juju/charmsig/testing"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/service"
 	"github.com/juju/juju/constraints"
@@ -151,6 +155,33 @@ func (s *DeploySuite) TestCharmBundle(c *gc.C) {
 	s.AssertService(c, "some-service-name", curl, 1, 0)
 }
 
+func (s *DeploySuite) TestSignedCharmBundle(c *gc.C) {
+	key := charmsigtesting.NewKey(c, "signer")
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"trusted-charm-keys": key.Public}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	archivePath := testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+
+	err = runDeploy(c, "local:dummy", "some-service-name")
+	c.Assert(err, gc.ErrorMatches, ".*charm is not signed.*")
+
+	archive, err := os.Open(archivePath)
+	c.Assert(err, jc.ErrorIsNil)
+	defer archive.Close()
+	signature, err := charmsig.Sign(archive, key.Private, "")
+	c.Assert(err, jc.ErrorIsNil)
+	signaturePath := filepath.Join(c.MkDir(), "dummy.charm.asc")
+	err = ioutil.WriteFile(signaturePath, []byte(signature), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = runDeploy(c, "local:dummy", "some-service-name", "--signature", signaturePath)
+	c.Assert(err, jc.ErrorIsNil)
+	curl := charm.MustParseURL("local:trusty/dummy-1")
+	s.AssertService(c, "some-service-name", curl, 1, 0)
+	sch, err := s.State.Charm(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sch.SignedBy(), gc.Equals, key.Fingerprint)
+}
+
 func (s *DeploySuite) TestSubordinateCharm(c *gc.C) {
 	testcharms.Repo.CharmArchivePath(s.SeriesPath, "logging")
 	err := runDeploy(c, "local:logging")
@@ -7,12 +7,15 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	"github.com/juju/utils/set"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/highavailability"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
@@ -24,8 +27,9 @@ import (
 // EnsureAvailabilityCommand makes the system highly available.
 type EnsureAvailabilityCommand struct {
 	envcmd.EnvCommandBase
-	out      cmd.Output
-	haClient EnsureAvailabilityClient
+	out          cmd.Output
+	haClient     EnsureAvailabilityClient
+	statusClient StateServerStatusClient
 
 	// NumStateServers specifies the number of state servers to make available.
 	NumStateServers int
@@ -42,6 +46,11 @@ type EnsureAvailabilityCommand struct {
 	Placement []string
 	// PlacementSpec holds the unparsed placement directives argument (--to).
 	PlacementSpec string
+	// Remove holds the id of a state server machine to remove.
+	Remove string
+	// Replace holds the id of a state server machine to replace
+	// with a new one.
+	Replace string
 }
 
 const ensureAvailabilityDoc = `
@@ -68,6 +77,15 @@ Examples:
      Ensure that 7 state servers are available, with machines server1 and
      server2 used first, and if necessary, newly created state server
      machines having the default series, and at least 8GB RAM.
+ juju ensure-availability --replace 2 --constraints mem=8G
+     Replace state server machine 2 with a newly created state server
+     machine having at least 8GB RAM. Machine 2's vote is removed and,
+     once the new state server has started and is voting, and machine
+     2 has left the replica set, it stops being a state server.
+ juju ensure-availability --remove 4
+     Stop state server machine 4 from being a state server, without
+     replacing it. This is only possible when an odd number of voting
+     state servers remains.
 `
 
 // formatSimple marshals value to a yaml-formatted []byte, unless value is nil.
@@ -133,6 +151,8 @@ func (c *EnsureAvailabilityCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.Series, "series", "", "the charm series")
 	f.StringVar(&c.PlacementSpec, "to", "", "the machine(s) to become state servers, bypasses constraints")
 	f.Var(constraints.ConstraintsValue{&c.Constraints}, "constraints", "additional machine constraints")
+	f.StringVar(&c.Remove, "remove", "", "the state server machine to remove")
+	f.StringVar(&c.Replace, "replace", "", "the state server machine to replace with a new one")
 	c.out.AddFlags(f, "simple", map[string]cmd.Formatter{
 		"yaml":   cmd.FormatYaml,
 		"json":   cmd.FormatJson,
@@ -145,6 +165,20 @@ func (c *EnsureAvailabilityCommand) Init(args []string) error {
 	if c.NumStateServers < 0 || (c.NumStateServers%2 != 1 && c.NumStateServers != 0) {
 		return fmt.Errorf("must specify a number of state servers odd and non-negative")
 	}
+	if c.Remove != "" && c.Replace != "" {
+		return errors.New("--remove and --replace cannot be used together")
+	}
+	if machineId := c.Remove + c.Replace; machineId != "" {
+		if c.NumStateServers != 0 {
+			return errors.New("-n cannot be used with --remove or --replace")
+		}
+		if !names.IsValidMachine(machineId) {
+			return errors.Errorf("invalid machine id %q", machineId)
+		}
+		if c.Remove != "" && c.PlacementSpec != "" {
+			return errors.New("--to cannot be used with --remove")
+		}
+	}
 	if c.PlacementSpec != "" {
 		placementSpecs := strings.Split(c.PlacementSpec, ",")
 		c.Placement = make([]string, len(placementSpecs))
@@ -184,6 +218,28 @@ type EnsureAvailabilityClient interface {
 	EnsureAvailability(
 		numStateServers int, cons constraints.Value, series string,
 		placement []string) (params.StateServersChanges, error)
+	RemoveStateServer(
+		machineId string, replace bool, cons constraints.Value, series string,
+		placement []string) (params.StateServersChanges, error)
+}
+
+// StateServerStatusClient defines the methods on the client api that
+// the ensure availability command calls to follow the removal of a
+// state server.
+type StateServerStatusClient interface {
+	Close() error
+	Status(patterns []string) (*api.Status, error)
+}
+
+func (c *EnsureAvailabilityCommand) getStatusClient() (StateServerStatusClient, error) {
+	if c.statusClient != nil {
+		return c.statusClient, nil
+	}
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API connection")
+	}
+	return client, nil
 }
 
 func (c *EnsureAvailabilityCommand) getHAClient() (EnsureAvailabilityClient, error) {
@@ -209,6 +265,9 @@ func (c *EnsureAvailabilityCommand) Run(ctx *cmd.Context) error {
 	}
 
 	defer haClient.Close()
+	if c.Remove != "" || c.Replace != "" {
+		return c.removeStateServer(ctx, haClient)
+	}
 	ensureAvailabilityResult, err := haClient.EnsureAvailability(
 		c.NumStateServers,
 		c.Constraints,
@@ -230,6 +289,123 @@ func (c *EnsureAvailabilityCommand) Run(ctx *cmd.Context) error {
 	return c.out.Write(ctx, result)
 }
 
+var (
+	// removeStateServerPollInterval holds the interval between calls
+	// made to follow the removal of a state server.
+	removeStateServerPollInterval = 5 * time.Second
+
+	// removeStateServerTimeout holds the time to wait for a state
+	// server to be removed.
+	removeStateServerTimeout = 10 * time.Minute
+)
+
+// removeStateServer removes or replaces a single state server,
+// reporting progress until the machine is no longer a state server.
+//
+// The removal is requested once. The command then waits for any new
+// state servers to start and join the replica set, and for the removed
+// one to leave it, before asking for the removal to be finished.
+func (c *EnsureAvailabilityCommand) removeStateServer(ctx *cmd.Context, haClient EnsureAvailabilityClient) error {
+	machineId, replace := c.Remove, false
+	if c.Replace != "" {
+		machineId, replace = c.Replace, true
+	}
+	changes, err := haClient.RemoveStateServer(machineId, replace, c.Constraints, c.Series, c.Placement)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	result := availabilityInfo{
+		Maintained: machineTagsToIds(changes.Maintained...),
+		Added:      machineTagsToIds(changes.Added...),
+		Promoted:   machineTagsToIds(changes.Promoted...),
+		Demoted:    machineTagsToIds(changes.Demoted...),
+		Removed:    machineTagsToIds(changes.Removed...),
+	}
+	for _, id := range result.Added {
+		ctx.Infof("adding machine %s", id)
+	}
+	for _, id := range result.Promoted {
+		ctx.Infof("promoting machine %s", id)
+	}
+	for _, id := range result.Demoted {
+		ctx.Infof("demoted machine %s, waiting for it to leave the replica set", id)
+	}
+	if !set.NewStrings(result.Removed...).Contains(machineId) {
+		newIds := append(append([]string(nil), result.Added...), result.Promoted...)
+		if err := c.waitStateServerRemoval(ctx, haClient, machineId, newIds); err != nil {
+			return errors.Trace(err)
+		}
+		result.Removed = appendNew(result.Removed, machineId)
+	}
+	for _, id := range result.Removed {
+		ctx.Infof("removed state server machine %s", id)
+	}
+	return c.out.Write(ctx, result)
+}
+
+// waitStateServerRemoval waits until the state server machines with
+// the given new ids have started and are voting, and the state server
+// machine being removed has left the replica set, and then finishes
+// the removal.
+func (c *EnsureAvailabilityCommand) waitStateServerRemoval(
+	ctx *cmd.Context, haClient EnsureAvailabilityClient, machineId string, newIds []string,
+) error {
+	statusClient, err := c.getStatusClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer statusClient.Close()
+
+	voting := set.NewStrings()
+	timeout := time.After(removeStateServerTimeout)
+	for {
+		status, err := statusClient.Status(nil)
+		if err != nil {
+			return errors.Annotate(err, "cannot get status")
+		}
+		ready := true
+		for _, id := range newIds {
+			m, ok := status.Machines[id]
+			if !ok || !m.HasVote || m.AgentState != params.StatusStarted {
+				ready = false
+			} else if !voting.Contains(id) {
+				voting.Add(id)
+				ctx.Infof("machine %s is voting", id)
+			}
+		}
+		if m, ok := status.Machines[machineId]; ok && m.HasVote {
+			ready = false
+		}
+		if ready {
+			changes, err := haClient.RemoveStateServer(machineId, false, constraints.Value{}, "", nil)
+			if params.IsCodeNotFound(err) {
+				// Someone else finished the removal.
+				return nil
+			} else if err != nil {
+				return block.ProcessBlockedError(err, block.BlockChange)
+			}
+			if set.NewStrings(machineTagsToIds(changes.Removed...)...).Contains(machineId) {
+				return nil
+			}
+		}
+		select {
+		case <-time.After(removeStateServerPollInterval):
+		case <-timeout:
+			return errors.Errorf("timed out waiting for state server machine %s to be removed", machineId)
+		}
+	}
+}
+
+// appendNew appends to list those ids not already in it.
+func appendNew(list []string, ids ...string) []string {
+	for _, id := range ids {
+		if !set.NewStrings(list...).Contains(id) {
+			list = append(list, id)
+		}
+	}
+	return list
+}
+
 // Convert machine tags to ids, skipping any non-machine tags.
 func machineTagsToIds(tags ...string) []string {
 	var result []string
@@ -8,12 +8,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/set"
 	gc "gopkg.in/check.v1"
 	goyaml "gopkg.in/yaml.v1"
 
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
@@ -30,7 +34,8 @@ type EnsureAvailabilitySuite struct {
 	// remove the mongo dependency once ensure-availability is
 	// moved under a supercommand again.
 	testing.JujuConnSuite
-	fake *fakeHAClient
+	fake       *fakeHAClient
+	fakeStatus *fakeStatusClient
 }
 
 // invalidNumServers is a number of state servers that would
@@ -44,6 +49,7 @@ func (s *EnsureAvailabilitySuite) SetUpTest(c *gc.C) {
 	// that ensure-availability doesn't call into the API when its
 	// pre-checks fail
 	s.fake = &fakeHAClient{numStateServers: invalidNumServers}
+	s.fakeStatus = &fakeStatusClient{}
 }
 
 type fakeHAClient struct {
@@ -53,6 +59,44 @@ type fakeHAClient struct {
 	series          string
 	placement       []string
 	result          params.StateServersChanges
+
+	removeMachineId string
+	replace         bool
+	removeResults   []params.StateServersChanges
+	removeCalls     int
+}
+
+type fakeStatusClient struct {
+	statuses []*api.Status
+	calls    int
+}
+
+func (f *fakeStatusClient) Close() error {
+	return nil
+}
+
+func (f *fakeStatusClient) Status(patterns []string) (*api.Status, error) {
+	f.calls++
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+	return status, nil
+}
+
+// stateServerStatus returns a status in which the machines with the
+// given ids are started state servers, and those in voting have a
+// vote.
+func stateServerStatus(ids []string, voting ...string) *api.Status {
+	status := &api.Status{Machines: make(map[string]api.MachineStatus)}
+	for _, id := range ids {
+		status.Machines[id] = api.MachineStatus{
+			Id:         id,
+			AgentState: params.StatusStarted,
+			HasVote:    set.NewStrings(voting...).Contains(id),
+		}
+	}
+	return status
 }
 
 func (f *fakeHAClient) Close() error {
@@ -98,10 +142,32 @@ func (f *fakeHAClient) EnsureAvailability(numStateServers int, cons constraints.
 	return f.result, nil
 }
 
+func (f *fakeHAClient) RemoveStateServer(machineId string, replace bool, cons constraints.Value,
+	series string, placement []string) (params.StateServersChanges, error) {
+
+	f.removeCalls++
+	if f.removeCalls == 1 {
+		f.removeMachineId = machineId
+		f.replace = replace
+		f.cons = cons
+		f.series = series
+		f.placement = placement
+	}
+
+	if f.err != nil {
+		return params.StateServersChanges{}, f.err
+	}
+	result := f.removeResults[0]
+	if len(f.removeResults) > 1 {
+		f.removeResults = f.removeResults[1:]
+	}
+	return result, nil
+}
+
 var _ = gc.Suite(&EnsureAvailabilitySuite{})
 
 func (s *EnsureAvailabilitySuite) runEnsureAvailability(c *gc.C, args ...string) (*cmd.Context, error) {
-	command := &EnsureAvailabilityCommand{haClient: s.fake}
+	command := &EnsureAvailabilityCommand{haClient: s.fake, statusClient: s.fakeStatus}
 	return coretesting.RunCommand(c, envcmd.Wrap(command), args...)
 }
 
@@ -275,3 +341,196 @@ converting machines: 1, 2
 	c.Check(s.fake.series, gc.Equals, "")
 	c.Check(len(s.fake.placement), gc.Equals, 2)
 }
+
+func (s *EnsureAvailabilitySuite) TestRemoveInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--remove", "1", "--replace", "2"},
+		err:  "--remove and --replace cannot be used together",
+	}, {
+		args: []string{"--replace", "1", "-n", "5"},
+		err:  "-n cannot be used with --remove or --replace",
+	}, {
+		args: []string{"--remove", "foo"},
+		err:  `invalid machine id "foo"`,
+	}, {
+		args: []string{"--remove", "1", "--to", "2"},
+		err:  "--to cannot be used with --remove",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := s.runEnsureAvailability(c, test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+	c.Assert(s.fake.removeMachineId, gc.Equals, "")
+}
+
+func (s *EnsureAvailabilitySuite) TestReplace(c *gc.C) {
+	s.PatchValue(&removeStateServerPollInterval, time.Millisecond)
+	s.fake.removeResults = []params.StateServersChanges{{
+		Maintained: []string{"machine-0", "machine-1"},
+		Demoted:    []string{"machine-2"},
+		Added:      []string{"machine-3"},
+	}, {
+		Removed: []string{"machine-2"},
+	}}
+	machines := []string{"0", "1", "2", "3"}
+	s.fakeStatus.statuses = []*api.Status{
+		// Machine 3 has not started yet.
+		stateServerStatus(machines[:3], "0", "1", "2"),
+		// Machine 3 is voting, but machine 2 is still in the
+		// replica set.
+		stateServerStatus(machines, "0", "1", "2", "3"),
+		stateServerStatus(machines, "0", "1", "3"),
+	}
+	ctx, err := s.runEnsureAvailability(c, "--replace", "2", "--series", "trusty", "--constraints", "mem=8G")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals,
+		"adding machine 3\n"+
+			"demoted machine 2, waiting for it to leave the replica set\n"+
+			"machine 3 is voting\n"+
+			"removed state server machine 2\n")
+	c.Assert(coretesting.Stdout(ctx), gc.Equals,
+		"maintaining machines: 0, 1\n"+
+			"adding machines: 3\n"+
+			"removing machines: 2\n"+
+			"demoting machines: 2\n\n")
+
+	// The removal is only requested again to finish it, once the
+	// replica set has changed.
+	c.Assert(s.fake.removeCalls, gc.Equals, 2)
+	c.Assert(s.fakeStatus.calls, gc.Equals, 3)
+	c.Assert(s.fake.removeMachineId, gc.Equals, "2")
+	c.Assert(s.fake.replace, jc.IsTrue)
+	c.Assert(s.fake.series, gc.Equals, "trusty")
+	c.Assert(s.fake.cons, gc.DeepEquals, constraints.MustParse("mem=8G"))
+}
+
+func (s *EnsureAvailabilitySuite) TestReplaceEndToEnd(c *gc.C) {
+	s.PatchValue(&removeStateServerPollInterval, 10*time.Millisecond)
+	m0 := s.Factory.MakeMachine(c, &factory.MachineParams{
+		Jobs: []state.MachineJob{state.JobManageEnviron},
+	})
+	err := m0.SetHasVote(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	done := make(chan struct{})
+	replaced := make(chan error, 1)
+	go func() {
+		replaced <- s.startReplacement(done)
+	}()
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&EnsureAvailabilityCommand{}), "--replace", "0")
+	close(done)
+	c.Assert(<-replaced, jc.ErrorIsNil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals,
+		"adding machine 1\n"+
+			"demoted machine 0, waiting for it to leave the replica set\n"+
+			"machine 1 is voting\n"+
+			"removed state server machine 0\n")
+	c.Assert(coretesting.Stdout(ctx), gc.Equals,
+		"adding machines: 1\n"+
+			"removing machines: 0\n"+
+			"demoting machines: 0\n\n")
+
+	err = m0.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m0.IsManager(), jc.IsFalse)
+	info, err := s.State.StateServerInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.MachineIds, gc.DeepEquals, []string{"1"})
+	c.Assert(info.VotingMachineIds, gc.DeepEquals, []string{"1"})
+}
+
+// startReplacement plays the parts of the agent of the replacement
+// state server, machine 1, and of the peergrouper: once machine 1 has
+// been added, its agent starts and it is given a vote, and then the
+// vote of machine 0 is taken away. The presence of machine 1's agent
+// is kept in sync in the API server's state until done is closed.
+func (s *EnsureAvailabilitySuite) startReplacement(done <-chan struct{}) error {
+	st := s.BackingState
+	var m1 *state.Machine
+	for m1 == nil {
+		select {
+		case <-done:
+			return errors.New("machine 1 was not added")
+		case <-time.After(coretesting.ShortWait):
+		}
+		m, err := st.Machine("1")
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		m1 = m
+	}
+	if err := m1.SetProvisioned("i-1", "fake_nonce", nil); err != nil {
+		return errors.Trace(err)
+	}
+	if err := m1.SetStatus(state.StatusStarted, "", nil); err != nil {
+		return errors.Trace(err)
+	}
+	pinger, err := m1.SetAgentPresence()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer pinger.Kill()
+	if err := m1.SetHasVote(true); err != nil {
+		return errors.Trace(err)
+	}
+	m0, err := st.Machine("0")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := m0.SetHasVote(false); err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		st.StartSync()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+}
+
+func (s *EnsureAvailabilitySuite) TestRemove(c *gc.C) {
+	s.fake.removeResults = []params.StateServersChanges{{
+		Maintained: []string{"machine-0", "machine-1", "machine-2"},
+		Removed:    []string{"machine-4"},
+	}}
+	ctx, err := s.runEnsureAvailability(c, "--remove", "4", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, "removed state server machine 4\n")
+	var result map[string][]string
+	err = goyaml.Unmarshal(ctx.Stdout.(*bytes.Buffer).Bytes(), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, map[string][]string{
+		"maintained": {"0", "1", "2"},
+		"removed":    {"4"},
+	})
+	c.Assert(s.fake.removeMachineId, gc.Equals, "4")
+	c.Assert(s.fake.replace, jc.IsFalse)
+}
+
+func (s *EnsureAvailabilitySuite) TestRemoveTimeout(c *gc.C) {
+	s.PatchValue(&removeStateServerPollInterval, time.Millisecond)
+	s.PatchValue(&removeStateServerTimeout, 10*time.Millisecond)
+	s.fake.removeResults = []params.StateServersChanges{{
+		Demoted: []string{"machine-2"},
+	}}
+	s.fakeStatus.statuses = []*api.Status{
+		stateServerStatus([]string{"0", "1", "2"}, "0", "1", "2"),
+	}
+	_, err := s.runEnsureAvailability(c, "--remove", "2")
+	c.Assert(err, gc.ErrorMatches, "timed out waiting for state server machine 2 to be removed")
+	c.Assert(s.fake.removeCalls, gc.Equals, 1)
+}
+
+func (s *EnsureAvailabilitySuite) TestBlockRemove(c *gc.C) {
+	s.fake.err = common.ErrOperationBlocked("TestBlockRemove")
+	_, err := s.runEnsureAvailability(c, "--remove", "2")
+	c.Assert(err, gc.ErrorMatches, cmd.ErrSilent.Error())
+}
@@ -195,6 +195,7 @@ var commandNames = []string{
 	"add-unit",
 	"api-endpoints",
 	"api-info",
+	"attach",
 	"authorised-keys", // alias for authorized-keys
 	"authorized-keys",
 	"backups",
@@ -40,8 +40,13 @@ names.  At least one target specifier is needed.
 Multiple values can be set for --machine, --service, and --unit by using
 comma separated values.
 
-If the target is a machine, the command is run as the "ubuntu" user on
-the remote machine.
+The commands are delivered to the machine and unit agents over their
+API connections, so no SSH access to the targets is needed. Agents run
+the commands while holding the hook execution lock, so they never run
+concurrently with hooks on the same machine.
+
+If the target is a machine, the command is run by the machine agent,
+outside of any hook context.
 
 If the target is a service, the command is run on all units for that
 service. For example, if there was a service "mysql" and that service
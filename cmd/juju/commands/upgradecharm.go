@@ -26,6 +26,7 @@ type UpgradeCharmCommand struct {
 	RepoPath    string // defaults to JUJU_REPOSITORY
 	SwitchURL   string
 	Revision    int // defaults to -1 (latest)
+	Signature   cmd.FileVar
 }
 
 const upgradeCharmDoc = `
@@ -61,6 +62,9 @@ The new charm may add new relations and configuration settings.
 number with --switch, give it in the charm URL, for instance "cs:wordpress-5"
 would specify revision number 5 of the wordpress charm.
 
+If the environment only accepts signed charms, the --signature flag gives the
+path to an armored detached signature of the new charm archive, as for deploy.
+
 Use of the --force flag is not generally recommended; units upgraded while in an
 error state will not have upgrade-charm hooks executed, and may cause unexpected
 behavior.
@@ -80,6 +84,7 @@ func (c *UpgradeCharmCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.RepoPath, "repository", os.Getenv("JUJU_REPOSITORY"), "local charm repository path")
 	f.StringVar(&c.SwitchURL, "switch", "", "crossgrade to a different charm")
 	f.IntVar(&c.Revision, "revision", -1, "explicit revision of current charm")
+	f.Var(&c.Signature, "signature", "path to an armored detached signature of the new charm archive")
 }
 
 func (c *UpgradeCharmCommand) Init(args []string) error {
@@ -153,7 +158,11 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		}
 	}
 
-	addedURL, err := addCharmViaAPI(client, ctx, newURL, repo, csClient)
+	signature, err := readCharmSignature(ctx, c.Signature)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	addedURL, err := addCharmViaAPI(client, ctx, newURL, repo, csClient, signature)
 	if err != nil {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
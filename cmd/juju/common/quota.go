@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider"
+)
+
+// QuotaPreflightAPI defines the client API method called to check
+// requests for new machines against the provider quotas.
+type QuotaPreflightAPI interface {
+	QuotaPreflight(params.QuotaPreflight) (params.QuotaPreflightResult, error)
+}
+
+// CheckQuotas asks the API server whether provisioning the new machines
+// described by args would exceed the environment's remaining provider
+// quotas. If it would, a warning is written to ctx, or an error is
+// returned if the environment is configured to refuse such requests.
+// The check is skipped if the API server does not support it, and a
+// warning is written if it fails.
+func CheckQuotas(ctx *cmd.Context, client QuotaPreflightAPI, args params.QuotaPreflight) error {
+	if args.Machines <= 0 {
+		return nil
+	}
+	result, err := client.QuotaPreflight(args)
+	if params.IsCodeNotImplemented(err) {
+		return nil
+	}
+	if err != nil {
+		// The preflight is advisory; failing to run it must not
+		// prevent the request from being made.
+		ctx.Infof("WARNING: cannot check provider quotas: %v", err)
+		return nil
+	}
+	if len(result.Exceeded) == 0 {
+		return nil
+	}
+	exceeded := strings.Join(result.Exceeded, "; ")
+	if result.Refuse {
+		return errors.Errorf("request would exceed provider quotas: %s", exceeded)
+	}
+	ctx.Infof("WARNING: request may exceed provider quotas: %s", exceeded)
+	return nil
+}
+
+// NewMachines returns the number of new machines that are provisioned
+// when numUnits units are deployed according to the given placement.
+// Units placed on existing machines, or in new containers on existing
+// machines, do not require new machines. A non-empty placementSpec
+// with no parsed placement holds a single existing machine or new
+// container, as accepted by older API servers.
+func NewMachines(numUnits int, placementSpec string, placement []*instance.Placement) int {
+	if placementSpec != "" && len(placement) == 0 {
+		return 0
+	}
+	machines := numUnits
+	for i, p := range placement {
+		if i >= numUnits {
+			break
+		}
+		if p.Directive == "" {
+			continue
+		}
+		if _, err := instance.ParseContainerType(p.Scope); err == nil || p.Scope == instance.MachineScope {
+			machines--
+		}
+	}
+	return machines
+}
+
+// localStoragePools holds the storage pools whose storage is provided
+// by the machine itself, rather than by provider volumes.
+var localStoragePools = map[string]bool{
+	string(provider.LoopProviderType):     true,
+	string(provider.HostLoopProviderType): true,
+	string(provider.RootfsProviderType):   true,
+	string(provider.TmpfsProviderType):    true,
+}
+
+// NewVolumes returns the number of provider volumes that may be
+// created for n units or machines, each with storage according to the
+// given constraints. Storage from machine-local pools is not counted.
+func NewVolumes(n int, cons []storage.Constraints) int {
+	var volumes int
+	for _, c := range cons {
+		if !localStoragePools[c.Pool] {
+			volumes += int(c.Count)
+		}
+	}
+	return n * volumes
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/common"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/testing"
+)
+
+type QuotaSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&QuotaSuite{})
+
+type fakeQuotaPreflightAPI struct {
+	args   params.QuotaPreflight
+	result params.QuotaPreflightResult
+	err    error
+	called bool
+}
+
+func (f *fakeQuotaPreflightAPI) QuotaPreflight(args params.QuotaPreflight) (params.QuotaPreflightResult, error) {
+	f.called = true
+	f.args = args
+	return f.result, f.err
+}
+
+func (s *QuotaSuite) TestCheckQuotasWithinQuotas(c *gc.C) {
+	api := &fakeQuotaPreflightAPI{}
+	ctx := testing.Context(c)
+	args := params.QuotaPreflight{Machines: 2, ServiceName: "mysql"}
+	err := common.CheckQuotas(ctx, api, args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(api.args, jc.DeepEquals, args)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *QuotaSuite) TestCheckQuotasNoMachines(c *gc.C) {
+	api := &fakeQuotaPreflightAPI{}
+	err := common.CheckQuotas(testing.Context(c), api, params.QuotaPreflight{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(api.called, jc.IsFalse)
+}
+
+func (s *QuotaSuite) TestCheckQuotasWarn(c *gc.C) {
+	api := &fakeQuotaPreflightAPI{
+		result: params.QuotaPreflightResult{
+			Exceeded: []string{
+				"3 instances requested, 2 remaining in quota",
+				"6 cores requested, 4 remaining in quota",
+			},
+		},
+	}
+	ctx := testing.Context(c)
+	err := common.CheckQuotas(ctx, api, params.QuotaPreflight{Machines: 3})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals,
+		"WARNING: request may exceed provider quotas: "+
+			"3 instances requested, 2 remaining in quota; "+
+			"6 cores requested, 4 remaining in quota\n",
+	)
+}
+
+func (s *QuotaSuite) TestCheckQuotasRefuse(c *gc.C) {
+	api := &fakeQuotaPreflightAPI{
+		result: params.QuotaPreflightResult{
+			Exceeded: []string{"3 instances requested, 2 remaining in quota"},
+			Refuse:   true,
+		},
+	}
+	err := common.CheckQuotas(testing.Context(c), api, params.QuotaPreflight{Machines: 3})
+	c.Assert(err, gc.ErrorMatches, "request would exceed provider quotas: 3 instances requested, 2 remaining in quota")
+}
+
+func (s *QuotaSuite) TestCheckQuotasNotImplemented(c *gc.C) {
+	api := &fakeQuotaPreflightAPI{
+		err: &params.Error{Code: params.CodeNotImplemented},
+	}
+	err := common.CheckQuotas(testing.Context(c), api, params.QuotaPreflight{Machines: 1})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *QuotaSuite) TestCheckQuotasError(c *gc.C) {
+	api := &fakeQuotaPreflightAPI{err: errors.New("boom")}
+	ctx := testing.Context(c)
+	err := common.CheckQuotas(ctx, api, params.QuotaPreflight{Machines: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "WARNING: cannot check provider quotas: boom\n")
+}
+
+func (s *QuotaSuite) TestNewMachines(c *gc.C) {
+	for i, test := range []struct {
+		numUnits      int
+		placementSpec string
+		placement     []string
+		expected      int
+	}{{
+		numUnits: 3,
+		expected: 3,
+	}, {
+		numUnits:      1,
+		placementSpec: "lxc:1",
+		expected:      0,
+	}, {
+		numUnits:      4,
+		placementSpec: "1,lxc:2,lxc,zone=a",
+		placement:     []string{"1", "lxc:2", "lxc", "env-uuid:zone=a"},
+		expected:      2,
+	}, {
+		numUnits:      3,
+		placementSpec: "1",
+		placement:     []string{"1"},
+		expected:      2,
+	}, {
+		numUnits:      1,
+		placementSpec: "1,2",
+		placement:     []string{"kvm", "2"},
+		expected:      1,
+	}} {
+		c.Logf("test %d", i)
+		var placement []*instance.Placement
+		for _, p := range test.placement {
+			placement = append(placement, instance.MustParsePlacement(p))
+		}
+		machines := common.NewMachines(test.numUnits, test.placementSpec, placement)
+		c.Check(machines, gc.Equals, test.expected)
+	}
+}
+
+func (s *QuotaSuite) TestNewVolumes(c *gc.C) {
+	volumes := common.NewVolumes(3, []storage.Constraints{
+		{Pool: "ebs", Count: 2},
+		{Count: 1},
+		{Pool: "loop", Count: 5},
+		{Pool: "tmpfs", Count: 1},
+	})
+	c.Assert(volumes, gc.Equals, 9)
+}
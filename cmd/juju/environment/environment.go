@@ -34,12 +34,14 @@ func NewSuperCommand() cmd.Command {
 	environmentCmd.Register(envcmd.Wrap(&RetryProvisioningCommand{}))
 	environmentCmd.Register(envcmd.Wrap(&EnvSetConstraintsCommand{}))
 	environmentCmd.Register(envcmd.Wrap(&EnvGetConstraintsCommand{}))
+	environmentCmd.Register(envcmd.Wrap(&WebhookDeliveriesCommand{}))
 
 	if featureflag.Enabled(feature.JES) {
 		environmentCmd.Register(envcmd.Wrap(&ShareCommand{}))
 		environmentCmd.Register(envcmd.Wrap(&UnshareCommand{}))
 		environmentCmd.Register(envcmd.Wrap(&UsersCommand{}))
 		environmentCmd.Register(envcmd.Wrap(&DestroyCommand{}))
+		environmentCmd.Register(envcmd.Wrap(&ExportCommand{}))
 	}
 	return environmentCmd
 }
@@ -25,6 +25,7 @@ var _ = gc.Suite(&EnvironmentCommandSuite{})
 
 var expectedCommmandNames = []string{
 	"destroy",
+	"export",
 	"get",
 	"get-constraints",
 	"help",
@@ -36,6 +37,7 @@ var expectedCommmandNames = []string{
 	"unset",
 	"unshare",
 	"users",
+	"webhook-deliveries",
 }
 
 func (s *EnvironmentCommandSuite) TestHelpCommands(c *gc.C) {
@@ -48,7 +50,7 @@ func (s *EnvironmentCommandSuite) TestHelpCommands(c *gc.C) {
 
 	// Remove "share" for the first test because the feature is not
 	// enabled.
-	devFeatures := set.NewStrings("destroy", "share", "unshare", "users")
+	devFeatures := set.NewStrings("destroy", "export", "share", "unshare", "users")
 
 	// Remove features behind dev_flag for the first test since they are not
 	// enabled.
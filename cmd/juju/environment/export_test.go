@@ -58,3 +58,18 @@ func NewDestroyCommand(api DestroyEnvironmentAPI) *DestroyCommand {
 		api: api,
 	}
 }
+
+// NewExportCommand returns an ExportCommand with the api provided as specified.
+func NewExportCommand(api ExportEnvironmentAPI) *ExportCommand {
+	return &ExportCommand{
+		api: api,
+	}
+}
+
+// NewWebhookDeliveriesCommand returns a WebhookDeliveriesCommand with the
+// api provided as specified.
+func NewWebhookDeliveriesCommand(api WebhookDeliveriesAPI) *WebhookDeliveriesCommand {
+	return &WebhookDeliveriesCommand{
+		api: api,
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"io/ioutil"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/environmentmanager"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const exportCommandDoc = `
+Writes a description of the model of the current environment, in YAML,
+to standard output or to the file given with --output. The description
+includes the environment's configuration, constraints, users, machines,
+services, units, relations, storage and annotations, and the archives
+of the charms the services use.
+
+The description can be used to recreate the model in a new environment,
+for disaster recovery or cloning.
+
+Examples:
+
+    # Write the model description to standard output
+    juju environment export
+
+    # Write the model description to a file
+    juju environment export -o myenv.yaml
+`
+
+// ExportCommand writes a description of the model of the current
+// environment.
+type ExportCommand struct {
+	envcmd.EnvCommandBase
+	outFile string
+	api     ExportEnvironmentAPI
+}
+
+// ExportEnvironmentAPI defines the methods on the environmentmanager
+// API that the export command calls. It is exported for mocking in tests.
+type ExportEnvironmentAPI interface {
+	Close() error
+	ExportEnvironment(envUUID string) ([]byte, error)
+}
+
+// Info implements Command.Info.
+func (c *ExportCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "export",
+		Purpose: "export a description of the environment's model",
+		Doc:     exportCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *ExportCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.outFile, "o", "", "write the description to the specified file")
+	f.StringVar(&c.outFile, "output", "", "")
+}
+
+// Init implements Command.Init.
+func (c *ExportCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ExportCommand) getAPI() (ExportEnvironmentAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return environmentmanager.NewClient(root), nil
+}
+
+// Run implements Command.Run.
+func (c *ExportCommand) Run(ctx *cmd.Context) error {
+	endpoint, err := c.ConnectionEndpoint(false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if endpoint.EnvironUUID == "" {
+		return errors.New("cannot determine environment UUID")
+	}
+
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	model, err := client.ExportEnvironment(endpoint.EnvironUUID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if c.outFile == "" {
+		_, err := ctx.Stdout.Write(model)
+		return err
+	}
+	if err := ioutil.WriteFile(ctx.AbsPath(c.outFile), model, 0600); err != nil {
+		return errors.Annotate(err, "cannot write model description")
+	}
+	ctx.Infof("environment model written to %s", c.outFile)
+	return nil
+}
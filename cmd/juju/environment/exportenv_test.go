@@ -0,0 +1,90 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/testing"
+)
+
+type ExportSuite struct {
+	testing.FakeJujuHomeSuite
+	api *fakeExportAPI
+}
+
+var _ = gc.Suite(&ExportSuite{})
+
+type fakeExportAPI struct {
+	envUUID string
+	model   []byte
+	err     error
+}
+
+func (f *fakeExportAPI) Close() error { return nil }
+
+func (f *fakeExportAPI) ExportEnvironment(envUUID string) ([]byte, error) {
+	f.envUUID = envUUID
+	return f.model, f.err
+}
+
+func (s *ExportSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeExportAPI{model: []byte("version: 1\n")}
+
+	store, err := configstore.Default()
+	c.Assert(err, jc.ErrorIsNil)
+	info := store.CreateInfo("test2")
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"localhost"},
+		CACert:      testing.CACert,
+		EnvironUUID: "test2-uuid",
+		ServerUUID:  "test1-uuid",
+	})
+	err = info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ExportSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := environment.NewExportCommand(s.api)
+	return testing.RunCommand(c, envcmd.Wrap(command), append([]string{"-e", "test2"}, args...)...)
+}
+
+func (s *ExportSuite) TestInit(c *gc.C) {
+	_, err := s.run(c, "extra")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *ExportSuite) TestExportStdout(c *gc.C) {
+	ctx, err := s.run(c)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.envUUID, gc.Equals, "test2-uuid")
+	c.Assert(testing.Stdout(ctx), gc.Equals, "version: 1\n")
+}
+
+func (s *ExportSuite) TestExportFile(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "model.yaml")
+	ctx, err := s.run(c, "-o", path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "environment model written to "+path+"\n")
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "version: 1\n")
+}
+
+func (s *ExportSuite) TestExportError(c *gc.C) {
+	s.api.err = errors.New("permission denied")
+	_, err := s.run(c)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
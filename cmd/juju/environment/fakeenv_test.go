@@ -27,11 +27,13 @@ func (s *fakeEnvSuite) SetUpTest(c *gc.C) {
 }
 
 type fakeEnvAPI struct {
-	values      map[string]interface{}
-	err         error
-	keys        []string
-	addUsers    []names.UserTag
-	removeUsers []names.UserTag
+	values       map[string]interface{}
+	err          error
+	keys         []string
+	addUsers     []names.UserTag
+	removeUsers  []names.UserTag
+	addGroups    []string
+	removeGroups []string
 }
 
 func (f *fakeEnvAPI) Close() error {
@@ -61,3 +63,13 @@ func (f *fakeEnvAPI) UnshareEnvironment(users ...names.UserTag) error {
 	f.removeUsers = users
 	return f.err
 }
+
+func (f *fakeEnvAPI) ShareEnvironmentWithGroups(groups ...string) error {
+	f.addGroups = groups
+	return f.err
+}
+
+func (f *fakeEnvAPI) UnshareEnvironmentWithGroups(groups ...string) error {
+	f.removeGroups = groups
+	return f.err
+}
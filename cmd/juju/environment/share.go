@@ -9,6 +9,7 @@ import (
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
@@ -26,6 +27,10 @@ Examples:
 
  juju environment share sam --environment myenv
      Give local user "sam" access to the environment named "myenv"
+
+ juju environment share --group ops dev
+     Give the members of the user groups "ops" and "dev" access to the
+     current environment
  `
 
 // ShareCommand represents the command to share an environment with a user(s).
@@ -36,19 +41,40 @@ type ShareCommand struct {
 
 	// Users to share the environment with.
 	Users []names.UserTag
+
+	// Groups to share the environment with.
+	Groups []string
+	group  bool
 }
 
 // Info implements Command.Info.
 func (c *ShareCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "share",
-		Args:    "<user> ...",
+		Args:    "<user>|<group> ...",
 		Purpose: "share the current environment with another user",
 		Doc:     strings.TrimSpace(shareEnvHelpDoc),
 	}
 }
 
+// SetFlags implements Command.SetFlags.
+func (c *ShareCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.group, "group", false, "treat the arguments as names of user groups")
+}
+
 func (c *ShareCommand) Init(args []string) (err error) {
+	if c.group {
+		if len(args) == 0 {
+			return errors.New("no groups specified")
+		}
+		for _, arg := range args {
+			if !names.IsValidUserName(arg) {
+				return errors.Errorf("invalid group name: %q", arg)
+			}
+		}
+		c.Groups = args
+		return nil
+	}
 	if len(args) == 0 {
 		return errors.New("no users specified")
 	}
@@ -74,6 +100,7 @@ func (c *ShareCommand) getAPI() (ShareEnvironmentAPI, error) {
 type ShareEnvironmentAPI interface {
 	Close() error
 	ShareEnvironment(...names.UserTag) error
+	ShareEnvironmentWithGroups(...string) error
 }
 
 func (c *ShareCommand) Run(ctx *cmd.Context) error {
@@ -83,5 +110,10 @@ func (c *ShareCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
-	return block.ProcessBlockedError(client.ShareEnvironment(c.Users...), block.BlockChange)
+	if c.group {
+		err = client.ShareEnvironmentWithGroups(c.Groups...)
+	} else {
+		err = client.ShareEnvironment(c.Users...)
+	}
+	return block.ProcessBlockedError(err, block.BlockChange)
 }
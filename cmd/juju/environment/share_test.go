@@ -57,3 +57,18 @@ func (s *shareSuite) TestBlockShare(c *gc.C) {
 	c.Assert(err, gc.Equals, cmd.ErrSilent)
 	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
 }
+
+func (s *shareSuite) TestInitGroups(c *gc.C) {
+	shareCmd := &environment.ShareCommand{}
+	err := testing.InitCommand(shareCmd, []string{"--group"})
+	c.Assert(err, gc.ErrorMatches, "no groups specified")
+
+	err = testing.InitCommand(shareCmd, []string{"--group", "ops@local"})
+	c.Assert(err, gc.ErrorMatches, `invalid group name: "ops@local"`)
+}
+
+func (s *shareSuite) TestPassesGroups(c *gc.C) {
+	_, err := s.run(c, "--group", "ops", "dev")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.addGroups, jc.DeepEquals, []string{"ops", "dev"})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
@@ -26,6 +27,10 @@ Examples:
 
  juju environment unshare sam -e/--environment myenv
      Deny local user "sam" access to the environment named "myenv"
+
+ juju environment unshare --group ops
+     Deny the members of the user group "ops" the access given to them
+     through the group
  `
 
 // UnshareCommand unshares an environment with the given user(s).
@@ -37,18 +42,39 @@ type UnshareCommand struct {
 
 	// Users to unshare the environment with.
 	Users []names.UserTag
+
+	// Groups to unshare the environment with.
+	Groups []string
+	group  bool
 }
 
 func (c *UnshareCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "unshare",
-		Args:    "<user> ...",
+		Args:    "<user>|<group> ...",
 		Purpose: "unshare the current environment with a user",
 		Doc:     strings.TrimSpace(unshareEnvHelpDoc),
 	}
 }
 
+// SetFlags implements Command.SetFlags.
+func (c *UnshareCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.group, "group", false, "treat the arguments as names of user groups")
+}
+
 func (c *UnshareCommand) Init(args []string) (err error) {
+	if c.group {
+		if len(args) == 0 {
+			return errors.New("no groups specified")
+		}
+		for _, arg := range args {
+			if !names.IsValidUserName(arg) {
+				return errors.Errorf("invalid group name: %q", arg)
+			}
+		}
+		c.Groups = args
+		return nil
+	}
 	if len(args) == 0 {
 		return errors.New("no users specified")
 	}
@@ -75,6 +101,7 @@ func (c *UnshareCommand) getAPI() (UnshareEnvironmentAPI, error) {
 type UnshareEnvironmentAPI interface {
 	Close() error
 	UnshareEnvironment(...names.UserTag) error
+	UnshareEnvironmentWithGroups(...string) error
 }
 
 func (c *UnshareCommand) Run(ctx *cmd.Context) error {
@@ -84,5 +111,10 @@ func (c *UnshareCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
-	return block.ProcessBlockedError(client.UnshareEnvironment(c.Users...), block.BlockChange)
+	if c.group {
+		err = client.UnshareEnvironmentWithGroups(c.Groups...)
+	} else {
+		err = client.UnshareEnvironment(c.Users...)
+	}
+	return block.ProcessBlockedError(err, block.BlockChange)
 }
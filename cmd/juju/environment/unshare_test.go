@@ -56,3 +56,18 @@ func (s *unshareSuite) TestBlockUnShare(c *gc.C) {
 	c.Assert(err, gc.Equals, cmd.ErrSilent)
 	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
 }
+
+func (s *unshareSuite) TestInitGroups(c *gc.C) {
+	unshareCmd := &environment.UnshareCommand{}
+	err := testing.InitCommand(unshareCmd, []string{"--group"})
+	c.Assert(err, gc.ErrorMatches, "no groups specified")
+
+	err = testing.InitCommand(unshareCmd, []string{"--group", "ops@local"})
+	c.Assert(err, gc.ErrorMatches, `invalid group name: "ops@local"`)
+}
+
+func (s *unshareSuite) TestPassesGroups(c *gc.C) {
+	_, err := s.run(c, "--group", "ops", "dev")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.removeGroups, jc.DeepEquals, []string{"ops", "dev"})
+}
@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const webhookDeliveriesDoc = `
+Shows the most recent deliveries of environment events to the webhooks
+configured in the environment's webhook-urls setting, most recent first.
+
+Each delivery shows the event that was posted, the entity it relates to,
+the webhook URL, and whether the webhook accepted the event. Deliveries
+that fail temporarily are retried with increasing delays before being
+reported as failed.
+
+See Also:
+    juju help environment set
+`
+
+// WebhookDeliveriesCommand shows the recent deliveries of environment
+// events to webhooks.
+type WebhookDeliveriesCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+	api WebhookDeliveriesAPI
+}
+
+// WebhookDelivery defines the serialization behaviour of a webhook
+// delivery.
+type WebhookDelivery struct {
+	Time      string `yaml:"time" json:"time"`
+	Event     string `yaml:"event" json:"event"`
+	Entity    string `yaml:"entity" json:"entity"`
+	URL       string `yaml:"url" json:"url"`
+	Delivered bool   `yaml:"delivered" json:"delivered"`
+	Attempts  int    `yaml:"attempts" json:"attempts"`
+	Error     string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// WebhookDeliveriesAPI defines the methods on the client API that the
+// webhook-deliveries command calls.
+type WebhookDeliveriesAPI interface {
+	Close() error
+	WebhookDeliveries() ([]params.WebhookDelivery, error)
+}
+
+func (c *WebhookDeliveriesCommand) getAPI() (WebhookDeliveriesAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+// Info implements Command.Info.
+func (c *WebhookDeliveriesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "webhook-deliveries",
+		Purpose: "shows recent deliveries of environment events to webhooks",
+		Doc:     webhookDeliveriesDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *WebhookDeliveriesCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": c.formatTabular,
+	})
+}
+
+// Run implements Command.Run.
+func (c *WebhookDeliveriesCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	result, err := client.WebhookDeliveries()
+	if err != nil {
+		return err
+	}
+
+	output := make([]WebhookDelivery, len(result))
+	for i, d := range result {
+		output[i] = WebhookDelivery{
+			Time:      d.Time.UTC().Format(time.RFC3339),
+			Event:     d.Event,
+			Entity:    d.Entity,
+			URL:       d.URL,
+			Delivered: d.Delivered,
+			Attempts:  d.Attempts,
+			Error:     d.Error,
+		}
+	}
+	return c.out.Write(ctx, output)
+}
+
+// formatTabular takes an interface{} to adhere to the cmd.Formatter interface
+func (c *WebhookDeliveriesCommand) formatTabular(value interface{}) ([]byte, error) {
+	deliveries, ok := value.([]WebhookDelivery)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", deliveries, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "TIME\tEVENT\tENTITY\tURL\tSTATUS\tATTEMPTS\n")
+	for _, d := range deliveries {
+		status := "delivered"
+		if !d.Delivered {
+			status = "failed: " + d.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", d.Time, d.Event, d.Entity, d.URL, status, d.Attempts)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type WebhookDeliveriesSuite struct {
+	fake *fakeWebhookDeliveriesClient
+}
+
+var _ = gc.Suite(&WebhookDeliveriesSuite{})
+
+type fakeWebhookDeliveriesClient struct {
+	deliveries []params.WebhookDelivery
+}
+
+func (f *fakeWebhookDeliveriesClient) Close() error {
+	return nil
+}
+
+func (f *fakeWebhookDeliveriesClient) WebhookDeliveries() ([]params.WebhookDelivery, error) {
+	return f.deliveries, nil
+}
+
+func (s *WebhookDeliveriesSuite) SetUpTest(c *gc.C) {
+	s.fake = &fakeWebhookDeliveriesClient{
+		deliveries: []params.WebhookDelivery{{
+			Event:    "machine-error",
+			Entity:   "machine-1",
+			URL:      "https://example.com/hook",
+			Time:     time.Date(2015, 7, 1, 12, 5, 0, 0, time.UTC),
+			Attempts: 5,
+			Error:    "500 Internal Server Error",
+		}, {
+			Event:     "unit-error",
+			Entity:    "unit-wordpress-0",
+			URL:       "https://example.com/hook",
+			Time:      time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC),
+			Attempts:  1,
+			Delivered: true,
+		}},
+	}
+}
+
+func (s *WebhookDeliveriesSuite) TestWebhookDeliveries(c *gc.C) {
+	context, err := testing.RunCommand(c, environment.NewWebhookDeliveriesCommand(s.fake))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, ""+
+		"TIME                  EVENT          ENTITY            URL                       STATUS                             ATTEMPTS\n"+
+		"2015-07-01T12:05:00Z  machine-error  machine-1         https://example.com/hook  failed: 500 Internal Server Error  5\n"+
+		"2015-07-01T12:00:00Z  unit-error     unit-wordpress-0  https://example.com/hook  delivered                          1\n"+
+		"\n")
+}
+
+func (s *WebhookDeliveriesSuite) TestWebhookDeliveriesFormatJson(c *gc.C) {
+	context, err := testing.RunCommand(c, environment.NewWebhookDeliveriesCommand(s.fake), "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, "["+
+		`{"time":"2015-07-01T12:05:00Z","event":"machine-error","entity":"machine-1","url":"https://example.com/hook","delivered":false,"attempts":5,"error":"500 Internal Server Error"},`+
+		`{"time":"2015-07-01T12:00:00Z","event":"unit-error","entity":"unit-wordpress-0","url":"https://example.com/hook","delivered":true,"attempts":1}`+
+		"]\n")
+}
+
+func (s *WebhookDeliveriesSuite) TestWebhookDeliveriesFormatYaml(c *gc.C) {
+	s.fake.deliveries = s.fake.deliveries[1:]
+	context, err := testing.RunCommand(c, environment.NewWebhookDeliveriesCommand(s.fake), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, ""+
+		"- time: \"2015-07-01T12:00:00Z\"\n"+
+		"  event: unit-error\n"+
+		"  entity: unit-wordpress-0\n"+
+		"  url: https://example.com/hook\n"+
+		"  delivered: true\n"+
+		"  attempts: 1\n")
+}
+
+func (s *WebhookDeliveriesSuite) TestUnrecognizedArg(c *gc.C) {
+	_, err := testing.RunCommand(c, environment.NewWebhookDeliveriesCommand(s.fake), "whoops")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["whoops"\]`)
+}
@@ -178,6 +178,15 @@ Placement directives:
       does not exist, or a machine cannot be allocated within it, then
       the machine addition will fail.
 
+    spot-price=<max-hourly-price>
+      The "spot-price" placement directive instructs the EC2 provider to
+      request a spot instance, bidding at most the given price in US
+      dollars per hour, instead of an on-demand instance. If the request
+      is not fulfilled within two minutes it is cancelled, and the
+      machine addition fails. Spot instances interrupted by EC2 are
+      reported with a "spot instance interrupted" instance status.
+      Directives may be combined, e.g. "zone=us-east-1a,spot-price=0.05".
+
 References:
 
   [1]: http://askubuntu.com/questions/730/how-do-i-set-environment-variables
@@ -16,6 +16,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
+	"github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/configstore"
@@ -154,6 +155,7 @@ type AddMachineAPI interface {
 	EnvironmentGet() (map[string]interface{}, error)
 	EnvironmentUUID() string
 	ProvisioningScript(params.ProvisioningScriptParams) (script string, err error)
+	QuotaPreflight(params.QuotaPreflight) (params.QuotaPreflightResult, error)
 }
 
 type MachineManagerAPI interface {
@@ -243,6 +245,22 @@ func (c *AddCommand) Run(ctx *cmd.Context) error {
 		return fmt.Errorf("machine-id cannot be specified when adding machines")
 	}
 
+	newMachines := c.NumMachines
+	if c.Placement != nil && c.Placement.Directive != "" {
+		if _, err := instance.ParseContainerType(c.Placement.Scope); err == nil {
+			// New containers on an existing machine need no new instances.
+			newMachines = 0
+		}
+	}
+	err = common.CheckQuotas(ctx, client, params.QuotaPreflight{
+		Machines:    newMachines,
+		Constraints: c.Constraints,
+		Volumes:     common.NewVolumes(c.NumMachines, c.Disks),
+	})
+	if err != nil {
+		return err
+	}
+
 	jobs := []multiwatcher.MachineJob{multiwatcher.JobHostUnits}
 
 	envVersion, err := envcmd.GetEnvironmentVersion(client)
@@ -16,6 +16,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/machine"
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/manual"
 	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/storage"
@@ -220,12 +221,43 @@ func (s *AddMachineSuite) TestAddMachineWithDisksUnsupported(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "cannot add machines with disks: not supported by the API server")
 }
 
+func (s *AddMachineSuite) TestQuotaPreflight(c *gc.C) {
+	_, err := s.run(c, "-n", "3", "--constraints", "cpu-cores=4")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fakeAddMachine.preflight, jc.DeepEquals, params.QuotaPreflight{
+		Machines:    3,
+		Constraints: constraints.MustParse("cpu-cores=4"),
+	})
+}
+
+func (s *AddMachineSuite) TestQuotaPreflightNewContainer(c *gc.C) {
+	s.fakeAddMachine.quotaResult = params.QuotaPreflightResult{
+		Exceeded: []string{"1 instances requested, 0 remaining in quota"},
+		Refuse:   true,
+	}
+	_, err := s.run(c, "lxc:1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fakeAddMachine.preflight, jc.DeepEquals, params.QuotaPreflight{})
+}
+
+func (s *AddMachineSuite) TestQuotaPreflightRefused(c *gc.C) {
+	s.fakeAddMachine.quotaResult = params.QuotaPreflightResult{
+		Exceeded: []string{"2 instances requested, 1 remaining in quota"},
+		Refuse:   true,
+	}
+	_, err := s.run(c, "-n", "2")
+	c.Assert(err, gc.ErrorMatches, "request would exceed provider quotas: 2 instances requested, 1 remaining in quota")
+	c.Assert(s.fakeAddMachine.args, gc.HasLen, 0)
+}
+
 type fakeAddMachineAPI struct {
 	successOrder []bool
 	currentOp    int
 	args         []params.AddMachineParams
 	addError     error
 	agentVersion interface{}
+	preflight    params.QuotaPreflight
+	quotaResult  params.QuotaPreflightResult
 }
 
 func (f *fakeAddMachineAPI) Close() error {
@@ -275,6 +307,11 @@ func (f *fakeAddMachineAPI) EnvironmentGet() (map[string]interface{}, error) {
 	return map[string]interface{}{"agent-version": f.agentVersion}, nil
 }
 
+func (f *fakeAddMachineAPI) QuotaPreflight(args params.QuotaPreflight) (params.QuotaPreflightResult, error) {
+	f.preflight = args
+	return f.quotaResult, nil
+}
+
 type fakeMachineManagerAPI struct {
 	apiVersion int
 	fakeAddMachineAPI
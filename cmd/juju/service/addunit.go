@@ -15,6 +15,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
+	"github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider"
@@ -160,6 +161,7 @@ type ServiceAddUnitAPI interface {
 	AddServiceUnits(service string, numUnits int, machineSpec string) ([]string, error)
 	AddServiceUnitsWithPlacement(service string, numUnits int, placement []*instance.Placement) ([]string, error)
 	EnvironmentGet() (map[string]interface{}, error)
+	QuotaPreflight(params.QuotaPreflight) (params.QuotaPreflightResult, error)
 }
 
 func (c *AddUnitCommand) getAPI() (ServiceAddUnitAPI, error) {
@@ -171,7 +173,7 @@ func (c *AddUnitCommand) getAPI() (ServiceAddUnitAPI, error) {
 
 // Run connects to the environment specified on the command line
 // and calls AddServiceUnits for the given service.
-func (c *AddUnitCommand) Run(_ *cmd.Context) error {
+func (c *AddUnitCommand) Run(ctx *cmd.Context) error {
 	apiclient, err := c.getAPI()
 	if err != nil {
 		return err
@@ -187,6 +189,14 @@ func (c *AddUnitCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 
+	err = common.CheckQuotas(ctx, apiclient, params.QuotaPreflight{
+		Machines:    common.NewMachines(c.NumUnits, c.PlacementSpec, c.Placement),
+		ServiceName: c.ServiceName,
+	})
+	if err != nil {
+		return err
+	}
+
 	for i, p := range c.Placement {
 		if p.Scope == "env-uuid" {
 			p.Scope = apiclient.EnvironmentUUID()
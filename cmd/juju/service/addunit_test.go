@@ -32,6 +32,8 @@ type fakeServiceAddUnitAPI struct {
 	placement   []*instance.Placement
 	err         error
 	newAPI      bool
+	preflight   params.QuotaPreflight
+	quotaResult params.QuotaPreflightResult
 }
 
 func (f *fakeServiceAddUnitAPI) Close() error {
@@ -83,6 +85,11 @@ func (f *fakeServiceAddUnitAPI) EnvironmentGet() (map[string]interface{}, error)
 	return cfg.AllAttrs(), nil
 }
 
+func (f *fakeServiceAddUnitAPI) QuotaPreflight(args params.QuotaPreflight) (params.QuotaPreflightResult, error) {
+	f.preflight = args
+	return f.quotaResult, nil
+}
+
 func (s *AddUnitSuite) SetUpTest(c *gc.C) {
 	s.FakeJujuHomeSuite.SetUpTest(c)
 	s.fake = &fakeServiceAddUnitAPI{service: "some-service-name", numUnits: 1, envType: "dummy"}
@@ -164,6 +171,36 @@ func (s *AddUnitSuite) TestAddUnitWithPlacement(c *gc.C) {
 	})
 }
 
+func (s *AddUnitSuite) TestAddUnitQuotaPreflight(c *gc.C) {
+	s.fake.newAPI = true
+	err := s.runAddUnit(c, "--num-units", "3", "--to", "lxc:1", "some-service-name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.preflight, jc.DeepEquals, params.QuotaPreflight{
+		Machines:    2,
+		ServiceName: "some-service-name",
+	})
+}
+
+func (s *AddUnitSuite) TestAddUnitQuotaPreflightWarning(c *gc.C) {
+	s.fake.quotaResult = params.QuotaPreflightResult{
+		Exceeded: []string{"2 instances requested, 1 remaining in quota"},
+	}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(service.NewAddUnitCommand(s.fake)), "-n", "2", "some-service-name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "WARNING: request may exceed provider quotas: 2 instances requested, 1 remaining in quota\n")
+	c.Assert(s.fake.numUnits, gc.Equals, 3)
+}
+
+func (s *AddUnitSuite) TestAddUnitQuotaPreflightRefused(c *gc.C) {
+	s.fake.quotaResult = params.QuotaPreflightResult{
+		Exceeded: []string{"2 instances requested, 1 remaining in quota"},
+		Refuse:   true,
+	}
+	err := s.runAddUnit(c, "-n", "2", "some-service-name")
+	c.Assert(err, gc.ErrorMatches, "request would exceed provider quotas: 2 instances requested, 1 remaining in quota")
+	c.Assert(s.fake.numUnits, gc.Equals, 1)
+}
+
 func (s *AddUnitSuite) TestBlockAddUnit(c *gc.C) {
 	// Block operation
 	s.fake.err = common.ErrOperationBlocked("TestBlockAddUnit")
@@ -52,6 +52,9 @@ type VolumeInfo struct {
 	// from params.Volume
 	Persistent bool `yaml:"persistent" json:"persistent"`
 
+	// from params.Volume. This is the availability zone of the volume.
+	Zone string `yaml:"zone,omitempty" json:"zone,omitempty"`
+
 	// from params.VolumeAttachments
 	DeviceName string `yaml:"device,omitempty" json:"device,omitempty"`
 
@@ -126,6 +129,7 @@ func createInfo(volume params.VolumeInstance) (info VolumeInfo, unit, storage st
 	info.HardwareId = volume.HardwareId
 	info.Size = volume.Size
 	info.Persistent = volume.Persistent
+	info.Zone = volume.Zone
 
 	if v, err := idFromTag(volume.VolumeTag); err == nil {
 		info.Volume = v
@@ -99,9 +99,9 @@ func (s *volumeListSuite) TestVolumeListTabular(c *gc.C) {
 		[]string{"2"},
 		// Default format is tabular
 		`
-MACHINE  UNIT          STORAGE      DEVICE      VOLUME      ID                            SIZE
-2        postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         1.0GiB
-2        unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88  1.0GiB
+MACHINE  UNIT          STORAGE      DEVICE      VOLUME      ID                            ZONE  SIZE
+2        postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         az-1  1.0GiB
+2        unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88        1.0GiB
 
 `[1:],
 		`
@@ -116,11 +116,11 @@ func (s *volumeListSuite) TestVolumeListTabularSort(c *gc.C) {
 		[]string{"2", "3"},
 		// Default format is tabular
 		`
-MACHINE  UNIT          STORAGE      DEVICE      VOLUME      ID                            SIZE
-2        postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         1.0GiB
-2        unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88  1.0GiB
-3        postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         1.0GiB
-3        unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88  1.0GiB
+MACHINE  UNIT          STORAGE      DEVICE      VOLUME      ID                            ZONE  SIZE
+2        postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         az-1  1.0GiB
+2        unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88        1.0GiB
+3        postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         az-1  1.0GiB
+3        unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88        1.0GiB
 
 `[1:],
 		`
@@ -136,13 +136,13 @@ func (s *volumeListSuite) TestVolumeListTabularSortWithUnattached(c *gc.C) {
 		[]string{"2", "3"},
 		// Default format is tabular
 		`
-MACHINE     UNIT          STORAGE      DEVICE      VOLUME      ID                            SIZE
-25          postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         1.0GiB
-25          unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88  1.0GiB
-42          postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         1.0GiB
-42          unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88  1.0GiB
-unattached  abc/0         db-dir/1000              3/4         provider-supplied-3/4         1.0GiB
-unattached  unattached    unassigned               3/3         provider-supplied-3/3         1.0GiB
+MACHINE     UNIT          STORAGE      DEVICE      VOLUME      ID                            ZONE  SIZE
+25          postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         az-1  1.0GiB
+25          unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88        1.0GiB
+42          postgresql/0  shared-fs/0  testdevice  0/1         provider-supplied-0/1         az-1  1.0GiB
+42          unattached    shared-fs/0  testdevice  0/abc/0/88  provider-supplied-0/abc/0/88        1.0GiB
+unattached  abc/0         db-dir/1000              3/4         provider-supplied-3/4         az-1  1.0GiB
+unattached  unattached    unassigned               3/3         provider-supplied-3/3               1.0GiB
 
 `[1:],
 		`
@@ -206,6 +206,7 @@ func (s *volumeListSuite) assertSameVolumeInfos(c *gc.C, one, two map[string]map
 				propertyCompare(info1.HardwareId, info2.HardwareId)
 				propertyCompare(info1.Size, info2.Size)
 				propertyCompare(info1.Persistent, info2.Persistent)
+				propertyCompare(info1.Zone, info2.Zone)
 				propertyCompare(info1.DeviceName, info2.DeviceName)
 				propertyCompare(info1.ReadOnly, info2.ReadOnly)
 			}
@@ -299,6 +300,9 @@ func (s mockVolumeListAPI) createTestVolume(id string, persistent bool, storagei
 		Persistent: persistent,
 		Size:       uint64(1024),
 	}
+	if persistent {
+		result.Zone = "az-1"
+	}
 	if storageid != "" {
 		result.StorageTag = names.NewStorageTag(storageid).String()
 	}
@@ -35,7 +35,7 @@ func formatVolumeListTabularTyped(infos map[string]map[string]map[string]VolumeI
 	print := func(values ...string) {
 		fmt.Fprintln(tw, strings.Join(values, "\t"))
 	}
-	print("MACHINE", "UNIT", "STORAGE", "DEVICE", "VOLUME", "ID", "SIZE")
+	print("MACHINE", "UNIT", "STORAGE", "DEVICE", "VOLUME", "ID", "ZONE", "SIZE")
 
 	// 1. sort by machines
 	machines := set.NewStrings()
@@ -67,7 +67,7 @@ func formatVolumeListTabularTyped(infos map[string]map[string]map[string]VolumeI
 			for _, storage := range storages.SortedValues() {
 				info := unitStorages[storage]
 				size := humanize.IBytes(info.Size * humanize.MiByte)
-				print(machine, unit, storage, info.DeviceName, info.Volume, info.VolumeId, size)
+				print(machine, unit, storage, info.DeviceName, info.Volume, info.VolumeId, info.Zone, size)
 			}
 		}
 	}
@@ -0,0 +1,211 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+// CleanupsCommand lists the cleanups pending in the environments of a
+// system, and allows individual cleanups to be retried or skipped.
+type CleanupsCommand struct {
+	envcmd.SysCommandBase
+	out   cmd.Output
+	retry string
+	skip  string
+	api   cleanupsAPI
+}
+
+// cleanupsAPI defines the methods on the cleanup manager API that the
+// cleanups command calls.
+type cleanupsAPI interface {
+	Close() error
+	ListCleanups() ([]params.CleanupInfo, error)
+	RetryCleanup(envUUID, id string) error
+	SkipCleanup(envUUID, id string) error
+}
+
+var cleanupsDoc = `
+List the cleanups pending in all environments of the Juju system.
+
+When units, machines, services or storage are destroyed, Juju schedules
+cleanups to remove the entities that depend on them. Cleanups that fail
+are retried when other cleanups are run, waiting longer after each
+failure; a cleanup that fails repeatedly can leave an environment
+partially destroyed. For each pending cleanup, the kind of
+cleanup, the entities it applies to, how long ago it was scheduled, and
+the error of its last failed attempt are shown.
+
+A system administrator may retry a cleanup immediately with --retry, or
+discard it without running it with --skip. Skipping a cleanup leaves
+any entities it would have removed in place.
+
+Examples:
+
+    juju system cleanups
+    juju system cleanups --retry 55f6b0e2c8d1f32c54000003
+    juju system cleanups --skip 55f6b0e2c8d1f32c54000003
+
+See Also:
+    juju help system remove-blocks
+`
+
+// Info implements Command.Info
+func (c *CleanupsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "cleanups",
+		Purpose: "list, retry or skip pending cleanups in the Juju system",
+		Doc:     cleanupsDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *CleanupsCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.retry, "retry", "", "run the cleanup with the given id now")
+	f.StringVar(&c.skip, "skip", "", "remove the cleanup with the given id without running it")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatCleanupsTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *CleanupsCommand) Init(args []string) error {
+	if c.retry != "" && c.skip != "" {
+		return errors.New("cannot specify both --retry and --skip")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *CleanupsCommand) getAPI() (cleanupsAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewCleanupManagerAPIClient()
+}
+
+// CleanupInfo holds the details of a pending cleanup, as displayed
+// by the cleanups command.
+type CleanupInfo struct {
+	Environment string `json:"environment"`
+	Id          string `json:"id"`
+	Kind        string `json:"kind"`
+	Prefix      string `json:"prefix"`
+	Age         string `json:"age"`
+	Attempts    int    `json:"attempts"`
+	LastError   string `json:"last-error,omitempty" yaml:"last-error,omitempty"`
+}
+
+// Run implements Command.Run
+func (c *CleanupsCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	cleanups, err := client.ListCleanups()
+	if err != nil {
+		return errors.Annotate(err, "cannot list cleanups")
+	}
+	if id := c.retry + c.skip; id != "" {
+		cleanup, err := findCleanup(cleanups, id)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tag, err := names.ParseEnvironTag(cleanup.EnvironTag)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if c.retry != "" {
+			if err := client.RetryCleanup(tag.Id(), id); err != nil {
+				return errors.Annotatef(err, "cannot retry cleanup %q", id)
+			}
+			ctx.Infof("cleanup %s completed", id)
+		} else {
+			if err := client.SkipCleanup(tag.Id(), id); err != nil {
+				return errors.Annotatef(err, "cannot skip cleanup %q", id)
+			}
+			ctx.Infof("cleanup %s skipped", id)
+		}
+		return nil
+	}
+
+	output := make([]CleanupInfo, len(cleanups))
+	now := time.Now()
+	for i, cleanup := range cleanups {
+		output[i] = CleanupInfo{
+			Environment: cleanup.EnvironName,
+			Id:          cleanup.Id,
+			Kind:        cleanup.Kind,
+			Prefix:      cleanup.Prefix,
+			Age:         cleanupAge(cleanup.Created, now),
+			Attempts:    cleanup.Attempts,
+			LastError:   cleanup.LastError,
+		}
+	}
+	return c.out.Write(ctx, output)
+}
+
+// findCleanup returns the cleanup with the given id.
+func findCleanup(cleanups []params.CleanupInfo, id string) (params.CleanupInfo, error) {
+	for _, cleanup := range cleanups {
+		if cleanup.Id == id {
+			return cleanup, nil
+		}
+	}
+	return params.CleanupInfo{}, errors.NotFoundf("cleanup %q", id)
+}
+
+// cleanupAge returns how long before now a cleanup was created, to the
+// nearest second.
+func cleanupAge(created, now time.Time) string {
+	if created.IsZero() {
+		return "unknown"
+	}
+	age := now.Sub(created)
+	if age < 0 {
+		age = 0
+	}
+	return (age - age%time.Second).String()
+}
+
+// formatCleanupsTabular takes an interface{} to adhere to the
+// cmd.Formatter interface.
+func formatCleanupsTabular(value interface{}) ([]byte, error) {
+	cleanups, ok := value.([]CleanupInfo)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", cleanups, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "ENVIRONMENT\tID\tKIND\tPREFIX\tAGE\tATTEMPTS\tLAST ERROR\n")
+	for _, cleanup := range cleanups {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			cleanup.Environment, cleanup.Id, cleanup.Kind, cleanup.Prefix,
+			cleanup.Age, cleanup.Attempts, cleanup.LastError)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system_test
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/system"
+	"github.com/juju/juju/testing"
+)
+
+type cleanupsSuite struct {
+	testing.FakeJujuHomeSuite
+	api *fakeCleanupsAPI
+}
+
+var _ = gc.Suite(&cleanupsSuite{})
+
+func (s *cleanupsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+
+	err := envcmd.WriteCurrentSystem("fake")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.api = &fakeCleanupsAPI{
+		cleanups: []params.CleanupInfo{{
+			EnvironTag:  "environment-" + testing.EnvironmentTag.Id(),
+			EnvironName: "test",
+			Id:          "55f6b0e2c8d1f32c54000003",
+			Kind:        "dyingMachine",
+			Prefix:      "3",
+			Created:     time.Now().Add(-90 * time.Second),
+			Attempts:    2,
+			LastError:   "machine 3 has units",
+		}, {
+			EnvironTag:  "environment-" + testing.EnvironmentTag.Id(),
+			EnvironName: "test",
+			Id:          "55f6b0e2c8d1f32c54000004",
+			Kind:        "settings",
+			Prefix:      "r#4#",
+			Created:     time.Now().Add(-10 * time.Second),
+		}},
+	}
+}
+
+func (s *cleanupsSuite) newCommand() cmd.Command {
+	command := system.NewCleanupsCommand(s.api)
+	return envcmd.WrapSystem(command)
+}
+
+func (s *cleanupsSuite) TestList(c *gc.C) {
+	ctx, err := testing.RunCommand(c, s.newCommand())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Matches, ""+
+		"ENVIRONMENT  ID                        KIND          PREFIX  AGE    ATTEMPTS  LAST ERROR\n"+
+		"test         55f6b0e2c8d1f32c54000003  dyingMachine  3       1m3.s  2         machine 3 has units\n"+
+		"test         55f6b0e2c8d1f32c54000004  settings      r#4#    1.s    0         \n"+
+		"\n")
+	c.Assert(s.api.closed, jc.IsTrue)
+}
+
+func (s *cleanupsSuite) TestListYaml(c *gc.C) {
+	s.api.cleanups = s.api.cleanups[:1]
+	ctx, err := testing.RunCommand(c, s.newCommand(), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Matches, ""+
+		"- environment: test\n"+
+		"  id: 55f6b0e2c8d1f32c54000003\n"+
+		"  kind: dyingMachine\n"+
+		`  prefix: "3"\n`+
+		"  age: 1m3.s\n"+
+		"  attempts: 2\n"+
+		"  last-error: machine 3 has units\n")
+}
+
+func (s *cleanupsSuite) TestRetry(c *gc.C) {
+	ctx, err := testing.RunCommand(c, s.newCommand(), "--retry", "55f6b0e2c8d1f32c54000003")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "cleanup 55f6b0e2c8d1f32c54000003 completed\n")
+	c.Assert(s.api.retried, gc.DeepEquals, []string{testing.EnvironmentTag.Id() + ":55f6b0e2c8d1f32c54000003"})
+	c.Assert(s.api.skipped, gc.HasLen, 0)
+}
+
+func (s *cleanupsSuite) TestRetryFails(c *gc.C) {
+	s.api.err = errors.New("machine 3 has units")
+	_, err := testing.RunCommand(c, s.newCommand(), "--retry", "55f6b0e2c8d1f32c54000003")
+	c.Assert(err, gc.ErrorMatches, `cannot retry cleanup "55f6b0e2c8d1f32c54000003": machine 3 has units`)
+}
+
+func (s *cleanupsSuite) TestSkip(c *gc.C) {
+	ctx, err := testing.RunCommand(c, s.newCommand(), "--skip", "55f6b0e2c8d1f32c54000004")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "cleanup 55f6b0e2c8d1f32c54000004 skipped\n")
+	c.Assert(s.api.skipped, gc.DeepEquals, []string{testing.EnvironmentTag.Id() + ":55f6b0e2c8d1f32c54000004"})
+	c.Assert(s.api.retried, gc.HasLen, 0)
+}
+
+func (s *cleanupsSuite) TestUnknownCleanup(c *gc.C) {
+	_, err := testing.RunCommand(c, s.newCommand(), "--skip", "missing")
+	c.Assert(err, gc.ErrorMatches, `cleanup "missing" not found`)
+	c.Assert(s.api.skipped, gc.HasLen, 0)
+}
+
+func (s *cleanupsSuite) TestInitErrors(c *gc.C) {
+	_, err := testing.RunCommand(c, s.newCommand(), "--skip", "a", "--retry", "b")
+	c.Assert(err, gc.ErrorMatches, "cannot specify both --retry and --skip")
+	_, err = testing.RunCommand(c, s.newCommand(), "whoops")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["whoops"\]`)
+}
+
+func (s *cleanupsSuite) TestListError(c *gc.C) {
+	s.api.listErr = errors.New("permission denied")
+	_, err := testing.RunCommand(c, s.newCommand())
+	c.Assert(err, gc.ErrorMatches, "cannot list cleanups: permission denied")
+}
+
+type fakeCleanupsAPI struct {
+	cleanups []params.CleanupInfo
+	listErr  error
+	err      error
+	retried  []string
+	skipped  []string
+	closed   bool
+}
+
+func (f *fakeCleanupsAPI) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeCleanupsAPI) ListCleanups() ([]params.CleanupInfo, error) {
+	return f.cleanups, f.listErr
+}
+
+func (f *fakeCleanupsAPI) RetryCleanup(envUUID, id string) error {
+	f.retried = append(f.retried, envUUID+":"+id)
+	return f.err
+}
+
+func (f *fakeCleanupsAPI) SkipCleanup(envUUID, id string) error {
+	f.skipped = append(f.skipped, envUUID+":"+id)
+	return f.err
+}
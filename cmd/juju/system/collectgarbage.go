@@ -0,0 +1,170 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+// CollectGarbageCommand removes the charm archives and tools that are
+// no longer used by any environment of a system, or with --dry-run
+// reports what would be removed.
+type CollectGarbageCommand struct {
+	envcmd.SysCommandBase
+	out    cmd.Output
+	dryRun bool
+	api    collectGarbageAPI
+}
+
+// collectGarbageAPI defines the methods on the garbage collector API
+// that the collect-garbage command calls.
+type collectGarbageAPI interface {
+	Close() error
+	ListGarbage() ([]params.GarbageInfo, error)
+	CollectGarbage() ([]params.GarbageInfo, error)
+}
+
+var collectGarbageDoc = `
+Remove the charm archives and tools that are no longer used by any
+environment of the Juju system.
+
+Every upgrade-charm and upgrade-juju leaves the previous charm archive
+or tools in the system's storage. A charm is unused once no service or
+unit in its environment refers to it; tools are unused once no agent
+is running them and no environment is configured to upgrade to them.
+The state server removes such items by itself once they have been
+unused for a day. This command removes them immediately.
+
+With --dry-run, nothing is removed; the items that would be removed,
+and their sizes, are listed instead.
+
+Examples:
+
+    juju system collect-garbage --dry-run
+    juju system collect-garbage
+`
+
+// Info implements Command.Info
+func (c *CollectGarbageCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "collect-garbage",
+		Purpose: "remove unused charm archives and tools from the Juju system",
+		Doc:     collectGarbageDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *CollectGarbageCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.dryRun, "dry-run", false, "list what would be removed without removing it")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatGarbageTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *CollectGarbageCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *CollectGarbageCommand) getAPI() (collectGarbageAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewGarbageCollectorAPIClient()
+}
+
+// GarbageInfo holds the details of an unused charm archive or tools
+// tarball, as displayed by the collect-garbage command.
+type GarbageInfo struct {
+	Kind        string `json:"kind"`
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Id          string `json:"id"`
+	Size        int64  `json:"size"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Run implements Command.Run
+func (c *CollectGarbageCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	var garbage []params.GarbageInfo
+	if c.dryRun {
+		garbage, err = client.ListGarbage()
+	} else {
+		garbage, err = client.CollectGarbage()
+	}
+	if err != nil {
+		return errors.Annotate(err, "cannot collect garbage")
+	}
+
+	output := make([]GarbageInfo, len(garbage))
+	var total int64
+	var failed int
+	for i, g := range garbage {
+		output[i] = GarbageInfo{
+			Kind:        g.Kind,
+			Environment: g.EnvironName,
+			Id:          g.Id,
+			Size:        g.Size,
+		}
+		if g.Error != nil {
+			output[i].Error = g.Error.Error()
+			failed++
+			continue
+		}
+		total += g.Size
+	}
+	if err := c.out.Write(ctx, output); err != nil {
+		return err
+	}
+	if c.dryRun {
+		ctx.Infof("%d bytes would be reclaimed", total)
+	} else {
+		ctx.Infof("%d bytes reclaimed", total)
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d items could not be removed", failed, len(garbage))
+	}
+	return nil
+}
+
+// formatGarbageTabular takes an interface{} to adhere to the
+// cmd.Formatter interface.
+func formatGarbageTabular(value interface{}) ([]byte, error) {
+	garbage, ok := value.([]GarbageInfo)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", garbage, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "KIND\tENVIRONMENT\tID\tSIZE\tERROR\n")
+	for _, g := range garbage {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", g.Kind, g.Environment, g.Id, g.Size, g.Error)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
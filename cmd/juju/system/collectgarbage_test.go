@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/system"
+	"github.com/juju/juju/testing"
+)
+
+type collectGarbageSuite struct {
+	testing.FakeJujuHomeSuite
+	api *fakeCollectGarbageAPI
+}
+
+var _ = gc.Suite(&collectGarbageSuite{})
+
+func (s *collectGarbageSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+
+	err := envcmd.WriteCurrentSystem("fake")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.api = &fakeCollectGarbageAPI{
+		garbage: []params.GarbageInfo{{
+			Kind:        "charm",
+			EnvironTag:  "environment-" + testing.EnvironmentTag.Id(),
+			EnvironName: "test",
+			Id:          "cs:trusty/mysql-1",
+			Size:        1000,
+		}, {
+			Kind: "tools",
+			Id:   "1.24.0-trusty-amd64",
+			Size: 20000,
+		}},
+	}
+}
+
+func (s *collectGarbageSuite) newCommand() cmd.Command {
+	command := system.NewCollectGarbageCommand(s.api)
+	return envcmd.WrapSystem(command)
+}
+
+func (s *collectGarbageSuite) TestDryRun(c *gc.C) {
+	ctx, err := testing.RunCommand(c, s.newCommand(), "--dry-run")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"KIND   ENVIRONMENT  ID                   SIZE   ERROR\n"+
+		"charm  test         cs:trusty/mysql-1    1000   \n"+
+		"tools               1.24.0-trusty-amd64  20000  \n"+
+		"\n")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "21000 bytes would be reclaimed\n")
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"ListGarbage"})
+	c.Assert(s.api.closed, jc.IsTrue)
+}
+
+func (s *collectGarbageSuite) TestCollect(c *gc.C) {
+	ctx, err := testing.RunCommand(c, s.newCommand())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "21000 bytes reclaimed\n")
+	c.Assert(s.api.calls, gc.DeepEquals, []string{"CollectGarbage"})
+}
+
+func (s *collectGarbageSuite) TestCollectYaml(c *gc.C) {
+	s.api.garbage = s.api.garbage[:1]
+	ctx, err := testing.RunCommand(c, s.newCommand(), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"- kind: charm\n"+
+		"  environment: test\n"+
+		"  id: cs:trusty/mysql-1\n"+
+		"  size: 1000\n")
+}
+
+func (s *collectGarbageSuite) TestCollectPartialFailure(c *gc.C) {
+	s.api.garbage[1].Error = &params.Error{Message: "blob store unavailable"}
+	ctx, err := testing.RunCommand(c, s.newCommand())
+	c.Assert(err, gc.ErrorMatches, "1 of 2 items could not be removed")
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"KIND   ENVIRONMENT  ID                   SIZE   ERROR\n"+
+		"charm  test         cs:trusty/mysql-1    1000   \n"+
+		"tools               1.24.0-trusty-amd64  20000  blob store unavailable\n"+
+		"\n")
+	c.Assert(testing.Stderr(ctx), gc.Matches, "(?s)1000 bytes reclaimed\n.*")
+}
+
+func (s *collectGarbageSuite) TestCollectError(c *gc.C) {
+	s.api.err = errors.New("permission denied")
+	_, err := testing.RunCommand(c, s.newCommand())
+	c.Assert(err, gc.ErrorMatches, "cannot collect garbage: permission denied")
+}
+
+func (s *collectGarbageSuite) TestInitErrors(c *gc.C) {
+	_, err := testing.RunCommand(c, s.newCommand(), "whoops")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["whoops"\]`)
+}
+
+type fakeCollectGarbageAPI struct {
+	garbage []params.GarbageInfo
+	err     error
+	calls   []string
+	closed  bool
+}
+
+func (f *fakeCollectGarbageAPI) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeCollectGarbageAPI) ListGarbage() ([]params.GarbageInfo, error) {
+	f.calls = append(f.calls, "ListGarbage")
+	return f.garbage, f.err
+}
+
+func (f *fakeCollectGarbageAPI) CollectGarbage() ([]params.GarbageInfo, error) {
+	f.calls = append(f.calls, "CollectGarbage")
+	return f.garbage, f.err
+}
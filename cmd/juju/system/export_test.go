@@ -66,6 +66,31 @@ func NewRemoveBlocksCommand(api removeBlocksAPI) *RemoveBlocksCommand {
 	}
 }
 
+// NewCleanupsCommand returns a CleanupsCommand with the API provided as
+// specified.
+func NewCleanupsCommand(api cleanupsAPI) *CleanupsCommand {
+	return &CleanupsCommand{
+		api: api,
+	}
+}
+
+// NewCollectGarbageCommand returns a CollectGarbageCommand with the API
+// provided as specified.
+func NewCollectGarbageCommand(api collectGarbageAPI) *CollectGarbageCommand {
+	return &CollectGarbageCommand{
+		api: api,
+	}
+}
+
+// NewMigrateCommand returns a MigrateCommand with the API and config
+// store provided as specified.
+func NewMigrateCommand(api migrateAPI, cfgStore configstore.Storage) *MigrateCommand {
+	return &MigrateCommand{
+		api:      api,
+		cfgStore: cfgStore,
+	}
+}
+
 // Name makes the private name attribute accessible for tests.
 func (c *CreateEnvironmentCommand) Name() string {
 	return c.name
@@ -0,0 +1,173 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/systemmanager"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/environs/configstore"
+)
+
+// MigrateCommand starts the migration of an environment from the
+// current system to another one.
+type MigrateCommand struct {
+	envcmd.SysCommandBase
+	api      migrateAPI
+	cfgStore configstore.Storage
+
+	envName      string
+	targetSystem string
+}
+
+// migrateAPI defines the methods on the system manager API that the
+// migrate command calls.
+type migrateAPI interface {
+	Close() error
+	AllEnvironments() ([]base.UserEnvironment, error)
+	InitiateEnvironmentMigration(envUUID string, target systemmanager.MigrationTargetInfo) error
+}
+
+var migrateDoc = `
+Migrate an environment hosted by the current system to another system.
+
+The target system must be one that you have logged in to. The
+environment may be specified by name or by UUID.
+
+The migration is carried out in the background. The environment's model
+is exported and imported into the target system, and the environment's
+agents are then redirected to the target system and told to trust its
+CA certificate. Once all of the agents have connected to the target
+system the migration is complete, and the environment is no longer
+managed by the current system. If any step
+fails, the migration is aborted, the environment is removed from the
+target system, and the agents carry on using the current system.
+
+Examples:
+
+    juju system migrate test new-system
+    juju system migrate cb4b94e8-29bb-44ae-820c-adac21194395 new-system
+
+See Also:
+    juju help juju-systems
+    juju help system login
+`
+
+// Info implements Command.Info
+func (c *MigrateCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "migrate",
+		Args:    "<environment name or UUID> <target system name>",
+		Purpose: "migrate an environment to another system",
+		Doc:     migrateDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *MigrateCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no environment specified")
+	case 1:
+		return errors.New("no target system specified")
+	}
+	c.envName, c.targetSystem = args[0], args[1]
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *MigrateCommand) getAPI() (migrateAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewSystemManagerAPIClient()
+}
+
+func (c *MigrateCommand) getConfigstore() (configstore.Storage, error) {
+	if c.cfgStore != nil {
+		return c.cfgStore, nil
+	}
+	return configstore.Default()
+}
+
+// Run implements Command.Run
+func (c *MigrateCommand) Run(ctx *cmd.Context) error {
+	store, err := c.getConfigstore()
+	if err != nil {
+		return errors.Annotate(err, "failed to get config store")
+	}
+	target, err := c.targetInfo(store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	envUUID, err := c.findEnvironment(client)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := client.InitiateEnvironmentMigration(envUUID, target); err != nil {
+		return errors.Annotate(err, "cannot initiate migration")
+	}
+	ctx.Infof("migration of environment %s to system %q started", envUUID, c.targetSystem)
+	return nil
+}
+
+// targetInfo reads the details of the target system from the config
+// store.
+func (c *MigrateCommand) targetInfo(store configstore.Storage) (systemmanager.MigrationTargetInfo, error) {
+	var target systemmanager.MigrationTargetInfo
+	info, err := store.ReadInfo(c.targetSystem)
+	if errors.IsNotFound(err) {
+		return target, errors.Errorf("system %q not found, use 'juju system login' first", c.targetSystem)
+	} else if err != nil {
+		return target, errors.Annotatef(err, "cannot read details of system %q", c.targetSystem)
+	}
+	endpoint := info.APIEndpoint()
+	creds := info.APICredentials()
+	if len(endpoint.Addresses) == 0 {
+		return target, errors.Errorf("no addresses known for system %q", c.targetSystem)
+	}
+	if !names.IsValidUser(creds.User) {
+		return target, errors.Errorf("invalid user %q for system %q", creds.User, c.targetSystem)
+	}
+	return systemmanager.MigrationTargetInfo{
+		Addrs:    endpoint.Addresses,
+		CACert:   endpoint.CACert,
+		AuthTag:  names.NewUserTag(creds.User),
+		Password: creds.Password,
+	}, nil
+}
+
+// findEnvironment returns the UUID of the environment to migrate.
+func (c *MigrateCommand) findEnvironment(client migrateAPI) (string, error) {
+	if names.IsValidEnvironment(c.envName) {
+		return c.envName, nil
+	}
+	envs, err := client.AllEnvironments()
+	if err != nil {
+		return "", errors.Annotate(err, "cannot list environments")
+	}
+	var matches []string
+	for _, env := range envs {
+		if env.Name == c.envName {
+			matches = append(matches, env.UUID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", errors.NotFoundf("environment %q", c.envName)
+	case 1:
+		return matches[0], nil
+	}
+	return "", errors.Errorf("multiple environments named %q, specify the environment UUID instead", c.envName)
+}
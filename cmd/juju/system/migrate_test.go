@@ -0,0 +1,149 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/systemmanager"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/system"
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/testing"
+)
+
+type migrateSuite struct {
+	testing.FakeJujuHomeSuite
+	api   *fakeMigrateAPI
+	store configstore.Storage
+}
+
+var _ = gc.Suite(&migrateSuite{})
+
+const migrateEnvUUID = "cb4b94e8-29bb-44ae-820c-adac21194395"
+
+func (s *migrateSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+
+	err := envcmd.WriteCurrentSystem("fake")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.api = &fakeMigrateAPI{
+		envs: []base.UserEnvironment{
+			{Name: "test", UUID: migrateEnvUUID, Owner: "admin@local"},
+			{Name: "dup", UUID: "ae673c19-73ef-437f-8224-4842a1772bdf", Owner: "bob@local"},
+			{Name: "dup", UUID: "deadbeef-0bad-400d-8000-4b1d0d06f00d", Owner: "mary@local"},
+		},
+	}
+	s.store = configstore.NewMem()
+	info := s.store.CreateInfo("target")
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses: []string{"10.0.0.1:17070"},
+		CACert:    testing.CACert,
+	})
+	info.SetAPICredentials(configstore.APICredentials{
+		User:     "admin",
+		Password: "secret",
+	})
+	err = info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *migrateSuite) newCommand() cmd.Command {
+	command := system.NewMigrateCommand(s.api, s.store)
+	return envcmd.WrapSystem(command)
+}
+
+func (s *migrateSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no environment specified",
+	}, {
+		args: []string{"test"},
+		err:  "no target system specified",
+	}, {
+		args: []string{"test", "target", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := testing.RunCommand(c, s.newCommand(), test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *migrateSuite) TestMigrateByName(c *gc.C) {
+	ctx, err := testing.RunCommand(c, s.newCommand(), "test", "target")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.envUUID, gc.Equals, migrateEnvUUID)
+	c.Assert(s.api.target, jc.DeepEquals, systemmanager.MigrationTargetInfo{
+		Addrs:    []string{"10.0.0.1:17070"},
+		CACert:   testing.CACert,
+		AuthTag:  names.NewUserTag("admin"),
+		Password: "secret",
+	})
+	c.Assert(testing.Stderr(ctx), gc.Equals,
+		`migration of environment `+migrateEnvUUID+` to system "target" started`+"\n")
+}
+
+func (s *migrateSuite) TestMigrateByUUID(c *gc.C) {
+	_, err := testing.RunCommand(c, s.newCommand(), migrateEnvUUID, "target")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.envUUID, gc.Equals, migrateEnvUUID)
+	c.Assert(s.api.listed, jc.IsFalse)
+}
+
+func (s *migrateSuite) TestMigrateAmbiguousName(c *gc.C) {
+	_, err := testing.RunCommand(c, s.newCommand(), "dup", "target")
+	c.Assert(err, gc.ErrorMatches, `multiple environments named "dup", specify the environment UUID instead`)
+	c.Assert(s.api.envUUID, gc.Equals, "")
+}
+
+func (s *migrateSuite) TestMigrateUnknownEnvironment(c *gc.C) {
+	_, err := testing.RunCommand(c, s.newCommand(), "missing", "target")
+	c.Assert(err, gc.ErrorMatches, `environment "missing" not found`)
+}
+
+func (s *migrateSuite) TestMigrateUnknownSystem(c *gc.C) {
+	_, err := testing.RunCommand(c, s.newCommand(), "test", "nowhere")
+	c.Assert(err, gc.ErrorMatches, `system "nowhere" not found, use 'juju system login' first`)
+}
+
+func (s *migrateSuite) TestMigrateAPIError(c *gc.C) {
+	s.api.err = errors.New("boom")
+	_, err := testing.RunCommand(c, s.newCommand(), "test", "target")
+	c.Assert(err, gc.ErrorMatches, "cannot initiate migration: boom")
+}
+
+type fakeMigrateAPI struct {
+	envs    []base.UserEnvironment
+	listed  bool
+	envUUID string
+	target  systemmanager.MigrationTargetInfo
+	err     error
+}
+
+func (f *fakeMigrateAPI) Close() error {
+	return nil
+}
+
+func (f *fakeMigrateAPI) AllEnvironments() ([]base.UserEnvironment, error) {
+	f.listed = true
+	return f.envs, nil
+}
+
+func (f *fakeMigrateAPI) InitiateEnvironmentMigration(envUUID string, target systemmanager.MigrationTargetInfo) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.envUUID = envUUID
+	f.target = target
+	return nil
+}
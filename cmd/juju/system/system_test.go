@@ -21,6 +21,8 @@ type SystemCommandSuite struct {
 var _ = gc.Suite(&SystemCommandSuite{})
 
 var expectedCommmandNames = []string{
+	"cleanups",
+	"collect-garbage",
 	"create-env", // alias for create-environment
 	"create-environment",
 	"destroy",
@@ -29,6 +31,7 @@ var expectedCommmandNames = []string{
 	"kill",
 	"list",
 	"login",
+	"migrate",
 	"remove-blocks",
 	"use-env", // alias for use-environment
 	"use-environment",
@@ -63,3 +63,57 @@ func NewListCommand(api UserInfoAPI) *ListCommand {
 		},
 	}
 }
+
+// NewUnlockCommand returns an UnlockCommand with the api provided as
+// specified.
+func NewUnlockCommand(api UnlockUserAPI) *UnlockCommand {
+	return &UnlockCommand{
+		api: api,
+	}
+}
+
+// NewAddGroupCommand returns an AddGroupCommand with the api provided as
+// specified.
+func NewAddGroupCommand(api GroupAPI) *AddGroupCommand {
+	return &AddGroupCommand{
+		GroupCommandBase: GroupCommandBase{api: api},
+	}
+}
+
+// NewAddGroupMemberCommand returns an AddGroupMemberCommand with the api
+// provided as specified.
+func NewAddGroupMemberCommand(api GroupAPI) *AddGroupMemberCommand {
+	return &AddGroupMemberCommand{
+		groupMembersBase{GroupCommandBase: GroupCommandBase{api: api}},
+	}
+}
+
+// NewRemoveGroupMemberCommand returns a RemoveGroupMemberCommand with the
+// api provided as specified.
+func NewRemoveGroupMemberCommand(api GroupAPI) *RemoveGroupMemberCommand {
+	return &RemoveGroupMemberCommand{
+		groupMembersBase{GroupCommandBase: GroupCommandBase{api: api}},
+	}
+}
+
+// NewCreateTokenCommand returns a CreateTokenCommand with the api provided
+// as specified.
+func NewCreateTokenCommand(api TokenAPI) *CreateTokenCommand {
+	return &CreateTokenCommand{api: api}
+}
+
+// NewListTokensCommand returns a ListTokensCommand with the api provided
+// as specified.
+func NewListTokensCommand(api TokenAPI) *ListTokensCommand {
+	return &ListTokensCommand{
+		TokenCommandBase: TokenCommandBase{api: api},
+	}
+}
+
+// NewRevokeTokenCommand returns a RevokeTokenCommand with the api provided
+// as specified.
+func NewRevokeTokenCommand(api TokenAPI) *RevokeTokenCommand {
+	return &RevokeTokenCommand{
+		TokenCommandBase: TokenCommandBase{api: api},
+	}
+}
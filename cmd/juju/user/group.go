@@ -0,0 +1,227 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const groupCommandDoc = `
+"juju user group" is used to manage groups of users. Sharing an
+environment with a group gives every member of the group access
+to the environment.
+
+See Also:
+    juju help environment share
+`
+
+const groupCommandPurpose = "manage groups of users"
+
+// NewGroupSuperCommand creates the user group super subcommand and
+// registers the subcommands that it supports.
+func NewGroupSuperCommand() cmd.Command {
+	groupcmd := jujucmd.NewSubSuperCommand(cmd.SuperCommandParams{
+		Name:        "group",
+		Doc:         groupCommandDoc,
+		UsagePrefix: "juju user",
+		Purpose:     groupCommandPurpose,
+	})
+	groupcmd.Register(envcmd.WrapSystem(&AddGroupCommand{}))
+	groupcmd.Register(envcmd.WrapSystem(&AddGroupMemberCommand{}))
+	groupcmd.Register(envcmd.WrapSystem(&RemoveGroupMemberCommand{}))
+	return groupcmd
+}
+
+// GroupAPI defines the usermanager API methods that the group
+// commands use.
+type GroupAPI interface {
+	AddGroup(name string) error
+	AddGroupMembers(group string, users ...names.UserTag) error
+	RemoveGroupMembers(group string, users ...names.UserTag) error
+	Close() error
+}
+
+// GroupCommandBase is a helper base structure for the group commands.
+type GroupCommandBase struct {
+	UserCommandBase
+	api GroupAPI
+}
+
+func (c *GroupCommandBase) getAPI() (GroupAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewUserManagerAPIClient()
+}
+
+const addGroupCommandDoc = `
+Add a group of users with no members. Use "juju user group add-member"
+to add users to the group.
+
+Examples:
+    juju user group add ops
+`
+
+// AddGroupCommand adds a new user group.
+type AddGroupCommand struct {
+	GroupCommandBase
+	Group string
+}
+
+// Info implements Command.Info.
+func (c *AddGroupCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add",
+		Args:    "<group>",
+		Purpose: "adds a group of users",
+		Doc:     addGroupCommandDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *AddGroupCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no group name supplied")
+	}
+	c.Group = args[0]
+	if !names.IsValidUserName(c.Group) {
+		return errors.Errorf("invalid group name %q", c.Group)
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+// Run implements Command.Run.
+func (c *AddGroupCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	if err := client.AddGroup(c.Group); err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("group %q added", c.Group)
+	return nil
+}
+
+const addGroupMemberCommandDoc = `
+Add users to a group. Users that already belong to the group are
+left unchanged. Users from other domains than "local" may be added
+before they have ever logged in.
+
+Examples:
+    juju user group add-member ops bob
+    juju user group add-member ops mary sam@example
+`
+
+const removeGroupMemberCommandDoc = `
+Remove users from a group. Users keep any access to environments
+that has been given to them individually, or through other groups.
+
+Examples:
+    juju user group remove-member ops bob
+`
+
+// groupMembersBase holds the common code for the commands that
+// change the members of a group.
+type groupMembersBase struct {
+	GroupCommandBase
+	Group string
+	Users []names.UserTag
+}
+
+// Init implements Command.Init.
+func (c *groupMembersBase) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no group name supplied")
+	}
+	c.Group, args = args[0], args[1:]
+	if len(args) == 0 {
+		return errors.New("no users supplied")
+	}
+	for _, arg := range args {
+		if !names.IsValidUser(arg) {
+			return errors.Errorf("invalid username: %q", arg)
+		}
+		c.Users = append(c.Users, names.NewUserTag(arg))
+	}
+	return nil
+}
+
+func (c *groupMembersBase) userNames() string {
+	userNames := make([]string, len(c.Users))
+	for i, user := range c.Users {
+		userNames[i] = user.Username()
+	}
+	return strings.Join(userNames, ", ")
+}
+
+// AddGroupMemberCommand adds users to a user group.
+type AddGroupMemberCommand struct {
+	groupMembersBase
+}
+
+// Info implements Command.Info.
+func (c *AddGroupMemberCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add-member",
+		Args:    "<group> <user> ...",
+		Purpose: "adds users to a group",
+		Doc:     addGroupMemberCommandDoc,
+	}
+}
+
+// Run implements Command.Run.
+func (c *AddGroupMemberCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	if err := client.AddGroupMembers(c.Group, c.Users...); err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("added %s to group %q", c.userNames(), c.Group)
+	return nil
+}
+
+// RemoveGroupMemberCommand removes users from a user group.
+type RemoveGroupMemberCommand struct {
+	groupMembersBase
+}
+
+// Info implements Command.Info.
+func (c *RemoveGroupMemberCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "remove-member",
+		Args:    "<group> <user> ...",
+		Purpose: "removes users from a group",
+		Doc:     removeGroupMemberCommandDoc,
+	}
+}
+
+// Run implements Command.Run.
+func (c *RemoveGroupMemberCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	if err := client.RemoveGroupMembers(c.Group, c.Users...); err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("removed %s from group %q", c.userNames(), c.Group)
+	return nil
+}
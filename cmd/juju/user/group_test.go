@@ -0,0 +1,155 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/user"
+	"github.com/juju/juju/testing"
+)
+
+type GroupCommandSuite struct {
+	BaseSuite
+	mock *mockGroupAPI
+}
+
+var _ = gc.Suite(&GroupCommandSuite{})
+
+func (s *GroupCommandSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.mock = &mockGroupAPI{}
+}
+
+func (s *GroupCommandSuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, user.NewGroupSuperCommand(), "--help")
+	c.Assert(err, jc.ErrorIsNil)
+	namesFound := testing.ExtractCommandsFromHelpOutput(ctx)
+	c.Assert(namesFound, gc.DeepEquals, []string{"add", "add-member", "help", "remove-member"})
+}
+
+func (s *GroupCommandSuite) TestAddGroupInit(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		errMatch string
+	}{{
+		errMatch: "no group name supplied",
+	}, {
+		args:     []string{"ops", "dev"},
+		errMatch: `unrecognized args: \["dev"\]`,
+	}, {
+		args:     []string{"ops@local"},
+		errMatch: `invalid group name "ops@local"`,
+	}, {
+		args: []string{"ops"},
+	}} {
+		c.Logf("test %d, args %v", i, test.args)
+		err := testing.InitCommand(&user.AddGroupCommand{}, test.args)
+		if test.errMatch == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *GroupCommandSuite) TestAddGroup(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.WrapSystem(user.NewAddGroupCommand(s.mock)), "ops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.group, gc.Equals, "ops")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "group \"ops\" added\n")
+}
+
+func (s *GroupCommandSuite) TestBlockAddGroup(c *gc.C) {
+	s.mock.err = &params.Error{Code: params.CodeOperationBlocked}
+	_, err := testing.RunCommand(c, envcmd.WrapSystem(user.NewAddGroupCommand(s.mock)), "ops")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
+}
+
+func (s *GroupCommandSuite) TestGroupMemberInit(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		errMatch string
+	}{{
+		errMatch: "no group name supplied",
+	}, {
+		args:     []string{"ops"},
+		errMatch: "no users supplied",
+	}, {
+		args:     []string{"ops", "not valid/0"},
+		errMatch: `invalid username: "not valid/0"`,
+	}, {
+		args: []string{"ops", "bob", "sam@example"},
+	}} {
+		c.Logf("test %d, args %v", i, test.args)
+		err := testing.InitCommand(&user.AddGroupMemberCommand{}, test.args)
+		if test.errMatch == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *GroupCommandSuite) TestAddGroupMember(c *gc.C) {
+	command := envcmd.WrapSystem(user.NewAddGroupMemberCommand(s.mock))
+	ctx, err := testing.RunCommand(c, command, "ops", "bob", "sam@example")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.group, gc.Equals, "ops")
+	c.Assert(s.mock.added, jc.DeepEquals, []names.UserTag{
+		names.NewUserTag("bob"), names.NewUserTag("sam@example"),
+	})
+	c.Assert(testing.Stderr(ctx), gc.Equals, "added bob@local, sam@example to group \"ops\"\n")
+}
+
+func (s *GroupCommandSuite) TestRemoveGroupMember(c *gc.C) {
+	command := envcmd.WrapSystem(user.NewRemoveGroupMemberCommand(s.mock))
+	ctx, err := testing.RunCommand(c, command, "ops", "bob")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.group, gc.Equals, "ops")
+	c.Assert(s.mock.removed, jc.DeepEquals, []names.UserTag{names.NewUserTag("bob")})
+	c.Assert(testing.Stderr(ctx), gc.Equals, "removed bob@local from group \"ops\"\n")
+}
+
+func (s *GroupCommandSuite) TestRemoveGroupMemberError(c *gc.C) {
+	s.mock.err = errors.New(`user "bob@local" in group "ops" not found`)
+	command := envcmd.WrapSystem(user.NewRemoveGroupMemberCommand(s.mock))
+	_, err := testing.RunCommand(c, command, "ops", "bob")
+	c.Assert(err, gc.ErrorMatches, `user "bob@local" in group "ops" not found`)
+}
+
+type mockGroupAPI struct {
+	err     error
+	group   string
+	added   []names.UserTag
+	removed []names.UserTag
+}
+
+func (m *mockGroupAPI) Close() error {
+	return nil
+}
+
+func (m *mockGroupAPI) AddGroup(name string) error {
+	m.group = name
+	return m.err
+}
+
+func (m *mockGroupAPI) AddGroupMembers(group string, users ...names.UserTag) error {
+	m.group = group
+	m.added = users
+	return m.err
+}
+
+func (m *mockGroupAPI) RemoveGroupMembers(group string, users ...names.UserTag) error {
+	m.group = group
+	m.removed = users
+	return m.err
+}
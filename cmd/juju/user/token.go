@@ -0,0 +1,308 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/usermanager"
+	"github.com/juju/juju/apiserver/params"
+	jujucmd "github.com/juju/juju/cmd"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const tokenCommandDoc = `
+"juju user token" is used to manage API tokens. An API token lets you
+log in to a single environment without your password until the token
+expires or is revoked, which makes it suitable for automated clients
+such as CI pipelines.
+
+An API token is used in place of the password when logging in as the
+user that created it.
+
+See Also:
+    juju help user change-password
+`
+
+const tokenCommandPurpose = "manage API tokens"
+
+// NewTokenSuperCommand creates the user token super subcommand and
+// registers the subcommands that it supports.
+func NewTokenSuperCommand() cmd.Command {
+	tokencmd := jujucmd.NewSubSuperCommand(cmd.SuperCommandParams{
+		Name:        "token",
+		Doc:         tokenCommandDoc,
+		UsagePrefix: "juju user",
+		Purpose:     tokenCommandPurpose,
+	})
+	tokencmd.Register(envcmd.Wrap(&CreateTokenCommand{}))
+	tokencmd.Register(envcmd.WrapSystem(&ListTokensCommand{}))
+	tokencmd.Register(envcmd.WrapSystem(&RevokeTokenCommand{}))
+	return tokencmd
+}
+
+// TokenAPI defines the usermanager API methods that the token
+// commands use.
+type TokenAPI interface {
+	AddAPIToken(expiresIn time.Duration) (params.AddAPITokenResult, error)
+	APITokens() ([]params.APITokenInfo, error)
+	RevokeAPITokens(ids ...string) error
+	Close() error
+}
+
+const createTokenCommandDoc = `
+Create an API token for the current user that can be used to log in to
+the environment instead of the password. The token is written to stdout;
+it is not stored anywhere, and cannot be retrieved again later.
+
+The token is only accepted for logins to the environment it was created
+in, and only until it expires.
+
+Examples:
+    juju user token create
+    juju user token create --expires 1h -e ci-env
+`
+
+// CreateTokenCommand creates an API token for the current user.
+type CreateTokenCommand struct {
+	envcmd.EnvCommandBase
+	api     TokenAPI
+	Expires time.Duration
+}
+
+// Info implements Command.Info.
+func (c *CreateTokenCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "create",
+		Purpose: "creates an API token for the environment",
+		Doc:     createTokenCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *CreateTokenCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.DurationVar(&c.Expires, "expires", 24*time.Hour, "how long the token may be used for")
+}
+
+// Init implements Command.Init.
+func (c *CreateTokenCommand) Init(args []string) error {
+	if c.Expires <= 0 {
+		return errors.New("token expiry must be positive")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *CreateTokenCommand) getAPI() (TokenAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return usermanager.NewClient(root), nil
+}
+
+// Run implements Command.Run.
+func (c *CreateTokenCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	result, err := client.AddAPIToken(c.Expires)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("token %s expires at %s", result.Id, result.Expires.Format(time.RFC3339))
+	fmt.Fprintln(ctx.Stdout, result.Credential)
+	return nil
+}
+
+// TokenCommandBase is a helper base structure for the token commands
+// that are not specific to an environment.
+type TokenCommandBase struct {
+	UserCommandBase
+	api TokenAPI
+}
+
+func (c *TokenCommandBase) getAPI() (TokenAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewUserManagerAPIClient()
+}
+
+const listTokensCommandDoc = `
+List the API tokens of the current user in all environments.
+
+Examples:
+    juju user token list
+    juju user token list --format yaml
+`
+
+// ListTokensCommand shows the API tokens of the current user.
+type ListTokensCommand struct {
+	TokenCommandBase
+	exactTime bool
+	out       cmd.Output
+}
+
+// TokenInfo defines the serialization behaviour of the API token
+// information.
+type TokenInfo struct {
+	Id          string `yaml:"id" json:"id"`
+	Environment string `yaml:"environment" json:"environment"`
+	DateCreated string `yaml:"date-created" json:"date-created"`
+	Expires     string `yaml:"expires" json:"expires"`
+	LastUsed    string `yaml:"last-used" json:"last-used"`
+	Expired     bool   `yaml:"expired,omitempty" json:"expired,omitempty"`
+}
+
+// Info implements Command.Info.
+func (c *ListTokensCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list",
+		Purpose: "shows the API tokens of the current user",
+		Doc:     listTokensCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *ListTokensCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.exactTime, "exact-time", false, "use full timestamp precision")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": c.formatTabular,
+	})
+}
+
+// Run implements Command.Run.
+func (c *ListTokensCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	tokens, err := client.APITokens()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return c.out.Write(ctx, c.apiTokensToTokenInfoSlice(tokens))
+}
+
+func (c *ListTokensCommand) apiTokensToTokenInfoSlice(tokens []params.APITokenInfo) []TokenInfo {
+	output := []TokenInfo{}
+	now := time.Now()
+	for _, token := range tokens {
+		outInfo := TokenInfo{
+			Id:          token.Id,
+			Environment: token.Environment,
+			Expires:     token.Expires.Format(time.RFC3339),
+			Expired:     token.Expired,
+			LastUsed:    "never used",
+		}
+		if outInfo.Environment == "" {
+			outInfo.Environment = token.EnvironUUID
+		}
+		if token.LastUsed != nil {
+			outInfo.LastUsed = LastConnection(token.LastUsed, now, c.exactTime)
+		}
+		if c.exactTime {
+			outInfo.DateCreated = token.DateCreated.String()
+		} else {
+			outInfo.DateCreated = UserFriendlyDuration(token.DateCreated, now)
+		}
+		output = append(output, outInfo)
+	}
+	return output
+}
+
+func (c *ListTokensCommand) formatTabular(value interface{}) ([]byte, error) {
+	tokens, valueConverted := value.([]TokenInfo)
+	if !valueConverted {
+		return nil, errors.Errorf("expected value of type %T, got %T", tokens, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "ID\tENVIRONMENT\tDATE CREATED\tEXPIRES\tLAST USED\n")
+	for _, token := range tokens {
+		expires := token.Expires
+		if token.Expired {
+			expires += " (expired)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", token.Id, token.Environment, token.DateCreated, expires, token.LastUsed)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
+
+const revokeTokenCommandDoc = `
+Revoke API tokens so that they can no longer be used to log in. Users
+may revoke their own tokens; the administrator may revoke any token.
+
+Examples:
+    juju user token revoke 3f0c2a9d81b4e657
+`
+
+// RevokeTokenCommand revokes API tokens.
+type RevokeTokenCommand struct {
+	TokenCommandBase
+	Ids []string
+}
+
+// Info implements Command.Info.
+func (c *RevokeTokenCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "revoke",
+		Args:    "<token id> ...",
+		Purpose: "revokes API tokens",
+		Doc:     revokeTokenCommandDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *RevokeTokenCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no token ids supplied")
+	}
+	c.Ids = args
+	return nil
+}
+
+// Run implements Command.Run.
+func (c *RevokeTokenCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	if err := client.RevokeAPITokens(c.Ids...); err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	for _, id := range c.Ids {
+		ctx.Infof("token %s revoked", id)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user_test
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/user"
+	"github.com/juju/juju/testing"
+)
+
+type TokenCommandSuite struct {
+	BaseSuite
+	mock *mockTokenAPI
+}
+
+var _ = gc.Suite(&TokenCommandSuite{})
+
+func (s *TokenCommandSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.mock = &mockTokenAPI{}
+}
+
+func (s *TokenCommandSuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, user.NewTokenSuperCommand(), "--help")
+	c.Assert(err, jc.ErrorIsNil)
+	namesFound := testing.ExtractCommandsFromHelpOutput(ctx)
+	c.Assert(namesFound, gc.DeepEquals, []string{"create", "help", "list", "revoke"})
+}
+
+func (s *TokenCommandSuite) TestCreateTokenInit(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		expires  time.Duration
+		errMatch string
+	}{{
+		expires: 24 * time.Hour,
+	}, {
+		args:    []string{"--expires", "90m"},
+		expires: 90 * time.Minute,
+	}, {
+		args:     []string{"--expires", "0"},
+		errMatch: "token expiry must be positive",
+	}, {
+		args:     []string{"extra"},
+		errMatch: `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d, args %v", i, test.args)
+		command := &user.CreateTokenCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.errMatch == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(command.Expires, gc.Equals, test.expires)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *TokenCommandSuite) TestCreateToken(c *gc.C) {
+	command := envcmd.Wrap(user.NewCreateTokenCommand(s.mock))
+	ctx, err := testing.RunCommand(c, command, "--expires", "1h")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.expiresIn, gc.Equals, time.Hour)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "apitoken:a1b2:s3cret\n")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "token a1b2 expires at 2015-07-01T12:00:00Z\n")
+}
+
+func (s *TokenCommandSuite) TestBlockCreateToken(c *gc.C) {
+	s.mock.err = &params.Error{Code: params.CodeOperationBlocked}
+	_, err := testing.RunCommand(c, envcmd.Wrap(user.NewCreateTokenCommand(s.mock)))
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
+}
+
+func (s *TokenCommandSuite) TestListTokens(c *gc.C) {
+	lastUsed := time.Date(2015, 6, 30, 8, 0, 0, 0, time.UTC)
+	s.mock.tokens = []params.APITokenInfo{{
+		Id:          "a1b2",
+		Environment: "ci",
+		DateCreated: time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Expires:     time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC),
+		LastUsed:    &lastUsed,
+	}, {
+		Id:          "c3d4",
+		EnvironUUID: "deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		DateCreated: time.Date(2015, 5, 1, 12, 0, 0, 0, time.UTC),
+		Expires:     time.Date(2015, 5, 2, 12, 0, 0, 0, time.UTC),
+		Expired:     true,
+	}}
+	command := envcmd.WrapSystem(user.NewListTokensCommand(s.mock))
+	ctx, err := testing.RunCommand(c, command)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"ID    ENVIRONMENT                           DATE CREATED  EXPIRES                         LAST USED\n"+
+		"a1b2  ci                                    2015-06-01    2015-07-01T12:00:00Z            2015-06-30\n"+
+		"c3d4  deadbeef-0bad-400d-8000-4b1d0d06f00d  2015-05-01    2015-05-02T12:00:00Z (expired)  never used\n"+
+		"\n")
+}
+
+func (s *TokenCommandSuite) TestListTokensYaml(c *gc.C) {
+	s.mock.tokens = []params.APITokenInfo{{
+		Id:          "a1b2",
+		Environment: "ci",
+		DateCreated: time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Expires:     time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	command := envcmd.WrapSystem(user.NewListTokensCommand(s.mock))
+	ctx, err := testing.RunCommand(c, command, "--format", "yaml", "--exact-time")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"- id: a1b2\n"+
+		"  environment: ci\n"+
+		"  date-created: 2015-06-01 12:00:00 +0000 UTC\n"+
+		"  expires: 2015-07-01T12:00:00Z\n"+
+		"  last-used: never used\n")
+}
+
+func (s *TokenCommandSuite) TestRevokeTokenInit(c *gc.C) {
+	err := testing.InitCommand(&user.RevokeTokenCommand{}, nil)
+	c.Assert(err, gc.ErrorMatches, "no token ids supplied")
+}
+
+func (s *TokenCommandSuite) TestRevokeToken(c *gc.C) {
+	command := envcmd.WrapSystem(user.NewRevokeTokenCommand(s.mock))
+	ctx, err := testing.RunCommand(c, command, "a1b2", "c3d4")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.revoked, jc.DeepEquals, []string{"a1b2", "c3d4"})
+	c.Assert(testing.Stderr(ctx), gc.Equals, "token a1b2 revoked\ntoken c3d4 revoked\n")
+}
+
+func (s *TokenCommandSuite) TestRevokeTokenError(c *gc.C) {
+	s.mock.err = errors.New(`API token "a1b2" not found`)
+	command := envcmd.WrapSystem(user.NewRevokeTokenCommand(s.mock))
+	_, err := testing.RunCommand(c, command, "a1b2")
+	c.Assert(err, gc.ErrorMatches, `API token "a1b2" not found`)
+}
+
+type mockTokenAPI struct {
+	err       error
+	expiresIn time.Duration
+	tokens    []params.APITokenInfo
+	revoked   []string
+}
+
+func (m *mockTokenAPI) Close() error {
+	return nil
+}
+
+func (m *mockTokenAPI) AddAPIToken(expiresIn time.Duration) (params.AddAPITokenResult, error) {
+	m.expiresIn = expiresIn
+	if m.err != nil {
+		return params.AddAPITokenResult{}, m.err
+	}
+	return params.AddAPITokenResult{
+		Id:         "a1b2",
+		Credential: "apitoken:a1b2:s3cret",
+		Expires:    time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func (m *mockTokenAPI) APITokens() ([]params.APITokenInfo, error) {
+	return m.tokens, m.err
+}
+
+func (m *mockTokenAPI) RevokeAPITokens(ids ...string) error {
+	m.revoked = ids
+	return m.err
+}
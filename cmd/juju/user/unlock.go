@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const unlockUserDoc = `
+A user that has failed to log in too many times in a row is locked out
+for a while, as set by the "login-failure-limit" and "login-lockout"
+environment settings of the state server. Unlocking the user ends the
+lockout immediately. If the user is not locked out, this command succeeds
+silently.
+
+Examples:
+  juju user unlock foobar
+
+See Also:
+  juju help user enable
+`
+
+// UnlockCommand ends the lockout of users after too many failed logins.
+type UnlockCommand struct {
+	UserCommandBase
+	api  UnlockUserAPI
+	User string
+}
+
+// Info implements Command.Info.
+func (c *UnlockCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "unlock",
+		Args:    "<username>",
+		Purpose: "unlocks a user that is locked out after failed logins",
+		Doc:     unlockUserDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *UnlockCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no username supplied")
+	}
+	c.User = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+// UnlockUserAPI defines the API methods that the unlock command uses.
+type UnlockUserAPI interface {
+	UnlockUser(username string) error
+	Close() error
+}
+
+// Run implements Command.Run.
+func (c *UnlockCommand) Run(ctx *cmd.Context) error {
+	if c.api == nil {
+		api, err := c.NewUserManagerAPIClient()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		c.api = api
+		defer c.api.Close()
+	}
+
+	if err := c.api.UnlockUser(c.User); err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("User %q unlocked", c.User)
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/user"
+	"github.com/juju/juju/testing"
+)
+
+type UnlockUserSuite struct {
+	BaseSuite
+	mock *mockUnlockUserAPI
+}
+
+var _ = gc.Suite(&UnlockUserSuite{})
+
+func (s *UnlockUserSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.mock = &mockUnlockUserAPI{}
+}
+
+func (s *UnlockUserSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		errMatch string
+		user     string
+	}{
+		{
+			errMatch: "no username supplied",
+		}, {
+			args:     []string{"username", "extra"},
+			errMatch: `unrecognized args: \["extra"\]`,
+		}, {
+			args: []string{"username"},
+			user: "username",
+		},
+	} {
+		c.Logf("test %d, args %v", i, test.args)
+		command := &user.UnlockCommand{}
+		err := testing.InitCommand(command, test.args)
+		if test.errMatch == "" {
+			c.Assert(err, jc.ErrorIsNil)
+			c.Assert(command.User, gc.Equals, test.user)
+		} else {
+			c.Assert(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *UnlockUserSuite) TestUnlock(c *gc.C) {
+	unlockCommand := envcmd.WrapSystem(user.NewUnlockCommand(s.mock))
+	ctx, err := testing.RunCommand(c, unlockCommand, "foobar")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.unlock, gc.Equals, "foobar")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "User \"foobar\" unlocked\n")
+}
+
+func (s *UnlockUserSuite) TestBlockUnlock(c *gc.C) {
+	s.mock.err = &params.Error{Code: params.CodeOperationBlocked}
+	unlockCommand := envcmd.WrapSystem(user.NewUnlockCommand(s.mock))
+	_, err := testing.RunCommand(c, unlockCommand, "foobar")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
+}
+
+type mockUnlockUserAPI struct {
+	unlock string
+	err    error
+}
+
+var _ user.UnlockUserAPI = (*mockUnlockUserAPI)(nil)
+
+func (m *mockUnlockUserAPI) Close() error {
+	return nil
+}
+
+func (m *mockUnlockUserAPI) UnlockUser(username string) error {
+	m.unlock = username
+	return m.err
+}
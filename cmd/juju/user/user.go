@@ -38,6 +38,9 @@ func NewSuperCommand() cmd.Command {
 	usercmd.Register(envcmd.WrapSystem(&DisableCommand{}))
 	usercmd.Register(envcmd.WrapSystem(&EnableCommand{}))
 	usercmd.Register(envcmd.WrapSystem(&ListCommand{}))
+	usercmd.Register(envcmd.WrapSystem(&UnlockCommand{}))
+	usercmd.Register(NewGroupSuperCommand())
+	usercmd.Register(NewTokenSuperCommand())
 	return usercmd
 }
 
@@ -30,9 +30,12 @@ var expectedUserCommmandNames = []string{
 	"credentials",
 	"disable",
 	"enable",
+	"group",
 	"help",
 	"info",
 	"list",
+	"token",
+	"unlock",
 }
 
 func (s *UserCommandSuite) TestHelp(c *gc.C) {
@@ -62,6 +62,8 @@ type AgentConf interface {
 	CurrentConfig() agent.Config
 	// SetAPIHostPorts satisfies worker/apiaddressupdater/APIAddressSetter.
 	SetAPIHostPorts(servers [][]network.HostPort) error
+	// SetAPIDetails satisfies worker/apiaddressupdater/APIDetailsSetter.
+	SetAPIDetails(servers [][]network.HostPort, caCert string) error
 	// SetStateServingInfo satisfies worker/certupdater/SetStateServingInfo.
 	SetStateServingInfo(info params.StateServingInfo) error
 	// DataDir returns the directory where this agent should store its data.
@@ -146,6 +148,15 @@ func (a *agentConf) SetAPIHostPorts(servers [][]network.HostPort) error {
 	})
 }
 
+// SetAPIDetails satisfies worker/apiaddressupdater/APIDetailsSetter.
+func (a *agentConf) SetAPIDetails(servers [][]network.HostPort, caCert string) error {
+	return a.ChangeConfig(func(c agent.ConfigSetter) error {
+		c.SetAPIHostPorts(servers)
+		c.SetCACert(caCert)
+		return nil
+	})
+}
+
 // SetStateServingInfo satisfies worker/certupdater/SetStateServingInfo.
 func (a *agentConf) SetStateServingInfo(info params.StateServingInfo) error {
 	return a.ChangeConfig(func(c agent.ConfigSetter) error {
@@ -48,6 +48,7 @@ import (
 	"github.com/juju/juju/container/lxc/lxcutils"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/instance"
 	jujunames "github.com/juju/juju/juju/names"
@@ -68,6 +69,7 @@ import (
 	"github.com/juju/juju/worker/addresser"
 	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/authenticationworker"
+	"github.com/juju/juju/worker/blobgc"
 	"github.com/juju/juju/worker/certupdater"
 	"github.com/juju/juju/worker/charmrevisionworker"
 	"github.com/juju/juju/worker/cleaner"
@@ -81,8 +83,10 @@ import (
 	"github.com/juju/juju/worker/localstorage"
 	workerlogger "github.com/juju/juju/worker/logger"
 	"github.com/juju/juju/worker/logsender"
+	"github.com/juju/juju/worker/machineactions"
 	"github.com/juju/juju/worker/machiner"
 	"github.com/juju/juju/worker/metricworker"
+	"github.com/juju/juju/worker/migrationmaster"
 	"github.com/juju/juju/worker/minunitsworker"
 	"github.com/juju/juju/worker/networker"
 	"github.com/juju/juju/worker/peergrouper"
@@ -97,6 +101,7 @@ import (
 	"github.com/juju/juju/worker/terminationworker"
 	"github.com/juju/juju/worker/txnpruner"
 	"github.com/juju/juju/worker/upgrader"
+	"github.com/juju/juju/worker/webhook"
 )
 
 const bootstrapMachineId = "0"
@@ -122,6 +127,7 @@ var (
 	newResumer               = resumer.NewResumer
 	newInstancePoller        = instancepoller.NewWorker
 	newCleaner               = cleaner.NewCleaner
+	newMigrationMaster       = migrationmaster.New
 	reportOpenedState        = func(io.Closer) {}
 	reportOpenedAPI          = func(io.Closer) {}
 	reportClosedMachineAPI   = func(io.Closer) {}
@@ -457,6 +463,7 @@ func (a *MachineAgent) Run(*cmd.Context) error {
 
 	network.InitializeFromConfig(agentConfig)
 	charmrepo.CacheDir = filepath.Join(agentConfig.DataDir(), "charmcache")
+	simplestreams.CacheDir = filepath.Join(agentConfig.DataDir(), "simplestreamscache")
 	if err := a.createJujuRun(agentConfig.DataDir()); err != nil {
 		return fmt.Errorf("cannot create juju run symlink: %v", err)
 	}
@@ -770,6 +777,17 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		}
 		return rebootworker.NewReboot(reboot, agentConfig, lock)
 	})
+	runner.StartWorker("machineactions", func() (worker.Worker, error) {
+		facade, err := st.MachineActions()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		lock, err := cmdutil.HookExecutionLock(cmdutil.DataDir)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return machineactions.NewMachineActionsWorker(facade, lock), nil
+	})
 	runner.StartWorker("apiaddressupdater", func() (worker.Worker, error) {
 		return apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), a.apiAddressSetter), nil
 	})
@@ -1084,6 +1102,10 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 				return txnpruner.New(st, time.Hour*2), nil
 			})
 
+			a.startWorkerAfterUpgrade(singularRunner, "blobgc", func() (worker.Worker, error) {
+				return blobgc.New(st, blobgc.DefaultInterval, blobgc.DefaultGracePeriod), nil
+			})
+
 		case state.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
 		default:
@@ -1156,6 +1178,9 @@ func (a *MachineAgent) startEnvWorkers(
 	singularRunner.StartWorker("addresserworker", func() (worker.Worker, error) {
 		return addresser.NewWorker(st)
 	})
+	singularRunner.StartWorker("webhook", func() (worker.Worker, error) {
+		return webhook.NewWorker(st), nil
+	})
 
 	// Start workers that use an API connection.
 	singularRunner.StartWorker("environ-provisioner", func() (worker.Worker, error) {
@@ -1178,6 +1203,9 @@ func (a *MachineAgent) startEnvWorkers(
 	singularRunner.StartWorker("cleaner", func() (worker.Worker, error) {
 		return newCleaner(apiSt.Cleaner()), nil
 	})
+	singularRunner.StartWorker("migrationmaster", func() (worker.Worker, error) {
+		return newMigrationMaster(apiSt.MigrationMaster(), envUUID, migrationmaster.OpenAPITarget), nil
+	})
 
 	// TODO(axw) 2013-09-24 bug #1229506
 	// Make another job to enable the firewaller. Not all
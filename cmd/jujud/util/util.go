@@ -21,6 +21,7 @@ import (
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/upgrader"
 )
@@ -118,13 +119,17 @@ type Pinger interface {
 
 // ConnectionIsFatal returns a function suitable for passing as the
 // isFatal argument to worker.NewRunner, that diagnoses an error as
-// fatal if the connection has failed or if the error is otherwise
-// fatal.
+// fatal if the connection has failed, if the API servers the
+// connection was made to have been replaced, or if the error is
+// otherwise fatal.
 func ConnectionIsFatal(logger loggo.Logger, conns ...Pinger) func(err error) bool {
 	return func(err error) bool {
 		if IsFatal(err) {
 			return true
 		}
+		if errors.Cause(err) == apiaddressupdater.ErrServersReplaced {
+			return true
+		}
 		for _, conn := range conns {
 			if ConnectionIsDead(logger, conn) {
 				return true
@@ -13,6 +13,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/upgrader"
 )
 
@@ -123,6 +124,15 @@ func (s *toolSuite) TestConnectionIsFatalWithMultipleConns(c *gc.C) {
 		jc.IsTrue)
 }
 
+func (s *toolSuite) TestConnectionIsFatalServersReplaced(c *gc.C) {
+	var okPinger testPinger = func() error {
+		return nil
+	}
+	err := errors.Annotate(apiaddressupdater.ErrServersReplaced, "apiaddressupdater")
+	c.Assert(ConnectionIsFatal(logger, okPinger)(err), jc.IsTrue)
+	c.Assert(IsFatal(err), jc.IsFalse)
+}
+
 func (*toolSuite) TestIsFatal(c *gc.C) {
 
 	for i, test := range isFatalTests {
@@ -31,6 +31,7 @@ var metadataCommandNames = []string{
 	"generate-image",
 	"generate-tools",
 	"help",
+	"mirror",
 	"sign",
 	"validate-images",
 	"validate-tools",
@@ -91,6 +92,10 @@ func (s *MetadataSuite) TestHelpValidateTools(c *gc.C) {
 	s.assertHelpOutput(c, "validate-tools")
 }
 
+func (s *MetadataSuite) TestHelpMirror(c *gc.C) {
+	s.assertHelpOutput(c, "mirror")
+}
+
 func (s *MetadataSuite) TestHelpGenerateImage(c *gc.C) {
 	s.assertHelpOutput(c, "generate-image")
 }
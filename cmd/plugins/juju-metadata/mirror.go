@@ -0,0 +1,207 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/loggo"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/environs/sync"
+	"github.com/juju/juju/version"
+)
+
+var (
+	mirrorTools  = sync.MirrorTools
+	verifyMirror = sync.VerifyMirror
+)
+
+var mirrorDoc = `
+mirror builds and maintains a local simplestreams mirror of juju tools for use
+in environments without internet access. Image metadata is not mirrored; see
+below.
+
+Tools matching the selection are fetched from the source (by default the public
+tools location) into the directory given with -d, and simplestreams index,
+product and mirror files describing them are written. If a signing key is
+given with -k, the metadata files are also inline signed.
+
+Running the command again against the same directory only fetches tools which
+are not already present, so the mirror can be updated incrementally.
+
+Only tools are mirrored. Image metadata is deliberately not handled: it
+describes images that already live in each cloud rather than files that can
+be copied, so it has to be generated against the target cloud with
+"juju metadata generate-image" and checked with
+"juju metadata validate-images".
+
+With --verify, nothing is fetched; instead every tools tarball described by the
+mirror metadata is checked against its recorded size and SHA-256 hash. If a
+public key is given with --public-key, the signatures of the metadata files are
+checked as well.
+
+Examples:
+
+  - mirror all released 1.24 tools for trusty on amd64:
+
+   juju metadata mirror -d <mirrordir> --version 1.24 --series trusty --arch amd64
+
+  - mirror specific proposed versions and sign the metadata:
+
+   juju metadata mirror -d <mirrordir> --stream proposed --versions 1.24.6,1.24.7 -k <keyfile>
+
+  - verify an existing mirror:
+
+   juju metadata mirror -d <mirrordir> --verify --public-key <keyfile>
+`
+
+// MirrorCommand is used to build, update and verify a local
+// simplestreams tools mirror.
+type MirrorCommand struct {
+	cmd.CommandBase
+	dir           string
+	source        string
+	stream        string
+	versionStr    string
+	versionsStr   string
+	seriesStr     string
+	archStr       string
+	keyFile       string
+	passphrase    string
+	publicKeyFile string
+	verify        bool
+	dryRun        bool
+
+	majorVersion int
+	minorVersion int
+	versions     []version.Number
+}
+
+func (c *MirrorCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "mirror",
+		Purpose: "build, update or verify a local simplestreams tools mirror",
+		Doc:     mirrorDoc,
+	}
+}
+
+func (c *MirrorCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.CommandBase.SetFlags(f)
+	f.StringVar(&c.dir, "d", "", "local directory in which the mirror is kept")
+	f.StringVar(&c.source, "source", "", "local source directory or URL (defaults to the public tools location)")
+	f.StringVar(&c.stream, "stream", "", "simplestreams stream to mirror (defaults to released)")
+	f.StringVar(&c.versionStr, "version", "", "mirror tools with a specific major[.minor] version")
+	f.StringVar(&c.versionsStr, "versions", "", "comma separated list of exact tools versions to mirror")
+	f.StringVar(&c.seriesStr, "series", "", "comma separated list of series to mirror")
+	f.StringVar(&c.archStr, "arch", "", "comma separated list of architectures to mirror")
+	f.StringVar(&c.keyFile, "k", "", "file containing the armored private key used to sign the metadata")
+	f.StringVar(&c.passphrase, "p", "", "passphrase used to decrypt the private key")
+	f.StringVar(&c.publicKeyFile, "public-key", "", "file containing the armored public key used to verify signatures")
+	f.BoolVar(&c.verify, "verify", false, "verify the mirror instead of updating it")
+	f.BoolVar(&c.dryRun, "dry-run", false, "don't copy, just print what would be copied")
+}
+
+func (c *MirrorCommand) Init(args []string) error {
+	if c.dir == "" {
+		return fmt.Errorf("directory must be specified")
+	}
+	if c.verify && (c.keyFile != "" || c.dryRun) {
+		return fmt.Errorf("--verify cannot be combined with -k or --dry-run")
+	}
+	if !c.verify && c.publicKeyFile != "" {
+		return fmt.Errorf("--public-key may only be used with --verify")
+	}
+	c.minorVersion = -1
+	if c.versionStr != "" {
+		var err error
+		if c.majorVersion, c.minorVersion, err = version.ParseMajorMinor(c.versionStr); err != nil {
+			return err
+		}
+	}
+	for _, vers := range splitList(c.versionsStr) {
+		num, err := version.Parse(vers)
+		if err != nil {
+			return err
+		}
+		c.versions = append(c.versions, num)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// splitList splits a comma separated list, ignoring empty elements.
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (c *MirrorCommand) Run(context *cmd.Context) error {
+	loggo.RegisterWriter("mirror", cmd.NewCommandLogWriter("juju.environs.sync", context.Stdout, context.Stderr), loggo.INFO)
+	defer loggo.RemoveWriter("mirror")
+	dir := context.AbsPath(c.dir)
+	if c.verify {
+		return c.runVerify(context, dir)
+	}
+
+	mirrorContext := &sync.MirrorContext{
+		Target:       dir,
+		Source:       c.source,
+		Stream:       c.stream,
+		MajorVersion: c.majorVersion,
+		MinorVersion: c.minorVersion,
+		Versions:     c.versions,
+		Series:       splitList(c.seriesStr),
+		Arches:       splitList(c.archStr),
+		Passphrase:   c.passphrase,
+		DryRun:       c.dryRun,
+	}
+	if c.keyFile != "" {
+		keyData, err := ioutil.ReadFile(context.AbsPath(c.keyFile))
+		if err != nil {
+			return err
+		}
+		mirrorContext.SigningKey = string(keyData)
+	}
+	copied, err := mirrorTools(mirrorContext)
+	if err != nil {
+		return err
+	}
+	if c.dryRun {
+		fmt.Fprintf(context.Stdout, "%d tools would be copied to %s.\n", len(copied), dir)
+	} else {
+		fmt.Fprintf(context.Stdout, "%d tools copied to %s.\n", len(copied), dir)
+	}
+	return nil
+}
+
+func (c *MirrorCommand) runVerify(context *cmd.Context, dir string) error {
+	var publicKey string
+	if c.publicKeyFile != "" {
+		keyData, err := ioutil.ReadFile(context.AbsPath(c.publicKeyFile))
+		if err != nil {
+			return err
+		}
+		publicKey = string(keyData)
+	}
+	problems, err := verifyMirror(dir, c.stream, publicKey)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(context.Stdout, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("mirror verification failed with %d problem(s)", len(problems))
+	}
+	fmt.Fprintf(context.Stdout, "Mirror in %s verified.\n", dir)
+	return nil
+}
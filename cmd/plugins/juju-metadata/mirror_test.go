@@ -0,0 +1,120 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/sync"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+type MirrorSuite struct {
+	coretesting.FakeJujuHomeSuite
+}
+
+var _ = gc.Suite(&MirrorSuite{})
+
+func (s *MirrorSuite) TearDownTest(c *gc.C) {
+	loggo.ResetLoggers()
+	s.FakeJujuHomeSuite.TearDownTest(c)
+}
+
+func (s *MirrorSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: nil,
+		err:  "directory must be specified",
+	}, {
+		args: []string{"-d", "dir", "--verify", "--dry-run"},
+		err:  "--verify cannot be combined with -k or --dry-run",
+	}, {
+		args: []string{"-d", "dir", "--public-key", "key"},
+		err:  "--public-key may only be used with --verify",
+	}, {
+		args: []string{"-d", "dir", "--versions", "1.2.x"},
+		err:  `invalid version "1.2.x"`,
+	}, {
+		args: []string{"-d", "dir", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(&MirrorCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *MirrorSuite) TestMirror(c *gc.C) {
+	dir := c.MkDir()
+	keyFile := filepath.Join(dir, "key.asc")
+	err := ioutil.WriteFile(keyFile, []byte("private key"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var called *sync.MirrorContext
+	s.PatchValue(&mirrorTools, func(ctx *sync.MirrorContext) (coretools.List, error) {
+		called = ctx
+		return coretools.List{{Version: version.MustParseBinary("1.24.6-trusty-amd64")}}, nil
+	})
+	ctx, err := coretesting.RunCommand(c, &MirrorCommand{},
+		"-d", filepath.Join(dir, "mirror"), "--version", "1.24", "--series", "trusty,precise",
+		"--arch", "amd64", "--versions", "1.24.6", "-k", keyFile, "-p", "secret",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Matches, "1 tools copied to .*mirror.\n")
+	c.Assert(called, jc.DeepEquals, &sync.MirrorContext{
+		Target:       filepath.Join(dir, "mirror"),
+		MajorVersion: 1,
+		MinorVersion: 24,
+		Versions:     []version.Number{version.MustParse("1.24.6")},
+		Series:       []string{"trusty", "precise"},
+		Arches:       []string{"amd64"},
+		SigningKey:   "private key",
+		Passphrase:   "secret",
+	})
+}
+
+func (s *MirrorSuite) TestMirrorDefaults(c *gc.C) {
+	var called *sync.MirrorContext
+	s.PatchValue(&mirrorTools, func(ctx *sync.MirrorContext) (coretools.List, error) {
+		called = ctx
+		return nil, nil
+	})
+	_, err := coretesting.RunCommand(c, &MirrorCommand{}, "-d", "/mirror", "--dry-run")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.DeepEquals, &sync.MirrorContext{
+		Target:       "/mirror",
+		MinorVersion: -1,
+		DryRun:       true,
+	})
+}
+
+func (s *MirrorSuite) TestVerify(c *gc.C) {
+	s.PatchValue(&verifyMirror, func(dir, stream, publicKey string) ([]string, error) {
+		c.Assert(dir, gc.Equals, "/mirror")
+		c.Assert(stream, gc.Equals, "proposed")
+		c.Assert(publicKey, gc.Equals, "")
+		return nil, nil
+	})
+	ctx, err := coretesting.RunCommand(c, &MirrorCommand{}, "-d", "/mirror", "--verify", "--stream", "proposed")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "Mirror in /mirror verified.\n")
+}
+
+func (s *MirrorSuite) TestVerifyProblems(c *gc.C) {
+	s.PatchValue(&verifyMirror, func(dir, stream, publicKey string) ([]string, error) {
+		return []string{"tools/released/juju-1.24.6-trusty-amd64.tgz: missing"}, nil
+	})
+	ctx, err := coretesting.RunCommand(c, &MirrorCommand{}, "-d", "/mirror", "--verify")
+	c.Assert(err, gc.ErrorMatches, `mirror verification failed with 1 problem\(s\)`)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "tools/released/juju-1.24.6-trusty-amd64.tgz: missing\n")
+}
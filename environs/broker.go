@@ -46,6 +46,13 @@ type StartInstanceParams struct {
 	// for attachment to the instance being started.
 	Volumes []storage.VolumeParams
 
+	// AvailabilityZone, if non-empty, is the availability zone in
+	// which the instance must be started, because existing volumes
+	// that are to be attached to the instance reside there. It takes
+	// precedence over the distribution of instances across zones.
+	// Providers without availability zones ignore it.
+	AvailabilityZone string
+
 	// NetworkInfo is an optional list of network interface details,
 	// necessary to configure on the instance.
 	NetworkInfo []network.InterfaceInfo
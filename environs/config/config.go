@@ -6,6 +6,7 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -22,6 +23,7 @@ import (
 	"gopkg.in/juju/environschema.v1"
 
 	"github.com/juju/juju/cert"
+	"github.com/juju/juju/charmsig"
 	"github.com/juju/juju/environs/tags"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/version"
@@ -95,6 +97,14 @@ const (
 	// config setting. Only non-zero, positive integer values will
 	// have effect.
 	DefaultLXCDefaultMTU = 0
+
+	// DefaultLoginFailureLimit is the default value for the
+	// "login-failure-limit" config setting.
+	DefaultLoginFailureLimit = 5
+
+	// DefaultLoginLockout is the default value for the "login-lockout"
+	// config setting.
+	DefaultLoginLockout = time.Minute
 )
 
 // TODO(katco-): Please grow this over time.
@@ -171,6 +181,70 @@ const (
 	// interfaces created for LXC containers. See also bug #1442257.
 	LXCDefaultMTU = "lxc-default-mtu"
 
+	// LDAPURLKey, when set, holds the ldap:// or ldaps:// URL of a
+	// directory server against which users in the LDAP domain are
+	// authenticated. Passwords are sent to ldap:// servers in the
+	// clear.
+	LDAPURLKey = "ldap-url"
+
+	// LDAPDomainKey holds the Juju user domain of directory users;
+	// "bob@example" is authenticated against the directory if this
+	// is "example".
+	LDAPDomainKey = "ldap-domain"
+
+	// LDAPUserDNKey holds the template for the distinguished name of
+	// a directory user, in which "%s" is replaced by the user name.
+	LDAPUserDNKey = "ldap-user-dn"
+
+	// LDAPGroupBaseKey holds the distinguished name under which the
+	// groups of directory users are found.
+	LDAPGroupBaseKey = "ldap-group-base"
+
+	// LDAPAccessGroupsKey holds a comma-separated list of directory
+	// groups whose members are granted access to the environment the
+	// first time they log in. The access is revoked when they next
+	// log in after leaving the groups.
+	LDAPAccessGroupsKey = "ldap-access-groups"
+
+	// PasswordMinLengthKey holds the minimum length of the passwords
+	// of local users.
+	PasswordMinLengthKey = "password-min-length"
+
+	// PasswordComplexityKey, when true, requires the passwords of
+	// local users to contain upper and lower case letters and digits.
+	PasswordComplexityKey = "password-complexity"
+
+	// LoginFailureLimitKey holds the number of consecutive failed
+	// logins after which a local user is locked out. Zero disables
+	// lockout.
+	LoginFailureLimitKey = "login-failure-limit"
+
+	// LoginLockoutKey holds how long a local user is first locked out
+	// for; each further lockout before a successful login doubles it.
+	LoginLockoutKey = "login-lockout"
+
+	// TrustedCharmKeysKey, when set, holds an armored OpenPGP key ring
+	// of the public keys trusted to sign charms. Charms must then be
+	// signed by one of these keys before they can be deployed.
+	TrustedCharmKeysKey = "trusted-charm-keys"
+
+	// WebhookURLsKey holds a comma-separated list of http:// or
+	// https:// URLs to which environment events are posted.
+	WebhookURLsKey = "webhook-urls"
+
+	// WebhookSecretKey, when set, holds the secret with which the
+	// events posted to webhooks are signed.
+	WebhookSecretKey = "webhook-secret"
+
+	// WebhookEventsKey holds a comma-separated list of the types of
+	// event posted to webhooks. If it is empty, all events are posted.
+	WebhookEventsKey = "webhook-events"
+
+	// QuotaPreflightKey determines what happens when a request for
+	// new machines would exceed the remaining provider quotas: one
+	// of "warn" (the default), "refuse" or "off".
+	QuotaPreflightKey = "quota-preflight"
+
 	//
 	// Deprecated Settings Attributes
 	//
@@ -605,6 +679,50 @@ func Validate(cfg, old *Config) error {
 		return errors.Annotate(err, "validating resource tags")
 	}
 
+	// Directory users can only be authenticated if we know which
+	// users are directory users, and where to find them.
+	if _, ok := cfg.LDAPURL(); ok {
+		for _, attr := range []string{LDAPDomainKey, LDAPUserDNKey} {
+			if cfg.asString(attr) == "" {
+				return errors.Errorf("%s requires %s to be set", LDAPURLKey, attr)
+			}
+		}
+	}
+
+	if cfg.PasswordMinLength() < 0 {
+		return errors.Errorf("%s: expected non-negative integer, got %d", PasswordMinLengthKey, cfg.PasswordMinLength())
+	}
+	if limit := cfg.LoginFailureLimit(); limit < 0 {
+		return errors.Errorf("%s: expected non-negative integer, got %d", LoginFailureLimitKey, limit)
+	}
+	if v, ok := cfg.defined[LoginLockoutKey].(string); ok {
+		if lockout, err := time.ParseDuration(v); err != nil {
+			return errors.Annotatef(err, "invalid %s", LoginLockoutKey)
+		} else if lockout <= 0 {
+			return errors.Errorf("%s: expected positive duration, got %q", LoginLockoutKey, v)
+		}
+	}
+
+	if keys, ok := cfg.TrustedCharmKeys(); ok {
+		if _, err := charmsig.ParseKeyRing(keys); err != nil {
+			return errors.Annotatef(err, "invalid %s", TrustedCharmKeysKey)
+		}
+	}
+
+	for _, webhookURL := range cfg.WebhookURLs() {
+		u, err := url.Parse(webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("%s: expected http or https URL, got %q", WebhookURLsKey, webhookURL)
+		}
+	}
+
+	switch mode := cfg.QuotaPreflight(); mode {
+	case QuotaPreflightWarn, QuotaPreflightRefuse, QuotaPreflightOff:
+	default:
+		return errors.Errorf("%s: expected %q, %q or %q, got %q",
+			QuotaPreflightKey, QuotaPreflightWarn, QuotaPreflightRefuse, QuotaPreflightOff, mode)
+	}
+
 	// Check the immutable config values.  These can't change
 	if old != nil {
 		for _, attr := range immutableAttributes {
@@ -1163,6 +1281,135 @@ func (c *Config) AllowLXCLoopMounts() (bool, bool) {
 	return v, ok
 }
 
+// LDAPURL returns the URL of the LDAP directory against which users
+// are authenticated, and whether it has been set.
+func (c *Config) LDAPURL() (string, bool) {
+	url := c.asString(LDAPURLKey)
+	return url, url != ""
+}
+
+// LDAPDomain returns the Juju user domain of LDAP directory users.
+func (c *Config) LDAPDomain() string {
+	return c.asString(LDAPDomainKey)
+}
+
+// LDAPUserDN returns the template for the distinguished name of an
+// LDAP directory user.
+func (c *Config) LDAPUserDN() string {
+	return c.asString(LDAPUserDNKey)
+}
+
+// LDAPGroupBase returns the distinguished name under which the groups
+// of LDAP directory users are found.
+func (c *Config) LDAPGroupBase() string {
+	return c.asString(LDAPGroupBaseKey)
+}
+
+// LDAPAccessGroups returns the LDAP groups whose members are granted
+// access to the environment.
+func (c *Config) LDAPAccessGroups() []string {
+	return c.asList(LDAPAccessGroupsKey)
+}
+
+// PasswordMinLength returns the minimum length of the passwords of
+// local users.
+func (c *Config) PasswordMinLength() int {
+	v, _ := c.defined[PasswordMinLengthKey].(int)
+	return v
+}
+
+// PasswordComplexity reports whether the passwords of local users must
+// contain upper and lower case letters and digits.
+func (c *Config) PasswordComplexity() bool {
+	v, _ := c.defined[PasswordComplexityKey].(bool)
+	return v
+}
+
+// LoginFailureLimit returns the number of consecutive failed logins
+// after which a local user is locked out, or zero if users are never
+// locked out.
+func (c *Config) LoginFailureLimit() int {
+	v, ok := c.defined[LoginFailureLimitKey].(int)
+	if !ok {
+		return DefaultLoginFailureLimit
+	}
+	return v
+}
+
+// LoginLockout returns how long a local user is first locked out for
+// after too many failed logins.
+func (c *Config) LoginLockout() time.Duration {
+	v, ok := c.defined[LoginLockoutKey].(string)
+	if !ok {
+		return DefaultLoginLockout
+	}
+	lockout, err := time.ParseDuration(v)
+	if err != nil {
+		// This should never happen as we validate the config.
+		return DefaultLoginLockout
+	}
+	return lockout
+}
+
+// TrustedCharmKeys returns the armored key ring of the public keys
+// trusted to sign charms, and whether it has been set.
+func (c *Config) TrustedCharmKeys() (string, bool) {
+	v, ok := c.defined[TrustedCharmKeysKey].(string)
+	return v, ok && v != ""
+}
+
+// WebhookURLs returns the URLs to which environment events are posted.
+func (c *Config) WebhookURLs() []string {
+	return c.asList(WebhookURLsKey)
+}
+
+// WebhookSecret returns the secret with which the events posted to
+// webhooks are signed, and whether it has been set.
+func (c *Config) WebhookSecret() (string, bool) {
+	secret := c.asString(WebhookSecretKey)
+	return secret, secret != ""
+}
+
+// WebhookEvents returns the types of event posted to webhooks. If it
+// is empty, all events are posted.
+func (c *Config) WebhookEvents() []string {
+	return c.asList(WebhookEventsKey)
+}
+
+// The values of the quota-preflight setting.
+const (
+	// QuotaPreflightWarn causes a warning to be shown when a
+	// request for new machines would exceed the remaining quotas.
+	QuotaPreflightWarn = "warn"
+
+	// QuotaPreflightRefuse causes such requests to be refused.
+	QuotaPreflightRefuse = "refuse"
+
+	// QuotaPreflightOff disables the quota preflight check.
+	QuotaPreflightOff = "off"
+)
+
+// QuotaPreflight returns what happens when a request for new machines
+// would exceed the remaining provider quotas.
+func (c *Config) QuotaPreflight() string {
+	if mode := c.asString(QuotaPreflightKey); mode != "" {
+		return mode
+	}
+	return QuotaPreflightWarn
+}
+
+// asList returns the comma-separated list held in the
+// given attribute, with empty elements removed.
+func (c *Config) asList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(c.asString(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // ResourceTags returns a set of tags to set on environment resources
 // that Juju creates and manages, if the provider supports them. These
 // tags have no special meaning to Juju, but may be used for existing
@@ -1274,6 +1521,20 @@ var alwaysOptional = schema.Defaults{
 	SetNumaControlPolicyKey:      DefaultNumaControlPolicy,
 	AllowLXCLoopMounts:           false,
 	ResourceTagsKey:              schema.Omit,
+	LDAPURLKey:                   schema.Omit,
+	LDAPDomainKey:                schema.Omit,
+	LDAPUserDNKey:                schema.Omit,
+	LDAPGroupBaseKey:             schema.Omit,
+	LDAPAccessGroupsKey:          schema.Omit,
+	PasswordMinLengthKey:         schema.Omit,
+	PasswordComplexityKey:        schema.Omit,
+	LoginFailureLimitKey:         schema.Omit,
+	LoginLockoutKey:              schema.Omit,
+	TrustedCharmKeysKey:          schema.Omit,
+	WebhookURLsKey:               schema.Omit,
+	WebhookSecretKey:             schema.Omit,
+	WebhookEventsKey:             schema.Omit,
+	QuotaPreflightKey:            schema.Omit,
 
 	// Storage related config.
 	// Environ providers will specify their own defaults.
@@ -1711,6 +1972,41 @@ global or per instance security groups.`,
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	LDAPAccessGroupsKey: {
+		Description: "Comma-separated list of LDAP groups whose members may access the environment",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPDomainKey: {
+		Description: "The Juju user domain of users authenticated against the LDAP directory",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPGroupBaseKey: {
+		Description: "The distinguished name under which LDAP groups are found",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPURLKey: {
+		Description: "The ldap:// or ldaps:// URL of the LDAP directory used to authenticate users; ldap:// sends passwords in the clear",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPUserDNKey: {
+		Description: `Template for the distinguished name of an LDAP user, in which "%s" is replaced by the user name`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LoginFailureLimitKey: {
+		Description: "The number of consecutive failed logins after which a local user is locked out; 0 disables lockout",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	LoginLockoutKey: {
+		Description: `How long a local user is first locked out for after too many failed logins (e.g. "5m"); further lockouts double this`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
 	"logging-config": {
 		Description: `The configuration string to use when configuring Juju agent logging (see http://godoc.org/github.com/juju/loggo#ParseConfigurationString for details)`,
 		Type:        environschema.Tstring,
@@ -1751,6 +2047,16 @@ global or per instance security groups.`,
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	PasswordComplexityKey: {
+		Description: "Whether the passwords of local users must contain upper and lower case letters and digits",
+		Type:        environschema.Tbool,
+		Group:       environschema.EnvironGroup,
+	},
+	PasswordMinLengthKey: {
+		Description: "The minimum length of the passwords of local users",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
 	"prefer-ipv6": {
 		Description: `Whether to prefer IPv6 over IPv4 addresses for API endpoints and machines`,
 		Type:        environschema.Tbool,
@@ -1834,6 +2140,11 @@ data of the store. (default false)`,
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	TrustedCharmKeysKey: {
+		Description: "Armored OpenPGP public keys trusted to sign charms; when set, only charms signed by one of them can be deployed",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
 	"type": {
 		Description: "Type of environment, e.g. local, ec2",
 		Type:        environschema.Tstring,
@@ -1847,4 +2158,25 @@ data of the store. (default false)`,
 		Group:       environschema.JujuGroup,
 		Immutable:   true,
 	},
+	QuotaPreflightKey: {
+		Description: "What happens when new machines would exceed the remaining provider quotas: warn, refuse or off",
+		Type:        environschema.Tstring,
+		Values:      []interface{}{QuotaPreflightWarn, QuotaPreflightRefuse, QuotaPreflightOff},
+		Group:       environschema.EnvironGroup,
+	},
+	WebhookEventsKey: {
+		Description: "Comma-separated list of the types of event posted to webhooks; all events are posted if empty",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	WebhookSecretKey: {
+		Description: "The secret with which events posted to webhooks are signed",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	WebhookURLsKey: {
+		Description: "Comma-separated list of http:// or https:// URLs to which environment events are posted",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
 }
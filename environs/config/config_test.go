@@ -21,6 +21,7 @@ import (
 	"gopkg.in/juju/environschema.v1"
 
 	"github.com/juju/juju/cert"
+	charmsigtesting "github.com/juju/juju/charmsig/testing"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/testing"
@@ -987,6 +988,151 @@ var configTests = []configTest{
 		},
 		err: `resource-tags: expected "key=value", got "a"`,
 	},
+	{
+		about:       "LDAP authentication",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":               "my-type",
+			"name":               "my-name",
+			"ldap-url":           "ldap://ldap.example.com",
+			"ldap-domain":        "example",
+			"ldap-user-dn":       "uid=%s,ou=people,dc=example,dc=com",
+			"ldap-group-base":    "ou=groups,dc=example,dc=com",
+			"ldap-access-groups": "ops, dev",
+		},
+	},
+	{
+		about:       "LDAP URL without domain",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"ldap-url":     "ldap://ldap.example.com",
+			"ldap-user-dn": "uid=%s,ou=people,dc=example,dc=com",
+		},
+		err: `ldap-url requires ldap-domain to be set`,
+	},
+	{
+		about:       "LDAP URL without user DN",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":        "my-type",
+			"name":        "my-name",
+			"ldap-url":    "ldap://ldap.example.com",
+			"ldap-domain": "example",
+		},
+		err: `ldap-url requires ldap-user-dn to be set`,
+	},
+	{
+		about:       "Password policy",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"password-min-length": 12,
+			"password-complexity": true,
+		},
+	},
+	{
+		about:       "Negative password minimum length",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"password-min-length": -1,
+		},
+		err: `password-min-length: expected non-negative integer, got -1`,
+	},
+	{
+		about:       "Login lockout",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"login-failure-limit": 3,
+			"login-lockout":       "5m",
+		},
+	},
+	{
+		about:       "Negative login failure limit",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"login-failure-limit": -1,
+		},
+		err: `login-failure-limit: expected non-negative integer, got -1`,
+	},
+	{
+		about:       "Invalid login lockout",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":          "my-type",
+			"name":          "my-name",
+			"login-lockout": "forever",
+		},
+		err: `invalid login-lockout: time: invalid duration "?forever"?`,
+	},
+	{
+		about:       "Zero login lockout",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":          "my-type",
+			"name":          "my-name",
+			"login-lockout": "0s",
+		},
+		err: `login-lockout: expected positive duration, got "0s"`,
+	},
+	{
+		about:       "Invalid trusted charm keys",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":               "my-type",
+			"name":               "my-name",
+			"trusted-charm-keys": "not a key",
+		},
+		err: `invalid trusted-charm-keys: cannot parse trusted charm keys: .*`,
+	},
+	{
+		about:       "Webhooks",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":           "my-type",
+			"name":           "my-name",
+			"webhook-urls":   "https://hooks.example.com/juju, http://10.0.0.1:8080/",
+			"webhook-secret": "sekrit",
+			"webhook-events": "unit-error,action-failed",
+		},
+	},
+	{
+		about:       "Invalid webhook URL",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"webhook-urls": "https://hooks.example.com/juju,ftp://example.com",
+		},
+		err: `webhook-urls: expected http or https URL, got "ftp://example.com"`,
+	},
+	{
+		about:       "Quota preflight",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"quota-preflight": "refuse",
+		},
+	},
+	{
+		about:       "Invalid quota preflight",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"quota-preflight": "maybe",
+		},
+		err: `quota-preflight: expected "warn", "refuse" or "off", got "maybe"`,
+	},
 }
 
 func missingAttributeNoDefault(attrName string) configTest {
@@ -1377,6 +1523,16 @@ func (test configTest) check(c *gc.C, home *gitjujutesting.FakeHome) {
 	} else {
 		c.Assert(cfgHasResourceTags, jc.IsFalse)
 	}
+
+	ldapURL, cfgHasLDAP := cfg.LDAPURL()
+	if v, ok := test.attrs["ldap-url"]; ok {
+		c.Assert(cfgHasLDAP, jc.IsTrue)
+		c.Assert(ldapURL, gc.Equals, v)
+		c.Assert(cfg.LDAPDomain(), gc.Equals, test.attrs["ldap-domain"])
+		c.Assert(cfg.LDAPUserDN(), gc.Equals, test.attrs["ldap-user-dn"])
+	} else {
+		c.Assert(cfgHasLDAP, jc.IsFalse)
+	}
 }
 
 func (test configTest) assertDuration(c *gc.C, name string, actual time.Duration, defaultInSeconds int) {
@@ -1679,6 +1835,74 @@ func newTestConfig(c *gc.C, explicit testing.Attrs) *config.Config {
 	return result
 }
 
+func (s *ConfigSuite) TestLDAPAccessGroups(c *gc.C) {
+	cfg := newTestConfig(c, testing.Attrs{
+		"ldap-access-groups": " ops,, dev ,",
+	})
+	c.Assert(cfg.LDAPAccessGroups(), jc.DeepEquals, []string{"ops", "dev"})
+
+	cfg = newTestConfig(c, nil)
+	c.Assert(cfg.LDAPAccessGroups(), gc.HasLen, 0)
+}
+
+func (s *ConfigSuite) TestUserPolicyDefaults(c *gc.C) {
+	cfg := newTestConfig(c, nil)
+	c.Assert(cfg.PasswordMinLength(), gc.Equals, 0)
+	c.Assert(cfg.PasswordComplexity(), jc.IsFalse)
+	c.Assert(cfg.LoginFailureLimit(), gc.Equals, config.DefaultLoginFailureLimit)
+	c.Assert(cfg.LoginLockout(), gc.Equals, config.DefaultLoginLockout)
+
+	cfg = newTestConfig(c, testing.Attrs{
+		"password-min-length": 10,
+		"password-complexity": true,
+		"login-failure-limit": 0,
+		"login-lockout":       "90s",
+	})
+	c.Assert(cfg.PasswordMinLength(), gc.Equals, 10)
+	c.Assert(cfg.PasswordComplexity(), jc.IsTrue)
+	c.Assert(cfg.LoginFailureLimit(), gc.Equals, 0)
+	c.Assert(cfg.LoginLockout(), gc.Equals, 90*time.Second)
+}
+
+func (s *ConfigSuite) TestTrustedCharmKeys(c *gc.C) {
+	cfg := newTestConfig(c, nil)
+	_, ok := cfg.TrustedCharmKeys()
+	c.Assert(ok, jc.IsFalse)
+
+	key := charmsigtesting.NewKey(c, "signer")
+	cfg = newTestConfig(c, testing.Attrs{"trusted-charm-keys": key.Public})
+	keys, ok := cfg.TrustedCharmKeys()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(keys, gc.Equals, key.Public)
+}
+
+func (s *ConfigSuite) TestWebhooks(c *gc.C) {
+	cfg := newTestConfig(c, nil)
+	c.Assert(cfg.WebhookURLs(), gc.HasLen, 0)
+	c.Assert(cfg.WebhookEvents(), gc.HasLen, 0)
+	_, ok := cfg.WebhookSecret()
+	c.Assert(ok, jc.IsFalse)
+
+	cfg = newTestConfig(c, testing.Attrs{
+		"webhook-urls":   "https://a.example.com/, http://b.example.com/hook",
+		"webhook-secret": "sekrit",
+		"webhook-events": "unit-error, ,action-failed",
+	})
+	c.Assert(cfg.WebhookURLs(), jc.DeepEquals, []string{"https://a.example.com/", "http://b.example.com/hook"})
+	c.Assert(cfg.WebhookEvents(), jc.DeepEquals, []string{"unit-error", "action-failed"})
+	secret, ok := cfg.WebhookSecret()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(secret, gc.Equals, "sekrit")
+}
+
+func (s *ConfigSuite) TestQuotaPreflight(c *gc.C) {
+	cfg := newTestConfig(c, nil)
+	c.Assert(cfg.QuotaPreflight(), gc.Equals, config.QuotaPreflightWarn)
+
+	cfg = newTestConfig(c, testing.Attrs{"quota-preflight": "off"})
+	c.Assert(cfg.QuotaPreflight(), gc.Equals, config.QuotaPreflightOff)
+}
+
 func (s *ConfigSuite) TestLoggingConfig(c *gc.C) {
 	s.addJujuFiles(c)
 	config := newTestConfig(c, testing.Attrs{
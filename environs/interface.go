@@ -200,6 +200,8 @@ type Environ interface {
 // InstanceTagger is an interface that can be used for tagging instances.
 type InstanceTagger interface {
 	// TagInstance tags the given instance with the specified tags.
+	// Where the provider can, the volumes attached to the instance
+	// are tagged too.
 	//
 	// The specified tags will replace any existing ones with the
 	// same names, but other existing tags will be left alone.
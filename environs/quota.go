@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"fmt"
+)
+
+// QuotaReporter is an interface that can be implemented by
+// environments that are able to report the remaining provider
+// quotas, so that requests for new machines can be checked
+// before they fail part way through.
+type QuotaReporter interface {
+	// Quotas returns the number of each kind of resource that
+	// may still be created before the provider quotas are
+	// exceeded.
+	Quotas() (Quotas, error)
+}
+
+// Quotas holds the number of each kind of resource that may still be
+// created in an environment. A nil value means that the quota is
+// unlimited, or unknown to the provider.
+type Quotas struct {
+	Instances   *int
+	Cores       *int
+	Volumes     *int
+	IPAddresses *int
+}
+
+// QuotaRequest holds the number of each kind of resource that a
+// request for new machines requires.
+type QuotaRequest struct {
+	Instances   int
+	Cores       int
+	Volumes     int
+	IPAddresses int
+}
+
+// Exceeded returns a description of each quota that the given
+// request would exceed.
+func (q Quotas) Exceeded(req QuotaRequest) []string {
+	var exceeded []string
+	check := func(what string, remaining *int, requested int) {
+		if remaining == nil || requested <= *remaining {
+			return
+		}
+		available := *remaining
+		if available < 0 {
+			available = 0
+		}
+		exceeded = append(exceeded, fmt.Sprintf(
+			"%d %s requested, %d remaining in quota", requested, what, available,
+		))
+	}
+	check("instances", q.Instances, req.Instances)
+	check("cores", q.Cores, req.Cores)
+	check("volumes", q.Volumes, req.Volumes)
+	check("IP addresses", q.IPAddresses, req.IPAddresses)
+	return exceeded
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type QuotaSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&QuotaSuite{})
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func (s *QuotaSuite) TestExceededUnlimited(c *gc.C) {
+	exceeded := environs.Quotas{}.Exceeded(environs.QuotaRequest{
+		Instances:   100,
+		Cores:       400,
+		Volumes:     10,
+		IPAddresses: 100,
+	})
+	c.Assert(exceeded, gc.HasLen, 0)
+}
+
+func (s *QuotaSuite) TestExceededWithinQuota(c *gc.C) {
+	quotas := environs.Quotas{
+		Instances: intPtr(3),
+		Cores:     intPtr(6),
+	}
+	exceeded := quotas.Exceeded(environs.QuotaRequest{Instances: 3, Cores: 6})
+	c.Assert(exceeded, gc.HasLen, 0)
+}
+
+func (s *QuotaSuite) TestExceeded(c *gc.C) {
+	quotas := environs.Quotas{
+		Instances:   intPtr(2),
+		Cores:       intPtr(8),
+		Volumes:     intPtr(-1),
+		IPAddresses: intPtr(0),
+	}
+	exceeded := quotas.Exceeded(environs.QuotaRequest{
+		Instances:   3,
+		Cores:       6,
+		Volumes:     1,
+		IPAddresses: 3,
+	})
+	c.Assert(exceeded, jc.DeepEquals, []string{
+		"3 instances requested, 2 remaining in quota",
+		"1 volumes requested, 0 remaining in quota",
+		"3 IP addresses requested, 0 remaining in quota",
+	})
+}
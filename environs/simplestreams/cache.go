@@ -0,0 +1,182 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package simplestreams
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// CacheDir holds the directory in which data fetched by caching
+// datasources is kept. If it is empty, caching is disabled and
+// NewCachingURLDataSource returns a plain URL datasource.
+var CacheDir string
+
+// cacheEntry records the validators of a cached file, used to
+// revalidate it with the server.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last-modified,omitempty"`
+}
+
+// A cachingDataSource retrieves data from an HTTP URL, keeping a copy
+// of each file on disk. Cached files are revalidated using ETag and
+// If-Modified-Since headers, and are served as is if the server cannot
+// be reached.
+type cachingDataSource struct {
+	*urlDataSource
+	cacheDir string
+}
+
+// NewCachingURLDataSource returns a new datasource reading from the
+// specified baseURL which caches the data it fetches in CacheDir.
+// Only http and https URLs are cached.
+func NewCachingURLDataSource(description, baseURL string, hostnameVerification utils.SSLHostnameVerification) DataSource {
+	source := &urlDataSource{
+		description:          description,
+		baseURL:              baseURL,
+		hostnameVerification: hostnameVerification,
+	}
+	if CacheDir == "" {
+		return source
+	}
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return source
+	}
+	return &cachingDataSource{
+		urlDataSource: source,
+		cacheDir:      CacheDir,
+	}
+}
+
+func (c *cachingDataSource) GoString() string {
+	return fmt.Sprintf("%v: cachingDataSource(%q)", c.description, c.baseURL)
+}
+
+// cachePaths returns the paths of the data and entry files used to
+// cache the content of dataURL.
+func (c *cachingDataSource) cachePaths(dataURL string) (dataPath, entryPath string) {
+	name := fmt.Sprintf("%x", sha256.Sum256([]byte(dataURL)))
+	return filepath.Join(c.cacheDir, name), filepath.Join(c.cacheDir, name+".entry")
+}
+
+// readCache returns the cached data and validators for dataURL, or
+// nil if nothing is cached.
+func (c *cachingDataSource) readCache(dataURL string) ([]byte, *cacheEntry) {
+	dataPath, entryPath := c.cachePaths(dataURL)
+	entryData, err := ioutil.ReadFile(entryPath)
+	if err != nil {
+		return nil, nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(entryData, &entry); err != nil || entry.URL != dataURL {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(dataPath)
+	if err != nil {
+		return nil, nil
+	}
+	return data, &entry
+}
+
+// writeCache stores data and its validators for dataURL.
+func (c *cachingDataSource) writeCache(dataURL string, data []byte, entry *cacheEntry) error {
+	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dataPath, entryPath := c.cachePaths(dataURL)
+	if err := utils.AtomicWriteFile(dataPath, data, 0644); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(utils.AtomicWriteFile(entryPath, entryData, 0644))
+}
+
+// removeCache discards anything cached for dataURL.
+func (c *cachingDataSource) removeCache(dataURL string) {
+	dataPath, entryPath := c.cachePaths(dataURL)
+	os.Remove(entryPath)
+	os.Remove(dataPath)
+}
+
+// Fetch is defined in simplestreams.DataSource.
+func (c *cachingDataSource) Fetch(path string) (io.ReadCloser, string, error) {
+	dataURL := urlJoin(c.baseURL, path)
+	cached, entry := c.readCache(dataURL)
+	serveStale := func(reason interface{}) (io.ReadCloser, string, error) {
+		logger.Warningf("cannot refresh %q (%v), using cached copy", dataURL, reason)
+		return ioutil.NopCloser(bytes.NewReader(cached)), dataURL, nil
+	}
+
+	req, err := http.NewRequest("GET", dataURL, nil)
+	if err != nil {
+		return nil, dataURL, errors.NotFoundf("invalid URL %q", dataURL)
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	client := utils.GetHTTPClient(c.hostnameVerification)
+	resp, err := client.Do(req)
+	if err != nil {
+		if cached != nil {
+			return serveStale(err)
+		}
+		logger.Tracef("Got error requesting %q: %v", dataURL, err)
+		return nil, dataURL, errors.NotFoundf("invalid URL %q", dataURL)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		logger.Tracef("using cached copy of %q", dataURL)
+		return ioutil.NopCloser(bytes.NewReader(cached)), dataURL, nil
+	case resp.StatusCode == http.StatusNotFound:
+		c.removeCache(dataURL)
+		return nil, dataURL, errors.NotFoundf("cannot find URL %q", dataURL)
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, dataURL, errors.Unauthorizedf("unauthorised access to URL %q", dataURL)
+	case resp.StatusCode != http.StatusOK:
+		if cached != nil {
+			return serveStale(resp.Status)
+		}
+		return nil, dataURL, fmt.Errorf("cannot access URL %q, %q", dataURL, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		if cached != nil {
+			return serveStale(err)
+		}
+		return nil, dataURL, errors.Annotatef(err, "cannot read URL %q", dataURL)
+	}
+	newEntry := &cacheEntry{
+		URL:          dataURL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if err := c.writeCache(dataURL, data, newEntry); err != nil {
+		logger.Warningf("cannot cache %q: %v", dataURL, err)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), dataURL, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package simplestreams_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/simplestreams"
+	coretesting "github.com/juju/juju/testing"
+)
+
+var _ = gc.Suite(&cachingDataSourceSuite{})
+
+type cachingDataSourceSuite struct {
+	coretesting.BaseSuite
+	server *httptest.Server
+
+	mu       sync.Mutex
+	content  string
+	status   int
+	requests []*http.Request
+}
+
+func (s *cachingDataSourceSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.content = "hello"
+	s.status = http.StatusOK
+	s.requests = nil
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.PatchValue(&simplestreams.CacheDir, c.MkDir())
+}
+
+func (s *cachingDataSourceSuite) TearDownTest(c *gc.C) {
+	s.server.Close()
+	s.BaseSuite.TearDownTest(c)
+}
+
+func (s *cachingDataSourceSuite) serve(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	if s.status != http.StatusOK {
+		w.WriteHeader(s.status)
+		return
+	}
+	etag := `"` + s.content + `"`
+	if req.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Write([]byte(s.content))
+}
+
+func (s *cachingDataSourceSuite) set(content string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = content
+	s.status = status
+}
+
+func (s *cachingDataSourceSuite) assertFetch(c *gc.C, ds simplestreams.DataSource, expected string) {
+	rc, url, err := ds.Fetch("streams/v1/index.json")
+	c.Assert(err, jc.ErrorIsNil)
+	defer rc.Close()
+	c.Assert(url, gc.Equals, s.server.URL+"/streams/v1/index.json")
+	data, err := ioutil.ReadAll(rc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, expected)
+}
+
+func (s *cachingDataSourceSuite) TestRevalidatesWithETag(c *gc.C) {
+	ds := simplestreams.NewCachingURLDataSource("test", s.server.URL, utils.VerifySSLHostnames)
+	s.assertFetch(c, ds, "hello")
+	s.assertFetch(c, ds, "hello")
+	c.Assert(s.requests, gc.HasLen, 2)
+	c.Assert(s.requests[0].Header.Get("If-None-Match"), gc.Equals, "")
+	c.Assert(s.requests[1].Header.Get("If-None-Match"), gc.Equals, `"hello"`)
+}
+
+func (s *cachingDataSourceSuite) TestRefreshesChangedContent(c *gc.C) {
+	ds := simplestreams.NewCachingURLDataSource("test", s.server.URL, utils.VerifySSLHostnames)
+	s.assertFetch(c, ds, "hello")
+	s.set("goodbye", http.StatusOK)
+	s.assertFetch(c, ds, "goodbye")
+}
+
+func (s *cachingDataSourceSuite) TestServesStaleOnServerError(c *gc.C) {
+	ds := simplestreams.NewCachingURLDataSource("test", s.server.URL, utils.VerifySSLHostnames)
+	s.assertFetch(c, ds, "hello")
+	s.set("", http.StatusInternalServerError)
+	s.assertFetch(c, ds, "hello")
+}
+
+func (s *cachingDataSourceSuite) TestServesStaleOnNetworkFailure(c *gc.C) {
+	ds := simplestreams.NewCachingURLDataSource("test", s.server.URL, utils.VerifySSLHostnames)
+	s.assertFetch(c, ds, "hello")
+	s.server.Close()
+	s.assertFetch(c, ds, "hello")
+}
+
+func (s *cachingDataSourceSuite) TestServerErrorWithoutCache(c *gc.C) {
+	s.set("", http.StatusInternalServerError)
+	ds := simplestreams.NewCachingURLDataSource("test", s.server.URL, utils.VerifySSLHostnames)
+	_, _, err := ds.Fetch("streams/v1/index.json")
+	c.Assert(err, gc.ErrorMatches, `cannot access URL ".*/streams/v1/index.json", "500 Internal Server Error"`)
+}
+
+func (s *cachingDataSourceSuite) TestNotFoundDiscardsCache(c *gc.C) {
+	ds := simplestreams.NewCachingURLDataSource("test", s.server.URL, utils.VerifySSLHostnames)
+	s.assertFetch(c, ds, "hello")
+	s.set("", http.StatusNotFound)
+	_, _, err := ds.Fetch("streams/v1/index.json")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	s.server.Close()
+	_, _, err = ds.Fetch("streams/v1/index.json")
+	c.Assert(err, gc.ErrorMatches, `invalid URL ".*/streams/v1/index.json" not found`)
+}
+
+func (s *cachingDataSourceSuite) TestCachingDisabled(c *gc.C) {
+	s.PatchValue(&simplestreams.CacheDir, "")
+	ds := simplestreams.NewCachingURLDataSource("test", s.server.URL, utils.VerifySSLHostnames)
+	s.assertFetch(c, ds, "hello")
+	s.server.Close()
+	_, _, err := ds.Fetch("streams/v1/index.json")
+	c.Assert(err, gc.ErrorMatches, `invalid URL ".*/streams/v1/index.json" not found`)
+}
@@ -0,0 +1,295 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/storage"
+	envtools "github.com/juju/juju/environs/tools"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+// MirrorContext describes the context for building or updating a
+// local simplestreams tools mirror.
+type MirrorContext struct {
+	// Target is the local directory in which the mirror is kept.
+	Target string
+
+	// Source, if non-empty, specifies a directory in the local file
+	// system or a URL to use as a source. It defaults to the public
+	// tools location.
+	Source string
+
+	// Stream specifies the simplestreams stream to mirror (defaults
+	// to "released").
+	Stream string
+
+	// MajorVersion and MinorVersion restrict the tools that are
+	// mirrored. If MajorVersion is zero, the current major version
+	// is used; if MinorVersion is negative, all minor versions are
+	// mirrored.
+	MajorVersion int
+	MinorVersion int
+
+	// Versions, if not empty, restricts the tools mirrored to those
+	// with the given version numbers.
+	Versions []version.Number
+
+	// Series, if not empty, restricts the tools mirrored to those
+	// for the given series.
+	Series []string
+
+	// Arches, if not empty, restricts the tools mirrored to those
+	// for the given architectures.
+	Arches []string
+
+	// SigningKey, if non-empty, holds an armored private key used to
+	// sign the index, product and mirror files written to the mirror.
+	SigningKey string
+
+	// Passphrase is used to decrypt SigningKey if it is encrypted.
+	Passphrase string
+
+	// DryRun controls that nothing is copied. Instead it's logged
+	// what would be copied.
+	DryRun bool
+}
+
+// MirrorTools fetches the tools selected by mirrorContext from the
+// source into the target directory, and writes simplestreams metadata
+// (including mirror information) describing them. Tools already
+// present in the target are not fetched again, so MirrorTools may be
+// used to incrementally update an existing mirror. The tools which
+// were copied are returned.
+func MirrorTools(mirrorContext *MirrorContext) (coretools.List, error) {
+	if mirrorContext.Target == "" {
+		return nil, errors.New("mirror target directory must be specified")
+	}
+	stream := mirrorContext.Stream
+	if stream == "" {
+		stream = envtools.ReleasedStream
+	}
+	major := mirrorContext.MajorVersion
+	minor := mirrorContext.MinorVersion
+	if major == 0 {
+		major = version.Current.Major
+		minor = -1
+	}
+
+	sourceDataSource, err := selectSourceDatasource(&SyncContext{Source: mirrorContext.Source})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	logger.Infof("listing available tools in stream %q", stream)
+	sourceTools, err := envtools.FindToolsForCloud(
+		[]simplestreams.DataSource{sourceDataSource}, simplestreams.CloudSpec{},
+		stream, major, minor, coretools.Filter{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sourceTools = filterMirrorTools(sourceTools, mirrorContext)
+	if len(sourceTools) == 0 {
+		return nil, errors.NotFoundf("tools matching the mirror selection")
+	}
+	logger.Infof("found %d tools to mirror", len(sourceTools))
+
+	if err := os.MkdirAll(mirrorContext.Target, 0755); err != nil {
+		return nil, errors.Trace(err)
+	}
+	targetStorage, err := filestorage.NewFileStorageWriter(mirrorContext.Target)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	targetTools, err := envtools.ReadList(targetStorage, stream, major, minor)
+	switch err {
+	case nil, coretools.ErrNoMatches, envtools.ErrNoTools:
+	default:
+		return nil, errors.Trace(err)
+	}
+	missing := sourceTools.Exclude(targetTools)
+	logger.Infof("found %d tools in mirror; %d tools to be copied", len(targetTools), len(missing))
+	if mirrorContext.DryRun {
+		for _, tools := range missing {
+			logger.Infof("copying %s from %s", tools.Version, tools.URL)
+		}
+		return missing, nil
+	}
+
+	uploader := StorageToolsUploader{Storage: targetStorage}
+	if err := copyTools(stream, stream, missing, uploader); err != nil {
+		return nil, errors.Trace(err)
+	}
+	logger.Infof("copied %d tools", len(missing))
+
+	if err := writeMirrorMetadata(targetStorage, stream, sourceTools); err != nil {
+		return nil, errors.Annotate(err, "cannot write mirror metadata")
+	}
+	if mirrorContext.SigningKey != "" {
+		metadataDir := filepath.Join(mirrorContext.Target, storage.BaseToolsPath, "streams", "v1")
+		err := signMetadataFiles(metadataDir, mirrorContext.SigningKey, mirrorContext.Passphrase)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot sign mirror metadata")
+		}
+	}
+	return missing, nil
+}
+
+// writeMirrorMetadata merges metadata for the given tools with any
+// existing metadata in the mirror, computing the size and hash of any
+// tools for which the source did not publish them, and writes it out
+// along with the mirrors file.
+func writeMirrorMetadata(stor storage.Storage, stream string, toolsList coretools.List) error {
+	existing, err := envtools.ReadAllMetadata(stor)
+	if err != nil {
+		return err
+	}
+	metadata := envtools.MetadataFromTools(toolsList, stream)
+	if metadata, err = envtools.MergeMetadata(metadata, existing[stream]); err != nil {
+		return err
+	}
+	if err := envtools.ResolveMetadata(stor, stream, metadata); err != nil {
+		return err
+	}
+	existing[stream] = metadata
+	return envtools.WriteMetadata(stor, existing, []string{stream}, envtools.WriteMirrors)
+}
+
+// filterMirrorTools returns the tools in src which match the version,
+// series and architecture selection in mirrorContext.
+func filterMirrorTools(src coretools.List, mirrorContext *MirrorContext) coretools.List {
+	var result coretools.List
+	for _, tools := range src {
+		if len(mirrorContext.Versions) > 0 && !containsVersion(mirrorContext.Versions, tools.Version.Number) {
+			continue
+		}
+		if len(mirrorContext.Series) > 0 && !containsString(mirrorContext.Series, tools.Version.Series) {
+			continue
+		}
+		if len(mirrorContext.Arches) > 0 && !containsString(mirrorContext.Arches, tools.Version.Arch) {
+			continue
+		}
+		result = append(result, tools)
+	}
+	return result
+}
+
+func containsVersion(versions []version.Number, v version.Number) bool {
+	for _, candidate := range versions {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, s string) bool {
+	for _, candidate := range values {
+		if candidate == s {
+			return true
+		}
+	}
+	return false
+}
+
+// signMetadataFiles inline signs every unsigned metadata file in dir,
+// writing the result alongside it with the signed suffix.
+func signMetadataFiles(dir, key, passphrase string) error {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*"+simplestreams.UnsignedSuffix))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, filename := range filenames {
+		logger.Infof("signing file %q", filename)
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		encoded, err := simplestreams.Encode(bytes.NewReader(data), key, passphrase)
+		if err != nil {
+			return errors.Annotatef(err, "encoding file %q", filename)
+		}
+		signedFilename := strings.TrimSuffix(filename, simplestreams.UnsignedSuffix) + simplestreams.SignedSuffix
+		if err := ioutil.WriteFile(signedFilename, encoded, 0644); err != nil {
+			return errors.Annotatef(err, "writing signed file %q", signedFilename)
+		}
+	}
+	return nil
+}
+
+// VerifyMirror checks the tools mirror in dir for the given stream.
+// Every tools tarball described by the metadata must be present with
+// the recorded size and SHA-256 hash. If publicKey is non-empty, the
+// signed metadata files must also carry a valid signature made with
+// the corresponding private key. A description of each problem found
+// is returned; an empty result means the mirror is consistent.
+func VerifyMirror(dir, stream, publicKey string) ([]string, error) {
+	if stream == "" {
+		stream = envtools.ReleasedStream
+	}
+	stor, err := filestorage.NewFileStorageReader(dir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	metadata, err := envtools.ReadMetadata(stor, stream)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(metadata) == 0 {
+		return nil, errors.NotFoundf("tools metadata for stream %q in %q", stream, dir)
+	}
+	var problems []string
+	for _, md := range metadata {
+		name := path.Join(storage.BaseToolsPath, md.Path)
+		r, err := stor.Get(name)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: missing", name))
+			continue
+		}
+		hash, size, err := utils.ReadSHA256(r)
+		r.Close()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if size != md.Size {
+			problems = append(problems, fmt.Sprintf("%s: size is %d, expected %d", name, size, md.Size))
+		} else if hash != md.SHA256 {
+			problems = append(problems, fmt.Sprintf("%s: SHA-256 hash is %s, expected %s", name, hash, md.SHA256))
+		}
+	}
+	if publicKey == "" {
+		return problems, nil
+	}
+	metadataDir := filepath.Join(dir, storage.BaseToolsPath, "streams", "v1")
+	filenames, err := filepath.Glob(filepath.Join(metadataDir, "*"+simplestreams.UnsignedSuffix))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, filename := range filenames {
+		signedFilename := strings.TrimSuffix(filename, simplestreams.UnsignedSuffix) + simplestreams.SignedSuffix
+		signedData, err := ioutil.ReadFile(signedFilename)
+		if os.IsNotExist(err) {
+			problems = append(problems, fmt.Sprintf("%s: not signed", filename))
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, err := simplestreams.DecodeCheckSignature(bytes.NewReader(signedData), publicKey); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid signature: %v", signedFilename, err))
+		}
+	}
+	return problems, nil
+}
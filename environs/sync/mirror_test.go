@@ -0,0 +1,166 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sync_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/filestorage"
+	sstesting "github.com/juju/juju/environs/simplestreams/testing"
+	"github.com/juju/juju/environs/storage"
+	"github.com/juju/juju/environs/sync"
+	envtesting "github.com/juju/juju/environs/testing"
+	envtools "github.com/juju/juju/environs/tools"
+	toolstesting "github.com/juju/juju/environs/tools/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type mirrorSuite struct {
+	coretesting.FakeJujuHomeSuite
+	envtesting.ToolsFixture
+	sourceDir string
+	targetDir string
+}
+
+var _ = gc.Suite(&mirrorSuite{})
+
+func (s *mirrorSuite) SetUpTest(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("issue 1403084: Currently does not work because of jujud problems")
+	}
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.ToolsFixture.SetUpTest(c)
+	s.PatchValue(&version.Current.Number, version.MustParse("1.8.3"))
+
+	s.sourceDir = c.MkDir()
+	s.targetDir = filepath.Join(c.MkDir(), "mirror")
+	versionStrings := make([]string, len(v1all))
+	for i, vers := range v1all {
+		versionStrings[i] = vers.String()
+	}
+	toolstesting.MakeToolsWithCheckSum(c, s.sourceDir, "released", versionStrings)
+}
+
+func (s *mirrorSuite) TearDownTest(c *gc.C) {
+	s.ToolsFixture.TearDownTest(c)
+	s.FakeJujuHomeSuite.TearDownTest(c)
+}
+
+func (s *mirrorSuite) mirrorContext() *sync.MirrorContext {
+	return &sync.MirrorContext{
+		Source:       s.sourceDir,
+		Target:       s.targetDir,
+		MajorVersion: 1,
+		MinorVersion: -1,
+	}
+}
+
+func (s *mirrorSuite) assertMirrored(c *gc.C, expected []version.Binary) {
+	stor, err := filestorage.NewFileStorageReader(s.targetDir)
+	c.Assert(err, jc.ErrorIsNil)
+	list, err := envtools.ReadList(stor, "released", 1, -1)
+	c.Assert(err, jc.ErrorIsNil)
+	var mirrored []version.Binary
+	for _, tools := range list {
+		mirrored = append(mirrored, tools.Version)
+	}
+	c.Assert(mirrored, jc.SameContents, expected)
+	metadata := toolstesting.ParseMetadataFromDir(c, s.targetDir, "released", true)
+	c.Assert(metadata, gc.HasLen, len(expected))
+}
+
+func (s *mirrorSuite) TestMirrorAll(c *gc.C) {
+	copied, err := sync.MirrorTools(s.mirrorContext())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(copied, gc.HasLen, len(v1all))
+	s.assertMirrored(c, v1all)
+}
+
+func (s *mirrorSuite) TestMirrorSelection(c *gc.C) {
+	ctx := s.mirrorContext()
+	ctx.Versions = []version.Number{version.MustParse("1.8.0"), version.MustParse("1.9.0")}
+	ctx.Series = []string{"quantal"}
+	ctx.Arches = []string{"amd64"}
+	_, err := sync.MirrorTools(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMirrored(c, []version.Binary{v180q64, v190q64})
+}
+
+func (s *mirrorSuite) TestMirrorNoMatches(c *gc.C) {
+	ctx := s.mirrorContext()
+	ctx.Series = []string{"trusty"}
+	_, err := sync.MirrorTools(ctx)
+	c.Assert(err, gc.ErrorMatches, "tools matching the mirror selection not found")
+}
+
+func (s *mirrorSuite) TestMirrorDryRun(c *gc.C) {
+	ctx := s.mirrorContext()
+	ctx.DryRun = true
+	copied, err := sync.MirrorTools(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(copied, gc.HasLen, len(v1all))
+	c.Assert(filepath.Join(s.targetDir, storage.BaseToolsPath), jc.DoesNotExist)
+}
+
+func (s *mirrorSuite) TestMirrorIncremental(c *gc.C) {
+	ctx := s.mirrorContext()
+	ctx.Series = []string{"precise"}
+	_, err := sync.MirrorTools(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMirrored(c, []version.Binary{v100p64, v180p32, v190p32})
+
+	ctx.Series = nil
+	copied, err := sync.MirrorTools(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	var copiedVersions []version.Binary
+	for _, tools := range copied {
+		copiedVersions = append(copiedVersions, tools.Version)
+	}
+	c.Assert(copiedVersions, jc.SameContents, []version.Binary{v100q64, v100q32, v180q64, v190q64})
+	s.assertMirrored(c, v1all)
+}
+
+func (s *mirrorSuite) TestVerifyMirror(c *gc.C) {
+	_, err := sync.MirrorTools(s.mirrorContext())
+	c.Assert(err, jc.ErrorIsNil)
+	problems, err := sync.VerifyMirror(s.targetDir, "released", "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 0)
+
+	corrupted := filepath.Join(s.targetDir, envtools.StorageName(v180q64, "released"))
+	err = ioutil.WriteFile(corrupted, []byte("corrupted"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	problems, err = sync.VerifyMirror(s.targetDir, "released", "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 1)
+	c.Assert(problems[0], gc.Matches, ".*juju-1.8.0-quantal-amd64.tgz: size is 9, expected .*")
+}
+
+func (s *mirrorSuite) TestVerifyMirrorSigned(c *gc.C) {
+	ctx := s.mirrorContext()
+	ctx.SigningKey = sstesting.SignedMetadataPrivateKey
+	ctx.Passphrase = sstesting.PrivateKeyPassphrase
+	_, err := sync.MirrorTools(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	problems, err := sync.VerifyMirror(s.targetDir, "released", sstesting.SignedMetadataPublicKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 0)
+}
+
+func (s *mirrorSuite) TestVerifyMirrorUnsigned(c *gc.C) {
+	_, err := sync.MirrorTools(s.mirrorContext())
+	c.Assert(err, jc.ErrorIsNil)
+	problems, err := sync.VerifyMirror(s.targetDir, "released", sstesting.SignedMetadataPublicKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.Not(gc.HasLen), 0)
+	for _, problem := range problems {
+		c.Check(problem, gc.Matches, ".*: not signed")
+	}
+}
@@ -162,7 +162,7 @@ func selectSourceDatasource(syncContext *SyncContext) (simplestreams.DataSource,
 		return nil, err
 	}
 	logger.Infof("using sync tools source: %v", sourceURL)
-	return simplestreams.NewURLDataSource("sync tools source", sourceURL, utils.VerifySSLHostnames), nil
+	return simplestreams.NewCachingURLDataSource("sync tools source", sourceURL, utils.VerifySSLHostnames), nil
 }
 
 // copyTools copies a set of tools from the source to the target.
@@ -76,7 +76,7 @@ func GetMetadataSources(env environs.Environ) ([]simplestreams.DataSource, error
 		if !config.SSLHostnameVerification() {
 			verify = utils.NoVerifySSLHostnames
 		}
-		sources = append(sources, simplestreams.NewURLDataSource(conf.AgentMetadataURLKey, userURL, verify))
+		sources = append(sources, simplestreams.NewCachingURLDataSource(conf.AgentMetadataURLKey, userURL, verify))
 	}
 
 	envDataSources, err := environmentDataSources(env)
@@ -92,7 +92,7 @@ func GetMetadataSources(env environs.Environ) ([]simplestreams.DataSource, error
 	}
 	if defaultURL != "" {
 		sources = append(sources,
-			simplestreams.NewURLDataSource("default simplestreams", defaultURL, utils.VerifySSLHostnames))
+			simplestreams.NewCachingURLDataSource("default simplestreams", defaultURL, utils.VerifySSLHostnames))
 	}
 	return sources, nil
 }
@@ -9,11 +9,12 @@ import (
 
 	"gopkg.in/juju/charm.v5/charmrepo"
 
+	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/utils/ssh"
 )
 
-// InitJujuHome initializes the charm cache, environs/config and utils/ssh packages
+// InitJujuHome initializes the charm cache, simplestreams cache, environs/config and utils/ssh packages
 // to use default paths based on the $JUJU_HOME or $HOME environment variables.
 // This function should be called before running a Juju CLI command.
 func InitJujuHome() error {
@@ -24,6 +25,7 @@ func InitJujuHome() error {
 	}
 	osenv.SetJujuHome(jujuHome)
 	charmrepo.CacheDir = osenv.JujuHomePath("charmcache")
+	simplestreams.CacheDir = osenv.JujuHomePath("simplestreamscache")
 	if err := ssh.LoadClientKeys(osenv.JujuHomePath("ssh")); err != nil {
 		return fmt.Errorf("cannot load ssh client keys: %v", err)
 	}
@@ -0,0 +1,155 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package jujurun defines the predefined action through which the
+// commands given to "juju run" are delivered to unit and machine
+// agents, along with the encoding of its parameters and results.
+package jujurun
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/exec"
+)
+
+var logger = loggo.GetLogger("juju.jujurun")
+
+// ActionName is the name of the predefined action used to run
+// commands on units and machines. It is valid for every action
+// receiver, regardless of the actions defined by a unit's charm.
+const ActionName = "juju-run"
+
+const (
+	// CommandsParam holds the commands to run.
+	CommandsParam = "command"
+
+	// TimeoutParam holds the maximum time, in nanoseconds, that the
+	// commands are allowed to run for. Zero means no limit.
+	TimeoutParam = "timeout"
+)
+
+const (
+	// CodeResult holds the exit code of the commands.
+	CodeResult = "Code"
+
+	// StdoutResult holds the standard output of the commands.
+	StdoutResult = "Stdout"
+
+	// StderrResult holds the standard error of the commands.
+	StderrResult = "Stderr"
+)
+
+// ActionParams returns the parameters of a juju-run action that runs
+// the given commands for at most the given duration.
+func ActionParams(commands string, timeout time.Duration) map[string]interface{} {
+	return map[string]interface{}{
+		CommandsParam: commands,
+		TimeoutParam:  int64(timeout),
+	}
+}
+
+// ParseParams extracts the commands and timeout from the parameters
+// of a juju-run action.
+func ParseParams(params map[string]interface{}) (string, time.Duration, error) {
+	commands, ok := params[CommandsParam].(string)
+	if !ok || commands == "" {
+		return "", 0, errors.NotValidf("%s action without commands", ActionName)
+	}
+	var timeout time.Duration
+	if value, ok := params[TimeoutParam]; ok {
+		n, err := toInt64(value)
+		if err != nil {
+			return "", 0, errors.Annotatef(err, "invalid %s action timeout", ActionName)
+		}
+		if n < 0 {
+			return "", 0, errors.NotValidf("negative %s action timeout", ActionName)
+		}
+		timeout = time.Duration(n)
+	}
+	for name := range params {
+		if name != CommandsParam && name != TimeoutParam {
+			return "", 0, errors.NotValidf("%s action parameter %q", ActionName, name)
+		}
+	}
+	return commands, timeout, nil
+}
+
+// ActionResults returns the results of a juju-run action that
+// produced the given response.
+func ActionResults(response *exec.ExecResponse) map[string]interface{} {
+	return map[string]interface{}{
+		CodeResult:   response.Code,
+		StdoutResult: string(response.Stdout),
+		StderrResult: string(response.Stderr),
+	}
+}
+
+// ParseResults converts the results of a completed juju-run action
+// back into the response produced by running its commands.
+func ParseResults(results map[string]interface{}) (*exec.ExecResponse, error) {
+	code, err := toInt64(results[CodeResult])
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid %s action exit code", ActionName)
+	}
+	stdout, _ := results[StdoutResult].(string)
+	stderr, _ := results[StderrResult].(string)
+	return &exec.ExecResponse{
+		Code:   int(code),
+		Stdout: []byte(stdout),
+		Stderr: []byte(stderr),
+	}, nil
+}
+
+// ErrTimedOut is returned by Wait when the commands do not finish
+// within their timeout.
+var ErrTimedOut = errors.New("commands timed out")
+
+// Wait waits for the started commands to finish, killing them if they
+// run for longer than the given timeout. A zero timeout waits without
+// limit. When the commands are killed, the output they produced so far
+// is returned along with ErrTimedOut.
+func Wait(command *exec.RunParams, timeout time.Duration) (*exec.ExecResponse, error) {
+	if timeout == 0 {
+		return command.Wait()
+	}
+	type waitResult struct {
+		response *exec.ExecResponse
+		err      error
+	}
+	done := make(chan waitResult, 1)
+	go func() {
+		response, err := command.Wait()
+		done <- waitResult{response, err}
+	}()
+	select {
+	case result := <-done:
+		return result.response, result.err
+	case <-time.After(timeout):
+	}
+	if err := command.Process().Kill(); err != nil {
+		logger.Warningf("cannot kill timed out commands: %v", err)
+	}
+	result := <-done
+	return result.response, ErrTimedOut
+}
+
+// toInt64 converts a number that may have been decoded from BSON or
+// JSON into an int64.
+func toInt64(value interface{}) (int64, error) {
+	switch value := value.(type) {
+	case int:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case float64:
+		if value != float64(int64(value)) {
+			return 0, errors.Errorf("expected integer, got %v", value)
+		}
+		return int64(value), nil
+	}
+	return 0, errors.Errorf("expected integer, got %T", value)
+}
@@ -0,0 +1,107 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujurun_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/exec"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/jujurun"
+	"github.com/juju/juju/testing"
+)
+
+type jujurunSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&jujurunSuite{})
+
+func (s *jujurunSuite) TestParamsRoundTrip(c *gc.C) {
+	params := jujurun.ActionParams("hostname", time.Minute)
+	commands, timeout, err := jujurun.ParseParams(params)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(commands, gc.Equals, "hostname")
+	c.Assert(timeout, gc.Equals, time.Minute)
+}
+
+func (s *jujurunSuite) TestParseParamsDecodedNumbers(c *gc.C) {
+	for i, value := range []interface{}{60000000000, int64(60000000000), float64(60000000000)} {
+		c.Logf("test %d: %T", i, value)
+		_, timeout, err := jujurun.ParseParams(map[string]interface{}{
+			"command": "hostname",
+			"timeout": value,
+		})
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(timeout, gc.Equals, time.Minute)
+	}
+}
+
+func (s *jujurunSuite) TestParseParamsInvalid(c *gc.C) {
+	for i, test := range []struct {
+		params map[string]interface{}
+		err    string
+	}{{
+		params: map[string]interface{}{},
+		err:    "juju-run action without commands not valid",
+	}, {
+		params: map[string]interface{}{"command": "hostname", "timeout": "soon"},
+		err:    "invalid juju-run action timeout: expected integer, got string",
+	}, {
+		params: map[string]interface{}{"command": "hostname", "timeout": 1.5},
+		err:    "invalid juju-run action timeout: expected integer, got 1.5",
+	}, {
+		params: map[string]interface{}{"command": "hostname", "timeout": -1},
+		err:    "negative juju-run action timeout not valid",
+	}, {
+		params: map[string]interface{}{"command": "hostname", "user": "root"},
+		err:    `juju-run action parameter "user" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, _, err := jujurun.ParseParams(test.params)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *jujurunSuite) TestResultsRoundTrip(c *gc.C) {
+	response := &exec.ExecResponse{
+		Code:   3,
+		Stdout: []byte("out"),
+		Stderr: []byte("err"),
+	}
+	results := jujurun.ActionResults(response)
+	c.Assert(results, jc.DeepEquals, map[string]interface{}{
+		"Code":   3,
+		"Stdout": "out",
+		"Stderr": "err",
+	})
+	parsed, err := jujurun.ParseResults(results)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed, jc.DeepEquals, response)
+}
+
+func (s *jujurunSuite) TestParseResultsMissingCode(c *gc.C) {
+	_, err := jujurun.ParseResults(map[string]interface{}{"Stdout": "out"})
+	c.Assert(err, gc.ErrorMatches, "invalid juju-run action exit code: expected integer, got <nil>")
+}
+
+func (s *jujurunSuite) TestWait(c *gc.C) {
+	command := exec.RunParams{Commands: "echo hello"}
+	err := command.Run()
+	c.Assert(err, jc.ErrorIsNil)
+	response, err := jujurun.Wait(&command, testing.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(response.Stdout), gc.Equals, "hello\n")
+}
+
+func (s *jujurunSuite) TestWaitTimesOut(c *gc.C) {
+	command := exec.RunParams{Commands: "echo hello; exec sleep 60"}
+	err := command.Run()
+	c.Assert(err, jc.ErrorIsNil)
+	response, err := jujurun.Wait(&command, testing.ShortWait)
+	c.Assert(err, gc.Equals, jujurun.ErrTimedOut)
+	c.Assert(response, gc.NotNil)
+}
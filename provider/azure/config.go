@@ -84,6 +84,13 @@ func (prov azureEnvironProvider) Validate(cfg, oldCfg *config.Config) (*config.C
 		return nil, err
 	}
 
+	// Resource tags are recorded on hosted services, which several
+	// instances may share, and could not be updated nor applied to
+	// data disks, so refuse them rather than applying them partially.
+	if tags, ok := cfg.ResourceTags(); ok && len(tags) > 0 {
+		return nil, fmt.Errorf("%s not supported by the Azure provider", config.ResourceTagsKey)
+	}
+
 	// User cannot change availability-sets-enabled after environment is prepared.
 	if oldCfg != nil {
 		if oldCfg.AllAttrs()["availability-sets-enabled"] != cfg.AllAttrs()["availability-sets-enabled"] {
@@ -95,6 +95,16 @@ func (*configSuite) TestValidateAcceptsUnchangedConfig(c *gc.C) {
 	c.Check(result.Name(), gc.Equals, attrs["name"])
 }
 
+func (*configSuite) TestValidateRejectsResourceTags(c *gc.C) {
+	attrs := makeAzureConfigMap(c)
+	attrs["resource-tags"] = "cost-centre=1234"
+	provider := azureEnvironProvider{}
+	config, err := config.New(config.NoDefaults, attrs)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = provider.Validate(config, nil)
+	c.Check(err, gc.ErrorMatches, "resource-tags not supported by the Azure provider")
+}
+
 func (*configSuite) TestValidateChecksConfigChanges(c *gc.C) {
 	provider := azureEnvironProvider{}
 	oldConfig, err := config.New(config.NoDefaults, makeConfigMap(nil))
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -475,7 +476,12 @@ func (env *azureEnviron) SetConfig(cfg *config.Config) error {
 // name it chooses (based on the given prefix), but recognizes that the name
 // may not be available.  If the name is not available, it does not treat that
 // as an error but just returns nil.
-func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string) (*gwacl.CreateHostedService, error) {
+//
+// Azure virtual machines have no tags, so the given tags are recorded
+// as extended properties of the hosted service. Only Juju's own tags,
+// which never change, are recorded; the resource-tags setting is
+// refused by the provider's Validate.
+func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string, tags map[string]string) (*gwacl.CreateHostedService, error) {
 	var err error
 	name := gwacl.MakeRandomHostedServiceName(prefix)
 	err = azure.CheckHostedServiceNameAvailability(name)
@@ -488,6 +494,7 @@ func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName,
 	}
 	req := gwacl.NewCreateHostedServiceWithLocation(name, label, "")
 	req.AffinityGroup = affinityGroupName
+	req.ExtendedProperties = extendedProperties(tags)
 	err = azure.AddHostedService(req)
 	if err != nil {
 		return nil, err
@@ -497,11 +504,11 @@ func attemptCreateService(azure *gwacl.ManagementAPI, prefix, affinityGroupName,
 
 // newHostedService creates a hosted service.  It will make up a unique name,
 // starting with the given prefix.
-func newHostedService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string) (*gwacl.HostedService, error) {
+func newHostedService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, label string, tags map[string]string) (*gwacl.HostedService, error) {
 	var err error
 	var createdService *gwacl.CreateHostedService
 	for tries := 10; tries > 0 && err == nil && createdService == nil; tries-- {
-		createdService, err = attemptCreateService(azure, prefix, affinityGroupName, label)
+		createdService, err = attemptCreateService(azure, prefix, affinityGroupName, label, tags)
 	}
 	if err != nil {
 		return nil, errors.Annotate(err, "could not create hosted service")
@@ -512,6 +519,51 @@ func newHostedService(azure *gwacl.ManagementAPI, prefix, affinityGroupName, lab
 	return azure.GetHostedServiceProperties(createdService.ServiceName, true)
 }
 
+const (
+	// maxPropertyNameLength and maxPropertyValueLength are the
+	// maximum lengths of the names and values of the extended
+	// properties of a hosted service.
+	maxPropertyNameLength  = 64
+	maxPropertyValueLength = 255
+)
+
+// invalidPropertyNameChars matches the characters that may not appear
+// in the name of an extended property.
+var invalidPropertyNameChars = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// extendedProperties returns the hosted service extended properties
+// recording the given tags, sorted by name. Property names may contain
+// only letters, digits and underscores, so any other characters in
+// tag names (such as the hyphens in "juju-env-uuid") are replaced with
+// underscores; overlong names and values are truncated.
+func extendedProperties(tags map[string]string) []gwacl.ExtendedProperty {
+	if len(tags) == 0 {
+		return nil
+	}
+	properties := make([]gwacl.ExtendedProperty, 0, len(tags))
+	for name, value := range tags {
+		name = invalidPropertyNameChars.ReplaceAllString(name, "_")
+		if len(name) > maxPropertyNameLength {
+			name = name[:maxPropertyNameLength]
+		}
+		if len(value) > maxPropertyValueLength {
+			value = value[:maxPropertyValueLength]
+		}
+		properties = append(properties, gwacl.ExtendedProperty{
+			Name:  name,
+			Value: value,
+		})
+	}
+	sort.Sort(byPropertyName(properties))
+	return properties
+}
+
+type byPropertyName []gwacl.ExtendedProperty
+
+func (p byPropertyName) Len() int           { return len(p) }
+func (p byPropertyName) Less(i, j int) bool { return p[i].Name < p[j].Name }
+func (p byPropertyName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
 // SupportedArchitectures is specified on the EnvironCapability interface.
 func (env *azureEnviron) SupportedArchitectures() ([]string, error) {
 	env.archMutex.Lock()
@@ -621,8 +673,9 @@ func (env *azureEnviron) PrecheckInstance(series string, cons constraints.Value,
 //
 // If serviceName is non-empty, then createInstance will assign to
 // the Cloud Service with that name. Otherwise, a new Cloud Service
-// will be created.
-func (env *azureEnviron) createInstance(azure *gwacl.ManagementAPI, role *gwacl.Role, serviceName string, stateServer bool) (resultInst instance.Instance, resultErr error) {
+// will be created, recording the given tags. Instances that share a
+// Cloud Service share its tags.
+func (env *azureEnviron) createInstance(azure *gwacl.ManagementAPI, role *gwacl.Role, serviceName string, stateServer bool, tags map[string]string) (resultInst instance.Instance, resultErr error) {
 	var inst instance.Instance
 	defer func() {
 		if inst != nil && resultErr != nil {
@@ -647,7 +700,7 @@ func (env *azureEnviron) createInstance(azure *gwacl.ManagementAPI, role *gwacl.
 		if stateServer {
 			label = stateServerLabel
 		}
-		service, err = newHostedService(azure, env.getEnvPrefix(), env.getAffinityGroupName(), label)
+		service, err = newHostedService(azure, env.getEnvPrefix(), env.getAffinityGroupName(), label, tags)
 	}
 	if err != nil {
 		return nil, err
@@ -763,7 +816,7 @@ func (env *azureEnviron) StartInstance(args environs.StartInstanceParams) (*envi
 	// All other machines get an auto-generated public port for SSH.
 	stateServer := multiwatcher.AnyJobNeedsState(args.InstanceConfig.Jobs...)
 	role := env.newRole(instanceType.Id, vhd, userData, stateServer)
-	inst, err := createInstance(env, snapshot.api, role, cloudServiceName, stateServer)
+	inst, err := createInstance(env, snapshot.api, role, cloudServiceName, stateServer, args.InstanceConfig.Tags)
 	if err != nil {
 		return nil, err
 	}
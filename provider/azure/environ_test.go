@@ -614,7 +614,7 @@ func (*environSuite) TestAttemptCreateServiceCreatesService(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, jc.ErrorIsNil)
 
-	service, err := attemptCreateService(azure, prefix, affinityGroup, "")
+	service, err := attemptCreateService(azure, prefix, affinityGroup, "", nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	c.Assert(*requests, gc.HasLen, 2)
@@ -626,6 +626,30 @@ func (*environSuite) TestAttemptCreateServiceCreatesService(c *gc.C) {
 	c.Check(service.Location, gc.Equals, "")
 }
 
+func (*environSuite) TestAttemptCreateServiceRecordsTags(c *gc.C) {
+	responses := []gwacl.DispatcherResponse{
+		gwacl.NewDispatcherResponse(makeAvailabilityResponse(c), http.StatusOK, nil),
+		gwacl.NewDispatcherResponse(nil, http.StatusOK, nil),
+	}
+	requests := gwacl.PatchManagementAPIResponses(responses)
+	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
+	c.Assert(err, jc.ErrorIsNil)
+
+	tags := map[string]string{
+		"juju-env-uuid":       "deadbeef",
+		"juju-units-deployed": strings.Repeat("x", 300),
+	}
+	_, err = attemptCreateService(azure, "service", "affinity-group", "", tags)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(*requests, gc.HasLen, 2)
+	body := parseCreateServiceRequest(c, (*requests)[1])
+	c.Check(body.ExtendedProperties, jc.DeepEquals, []gwacl.ExtendedProperty{
+		{Name: "juju_env_uuid", Value: "deadbeef"},
+		{Name: "juju_units_deployed", Value: strings.Repeat("x", 255)},
+	})
+}
+
 func (*environSuite) TestAttemptCreateServiceReturnsNilIfNameNotUnique(c *gc.C) {
 	responses := []gwacl.DispatcherResponse{
 		gwacl.NewDispatcherResponse(makeNonAvailabilityResponse(c), http.StatusOK, nil),
@@ -634,7 +658,7 @@ func (*environSuite) TestAttemptCreateServiceReturnsNilIfNameNotUnique(c *gc.C)
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, jc.ErrorIsNil)
 
-	service, err := attemptCreateService(azure, "service", "affinity-group", "")
+	service, err := attemptCreateService(azure, "service", "affinity-group", "", nil)
 	c.Check(err, jc.ErrorIsNil)
 	c.Check(service, gc.IsNil)
 }
@@ -648,7 +672,7 @@ func (*environSuite) TestAttemptCreateServicePropagatesOtherFailure(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, jc.ErrorIsNil)
 
-	_, err = attemptCreateService(azure, "service", "affinity-group", "")
+	_, err = attemptCreateService(azure, "service", "affinity-group", "", nil)
 	c.Assert(err, gc.NotNil)
 	c.Check(err, gc.ErrorMatches, ".*Not Found.*")
 }
@@ -669,7 +693,7 @@ func (*environSuite) TestNewHostedServiceCreatesService(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, jc.ErrorIsNil)
 
-	service, err := newHostedService(azure, prefix, affinityGroup, "")
+	service, err := newHostedService(azure, prefix, affinityGroup, "", nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	c.Assert(*requests, gc.HasLen, 3)
@@ -702,7 +726,7 @@ func (*environSuite) TestNewHostedServiceRetriesIfNotUnique(c *gc.C) {
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, jc.ErrorIsNil)
 
-	service, err := newHostedService(azure, "service", "affinity-group", "")
+	service, err := newHostedService(azure, "service", "affinity-group", "", nil)
 	c.Check(err, jc.ErrorIsNil)
 
 	c.Assert(*requests, gc.HasLen, 5)
@@ -740,7 +764,7 @@ func (*environSuite) TestNewHostedServiceFailsIfUnableToFindUniqueName(c *gc.C)
 	azure, err := gwacl.NewManagementAPI("subscription", "", "West US")
 	c.Assert(err, jc.ErrorIsNil)
 
-	_, err = newHostedService(azure, "service", "affinity-group", "")
+	_, err = newHostedService(azure, "service", "affinity-group", "", nil)
 	c.Assert(err, gc.NotNil)
 	c.Check(err, gc.ErrorMatches, "could not come up with a unique hosted service name.*")
 }
@@ -1503,7 +1527,7 @@ func (s *startInstanceSuite) SetUpTest(c *gc.C) {
 func (s *startInstanceSuite) startInstance(c *gc.C) (serviceName string, stateServer bool) {
 	var called bool
 	var roleSize gwacl.RoleSize
-	restore := testing.PatchValue(&createInstance, func(env *azureEnviron, azure *gwacl.ManagementAPI, role *gwacl.Role, serviceNameArg string, stateServerArg bool) (instance.Instance, error) {
+	restore := testing.PatchValue(&createInstance, func(env *azureEnviron, azure *gwacl.ManagementAPI, role *gwacl.Role, serviceNameArg string, stateServerArg bool, tags map[string]string) (instance.Instance, error) {
 		serviceName = serviceNameArg
 		stateServer = stateServerArg
 		for _, r := range gwacl.RoleSizes {
@@ -1648,7 +1672,7 @@ func (s *environSuite) TestBootstrapReusesAffinityGroupAndVNet(c *gc.C) {
 	responses = append(responses, gwacl.NewDispatcherResponse(nil, http.StatusOK, nil))          // PUT network (delete)
 	gwacl.PatchManagementAPIResponses(responses)
 
-	s.PatchValue(&createInstance, func(*azureEnviron, *gwacl.ManagementAPI, *gwacl.Role, string, bool) (instance.Instance, error) {
+	s.PatchValue(&createInstance, func(*azureEnviron, *gwacl.ManagementAPI, *gwacl.Role, string, bool, map[string]string) (instance.Instance, error) {
 		return nil, fmt.Errorf("no instance for you")
 	})
 	err = bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
@@ -15,5 +15,9 @@ const (
 func init() {
 	environs.RegisterProvider(providerType, azureEnvironProvider{})
 
-	registry.RegisterEnvironStorageProviders(providerType)
+	// Register the Azure specific providers.
+	registry.RegisterProvider(AzureProviderType, &azureStorageProvider{})
+
+	// Inform the storage provider registry about the Azure providers.
+	registry.RegisterEnvironStorageProviders(providerType, AzureProviderType)
 }
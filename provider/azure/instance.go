@@ -288,3 +288,23 @@ func (azInstance *azureInstance) listPorts(api *gwacl.ManagementAPI) ([]network.
 	ports := convertAndFilterEndpoints(endpoints, azInstance.environ, azInstance.maskStateServerPorts)
 	return ports, nil
 }
+
+// getRole returns the persistent VM role of this instance.
+func (azInstance *azureInstance) getRole(api *gwacl.ManagementAPI) (*gwacl.PersistentVMRole, error) {
+	return api.GetRole(&gwacl.GetRoleRequest{
+		ServiceName:    azInstance.serviceName(),
+		DeploymentName: azInstance.deploymentName,
+		RoleName:       azInstance.roleName,
+	})
+}
+
+// updateRole replaces the persistent VM role of this instance with
+// the given one.
+func (azInstance *azureInstance) updateRole(api *gwacl.ManagementAPI, role *gwacl.PersistentVMRole) error {
+	return api.UpdateRole(&gwacl.UpdateRoleRequest{
+		ServiceName:      azInstance.serviceName(),
+		DeploymentName:   azInstance.deploymentName,
+		RoleName:         azInstance.roleName,
+		PersistentVMRole: role,
+	})
+}
@@ -0,0 +1,454 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"launchpad.net/gwacl"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+const (
+	AzureProviderType = storage.ProviderType("azure")
+)
+
+const (
+	// dataDiskSizeMaxGB is the maximum size (in gigabytes) of an
+	// Azure data disk.
+	dataDiskSizeMaxGB = 1023
+
+	// maxDataDiskLUN is one more than the highest logical unit
+	// number that may be assigned to a role's data disks. The number
+	// of disks that may actually be attached is further limited by
+	// the role size.
+	maxDataDiskLUN = 32
+
+	// vhdContainer is the storage container in which the page blobs
+	// backing data disks (and OS disks) are kept.
+	vhdContainer = "vhds"
+
+	// dataDiskBusAddressFormat is the format of the bus address of a
+	// data disk, given its LUN. Hyper-V exposes data disks to Linux
+	// guests on a SCSI host of their own, after the hosts of the OS
+	// and resource disks; they are all at channel 0 and target 0, and
+	// differ only by LUN. The host number is the one Ubuntu's kernels
+	// assign to that controller (as shown by e.g. lsscsi).
+	dataDiskBusAddressFormat = "scsi@5:0.0.%d"
+)
+
+// azureStorageProvider creates volume sources which use Azure data
+// disks, backed by page blobs in the environment's storage account.
+type azureStorageProvider struct{}
+
+var _ storage.Provider = (*azureStorageProvider)(nil)
+
+var azureStorageConfigFields = schema.Fields{
+	storage.Persistent: schema.Bool(),
+}
+
+var azureStorageConfigChecker = schema.FieldMap(
+	azureStorageConfigFields,
+	schema.Defaults{
+		storage.Persistent: true,
+	},
+)
+
+func validateAzureStorageConfig(attrs map[string]interface{}) error {
+	out, err := azureStorageConfigChecker.Coerce(attrs, nil)
+	if err != nil {
+		return errors.Annotate(err, "validating Azure storage config")
+	}
+	coerced := out.(map[string]interface{})
+	if !coerced[storage.Persistent].(bool) {
+		// Data disks are not deleted when the roles they are
+		// attached to are, so they always outlive their instances.
+		return errors.New("cannot create a non-persistent Azure data disk")
+	}
+	return nil
+}
+
+// ValidateConfig is defined on the Provider interface.
+func (*azureStorageProvider) ValidateConfig(cfg *storage.Config) error {
+	return validateAzureStorageConfig(cfg.Attrs())
+}
+
+// Supports is defined on the Provider interface.
+func (*azureStorageProvider) Supports(k storage.StorageKind) bool {
+	return k == storage.StorageKindBlock
+}
+
+// Scope is defined on the Provider interface.
+func (*azureStorageProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+// Dynamic is defined on the Provider interface.
+func (*azureStorageProvider) Dynamic() bool {
+	return true
+}
+
+// VolumeSource is defined on the Provider interface.
+func (p *azureStorageProvider) VolumeSource(environConfig *config.Config, cfg *storage.Config) (storage.VolumeSource, error) {
+	if err := p.ValidateConfig(cfg); err != nil {
+		return nil, errors.Trace(err)
+	}
+	env, err := NewEnviron(environConfig)
+	if err != nil {
+		return nil, errors.Annotate(err, "creating Azure environ")
+	}
+	return &azureVolumeSource{env: env}, nil
+}
+
+// FilesystemSource is defined on the Provider interface.
+func (*azureStorageProvider) FilesystemSource(environConfig *config.Config, providerConfig *storage.Config) (storage.FilesystemSource, error) {
+	return nil, errors.NotSupportedf("filesystems")
+}
+
+type azureVolumeSource struct {
+	env *azureEnviron
+}
+
+var _ storage.VolumeSource = (*azureVolumeSource)(nil)
+
+// CreateVolumes is specified on the storage.VolumeSource interface.
+//
+// Azure creates a blank data disk when a role is updated to refer to
+// a page blob that does not yet exist, so each volume is created by
+// attaching it to the role of the instance it is destined for.
+func (v *azureVolumeSource) CreateVolumes(params []storage.VolumeParams) (_ []storage.Volume, _ []storage.VolumeAttachment, err error) {
+	volumes := make([]storage.Volume, 0, len(params))
+	volumeAttachments := make([]storage.VolumeAttachment, 0, len(params))
+
+	// If there's an error, we detach and delete any disks that
+	// were created.
+	var attached []storage.VolumeAttachmentParams
+	defer func() {
+		if err == nil {
+			return
+		}
+		if err := v.DetachVolumes(attached); err != nil {
+			logger.Warningf("error detaching volumes: %v", err)
+		}
+		volIds := make([]string, len(volumes))
+		for i, volume := range volumes {
+			volIds[i] = volume.VolumeId
+		}
+		for i, volErr := range v.DestroyVolumes(volIds) {
+			if volErr != nil {
+				logger.Warningf("error cleaning up volume %v: %v", volumes[i].Tag, volErr)
+			}
+		}
+	}()
+
+	instIds := make([]instance.Id, len(params))
+	for i, p := range params {
+		if err := v.ValidateVolumeParams(p); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		instIds[i] = p.Attachment.InstanceId
+	}
+	instances, err := v.instances(instIds)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "querying instance details")
+	}
+
+	for i, p := range params {
+		volumeId := gwacl.MakeRandomDiskName("juju")
+		sizeGB := mibToGb(p.Size)
+		mediaLink := v.mediaLink(volumeId)
+		lun, err := v.attachDataDisk(instances[i], mediaLink, gwacl.DataVirtualHardDisk{
+			MediaLink:           mediaLink,
+			LogicalDiskSizeInGB: int(sizeGB),
+		})
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "creating volume %v", p.Tag)
+		}
+		volumes = append(volumes, storage.Volume{
+			p.Tag,
+			storage.VolumeInfo{
+				VolumeId:   volumeId,
+				Size:       gbToMib(sizeGB),
+				Persistent: true,
+			},
+		})
+		attached = append(attached, storage.VolumeAttachmentParams{
+			AttachmentParams: *p.Attachment,
+			Volume:           p.Tag,
+			VolumeId:         volumeId,
+		})
+		volumeAttachments = append(volumeAttachments, storage.VolumeAttachment{
+			p.Tag,
+			p.Attachment.Machine,
+			storage.VolumeAttachmentInfo{
+				BusAddress: dataDiskBusAddress(lun),
+			},
+		})
+	}
+	return volumes, volumeAttachments, nil
+}
+
+// DescribeVolumes is specified on the storage.VolumeSource interface.
+func (v *azureVolumeSource) DescribeVolumes(volIds []string) ([]storage.VolumeInfo, error) {
+	disks, err := v.disks()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	vols := make([]storage.VolumeInfo, len(volIds))
+	for i, volumeId := range volIds {
+		disk, ok := disks[v.mediaLink(volumeId)]
+		if !ok {
+			return nil, errors.NotFoundf("volume %q", volumeId)
+		}
+		vols[i] = storage.VolumeInfo{
+			VolumeId:   volumeId,
+			Size:       gbToMib(uint64(disk.LogicalDiskSizeInGB)),
+			Persistent: true,
+		}
+	}
+	return vols, nil
+}
+
+// DestroyVolumes is specified on the storage.VolumeSource interface.
+//
+// Deleting a disk also deletes the page blob backing it. A volume
+// whose disk no longer exists is considered to have been destroyed.
+func (v *azureVolumeSource) DestroyVolumes(volIds []string) []error {
+	results := make([]error, len(volIds))
+	disks, err := v.disks()
+	if err != nil {
+		for i := range results {
+			results[i] = errors.Trace(err)
+		}
+		return results
+	}
+	api := v.env.getSnapshot().api
+	for i, volumeId := range volIds {
+		disk, ok := disks[v.mediaLink(volumeId)]
+		if !ok {
+			continue
+		}
+		err := api.DeleteDisk(&gwacl.DeleteDiskRequest{
+			DiskName:   disk.Name,
+			DeleteBlob: true,
+		})
+		if err != nil {
+			results[i] = errors.Annotatef(err, "destroying %q", volumeId)
+		}
+	}
+	return results
+}
+
+// ValidateVolumeParams is specified on the storage.VolumeSource interface.
+func (v *azureVolumeSource) ValidateVolumeParams(params storage.VolumeParams) error {
+	if err := validateAzureStorageConfig(params.Attributes); err != nil {
+		return errors.Trace(err)
+	}
+	if params.Attachment == nil {
+		return errors.Errorf("cannot create %v: Azure data disks must be created attached to an instance", params.Tag)
+	}
+	if size := mibToGb(params.Size); size > dataDiskSizeMaxGB {
+		return errors.Errorf("%d GB exceeds the maximum of %d GB", size, dataDiskSizeMaxGB)
+	}
+	return nil
+}
+
+// AttachVolumes is specified on the storage.VolumeSource interface.
+//
+// A data disk that refers to a media link alone is created from that
+// page blob; volumes already have a registered disk, so they must be
+// attached by disk name.
+func (v *azureVolumeSource) AttachVolumes(attachParams []storage.VolumeAttachmentParams) ([]storage.VolumeAttachment, error) {
+	instIds := make([]instance.Id, len(attachParams))
+	for i, params := range attachParams {
+		instIds[i] = params.InstanceId
+	}
+	instances, err := v.instances(instIds)
+	if err != nil {
+		return nil, errors.Annotate(err, "querying instance details")
+	}
+	disks, err := v.disks()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	attachments := make([]storage.VolumeAttachment, len(attachParams))
+	for i, params := range attachParams {
+		mediaLink := v.mediaLink(params.VolumeId)
+		disk, ok := disks[mediaLink]
+		if !ok {
+			return nil, errors.NotFoundf("volume %q", params.VolumeId)
+		}
+		lun, err := v.attachDataDisk(instances[i], mediaLink, gwacl.DataVirtualHardDisk{
+			DiskName: disk.Name,
+		})
+		if err != nil {
+			return nil, errors.Annotatef(err, "attaching %v to %v", params.VolumeId, params.InstanceId)
+		}
+		attachments[i] = storage.VolumeAttachment{
+			params.Volume,
+			params.Machine,
+			storage.VolumeAttachmentInfo{
+				BusAddress: dataDiskBusAddress(lun),
+			},
+		}
+	}
+	return attachments, nil
+}
+
+// DetachVolumes is specified on the storage.VolumeSource interface.
+func (v *azureVolumeSource) DetachVolumes(attachParams []storage.VolumeAttachmentParams) error {
+	if len(attachParams) == 0 {
+		return nil
+	}
+	instIds := make([]instance.Id, len(attachParams))
+	for i, params := range attachParams {
+		instIds[i] = params.InstanceId
+	}
+	instances, err := v.instances(instIds)
+	if err != nil {
+		return errors.Annotate(err, "querying instance details")
+	}
+	for i, params := range attachParams {
+		if err := v.detachDataDisk(instances[i], v.mediaLink(params.VolumeId)); err != nil {
+			return errors.Annotatef(err, "detaching %v from %v", params.Volume, params.Machine)
+		}
+	}
+	return nil
+}
+
+// instances returns the Azure instances with the specified IDs. If
+// any of the specified IDs does not refer to a running instance, it
+// will cause an error to be returned.
+func (v *azureVolumeSource) instances(ids []instance.Id) ([]*azureInstance, error) {
+	instances, err := v.env.Instances(ids)
+	switch err {
+	case nil, environs.ErrNoInstances, environs.ErrPartialInstances:
+	default:
+		return nil, errors.Trace(err)
+	}
+	result := make([]*azureInstance, len(ids))
+	var notRunning []string
+	for i := range ids {
+		if instances == nil || instances[i] == nil {
+			notRunning = append(notRunning, string(ids[i]))
+			continue
+		}
+		result[i] = instances[i].(*azureInstance)
+	}
+	if len(notRunning) > 0 {
+		return nil, errors.Errorf(
+			"volumes can only be attached to running instances, these instances are not running: %v",
+			strings.Join(notRunning, ","),
+		)
+	}
+	return result, nil
+}
+
+// attachDataDisk adds the given data disk, backed by the page blob
+// with the given media link, to the instance's role at the lowest free
+// LUN, and returns the LUN. If a disk with the same media link is
+// already attached to the role, its LUN is returned and the role is
+// left unchanged.
+func (v *azureVolumeSource) attachDataDisk(inst *azureInstance, mediaLink string, disk gwacl.DataVirtualHardDisk) (int, error) {
+	var lun int
+	err := inst.apiCall(true, func(api *gwacl.ManagementAPI) error {
+		role, err := inst.getRole(api)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		inUse := make([]bool, maxDataDiskLUN)
+		for _, existing := range role.DataVirtualHardDisks {
+			if existing.MediaLink == mediaLink {
+				lun = existing.LUN
+				return nil
+			}
+			if existing.LUN >= 0 && existing.LUN < maxDataDiskLUN {
+				inUse[existing.LUN] = true
+			}
+		}
+		lun = -1
+		for i, used := range inUse {
+			if !used {
+				lun = i
+				break
+			}
+		}
+		if lun < 0 {
+			return errors.Errorf("no free LUNs on %v", inst.Id())
+		}
+		disk.LUN = lun
+		role.DataVirtualHardDisks = append(role.DataVirtualHardDisks, disk)
+		return inst.updateRole(api, role)
+	})
+	return lun, err
+}
+
+// detachDataDisk removes the data disk with the given media link from
+// the instance's role. If no such disk is attached to the role then
+// this is a noop.
+func (v *azureVolumeSource) detachDataDisk(inst *azureInstance, mediaLink string) error {
+	return inst.apiCall(true, func(api *gwacl.ManagementAPI) error {
+		role, err := inst.getRole(api)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		disks := role.DataVirtualHardDisks[:0]
+		for _, disk := range role.DataVirtualHardDisks {
+			if disk.MediaLink != mediaLink {
+				disks = append(disks, disk)
+			}
+		}
+		if len(disks) == len(role.DataVirtualHardDisks) {
+			return nil
+		}
+		role.DataVirtualHardDisks = disks
+		return inst.updateRole(api, role)
+	})
+}
+
+// disks returns the disks registered in the environment's
+// subscription, keyed by media link.
+func (v *azureVolumeSource) disks() (map[string]gwacl.Disk, error) {
+	disks, err := v.env.getSnapshot().api.ListDisks()
+	if err != nil {
+		return nil, errors.Annotate(err, "listing disks")
+	}
+	result := make(map[string]gwacl.Disk)
+	for _, disk := range disks {
+		result[disk.MediaLink] = disk
+	}
+	return result, nil
+}
+
+// mediaLink returns the URL of the page blob backing the volume with
+// the given ID.
+func (v *azureVolumeSource) mediaLink(volumeId string) string {
+	storageAccount := v.env.getSnapshot().ecfg.storageAccountName()
+	return gwacl.CreateVirtualHardDiskMediaLink(storageAccount, path.Join(vhdContainer, volumeId))
+}
+
+// dataDiskBusAddress returns the bus address of the data disk with
+// the given LUN.
+func dataDiskBusAddress(lun int) string {
+	return fmt.Sprintf(dataDiskBusAddressFormat, lun)
+}
+
+// mibToGb converts mebibytes to gigabytes, rounding up. Azure disk
+// sizes are in gigabytes, but are in fact GiB.
+func mibToGb(m uint64) uint64 {
+	return (m + 1023) / 1024
+}
+
+// gbToMib converts gigabytes to mebibytes.
+func gbToMib(g uint64) uint64 {
+	return g * 1024
+}
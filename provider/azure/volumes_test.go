@@ -0,0 +1,338 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package azure
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"launchpad.net/gwacl"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider/registry"
+	"github.com/juju/juju/testing"
+)
+
+type volumeSuite struct {
+	testing.BaseSuite
+	env     *azureEnviron
+	service *gwacl.HostedService
+	role    *gwacl.Role
+	instId  instance.Id
+	source  *azureVolumeSource
+}
+
+var _ = gc.Suite(&volumeSuite{})
+
+func (s *volumeSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.env = makeEnviron(c)
+	prefix := s.env.getEnvPrefix()
+	s.service = makeDeployment(s.env, prefix+"service")
+	s.role = &s.service.Deployments[0].RoleList[0]
+	s.role.RoleName = "roleone"
+	s.instId = instance.Id(prefix + "service-roleone")
+	s.source = &azureVolumeSource{env: s.env}
+}
+
+// prepareRoleConversation returns the responses to a conversation
+// in which the role is queried, and then optionally updated.
+func (s *volumeSuite) prepareRoleConversation(c *gc.C, update bool, disks ...gwacl.DataVirtualHardDisk) []gwacl.DispatcherResponse {
+	responses := prepareInstancesResponses(c, s.env.getEnvPrefix(), s.service)
+	persistentRole := &gwacl.PersistentVMRole{
+		XMLNS:                gwacl.XMLNS,
+		RoleName:             s.role.RoleName,
+		ConfigurationSets:    s.role.ConfigurationSets,
+		DataVirtualHardDisks: disks,
+	}
+	responses = append(responses, gwacl.NewDispatcherResponse(serialize(c, persistentRole), http.StatusOK, nil))
+	if update {
+		responses = append(responses, gwacl.NewDispatcherResponse(nil, http.StatusOK, nil))
+	}
+	return responses
+}
+
+// prepareAttachConversation returns the responses to a conversation
+// in which the instances are queried, then the disks listed, and then
+// the role queried and optionally updated.
+func (s *volumeSuite) prepareAttachConversation(c *gc.C, update bool, disks ...gwacl.DataVirtualHardDisk) []gwacl.DispatcherResponse {
+	responses := s.prepareRoleConversation(c, update, disks...)
+	n := len(prepareInstancesResponses(c, s.env.getEnvPrefix(), s.service))
+	result := append([]gwacl.DispatcherResponse{}, responses[:n]...)
+	result = append(result, s.prepareDisksResponse())
+	return append(result, responses[n:]...)
+}
+
+func (s *volumeSuite) volumeParams() storage.VolumeParams {
+	return storage.VolumeParams{
+		Tag:      names.NewVolumeTag("0"),
+		Size:     2000,
+		Provider: AzureProviderType,
+		Attachment: &storage.VolumeAttachmentParams{
+			AttachmentParams: storage.AttachmentParams{
+				Machine:    names.NewMachineTag("1"),
+				InstanceId: s.instId,
+			},
+		},
+	}
+}
+
+func (s *volumeSuite) attachmentParams(volumeId string) storage.VolumeAttachmentParams {
+	return storage.VolumeAttachmentParams{
+		AttachmentParams: storage.AttachmentParams{
+			Machine:    names.NewMachineTag("1"),
+			InstanceId: s.instId,
+		},
+		Volume:   names.NewVolumeTag("0"),
+		VolumeId: volumeId,
+	}
+}
+
+func (s *volumeSuite) TestProviderRegistered(c *gc.C) {
+	p, err := registry.StorageProvider(AzureProviderType)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(p, gc.FitsTypeOf, &azureStorageProvider{})
+	c.Assert(registry.IsProviderSupported("azure", AzureProviderType), jc.IsTrue)
+}
+
+func (s *volumeSuite) TestValidateConfig(c *gc.C) {
+	p := &azureStorageProvider{}
+	cfg, err := storage.NewConfig("azure", AzureProviderType, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(p.ValidateConfig(cfg), jc.ErrorIsNil)
+
+	cfg, err = storage.NewConfig("azure", AzureProviderType, map[string]interface{}{
+		"persistent": false,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(p.ValidateConfig(cfg), gc.ErrorMatches, "cannot create a non-persistent Azure data disk")
+}
+
+func (s *volumeSuite) TestSupports(c *gc.C) {
+	p := &azureStorageProvider{}
+	c.Assert(p.Supports(storage.StorageKindBlock), jc.IsTrue)
+	c.Assert(p.Supports(storage.StorageKindFilesystem), jc.IsFalse)
+}
+
+func (s *volumeSuite) TestVolumeSource(c *gc.C) {
+	p := &azureStorageProvider{}
+	cfg, err := storage.NewConfig("azure", AzureProviderType, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	source, err := p.VolumeSource(s.env.Config(), cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(source, gc.FitsTypeOf, &azureVolumeSource{})
+}
+
+func (s *volumeSuite) TestValidateVolumeParamsTooLarge(c *gc.C) {
+	params := s.volumeParams()
+	params.Size = 2000 * 1024
+	err := s.source.ValidateVolumeParams(params)
+	c.Check(err, gc.ErrorMatches, "2000 GB exceeds the maximum of 1023 GB")
+}
+
+func (s *volumeSuite) TestValidateVolumeParamsNoAttachment(c *gc.C) {
+	params := s.volumeParams()
+	params.Attachment = nil
+	err := s.source.ValidateVolumeParams(params)
+	c.Check(err, gc.ErrorMatches, "cannot create volume-0: Azure data disks must be created attached to an instance")
+}
+
+func (s *volumeSuite) TestCreateVolumes(c *gc.C) {
+	existing := gwacl.DataVirtualHardDisk{
+		MediaLink: s.source.mediaLink("existing.vhd"),
+		LUN:       0,
+	}
+	requests := gwacl.PatchManagementAPIResponses(s.prepareRoleConversation(c, true, existing))
+
+	volumes, attachments, err := s.source.CreateVolumes([]storage.VolumeParams{s.volumeParams()})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumes, gc.HasLen, 1)
+	volumeId := volumes[0].VolumeId
+	c.Check(volumeId, jc.HasPrefix, "juju")
+	c.Check(volumes[0], jc.DeepEquals, storage.Volume{
+		Tag: names.NewVolumeTag("0"),
+		VolumeInfo: storage.VolumeInfo{
+			VolumeId:   volumeId,
+			Size:       2048,
+			Persistent: true,
+		},
+	})
+	c.Check(attachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:  names.NewVolumeTag("0"),
+		Machine: names.NewMachineTag("1"),
+		VolumeAttachmentInfo: storage.VolumeAttachmentInfo{
+			BusAddress: "scsi@5:0.0.1",
+		},
+	}})
+
+	c.Assert(*requests, gc.HasLen, 4)
+	update := (*requests)[3]
+	c.Check(update.Method, gc.Equals, "PUT")
+	c.Check(update.URL, gc.Matches, ".*/deployments/.*-v2/roles/roleone")
+	var role gwacl.PersistentVMRole
+	err = role.Deserialize(update.Payload)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(role.DataVirtualHardDisks, gc.HasLen, 2)
+	c.Check(role.DataVirtualHardDisks[1], jc.DeepEquals, gwacl.DataVirtualHardDisk{
+		MediaLink:           s.source.mediaLink(volumeId),
+		LogicalDiskSizeInGB: 2,
+		LUN:                 1,
+	})
+}
+
+func (s *volumeSuite) TestCreateVolumesInstanceNotRunning(c *gc.C) {
+	gwacl.PatchManagementAPIResponses(prepareInstancesResponses(c, s.env.getEnvPrefix()))
+	_, _, err := s.source.CreateVolumes([]storage.VolumeParams{s.volumeParams()})
+	c.Check(err, gc.ErrorMatches, "querying instance details: volumes can only be attached to running instances, these instances are not running: .*-service-roleone")
+}
+
+func (s *volumeSuite) TestCreateVolumesNoFreeLUNs(c *gc.C) {
+	disks := make([]gwacl.DataVirtualHardDisk, maxDataDiskLUN)
+	for i := range disks {
+		disks[i] = gwacl.DataVirtualHardDisk{MediaLink: s.source.mediaLink(fmt.Sprint(i)), LUN: i}
+	}
+	gwacl.PatchManagementAPIResponses(s.prepareRoleConversation(c, false, disks...))
+	_, _, err := s.source.CreateVolumes([]storage.VolumeParams{s.volumeParams()})
+	c.Check(err, gc.ErrorMatches, "creating volume volume-0: no free LUNs on .*-service-roleone")
+}
+
+func (s *volumeSuite) TestAttachVolumes(c *gc.C) {
+	existing := gwacl.DataVirtualHardDisk{
+		MediaLink: s.source.mediaLink("existing.vhd"),
+		LUN:       0,
+	}
+	requests := gwacl.PatchManagementAPIResponses(s.prepareAttachConversation(c, true, existing))
+
+	attachments, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{
+		s.attachmentParams("juju-vol.vhd"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(attachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:  names.NewVolumeTag("0"),
+		Machine: names.NewMachineTag("1"),
+		VolumeAttachmentInfo: storage.VolumeAttachmentInfo{
+			BusAddress: "scsi@5:0.0.1",
+		},
+	}})
+
+	// The registered disk is attached by name, rather than by
+	// media link, which would create a new disk from the blob.
+	c.Assert(*requests, gc.HasLen, 5)
+	update := (*requests)[4]
+	c.Check(update.Method, gc.Equals, "PUT")
+	var role gwacl.PersistentVMRole
+	err = role.Deserialize(update.Payload)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(role.DataVirtualHardDisks, gc.HasLen, 2)
+	c.Check(role.DataVirtualHardDisks[1], jc.DeepEquals, gwacl.DataVirtualHardDisk{
+		DiskName: "disk-one",
+		LUN:      1,
+	})
+}
+
+func (s *volumeSuite) TestAttachVolumesNotFound(c *gc.C) {
+	gwacl.PatchManagementAPIResponses(s.prepareAttachConversation(c, false))
+	_, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{
+		s.attachmentParams("juju-other.vhd"),
+	})
+	c.Check(err, gc.ErrorMatches, `volume "juju-other.vhd" not found`)
+}
+
+func (s *volumeSuite) TestAttachVolumesAlreadyAttached(c *gc.C) {
+	attached := gwacl.DataVirtualHardDisk{
+		DiskName:  "disk-one",
+		MediaLink: s.source.mediaLink("juju-vol.vhd"),
+		LUN:       3,
+	}
+	requests := gwacl.PatchManagementAPIResponses(s.prepareAttachConversation(c, false, attached))
+
+	attachments, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{
+		s.attachmentParams("juju-vol.vhd"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(attachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:  names.NewVolumeTag("0"),
+		Machine: names.NewMachineTag("1"),
+		VolumeAttachmentInfo: storage.VolumeAttachmentInfo{
+			BusAddress: "scsi@5:0.0.3",
+		},
+	}})
+	// No UpdateRole request is made.
+	c.Check(*requests, gc.HasLen, 4)
+}
+
+func (s *volumeSuite) TestDetachVolumes(c *gc.C) {
+	disks := []gwacl.DataVirtualHardDisk{
+		{MediaLink: s.source.mediaLink("juju-vol.vhd"), LUN: 0},
+		{MediaLink: s.source.mediaLink("juju-other.vhd"), LUN: 1},
+	}
+	requests := gwacl.PatchManagementAPIResponses(s.prepareRoleConversation(c, true, disks...))
+
+	err := s.source.DetachVolumes([]storage.VolumeAttachmentParams{
+		s.attachmentParams("juju-vol.vhd"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(*requests, gc.HasLen, 4)
+	var role gwacl.PersistentVMRole
+	err = role.Deserialize((*requests)[3].Payload)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(role.DataVirtualHardDisks, jc.DeepEquals, disks[1:])
+}
+
+func (s *volumeSuite) TestDetachVolumesNotAttached(c *gc.C) {
+	requests := gwacl.PatchManagementAPIResponses(s.prepareRoleConversation(c, false))
+	err := s.source.DetachVolumes([]storage.VolumeAttachmentParams{
+		s.attachmentParams("juju-vol.vhd"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(*requests, gc.HasLen, 3)
+}
+
+const disksResponse = `<Disks xmlns="http://schemas.microsoft.com/windowsazure">
+  <Disk>
+    <LogicalDiskSizeInGB>10</LogicalDiskSizeInGB>
+    <MediaLink>%s</MediaLink>
+    <Name>disk-one</Name>
+  </Disk>
+</Disks>`
+
+func (s *volumeSuite) prepareDisksResponse() gwacl.DispatcherResponse {
+	body := fmt.Sprintf(disksResponse, s.source.mediaLink("juju-vol.vhd"))
+	return gwacl.NewDispatcherResponse([]byte(body), http.StatusOK, nil)
+}
+
+func (s *volumeSuite) TestDescribeVolumes(c *gc.C) {
+	gwacl.PatchManagementAPIResponses([]gwacl.DispatcherResponse{s.prepareDisksResponse()})
+	volumes, err := s.source.DescribeVolumes([]string{"juju-vol.vhd"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(volumes, jc.DeepEquals, []storage.VolumeInfo{{
+		VolumeId:   "juju-vol.vhd",
+		Size:       10240,
+		Persistent: true,
+	}})
+}
+
+func (s *volumeSuite) TestDescribeVolumesNotFound(c *gc.C) {
+	gwacl.PatchManagementAPIResponses([]gwacl.DispatcherResponse{s.prepareDisksResponse()})
+	_, err := s.source.DescribeVolumes([]string{"juju-other.vhd"})
+	c.Check(err, gc.ErrorMatches, `volume "juju-other.vhd" not found`)
+}
+
+func (s *volumeSuite) TestDestroyVolumes(c *gc.C) {
+	requests := gwacl.PatchManagementAPIResponses([]gwacl.DispatcherResponse{
+		s.prepareDisksResponse(),
+		gwacl.NewDispatcherResponse(nil, http.StatusOK, nil),
+	})
+	errs := s.source.DestroyVolumes([]string{"juju-vol.vhd", "juju-gone.vhd"})
+	c.Check(errs, jc.DeepEquals, []error{nil, nil})
+
+	c.Assert(*requests, gc.HasLen, 2)
+	c.Check((*requests)[1].Method, gc.Equals, "DELETE")
+	c.Check((*requests)[1].URL, gc.Matches, ".*/services/disks/disk-one\\?comp=media")
+}
@@ -32,6 +32,13 @@ amazon:
     #
     # secret-key: <secret>
 
+    # instance-types-url is the URL of a JSON catalogue of instance
+    # types and their prices in each region. The catalogue is merged
+    # over juju's built-in one, so that new instance types may be used
+    # without upgrading juju, and is refreshed hourly.
+    #
+    # instance-types-url: https://example.com/ec2-instance-types.json
+
     # image-stream chooses a simplestreams stream from which to select
     # OS images, for example daily or released images (or any other stream
     # available on simplestreams).
@@ -84,6 +91,10 @@ var configSchema = environschema.Fields{
 		Description: "The S3 bucket used to store environment metadata",
 		Type:        environschema.Tstring,
 	},
+	"instance-types-url": {
+		Description: "The URL of a JSON catalogue of EC2 instance types and prices, merged over the built-in catalogue and refreshed hourly",
+		Type:        environschema.Tstring,
+	},
 }
 
 var configFields = func() schema.Fields {
@@ -95,10 +106,11 @@ var configFields = func() schema.Fields {
 }()
 
 var configDefaults = schema.Defaults{
-	"access-key":     "",
-	"secret-key":     "",
-	"region":         "us-east-1",
-	"control-bucket": "",
+	"access-key":         "",
+	"secret-key":         "",
+	"region":             "us-east-1",
+	"control-bucket":     "",
+	"instance-types-url": "",
 }
 
 type environConfig struct {
@@ -114,6 +126,10 @@ func (c *environConfig) controlBucket() string {
 	return c.attrs["control-bucket"].(string)
 }
 
+func (c *environConfig) instanceTypesURL() string {
+	return c.attrs["instance-types-url"].(string)
+}
+
 func (c *environConfig) accessKey() string {
 	return c.attrs["access-key"].(string)
 }
@@ -231,6 +231,18 @@ var configTests = []configTest{
 			"ssl-hostname-verification": false,
 		},
 		err: ".*disabling ssh-hostname-verification is not supported",
+	}, {
+		config: attrs{
+			"instance-types-url": "https://example.com/instance-types.json",
+		},
+		expect: attrs{
+			"instance-types-url": "https://example.com/instance-types.json",
+		},
+	}, {
+		config: attrs{
+			"instance-types-url": 42,
+		},
+		err: `.*expected string, got int\(42\)`,
 	}, {
 		config: attrs{
 			"future": "hammerstein",
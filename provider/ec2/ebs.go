@@ -279,6 +279,7 @@ func (v *ebsVolumeSource) CreateVolumes(params []storage.VolumeParams) (_ []stor
 				// volumes within Juju, we should not mark any
 				// EBS volumes as persistent.
 				Persistent: persistent,
+				Zone:       resp.AvailZone,
 			},
 		})
 
@@ -329,6 +330,7 @@ func (v *ebsVolumeSource) DescribeVolumes(volIds []string) ([]storage.VolumeInfo
 		vols[i] = storage.VolumeInfo{
 			Size:     gibToMib(uint64(vol.Size)),
 			VolumeId: vol.Id,
+			Zone:     vol.AvailZone,
 		}
 		for _, attachment := range vol.Attachments {
 			if !attachment.DeleteOnTermination {
@@ -159,6 +159,7 @@ func (s *ebsVolumeSuite) assertCreateVolumes(c *gc.C, vs storage.VolumeSource, i
 			Size:       10240,
 			VolumeId:   "vol-0",
 			Persistent: true,
+			Zone:       volumeZone(c, vs, "vol-0"),
 		},
 	}, {
 		names.NewVolumeTag("1"),
@@ -166,6 +167,7 @@ func (s *ebsVolumeSuite) assertCreateVolumes(c *gc.C, vs storage.VolumeSource, i
 			Size:       20480,
 			VolumeId:   "vol-1",
 			Persistent: true,
+			Zone:       volumeZone(c, vs, "vol-1"),
 		},
 	}, {
 		names.NewVolumeTag("2"),
@@ -173,6 +175,7 @@ func (s *ebsVolumeSuite) assertCreateVolumes(c *gc.C, vs storage.VolumeSource, i
 			Size:       30720,
 			VolumeId:   "vol-2",
 			Persistent: false,
+			Zone:       volumeZone(c, vs, "vol-2"),
 		},
 	}})
 	ec2Client := ec2.StorageEC2(vs)
@@ -185,6 +188,15 @@ func (s *ebsVolumeSuite) assertCreateVolumes(c *gc.C, vs storage.VolumeSource, i
 	c.Assert(ec2Vols.Volumes[2].Size, gc.Equals, 30)
 }
 
+// volumeZone returns the availability zone in which the EC2 test
+// server created the volume with the given ID.
+func volumeZone(c *gc.C, vs storage.VolumeSource, volumeId string) string {
+	resp, err := ec2.StorageEC2(vs).Volumes([]string{volumeId}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resp.Volumes, gc.HasLen, 1)
+	return resp.Volumes[0].AvailZone
+}
+
 type volumeSorter struct {
 	vols []awsec2.Volume
 	less func(i, j awsec2.Volume) bool
@@ -224,6 +236,7 @@ func (s *ebsVolumeSuite) TestVolumeTags(c *gc.C) {
 			Size:       10240,
 			VolumeId:   "vol-0",
 			Persistent: true,
+			Zone:       volumeZone(c, vs, "vol-0"),
 		},
 	}, {
 		names.NewVolumeTag("1"),
@@ -231,6 +244,7 @@ func (s *ebsVolumeSuite) TestVolumeTags(c *gc.C) {
 			Size:       20480,
 			VolumeId:   "vol-1",
 			Persistent: true,
+			Zone:       volumeZone(c, vs, "vol-1"),
 		},
 	}, {
 		names.NewVolumeTag("2"),
@@ -238,6 +252,7 @@ func (s *ebsVolumeSuite) TestVolumeTags(c *gc.C) {
 			Size:       30720,
 			VolumeId:   "vol-2",
 			Persistent: false,
+			Zone:       volumeZone(c, vs, "vol-2"),
 		},
 	}})
 	ec2Client := ec2.StorageEC2(vs)
@@ -325,9 +340,11 @@ func (s *ebsVolumeSuite) TestVolumes(c *gc.C) {
 	c.Assert(vols, jc.SameContents, []storage.VolumeInfo{{
 		Size:     10240,
 		VolumeId: "vol-0",
+		Zone:     volumeZone(c, vs, "vol-0"),
 	}, {
 		Size:     20480,
 		VolumeId: "vol-1",
+		Zone:     volumeZone(c, vs, "vol-1"),
 	}})
 }
 
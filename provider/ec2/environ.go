@@ -6,6 +6,7 @@ package ec2
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -83,6 +84,8 @@ var _ environs.NetworkingEnviron = (*environ)(nil)
 var _ simplestreams.HasRegion = (*environ)(nil)
 var _ state.Prechecker = (*environ)(nil)
 var _ state.InstanceDistributor = (*environ)(nil)
+var _ environs.InstanceTagger = (*environ)(nil)
+var _ environs.QuotaReporter = (*environ)(nil)
 
 type defaultVpc struct {
 	hasDefaultVpc bool
@@ -252,8 +255,9 @@ func (e *environ) ConstraintsValidator() (constraints.Validator, error) {
 		return nil, err
 	}
 	validator.RegisterVocabulary(constraints.Arch, supportedArches)
-	instTypeNames := make([]string, len(allInstanceTypes))
-	for i, itype := range allInstanceTypes {
+	itypes, _ := e.instanceTypes()
+	instTypeNames := make([]string, len(itypes))
+	for i, itype := range itypes {
 		instTypeNames[i] = itype.Name
 	}
 	validator.RegisterVocabulary(constraints.InstanceType, instTypeNames)
@@ -326,30 +330,53 @@ func (e *environ) InstanceAvailabilityZoneNames(ids []instance.Id) ([]string, er
 
 type ec2Placement struct {
 	availabilityZone ec2.AvailabilityZoneInfo
+
+	// spotPrice, if non-empty, is the maximum hourly price to bid
+	// for a spot instance.
+	spotPrice string
 }
 
+// parsePlacement parses a placement made up of comma-separated
+// directives, e.g. "zone=us-east-1a,spot-price=0.05".
 func (e *environ) parsePlacement(placement string) (*ec2Placement, error) {
-	pos := strings.IndexRune(placement, '=')
-	if pos == -1 {
-		return nil, fmt.Errorf("unknown placement directive: %v", placement)
-	}
-	switch key, value := placement[:pos], placement[pos+1:]; key {
-	case "zone":
-		availabilityZone := value
-		zones, err := e.AvailabilityZones()
-		if err != nil {
-			return nil, err
+	var result ec2Placement
+	for _, directive := range strings.Split(placement, ",") {
+		pos := strings.IndexRune(directive, '=')
+		if pos == -1 {
+			return nil, fmt.Errorf("unknown placement directive: %v", directive)
 		}
-		for _, z := range zones {
-			if z.Name() == availabilityZone {
-				return &ec2Placement{
-					z.(*ec2AvailabilityZone).AvailabilityZoneInfo,
-				}, nil
+		switch key, value := directive[:pos], directive[pos+1:]; key {
+		case "zone":
+			zone, err := e.availabilityZone(value)
+			if err != nil {
+				return nil, err
+			}
+			result.availabilityZone = zone
+		case spotPriceDirective:
+			spotPrice, err := parseSpotPrice(value)
+			if err != nil {
+				return nil, err
 			}
+			result.spotPrice = spotPrice
+		default:
+			return nil, fmt.Errorf("unknown placement directive: %v", directive)
 		}
-		return nil, fmt.Errorf("invalid availability zone %q", availabilityZone)
 	}
-	return nil, fmt.Errorf("unknown placement directive: %v", placement)
+	return &result, nil
+}
+
+// availabilityZone returns the named availability zone.
+func (e *environ) availabilityZone(name string) (ec2.AvailabilityZoneInfo, error) {
+	zones, err := e.AvailabilityZones()
+	if err != nil {
+		return ec2.AvailabilityZoneInfo{}, err
+	}
+	for _, z := range zones {
+		if z.Name() == name {
+			return z.(*ec2AvailabilityZone).AvailabilityZoneInfo, nil
+		}
+	}
+	return ec2.AvailabilityZoneInfo{}, fmt.Errorf("invalid availability zone %q", name)
 }
 
 // PrecheckInstance is defined on the state.Prechecker interface.
@@ -363,7 +390,8 @@ func (e *environ) PrecheckInstance(series string, cons constraints.Value, placem
 		return nil
 	}
 	// Constraint has an instance-type constraint so let's see if it is valid.
-	for _, itype := range allInstanceTypes {
+	itypes, _ := e.instanceTypes()
+	for _, itype := range itypes {
 		if itype.Name != *cons.InstanceType {
 			continue
 		}
@@ -446,15 +474,32 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 	}()
 
 	var availabilityZones []string
+	var spotPrice string
 	if args.Placement != "" {
 		placement, err := e.parsePlacement(args.Placement)
 		if err != nil {
 			return nil, err
 		}
-		if placement.availabilityZone.State != "available" {
-			return nil, errors.Errorf("availability zone %q is %s", placement.availabilityZone.Name, placement.availabilityZone.State)
+		if placement.availabilityZone.Name != "" {
+			if placement.availabilityZone.State != "available" {
+				return nil, errors.Errorf("availability zone %q is %s", placement.availabilityZone.Name, placement.availabilityZone.State)
+			}
+			availabilityZones = append(availabilityZones, placement.availabilityZone.Name)
+		}
+		spotPrice = placement.spotPrice
+	}
+
+	// Volumes can only be attached to instances in the same zone,
+	// so the instance must be started in the zone of any existing
+	// volumes that are to be attached to it.
+	if args.AvailabilityZone != "" {
+		if len(availabilityZones) > 0 && availabilityZones[0] != args.AvailabilityZone {
+			return nil, errors.Errorf(
+				"cannot start instance in availability zone %q: volumes to be attached are in availability zone %q",
+				availabilityZones[0], args.AvailabilityZone,
+			)
 		}
-		availabilityZones = append(availabilityZones, placement.availabilityZone.Name)
+		availabilityZones = []string{args.AvailabilityZone}
 	}
 
 	// If no availability zone is specified, then automatically spread across
@@ -491,7 +536,8 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 	}
 
 	series := args.Tools.OneSeries()
-	spec, err := findInstanceSpec(sources, e.Config().ImageStream(), &instances.InstanceConstraint{
+	itypes, costs := e.instanceTypes()
+	spec, err := findInstanceSpec(sources, e.Config().ImageStream(), itypes, costs, &instances.InstanceConstraint{
 		Region:      e.ecfg().region(),
 		Series:      series,
 		Arches:      arches,
@@ -530,7 +576,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 	rootDiskSize := uint64(blockDeviceMappings[0].VolumeSize) * 1024
 
 	for _, availZone := range availabilityZones {
-		instResp, err = runInstances(e.ec2(), &ec2.RunInstances{
+		ri := &ec2.RunInstances{
 			AvailZone:           availZone,
 			ImageId:             spec.Image.Id,
 			MinCount:            1,
@@ -539,8 +585,13 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 			InstanceType:        spec.InstanceType.Name,
 			SecurityGroups:      groups,
 			BlockDeviceMappings: blockDeviceMappings,
-		})
-		if isZoneConstrainedError(err) {
+		}
+		if spotPrice != "" {
+			instResp, err = runSpotInstance(e.ec2(), ri, spotPrice)
+		} else {
+			instResp, err = runInstances(e.ec2(), ri)
+		}
+		if isZoneConstrainedError(err) || isSpotZoneConstrainedError(err) {
 			logger.Infof("%q is constrained, trying another availability zone", availZone)
 		} else {
 			break
@@ -609,6 +660,68 @@ func tagResources(e *ec2.EC2, tags map[string]string, resourceIds ...string) err
 	return err
 }
 
+// TagInstance implements environs.InstanceTagger. The EBS volumes
+// attached to the instance are tagged along with it.
+func (e *environ) TagInstance(id instance.Id, tags map[string]string) error {
+	ec2inst := e.ec2()
+	filter := ec2.NewFilter()
+	filter.Add("attachment.instance-id", string(id))
+	resp, err := ec2inst.Volumes(nil, filter)
+	if err != nil {
+		return errors.Annotate(err, "querying attached volumes")
+	}
+	resourceIds := []string{string(id)}
+	for _, vol := range resp.Volumes {
+		resourceIds = append(resourceIds, vol.Id)
+	}
+	if err := tagResources(ec2inst, tags, resourceIds...); err != nil {
+		return errors.Annotate(err, "tagging instance")
+	}
+	return nil
+}
+
+// maxInstancesAttribute is the account attribute holding the maximum
+// number of on-demand instances that may run in the region.
+const maxInstancesAttribute = "max-instances"
+
+var ec2AccountAttributes = (*ec2.EC2).AccountAttributes
+
+// Quotas implements environs.QuotaReporter. The remaining instances are
+// those allowed by the account's max-instances attribute, less those
+// pending or running in the region, whether or not they belong to the
+// environment. EC2 does not report limits for cores or volumes.
+func (e *environ) Quotas() (environs.Quotas, error) {
+	var quotas environs.Quotas
+	resp, err := ec2AccountAttributes(e.ec2(), maxInstancesAttribute)
+	if err != nil {
+		return quotas, errors.Annotate(err, "getting account attributes")
+	}
+	maxInstances := -1
+	for _, attr := range resp.Attributes {
+		if attr.Name != maxInstancesAttribute || len(attr.Values) == 0 {
+			continue
+		}
+		if maxInstances, err = strconv.Atoi(attr.Values[0]); err != nil {
+			return quotas, errors.Annotatef(err, "parsing %s", maxInstancesAttribute)
+		}
+	}
+	if maxInstances < 0 {
+		return quotas, nil
+	}
+	filter := ec2.NewFilter()
+	filter.Add("instance-state-name", "pending", "running")
+	instances, err := e.ec2().Instances(nil, filter)
+	if err != nil {
+		return quotas, errors.Annotate(err, "getting instances")
+	}
+	remaining := maxInstances
+	for _, r := range instances.Reservations {
+		remaining -= len(r.Instances)
+	}
+	quotas.Instances = &remaining
+	return quotas, nil
+}
+
 var runInstances = _runInstances
 
 // runInstances calls ec2.RunInstances for a fixed number of attempts until
@@ -743,12 +856,23 @@ func (e *environ) Instances(ids []instance.Id) ([]instance.Instance, error) {
 		}
 	}
 	if err == environs.ErrPartialInstances {
+		// Instances that are no longer running may have been
+		// interrupted spot instances; report them as such, so
+		// the interruption is visible on their machines.
+		e.gatherSpotInterruptions(ids, insts)
+		missing := 0
 		for _, inst := range insts {
-			if inst != nil {
-				return insts, environs.ErrPartialInstances
+			if inst == nil {
+				missing++
 			}
 		}
-		return nil, environs.ErrNoInstances
+		switch missing {
+		case 0:
+			return insts, nil
+		case len(insts):
+			return nil, environs.ErrNoInstances
+		}
+		return insts, environs.ErrPartialInstances
 	}
 	if err != nil {
 		return nil, err
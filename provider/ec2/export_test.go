@@ -52,8 +52,10 @@ func InstanceEC2(inst instance.Instance) *ec2.Instance {
 
 var (
 	EC2AvailabilityZones        = &ec2AvailabilityZones
+	EC2AccountAttributes        = &ec2AccountAttributes
 	AvailabilityZoneAllocations = &availabilityZoneAllocations
 	RunInstances                = &runInstances
+	RunSpotInstance             = &runSpotInstance
 	BlockDeviceNamer            = blockDeviceNamer
 	GetBlockDeviceMappings      = getBlockDeviceMappings
 )
@@ -124,6 +126,12 @@ func EC2ErrCode(err error) string {
 	return ec2ErrCode(err)
 }
 
+// NewSpotRequestError returns the error returned when a spot request
+// cannot be fulfilled.
+func NewSpotRequestError(requestId, code, message string) error {
+	return &spotRequestError{requestId, code, message}
+}
+
 // FabricateInstance creates a new fictitious instance
 // given an existing instance and a new id.
 func FabricateInstance(inst instance.Instance, newId string) instance.Instance {
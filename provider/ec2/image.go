@@ -43,9 +43,13 @@ func filterImages(images []*imagemetadata.ImageMetadata, ic *instances.InstanceC
 	return nil
 }
 
-// findInstanceSpec returns an InstanceSpec satisfying the supplied instanceConstraint.
+// findInstanceSpec returns an InstanceSpec satisfying the supplied
+// instanceConstraint, choosing from the given instance types.
 func findInstanceSpec(
-	sources []simplestreams.DataSource, stream string, ic *instances.InstanceConstraint) (*instances.InstanceSpec, error) {
+	sources []simplestreams.DataSource, stream string,
+	itypes []instances.InstanceType, costs regionCosts,
+	ic *instances.InstanceConstraint,
+) (*instances.InstanceSpec, error) {
 
 	if ic.Constraints.CpuPower == nil {
 		ic.Constraints.CpuPower = instances.CpuPower(defaultCpuPower)
@@ -68,13 +72,13 @@ func findInstanceSpec(
 	images := instances.ImageMetadataToImages(suitableImages)
 
 	// Make a copy of the known EC2 instance types, filling in the cost for the specified region.
-	regionCosts := allRegionCosts[ic.Region]
-	if len(regionCosts) == 0 && len(allRegionCosts) > 0 {
+	regionCosts := costs[ic.Region]
+	if len(regionCosts) == 0 && len(costs) > 0 {
 		return nil, fmt.Errorf("no instance types found in %s", ic.Region)
 	}
 
 	var itypesWithCosts []instances.InstanceType
-	for _, itype := range allInstanceTypes {
+	for _, itype := range itypes {
 		cost, ok := regionCosts[itype.Name]
 		if !ok {
 			continue
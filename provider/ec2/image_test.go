@@ -160,6 +160,8 @@ func (s *specSuite) TestFindInstanceSpec(c *gc.C) {
 			[]simplestreams.DataSource{
 				simplestreams.NewURLDataSource("test", "test:", utils.VerifySSLHostnames)},
 			"released",
+			allInstanceTypes,
+			allRegionCosts,
 			&instances.InstanceConstraint{
 				Region:      "test",
 				Series:      test.series,
@@ -202,6 +204,8 @@ func (s *specSuite) TestFindInstanceSpecErrors(c *gc.C) {
 			[]simplestreams.DataSource{
 				simplestreams.NewURLDataSource("test", "test:", utils.VerifySSLHostnames)},
 			"released",
+			allInstanceTypes,
+			allRegionCosts,
 			&instances.InstanceConstraint{
 				Region:      "test",
 				Series:      t.series,
@@ -19,6 +19,11 @@ type ec2Instance struct {
 
 	mu sync.Mutex
 	*ec2.Instance
+
+	// spotStatus holds the status code of the spot request for
+	// which the instance was launched, if the instance was
+	// interrupted by EC2.
+	spotStatus string
 }
 
 func (inst *ec2Instance) String() string {
@@ -38,7 +43,11 @@ func (inst *ec2Instance) Id() instance.Id {
 }
 
 func (inst *ec2Instance) Status() string {
-	return inst.getInstance().State.Name
+	status := inst.getInstance().State.Name
+	if inst.spotStatus != "" {
+		status = fmt.Sprintf("%s (%s: %s)", status, spotInterruptedStatus, inst.spotStatus)
+	}
+	return status
 }
 
 // Refresh implements instance.Refresh(), requerying the
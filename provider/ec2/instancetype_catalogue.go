@@ -0,0 +1,270 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs/instances"
+)
+
+// instanceTypesRefreshInterval is how long an instance type catalogue
+// fetched from an instance-types-url is used for before it is fetched
+// again.
+var instanceTypesRefreshInterval = time.Hour
+
+// instanceTypesFetchTimeout bounds how long fetching an instance type
+// catalogue may take.
+var instanceTypesFetchTimeout = 30 * time.Second
+
+// instanceTypeCatalogue is the JSON document served at an
+// instance-types-url. Its instance types and costs are merged over
+// the built-in ones: entries for instance types that juju already
+// knows about replace the built-in entries, and new ones are added.
+//
+// For example:
+//
+//	{
+//	    "instance-types": [{
+//	        "name": "m4.large",
+//	        "arches": ["amd64"],
+//	        "cpu-cores": 2,
+//	        "cpu-power": 650,
+//	        "mem": 8192,
+//	        "virt-type": "hvm"
+//	    }],
+//	    "region-costs": {
+//	        "us-east-1": {"m4.large": 126}
+//	    }
+//	}
+type instanceTypeCatalogue struct {
+	InstanceTypes []catalogueInstanceType `json:"instance-types"`
+
+	// RegionCosts holds the cost in USDe-3/hour of instance types
+	// in each region. An instance type is only available in the
+	// regions in which it has a cost.
+	RegionCosts regionCosts `json:"region-costs"`
+}
+
+type catalogueInstanceType struct {
+	Name     string   `json:"name"`
+	Arches   []string `json:"arches"`
+	CpuCores uint64   `json:"cpu-cores"`
+	CpuPower *uint64  `json:"cpu-power,omitempty"`
+	Mem      uint64   `json:"mem"`
+	VirtType string   `json:"virt-type,omitempty"`
+}
+
+func (t catalogueInstanceType) validate() error {
+	if t.Name == "" {
+		return errors.New("instance type with no name")
+	}
+	if len(t.Arches) == 0 {
+		return errors.Errorf("instance type %q has no arches", t.Name)
+	}
+	if t.CpuCores == 0 || t.Mem == 0 {
+		return errors.Errorf("instance type %q has no cpu-cores or mem", t.Name)
+	}
+	switch t.VirtType {
+	case "", paravirtual, hvm:
+	default:
+		return errors.Errorf("instance type %q has unknown virt-type %q", t.Name, t.VirtType)
+	}
+	return nil
+}
+
+func (t catalogueInstanceType) instanceType() instances.InstanceType {
+	itype := instances.InstanceType{
+		Name:     t.Name,
+		Arches:   t.Arches,
+		CpuCores: t.CpuCores,
+		CpuPower: t.CpuPower,
+		Mem:      t.Mem,
+	}
+	if t.VirtType != "" {
+		virtType := t.VirtType
+		itype.VirtType = &virtType
+	}
+	return itype
+}
+
+// merge returns the given instance types and costs, with those in
+// the catalogue merged over them. The arguments are not modified.
+func (c *instanceTypeCatalogue) merge(itypes []instances.InstanceType, costs regionCosts) ([]instances.InstanceType, regionCosts) {
+	if c == nil {
+		return itypes, costs
+	}
+	merged := make([]instances.InstanceType, len(itypes), len(itypes)+len(c.InstanceTypes))
+	copy(merged, itypes)
+	index := make(map[string]int)
+	for i, itype := range merged {
+		index[itype.Name] = i
+	}
+	for _, t := range c.InstanceTypes {
+		if i, ok := index[t.Name]; ok {
+			merged[i] = t.instanceType()
+			continue
+		}
+		index[t.Name] = len(merged)
+		merged = append(merged, t.instanceType())
+	}
+
+	mergedCosts := make(regionCosts)
+	for region, typeCosts := range costs {
+		mergedCosts[region] = make(instanceTypeCost)
+		for name, cost := range typeCosts {
+			mergedCosts[region][name] = cost
+		}
+	}
+	for region, typeCosts := range c.RegionCosts {
+		if mergedCosts[region] == nil {
+			mergedCosts[region] = make(instanceTypeCost)
+		}
+		for name, cost := range typeCosts {
+			mergedCosts[region][name] = cost
+		}
+	}
+	return merged, mergedCosts
+}
+
+var fetchInstanceTypeCatalogue = _fetchInstanceTypeCatalogue
+
+// _fetchInstanceTypeCatalogue fetches and validates the instance type
+// catalogue at the given URL.
+func _fetchInstanceTypeCatalogue(url string) (*instanceTypeCatalogue, error) {
+	// The default clients are shared, so take a copy to set the timeout.
+	client := *utils.GetHTTPClient(utils.VerifySSLHostnames)
+	client.Timeout = instanceTypesFetchTimeout
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot fetch instance types from %q", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("cannot fetch instance types from %q: %s", url, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read instance types from %q", url)
+	}
+	var catalogue instanceTypeCatalogue
+	if err := json.Unmarshal(data, &catalogue); err != nil {
+		return nil, errors.Annotatef(err, "cannot parse instance types from %q", url)
+	}
+	for _, t := range catalogue.InstanceTypes {
+		if err := t.validate(); err != nil {
+			return nil, errors.Annotatef(err, "invalid instance types from %q", url)
+		}
+	}
+	return &catalogue, nil
+}
+
+// instanceTypeCatalogues caches the catalogues fetched from each
+// instance-types-url. It is shared by all environs in the process,
+// because environs are created afresh by each worker and command that
+// needs one, and each would otherwise fetch the catalogue again.
+var instanceTypeCatalogues = &instanceTypesCache{}
+
+// instanceTypesCache caches instance type catalogues by URL.
+type instanceTypesCache struct {
+	mu      sync.Mutex
+	entries map[string]*instanceTypesCacheEntry
+}
+
+// instanceTypesCacheEntry holds the catalogue fetched from one URL.
+type instanceTypesCacheEntry struct {
+	// ready is closed once the first fetch from the URL completes.
+	ready chan struct{}
+
+	fetched    time.Time
+	refreshing bool
+	catalogue  *instanceTypeCatalogue
+}
+
+// get returns the catalogue at the given URL. The first time a URL
+// is asked for, its catalogue is fetched before returning; after that
+// the cached catalogue is returned straight away, and once it is older
+// than the refresh interval it is fetched again in the background. If
+// a catalogue cannot be fetched, the previously fetched one (if any)
+// continues to be used, and the fetch is retried after another
+// refresh interval.
+//
+// Fetches are made without holding the cache's mutex, so a slow
+// fetch from one URL only holds up callers asking for that URL.
+func (c *instanceTypesCache) get(url string) *instanceTypeCatalogue {
+	if url == "" {
+		return nil
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[url]
+	if !ok {
+		// There is nothing to fall back on until the first fetch
+		// completes, so fetch it now; anyone else asking for the
+		// same URL in the meantime waits for this fetch.
+		entry = &instanceTypesCacheEntry{ready: make(chan struct{})}
+		if c.entries == nil {
+			c.entries = make(map[string]*instanceTypesCacheEntry)
+		}
+		c.entries[url] = entry
+		c.mu.Unlock()
+
+		catalogue, err := fetchInstanceTypeCatalogue(url)
+		c.mu.Lock()
+		entry.update(url, catalogue, err)
+		c.mu.Unlock()
+		close(entry.ready)
+		return catalogue
+	}
+	c.mu.Unlock()
+	<-entry.ready
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !entry.refreshing && time.Since(entry.fetched) >= instanceTypesRefreshInterval {
+		entry.refreshing = true
+		go c.refresh(url, entry)
+	}
+	return entry.catalogue
+}
+
+// refresh fetches the catalogue at the given URL again, and records
+// it in the given entry.
+func (c *instanceTypesCache) refresh(url string, entry *instanceTypesCacheEntry) {
+	catalogue, err := fetchInstanceTypeCatalogue(url)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.refreshing = false
+	entry.update(url, catalogue, err)
+}
+
+// update records the result of fetching the catalogue at the given
+// URL. The cache's mutex must be held.
+func (entry *instanceTypesCacheEntry) update(url string, catalogue *instanceTypeCatalogue, err error) {
+	entry.fetched = time.Now()
+	if err != nil {
+		if entry.catalogue == nil {
+			logger.Warningf("%v; using built-in instance types", err)
+		} else {
+			logger.Warningf("%v; using previously fetched instance types", err)
+		}
+		return
+	}
+	logger.Debugf("fetched %d instance types from %q", len(catalogue.InstanceTypes), url)
+	entry.catalogue = catalogue
+}
+
+// instanceTypes returns the known instance types, and their costs
+// in each region. These are the built-in ones, with those from the
+// environment's instance-types-url (if any) merged over them.
+func (e *environ) instanceTypes() ([]instances.InstanceType, regionCosts) {
+	catalogue := instanceTypeCatalogues.get(e.ecfg().instanceTypesURL())
+	return catalogue.merge(allInstanceTypes, allRegionCosts)
+}
@@ -0,0 +1,297 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/instances"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type catalogueSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&catalogueSuite{})
+
+const catalogueJSON = `{
+    "instance-types": [{
+        "name": "m4.large",
+        "arches": ["amd64"],
+        "cpu-cores": 2,
+        "cpu-power": 650,
+        "mem": 8192,
+        "virt-type": "hvm"
+    }, {
+        "name": "m1.small",
+        "arches": ["amd64"],
+        "cpu-cores": 1,
+        "mem": 2048
+    }],
+    "region-costs": {
+        "us-east-1": {"m4.large": 126},
+        "new-region": {"m4.large": 150}
+    }
+}`
+
+func serveCatalogue(c *gc.C, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+}
+
+func (s *catalogueSuite) TestFetch(c *gc.C) {
+	server := serveCatalogue(c, http.StatusOK, catalogueJSON)
+	defer server.Close()
+
+	catalogue, err := fetchInstanceTypeCatalogue(server.URL)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(catalogue.InstanceTypes, gc.HasLen, 2)
+	c.Check(catalogue.InstanceTypes[0], jc.DeepEquals, catalogueInstanceType{
+		Name:     "m4.large",
+		Arches:   []string{"amd64"},
+		CpuCores: 2,
+		CpuPower: instances.CpuPower(650),
+		Mem:      8192,
+		VirtType: "hvm",
+	})
+	c.Check(catalogue.RegionCosts["us-east-1"], jc.DeepEquals, instanceTypeCost{"m4.large": 126})
+}
+
+func (s *catalogueSuite) TestFetchNotFound(c *gc.C) {
+	server := serveCatalogue(c, http.StatusNotFound, "")
+	defer server.Close()
+
+	_, err := fetchInstanceTypeCatalogue(server.URL)
+	c.Assert(err, gc.ErrorMatches, `cannot fetch instance types from ".*": 404 Not Found`)
+}
+
+func (s *catalogueSuite) TestFetchInvalid(c *gc.C) {
+	for i, test := range []struct {
+		body string
+		err  string
+	}{{
+		body: "rubbish",
+		err:  `cannot parse instance types from ".*": .*`,
+	}, {
+		body: `{"instance-types": [{"arches": ["amd64"], "cpu-cores": 1, "mem": 1}]}`,
+		err:  `invalid instance types from ".*": instance type with no name`,
+	}, {
+		body: `{"instance-types": [{"name": "x", "cpu-cores": 1, "mem": 1}]}`,
+		err:  `invalid instance types from ".*": instance type "x" has no arches`,
+	}, {
+		body: `{"instance-types": [{"name": "x", "arches": ["amd64"], "mem": 1}]}`,
+		err:  `invalid instance types from ".*": instance type "x" has no cpu-cores or mem`,
+	}, {
+		body: `{"instance-types": [{"name": "x", "arches": ["amd64"], "cpu-cores": 1, "mem": 1, "virt-type": "lxc"}]}`,
+		err:  `invalid instance types from ".*": instance type "x" has unknown virt-type "lxc"`,
+	}} {
+		c.Logf("test %d", i)
+		server := serveCatalogue(c, http.StatusOK, test.body)
+		_, err := fetchInstanceTypeCatalogue(server.URL)
+		c.Check(err, gc.ErrorMatches, test.err)
+		server.Close()
+	}
+}
+
+func (s *catalogueSuite) TestMerge(c *gc.C) {
+	hvmType := hvm
+	itypes := []instances.InstanceType{{
+		Name:     "m1.small",
+		Arches:   both,
+		CpuCores: 1,
+		Mem:      1740,
+	}, {
+		Name:     "m3.medium",
+		Arches:   amd64,
+		CpuCores: 1,
+		Mem:      3840,
+	}}
+	costs := regionCosts{
+		"us-east-1": {"m1.small": 44, "m3.medium": 70},
+	}
+	catalogue := &instanceTypeCatalogue{
+		InstanceTypes: []catalogueInstanceType{{
+			Name: "m4.large", Arches: amd64, CpuCores: 2, Mem: 8192, VirtType: hvm,
+		}, {
+			Name: "m1.small", Arches: amd64, CpuCores: 1, Mem: 2048,
+		}},
+		RegionCosts: regionCosts{
+			"us-east-1":  {"m4.large": 126},
+			"new-region": {"m4.large": 150},
+		},
+	}
+	mergedTypes, mergedCosts := catalogue.merge(itypes, costs)
+	c.Check(mergedTypes, jc.DeepEquals, []instances.InstanceType{{
+		Name:     "m1.small",
+		Arches:   amd64,
+		CpuCores: 1,
+		Mem:      2048,
+	}, itypes[1], {
+		Name:     "m4.large",
+		Arches:   amd64,
+		CpuCores: 2,
+		Mem:      8192,
+		VirtType: &hvmType,
+	}})
+	c.Check(mergedCosts, jc.DeepEquals, regionCosts{
+		"us-east-1":  {"m1.small": 44, "m3.medium": 70, "m4.large": 126},
+		"new-region": {"m4.large": 150},
+	})
+
+	// The arguments are left alone.
+	c.Check(itypes[0].Mem, gc.Equals, uint64(1740))
+	c.Check(costs["us-east-1"], gc.HasLen, 2)
+}
+
+func (s *catalogueSuite) TestMergeNil(c *gc.C) {
+	var catalogue *instanceTypeCatalogue
+	itypes, costs := catalogue.merge(allInstanceTypes, allRegionCosts)
+	c.Check(itypes, jc.DeepEquals, allInstanceTypes)
+	c.Check(costs, jc.DeepEquals, allRegionCosts)
+}
+
+func (s *catalogueSuite) TestCacheFetchesFirstUse(c *gc.C) {
+	var fetched []string
+	catalogues := map[string]*instanceTypeCatalogue{
+		"http://a": {},
+		"http://b": {},
+	}
+	s.PatchValue(&fetchInstanceTypeCatalogue, func(url string) (*instanceTypeCatalogue, error) {
+		fetched = append(fetched, url)
+		return catalogues[url], nil
+	})
+
+	cache := &instanceTypesCache{}
+	c.Check(cache.get(""), gc.IsNil)
+	c.Check(fetched, gc.HasLen, 0)
+
+	// Each URL is fetched once, and cached separately.
+	c.Check(cache.get("http://a"), gc.Equals, catalogues["http://a"])
+	c.Check(cache.get("http://b"), gc.Equals, catalogues["http://b"])
+	c.Check(cache.get("http://a"), gc.Equals, catalogues["http://a"])
+	c.Check(fetched, jc.DeepEquals, []string{"http://a", "http://b"})
+}
+
+func (s *catalogueSuite) TestCacheFirstFetchFails(c *gc.C) {
+	var fetched int
+	s.PatchValue(&fetchInstanceTypeCatalogue, func(url string) (*instanceTypeCatalogue, error) {
+		fetched++
+		return nil, errors.New("boom")
+	})
+
+	cache := &instanceTypesCache{}
+	c.Check(cache.get("http://a"), gc.IsNil)
+	c.Check(cache.get("http://a"), gc.IsNil)
+	c.Check(fetched, gc.Equals, 1)
+}
+
+// fetchResult holds the result of a patched catalogue fetch.
+type fetchResult struct {
+	catalogue *instanceTypeCatalogue
+	err       error
+}
+
+func (s *catalogueSuite) TestCacheRefreshesInBackground(c *gc.C) {
+	for i, refreshErr := range []error{nil, errors.New("boom")} {
+		c.Logf("test %d: refresh error %v", i, refreshErr)
+		s.assertCacheRefreshesInBackground(c, refreshErr)
+	}
+}
+
+func (s *catalogueSuite) assertCacheRefreshesInBackground(c *gc.C, refreshErr error) {
+	first := &instanceTypeCatalogue{}
+	second := &instanceTypeCatalogue{}
+	fetches := make(chan string, 1)
+	results := make(chan fetchResult, 1)
+	results <- fetchResult{catalogue: first}
+	s.PatchValue(&fetchInstanceTypeCatalogue, func(url string) (*instanceTypeCatalogue, error) {
+		fetches <- url
+		result := <-results
+		return result.catalogue, result.err
+	})
+	s.PatchValue(&instanceTypesRefreshInterval, time.Duration(0))
+
+	cache := &instanceTypesCache{}
+	c.Assert(cache.get("http://a"), gc.Equals, first)
+	c.Assert(<-fetches, gc.Equals, "http://a")
+
+	// The stale catalogue is returned while it is fetched again, and
+	// only one fetch runs at a time.
+	c.Assert(cache.get("http://a"), gc.Equals, first)
+	select {
+	case url := <-fetches:
+		c.Assert(url, gc.Equals, "http://a")
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for refresh")
+	}
+	c.Assert(cache.get("http://a"), gc.Equals, first)
+	instanceTypesRefreshInterval = time.Hour
+	results <- fetchResult{catalogue: second, err: refreshErr}
+
+	expect := second
+	if refreshErr != nil {
+		// A failed refresh keeps using the previous catalogue.
+		expect = first
+	}
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		cache.mu.Lock()
+		refreshing := cache.entries["http://a"].refreshing
+		cache.mu.Unlock()
+		if !refreshing {
+			break
+		}
+	}
+	c.Assert(cache.get("http://a"), gc.Equals, expect)
+	select {
+	case <-fetches:
+		c.Fatalf("unexpected fetch")
+	default:
+	}
+}
+
+func (s *catalogueSuite) TestCacheSlowFetchHoldsUpOnlyItsURL(c *gc.C) {
+	release := make(chan struct{})
+	catalogue := &instanceTypeCatalogue{}
+	s.PatchValue(&fetchInstanceTypeCatalogue, func(url string) (*instanceTypeCatalogue, error) {
+		if url == "http://slow" {
+			<-release
+		}
+		return catalogue, nil
+	})
+
+	cache := &instanceTypesCache{}
+	slow := make(chan *instanceTypeCatalogue, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			slow <- cache.get("http://slow")
+		}()
+	}
+	c.Check(cache.get("http://fast"), gc.Equals, catalogue)
+
+	// Callers asking for the slow URL wait for its fetch.
+	select {
+	case <-slow:
+		c.Fatalf("slow fetch completed early")
+	default:
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		select {
+		case got := <-slow:
+			c.Check(got, gc.Equals, catalogue)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for slow fetch")
+		}
+	}
+}
@@ -6,6 +6,8 @@ package ec2_test
 import (
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"sort"
 	"strings"
@@ -330,6 +332,30 @@ func (t *localServerSuite) TestStartInstanceAvailZoneUnknown(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `invalid availability zone "test-unknown"`)
 }
 
+func (t *localServerSuite) TestStartInstanceVolumeZone(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	params := environs.StartInstanceParams{AvailabilityZone: "test-available"}
+	result, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ec2.InstanceEC2(result.Instance).AvailZone, gc.Equals, "test-available")
+}
+
+func (t *localServerSuite) TestStartInstanceVolumeZoneMismatch(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	params := environs.StartInstanceParams{
+		Placement:        "zone=test-available",
+		AvailabilityZone: "test-impaired",
+	}
+	_, err = testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot start instance in availability zone "test-available": volumes to be attached are in availability zone "test-impaired"`)
+}
+
 func (t *localServerSuite) testStartInstanceAvailZone(c *gc.C, zone string) (instance.Instance, error) {
 	env := t.Prepare(c)
 	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
@@ -581,6 +607,93 @@ func (t *localServerSuite) testStartInstanceAvailZoneOneConstrained(c *gc.C, run
 	c.Check(*hwc.AvailabilityZone, gc.Equals, "az2")
 }
 
+func (t *localServerSuite) TestStartInstanceSpotPrice(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The spot request is fulfilled by running an on-demand instance,
+	// as the test server does not support spot requests.
+	var spotPrices []string
+	realRunInstances := *ec2.RunInstances
+	t.PatchValue(ec2.RunSpotInstance, func(e *amzec2.EC2, ri *amzec2.RunInstances, spotPrice string) (*amzec2.RunInstancesResp, error) {
+		spotPrices = append(spotPrices, spotPrice)
+		return realRunInstances(e, ri)
+	})
+	t.PatchValue(ec2.RunInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances) (*amzec2.RunInstancesResp, error) {
+		c.Fatalf("on-demand instance requested")
+		return nil, nil
+	})
+	params := environs.StartInstanceParams{Placement: "zone=test-available,spot-price=0.05"}
+	result, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spotPrices, gc.DeepEquals, []string{"0.05"})
+	c.Assert(ec2.InstanceEC2(result.Instance).AvailZone, gc.Equals, "test-available")
+}
+
+func (t *localServerSuite) TestStartInstanceSpotZoneConstrained(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	mock := mockAvailabilityZoneAllocations{
+		result: []common.AvailabilityZoneInstances{
+			{ZoneName: "az1"}, {ZoneName: "az2"},
+		},
+	}
+	t.PatchValue(ec2.AvailabilityZoneAllocations, mock.AvailabilityZoneAllocations)
+
+	var azArgs []string
+	realRunInstances := *ec2.RunInstances
+	t.PatchValue(ec2.RunSpotInstance, func(e *amzec2.EC2, ri *amzec2.RunInstances, spotPrice string) (*amzec2.RunInstancesResp, error) {
+		azArgs = append(azArgs, ri.AvailZone)
+		if len(azArgs) == 1 {
+			return nil, ec2.NewSpotRequestError("sir-1", "capacity-not-available", "no capacity")
+		}
+		return realRunInstances(e, ri)
+	})
+	params := environs.StartInstanceParams{Placement: "spot-price=0.05"}
+	result, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(azArgs, gc.DeepEquals, []string{"az1", "az2"})
+	c.Assert(ec2.InstanceEC2(result.Instance).AvailZone, gc.Equals, "az2")
+}
+
+func (t *localServerSuite) TestStartInstanceSpotRequestFailed(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	t.PatchValue(ec2.RunSpotInstance, func(e *amzec2.EC2, ri *amzec2.RunInstances, spotPrice string) (*amzec2.RunInstancesResp, error) {
+		return nil, ec2.NewSpotRequestError("sir-1", "price-too-low", "Your price is too low")
+	})
+	params := environs.StartInstanceParams{Placement: "zone=test-available,spot-price=0.001"}
+	_, err = testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot run instances: spot request sir-1 not fulfilled: Your price is too low \(price-too-low\)`)
+}
+
+func (t *localServerSuite) TestPrecheckInstanceSpotPrice(c *gc.C) {
+	env := t.Prepare(c)
+	for _, placement := range []string{"spot-price=0.05", "zone=test-available,spot-price=1"} {
+		err := env.PrecheckInstance(coretesting.FakeDefaultSeries, constraints.Value{}, placement)
+		c.Check(err, jc.ErrorIsNil)
+	}
+}
+
+func (t *localServerSuite) TestPrecheckInstanceInvalidSpotPrice(c *gc.C) {
+	env := t.Prepare(c)
+	for _, price := range []string{"", "cheap", "0", "-1"} {
+		err := env.PrecheckInstance(coretesting.FakeDefaultSeries, constraints.Value{}, "spot-price="+price)
+		c.Check(err, gc.ErrorMatches, fmt.Sprintf("invalid spot price %q", price))
+	}
+}
+
+func (t *localServerSuite) TestPrecheckInstanceUnknownDirective(c *gc.C) {
+	env := t.Prepare(c)
+	err := env.PrecheckInstance(coretesting.FakeDefaultSeries, constraints.Value{}, "zone=test-available,foo=bar")
+	c.Assert(err, gc.ErrorMatches, "unknown placement directive: foo=bar")
+}
+
 func (t *localServerSuite) TestAddresses(c *gc.C) {
 	env := t.Prepare(c)
 	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
@@ -630,6 +743,35 @@ func (t *localServerSuite) TestConstraintsValidatorVocab(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "invalid constraint value: instance-type=foo\nvalid values are:.*")
 }
 
+func (t *localServerSuite) TestConstraintsValidatorInstanceTypesURL(c *gc.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"instance-types": [{"name": "m9.huge", "arches": ["amd64"], "cpu-cores": 64, "mem": 524288}],
+			"region-costs": {"test": {"m9.huge": 9999}}
+		}`)
+	}))
+	defer server.Close()
+
+	env := t.Prepare(c)
+	cfg, err := env.Config().Apply(map[string]interface{}{
+		"instance-types-url": server.URL,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetConfig(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+
+	validator, err := env.ConstraintsValidator()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = validator.Validate(constraints.MustParse("instance-type=m9.huge"))
+	c.Assert(err, jc.ErrorIsNil)
+	// The built-in instance types are still known.
+	_, err = validator.Validate(constraints.MustParse("instance-type=m1.small"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = env.PrecheckInstance(coretesting.FakeDefaultSeries, constraints.MustParse("instance-type=m9.huge"), "")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (t *localServerSuite) TestConstraintsMerge(c *gc.C) {
 	env := t.Prepare(c)
 	validator, err := env.ConstraintsValidator()
@@ -960,6 +1102,87 @@ func (t *localServerSuite) TestInstanceTags(c *gc.C) {
 	})
 }
 
+func (t *localServerSuite) TestTagInstance(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	instances, err := env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 1)
+
+	// Volumes attached to the instance are tagged along with it.
+	ec2conn := ec2.EnvironEC2(env)
+	vol, err := ec2conn.CreateVolume(amzec2.CreateVolume{
+		AvailZone:  ec2.InstanceEC2(instances[0]).AvailZone,
+		VolumeSize: 1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = ec2conn.AttachVolume(vol.Id, string(instances[0].Id()), "/dev/sdf")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = env.(environs.InstanceTagger).TagInstance(instances[0].Id(), map[string]string{
+		"cost-centre":   "1234",
+		"juju-env-uuid": coretesting.EnvironmentTag.Id(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	instances, err = env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 1)
+	ec2Inst := ec2.InstanceEC2(instances[0])
+	c.Assert(ec2Inst.Tags, jc.SameContents, []amzec2.Tag{
+		{"Name", "juju-sample-machine-0"},
+		{"juju-env-uuid", coretesting.EnvironmentTag.Id()},
+		{"juju-is-state", "true"},
+		{"cost-centre", "1234"},
+	})
+
+	resp, err := ec2conn.Volumes([]string{vol.Id}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resp.Volumes, gc.HasLen, 1)
+	c.Assert(resp.Volumes[0].Tags, jc.SameContents, []amzec2.Tag{
+		{"juju-env-uuid", coretesting.EnvironmentTag.Id()},
+		{"cost-centre", "1234"},
+	})
+}
+
+func (t *localServerSuite) patchMaxInstances(c *gc.C, values ...string) {
+	t.PatchValue(ec2.EC2AccountAttributes, func(e *amzec2.EC2, names ...string) (*amzec2.AccountAttributesResp, error) {
+		c.Assert(names, jc.DeepEquals, []string{"max-instances"})
+		return &amzec2.AccountAttributesResp{
+			Attributes: []amzec2.AccountAttribute{{
+				Name:   "max-instances",
+				Values: values,
+			}},
+		}, nil
+	})
+}
+
+func (t *localServerSuite) TestQuotas(c *gc.C) {
+	t.patchMaxInstances(c, "20")
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+	testing.AssertStartInstance(c, env, "1")
+
+	quotas, err := env.(environs.QuotaReporter).Quotas()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(quotas.Instances, gc.NotNil)
+	c.Assert(*quotas.Instances, gc.Equals, 18)
+	c.Assert(quotas.Cores, gc.IsNil)
+	c.Assert(quotas.Volumes, gc.IsNil)
+	c.Assert(quotas.IPAddresses, gc.IsNil)
+}
+
+func (t *localServerSuite) TestQuotasNoMaxInstances(c *gc.C) {
+	t.patchMaxInstances(c)
+	env := t.Prepare(c)
+	quotas, err := env.(environs.QuotaReporter).Quotas()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(quotas, jc.DeepEquals, environs.Quotas{})
+}
+
 // localNonUSEastSuite is similar to localServerSuite but the S3 mock server
 // behaves as if it is not in the us-east region.
 type localNonUSEastSuite struct {
@@ -0,0 +1,259 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"gopkg.in/amz.v3/ec2"
+
+	"github.com/juju/juju/instance"
+)
+
+const (
+	// spotPriceDirective is the placement directive used to request
+	// a spot instance, bidding at most the given hourly price (in US
+	// dollars), instead of an on-demand instance.
+	spotPriceDirective = "spot-price"
+
+	// spotInterruptedStatus is appended to the status of instances
+	// that were terminated by EC2 because their spot price was
+	// exceeded, or because spot capacity was withdrawn.
+	spotInterruptedStatus = "spot instance interrupted"
+)
+
+// spotAttempt is used to wait for spot requests to be fulfilled. If
+// a request is not fulfilled in time, it is cancelled. Requests that
+// can be fulfilled usually are within a minute or so, and StartInstance
+// holds up the provisioner while it waits, so this is kept short.
+var spotAttempt = utils.AttemptStrategy{
+	Total: 2 * time.Minute,
+	Delay: 5 * time.Second,
+}
+
+var (
+	requestSpotInstances = (*ec2.EC2).RequestSpotInstances
+	describeSpotRequests = (*ec2.EC2).DescribeSpotRequests
+	cancelSpotRequests   = (*ec2.EC2).CancelSpotRequests
+	ec2Instances         = (*ec2.EC2).Instances
+	terminateInstances   = (*ec2.EC2).TerminateInstances
+)
+
+// parseSpotPrice checks that the given spot price is a positive
+// number, and returns it.
+func parseSpotPrice(value string) (string, error) {
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price <= 0 {
+		return "", errors.Errorf("invalid spot price %q", value)
+	}
+	return value, nil
+}
+
+// spotRequestError is returned when a spot request cannot be
+// fulfilled. Code and Message are the spot request's status code
+// and message.
+type spotRequestError struct {
+	RequestId string
+	Code      string
+	Message   string
+}
+
+func (e *spotRequestError) Error() string {
+	return fmt.Sprintf("spot request %s not fulfilled: %s (%s)", e.RequestId, e.Message, e.Code)
+}
+
+// isSpotZoneConstrainedError reports whether or not the error
+// indicates that a spot request could not be fulfilled because there
+// is no spot capacity in the requested availability zone.
+func isSpotZoneConstrainedError(err error) bool {
+	if err, ok := errors.Cause(err).(*spotRequestError); ok {
+		switch err.Code {
+		case "capacity-not-available", "capacity-oversubscribed":
+			return true
+		}
+	}
+	return false
+}
+
+var runSpotInstance = _runSpotInstance
+
+// _runSpotInstance requests a one-time spot instance, with the
+// parameters of the given RunInstances request, bidding at most the
+// given price. It waits for the request to be fulfilled, and returns
+// the instance in the same form as RunInstances would. If the request
+// cannot be fulfilled, or is not fulfilled in time, the request is
+// cancelled and an error is returned.
+func _runSpotInstance(e *ec2.EC2, ri *ec2.RunInstances, spotPrice string) (*ec2.RunInstancesResp, error) {
+	resp, err := requestSpotInstances(e, &ec2.RequestSpotInstances{
+		SpotPrice:           spotPrice,
+		InstanceCount:       1,
+		Type:                "one-time",
+		AvailZone:           ri.AvailZone,
+		ImageId:             ri.ImageId,
+		UserData:            ri.UserData,
+		InstanceType:        ri.InstanceType,
+		SecurityGroups:      ri.SecurityGroups,
+		BlockDeviceMappings: ri.BlockDeviceMappings,
+	})
+	if err != nil {
+		return nil, errors.Annotate(err, "requesting spot instance")
+	}
+	if n := len(resp.SpotRequestResults); n != 1 {
+		return nil, errors.Errorf("expected 1 spot request, got %d", n)
+	}
+	requestId := resp.SpotRequestResults[0].SpotRequestId
+	logger.Infof("requested spot instance at %s/hour (request %s)", spotPrice, requestId)
+
+	instId, err := waitSpotRequest(e, requestId)
+	if err != nil {
+		cancelSpotRequest(e, requestId)
+		return nil, errors.Trace(err)
+	}
+
+	var instResp *ec2.InstancesResp
+	for a := shortAttempt.Start(); a.Next(); {
+		instResp, err = ec2Instances(e, []string{instId}, nil)
+		if err == nil || ec2ErrCode(err) != "InvalidInstanceID.NotFound" {
+			break
+		}
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "querying spot instance %s", instId)
+	}
+	if len(instResp.Reservations) != 1 {
+		return nil, errors.Errorf("expected 1 reservation for spot instance %s, got %d", instId, len(instResp.Reservations))
+	}
+	return &ec2.RunInstancesResp{
+		ReservationId: instResp.Reservations[0].ReservationId,
+		OwnerId:       instResp.Reservations[0].OwnerId,
+		Instances:     instResp.Reservations[0].Instances,
+	}, nil
+}
+
+// cancelSpotRequest cancels the identified spot request. The request
+// may have been fulfilled after we last looked at it, and cancelling
+// a request leaves its instance running, so any instance the request
+// launched is terminated as well. Failures are logged, as the caller
+// is already reporting an error.
+func cancelSpotRequest(e *ec2.EC2, requestId string) {
+	if _, err := cancelSpotRequests(e, []string{requestId}); err != nil {
+		logger.Errorf("cannot cancel spot request %s: %v", requestId, err)
+		return
+	}
+	resp, err := describeSpotRequests(e, []string{requestId}, nil)
+	if err != nil {
+		logger.Errorf("cannot query cancelled spot request %s: %v", requestId, err)
+		return
+	}
+	for _, r := range resp.SpotRequestResults {
+		if r.InstanceId == "" {
+			continue
+		}
+		logger.Infof("terminating instance %s of cancelled spot request %s", r.InstanceId, requestId)
+		if _, err := terminateInstances(e, []string{r.InstanceId}); err != nil {
+			logger.Errorf("cannot terminate instance %s of cancelled spot request %s: %v", r.InstanceId, requestId, err)
+		}
+	}
+}
+
+// waitSpotRequest waits for the identified spot request to be
+// fulfilled, and returns the ID of the instance it launched.
+func waitSpotRequest(e *ec2.EC2, requestId string) (string, error) {
+	var status ec2.SpotRequestStatus
+	for a := spotAttempt.Start(); a.Next(); {
+		resp, err := describeSpotRequests(e, []string{requestId}, nil)
+		if ec2ErrCode(err) == "InvalidSpotInstanceRequestID.NotFound" {
+			// The request is not visible yet.
+			continue
+		}
+		if err != nil {
+			return "", errors.Annotatef(err, "querying spot request %s", requestId)
+		}
+		if n := len(resp.SpotRequestResults); n != 1 {
+			return "", errors.Errorf("expected 1 spot request, got %d", n)
+		}
+		result := resp.SpotRequestResults[0]
+		status = result.Status
+		switch result.State {
+		case "active":
+			if result.InstanceId != "" {
+				return result.InstanceId, nil
+			}
+		case "open":
+			switch status.Code {
+			case "capacity-not-available", "capacity-oversubscribed", "price-too-low":
+				// The request would stay open until capacity is
+				// available, or the price drops; there is no point
+				// in waiting for that.
+				return "", &spotRequestError{requestId, status.Code, status.Message}
+			}
+		default:
+			// closed, cancelled or failed.
+			return "", &spotRequestError{requestId, status.Code, status.Message}
+		}
+		logger.Debugf("waiting for spot request %s: %s", requestId, status.Code)
+	}
+	return "", errors.Errorf(
+		"spot request %s not fulfilled after %v (last status %q)",
+		requestId, spotAttempt.Total, status.Code,
+	)
+}
+
+// isSpotInterruption reports whether the given spot request status
+// code indicates that EC2 terminated the request's instance, rather
+// than the user.
+func isSpotInterruption(code string) bool {
+	return strings.HasPrefix(code, "instance-terminated-") && code != "instance-terminated-by-user"
+}
+
+// gatherSpotInterruptions fills in each nil slot of insts whose
+// corresponding instance ID belongs to an instance that was launched
+// for a spot request, and then interrupted by EC2. The filled in
+// instances are terminated, and their status says why.
+//
+// Failure to query spot requests is not fatal; the instances are
+// simply left out.
+func (e *environ) gatherSpotInterruptions(ids []instance.Id, insts []instance.Instance) {
+	var need []string
+	for i, inst := range insts {
+		if inst == nil {
+			need = append(need, string(ids[i]))
+		}
+	}
+	if len(need) == 0 {
+		return
+	}
+	filter := ec2.NewFilter()
+	filter.Add("instance-id", need...)
+	resp, err := describeSpotRequests(e.ec2(), nil, filter)
+	if err != nil {
+		logger.Debugf("cannot query spot requests: %v", err)
+		return
+	}
+	for _, r := range resp.SpotRequestResults {
+		if r.InstanceId == "" || !isSpotInterruption(r.Status.Code) {
+			continue
+		}
+		for i, id := range ids {
+			if insts[i] != nil || string(id) != r.InstanceId {
+				continue
+			}
+			logger.Warningf("spot instance %s was interrupted: %s", id, r.Status.Message)
+			insts[i] = &ec2Instance{
+				e: e,
+				Instance: &ec2.Instance{
+					InstanceId: r.InstanceId,
+					State:      ec2.InstanceState{Name: "terminated"},
+					AvailZone:  r.AvailZone,
+				},
+				spotStatus: r.Status.Code,
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	amzec2 "gopkg.in/amz.v3/ec2"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/instance"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type spotSuite struct {
+	coretesting.BaseSuite
+
+	requests   []*amzec2.RequestSpotInstances
+	describes  [][]string
+	cancelled  []string
+	terminated []string
+	states     []amzec2.SpotRequestResult
+}
+
+var _ = gc.Suite(&spotSuite{})
+
+func (s *spotSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.requests = nil
+	s.describes = nil
+	s.cancelled = nil
+	s.terminated = nil
+	s.states = nil
+
+	s.PatchValue(&spotAttempt, utils.AttemptStrategy{
+		Total: time.Second,
+		Delay: time.Millisecond,
+	})
+	s.PatchValue(&requestSpotInstances, func(e *amzec2.EC2, req *amzec2.RequestSpotInstances) (*amzec2.RequestSpotInstancesResp, error) {
+		s.requests = append(s.requests, req)
+		return &amzec2.RequestSpotInstancesResp{
+			SpotRequestResults: []amzec2.SpotRequestResult{{
+				SpotRequestId: "sir-1",
+				State:         "open",
+			}},
+		}, nil
+	})
+	s.PatchValue(&describeSpotRequests, func(e *amzec2.EC2, ids []string, filter *amzec2.Filter) (*amzec2.SpotRequestsResp, error) {
+		s.describes = append(s.describes, ids)
+		state := s.states[0]
+		if len(s.states) > 1 {
+			s.states = s.states[1:]
+		}
+		return &amzec2.SpotRequestsResp{
+			SpotRequestResults: []amzec2.SpotRequestResult{state},
+		}, nil
+	})
+	s.PatchValue(&cancelSpotRequests, func(e *amzec2.EC2, ids []string) (*amzec2.CancelSpotRequestsResp, error) {
+		s.cancelled = append(s.cancelled, ids...)
+		return &amzec2.CancelSpotRequestsResp{}, nil
+	})
+	s.PatchValue(&terminateInstances, func(e *amzec2.EC2, ids []string) (*amzec2.TerminateInstancesResp, error) {
+		s.terminated = append(s.terminated, ids...)
+		return &amzec2.TerminateInstancesResp{}, nil
+	})
+	s.PatchValue(&ec2Instances, func(e *amzec2.EC2, ids []string, filter *amzec2.Filter) (*amzec2.InstancesResp, error) {
+		c.Assert(ids, jc.DeepEquals, []string{"i-1"})
+		return &amzec2.InstancesResp{
+			Reservations: []amzec2.Reservation{{
+				ReservationId: "r-1",
+				Instances: []amzec2.Instance{{
+					InstanceId: "i-1",
+					AvailZone:  "az1",
+				}},
+			}},
+		}, nil
+	})
+}
+
+func spotRequestState(state, code, instId string) amzec2.SpotRequestResult {
+	return amzec2.SpotRequestResult{
+		SpotRequestId: "sir-1",
+		State:         state,
+		InstanceId:    instId,
+		Status:        amzec2.SpotRequestStatus{Code: code, Message: code + " message"},
+	}
+}
+
+func (s *spotSuite) runSpotInstance() (*amzec2.RunInstancesResp, error) {
+	return _runSpotInstance(nil, &amzec2.RunInstances{
+		AvailZone:    "az1",
+		ImageId:      "ami-1",
+		InstanceType: "m3.medium",
+		UserData:     []byte("user-data"),
+	}, "0.05")
+}
+
+func (s *spotSuite) TestRunSpotInstance(c *gc.C) {
+	s.states = []amzec2.SpotRequestResult{
+		spotRequestState("open", "pending-evaluation", ""),
+		spotRequestState("open", "pending-fulfillment", ""),
+		spotRequestState("active", "fulfilled", "i-1"),
+	}
+	resp, err := s.runSpotInstance()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resp.Instances, gc.HasLen, 1)
+	c.Check(resp.Instances[0].InstanceId, gc.Equals, "i-1")
+	c.Check(resp.ReservationId, gc.Equals, "r-1")
+
+	c.Assert(s.requests, gc.HasLen, 1)
+	req := s.requests[0]
+	c.Check(req.SpotPrice, gc.Equals, "0.05")
+	c.Check(req.InstanceCount, gc.Equals, 1)
+	c.Check(req.Type, gc.Equals, "one-time")
+	c.Check(req.AvailZone, gc.Equals, "az1")
+	c.Check(req.ImageId, gc.Equals, "ami-1")
+	c.Check(req.InstanceType, gc.Equals, "m3.medium")
+	c.Check(string(req.UserData), gc.Equals, "user-data")
+	c.Check(s.describes, gc.HasLen, 3)
+	c.Check(s.cancelled, gc.HasLen, 0)
+}
+
+func (s *spotSuite) TestRunSpotInstancePriceTooLow(c *gc.C) {
+	s.states = []amzec2.SpotRequestResult{
+		spotRequestState("open", "price-too-low", ""),
+	}
+	_, err := s.runSpotInstance()
+	c.Assert(err, gc.ErrorMatches, `spot request sir-1 not fulfilled: price-too-low message \(price-too-low\)`)
+	c.Check(isSpotZoneConstrainedError(err), jc.IsFalse)
+	c.Check(s.cancelled, jc.DeepEquals, []string{"sir-1"})
+}
+
+func (s *spotSuite) TestRunSpotInstanceCapacityNotAvailable(c *gc.C) {
+	s.states = []amzec2.SpotRequestResult{
+		spotRequestState("open", "capacity-not-available", ""),
+	}
+	_, err := s.runSpotInstance()
+	c.Assert(err, gc.NotNil)
+	c.Check(isSpotZoneConstrainedError(err), jc.IsTrue)
+	c.Check(s.cancelled, jc.DeepEquals, []string{"sir-1"})
+}
+
+func (s *spotSuite) TestRunSpotInstanceFailed(c *gc.C) {
+	s.states = []amzec2.SpotRequestResult{
+		spotRequestState("failed", "bad-parameters", ""),
+	}
+	_, err := s.runSpotInstance()
+	c.Assert(err, gc.ErrorMatches, `spot request sir-1 not fulfilled: bad-parameters message \(bad-parameters\)`)
+}
+
+func (s *spotSuite) TestRunSpotInstanceTimeout(c *gc.C) {
+	s.states = []amzec2.SpotRequestResult{
+		spotRequestState("open", "pending-evaluation", ""),
+	}
+	_, err := s.runSpotInstance()
+	c.Assert(err, gc.ErrorMatches, `spot request sir-1 not fulfilled after 1s \(last status "pending-evaluation"\)`)
+	c.Check(s.cancelled, jc.DeepEquals, []string{"sir-1"})
+	c.Check(s.terminated, gc.HasLen, 0)
+}
+
+func (s *spotSuite) TestRunSpotInstanceTimeoutFulfilledWhileCancelling(c *gc.C) {
+	s.states = []amzec2.SpotRequestResult{
+		spotRequestState("open", "pending-fulfillment", ""),
+	}
+	s.PatchValue(&cancelSpotRequests, func(e *amzec2.EC2, ids []string) (*amzec2.CancelSpotRequestsResp, error) {
+		s.cancelled = append(s.cancelled, ids...)
+		s.states = []amzec2.SpotRequestResult{
+			spotRequestState("cancelled", "request-canceled-and-instance-running", "i-1"),
+		}
+		return &amzec2.CancelSpotRequestsResp{}, nil
+	})
+	_, err := s.runSpotInstance()
+	c.Assert(err, gc.ErrorMatches, `spot request sir-1 not fulfilled after 1s \(last status "pending-fulfillment"\)`)
+	c.Check(s.cancelled, jc.DeepEquals, []string{"sir-1"})
+	c.Check(s.terminated, jc.DeepEquals, []string{"i-1"})
+}
+
+func (s *spotSuite) TestGatherSpotInterruptions(c *gc.C) {
+	s.PatchValue(&describeSpotRequests, func(e *amzec2.EC2, ids []string, filter *amzec2.Filter) (*amzec2.SpotRequestsResp, error) {
+		return &amzec2.SpotRequestsResp{
+			SpotRequestResults: []amzec2.SpotRequestResult{
+				spotRequestState("closed", "instance-terminated-by-price", "i-1"),
+				spotRequestState("closed", "instance-terminated-by-user", "i-2"),
+			},
+		}, nil
+	})
+	env := &environ{}
+	running := &ec2Instance{e: env, Instance: &amzec2.Instance{InstanceId: "i-0"}}
+	ids := []instance.Id{"i-0", "i-1", "i-2", "i-3"}
+	insts := []instance.Instance{running, nil, nil, nil}
+	env.gatherSpotInterruptions(ids, insts)
+
+	c.Check(insts[0], gc.Equals, running)
+	c.Assert(insts[1], gc.NotNil)
+	c.Check(insts[1].Id(), gc.Equals, instance.Id("i-1"))
+	c.Check(insts[1].Status(), gc.Equals, "terminated (spot instance interrupted: instance-terminated-by-price)")
+	c.Check(insts[2], gc.IsNil)
+	c.Check(insts[3], gc.IsNil)
+}
+
+func (s *spotSuite) TestIsSpotInterruption(c *gc.C) {
+	for code, expect := range map[string]bool{
+		"instance-terminated-by-price":                true,
+		"instance-terminated-no-capacity":             true,
+		"instance-terminated-capacity-oversubscribed": true,
+		"instance-terminated-by-user":                 false,
+		"fulfilled":                                   false,
+	} {
+		c.Check(isSpotInterruption(code), gc.Equals, expect, gc.Commentf("%s", code))
+	}
+}
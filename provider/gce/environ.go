@@ -27,12 +27,19 @@ type gceConnection interface {
 	Instances(prefix string, statuses ...string) ([]google.Instance, error)
 	AddInstance(spec google.InstanceSpec, zones ...string) (*google.Instance, error)
 	RemoveInstances(prefix string, ids ...string) error
+	UpdateMetadata(id, zone string, metadata map[string]string) error
 
 	Ports(fwname string) ([]network.PortRange, error)
 	OpenPorts(fwname string, ports ...network.PortRange) error
 	ClosePorts(fwname string, ports ...network.PortRange) error
 
 	AvailabilityZones(region string) ([]google.AvailabilityZone, error)
+
+	CreateDisk(zone string, spec google.DiskSpec) (*google.Disk, error)
+	Disk(zone, name string) (*google.Disk, error)
+	RemoveDisk(zone, name string) error
+	AttachDisk(zone, name, instanceID string, readOnly bool) error
+	DetachDisk(zone, name, instanceID string) error
 }
 
 type environ struct {
@@ -81,10 +81,11 @@ var availabilityZoneAllocations = common.AvailabilityZoneAllocations
 
 // parseAvailabilityZones returns the availability zones that should be
 // tried for the given instance spec. If a placement argument was
-// provided then only that one is returned. Otherwise the environment is
-// queried for available zones. In that case, the resulting list is
-// roughly ordered such that the environment's instances are spread
-// evenly across the region.
+// provided then only that one is returned, and likewise if the
+// instance must be in the zone of existing volumes. Otherwise the
+// environment is queried for available zones. In that case, the
+// resulting list is roughly ordered such that the environment's
+// instances are spread evenly across the region.
 func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams) ([]string, error) {
 	if args.Placement != "" {
 		// args.Placement will always be a zone name or empty.
@@ -93,7 +94,21 @@ func (env *environ) parseAvailabilityZones(args environs.StartInstanceParams) ([
 			return nil, errors.Trace(err)
 		}
 		// TODO(ericsnow) Fail if placement.Zone is not in the env's configured region?
-		return []string{placement.Zone.Name()}, nil
+		zone := placement.Zone.Name()
+		if args.AvailabilityZone != "" && zone != args.AvailabilityZone {
+			return nil, errors.Errorf(
+				"cannot start instance in zone %q: volumes to be attached are in zone %q",
+				zone, args.AvailabilityZone,
+			)
+		}
+		return []string{zone}, nil
+	}
+
+	// Disks can only be attached to instances in the same zone, so
+	// the instance must be started in the zone of any existing disks
+	// that are to be attached to it.
+	if args.AvailabilityZone != "" {
+		return []string{args.AvailabilityZone}, nil
 	}
 
 	// If no availability zone is specified, then automatically spread across
@@ -151,6 +151,28 @@ func (s *environAZSuite) TestParseAvailabilityZonesPlacementUnavailable(c *gc.C)
 	c.Check(err, gc.ErrorMatches, `.*availability zone "a-zone" is DOWN`)
 }
 
+func (s *environAZSuite) TestParseAvailabilityZonesPlacementVolumeZoneMismatch(c *gc.C) {
+	s.StartInstArgs.Placement = "zone=a-zone"
+	s.StartInstArgs.AvailabilityZone = "b-zone"
+	s.FakeConn.Zones = []google.AvailabilityZone{
+		google.NewZone("a-zone", google.StatusUp, "", ""),
+	}
+
+	_, err := gce.ParseAvailabilityZones(s.Env, s.StartInstArgs)
+
+	c.Check(err, gc.ErrorMatches, `cannot start instance in zone "a-zone": volumes to be attached are in zone "b-zone"`)
+}
+
+func (s *environAZSuite) TestParseAvailabilityZonesVolumeZone(c *gc.C) {
+	s.StartInstArgs.AvailabilityZone = "b-zone"
+
+	zones, err := gce.ParseAvailabilityZones(s.Env, s.StartInstArgs)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(zones, jc.DeepEquals, []string{"b-zone"})
+	s.FakeCommon.CheckCalls(c, []gce.FakeCall{})
+}
+
 func (s *environAZSuite) TestParseAvailabilityZonesDistGroup(c *gc.C) {
 	s.FakeCommon.AZInstances = []common.AvailabilityZoneInstances{{
 		ZoneName:  "home-zone",
@@ -224,6 +224,15 @@ func getMetadata(args environs.StartInstanceParams) (map[string]string, error) {
 	if isStateServer(args.InstanceConfig) {
 		metadata[metadataKeyIsState] = metadataValueTrue
 	}
+	// GCE instances have no tags as such, so the resource tags
+	// are recorded in the instance metadata.
+	tagItems, err := tagsMetadata(args.InstanceConfig.Tags)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for key, value := range tagItems {
+		metadata[key] = value
+	}
 
 	return metadata, nil
 }
@@ -173,6 +173,40 @@ func (s *environBrokerSuite) TestGetMetadata(c *gc.C) {
 	c.Check(metadata, gc.DeepEquals, s.Metadata)
 }
 
+func (s *environBrokerSuite) TestGetMetadataResourceTags(c *gc.C) {
+	s.StartInstArgs.InstanceConfig.Tags = map[string]string{
+		"juju-env-uuid": "deadbeef",
+		"juju-is-state": "true",
+		"cost-centre":   "1234",
+	}
+	metadata, err := gce.GetMetadata(s.StartInstArgs)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The state server item comes from the instance config alone,
+	// and user tags are prefixed.
+	expected := make(map[string]string)
+	for k, v := range s.Metadata {
+		expected[k] = v
+	}
+	expected["juju-env-uuid"] = "deadbeef"
+	expected["juju-tag-cost-centre"] = "1234"
+	c.Check(metadata, jc.DeepEquals, expected)
+}
+
+func (s *environBrokerSuite) TestGetMetadataReservedResourceTag(c *gc.C) {
+	for _, key := range []string{"sshKeys", "ssh-keys", "startup-script", "user-data"} {
+		s.StartInstArgs.InstanceConfig.Tags = map[string]string{key: "not allowed"}
+		_, err := gce.GetMetadata(s.StartInstArgs)
+		c.Check(err, gc.ErrorMatches, `resource tag "`+key+`" uses a reserved metadata key`)
+	}
+}
+
+func (s *environBrokerSuite) TestGetMetadataInvalidResourceTag(c *gc.C) {
+	s.StartInstArgs.InstanceConfig.Tags = map[string]string{"cost centre": "1234"}
+	_, err := gce.GetMetadata(s.StartInstArgs)
+	c.Check(err, gc.ErrorMatches, `resource tag "cost centre" is not a valid metadata key`)
+}
+
 func (s *environBrokerSuite) TestGetDisks(c *gc.C) {
 	diskSpecs := gce.GetDisks(s.spec, s.StartInstArgs.Constraints)
 
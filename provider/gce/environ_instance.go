@@ -4,6 +4,7 @@
 package gce
 
 import (
+	"path"
 	"strings"
 
 	"github.com/juju/errors"
@@ -108,8 +109,9 @@ func (env *environ) StateServerInstances() ([]instance.Id, error) {
 	var results []instance.Id
 	for _, inst := range instances {
 		metadata := inst.Metadata()
-		isState, ok := metadata[metadataKeyIsState]
-		if ok && isState == metadataValueTrue {
+		isState := metadata[metadataKeyIsState] == metadataValueTrue ||
+			metadata[metadataKeyIsStateV1] == metadataValueTrue
+		if isState {
 			results = append(results, instance.Id(inst.ID))
 		}
 	}
@@ -119,6 +121,36 @@ func (env *environ) StateServerInstances() ([]instance.Id, error) {
 	return results, nil
 }
 
+var _ environs.InstanceTagger = (*environ)(nil)
+
+// TagInstance implements environs.InstanceTagger. GCE instances have
+// no tags as such, so the tags are recorded in the instance metadata.
+// The tags of attached disks are recorded in their descriptions,
+// which cannot be changed once a disk is created, so they are not
+// updated.
+func (env *environ) TagInstance(id instance.Id, tags map[string]string) error {
+	metadata, err := tagsMetadata(tags)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	env = env.getSnapshot()
+
+	prefix := common.MachineFullName(env, "")
+	instances, err := env.gce.Instances(prefix, instStatuses...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, inst := range instances {
+		if inst.ID != string(id) {
+			continue
+		}
+		zone := path.Base(inst.ZoneName)
+		err := env.gce.UpdateMetadata(inst.ID, zone, metadata)
+		return errors.Annotatef(err, "tagging instance %q", id)
+	}
+	return errors.NotFoundf("instance %q", id)
+}
+
 // TODO(ericsnow) Turn into an interface.
 type instPlacement struct {
 	Zone *google.AvailabilityZone
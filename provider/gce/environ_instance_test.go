@@ -136,6 +136,61 @@ func (s *environInstSuite) TestStateServerInstancesMixed(c *gc.C) {
 	c.Check(ids, jc.DeepEquals, []instance.Id{"spam"})
 }
 
+func (s *environInstSuite) TestStateServerInstancesV1(c *gc.C) {
+	// Instances started before the state server metadata key was
+	// changed are still recognised.
+	inst := google.NewInstance(s.BaseInstance.InstanceSummary, nil)
+	inst.InstanceSummary.Metadata = map[string]string{
+		"juju-env-uuid": "true",
+	}
+	s.FakeConn.Insts = []google.Instance{*inst}
+
+	ids, err := s.Env.StateServerInstances()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(ids, jc.DeepEquals, []instance.Id{"spam"})
+}
+
+func (s *environInstSuite) TestTagInstance(c *gc.C) {
+	s.FakeConn.Insts = []google.Instance{*s.BaseInstance}
+
+	tags := map[string]string{
+		"juju-env-uuid": "deadbeef",
+		"juju-is-state": "true",
+		"cost-centre":   "1234",
+	}
+	err := s.Env.TagInstance("spam", tags)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 2)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "Instances")
+	c.Check(s.FakeConn.Calls[1].FuncName, gc.Equals, "UpdateMetadata")
+	c.Check(s.FakeConn.Calls[1].ID, gc.Equals, "spam")
+	c.Check(s.FakeConn.Calls[1].ZoneName, gc.Equals, "home-zone")
+	c.Check(s.FakeConn.Calls[1].Metadata, jc.DeepEquals, map[string]string{
+		"juju-env-uuid":        "deadbeef",
+		"juju-tag-cost-centre": "1234",
+	})
+}
+
+func (s *environInstSuite) TestTagInstanceReservedKey(c *gc.C) {
+	s.FakeConn.Insts = []google.Instance{*s.BaseInstance}
+
+	err := s.Env.TagInstance("spam", map[string]string{"startup-script": "rm -rf /"})
+
+	c.Check(err, gc.ErrorMatches, `resource tag "startup-script" uses a reserved metadata key`)
+	c.Check(s.FakeConn.Calls, gc.HasLen, 0)
+}
+
+func (s *environInstSuite) TestTagInstanceNotFound(c *gc.C) {
+	s.FakeConn.Insts = []google.Instance{*s.BaseInstance}
+
+	err := s.Env.TagInstance("eggs", map[string]string{"cost-centre": "1234"})
+
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	c.Check(s.FakeConn.Calls, gc.HasLen, 1)
+}
+
 func (s *environInstSuite) TestParsePlacement(c *gc.C) {
 	zone := google.NewZone("a-zone", google.StatusUp, "", "")
 	s.FakeConn.Zones = []google.AvailabilityZone{zone}
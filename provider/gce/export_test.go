@@ -8,6 +8,7 @@ import (
 	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider/gce/google"
+	"github.com/juju/juju/storage"
 )
 
 var (
@@ -17,6 +18,8 @@ var (
 	GetMetadata                                = getMetadata
 	GetDisks                                   = getDisks
 	ConfigImmutable                            = configImmutableFields
+
+	StorageProvider storage.Provider = &gceProvider{}
 )
 
 func ExposeInstBase(inst *environInstance) *google.Instance {
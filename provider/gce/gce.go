@@ -4,6 +4,9 @@
 package gce
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 
@@ -12,7 +15,11 @@ import (
 
 // The metadata keys used when creating new instances.
 const (
-	metadataKeyIsState = tags.JujuEnv
+	metadataKeyIsState = tags.JujuStateServer
+	// metadataKeyIsStateV1 is the key that recorded whether an
+	// instance is a state server before resource tags were added
+	// to instance metadata. It is now used for the environment UUID.
+	metadataKeyIsStateV1 = tags.JujuEnv
 	// This is defined by the cloud-init code:
 	// http://bazaar.launchpad.net/~cloud-init-dev/cloud-init/trunk/view/head:/cloudinit/sources/DataSourceGCE.py
 	// http://cloudinit.readthedocs.org/en/latest/
@@ -22,8 +29,55 @@ const (
 	// GCE uses this specific key for authentication (*handwaving*)
 	// https://cloud.google.com/compute/docs/instances#sshkeys
 	metadataKeySSHKeys = "sshKeys"
+	// metadataKeyTagPrefix prefixes the keys that record user
+	// resource tags, so that they cannot replace the items above or
+	// those that GCE itself interprets.
+	metadataKeyTagPrefix = "juju-tag-"
 )
 
+// reservedMetadataKeys holds the metadata keys that resource tags may
+// never set, as Juju, GCE or cloud-init interpret them.
+var reservedMetadataKeys = map[string]bool{
+	metadataKeyIsState:   true,
+	metadataKeyCloudInit: true,
+	metadataKeyEncoding:  true,
+	metadataKeySSHKeys:   true,
+	"ssh-keys":           true,
+	"startup-script":     true,
+	"startup-script-url": true,
+	"shutdown-script":    true,
+}
+
+// validMetadataKey matches the keys GCE accepts for instance metadata.
+var validMetadataKey = regexp.MustCompile("^[a-zA-Z0-9_-]{1,128}$")
+
+// tagsMetadata returns the instance metadata items that record the
+// given resource tags. Juju's own tags are recorded under their own
+// names, except for the state server tag, which is always derived
+// from the instance config; user tags are recorded with a prefix.
+// Tags named after reserved keys are refused, even though the prefix
+// keeps them apart, as they are almost certainly mistakes.
+func tagsMetadata(resourceTags map[string]string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for name, value := range resourceTags {
+		if name == tags.JujuStateServer {
+			continue
+		}
+		if reservedMetadataKeys[name] {
+			return nil, errors.Errorf("resource tag %q uses a reserved metadata key", name)
+		}
+		key := name
+		if !strings.HasPrefix(name, tags.JujuTagPrefix) {
+			key = metadataKeyTagPrefix + name
+		}
+		if !validMetadataKey.MatchString(key) {
+			return nil, errors.Errorf("resource tag %q is not a valid metadata key", name)
+		}
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
 // Common metadata values used when creating new instances.
 const (
 	metadataValueTrue  = "true"
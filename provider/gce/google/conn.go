@@ -27,6 +27,12 @@ type rawConnectionWrapper interface {
 	// given project, with the provided instance data. The call blocks
 	// until the instance is created or the request fails.
 	AddInstance(projectID, zone string, spec *compute.Instance) error
+	// SetMetadata sends a request to the GCE API to replace the
+	// metadata of the instance with the provided ID (in the specified
+	// zone). The metadata's fingerprint must match that of the
+	// instance's current metadata. The call blocks until the metadata
+	// is updated (or the request fails).
+	SetMetadata(projectID, zone, id string, metadata *compute.Metadata) error
 	// RemoveInstance sends a request to the GCE API to remove the instance
 	// with the provided ID (in the specified zone). The call blocks until
 	// the instance is removed (or the request fails).
@@ -52,6 +58,28 @@ type rawConnectionWrapper interface {
 	// GCE region. If none are found the the list is empty. Any failure in
 	// the low-level request is returned as an error.
 	ListAvailabilityZones(projectID, region string) ([]*compute.Zone, error)
+	// GetDisk sends a request to the GCE API for info about the named
+	// disk in the given zone. If the disk does not exist then
+	// errors.NotFound is returned.
+	GetDisk(projectID, zone, name string) (*compute.Disk, error)
+	// AddDisk sends a request to GCE to add a new persistent disk to
+	// the given zone, with the provided disk data. The call blocks
+	// until the disk is created or the request fails.
+	AddDisk(projectID, zone string, disk *compute.Disk) error
+	// RemoveDisk sends a request to the GCE API to remove the named
+	// disk from the given zone. If the disk does not exist then
+	// errors.NotFound is returned. The call blocks until the disk is
+	// removed or the request fails.
+	RemoveDisk(projectID, zone, name string) error
+	// AttachDisk sends a request to the GCE API to attach a disk to
+	// the identified instance (in the given zone). The call blocks
+	// until the disk is attached or the request fails.
+	AttachDisk(projectID, zone, instanceID string, disk *compute.AttachedDisk) error
+	// DetachDisk sends a request to the GCE API to detach the disk
+	// with the given device name from the identified instance (in the
+	// given zone). The call blocks until the disk is detached or the
+	// request fails.
+	DetachDisk(projectID, zone, instanceID, deviceName string) error
 }
 
 // TODO(ericsnow) Add specific error types for common failures
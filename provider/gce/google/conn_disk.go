@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package google
+
+import (
+	"github.com/juju/errors"
+	"google.golang.org/api/compute/v1"
+)
+
+// CreateDisk creates a new persistent disk in the given zone, based on
+// the spec's data, and returns it. The call blocks until the disk is
+// created or the request fails.
+func (gce *Connection) CreateDisk(zone string, spec DiskSpec) (*Disk, error) {
+	if spec.Name == "" {
+		return nil, errors.New("disk name not set")
+	}
+	raw := spec.newDetached(zone)
+	if err := gce.raw.AddDisk(gce.projectID, zone, raw); err != nil {
+		return nil, errors.Annotatef(err, "creating disk %q", spec.Name)
+	}
+	return gce.Disk(zone, spec.Name)
+}
+
+// Disk gets the up-to-date info about the named disk in the given
+// zone and returns it. If the disk does not exist then
+// errors.NotFound is returned.
+func (gce *Connection) Disk(zone, name string) (*Disk, error) {
+	raw, err := gce.raw.GetDisk(gce.projectID, zone, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return newDisk(raw), nil
+}
+
+// RemoveDisk removes the named disk from the given zone. If the disk
+// does not exist then errors.NotFound is returned. A disk that is
+// attached to an instance cannot be removed.
+func (gce *Connection) RemoveDisk(zone, name string) error {
+	err := gce.raw.RemoveDisk(gce.projectID, zone, name)
+	return errors.Trace(err)
+}
+
+// AttachDisk attaches the named disk to the identified instance. Both
+// must be in the given zone. The disk is exposed to the instance using
+// its name as the device name. If the disk is already attached to the
+// instance then this is a noop.
+func (gce *Connection) AttachDisk(zone, name, instanceID string, readOnly bool) error {
+	disk, err := gce.Disk(zone, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if disk.AttachedTo(instanceID) {
+		return nil
+	}
+
+	mode := diskModeRW
+	if readOnly {
+		mode = diskModeRO
+	}
+	attached := &compute.AttachedDisk{
+		Type:       diskTypePersistent,
+		Mode:       mode,
+		Source:     formatDiskSource(zone, name),
+		DeviceName: name,
+	}
+	err = gce.raw.AttachDisk(gce.projectID, zone, instanceID, attached)
+	return errors.Annotatef(err, "attaching disk %q to %q", name, instanceID)
+}
+
+// DetachDisk detaches the named disk from the identified instance. Both
+// must be in the given zone. If the disk is not attached to the
+// instance then this is a noop.
+func (gce *Connection) DetachDisk(zone, name, instanceID string) error {
+	disk, err := gce.Disk(zone, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !disk.AttachedTo(instanceID) {
+		return nil
+	}
+
+	err = gce.raw.DetachDisk(gce.projectID, zone, instanceID, name)
+	return errors.Annotatef(err, "detaching disk %q from %q", name, instanceID)
+}
@@ -0,0 +1,141 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package google_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"google.golang.org/api/compute/v1"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/provider/gce/google"
+)
+
+func (s *connSuite) TestConnectionCreateDisk(c *gc.C) {
+	s.FakeConn.Disk = &compute.Disk{
+		Name:   "spam",
+		Zone:   "a-zone",
+		SizeGb: 20,
+		Type:   "zones/a-zone/diskTypes/pd-ssd",
+		Status: google.DiskStatusReady,
+	}
+	spec := google.DiskSpec{
+		Name:               "spam",
+		SizeHintGB:         20,
+		PersistentDiskType: google.PersistentDiskSSD,
+	}
+	disk, err := s.Conn.CreateDisk("a-zone", spec)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(disk, jc.DeepEquals, &google.Disk{
+		Name:     "spam",
+		ZoneName: "a-zone",
+		SizeGB:   20,
+		Type:     "pd-ssd",
+		Status:   google.DiskStatusReady,
+	})
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 2)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "AddDisk")
+	c.Check(s.FakeConn.Calls[0].ProjectID, gc.Equals, "spam")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "a-zone")
+	c.Check(s.FakeConn.Calls[0].Disk, jc.DeepEquals, &compute.Disk{
+		Name:   "spam",
+		SizeGb: 20,
+		Type:   "zones/a-zone/diskTypes/pd-ssd",
+	})
+	c.Check(s.FakeConn.Calls[1].FuncName, gc.Equals, "GetDisk")
+	c.Check(s.FakeConn.Calls[1].Name, gc.Equals, "spam")
+}
+
+func (s *connSuite) TestConnectionCreateDiskNoName(c *gc.C) {
+	_, err := s.Conn.CreateDisk("a-zone", google.DiskSpec{})
+
+	c.Check(err, gc.ErrorMatches, "disk name not set")
+	c.Check(s.FakeConn.Calls, gc.HasLen, 0)
+}
+
+func (s *connSuite) TestConnectionCreateDiskFailed(c *gc.C) {
+	s.FakeConn.Err = errors.New("<unknown>")
+	_, err := s.Conn.CreateDisk("a-zone", google.DiskSpec{Name: "spam"})
+
+	c.Check(err, gc.ErrorMatches, `creating disk "spam": <unknown>`)
+}
+
+func (s *connSuite) TestConnectionDisk(c *gc.C) {
+	s.FakeConn.Disk = &compute.Disk{Name: "spam", Zone: "a-zone"}
+	disk, err := s.Conn.Disk("a-zone", "spam")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(disk.Name, gc.Equals, "spam")
+	c.Check(s.FakeConn.Calls, gc.HasLen, 1)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "GetDisk")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "a-zone")
+	c.Check(s.FakeConn.Calls[0].Name, gc.Equals, "spam")
+}
+
+func (s *connSuite) TestConnectionRemoveDisk(c *gc.C) {
+	err := s.Conn.RemoveDisk("a-zone", "spam")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(s.FakeConn.Calls, gc.HasLen, 1)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "RemoveDisk")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "a-zone")
+	c.Check(s.FakeConn.Calls[0].Name, gc.Equals, "spam")
+}
+
+func (s *connSuite) TestConnectionAttachDisk(c *gc.C) {
+	s.FakeConn.Disk = &compute.Disk{Name: "spam", Zone: "a-zone"}
+	err := s.Conn.AttachDisk("a-zone", "spam", "eggs", true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 2)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "GetDisk")
+	c.Check(s.FakeConn.Calls[1].FuncName, gc.Equals, "AttachDisk")
+	c.Check(s.FakeConn.Calls[1].ZoneName, gc.Equals, "a-zone")
+	c.Check(s.FakeConn.Calls[1].ID, gc.Equals, "eggs")
+	c.Check(s.FakeConn.Calls[1].Attached, jc.DeepEquals, &compute.AttachedDisk{
+		Type:       "PERSISTENT",
+		Mode:       "READ_ONLY",
+		Source:     "zones/a-zone/disks/spam",
+		DeviceName: "spam",
+	})
+}
+
+func (s *connSuite) TestConnectionAttachDiskAlreadyAttached(c *gc.C) {
+	s.FakeConn.Disk = &compute.Disk{
+		Name:  "spam",
+		Zone:  "a-zone",
+		Users: []string{"zones/a-zone/instances/eggs"},
+	}
+	err := s.Conn.AttachDisk("a-zone", "spam", "eggs", false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(s.FakeConn.Calls, gc.HasLen, 1)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "GetDisk")
+}
+
+func (s *connSuite) TestConnectionDetachDisk(c *gc.C) {
+	s.FakeConn.Disk = &compute.Disk{
+		Name:  "spam",
+		Zone:  "a-zone",
+		Users: []string{"zones/a-zone/instances/eggs"},
+	}
+	err := s.Conn.DetachDisk("a-zone", "spam", "eggs")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 2)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "GetDisk")
+	c.Check(s.FakeConn.Calls[1].FuncName, gc.Equals, "DetachDisk")
+	c.Check(s.FakeConn.Calls[1].ZoneName, gc.Equals, "a-zone")
+	c.Check(s.FakeConn.Calls[1].ID, gc.Equals, "eggs")
+	c.Check(s.FakeConn.Calls[1].Name, gc.Equals, "spam")
+}
+
+func (s *connSuite) TestConnectionDetachDiskNotAttached(c *gc.C) {
+	s.FakeConn.Disk = &compute.Disk{Name: "spam", Zone: "a-zone"}
+	err := s.Conn.DetachDisk("a-zone", "spam", "eggs")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(s.FakeConn.Calls, gc.HasLen, 1)
+}
@@ -91,6 +91,30 @@ func (gce *Connection) Instances(prefix string, statuses ...string) ([]Instance,
 	return insts, nil
 }
 
+// UpdateMetadata sets the given items of the metadata of the given
+// instance (in the given zone), leaving any other items alone.
+func (gce *Connection) UpdateMetadata(id, zone string, metadata map[string]string) error {
+	raw, err := gce.raw.GetInstance(gce.projectID, zone, id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	updated := unpackMetadata(raw.Metadata)
+	if updated == nil {
+		updated = make(map[string]string)
+	}
+	for key, value := range metadata {
+		updated[key] = value
+	}
+	packed := packMetadata(updated)
+	if raw.Metadata != nil {
+		// GCE rejects the update if the metadata has
+		// changed since it was read.
+		packed.Fingerprint = raw.Metadata.Fingerprint
+	}
+	err = gce.raw.SetMetadata(gce.projectID, zone, id, packed)
+	return errors.Annotatef(err, "updating metadata of instance %q", id)
+}
+
 // removeInstance sends a request to the GCE API to remove the instance
 // with the provided ID (in the specified zone). The call blocks until
 // the instance is removed (or the request fails).
@@ -199,6 +199,40 @@ func (s *connSuite) TestConnectionInstancesFailure(c *gc.C) {
 	c.Check(errors.Cause(err), gc.Equals, failure)
 }
 
+func (s *connSuite) TestConnectionUpdateMetadata(c *gc.C) {
+	s.RawMetadata.Fingerprint = "abc"
+	s.FakeConn.Instance = &s.RawInstanceFull
+
+	err := s.Conn.UpdateMetadata("spam", "a-zone", map[string]string{
+		"cost-centre": "1234",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 2)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "GetInstance")
+	c.Check(s.FakeConn.Calls[1].FuncName, gc.Equals, "SetMetadata")
+	c.Check(s.FakeConn.Calls[1].ProjectID, gc.Equals, "spam")
+	c.Check(s.FakeConn.Calls[1].ZoneName, gc.Equals, "a-zone")
+	c.Check(s.FakeConn.Calls[1].ID, gc.Equals, "spam")
+	metadata := s.FakeConn.Calls[1].Metadata
+	c.Check(metadata.Fingerprint, gc.Equals, "abc")
+	c.Check(google.UnpackMetadata(metadata), jc.DeepEquals, map[string]string{
+		"eggs":        "steak",
+		"cost-centre": "1234",
+	})
+}
+
+func (s *connSuite) TestConnectionUpdateMetadataFailed(c *gc.C) {
+	s.FakeConn.Instance = &s.RawInstanceFull
+	failure := errors.New("<unknown>")
+	s.FakeConn.Err = failure
+	s.FakeConn.FailOnCall = 1
+
+	err := s.Conn.UpdateMetadata("spam", "a-zone", map[string]string{"a": "b"})
+
+	c.Check(errors.Cause(err), gc.Equals, failure)
+}
+
 func (s *connSuite) TestConnectionRemoveInstance(c *gc.C) {
 	err := google.ConnRemoveInstance(s.Conn, "spam", "a-zone")
 
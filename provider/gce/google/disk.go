@@ -4,6 +4,9 @@
 package google
 
 import (
+	"fmt"
+	"path"
+
 	"google.golang.org/api/compute/v1"
 )
 
@@ -13,6 +16,20 @@ const (
 	diskTypePersistent = "PERSISTENT"
 )
 
+// The different types of persistent disks supported by GCE.
+const (
+	PersistentDiskStandard = "pd-standard"
+	PersistentDiskSSD      = "pd-ssd"
+)
+
+// The status values of GCE persistent disks.
+const (
+	DiskStatusCreating  = "CREATING"
+	DiskStatusFailed    = "FAILED"
+	DiskStatusReady     = "READY"
+	DiskStatusRestoring = "RESTORING"
+)
+
 // The different disk modes supported by GCE.
 const (
 	diskModeRW = "READ_WRITE"
@@ -50,6 +67,17 @@ type DiskSpec struct {
 	// AutoDelete indicates that the attached disk should be removed
 	// when the instance to which it is attached is removed.
 	AutoDelete bool
+	// Name is the name of the disk, which must be unique within its
+	// zone. (detached only)
+	Name string
+	// PersistentDiskType is the type of persistent disk to create,
+	// e.g. pd-standard or pd-ssd. GCE defaults to pd-standard.
+	// (detached only)
+	PersistentDiskType string
+	// Description is a free-form description of the disk. GCE disks
+	// have no metadata, so this is where resource tags are recorded.
+	// (detached only)
+	Description string
 }
 
 // TooSmall checks the spec's size hint and indicates whether or not
@@ -100,3 +128,69 @@ func (ds *DiskSpec) newAttached() *compute.AttachedDisk {
 	}
 	return &disk
 }
+
+// newDetached builds a compute.Disk for a new persistent disk in the
+// given zone, using the information in the disk spec, and returns it.
+func (ds *DiskSpec) newDetached(zone string) *compute.Disk {
+	disk := compute.Disk{
+		Name:        ds.Name,
+		Description: ds.Description,
+		SizeGb:      int64(ds.SizeGB()),
+		SourceImage: ds.ImageURL,
+	}
+	if ds.PersistentDiskType != "" {
+		disk.Type = formatDiskType(zone, ds.PersistentDiskType)
+	}
+	return &disk
+}
+
+// Disk represents a single GCE persistent disk.
+type Disk struct {
+	// Name is the name of the disk, unique within its zone.
+	Name string
+	// ZoneName is the name of the zone in which the disk resides.
+	ZoneName string
+	// SizeGB is the size of the disk in Gigabytes.
+	SizeGB uint64
+	// Type is the type of persistent disk (e.g. pd-ssd).
+	Type string
+	// Status is the disk's status, matching one of the DiskStatus*
+	// constants defined in the package.
+	Status string
+	// InstanceIDs holds the IDs of the instances to which the disk
+	// is attached.
+	InstanceIDs []string
+}
+
+func newDisk(raw *compute.Disk) *Disk {
+	disk := Disk{
+		Name:     raw.Name,
+		ZoneName: path.Base(raw.Zone),
+		SizeGB:   uint64(raw.SizeGb),
+		Type:     path.Base(raw.Type),
+		Status:   raw.Status,
+	}
+	for _, user := range raw.Users {
+		disk.InstanceIDs = append(disk.InstanceIDs, path.Base(user))
+	}
+	return &disk
+}
+
+// AttachedTo indicates whether or not the disk is attached to the
+// instance with the given ID.
+func (d *Disk) AttachedTo(instanceID string) bool {
+	for _, id := range d.InstanceIDs {
+		if id == instanceID {
+			return true
+		}
+	}
+	return false
+}
+
+func formatDiskType(zone, name string) string {
+	return fmt.Sprintf("zones/%s/diskTypes/%s", zone, name)
+}
+
+func formatDiskSource(zone, name string) string {
+	return fmt.Sprintf("zones/%s/disks/%s", zone, name)
+}
@@ -140,3 +140,52 @@ func (s *diskSuite) TestRootDiskSpec(c *gc.C) {
 		diskMode: "READ_WRITE",
 	})
 }
+
+func (s *diskSuite) TestDiskSpecNewDetached(c *gc.C) {
+	spec := google.DiskSpec{
+		Name:               "spam",
+		SizeHintGB:         20,
+		PersistentDiskType: google.PersistentDiskSSD,
+		Description:        "juju-env-uuid=deadbeef",
+	}
+	disk := google.NewDetached(spec, "a-zone")
+
+	c.Check(disk, jc.DeepEquals, &compute.Disk{
+		Name:        "spam",
+		Description: "juju-env-uuid=deadbeef",
+		SizeGb:      20,
+		Type:        "zones/a-zone/diskTypes/pd-ssd",
+	})
+}
+
+func (s *diskSuite) TestDiskSpecNewDetachedDefaults(c *gc.C) {
+	spec := google.DiskSpec{Name: "spam"}
+	disk := google.NewDetached(spec, "a-zone")
+
+	c.Check(disk, jc.DeepEquals, &compute.Disk{
+		Name:   "spam",
+		SizeGb: 10,
+	})
+}
+
+func (s *diskSuite) TestNewDisk(c *gc.C) {
+	disk := google.NewDiskRaw(&compute.Disk{
+		Name:   "spam",
+		Zone:   "https://www.googleapis.com/compute/v1/projects/eggs/zones/a-zone",
+		SizeGb: 20,
+		Type:   "https://www.googleapis.com/compute/v1/projects/eggs/zones/a-zone/diskTypes/pd-ssd",
+		Status: google.DiskStatusReady,
+		Users:  []string{"https://www.googleapis.com/compute/v1/projects/eggs/zones/a-zone/instances/ham"},
+	})
+
+	c.Check(disk, jc.DeepEquals, &google.Disk{
+		Name:        "spam",
+		ZoneName:    "a-zone",
+		SizeGB:      20,
+		Type:        "pd-ssd",
+		Status:      google.DiskStatusReady,
+		InstanceIDs: []string{"ham"},
+	})
+	c.Check(disk.AttachedTo("ham"), jc.IsTrue)
+	c.Check(disk.AttachedTo("eggs"), jc.IsFalse)
+}
@@ -11,6 +11,7 @@ var (
 	NewRawConnection = &newRawConnection
 
 	NewInstanceRaw    = newInstance
+	NewDiskRaw        = newDisk
 	PackMetadata      = packMetadata
 	UnpackMetadata    = unpackMetadata
 	FormatMachineType = formatMachineType
@@ -30,6 +31,10 @@ func NewAttached(spec DiskSpec) *compute.AttachedDisk {
 	return spec.newAttached()
 }
 
+func NewDetached(spec DiskSpec, zone string) *compute.Disk {
+	return spec.newDetached(zone)
+}
+
 func NewAvailabilityZone(zone *compute.Zone) AvailabilityZone {
 	return AvailabilityZone{zone: zone}
 }
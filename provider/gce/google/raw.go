@@ -105,6 +105,17 @@ func (rc *rawConn) AddInstance(projectID, zoneName string, spec *compute.Instanc
 	return errors.Trace(err)
 }
 
+func (rc *rawConn) SetMetadata(projectID, zone, id string, metadata *compute.Metadata) error {
+	call := rc.Instances.SetMetadata(projectID, zone, id, metadata)
+	operation, err := call.Do()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = rc.waitOperation(projectID, operation, attemptsShort)
+	return errors.Trace(err)
+}
+
 func (rc *rawConn) RemoveInstance(projectID, zone, id string) error {
 	call := rc.Instances.Delete(projectID, zone, id)
 	operation, err := call.Do()
@@ -187,6 +198,56 @@ func (rc *rawConn) ListAvailabilityZones(projectID, region string) ([]*compute.Z
 	return results, nil
 }
 
+func (rc *rawConn) GetDisk(projectID, zone, name string) (*compute.Disk, error) {
+	call := rc.Disks.Get(projectID, zone, name)
+	disk, err := call.Do()
+	return disk, errors.Trace(convertRawAPIError(err))
+}
+
+func (rc *rawConn) AddDisk(projectID, zone string, disk *compute.Disk) error {
+	call := rc.Disks.Insert(projectID, zone, disk)
+	operation, err := call.Do()
+	if err != nil {
+		return errors.Annotate(err, "sending new disk request")
+	}
+
+	err = rc.waitOperation(projectID, operation, attemptsLong)
+	return errors.Trace(err)
+}
+
+func (rc *rawConn) RemoveDisk(projectID, zone, name string) error {
+	call := rc.Disks.Delete(projectID, zone, name)
+	operation, err := call.Do()
+	if err != nil {
+		return errors.Trace(convertRawAPIError(err))
+	}
+
+	err = rc.waitOperation(projectID, operation, attemptsLong)
+	return errors.Trace(convertRawAPIError(err))
+}
+
+func (rc *rawConn) AttachDisk(projectID, zone, instanceID string, disk *compute.AttachedDisk) error {
+	call := rc.Instances.AttachDisk(projectID, zone, instanceID, disk)
+	operation, err := call.Do()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = rc.waitOperation(projectID, operation, attemptsLong)
+	return errors.Trace(err)
+}
+
+func (rc *rawConn) DetachDisk(projectID, zone, instanceID, deviceName string) error {
+	call := rc.Instances.DetachDisk(projectID, zone, instanceID, deviceName)
+	operation, err := call.Do()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = rc.waitOperation(projectID, operation, attemptsLong)
+	return errors.Trace(err)
+}
+
 type waitError struct {
 	op    *compute.Operation
 	cause error
@@ -153,6 +153,9 @@ type fakeCall struct {
 	Instance  *compute.Instance
 	InstValue compute.Instance
 	Firewall  *compute.Firewall
+	Disk      *compute.Disk
+	Attached  *compute.AttachedDisk
+	Metadata  *compute.Metadata
 }
 
 type fakeConn struct {
@@ -163,6 +166,7 @@ type fakeConn struct {
 	Instances  []*compute.Instance
 	Firewall   *compute.Firewall
 	Zones      []*compute.Zone
+	Disk       *compute.Disk
 	Err        error
 	FailOnCall int
 }
@@ -230,6 +234,23 @@ func (rc *fakeConn) AddInstance(projectID, zoneName string, spec *compute.Instan
 	return err
 }
 
+func (rc *fakeConn) SetMetadata(projectID, zone, id string, metadata *compute.Metadata) error {
+	call := fakeCall{
+		FuncName:  "SetMetadata",
+		ProjectID: projectID,
+		ZoneName:  zone,
+		ID:        id,
+		Metadata:  metadata,
+	}
+	rc.Calls = append(rc.Calls, call)
+
+	err := rc.Err
+	if len(rc.Calls) != rc.FailOnCall+1 {
+		err = nil
+	}
+	return err
+}
+
 func (rc *fakeConn) RemoveInstance(projectID, zone, id string) error {
 	call := fakeCall{
 		FuncName:  "RemoveInstance",
@@ -321,3 +342,85 @@ func (rc *fakeConn) ListAvailabilityZones(projectID, region string) ([]*compute.
 	}
 	return rc.Zones, err
 }
+
+func (rc *fakeConn) GetDisk(projectID, zone, name string) (*compute.Disk, error) {
+	call := fakeCall{
+		FuncName:  "GetDisk",
+		ProjectID: projectID,
+		ZoneName:  zone,
+		Name:      name,
+	}
+	rc.Calls = append(rc.Calls, call)
+
+	err := rc.Err
+	if len(rc.Calls) != rc.FailOnCall+1 {
+		err = nil
+	}
+	return rc.Disk, err
+}
+
+func (rc *fakeConn) AddDisk(projectID, zone string, disk *compute.Disk) error {
+	call := fakeCall{
+		FuncName:  "AddDisk",
+		ProjectID: projectID,
+		ZoneName:  zone,
+		Disk:      disk,
+	}
+	rc.Calls = append(rc.Calls, call)
+
+	err := rc.Err
+	if len(rc.Calls) != rc.FailOnCall+1 {
+		err = nil
+	}
+	return err
+}
+
+func (rc *fakeConn) RemoveDisk(projectID, zone, name string) error {
+	call := fakeCall{
+		FuncName:  "RemoveDisk",
+		ProjectID: projectID,
+		ZoneName:  zone,
+		Name:      name,
+	}
+	rc.Calls = append(rc.Calls, call)
+
+	err := rc.Err
+	if len(rc.Calls) != rc.FailOnCall+1 {
+		err = nil
+	}
+	return err
+}
+
+func (rc *fakeConn) AttachDisk(projectID, zone, instanceID string, disk *compute.AttachedDisk) error {
+	call := fakeCall{
+		FuncName:  "AttachDisk",
+		ProjectID: projectID,
+		ZoneName:  zone,
+		ID:        instanceID,
+		Attached:  disk,
+	}
+	rc.Calls = append(rc.Calls, call)
+
+	err := rc.Err
+	if len(rc.Calls) != rc.FailOnCall+1 {
+		err = nil
+	}
+	return err
+}
+
+func (rc *fakeConn) DetachDisk(projectID, zone, instanceID, deviceName string) error {
+	call := fakeCall{
+		FuncName:  "DetachDisk",
+		ProjectID: projectID,
+		ZoneName:  zone,
+		ID:        instanceID,
+		Name:      deviceName,
+	}
+	rc.Calls = append(rc.Calls, call)
+
+	err := rc.Err
+	if len(rc.Calls) != rc.FailOnCall+1 {
+		err = nil
+	}
+	return err
+}
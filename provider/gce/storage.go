@@ -0,0 +1,398 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+	"github.com/juju/utils"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/gce/google"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/poolmanager"
+)
+
+const (
+	GCEProviderType = storage.ProviderType("gce")
+
+	// Config attributes
+
+	// The persistent disk type (default pd-standard):
+	//   "pd-standard" for standard (magnetic) disks,
+	//   "pd-ssd" for SSD disks.
+	GCEDiskType = "disk-type"
+)
+
+const (
+	// diskSizeMaxGB is the maximum size (in gigabytes) of a GCE
+	// persistent disk.
+	diskSizeMaxGB = 10240
+
+	// volumeIdSeparator separates the zone from the rest of the disk
+	// name in the IDs of volumes created by the gce storage provider.
+	volumeIdSeparator = "--"
+
+	// hardwareIdPrefix is the prefix of the hardware IDs of GCE
+	// persistent disks, which are followed by the disk's device name.
+	hardwareIdPrefix = "scsi-0Google_PersistentDisk_"
+)
+
+func init() {
+	gcessdPool, _ := storage.NewConfig("gce-ssd", GCEProviderType, map[string]interface{}{
+		GCEDiskType: google.PersistentDiskSSD,
+	})
+	defaultPools := []*storage.Config{
+		gcessdPool,
+	}
+	poolmanager.RegisterDefaultStoragePools(defaultPools)
+}
+
+// gceProvider creates volume sources which use GCE persistent disks.
+type gceProvider struct{}
+
+var _ storage.Provider = (*gceProvider)(nil)
+
+var gceConfigFields = schema.Fields{
+	storage.Persistent: schema.Bool(),
+	GCEDiskType: schema.OneOf(
+		schema.Const(google.PersistentDiskStandard),
+		schema.Const(google.PersistentDiskSSD),
+	),
+}
+
+var gceConfigChecker = schema.FieldMap(
+	gceConfigFields,
+	schema.Defaults{
+		storage.Persistent: true,
+		GCEDiskType:        google.PersistentDiskStandard,
+	},
+)
+
+type gceConfig struct {
+	diskType string
+}
+
+func newGCEConfig(attrs map[string]interface{}) (*gceConfig, error) {
+	out, err := gceConfigChecker.Coerce(attrs, nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "validating GCE storage config")
+	}
+	coerced := out.(map[string]interface{})
+	if !coerced[storage.Persistent].(bool) {
+		// Disks are attached without auto-delete, so that
+		// they outlive the instances they are attached to.
+		return nil, errors.New("cannot create a non-persistent GCE disk")
+	}
+	return &gceConfig{
+		diskType: coerced[GCEDiskType].(string),
+	}, nil
+}
+
+// ValidateConfig is defined on the Provider interface.
+func (g *gceProvider) ValidateConfig(cfg *storage.Config) error {
+	_, err := newGCEConfig(cfg.Attrs())
+	return errors.Trace(err)
+}
+
+// Supports is defined on the Provider interface.
+func (g *gceProvider) Supports(k storage.StorageKind) bool {
+	return k == storage.StorageKindBlock
+}
+
+// Scope is defined on the Provider interface.
+func (g *gceProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+// Dynamic is defined on the Provider interface.
+func (g *gceProvider) Dynamic() bool {
+	return true
+}
+
+// VolumeSource is defined on the Provider interface.
+func (g *gceProvider) VolumeSource(environConfig *config.Config, cfg *storage.Config) (storage.VolumeSource, error) {
+	if err := g.ValidateConfig(cfg); err != nil {
+		return nil, errors.Trace(err)
+	}
+	env, err := newEnviron(environConfig)
+	if err != nil {
+		return nil, errors.Annotate(err, "creating GCE connection")
+	}
+	return &gceVolumeSource{env: env}, nil
+}
+
+// FilesystemSource is defined on the Provider interface.
+func (g *gceProvider) FilesystemSource(environConfig *config.Config, providerConfig *storage.Config) (storage.FilesystemSource, error) {
+	return nil, errors.NotSupportedf("filesystems")
+}
+
+type gceVolumeSource struct {
+	env *environ
+}
+
+var _ storage.VolumeSource = (*gceVolumeSource)(nil)
+
+// CreateVolumes is specified on the storage.VolumeSource interface.
+//
+// GCE persistent disks live in a single zone and may only be attached
+// to instances in that zone, so each disk is created in the zone of
+// the instance it is to be attached to.
+func (v *gceVolumeSource) CreateVolumes(params []storage.VolumeParams) (_ []storage.Volume, _ []storage.VolumeAttachment, err error) {
+	volumes := make([]storage.Volume, 0, len(params))
+	volumeAttachments := make([]storage.VolumeAttachment, 0, len(params))
+
+	// If there's an error, we detach and delete any disks that
+	// were created.
+	var attached []storage.VolumeAttachmentParams
+	defer func() {
+		if err == nil {
+			return
+		}
+		if err := v.DetachVolumes(attached); err != nil {
+			logger.Warningf("error detaching volumes: %v", err)
+		}
+		volIds := make([]string, len(volumes))
+		for i, volume := range volumes {
+			volIds[i] = volume.VolumeId
+		}
+		for i, volErr := range v.DestroyVolumes(volIds) {
+			if volErr != nil {
+				logger.Warningf("error cleaning up volume %v: %v", volumes[i].Tag, volErr)
+			}
+		}
+	}()
+
+	// First, validate the params before we use them.
+	instIds := set.NewStrings()
+	for _, p := range params {
+		if err := v.ValidateVolumeParams(p); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		instIds.Add(string(p.Attachment.InstanceId))
+	}
+	zones, err := v.instanceZones(instIds.Values())
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "querying instance details")
+	}
+
+	for _, p := range params {
+		instId := string(p.Attachment.InstanceId)
+		zone := zones[instId]
+		cfg, _ := newGCEConfig(p.Attributes)
+		name, err := newVolumeId(zone)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		disk, err := v.env.gce.CreateDisk(zone, google.DiskSpec{
+			Name:               name,
+			SizeHintGB:         mibToGb(p.Size),
+			PersistentDiskType: cfg.diskType,
+			Description:        diskDescription(p.ResourceTags),
+		})
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "creating volume %v", p.Tag)
+		}
+		volumes = append(volumes, storage.Volume{
+			p.Tag,
+			diskVolumeInfo(disk),
+		})
+
+		if err := v.env.gce.AttachDisk(zone, name, instId, p.Attachment.ReadOnly); err != nil {
+			return nil, nil, errors.Annotatef(err, "attaching %v to %v", name, instId)
+		}
+		attached = append(attached, storage.VolumeAttachmentParams{
+			AttachmentParams: *p.Attachment,
+			Volume:           p.Tag,
+			VolumeId:         name,
+		})
+		volumeAttachments = append(volumeAttachments, storage.VolumeAttachment{
+			p.Tag,
+			p.Attachment.Machine,
+			storage.VolumeAttachmentInfo{
+				ReadOnly: p.Attachment.ReadOnly,
+			},
+		})
+	}
+	return volumes, volumeAttachments, nil
+}
+
+// DescribeVolumes is specified on the storage.VolumeSource interface.
+func (v *gceVolumeSource) DescribeVolumes(volIds []string) ([]storage.VolumeInfo, error) {
+	vols := make([]storage.VolumeInfo, len(volIds))
+	for i, volumeId := range volIds {
+		zone, name, err := parseVolumeId(volumeId)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		disk, err := v.env.gce.Disk(zone, name)
+		if err != nil {
+			return nil, errors.Annotatef(err, "querying volume %q", volumeId)
+		}
+		vols[i] = diskVolumeInfo(disk)
+	}
+	return vols, nil
+}
+
+// DestroyVolumes is specified on the storage.VolumeSource interface.
+func (v *gceVolumeSource) DestroyVolumes(volIds []string) []error {
+	results := make([]error, len(volIds))
+	for i, volumeId := range volIds {
+		zone, name, err := parseVolumeId(volumeId)
+		if err != nil {
+			results[i] = errors.Trace(err)
+			continue
+		}
+		err = v.env.gce.RemoveDisk(zone, name)
+		if err != nil && !errors.IsNotFound(err) {
+			results[i] = errors.Annotatef(err, "destroying %q", volumeId)
+		}
+	}
+	return results
+}
+
+// ValidateVolumeParams is specified on the storage.VolumeSource interface.
+func (v *gceVolumeSource) ValidateVolumeParams(params storage.VolumeParams) error {
+	if _, err := newGCEConfig(params.Attributes); err != nil {
+		return errors.Trace(err)
+	}
+	if params.Attachment == nil {
+		return errors.Errorf("cannot create %v: GCE disks must be created in the zone of an instance", params.Tag)
+	}
+	if size := mibToGb(params.Size); size > diskSizeMaxGB {
+		return errors.Errorf("%d GB exceeds the maximum of %d GB", size, diskSizeMaxGB)
+	}
+	return nil
+}
+
+// AttachVolumes is specified on the storage.VolumeSource interface.
+func (v *gceVolumeSource) AttachVolumes(attachParams []storage.VolumeAttachmentParams) ([]storage.VolumeAttachment, error) {
+	attachments := make([]storage.VolumeAttachment, len(attachParams))
+	for i, params := range attachParams {
+		zone, name, err := parseVolumeId(params.VolumeId)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		instId := string(params.InstanceId)
+		if err := v.env.gce.AttachDisk(zone, name, instId, params.ReadOnly); err != nil {
+			return nil, errors.Annotatef(err, "attaching %v to %v", params.VolumeId, params.InstanceId)
+		}
+		attachments[i] = storage.VolumeAttachment{
+			params.Volume,
+			params.Machine,
+			storage.VolumeAttachmentInfo{
+				ReadOnly: params.ReadOnly,
+			},
+		}
+	}
+	return attachments, nil
+}
+
+// DetachVolumes is specified on the storage.VolumeSource interface.
+func (v *gceVolumeSource) DetachVolumes(attachParams []storage.VolumeAttachmentParams) error {
+	for _, params := range attachParams {
+		zone, name, err := parseVolumeId(params.VolumeId)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := v.env.gce.DetachDisk(zone, name, string(params.InstanceId)); err != nil {
+			return errors.Annotatef(err, "detaching %v from %v", params.Volume, params.Machine)
+		}
+	}
+	return nil
+}
+
+// instanceZones returns a mapping from the specified instance IDs to
+// the zones in which the instances reside. If any of the specified IDs
+// does not refer to a running instance, it will cause an error to be
+// returned.
+func (v *gceVolumeSource) instanceZones(instIds []string) (map[string]string, error) {
+	ids := make([]instance.Id, len(instIds))
+	for i, id := range instIds {
+		ids[i] = instance.Id(id)
+	}
+	instances, err := v.env.Instances(ids)
+	switch err {
+	case nil, environs.ErrNoInstances, environs.ErrPartialInstances:
+	default:
+		return nil, errors.Trace(err)
+	}
+	zones := make(map[string]string)
+	var notRunning []string
+	for i, inst := range instances {
+		if inst == nil {
+			notRunning = append(notRunning, instIds[i])
+			continue
+		}
+		zones[instIds[i]] = inst.(*environInstance).base.ZoneName
+	}
+	if len(notRunning) > 0 {
+		return nil, errors.Errorf(
+			"volumes can only be attached to running instances, these instances are not running: %v",
+			strings.Join(notRunning, ","),
+		)
+	}
+	return zones, nil
+}
+
+// newVolumeId returns a new unique volume ID, which is also the name
+// of the disk, for a disk in the given zone. The zone is included so
+// that it can be recovered from the volume ID alone.
+func newVolumeId(zone string) (string, error) {
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return "", errors.Annotate(err, "generating volume ID")
+	}
+	return zone + volumeIdSeparator + uuid.String(), nil
+}
+
+// parseVolumeId returns the zone and disk name of the disk with the
+// given volume ID.
+func parseVolumeId(volumeId string) (zone, name string, _ error) {
+	pos := strings.Index(volumeId, volumeIdSeparator)
+	if pos <= 0 {
+		return "", "", errors.NotValidf("volume ID %q", volumeId)
+	}
+	return volumeId[:pos], volumeId, nil
+}
+
+// diskDescription returns the description of a disk with the given
+// resource tags. GCE disks have no tags, so the tags are recorded in
+// the description as space-separated key=value pairs, sorted by key.
+func diskDescription(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+func diskVolumeInfo(disk *google.Disk) storage.VolumeInfo {
+	return storage.VolumeInfo{
+		VolumeId:   disk.Name,
+		HardwareId: hardwareIdPrefix + disk.Name,
+		Size:       gbToMib(disk.SizeGB),
+		// Disks are never auto-deleted with their instances.
+		Persistent: true,
+		Zone:       disk.ZoneName,
+	}
+}
+
+// mibToGb converts mebibytes to gigabytes, rounding up. GCE disk
+// sizes are in gigabytes, but are in fact GiB.
+func mibToGb(m uint64) uint64 {
+	return (m + 1023) / 1024
+}
+
+// gbToMib converts gigabytes to mebibytes.
+func gbToMib(g uint64) uint64 {
+	return g * 1024
+}
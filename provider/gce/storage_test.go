@@ -0,0 +1,253 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package gce_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/gce"
+	"github.com/juju/juju/provider/gce/google"
+	"github.com/juju/juju/storage"
+)
+
+type storageSuite struct {
+	gce.BaseSuite
+
+	source storage.VolumeSource
+}
+
+var _ = gc.Suite(&storageSuite{})
+
+func (s *storageSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+
+	cfg, err := storage.NewConfig("gce", gce.GCEProviderType, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.source, err = gce.StorageProvider.VolumeSource(s.Config, cfg)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.FakeEnviron.Insts = []instance.Instance{s.Instance}
+	s.FakeConn.Disk = &google.Disk{
+		Name:     "home-zone--c930380d-8337-4bf5-b07a-9dbb5ae771e4",
+		ZoneName: "home-zone",
+		SizeGB:   10,
+		Type:     google.PersistentDiskStandard,
+		Status:   google.DiskStatusReady,
+	}
+}
+
+func (s *storageSuite) volumeParams(attrs map[string]interface{}) storage.VolumeParams {
+	return storage.VolumeParams{
+		Tag:        names.NewVolumeTag("0"),
+		Size:       2048,
+		Provider:   gce.GCEProviderType,
+		Attributes: attrs,
+		Attachment: &storage.VolumeAttachmentParams{
+			AttachmentParams: storage.AttachmentParams{
+				Machine:    names.NewMachineTag("1"),
+				InstanceId: "spam",
+			},
+		},
+	}
+}
+
+func (s *storageSuite) TestValidateConfig(c *gc.C) {
+	for _, attrs := range []map[string]interface{}{
+		{},
+		{"disk-type": "pd-standard"},
+		{"disk-type": "pd-ssd", "persistent": true},
+	} {
+		cfg, err := storage.NewConfig("gce", gce.GCEProviderType, attrs)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(gce.StorageProvider.ValidateConfig(cfg), jc.ErrorIsNil)
+	}
+}
+
+func (s *storageSuite) TestValidateConfigInvalidDiskType(c *gc.C) {
+	cfg, err := storage.NewConfig("gce", gce.GCEProviderType, map[string]interface{}{
+		"disk-type": "local-ssd",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = gce.StorageProvider.ValidateConfig(cfg)
+	c.Assert(err, gc.ErrorMatches, `validating GCE storage config: disk-type: .*`)
+}
+
+func (s *storageSuite) TestValidateConfigNonPersistent(c *gc.C) {
+	cfg, err := storage.NewConfig("gce", gce.GCEProviderType, map[string]interface{}{
+		"persistent": false,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = gce.StorageProvider.ValidateConfig(cfg)
+	c.Assert(err, gc.ErrorMatches, "cannot create a non-persistent GCE disk")
+}
+
+func (s *storageSuite) TestSupports(c *gc.C) {
+	c.Assert(gce.StorageProvider.Supports(storage.StorageKindBlock), jc.IsTrue)
+	c.Assert(gce.StorageProvider.Supports(storage.StorageKindFilesystem), jc.IsFalse)
+}
+
+func (s *storageSuite) TestCreateVolumes(c *gc.C) {
+	params := s.volumeParams(map[string]interface{}{"disk-type": "pd-ssd"})
+	params.ResourceTags = map[string]string{
+		"juju-env-uuid": "deadbeef",
+		"cost-centre":   "1234",
+	}
+	volumes, attachments, err := s.source.CreateVolumes([]storage.VolumeParams{params})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(volumes, jc.DeepEquals, []storage.Volume{{
+		Tag: names.NewVolumeTag("0"),
+		VolumeInfo: storage.VolumeInfo{
+			VolumeId:   "home-zone--c930380d-8337-4bf5-b07a-9dbb5ae771e4",
+			HardwareId: "scsi-0Google_PersistentDisk_home-zone--c930380d-8337-4bf5-b07a-9dbb5ae771e4",
+			Size:       10240,
+			Persistent: true,
+			Zone:       "home-zone",
+		},
+	}})
+	c.Check(attachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:  names.NewVolumeTag("0"),
+		Machine: names.NewMachineTag("1"),
+	}})
+
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 2)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "CreateDisk")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "home-zone")
+	spec := s.FakeConn.Calls[0].DiskSpec
+	c.Check(spec.Name, jc.HasPrefix, "home-zone--")
+	c.Check(spec.SizeHintGB, gc.Equals, uint64(2))
+	c.Check(spec.PersistentDiskType, gc.Equals, "pd-ssd")
+	c.Check(spec.Description, gc.Equals, "cost-centre=1234 juju-env-uuid=deadbeef")
+	c.Check(s.FakeConn.Calls[1].FuncName, gc.Equals, "AttachDisk")
+	c.Check(s.FakeConn.Calls[1].ZoneName, gc.Equals, "home-zone")
+	c.Check(s.FakeConn.Calls[1].DiskName, gc.Equals, spec.Name)
+	c.Check(s.FakeConn.Calls[1].ID, gc.Equals, "spam")
+}
+
+func (s *storageSuite) TestCreateVolumesInstanceNotRunning(c *gc.C) {
+	params := s.volumeParams(nil)
+	params.Attachment.InstanceId = "eggs"
+	_, _, err := s.source.CreateVolumes([]storage.VolumeParams{params})
+
+	c.Check(err, gc.ErrorMatches, "querying instance details: volumes can only be attached to running instances, these instances are not running: eggs")
+}
+
+func (s *storageSuite) TestCreateVolumesNoAttachment(c *gc.C) {
+	params := s.volumeParams(nil)
+	params.Attachment = nil
+	_, _, err := s.source.CreateVolumes([]storage.VolumeParams{params})
+
+	c.Check(err, gc.ErrorMatches, "cannot create volume-0: GCE disks must be created in the zone of an instance")
+}
+
+func (s *storageSuite) TestCreateVolumesAttachFailed(c *gc.C) {
+	s.FakeConn.Err = errors.New("<unknown>")
+	s.FakeConn.FailOnCall = 1
+	params := s.volumeParams(nil)
+	_, _, err := s.source.CreateVolumes([]storage.VolumeParams{params})
+	c.Assert(err, gc.ErrorMatches, "attaching .* to spam: <unknown>")
+
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 3)
+	c.Check(s.FakeConn.Calls[2].FuncName, gc.Equals, "RemoveDisk")
+	c.Check(s.FakeConn.Calls[2].DiskName, gc.Equals, "home-zone--c930380d-8337-4bf5-b07a-9dbb5ae771e4")
+}
+
+func (s *storageSuite) TestValidateVolumeParamsTooLarge(c *gc.C) {
+	params := s.volumeParams(nil)
+	params.Size = 20000 * 1024
+	err := s.source.ValidateVolumeParams(params)
+
+	c.Check(err, gc.ErrorMatches, "20000 GB exceeds the maximum of 10240 GB")
+}
+
+func (s *storageSuite) TestDescribeVolumes(c *gc.C) {
+	volumes, err := s.source.DescribeVolumes([]string{"home-zone--c930380d-8337-4bf5-b07a-9dbb5ae771e4"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(volumes, jc.DeepEquals, []storage.VolumeInfo{{
+		VolumeId:   "home-zone--c930380d-8337-4bf5-b07a-9dbb5ae771e4",
+		HardwareId: "scsi-0Google_PersistentDisk_home-zone--c930380d-8337-4bf5-b07a-9dbb5ae771e4",
+		Size:       10240,
+		Persistent: true,
+		Zone:       "home-zone",
+	}})
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 1)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "Disk")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "home-zone")
+}
+
+func (s *storageSuite) TestDescribeVolumesInvalidId(c *gc.C) {
+	_, err := s.source.DescribeVolumes([]string{"spam"})
+
+	c.Check(err, gc.ErrorMatches, `volume ID "spam" not valid`)
+	s.CheckNoAPI(c)
+}
+
+func (s *storageSuite) TestDestroyVolumes(c *gc.C) {
+	errs := s.source.DestroyVolumes([]string{"home-zone--a", "spam"})
+
+	c.Assert(errs, gc.HasLen, 2)
+	c.Check(errs[0], jc.ErrorIsNil)
+	c.Check(errs[1], gc.ErrorMatches, `volume ID "spam" not valid`)
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 1)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "RemoveDisk")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "home-zone")
+	c.Check(s.FakeConn.Calls[0].DiskName, gc.Equals, "home-zone--a")
+}
+
+func (s *storageSuite) TestDestroyVolumesNotFound(c *gc.C) {
+	s.FakeConn.Err = errors.NotFoundf("disk")
+	errs := s.source.DestroyVolumes([]string{"home-zone--a"})
+
+	c.Check(errs, jc.DeepEquals, []error{nil})
+}
+
+func (s *storageSuite) TestAttachVolumes(c *gc.C) {
+	attachments, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{{
+		AttachmentParams: storage.AttachmentParams{
+			Machine:    names.NewMachineTag("1"),
+			InstanceId: "spam",
+			ReadOnly:   true,
+		},
+		Volume:   names.NewVolumeTag("0"),
+		VolumeId: "home-zone--a",
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(attachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:  names.NewVolumeTag("0"),
+		Machine: names.NewMachineTag("1"),
+		VolumeAttachmentInfo: storage.VolumeAttachmentInfo{
+			ReadOnly: true,
+		},
+	}})
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 1)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "AttachDisk")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "home-zone")
+	c.Check(s.FakeConn.Calls[0].DiskName, gc.Equals, "home-zone--a")
+	c.Check(s.FakeConn.Calls[0].ID, gc.Equals, "spam")
+	c.Check(s.FakeConn.Calls[0].ReadOnly, jc.IsTrue)
+}
+
+func (s *storageSuite) TestDetachVolumes(c *gc.C) {
+	err := s.source.DetachVolumes([]storage.VolumeAttachmentParams{{
+		AttachmentParams: storage.AttachmentParams{
+			Machine:    names.NewMachineTag("1"),
+			InstanceId: "spam",
+		},
+		Volume:   names.NewVolumeTag("0"),
+		VolumeId: "home-zone--a",
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.FakeConn.Calls, gc.HasLen, 1)
+	c.Check(s.FakeConn.Calls[0].FuncName, gc.Equals, "DetachDisk")
+	c.Check(s.FakeConn.Calls[0].ZoneName, gc.Equals, "home-zone")
+	c.Check(s.FakeConn.Calls[0].DiskName, gc.Equals, "home-zone--a")
+	c.Check(s.FakeConn.Calls[0].ID, gc.Equals, "spam")
+}
@@ -422,6 +422,10 @@ type fakeConnCall struct {
 	FirewallName string
 	PortRanges   []network.PortRange
 	Region       string
+	DiskSpec     google.DiskSpec
+	DiskName     string
+	ReadOnly     bool
+	Metadata     map[string]string
 }
 
 type fakeConn struct {
@@ -431,6 +435,7 @@ type fakeConn struct {
 	Insts      []google.Instance
 	PortRanges []network.PortRange
 	Zones      []google.AvailabilityZone
+	Disk       *google.Disk
 	Err        error
 	FailOnCall int
 }
@@ -485,6 +490,16 @@ func (fc *fakeConn) RemoveInstances(prefix string, ids ...string) error {
 	return fc.err()
 }
 
+func (fc *fakeConn) UpdateMetadata(id, zone string, metadata map[string]string) error {
+	fc.Calls = append(fc.Calls, fakeConnCall{
+		FuncName: "UpdateMetadata",
+		ID:       id,
+		ZoneName: zone,
+		Metadata: metadata,
+	})
+	return fc.err()
+}
+
 func (fc *fakeConn) Ports(fwname string) ([]network.PortRange, error) {
 	fc.Calls = append(fc.Calls, fakeConnCall{
 		FuncName:     "Ports",
@@ -519,6 +534,54 @@ func (fc *fakeConn) AvailabilityZones(region string) ([]google.AvailabilityZone,
 	return fc.Zones, fc.err()
 }
 
+func (fc *fakeConn) CreateDisk(zone string, spec google.DiskSpec) (*google.Disk, error) {
+	fc.Calls = append(fc.Calls, fakeConnCall{
+		FuncName: "CreateDisk",
+		ZoneName: zone,
+		DiskSpec: spec,
+	})
+	return fc.Disk, fc.err()
+}
+
+func (fc *fakeConn) Disk(zone, name string) (*google.Disk, error) {
+	fc.Calls = append(fc.Calls, fakeConnCall{
+		FuncName: "Disk",
+		ZoneName: zone,
+		DiskName: name,
+	})
+	return fc.Disk, fc.err()
+}
+
+func (fc *fakeConn) RemoveDisk(zone, name string) error {
+	fc.Calls = append(fc.Calls, fakeConnCall{
+		FuncName: "RemoveDisk",
+		ZoneName: zone,
+		DiskName: name,
+	})
+	return fc.err()
+}
+
+func (fc *fakeConn) AttachDisk(zone, name, instanceID string, readOnly bool) error {
+	fc.Calls = append(fc.Calls, fakeConnCall{
+		FuncName: "AttachDisk",
+		ZoneName: zone,
+		DiskName: name,
+		ID:       instanceID,
+		ReadOnly: readOnly,
+	})
+	return fc.err()
+}
+
+func (fc *fakeConn) DetachDisk(zone, name, instanceID string) error {
+	fc.Calls = append(fc.Calls, fakeConnCall{
+		FuncName: "DetachDisk",
+		ZoneName: zone,
+		DiskName: name,
+		ID:       instanceID,
+	})
+	return fc.err()
+}
+
 func (fc *fakeConn) WasCalled(funcName string) (bool, []fakeConnCall) {
 	var calls []fakeConnCall
 	called := false
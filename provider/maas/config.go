@@ -74,6 +74,13 @@ func (prov maasEnvironProvider) Validate(cfg, oldCfg *config.Config) (*config.Co
 		return nil, err
 	}
 
+	// MAAS nodes have no key/value metadata in which to record
+	// resource tags (MAAS tags select nodes, they do not annotate
+	// them), so refuse them rather than silently ignoring them.
+	if tags, ok := cfg.ResourceTags(); ok && len(tags) > 0 {
+		return nil, fmt.Errorf("%s not supported by the MAAS provider", config.ResourceTagsKey)
+	}
+
 	// Add MAAS specific defaults.
 	providerDefaults := make(map[string]interface{})
 
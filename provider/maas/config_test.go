@@ -87,6 +87,15 @@ func (*configSuite) TestChecksWellFormedMaasOAuth(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, ".*malformed maas-oauth.*")
 }
 
+func (*configSuite) TestRejectsResourceTags(c *gc.C) {
+	_, err := newConfig(map[string]interface{}{
+		"maas-server":   "http://maas.testing.invalid/maas/",
+		"maas-oauth":    "consumer-key:resource-token:resource-secret",
+		"resource-tags": "cost-centre=1234",
+	})
+	c.Check(err, gc.ErrorMatches, "resource-tags not supported by the MAAS provider")
+}
+
 func (*configSuite) TestBlockStorageProviderDefault(c *gc.C) {
 	ecfg, err := newConfig(map[string]interface{}{
 		"maas-server": "http://maas.testing.invalid/maas/",
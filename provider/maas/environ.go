@@ -853,6 +853,9 @@ func (*maasEnviron) MaintainInstance(args environs.StartInstanceParams) error {
 }
 
 // StartInstance is specified in the InstanceBroker interface.
+//
+// Resource tags are not applied to MAAS nodes or volumes; the
+// resource-tags setting is refused by the provider's Validate.
 func (environ *maasEnviron) StartInstance(args environs.StartInstanceParams) (
 	*environs.StartInstanceResult, error,
 ) {
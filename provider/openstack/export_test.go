@@ -358,4 +358,5 @@ var PortsToRuleInfo = portsToRuleInfo
 var RuleMatchesPortRange = ruleMatchesPortRange
 
 var MakeServiceURL = &makeServiceURL
+var SendRequest = &sendRequest
 var ProviderInstance = providerInstance
@@ -5,6 +5,7 @@ package openstack_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -19,6 +20,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/goose.v1/client"
+	goosehttp "gopkg.in/goose.v1/http"
 	"gopkg.in/goose.v1/identity"
 	"gopkg.in/goose.v1/nova"
 	"gopkg.in/goose.v1/testservices/hook"
@@ -811,6 +813,59 @@ func (s *localServerSuite) assertGetImageMetadataSources(c *gc.C, stream, offici
 	c.Assert(urls[2], gc.Equals, fmt.Sprintf("http://cloud-images.ubuntu.com/%s/", officialSourcePath))
 }
 
+// patchLimits arranges for the compute and volume services to report
+// the given limits; a service without limits reports an error.
+func (s *localServerSuite) patchLimits(c *gc.C, compute, volume string) {
+	s.PatchValue(openstack.SendRequest, func(_ client.AuthenticatingClient, method, svcType, apiCall string, requestData *goosehttp.RequestData) error {
+		c.Check(method, gc.Equals, "GET")
+		c.Check(apiCall, gc.Equals, "limits")
+		body := map[string]string{"compute": compute, "volume": volume}[svcType]
+		if body == "" {
+			return fmt.Errorf("no %s endpoint", svcType)
+		}
+		return json.Unmarshal([]byte(body), requestData.RespValue)
+	})
+}
+
+const computeLimits = `{"limits": {"rate": [], "absolute": {
+	"maxTotalInstances": 10, "totalInstancesUsed": 7,
+	"maxTotalCores": -1, "totalCoresUsed": 14,
+	"maxTotalFloatingIps": 5, "totalFloatingIpsUsed": 5}}}`
+
+func (s *localServerSuite) TestQuotas(c *gc.C) {
+	s.patchLimits(c, computeLimits, `{"limits": {"absolute": {"maxTotalVolumes": 10, "totalVolumesUsed": 4}}}`)
+	quotas, err := s.env.(environs.QuotaReporter).Quotas()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(quotas.Instances, gc.NotNil)
+	c.Check(*quotas.Instances, gc.Equals, 3)
+	c.Check(quotas.Cores, gc.IsNil)
+	c.Check(quotas.IPAddresses, gc.IsNil)
+	c.Assert(quotas.Volumes, gc.NotNil)
+	c.Check(*quotas.Volumes, gc.Equals, 6)
+}
+
+func (s *localServerSuite) TestQuotasFloatingIPs(c *gc.C) {
+	s.patchLimits(c, computeLimits, "")
+	cfg, err := config.New(config.NoDefaults, s.TestConfig.Merge(coretesting.Attrs{
+		"use-floating-ip": true,
+	}))
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := environs.New(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	quotas, err := env.(environs.QuotaReporter).Quotas()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(quotas.IPAddresses, gc.NotNil)
+	c.Check(*quotas.IPAddresses, gc.Equals, 0)
+	// The volume service is optional.
+	c.Check(quotas.Volumes, gc.IsNil)
+}
+
+func (s *localServerSuite) TestQuotasComputeError(c *gc.C) {
+	s.patchLimits(c, "", "")
+	_, err := s.env.(environs.QuotaReporter).Quotas()
+	c.Assert(err, gc.ErrorMatches, "getting compute limits: no compute endpoint")
+}
+
 func (s *localServerSuite) TestGetImageMetadataSources(c *gc.C) {
 	s.assertGetImageMetadataSources(c, "", "releases")
 	s.assertGetImageMetadataSources(c, "released", "releases")
@@ -860,7 +860,7 @@ func (e *environ) getKeystoneDataSource(mu *sync.Mutex, datasource *simplestream
 	if !e.Config().SSLHostnameVerification() {
 		verify = utils.NoVerifySSLHostnames
 	}
-	*datasource = simplestreams.NewURLDataSource("keystone catalog", url, verify)
+	*datasource = simplestreams.NewCachingURLDataSource("keystone catalog", url, verify)
 	return *datasource, nil
 }
 
@@ -1669,7 +1669,9 @@ func (e *environ) cloudSpec(region string) (simplestreams.CloudSpec, error) {
 	}, nil
 }
 
-// TagInstance implements environs.InstanceTagger.
+// TagInstance implements environs.InstanceTagger. The metadata of
+// attached Cinder volumes is not updated, as the Cinder client has
+// no way of changing it once a volume is created.
 func (e *environ) TagInstance(id instance.Id, tags map[string]string) error {
 	if err := e.nova().SetServerMetadata(string(id), tags); err != nil {
 		return errors.Annotate(err, "setting server metadata")
@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/goose.v1/client"
+	goosehttp "gopkg.in/goose.v1/http"
+
+	"github.com/juju/juju/environs"
+)
+
+var _ environs.QuotaReporter = (*environ)(nil)
+
+var sendRequest = client.AuthenticatingClient.SendRequest
+
+// absoluteLimits holds the absolute limits reported by the compute
+// and volume services, in response to a GET of their limits resource.
+// A maximum of -1 means that the resource is unlimited.
+type absoluteLimits struct {
+	Limits struct {
+		Absolute struct {
+			MaxTotalInstances    *int `json:"maxTotalInstances"`
+			TotalInstancesUsed   int  `json:"totalInstancesUsed"`
+			MaxTotalCores        *int `json:"maxTotalCores"`
+			TotalCoresUsed       int  `json:"totalCoresUsed"`
+			MaxTotalFloatingIps  *int `json:"maxTotalFloatingIps"`
+			TotalFloatingIpsUsed int  `json:"totalFloatingIpsUsed"`
+			MaxTotalVolumes      *int `json:"maxTotalVolumes"`
+			TotalVolumesUsed     int  `json:"totalVolumesUsed"`
+		} `json:"absolute"`
+	} `json:"limits"`
+}
+
+// Quotas implements environs.QuotaReporter, using the limits of the
+// tenant reported by the compute and volume services. Floating IP
+// addresses are only reported if use-floating-ip is set, as they are
+// not otherwise allocated to new instances. Volumes are not reported
+// if the cloud has no volume service.
+func (e *environ) Quotas() (environs.Quotas, error) {
+	var quotas environs.Quotas
+	if !e.client.IsAuthenticated() {
+		if err := authenticateClient(e); err != nil {
+			return quotas, err
+		}
+	}
+
+	var compute absoluteLimits
+	if err := e.getLimits("compute", &compute); err != nil {
+		return quotas, errors.Annotate(err, "getting compute limits")
+	}
+	limits := compute.Limits.Absolute
+	quotas.Instances = remaining(limits.MaxTotalInstances, limits.TotalInstancesUsed)
+	quotas.Cores = remaining(limits.MaxTotalCores, limits.TotalCoresUsed)
+	if e.ecfg().useFloatingIP() {
+		quotas.IPAddresses = remaining(limits.MaxTotalFloatingIps, limits.TotalFloatingIpsUsed)
+	}
+
+	var volume absoluteLimits
+	if err := e.getLimits("volume", &volume); err != nil {
+		logger.Debugf("cannot get volume limits: %v", err)
+	} else {
+		limits := volume.Limits.Absolute
+		quotas.Volumes = remaining(limits.MaxTotalVolumes, limits.TotalVolumesUsed)
+	}
+	return quotas, nil
+}
+
+// getLimits gets the limits reported by the given service.
+func (e *environ) getLimits(serviceType string, limits *absoluteLimits) error {
+	requestData := goosehttp.RequestData{RespValue: limits}
+	return sendRequest(e.client, client.GET, serviceType, "limits", &requestData)
+}
+
+// remaining returns the number of resources that may still be created,
+// given the maximum and current usage reported by a service. It returns
+// nil if the resource is unlimited, or its maximum was not reported.
+func remaining(max *int, used int) *int {
+	if max == nil || *max < 0 {
+		return nil
+	}
+	n := *max - used
+	return &n
+}
@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package resource defines the named binary payloads that a charm may
+// declare in its metadata, and that are attached to the services
+// deployed from that charm with "juju attach".
+package resource
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+
+	"github.com/juju/errors"
+	goyaml "gopkg.in/yaml.v1"
+)
+
+// TypeFile is the only supported resource type: a single file that is
+// delivered to the unit as-is.
+const TypeFile = "file"
+
+// Meta describes a resource declared by a charm.
+type Meta struct {
+	// Name identifies the resource within the charm.
+	Name string
+
+	// Type is the kind of resource. Only TypeFile is supported.
+	Type string
+
+	// Filename is the name of the file to which the resource is
+	// written when a unit fetches it.
+	Filename string
+
+	// Description is a human-readable description of the resource.
+	Description string
+}
+
+var validName = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// IsValidName reports whether name is a valid resource name.
+func IsValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// metadata holds the part of a charm's metadata.yaml that declares
+// its resources.
+type metadata struct {
+	Resources map[string]struct {
+		Type        string `yaml:"type"`
+		Filename    string `yaml:"filename"`
+		Description string `yaml:"description"`
+	} `yaml:"resources"`
+}
+
+// ParseMeta returns the resources declared in the given charm
+// metadata.yaml content, keyed by name.
+func ParseMeta(data []byte) (map[string]Meta, error) {
+	var meta metadata
+	if err := goyaml.Unmarshal(data, &meta); err != nil {
+		return nil, errors.Annotate(err, "cannot parse charm metadata")
+	}
+	result := make(map[string]Meta)
+	for name, res := range meta.Resources {
+		if !IsValidName(name) {
+			return nil, errors.NotValidf("resource name %q", name)
+		}
+		if res.Type == "" {
+			res.Type = TypeFile
+		}
+		if res.Type != TypeFile {
+			return nil, errors.NotSupportedf("resource %q of type %q", name, res.Type)
+		}
+		filename := res.Filename
+		if filename == "" {
+			filename = name
+		}
+		if path.Base(filename) != filename || filename == "." || filename == ".." {
+			return nil, errors.NotValidf("resource %q filename %q", name, res.Filename)
+		}
+		result[name] = Meta{
+			Name:        name,
+			Type:        res.Type,
+			Filename:    filename,
+			Description: res.Description,
+		}
+	}
+	return result, nil
+}
+
+// ReadArchiveMeta returns the resources declared by the charm archive
+// with the given content and size, keyed by name.
+func ReadArchiveMeta(r io.ReaderAt, size int64) (map[string]Meta, error) {
+	zipr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot open charm archive")
+	}
+	for _, file := range zipr.File {
+		if path.Clean(file.Name) != "metadata.yaml" {
+			continue
+		}
+		f, err := file.Open()
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot open charm metadata")
+		}
+		defer f.Close()
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read charm metadata")
+		}
+		return ParseMeta(data)
+	}
+	return nil, errors.NotFoundf("charm metadata")
+}
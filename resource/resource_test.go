@@ -0,0 +1,101 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resource_test
+
+import (
+	"archive/zip"
+	"bytes"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/testing"
+)
+
+type resourceSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&resourceSuite{})
+
+const metadataYAML = `
+name: website
+summary: a website
+description: a website served from an attached tarball
+resources:
+  site:
+    type: file
+    filename: site.tgz
+    description: the site content
+  tools:
+    description: some tools
+`
+
+func (s *resourceSuite) TestParseMeta(c *gc.C) {
+	meta, err := resource.ParseMeta([]byte(metadataYAML))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta, jc.DeepEquals, map[string]resource.Meta{
+		"site": {
+			Name:        "site",
+			Type:        resource.TypeFile,
+			Filename:    "site.tgz",
+			Description: "the site content",
+		},
+		"tools": {
+			Name:        "tools",
+			Type:        resource.TypeFile,
+			Filename:    "tools",
+			Description: "some tools",
+		},
+	})
+}
+
+func (s *resourceSuite) TestParseMetaNoResources(c *gc.C) {
+	meta, err := resource.ParseMeta([]byte("name: website\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta, gc.HasLen, 0)
+}
+
+func (s *resourceSuite) TestParseMetaInvalid(c *gc.C) {
+	for i, test := range []struct {
+		yaml string
+		err  string
+	}{{
+		yaml: "resources:\n  Site: {}\n",
+		err:  `resource name "Site" not valid`,
+	}, {
+		yaml: "resources:\n  site: {type: docker}\n",
+		err:  `resource "site" of type "docker" not supported`,
+	}, {
+		yaml: "resources:\n  site: {filename: ../site.tgz}\n",
+		err:  `resource "site" filename "../site.tgz" not valid`,
+	}} {
+		c.Logf("test %d: %s", i, test.yaml)
+		_, err := resource.ParseMeta([]byte(test.yaml))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *resourceSuite) TestReadArchiveMeta(c *gc.C) {
+	var buf bytes.Buffer
+	zipw := zip.NewWriter(&buf)
+	w, err := zipw.Create("metadata.yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write([]byte(metadataYAML))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(zipw.Close(), jc.ErrorIsNil)
+
+	meta, err := resource.ReadArchiveMeta(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta, gc.HasLen, 2)
+	c.Assert(meta["site"].Filename, gc.Equals, "site.tgz")
+}
+
+func (s *resourceSuite) TestReadArchiveMetaMissing(c *gc.C) {
+	var buf bytes.Buffer
+	c.Assert(zip.NewWriter(&buf).Close(), jc.ErrorIsNil)
+	_, err := resource.ReadArchiveMeta(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	c.Assert(err, gc.ErrorMatches, "charm metadata not found")
+}
@@ -13,6 +13,8 @@ import (
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/jujurun"
 )
 
 var actionLogger = loggo.GetLogger("juju.state.action")
@@ -377,6 +379,15 @@ func (st *State) EnqueueAction(receiver names.Tag, actionName string, payload ma
 	return nil, err
 }
 
+// addJujuRunAction queues the predefined juju-run action, which is
+// valid for every kind of action receiver, for the given receiver.
+func addJujuRunAction(st *State, receiver names.Tag, payload map[string]interface{}) (*Action, error) {
+	if _, _, err := jujurun.ParseParams(payload); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return st.EnqueueAction(receiver, jujurun.ActionName, payload)
+}
+
 // matchingActions finds actions that match ActionReceiver.
 func (st *State) matchingActions(ar ActionReceiver) ([]*Action, error) {
 	return st.matchingActionsByReceiverId(ar.Tag().Id())
@@ -7,14 +7,17 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/txn"
 	"github.com/juju/utils"
+	"github.com/juju/utils/exec"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
 	"github.com/juju/juju/testing"
@@ -587,6 +590,64 @@ func (s *ActionSuite) TestUnitWatchActionNotifications(c *gc.C) {
 	wc.AssertNoChange()
 }
 
+func (s *ActionSuite) TestAddJujuRunActionOnActionlessUnit(c *gc.C) {
+	params := jujurun.ActionParams("hostname", time.Minute)
+	action, err := s.actionlessUnit.AddAction(jujurun.ActionName, params)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(action.Name(), gc.Equals, jujurun.ActionName)
+	c.Assert(action.Receiver(), gc.Equals, s.actionlessUnit.Name())
+
+	_, err = s.actionlessUnit.AddAction(jujurun.ActionName, nil)
+	c.Assert(err, gc.ErrorMatches, "juju-run action without commands not valid")
+}
+
+func (s *ActionSuite) TestMachineActions(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = machine.AddAction("snapshot", nil)
+	c.Assert(err, gc.ErrorMatches, `action "snapshot" not defined on machine "0"`)
+
+	w := machine.WatchActionNotifications()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	action, err := machine.AddAction(jujurun.ActionName, jujurun.ActionParams("hostname", time.Minute))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(action.Receiver(), gc.Equals, machine.Id())
+	wc.AssertChange(expectActionIds(action)...)
+	wc.AssertNoChange()
+
+	pending, err := machine.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 1)
+
+	_, err = action.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	running, err := machine.RunningActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(running, gc.HasLen, 1)
+
+	_, err = action.Finish(state.ActionResults{
+		Status:  state.ActionCompleted,
+		Results: jujurun.ActionResults(&exec.ExecResponse{Stdout: []byte("host")}),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	completed, err := machine.CompletedActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(completed, gc.HasLen, 1)
+	results, _ := completed[0].Results()
+	response, err := jujurun.ParseResults(results)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(response.Stdout), gc.Equals, "host")
+
+	all, err := machine.Actions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 1)
+}
+
 func (s *ActionSuite) TestMergeIds(c *gc.C) {
 	var tests = []struct {
 		changes  string
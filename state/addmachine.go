@@ -11,6 +11,7 @@ import (
 	"github.com/juju/names"
 	"github.com/juju/replicaset"
 	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils/set"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 
@@ -720,40 +721,137 @@ func (st *State) EnsureAvailability(
 			return nil, errors.New("cannot reduce state server count")
 		}
 
-		intent, err := st.ensureAvailabilityIntentions(currentInfo, placement)
+		var ops []txn.Op
+		ops, change, err = st.ensureAvailabilityOps(currentInfo, desiredStateServerCount, cons, series, placement)
+		return ops, err
+	}
+	if err := st.run(buildTxn); err != nil {
+		err = errors.Annotate(err, "failed to create new state server machines")
+		return StateServersChanges{}, err
+	}
+	return change, nil
+}
+
+// ensureAvailabilityOps returns the operations required to make the
+// number of voting state servers equal to desiredStateServerCount,
+// given the current state server information.
+func (st *State) ensureAvailabilityOps(
+	currentInfo *StateServerInfo,
+	desiredStateServerCount int,
+	cons constraints.Value,
+	series string,
+	placement []string,
+) ([]txn.Op, StateServersChanges, error) {
+	intent, err := st.ensureAvailabilityIntentions(currentInfo, placement)
+	if err != nil {
+		return nil, StateServersChanges{}, err
+	}
+	voteCount := 0
+	for _, m := range intent.maintain {
+		if m.WantsVote() {
+			voteCount++
+		}
+	}
+	if voteCount == desiredStateServerCount && len(intent.remove) == 0 && len(intent.demote) == 0 {
+		return nil, StateServersChanges{}, jujutxn.ErrNoOperations
+	}
+	// Promote as many machines as we can to fulfil the shortfall.
+	if n := desiredStateServerCount - voteCount; n < len(intent.promote) {
+		intent.promote = intent.promote[:n]
+	}
+	voteCount += len(intent.promote)
+
+	if n := desiredStateServerCount - voteCount; n < len(intent.convert) {
+		intent.convert = intent.convert[:n]
+	}
+	voteCount += len(intent.convert)
+
+	intent.newCount = desiredStateServerCount - voteCount
+
+	logger.Infof("%d new machines; promoting %v; converting %v", intent.newCount, intent.promote, intent.convert)
+
+	return st.ensureAvailabilityIntentionOps(intent, currentInfo, cons, series)
+}
+
+// RemoveStateServer starts the removal of the state server running on
+// the machine with the given id. The machine's vote is removed, and it
+// is recorded as being removed so that it will not be promoted again;
+// once worker/peergrouper has removed it from the replica set, calling
+// RemoveStateServer (or EnsureAvailability) again removes its
+// JobManageEnviron job.
+//
+// Calling RemoveStateServer for a machine that is already being
+// removed does nothing else: the state servers are not reconsidered,
+// so a replacement whose agent has not started yet is neither demoted
+// nor replaced in turn.
+//
+// If replace is true, a replacement is provided in the same
+// transaction, by promoting an available non-voting state server or
+// by adding a new machine with the given constraints, series and
+// placement, so that the number of voting state servers is
+// maintained. Otherwise the removal must not leave an even number of
+// voting state servers.
+func (st *State) RemoveStateServer(
+	machineId string, replace bool, cons constraints.Value, series string, placement []string,
+) (StateServersChanges, error) {
+	var change StateServersChanges
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		currentInfo, err := st.StateServerInfo()
 		if err != nil {
 			return nil, err
 		}
-		voteCount := 0
-		for _, m := range intent.maintain {
-			if m.WantsVote() {
-				voteCount++
-			}
+		if !set.NewStrings(currentInfo.MachineIds...).Contains(machineId) {
+			return nil, errors.NotFoundf("state server machine %q", machineId)
 		}
-		if voteCount == desiredStateServerCount && len(intent.remove) == 0 {
-			return nil, jujutxn.ErrNoOperations
+		m, err := st.Machine(machineId)
+		if err != nil {
+			return nil, err
 		}
-		// Promote as many machines as we can to fulfil the shortfall.
-		if n := desiredStateServerCount - voteCount; n < len(intent.promote) {
-			intent.promote = intent.promote[:n]
+		if set.NewStrings(currentInfo.RemovingMachineIds...).Contains(machineId) {
+			if m.WantsVote() || m.HasVote() {
+				// Still waiting for the peergrouper.
+				change = StateServersChanges{Demoted: []string{machineId}}
+				return nil, jujutxn.ErrNoOperations
+			}
+			change = StateServersChanges{Removed: []string{machineId}}
+			return removeStateServerOps(m), nil
 		}
-		voteCount += len(intent.promote)
 
-		if n := desiredStateServerCount - voteCount; n < len(intent.convert) {
-			intent.convert = intent.convert[:n]
+		desiredStateServerCount := len(currentInfo.VotingMachineIds)
+		if m.WantsVote() && !replace {
+			desiredStateServerCount--
+			if desiredStateServerCount%2 != 1 {
+				return nil, errors.Errorf(
+					"removing machine %s would leave an even number of voting state servers; use replace instead",
+					machineId,
+				)
+			}
 		}
-		voteCount += len(intent.convert)
-
-		intent.newCount = desiredStateServerCount - voteCount
-
-		logger.Infof("%d new machines; promoting %v; converting %v", intent.newCount, intent.promote, intent.convert)
-
-		var ops []txn.Op
-		ops, change, err = st.ensureAvailabilityIntentionOps(intent, currentInfo, cons, series)
-		return ops, err
+		if !replace {
+			// Do not add or promote anything else.
+			placement = nil
+		}
+		info := *currentInfo
+		info.RemovingMachineIds = append(append([]string(nil), currentInfo.RemovingMachineIds...), machineId)
+		ops, changes, err := st.ensureAvailabilityOps(&info, desiredStateServerCount, cons, series, placement)
+		if err != nil {
+			return nil, err
+		}
+		// A machine that neither wants nor has a vote is removed
+		// outright by the ops above, so it must not be left behind
+		// in the removing list.
+		if !set.NewStrings(changes.Removed...).Contains(machineId) {
+			ops = append(ops, txn.Op{
+				C:      stateServersC,
+				Id:     environGlobalKey,
+				Update: bson.D{{"$addToSet", bson.D{{"removingmachineids", machineId}}}},
+			})
+		}
+		change = changes
+		return ops, nil
 	}
 	if err := st.run(buildTxn); err != nil {
-		err = errors.Annotate(err, "failed to create new state server machines")
+		err = errors.Annotatef(err, "failed to remove state server machine %s", machineId)
 		return StateServersChanges{}, err
 	}
 	return change, nil
@@ -869,6 +967,7 @@ type ensureAvailabilityIntent struct {
 //   demoting unavailable, voting machines;
 //   removing unavailable, non-voting, non-vote-holding machines;
 //   gathering available, non-voting machines that may be promoted;
+//   demoting or removing machines that have been requested to be removed;
 func (st *State) ensureAvailabilityIntentions(info *StateServerInfo, placement []string) (*ensureAvailabilityIntent, error) {
 	var intent ensureAvailabilityIntent
 	for _, s := range placement {
@@ -903,11 +1002,24 @@ func (st *State) ensureAvailabilityIntentions(info *StateServerInfo, placement [
 		return nil, errors.Errorf("unsupported placement directive %q", s)
 	}
 
+	removing := set.NewStrings(info.RemovingMachineIds...)
 	for _, mid := range info.MachineIds {
 		m, err := st.Machine(mid)
 		if err != nil {
 			return nil, err
 		}
+		if removing.Contains(mid) {
+			// The machine has been explicitly requested to be
+			// removed, so it must not be promoted again.
+			if m.WantsVote() {
+				intent.demote = append(intent.demote, m)
+			} else if m.HasVote() {
+				intent.maintain = append(intent.maintain, m)
+			} else {
+				intent.remove = append(intent.remove, m)
+			}
+			continue
+		}
 		available, err := stateServerAvailable(m)
 		if err != nil {
 			return nil, err
@@ -997,8 +1109,11 @@ func removeStateServerOps(m *Machine) []txn.Op {
 			{"$set", bson.D{{"novote", false}}},
 		},
 	}, {
-		C:      stateServersC,
-		Id:     environGlobalKey,
-		Update: bson.D{{"$pull", bson.D{{"machineids", m.doc.Id}}}},
+		C:  stateServersC,
+		Id: environGlobalKey,
+		Update: bson.D{{"$pull", bson.D{
+			{"machineids", m.doc.Id},
+			{"removingmachineids", m.doc.Id},
+		}}},
 	}}
 }
@@ -91,6 +91,10 @@ func allCollections() collectionSchema {
 		// Life and its UUID.
 		environmentsC: {global: true},
 
+		// This collection holds the details of environment migrations,
+		// keyed by the UUID of the environment being migrated.
+		envMigrationsC: {global: true},
+
 		// This collection holds user information that's not specific to any
 		// one environment.
 		usersC: {
@@ -101,6 +105,24 @@ func allCollections() collectionSchema {
 			}},
 		},
 
+		// This collection holds the named groups of users that
+		// environments can be shared with.
+		userGroupsC: {
+			global: true,
+			indexes: []mgo.Index{{
+				Key: []string{"members"},
+			}},
+		},
+
+		// This collection holds the time-limited API tokens that users
+		// can log in with instead of their passwords.
+		apiTokensC: {
+			global: true,
+			indexes: []mgo.Index{{
+				Key: []string{"owner"},
+			}},
+		},
+
 		// This collection is used as a unique key restraint. The _id field is
 		// a concatenation of multiple fields that form a compound index,
 		// allowing us to ensure users cannot have the same name for two
@@ -134,6 +156,15 @@ func allCollections() collectionSchema {
 		// given collection.
 		envUsersC: {},
 
+		// This collection is the equivalent of envUsersC for groups of
+		// users; each member of a group recorded here may access the
+		// environment.
+		envGroupsC: {
+			indexes: []mgo.Index{{
+				Key: []string{"group"},
+			}},
+		},
+
 		// This collection contains governors that prevent certain kinds of
 		// changes from being accepted.
 		blocksC: {},
@@ -143,6 +174,11 @@ func allCollections() collectionSchema {
 		// for later handling.
 		cleanupsC: {},
 
+		// This collection records failed attempts to run cleanups. It is
+		// kept apart from cleanupsC so that recording a failure does not
+		// itself trigger the cleanup watcher.
+		cleanupFailuresC: {},
+
 		// This collection contains incrementing integers, subdivided by name,
 		// to ensure various IDs aren't reused.
 		sequenceC: {},
@@ -174,6 +210,14 @@ func allCollections() collectionSchema {
 		},
 		minUnitsC: {},
 
+		// This collection holds the charm resources attached to services;
+		// their content is held in environment blob storage.
+		resourcesC: {
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "service"},
+			}},
+		},
+
 		// meterStatusC is the collection used to store meter status information.
 		meterStatusC:  {},
 		settingsrefsC: {},
@@ -303,6 +347,14 @@ func allCollections() collectionSchema {
 			}},
 		},
 
+		// This collection holds a bounded record of the deliveries made
+		// by the webhook worker, for display to users.
+		webhookDeliveriesC: {
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "seq"},
+			}},
+		},
+
 		// ----------------------
 
 		// Raw-access collections
@@ -321,12 +373,16 @@ const (
 	actionresultsC         = "actionresults"
 	actionsC               = "actions"
 	annotationsC           = "annotations"
+	apiTokensC             = "apitokens"
 	blockDevicesC          = "blockdevices"
 	blocksC                = "blocks"
 	charmsC                = "charms"
 	cleanupsC              = "cleanups"
+	cleanupFailuresC       = "cleanupfailures"
 	constraintsC           = "constraints"
 	containerRefsC         = "containerRefs"
+	envGroupsC             = "envgroups"
+	envMigrationsC         = "envmigrations"
 	envUsersC              = "envusers"
 	environmentsC          = "environments"
 	filesystemAttachmentsC = "filesystemAttachments"
@@ -347,6 +403,7 @@ const (
 	relationScopesC        = "relationscopes"
 	relationsC             = "relations"
 	requestedNetworksC     = "requestednetworks"
+	resourcesC             = "resources"
 	restoreInfoC           = "restoreInfo"
 	sequenceC              = "sequence"
 	servicesC              = "services"
@@ -364,8 +421,10 @@ const (
 	txnsC                  = "txns"
 	unitsC                 = "units"
 	upgradeInfoC           = "upgradeInfo"
+	userGroupsC            = "usergroups"
 	userenvnameC           = "userenvname"
 	usersC                 = "users"
 	volumeAttachmentsC     = "volumeattachments"
 	volumesC               = "volumes"
+	webhookDeliveriesC     = "webhookdeliveries"
 )
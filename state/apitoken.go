@@ -0,0 +1,246 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// apiTokenPrefix starts every API token, so that tokens can be told
+// apart from passwords when they are presented as login credentials.
+const apiTokenPrefix = "apitoken:"
+
+// apiTokenNow returns the current time when checking token expiry; it
+// is a variable so that tests can patch it.
+var apiTokenNow = time.Now
+
+// APIToken is a time-limited credential that allows a user to log in
+// to a single environment without using their password.
+type APIToken struct {
+	st  *State
+	doc apiTokenDoc
+}
+
+type apiTokenDoc struct {
+	DocID       string     `bson:"_id"`
+	Owner       string     `bson:"owner"`
+	Environment string     `bson:"environment"`
+	SecretHash  string     `bson:"secrethash"`
+	SecretSalt  string     `bson:"secretsalt"`
+	DateCreated time.Time  `bson:"datecreated"`
+	Expires     time.Time  `bson:"expires"`
+	LastUsed    *time.Time `bson:"lastused,omitempty"`
+}
+
+// FormatAPIToken returns the credential that is presented at login for
+// the token with the given id and secret.
+func FormatAPIToken(id, secret string) string {
+	return apiTokenPrefix + id + ":" + secret
+}
+
+// ParseAPIToken splits a credential created by FormatAPIToken into the
+// id and secret of the token. It returns false if the credential is not
+// an API token.
+func ParseAPIToken(credential string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(credential, apiTokenPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(credential, apiTokenPrefix), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// Id returns the id of the token.
+func (t *APIToken) Id() string {
+	return t.doc.DocID
+}
+
+// Owner returns the user that the token authenticates.
+func (t *APIToken) Owner() names.UserTag {
+	return names.NewUserTag(t.doc.Owner)
+}
+
+// EnvironUUID returns the UUID of the only environment that the token
+// may be used to log in to.
+func (t *APIToken) EnvironUUID() string {
+	return t.doc.Environment
+}
+
+// DateCreated returns when the token was created in UTC.
+func (t *APIToken) DateCreated() time.Time {
+	return t.doc.DateCreated.UTC()
+}
+
+// Expires returns when the token stops being accepted in UTC.
+func (t *APIToken) Expires() time.Time {
+	return t.doc.Expires.UTC()
+}
+
+// IsExpired reports whether the token has expired.
+func (t *APIToken) IsExpired() bool {
+	return !apiTokenNow().Before(t.doc.Expires)
+}
+
+// LastUsed returns when the token was last used to log in, or nil if
+// it has never been used.
+func (t *APIToken) LastUsed() *time.Time {
+	if t.doc.LastUsed == nil {
+		return nil
+	}
+	lastUsed := t.doc.LastUsed.UTC()
+	return &lastUsed
+}
+
+// SecretValid reports whether the given secret is the token's secret.
+// Expired tokens never have a valid secret.
+func (t *APIToken) SecretValid(secret string) bool {
+	if t.IsExpired() {
+		return false
+	}
+	return utils.UserPasswordHash(secret, t.doc.SecretSalt) == t.doc.SecretHash
+}
+
+// UpdateLastUsed sets the last used time of the token to the current
+// time.
+func (t *APIToken) UpdateLastUsed() error {
+	tokens, closer := t.st.getCollection(apiTokensC)
+	defer closer()
+	// The last used time is informational, and is written on every
+	// login with the token, so it is set outside a transaction in the
+	// same way as a user's last login time. No transaction writes or
+	// asserts lastused after the token is created.
+	tokensW := tokens.Writeable()
+
+	// Update the safe mode of the underlying session to be not require
+	// write majority, nor sync to disk.
+	session := tokensW.Underlying().Database.Session
+	session.SetSafe(&mgo.Safe{})
+
+	timestamp := nowToTheSecond()
+	update := bson.D{{"$set", bson.D{{"lastused", timestamp}}}}
+	if err := tokensW.UpdateId(t.doc.DocID, update); err != nil {
+		return errors.Annotatef(err, "cannot update last used timestamp for API token %q", t.doc.DocID)
+	}
+	t.doc.LastUsed = &timestamp
+	return nil
+}
+
+// AddAPIToken creates a token that lets the given user log in to this
+// environment until the expiry time. The token's credential is returned
+// along with the token; only a hash of it is stored, so it cannot be
+// retrieved later.
+func (st *State) AddAPIToken(owner names.UserTag, expires time.Time) (*APIToken, string, error) {
+	if !expires.After(apiTokenNow()) {
+		return nil, "", errors.New("token expiry time must be in the future")
+	}
+	idBytes, err := utils.RandomBytes(8)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	secret, err := utils.RandomPassword()
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	salt, err := utils.RandomSalt()
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	token := &APIToken{
+		st: st,
+		doc: apiTokenDoc{
+			DocID:       hex.EncodeToString(idBytes),
+			Owner:       memberID(owner),
+			Environment: st.EnvironUUID(),
+			SecretHash:  utils.UserPasswordHash(secret, salt),
+			SecretSalt:  salt,
+			DateCreated: nowToTheSecond(),
+			Expires:     expires.UTC().Truncate(time.Second),
+		},
+	}
+	ops := []txn.Op{{
+		C:      apiTokensC,
+		Id:     token.doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &token.doc,
+	}}
+	if owner.IsLocal() {
+		ops = append(ops, txn.Op{
+			C:      usersC,
+			Id:     strings.ToLower(owner.Name()),
+			Assert: txn.DocExists,
+		})
+	}
+	err = st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if _, err := st.User(owner); err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		return nil, "", errors.AlreadyExistsf("API token %q", token.doc.DocID)
+	}
+	if err != nil {
+		return nil, "", errors.Annotatef(err, "cannot add API token for %q", owner.Username())
+	}
+	return token, FormatAPIToken(token.doc.DocID, secret), nil
+}
+
+// APIToken returns the token with the given id.
+func (st *State) APIToken(id string) (*APIToken, error) {
+	tokens, closer := st.getCollection(apiTokensC)
+	defer closer()
+
+	token := &APIToken{st: st}
+	err := tokens.FindId(id).One(&token.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("API token %q", id)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return token, nil
+}
+
+// APITokensForUser returns the tokens belonging to the given user,
+// across all environments, ordered by creation time.
+func (st *State) APITokensForUser(owner names.UserTag) ([]*APIToken, error) {
+	tokens, closer := st.getCollection(apiTokensC)
+	defer closer()
+
+	var docs []apiTokenDoc
+	query := tokens.Find(bson.D{{"owner", memberID(owner)}}).Sort("datecreated", "_id")
+	if err := query.All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]*APIToken, len(docs))
+	for i, doc := range docs {
+		result[i] = &APIToken{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// RemoveAPIToken revokes the token with the given id, so that it can
+// no longer be used to log in.
+func (st *State) RemoveAPIToken(id string) error {
+	ops := []txn.Op{{
+		C:      apiTokensC,
+		Id:     id,
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.NotFoundf("API token %q", id)
+	}
+	return errors.Trace(err)
+}
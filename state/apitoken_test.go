@@ -0,0 +1,143 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type APITokenSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&APITokenSuite{})
+
+func (s *APITokenSuite) TestAddAPIToken(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	now := state.NowToTheSecond()
+	expires := now.Add(24 * time.Hour)
+
+	token, credential, err := s.State.AddAPIToken(user.UserTag(), expires)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Owner(), gc.Equals, names.NewUserTag("bob@local"))
+	c.Assert(token.EnvironUUID(), gc.Equals, s.State.EnvironUUID())
+	c.Assert(token.Expires().Equal(expires), jc.IsTrue)
+	c.Assert(token.DateCreated().Equal(now) || token.DateCreated().After(now), jc.IsTrue)
+	c.Assert(token.LastUsed(), gc.IsNil)
+	c.Assert(token.IsExpired(), jc.IsFalse)
+
+	id, secret, ok := state.ParseAPIToken(credential)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(id, gc.Equals, token.Id())
+
+	token, err = s.State.APIToken(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.SecretValid(secret), jc.IsTrue)
+	c.Assert(token.SecretValid("wrong"), jc.IsFalse)
+}
+
+func (s *APITokenSuite) TestAddAPITokenUnknownUser(c *gc.C) {
+	_, _, err := s.State.AddAPIToken(names.NewUserTag("bob"), time.Now().Add(time.Hour))
+	c.Assert(err, gc.ErrorMatches, `user "bob" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *APITokenSuite) TestAddAPITokenExternalUser(c *gc.C) {
+	token, _, err := s.State.AddAPIToken(names.NewUserTag("bob@example"), time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Owner(), gc.Equals, names.NewUserTag("bob@example"))
+}
+
+func (s *APITokenSuite) TestAddAPITokenExpiryInPast(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	_, _, err := s.State.AddAPIToken(user.UserTag(), time.Now().Add(-time.Minute))
+	c.Assert(err, gc.ErrorMatches, "token expiry time must be in the future")
+}
+
+func (s *APITokenSuite) TestExpiredTokenSecretInvalid(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	token, credential, err := s.State.AddAPIToken(user.UserTag(), time.Now().Add(time.Second))
+	c.Assert(err, jc.ErrorIsNil)
+	_, secret, _ := state.ParseAPIToken(credential)
+
+	s.PatchValue(state.APITokenNow, func() time.Time {
+		return time.Now().Add(time.Minute)
+	})
+	c.Assert(token.IsExpired(), jc.IsTrue)
+	c.Assert(token.SecretValid(secret), jc.IsFalse)
+}
+
+func (s *APITokenSuite) TestUpdateLastUsed(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	token, _, err := s.State.AddAPIToken(user.UserTag(), time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	now := state.NowToTheSecond()
+
+	err = token.UpdateLastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+	token, err = s.State.APIToken(token.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.LastUsed(), gc.NotNil)
+	c.Assert(token.LastUsed().Equal(now) || token.LastUsed().After(now), jc.IsTrue)
+}
+
+func (s *APITokenSuite) TestAPITokensForUser(c *gc.C) {
+	bob := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	mary := s.Factory.MakeUser(c, &factory.UserParams{Name: "mary"})
+	expires := time.Now().Add(time.Hour)
+	first, _, err := s.State.AddAPIToken(bob.UserTag(), expires)
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.State.AddAPIToken(mary.UserTag(), expires)
+	c.Assert(err, jc.ErrorIsNil)
+
+	tokens, err := s.State.APITokensForUser(bob.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 1)
+	c.Assert(tokens[0].Id(), gc.Equals, first.Id())
+}
+
+func (s *APITokenSuite) TestRemoveAPIToken(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	token, _, err := s.State.AddAPIToken(user.UserTag(), time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RemoveAPIToken(token.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.APIToken(token.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.RemoveAPIToken(token.Id())
+	c.Assert(err, gc.ErrorMatches, `API token ".*" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *APITokenSuite) TestParseAPIToken(c *gc.C) {
+	for _, test := range []struct {
+		credential string
+		id         string
+		secret     string
+		ok         bool
+	}{
+		{credential: "apitoken:abc:s3cret", id: "abc", secret: "s3cret", ok: true},
+		{credential: "apitoken:abc:s3:cret", id: "abc", secret: "s3:cret", ok: true},
+		{credential: "apitoken:abc"},
+		{credential: "apitoken::s3cret"},
+		{credential: "apitoken:abc:"},
+		{credential: "password"},
+	} {
+		c.Logf("credential %q", test.credential)
+		id, secret, ok := state.ParseAPIToken(test.credential)
+		c.Check(ok, gc.Equals, test.ok)
+		c.Check(id, gc.Equals, test.id)
+		c.Check(secret, gc.Equals, test.secret)
+	}
+}
@@ -6,6 +6,7 @@ package state
 import (
 	"net/url"
 	"regexp"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -44,6 +45,42 @@ type charmDoc struct {
 	StoragePath   string `bson:"storagepath"`
 	PendingUpload bool   `bson:"pendingupload"`
 	Placeholder   bool   `bson:"placeholder"`
+
+	// SignedBy holds the fingerprint of the trusted key that made
+	// the verified signature of the charm archive, if any.
+	SignedBy string `bson:"signedby,omitempty"`
+
+	// RefCount holds the number of service settings documents for
+	// this charm, which exist for as long as the service or any of
+	// its units uses the charm. The charm cannot be removed while it
+	// is non-zero.
+	RefCount int `bson:"refcount,omitempty"`
+
+	// UploadedAt holds when the charm archive was stored. It is
+	// unset for placeholders, pending uploads, and charms stored
+	// before it was recorded.
+	UploadedAt *time.Time `bson:"uploadedat,omitempty"`
+}
+
+// charmIncRefOp returns an operation that increments the ref count of
+// the charm with the given URL, which must exist.
+func charmIncRefOp(st *State, curl *charm.URL) txn.Op {
+	return txn.Op{
+		C:      charmsC,
+		Id:     st.docID(curl.String()),
+		Assert: txn.DocExists,
+		Update: bson.D{{"$inc", bson.D{{"refcount", 1}}}},
+	}
+}
+
+// charmDecRefOp returns an operation that decrements the ref count of
+// the charm with the given URL.
+func charmDecRefOp(st *State, curl *charm.URL) txn.Op {
+	return txn.Op{
+		C:      charmsC,
+		Id:     st.docID(curl.String()),
+		Update: bson.D{{"$inc", bson.D{{"refcount", -1}}}},
+	}
 }
 
 // insertCharmOps returns the txn operations necessary to insert the supplied
@@ -51,6 +88,7 @@ type charmDoc struct {
 func insertCharmOps(
 	st *State, ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string,
 ) ([]txn.Op, error) {
+	uploadedAt := time.Now()
 	return insertAnyCharmOps(&charmDoc{
 		DocID:        curl.String(),
 		URL:          curl,
@@ -61,6 +99,7 @@ func insertCharmOps(
 		Actions:      ch.Actions(),
 		BundleSha256: bundleSha256,
 		StoragePath:  storagePath,
+		UploadedAt:   &uploadedAt,
 	})
 }
 
@@ -114,6 +153,7 @@ func updateCharmOps(
 		{"bundlesha256", bundleSha256},
 		{"pendingupload", false},
 		{"placeholder", false},
+		{"uploadedat", time.Now()},
 	}}}
 	return []txn.Op{{
 		C:      charmsC,
@@ -286,6 +326,29 @@ func (c *Charm) IsUploaded() bool {
 	return !c.doc.PendingUpload
 }
 
+// SignedBy returns the fingerprint of the trusted key that made the
+// verified signature of the charm archive, or "" if the charm has no
+// verified signature.
+func (c *Charm) SignedBy() string {
+	return c.doc.SignedBy
+}
+
+// SetSignedBy records that the signature of the uploaded charm archive
+// was verified to be made by the key with the given fingerprint.
+func (c *Charm) SetSignedBy(fingerprint string) error {
+	ops := []txn.Op{{
+		C:      charmsC,
+		Id:     c.doc.DocID,
+		Assert: bson.D{{"pendingupload", false}, {"placeholder", false}},
+		Update: bson.D{{"$set", bson.D{{"signedby", fingerprint}}}},
+	}}
+	if err := c.st.runTransaction(ops); err != nil {
+		return errors.Trace(onAbort(err, errors.NotFoundf("uploaded charm %q", c)))
+	}
+	c.doc.SignedBy = fingerprint
+	return nil
+}
+
 // IsPlaceholder returns whether the charm record is just a placeholder
 // rather than representing a deployed charm.
 func (c *Charm) IsPlaceholder() bool {
@@ -76,6 +76,29 @@ func (s *CharmSuite) TestCharmNotFound(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *CharmSuite) TestSetSignedBy(c *gc.C) {
+	dummy, err := s.State.Charm(s.curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dummy.SignedBy(), gc.Equals, "")
+
+	err = dummy.SetSignedBy("0123456789ABCDEF")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dummy.SignedBy(), gc.Equals, "0123456789ABCDEF")
+
+	dummy, err = s.State.Charm(s.curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dummy.SignedBy(), gc.Equals, "0123456789ABCDEF")
+}
+
+func (s *CharmSuite) TestSetSignedByPendingUpload(c *gc.C) {
+	curl := charm.MustParseURL("cs:quantal/wordpress-3")
+	pending, err := s.State.PrepareStoreCharmUpload(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	err = pending.SetSignedBy("0123456789ABCDEF")
+	c.Assert(err, gc.ErrorMatches, `uploaded charm "cs:quantal/wordpress-3" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 type CharmTestHelperSuite struct {
 	ConnSuite
 }
@@ -6,9 +6,11 @@ package state
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
@@ -28,6 +30,7 @@ const (
 	cleanupAttachmentsForDyingStorage    cleanupKind = "storageAttachments"
 	cleanupAttachmentsForDyingVolume     cleanupKind = "volumeAttachments"
 	cleanupAttachmentsForDyingFilesystem cleanupKind = "filesystemAttachments"
+	cleanupResourcesForRemovedService    cleanupKind = "resources"
 )
 
 // cleanupDoc represents a potentially large set of documents that should be
@@ -39,6 +42,57 @@ type cleanupDoc struct {
 	Prefix  string
 }
 
+// cleanupFailureDoc records failed attempts to run the cleanup with
+// the same id. It is stored apart from the cleanup document, so that
+// recording a failure does not wake the cleanup watcher and cause the
+// cleanup to be retried immediately.
+type cleanupFailureDoc struct {
+	DocID       string    `bson:"_id"`
+	EnvUUID     string    `bson:"env-uuid"`
+	Attempts    int       `bson:"attempts"`
+	LastAttempt time.Time `bson:"lastattempt"`
+	LastError   string    `bson:"lasterror"`
+}
+
+var (
+	// cleanupMinRetryDelay and cleanupMaxRetryDelay bound the time
+	// that Cleanup waits before retrying a failed cleanup; the delay
+	// doubles with each failed attempt.
+	cleanupMinRetryDelay = 10 * time.Second
+	cleanupMaxRetryDelay = 30 * time.Minute
+)
+
+// retryDelay returns the time to wait after the last of the given
+// number of failed attempts before a cleanup is run again.
+func retryDelay(attempts int) time.Duration {
+	delay := cleanupMinRetryDelay
+	for i := 1; i < attempts && delay < cleanupMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > cleanupMaxRetryDelay {
+		delay = cleanupMaxRetryDelay
+	}
+	return delay
+}
+
+// CleanupInfo describes a pending cleanup.
+type CleanupInfo struct {
+	// Id identifies the cleanup within its environment.
+	Id string
+	// Kind describes what is being cleaned up.
+	Kind string
+	// Prefix identifies the entities being cleaned up.
+	Prefix string
+	// Created holds the time the cleanup was scheduled.
+	Created time.Time
+	// Attempts holds the number of failed attempts to run the cleanup.
+	Attempts int
+	// LastAttempt holds the time of the last failed attempt, if any.
+	LastAttempt time.Time
+	// LastError holds the error of the last failed attempt, if any.
+	LastError string
+}
+
 // newCleanupOp returns a txn.Op that creates a cleanup document with a unique
 // id and the supplied kind and prefix.
 func (st *State) newCleanupOp(kind cleanupKind, prefix string) txn.Op {
@@ -70,50 +124,239 @@ func (st *State) NeedsCleanup() (bool, error) {
 // any such exist. It should be called periodically by at least one element
 // of the system.
 func (st *State) Cleanup() (err error) {
+	failures, err := st.cleanupFailures()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	now := time.Now()
 	var doc cleanupDoc
 	cleanups, closer := st.getCollection(cleanupsC)
 	defer closer()
 	iter := cleanups.Find(nil).Iter()
 	defer closeIter(iter, &err, "reading cleanup document")
 	for iter.Next(&doc) {
-		var err error
-		logger.Debugf("running %q cleanup: %q", doc.Kind, doc.Prefix)
-		switch doc.Kind {
-		case cleanupRelationSettings:
-			err = st.cleanupRelationSettings(doc.Prefix)
-		case cleanupUnitsForDyingService:
-			err = st.cleanupUnitsForDyingService(doc.Prefix)
-		case cleanupDyingUnit:
-			err = st.cleanupDyingUnit(doc.Prefix)
-		case cleanupRemovedUnit:
-			err = st.cleanupRemovedUnit(doc.Prefix)
-		case cleanupServicesForDyingEnvironment:
-			err = st.cleanupServicesForDyingEnvironment()
-		case cleanupDyingMachine:
-			err = st.cleanupDyingMachine(doc.Prefix)
-		case cleanupForceDestroyedMachine:
-			err = st.cleanupForceDestroyedMachine(doc.Prefix)
-		case cleanupAttachmentsForDyingStorage:
-			err = st.cleanupAttachmentsForDyingStorage(doc.Prefix)
-		case cleanupAttachmentsForDyingVolume:
-			err = st.cleanupAttachmentsForDyingVolume(doc.Prefix)
-		case cleanupAttachmentsForDyingFilesystem:
-			err = st.cleanupAttachmentsForDyingFilesystem(doc.Prefix)
-		default:
-			err = fmt.Errorf("unknown cleanup kind %q", doc.Kind)
+		if failure, ok := failures[doc.DocID]; ok {
+			retry := failure.LastAttempt.Add(retryDelay(failure.Attempts))
+			if now.Before(retry) {
+				logger.Debugf("not retrying %q cleanup %q before %v", doc.Kind, doc.Prefix, retry)
+				continue
+			}
 		}
-		if err != nil {
+		if err := st.runCleanup(&doc); err != nil {
 			logger.Warningf("cleanup failed: %v", err)
-			continue
+		}
+	}
+	return nil
+}
+
+// cleanupFailures returns the recorded cleanup failures, keyed by the
+// id of the cleanup document.
+func (st *State) cleanupFailures() (map[string]cleanupFailureDoc, error) {
+	coll, closer := st.getCollection(cleanupFailuresC)
+	defer closer()
+	var docs []cleanupFailureDoc
+	if err := coll.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot read cleanup failures")
+	}
+	failures := make(map[string]cleanupFailureDoc, len(docs))
+	for _, doc := range docs {
+		failures[doc.DocID] = doc
+	}
+	return failures, nil
+}
+
+// runCleanup runs the cleanup described by doc, removing the document
+// if it succeeds and recording the error if it fails.
+func (st *State) runCleanup(doc *cleanupDoc) error {
+	var err error
+	logger.Debugf("running %q cleanup: %q", doc.Kind, doc.Prefix)
+	switch doc.Kind {
+	case cleanupRelationSettings:
+		err = st.cleanupRelationSettings(doc.Prefix)
+	case cleanupUnitsForDyingService:
+		err = st.cleanupUnitsForDyingService(doc.Prefix)
+	case cleanupDyingUnit:
+		err = st.cleanupDyingUnit(doc.Prefix)
+	case cleanupRemovedUnit:
+		err = st.cleanupRemovedUnit(doc.Prefix)
+	case cleanupServicesForDyingEnvironment:
+		err = st.cleanupServicesForDyingEnvironment()
+	case cleanupDyingMachine:
+		err = st.cleanupDyingMachine(doc.Prefix)
+	case cleanupForceDestroyedMachine:
+		err = st.cleanupForceDestroyedMachine(doc.Prefix)
+	case cleanupAttachmentsForDyingStorage:
+		err = st.cleanupAttachmentsForDyingStorage(doc.Prefix)
+	case cleanupAttachmentsForDyingVolume:
+		err = st.cleanupAttachmentsForDyingVolume(doc.Prefix)
+	case cleanupAttachmentsForDyingFilesystem:
+		err = st.cleanupAttachmentsForDyingFilesystem(doc.Prefix)
+	case cleanupResourcesForRemovedService:
+		err = st.cleanupResourcesForRemovedService(doc.Prefix)
+	default:
+		err = fmt.Errorf("unknown cleanup kind %q", doc.Kind)
+	}
+	if err != nil {
+		if err := st.recordCleanupFailure(doc, err); err != nil {
+			logger.Warningf("cannot record cleanup failure: %v", err)
+		}
+		return err
+	}
+	if err := st.runTransaction(removeCleanupOps(doc.DocID)); err != nil {
+		logger.Warningf("cannot remove empty cleanup document: %v", err)
+	}
+	return nil
+}
+
+// recordCleanupFailure records a failed attempt to run the cleanup
+// described by doc, unless the cleanup no longer exists.
+func (st *State) recordCleanupFailure(doc *cleanupDoc, cleanupErr error) error {
+	failures, closer := st.getCollection(cleanupFailuresC)
+	defer closer()
+	now := nowToTheSecond()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			// The cleanup may have been removed, by a retry or
+			// skip, since it was read.
+			cleanups, closer := st.getCollection(cleanupsC)
+			defer closer()
+			if n, err := cleanups.FindId(doc.DocID).Count(); err != nil {
+				return nil, errors.Trace(err)
+			} else if n == 0 {
+				return nil, jujutxn.ErrNoOperations
+			}
 		}
 		ops := []txn.Op{{
 			C:      cleanupsC,
 			Id:     doc.DocID,
-			Remove: true,
+			Assert: txn.DocExists,
 		}}
-		if err := st.runTransaction(ops); err != nil {
-			logger.Warningf("cannot remove empty cleanup document: %v", err)
+		var existing cleanupFailureDoc
+		err := failures.FindId(doc.DocID).One(&existing)
+		switch {
+		case err == mgo.ErrNotFound:
+			ops = append(ops, txn.Op{
+				C:      cleanupFailuresC,
+				Id:     doc.DocID,
+				Assert: txn.DocMissing,
+				Insert: &cleanupFailureDoc{
+					DocID:       doc.DocID,
+					EnvUUID:     st.EnvironUUID(),
+					Attempts:    1,
+					LastAttempt: now,
+					LastError:   cleanupErr.Error(),
+				},
+			})
+		case err != nil:
+			return nil, errors.Trace(err)
+		default:
+			ops = append(ops, txn.Op{
+				C:      cleanupFailuresC,
+				Id:     doc.DocID,
+				Assert: bson.D{{"attempts", existing.Attempts}},
+				Update: bson.D{
+					{"$set", bson.D{
+						{"attempts", existing.Attempts + 1},
+						{"lastattempt", now},
+						{"lasterror", cleanupErr.Error()},
+					}},
+				},
+			})
 		}
+		return ops, nil
+	}
+	return st.run(buildTxn)
+}
+
+// removeCleanupOps returns the operations required to remove the
+// cleanup with the given document id, along with any record of its
+// failures.
+func removeCleanupOps(docID string) []txn.Op {
+	return []txn.Op{{
+		C:      cleanupsC,
+		Id:     docID,
+		Remove: true,
+	}, {
+		C:      cleanupFailuresC,
+		Id:     docID,
+		Remove: true,
+	}}
+}
+
+// Cleanups returns the pending cleanups, in the order in which they
+// were scheduled.
+func (st *State) Cleanups() ([]CleanupInfo, error) {
+	cleanups, closer := st.getCollection(cleanupsC)
+	defer closer()
+	var docs []cleanupDoc
+	if err := cleanups.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot read cleanup documents")
+	}
+	failures, err := st.cleanupFailures()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]CleanupInfo, len(docs))
+	for i, doc := range docs {
+		id := st.localID(doc.DocID)
+		failure := failures[doc.DocID]
+		info := CleanupInfo{
+			Id:          id,
+			Kind:        string(doc.Kind),
+			Prefix:      doc.Prefix,
+			Attempts:    failure.Attempts,
+			LastAttempt: failure.LastAttempt,
+			LastError:   failure.LastError,
+		}
+		// Cleanup ids are object ids, which record the time
+		// they were created.
+		if bson.IsObjectIdHex(id) {
+			info.Created = bson.ObjectIdHex(id).Time().UTC()
+		}
+		result[i] = info
+	}
+	return result, nil
+}
+
+// cleanup returns the document of the cleanup with the given id.
+func (st *State) cleanup(id string) (*cleanupDoc, error) {
+	cleanups, closer := st.getCollection(cleanupsC)
+	defer closer()
+	var doc cleanupDoc
+	err := cleanups.FindId(st.docID(id)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("cleanup %q", id)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot read cleanup %q", id)
+	}
+	return &doc, nil
+}
+
+// RetryCleanup immediately runs the pending cleanup with the given id,
+// returning any error encountered.
+func (st *State) RetryCleanup(id string) error {
+	doc, err := st.cleanup(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Annotatef(st.runCleanup(doc), "cleanup %q failed", id)
+}
+
+// SkipCleanup removes the pending cleanup with the given id without
+// running it. Any entities it would have cleaned up are left as they
+// are.
+func (st *State) SkipCleanup(id string) error {
+	doc, err := st.cleanup(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("skipping %q cleanup: %q", doc.Kind, doc.Prefix)
+	ops := removeCleanupOps(doc.DocID)
+	ops[0].Assert = txn.DocExists
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("cleanup %q", id)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot skip cleanup %q", id)
 	}
 	return nil
 }
@@ -5,6 +5,8 @@ package state_test
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
 	"github.com/juju/juju/storage/provider"
 	"github.com/juju/juju/storage/provider/registry"
 )
@@ -514,12 +517,129 @@ func (s *CleanupSuite) TestCleanupFilesystemAttachments(c *gc.C) {
 	c.Assert(attachment.Life(), gc.Equals, state.Dying)
 }
 
+func (s *CleanupSuite) TestCleanupServiceResources(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	_, err := s.State.SetResource("wordpress", "site", strings.NewReader("hello"), 5, "sha", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	err = wordpress.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertDoesNotNeedCleanup(c)
+
+	// Removing the service schedules the removal of its resources.
+	err = wordpress.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertNeedsCleanup(c)
+	s.assertCleanupRuns(c)
+	s.assertDoesNotNeedCleanup(c)
+
+	resources, err := s.State.ServiceResources("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, gc.HasLen, 0)
+}
+
+func (s *CleanupSuite) TestCleanupServiceResourcesAttachedConcurrently(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	defer state.SetBeforeHooks(c, s.State, func() {
+		_, err := s.State.SetResource("wordpress", "site", strings.NewReader("hello"), 5, "sha", s.Owner)
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+
+	err := wordpress.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertCleanupCount(c, 1)
+	resources, err := s.State.ServiceResources("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, gc.HasLen, 0)
+}
+
 func (s *CleanupSuite) TestNothingToCleanup(c *gc.C) {
 	s.assertDoesNotNeedCleanup(c)
 	s.assertCleanupRuns(c)
 	s.assertDoesNotNeedCleanup(c)
 }
 
+func (s *CleanupSuite) TestCleanupFailureRecorded(c *gc.C) {
+	err := state.AddCleanup(s.State, "bogus", "foo")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertCleanupRuns(c)
+
+	// The failed cleanup is not retried until the retry delay has
+	// passed.
+	s.assertCleanupRuns(c)
+	cleanups, err := s.State.Cleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cleanups, gc.HasLen, 1)
+	c.Assert(cleanups[0].Attempts, gc.Equals, 1)
+
+	s.PatchValue(state.CleanupMinRetryDelay, time.Duration(0))
+	s.assertCleanupRuns(c)
+	cleanups, err = s.State.Cleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cleanups, gc.HasLen, 1)
+	info := cleanups[0]
+	c.Assert(info.Kind, gc.Equals, "bogus")
+	c.Assert(info.Prefix, gc.Equals, "foo")
+	c.Assert(info.Attempts, gc.Equals, 2)
+	c.Assert(info.LastError, gc.Equals, `unknown cleanup kind "bogus"`)
+	c.Assert(info.LastAttempt.IsZero(), jc.IsFalse)
+	c.Assert(time.Since(info.Created) < time.Minute, jc.IsTrue)
+}
+
+func (s *CleanupSuite) TestCleanupFailureDoesNotTriggerWatcher(c *gc.C) {
+	w := s.State.WatchCleanups()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := state.AddCleanup(s.State, "bogus", "foo")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	s.assertCleanupRuns(c)
+	wc.AssertNoChange()
+}
+
+func (s *CleanupSuite) TestRetryCleanup(c *gc.C) {
+	err := state.AddCleanup(s.State, "settings", "r#99#")
+	c.Assert(err, jc.ErrorIsNil)
+	err = state.AddCleanup(s.State, "bogus", "foo")
+	c.Assert(err, jc.ErrorIsNil)
+
+	cleanups, err := s.State.Cleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cleanups, gc.HasLen, 2)
+	c.Assert(cleanups[0].Kind, gc.Equals, "settings")
+	c.Assert(cleanups[1].Kind, gc.Equals, "bogus")
+
+	err = s.State.RetryCleanup(cleanups[1].Id)
+	c.Assert(err, gc.ErrorMatches, `cleanup ".*" failed: unknown cleanup kind "bogus"`)
+	err = s.State.RetryCleanup(cleanups[0].Id)
+	c.Assert(err, jc.ErrorIsNil)
+
+	remaining, err := s.State.Cleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remaining, gc.HasLen, 1)
+	c.Assert(remaining[0].Id, gc.Equals, cleanups[1].Id)
+	c.Assert(remaining[0].Attempts, gc.Equals, 1)
+}
+
+func (s *CleanupSuite) TestSkipCleanup(c *gc.C) {
+	err := state.AddCleanup(s.State, "bogus", "foo")
+	c.Assert(err, jc.ErrorIsNil)
+	cleanups, err := s.State.Cleanups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cleanups, gc.HasLen, 1)
+
+	err = s.State.SkipCleanup(cleanups[0].Id)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertDoesNotNeedCleanup(c)
+
+	err = s.State.SkipCleanup(cleanups[0].Id)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = s.State.RetryCleanup(cleanups[0].Id)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *CleanupSuite) assertCleanupRuns(c *gc.C) {
 	err := s.State.Cleanup()
 	c.Assert(err, jc.ErrorIsNil)
@@ -0,0 +1,278 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The description package defines a versioned, serialisable
+// description of the model of an environment: its configuration,
+// users, machines, services, units, relations, storage, annotations
+// and id sequences. Descriptions are produced by exporting an environment
+// from state, and may be imported to recreate the model in a new
+// environment.
+package description
+
+import (
+	"github.com/juju/errors"
+	goyaml "gopkg.in/yaml.v1"
+)
+
+// Version is the version of the model description produced by
+// this package. Descriptions with a different version cannot be
+// deserialised.
+const Version = 1
+
+// Model describes the model of an environment.
+type Model struct {
+	// Version holds the version of the description format.
+	Version int `yaml:"version"`
+
+	// UUID and Name identify the environment the model was
+	// exported from.
+	UUID string `yaml:"uuid"`
+	Name string `yaml:"name"`
+
+	// Owner holds the name of the user owning the environment.
+	Owner string `yaml:"owner"`
+
+	// Config holds the environment configuration.
+	Config map[string]interface{} `yaml:"config"`
+
+	// Constraints holds the environment constraints.
+	Constraints string `yaml:"constraints,omitempty"`
+
+	// Annotations holds the environment annotations.
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+
+	Users     []User            `yaml:"users,omitempty"`
+	Machines  []Machine         `yaml:"machines,omitempty"`
+	Charms    []Charm           `yaml:"charms,omitempty"`
+	Services  []Service         `yaml:"services,omitempty"`
+	Relations []Relation        `yaml:"relations,omitempty"`
+	Storage   []StorageInstance `yaml:"storage,omitempty"`
+
+	// Sequences holds the next value of each of the sequences from
+	// which the ids of the environment's entities are allocated. They
+	// are only restored when the model is imported as part of a
+	// migration, so that ids are not reused.
+	Sequences map[string]int `yaml:"sequences,omitempty"`
+}
+
+// User describes a user with access to the environment.
+type User struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display-name,omitempty"`
+	CreatedBy   string `yaml:"created-by"`
+}
+
+// Machine describes a machine and the containers inside it.
+type Machine struct {
+	Id          string   `yaml:"id"`
+	Series      string   `yaml:"series"`
+	Jobs        []string `yaml:"jobs"`
+	Constraints string   `yaml:"constraints,omitempty"`
+
+	// ContainerType holds the type of container the machine is,
+	// if it is a container.
+	ContainerType string `yaml:"container-type,omitempty"`
+
+	// InstanceId and HardwareCharacteristics describe the
+	// instance the machine was provisioned on, if any. They are
+	// only restored when the model is imported as part of a
+	// migration.
+	InstanceId              string `yaml:"instance-id,omitempty"`
+	HardwareCharacteristics string `yaml:"hardware-characteristics,omitempty"`
+
+	// Nonce and PasswordHash hold the provisioning nonce and the
+	// password hash of the machine's agent. They are only restored
+	// when the model is imported as part of a migration, so that the
+	// agent can continue to log in.
+	Nonce        string `yaml:"nonce,omitempty"`
+	PasswordHash string `yaml:"password-hash,omitempty"`
+
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Containers  []Machine         `yaml:"containers,omitempty"`
+}
+
+// Charm describes a charm used by the environment's services.
+type Charm struct {
+	URL          string `yaml:"url"`
+	StoragePath  string `yaml:"storage-path,omitempty"`
+	BundleSha256 string `yaml:"bundle-sha256,omitempty"`
+
+	// Archive holds the base64 encoded charm archive, so that the
+	// model can be imported without access to the environment it
+	// was exported from. It is not filled in by State.Export.
+	Archive string `yaml:"archive,omitempty"`
+}
+
+// Service describes a service and its units.
+type Service struct {
+	Name        string `yaml:"name"`
+	Owner       string `yaml:"owner"`
+	CharmURL    string `yaml:"charm-url"`
+	Exposed     bool   `yaml:"exposed,omitempty"`
+	Constraints string `yaml:"constraints,omitempty"`
+
+	// Settings holds the service's charm config settings.
+	Settings map[string]interface{} `yaml:"settings,omitempty"`
+
+	// StorageConstraints holds the service's storage constraints,
+	// keyed by charm storage name.
+	StorageConstraints map[string]StorageConstraints `yaml:"storage-constraints,omitempty"`
+
+	// Leader holds the name of the unit that is the service's leader,
+	// if any, and LeaderSettings the settings it has published. They
+	// are only restored when the model is imported as part of a
+	// migration.
+	Leader         string                 `yaml:"leader,omitempty"`
+	LeaderSettings map[string]interface{} `yaml:"leader-settings,omitempty"`
+
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Units       []Unit            `yaml:"units,omitempty"`
+}
+
+// StorageConstraints describes the storage to provision for each unit
+// of a service.
+type StorageConstraints struct {
+	Pool  string `yaml:"pool"`
+	Size  uint64 `yaml:"size"`
+	Count uint64 `yaml:"count"`
+}
+
+// Unit describes a unit of a service.
+type Unit struct {
+	Name string `yaml:"name"`
+
+	// Machine holds the id of the machine the unit is assigned to,
+	// if it is a principal unit.
+	Machine string `yaml:"machine,omitempty"`
+
+	// Principal holds the name of the unit's principal, if it is
+	// a subordinate unit.
+	Principal string `yaml:"principal,omitempty"`
+
+	// PasswordHash holds the password hash of the unit's agent. It
+	// is only restored when the model is imported as part of a
+	// migration.
+	PasswordHash string `yaml:"password-hash,omitempty"`
+
+	// CharmURL holds the URL of the charm the unit's agent is
+	// running, and OpenedPorts the ports it has opened. They are only
+	// restored when the model is imported as part of a migration.
+	CharmURL    string      `yaml:"charm-url,omitempty"`
+	OpenedPorts []PortRange `yaml:"opened-ports,omitempty"`
+
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// PortRange describes a range of ports opened by a unit.
+type PortRange struct {
+	FromPort int    `yaml:"from-port"`
+	ToPort   int    `yaml:"to-port"`
+	Protocol string `yaml:"protocol"`
+}
+
+// Relation describes a relation between services.
+type Relation struct {
+	Id        int        `yaml:"id"`
+	Key       string     `yaml:"key"`
+	Endpoints []Endpoint `yaml:"endpoints"`
+
+	// Units describes the units in the relation's scopes. They are
+	// only restored when the model is imported as part of a
+	// migration; otherwise units enter scope as their agents join
+	// the relation.
+	Units []RelationUnit `yaml:"units,omitempty"`
+}
+
+// RelationUnit describes a unit in the scope of a relation, and the
+// settings it has published in the relation.
+type RelationUnit struct {
+	Name     string                 `yaml:"name"`
+	Settings map[string]interface{} `yaml:"settings,omitempty"`
+}
+
+// Endpoint describes one end of a relation.
+type Endpoint struct {
+	Service   string `yaml:"service"`
+	Name      string `yaml:"name"`
+	Role      string `yaml:"role"`
+	Interface string `yaml:"interface"`
+	Scope     string `yaml:"scope"`
+}
+
+// StorageInstance describes a storage instance. Storage instances are
+// recreated when units are added with the service's storage
+// constraints, so they are only restored when the model is imported
+// as part of a migration, along with their attachments and the
+// volumes or filesystems that were provisioned for them. Only storage
+// instances owned by units can be restored.
+type StorageInstance struct {
+	Id    string `yaml:"id"`
+	Kind  string `yaml:"kind"`
+	Owner string `yaml:"owner"`
+	Name  string `yaml:"name"`
+
+	// Attachments holds the names of the units the storage instance
+	// is attached to.
+	Attachments []string `yaml:"attachments,omitempty"`
+
+	// Volume or Filesystem describes the provisioned volume or
+	// filesystem assigned to the storage instance, if any.
+	Volume     *Volume     `yaml:"volume,omitempty"`
+	Filesystem *Filesystem `yaml:"filesystem,omitempty"`
+}
+
+// Volume describes a provisioned volume.
+type Volume struct {
+	VolumeId    string             `yaml:"volume-id"`
+	HardwareId  string             `yaml:"hardware-id,omitempty"`
+	Size        uint64             `yaml:"size"`
+	Pool        string             `yaml:"pool"`
+	Persistent  bool               `yaml:"persistent,omitempty"`
+	Zone        string             `yaml:"zone,omitempty"`
+	Attachments []VolumeAttachment `yaml:"attachments,omitempty"`
+}
+
+// VolumeAttachment describes the provisioned attachment of a volume
+// to a machine.
+type VolumeAttachment struct {
+	Machine    string `yaml:"machine"`
+	DeviceName string `yaml:"device-name,omitempty"`
+	BusAddress string `yaml:"bus-address,omitempty"`
+	ReadOnly   bool   `yaml:"read-only,omitempty"`
+}
+
+// Filesystem describes a provisioned filesystem.
+type Filesystem struct {
+	FilesystemId string                 `yaml:"filesystem-id"`
+	Size         uint64                 `yaml:"size"`
+	Pool         string                 `yaml:"pool"`
+	Attachments  []FilesystemAttachment `yaml:"attachments,omitempty"`
+}
+
+// FilesystemAttachment describes the provisioned attachment of a
+// filesystem to a machine.
+type FilesystemAttachment struct {
+	Machine    string `yaml:"machine"`
+	MountPoint string `yaml:"mount-point,omitempty"`
+	ReadOnly   bool   `yaml:"read-only,omitempty"`
+}
+
+// Serialize returns the YAML representation of the model.
+func Serialize(model *Model) ([]byte, error) {
+	if model.Version != Version {
+		return nil, errors.NotValidf("model description version %d", model.Version)
+	}
+	return goyaml.Marshal(model)
+}
+
+// Deserialize returns the model described by the given YAML data.
+func Deserialize(data []byte) (*Model, error) {
+	var model Model
+	if err := goyaml.Unmarshal(data, &model); err != nil {
+		return nil, errors.Annotate(err, "cannot parse model description")
+	}
+	if model.Version != Version {
+		return nil, errors.NotSupportedf("model description version %d", model.Version)
+	}
+	return &model, nil
+}
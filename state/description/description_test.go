@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package description_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/description"
+)
+
+type DescriptionSuite struct{}
+
+var _ = gc.Suite(&DescriptionSuite{})
+
+func (*DescriptionSuite) TestRoundTrip(c *gc.C) {
+	model := &description.Model{
+		Version:     description.Version,
+		UUID:        "deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		Name:        "testenv",
+		Owner:       "admin@local",
+		Config:      map[string]interface{}{"type": "dummy", "name": "testenv"},
+		Constraints: "mem=4096M",
+		Annotations: map[string]string{"owner": "ops"},
+		Users: []description.User{{
+			Name:      "admin@local",
+			CreatedBy: "admin@local",
+		}},
+		Machines: []description.Machine{{
+			Id:     "0",
+			Series: "trusty",
+			Jobs:   []string{"JobHostUnits"},
+			Containers: []description.Machine{{
+				Id:            "0/lxc/0",
+				Series:        "trusty",
+				Jobs:          []string{"JobHostUnits"},
+				ContainerType: "lxc",
+			}},
+		}},
+		Charms: []description.Charm{{URL: "cs:trusty/mysql-1"}},
+		Services: []description.Service{{
+			Name:     "mysql",
+			Owner:    "user-admin@local",
+			CharmURL: "cs:trusty/mysql-1",
+			Exposed:  true,
+			Settings: map[string]interface{}{"dataset-size": "80%"},
+			StorageConstraints: map[string]description.StorageConstraints{
+				"data": {Pool: "ebs", Size: 1024, Count: 1},
+			},
+			Leader:         "mysql/0",
+			LeaderSettings: map[string]interface{}{"master": "mysql/0"},
+			Units: []description.Unit{{
+				Name:        "mysql/0",
+				Machine:     "0/lxc/0",
+				CharmURL:    "cs:trusty/mysql-1",
+				OpenedPorts: []description.PortRange{{FromPort: 3306, ToPort: 3306, Protocol: "tcp"}},
+			}},
+		}},
+		Relations: []description.Relation{{
+			Id:  0,
+			Key: "mysql:cluster",
+			Endpoints: []description.Endpoint{{
+				Service:   "mysql",
+				Name:      "cluster",
+				Role:      "peer",
+				Interface: "mysql-ha",
+				Scope:     "global",
+			}},
+			Units: []description.RelationUnit{{
+				Name:     "mysql/0",
+				Settings: map[string]interface{}{"hostname": "mysql-0"},
+			}},
+		}},
+		Storage: []description.StorageInstance{{
+			Id:          "data/0",
+			Kind:        "block",
+			Owner:       "unit-mysql-0",
+			Name:        "data",
+			Attachments: []string{"mysql/0"},
+			Volume: &description.Volume{
+				VolumeId: "vol-0",
+				Size:     1024,
+				Pool:     "ebs",
+				Attachments: []description.VolumeAttachment{{
+					Machine:    "0/lxc/0",
+					DeviceName: "xvdf",
+				}},
+			},
+		}},
+		Sequences: map[string]int{"machine": 1, "relation": 1},
+	}
+	data, err := description.Serialize(model)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := description.Deserialize(data)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, model)
+}
+
+func (*DescriptionSuite) TestSerializeInvalidVersion(c *gc.C) {
+	_, err := description.Serialize(&description.Model{Version: 2})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (*DescriptionSuite) TestDeserializeUnsupportedVersion(c *gc.C) {
+	_, err := description.Deserialize([]byte("version: 2\nuuid: foo\n"))
+	c.Assert(err, gc.ErrorMatches, "model description version 2 not supported")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (*DescriptionSuite) TestDeserializeInvalid(c *gc.C) {
+	_, err := description.Deserialize([]byte("version: [\n"))
+	c.Assert(err, gc.ErrorMatches, "cannot parse model description: .*")
+}
@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// envGroupDoc records that an environment has been shared with a
+// group of users.
+type envGroupDoc struct {
+	ID          string    `bson:"_id"`
+	EnvUUID     string    `bson:"env-uuid"`
+	GroupName   string    `bson:"group"`
+	CreatedBy   string    `bson:"createdby"`
+	DateCreated time.Time `bson:"datecreated"`
+}
+
+// AddEnvironmentGroup shares the environment with the named group,
+// giving all of its members access to the environment.
+func (st *State) AddEnvironmentGroup(group string, createdBy names.UserTag) error {
+	id := userGroupID(group)
+	ops := []txn.Op{{
+		C:      userGroupsC,
+		Id:     id,
+		Assert: txn.DocExists,
+	}, {
+		C:      envGroupsC,
+		Id:     id,
+		Assert: txn.DocMissing,
+		Insert: &envGroupDoc{
+			ID:          id,
+			EnvUUID:     st.EnvironUUID(),
+			GroupName:   id,
+			CreatedBy:   createdBy.Username(),
+			DateCreated: nowToTheSecond(),
+		},
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if _, err := st.UserGroup(group); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.AlreadyExistsf("environment group %q", group)
+	}
+	return errors.Trace(err)
+}
+
+// RemoveEnvironmentGroup stops sharing the environment with the named
+// group. Members of the group keep any access they have been given
+// individually or through other groups.
+func (st *State) RemoveEnvironmentGroup(group string) error {
+	ops := []txn.Op{{
+		C:      envGroupsC,
+		Id:     userGroupID(group),
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.NotFoundf("environment group %q", group)
+	}
+	return errors.Trace(err)
+}
+
+// EnvironmentGroups returns the names of the groups that the
+// environment has been shared with, sorted by name.
+func (st *State) EnvironmentGroups() ([]string, error) {
+	envGroups, closer := st.getCollection(envGroupsC)
+	defer closer()
+
+	var docs []envGroupDoc
+	if err := envGroups.Find(nil).All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]string, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.GroupName
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// HasEnvironmentAccess reports whether the given user may use the
+// environment, either as an environment user or as a member of a
+// group that the environment has been shared with.
+func (st *State) HasEnvironmentAccess(user names.UserTag) (bool, error) {
+	_, err := st.EnvironmentUser(user)
+	if err == nil {
+		return true, nil
+	} else if !errors.IsNotFound(err) {
+		return false, errors.Trace(err)
+	}
+	uuids, err := st.groupEnvironmentUUIDs(user, bson.D{{"env-uuid", st.EnvironUUID()}})
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(uuids) > 0, nil
+}
+
+// groupEnvironmentUUIDs returns the UUIDs of the environments matching
+// the given query that have been shared with any group the given user
+// belongs to. A raw collection is required to support queries across
+// multiple environments.
+func (st *State) groupEnvironmentUUIDs(user names.UserTag, query bson.D) ([]string, error) {
+	groups, err := st.userGroupNames(user)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	envGroups, closer := st.getRawCollection(envGroupsC)
+	defer closer()
+
+	query = append(query, bson.DocElem{"group", bson.D{{"$in", groups}}})
+	var uuids []string
+	if err := envGroups.Find(query).Distinct("env-uuid", &uuids); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return uuids, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type EnvGroupSuite struct {
+	ConnSuite
+	group *state.UserGroup
+	user  names.UserTag
+}
+
+var _ = gc.Suite(&EnvGroupSuite{})
+
+func (s *EnvGroupSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	group, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	s.group = group
+	s.user = s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true}).UserTag()
+	err = group.AddMember(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EnvGroupSuite) TestAddEnvironmentGroup(c *gc.C) {
+	err := s.State.AddEnvironmentGroup("Ops", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+
+	groups, err := s.State.EnvironmentGroups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(groups, jc.DeepEquals, []string{"ops"})
+
+	err = s.State.AddEnvironmentGroup("ops", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `environment group "ops" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *EnvGroupSuite) TestAddEnvironmentGroupMissingGroup(c *gc.C) {
+	err := s.State.AddEnvironmentGroup("dev", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `group "dev" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EnvGroupSuite) TestRemoveEnvironmentGroup(c *gc.C) {
+	err := s.State.AddEnvironmentGroup("ops", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveEnvironmentGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+
+	groups, err := s.State.EnvironmentGroups()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(groups, gc.HasLen, 0)
+
+	err = s.State.RemoveEnvironmentGroup("ops")
+	c.Assert(err, gc.ErrorMatches, `environment group "ops" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EnvGroupSuite) TestHasEnvironmentAccess(c *gc.C) {
+	hasAccess, err := s.State.HasEnvironmentAccess(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hasAccess, jc.IsFalse)
+
+	err = s.State.AddEnvironmentGroup("ops", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	hasAccess, err = s.State.HasEnvironmentAccess(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hasAccess, jc.IsTrue)
+
+	// Access follows group membership.
+	err = s.group.RemoveMember(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	hasAccess, err = s.State.HasEnvironmentAccess(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hasAccess, jc.IsFalse)
+
+	// Environment users always have access.
+	hasAccess, err = s.State.HasEnvironmentAccess(s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hasAccess, jc.IsTrue)
+}
+
+func (s *EnvGroupSuite) TestHasEnvironmentAccessOtherEnvironment(c *gc.C) {
+	otherState := s.Factory.MakeEnvironment(c, nil)
+	defer otherState.Close()
+	err := otherState.AddEnvironmentGroup("ops", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+
+	hasAccess, err := s.State.HasEnvironmentAccess(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hasAccess, jc.IsFalse)
+
+	hasAccess, err = otherState.HasEnvironmentAccess(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hasAccess, jc.IsTrue)
+}
+
+func (s *EnvGroupSuite) TestEnvironmentsForUser(c *gc.C) {
+	otherState := s.Factory.MakeEnvironment(c, nil)
+	defer otherState.Close()
+	err := otherState.AddEnvironmentGroup("ops", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = otherState.AddEnvironmentUser(s.user, s.Owner, "")
+	c.Assert(err, jc.ErrorIsNil)
+
+	environments, err := s.State.EnvironmentsForUser(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(environments, gc.HasLen, 1)
+	c.Assert(environments[0].UUID(), gc.Equals, otherState.EnvironUUID())
+
+	err = s.State.AddEnvironmentGroup("ops", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	environments, err = s.State.EnvironmentsForUser(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(environments, gc.HasLen, 2)
+}
+
+func (s *EnvGroupSuite) TestIsSystemAdministrator(c *gc.C) {
+	isAdmin, err := s.State.IsSystemAdministrator(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isAdmin, jc.IsFalse)
+
+	err = s.State.AddEnvironmentGroup("ops", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	isAdmin, err = s.State.IsSystemAdministrator(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isAdmin, jc.IsTrue)
+}
@@ -33,6 +33,28 @@ type environmentDoc struct {
 	Life       Life
 	Owner      string `bson:"owner"`
 	ServerUUID string `bson:"server-uuid"`
+
+	// Migrated is set when the environment has been migrated to
+	// another system.
+	Migrated bool `bson:"migrated,omitempty"`
+
+	// Imported is set when the environment was created by importing
+	// an environment migrated from another system.
+	Imported bool `bson:"imported,omitempty"`
+
+	// Importing is set while the migration an imported environment was
+	// created by is in progress. The environment's workers are not run
+	// until it is activated, so that they do not act on the
+	// environment's machines while the source system still manages
+	// them.
+	Importing bool `bson:"importing,omitempty"`
+
+	// ReturnAddrs and ReturnCACert hold the API addresses and CA
+	// certificate of the system an imported environment was migrated
+	// from, once the migration has been aborted. Agents that connected
+	// to this system are sent back to that one.
+	ReturnAddrs  []string `bson:"return-addrs,omitempty"`
+	ReturnCACert string   `bson:"return-cacert,omitempty"`
 }
 
 // StateServerEnvironment returns the environment that was bootstrapped.
@@ -110,7 +132,19 @@ func (st *State) AllEnvironments() ([]*Environment, error) {
 // environment document means that we have a way to represent external
 // environments, perhaps for future use around cross environment
 // relations.
-func (st *State) NewEnvironment(cfg *config.Config, owner names.UserTag) (_ *Environment, _ *State, err error) {
+func (st *State) NewEnvironment(cfg *config.Config, owner names.UserTag) (*Environment, *State, error) {
+	return st.newEnvironment(cfg, owner, false)
+}
+
+// NewImportingEnvironment creates a new environment, like NewEnvironment,
+// into which an environment being migrated from another system is to be
+// imported. The environment's workers are not run until it is activated
+// with ActivateImportedEnvironment, once the migration has succeeded.
+func (st *State) NewImportingEnvironment(cfg *config.Config, owner names.UserTag) (*Environment, *State, error) {
+	return st.newEnvironment(cfg, owner, true)
+}
+
+func (st *State) newEnvironment(cfg *config.Config, owner names.UserTag, importing bool) (_ *Environment, _ *State, err error) {
 	if owner.IsLocal() {
 		if _, err := st.User(owner); err != nil {
 			return nil, nil, errors.Annotate(err, "cannot create environment")
@@ -140,6 +174,13 @@ func (st *State) NewEnvironment(cfg *config.Config, owner names.UserTag) (_ *Env
 	if err != nil {
 		return nil, nil, errors.Annotate(err, "failed to create new environment")
 	}
+	if importing {
+		for _, op := range ops {
+			if doc, ok := op.Insert.(*environmentDoc); ok {
+				doc.Importing = true
+			}
+		}
+	}
 	err = newState.runTransaction(ops)
 	if err == txn.ErrAborted {
 
@@ -212,6 +253,27 @@ func (e *Environment) Life() Life {
 	return e.doc.Life
 }
 
+// Migrated returns whether the environment has been migrated to
+// another system. The agents of a migrated environment connect to the
+// other system, and its workers should not be run in this one.
+func (e *Environment) Migrated() bool {
+	return e.doc.Migrated
+}
+
+// Imported returns whether the environment was created by importing an
+// environment migrated from another system.
+func (e *Environment) Imported() bool {
+	return e.doc.Imported
+}
+
+// Importing returns whether the environment was created by importing
+// an environment migrated from another system, and has yet to be
+// activated. The workers of an environment being imported should not
+// be run.
+func (e *Environment) Importing() bool {
+	return e.doc.Importing
+}
+
 // Owner returns tag representing the owner of the environment.
 // The owner is the user that created the environment.
 func (e *Environment) Owner() names.UserTag {
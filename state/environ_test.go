@@ -187,6 +187,21 @@ func (s *EnvironSuite) TestConfigForOtherEnv(c *gc.C) {
 }
 
 // createTestEnvConfig returns a new environment config and its UUID for testing.
+func (s *EnvironSuite) TestNewImportingEnvironment(c *gc.C) {
+	cfg, _ := s.createTestEnvConfig(c)
+	owner := names.NewUserTag("test@remote")
+
+	env, st, err := s.State.NewImportingEnvironment(cfg, owner)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	c.Assert(env.Importing(), jc.IsTrue)
+	c.Assert(env.Life(), gc.Equals, state.Alive)
+
+	env, err = s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Importing(), jc.IsFalse)
+}
+
 func (s *EnvironSuite) createTestEnvConfig(c *gc.C) (*config.Config, string) {
 	uuid, err := utils.NewUUID()
 	c.Assert(err, jc.ErrorIsNil)
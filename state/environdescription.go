@@ -0,0 +1,1148 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v5"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state/description"
+)
+
+// Export returns a description of the model of the environment.
+func (st *State) Export() (*description.Model, error) {
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg, err := env.Config()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	model := &description.Model{
+		Version: description.Version,
+		UUID:    env.UUID(),
+		Name:    env.Name(),
+		Owner:   env.Owner().Username(),
+		Config:  cfg.AllAttrs(),
+	}
+	cons, err := st.EnvironConstraints()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	model.Constraints = cons.String()
+	if model.Annotations, err = st.Annotations(env); err != nil {
+		return nil, errors.Trace(err)
+	}
+	users, err := env.Users()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, user := range users {
+		model.Users = append(model.Users, description.User{
+			Name:        user.UserName(),
+			DisplayName: user.DisplayName(),
+			CreatedBy:   user.CreatedBy(),
+		})
+	}
+	if model.Machines, err = st.exportMachines(); err != nil {
+		return nil, errors.Annotate(err, "cannot export machines")
+	}
+	if model.Services, model.Charms, err = st.exportServices(); err != nil {
+		return nil, errors.Annotate(err, "cannot export services")
+	}
+	relations, err := st.AllRelations()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot export relations")
+	}
+	for _, relation := range relations {
+		rel := description.Relation{
+			Id:  relation.Id(),
+			Key: relation.String(),
+		}
+		for _, ep := range relation.Endpoints() {
+			rel.Endpoints = append(rel.Endpoints, description.Endpoint{
+				Service:   ep.ServiceName,
+				Name:      ep.Name,
+				Role:      string(ep.Role),
+				Interface: ep.Interface,
+				Scope:     string(ep.Scope),
+			})
+		}
+		if rel.Units, err = st.exportRelationUnits(relation); err != nil {
+			return nil, errors.Annotatef(err, "cannot export relation %q", rel.Key)
+		}
+		model.Relations = append(model.Relations, rel)
+	}
+	storageInstances, err := st.AllStorageInstances()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot export storage")
+	}
+	for _, si := range storageInstances {
+		storage, err := st.exportStorageInstance(si)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot export storage %q", si.StorageTag().Id())
+		}
+		model.Storage = append(model.Storage, storage)
+	}
+	if model.Sequences, err = st.sequences(); err != nil {
+		return nil, errors.Annotate(err, "cannot export sequences")
+	}
+	return model, nil
+}
+
+// exportRelationUnits returns descriptions of the units in the
+// relation's scopes, and of the settings they have published in it.
+func (st *State) exportRelationUnits(relation *Relation) ([]description.RelationUnit, error) {
+	var result []description.RelationUnit
+	for _, ep := range relation.Endpoints() {
+		service, err := st.Service(ep.ServiceName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, unit := range units {
+			ru, err := relation.Unit(unit)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			inScope, err := ru.InScope()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if !inScope {
+				continue
+			}
+			settings, err := ru.Settings()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			runit := description.RelationUnit{Name: unit.Name()}
+			if values := settings.Map(); len(values) > 0 {
+				runit.Settings = values
+			}
+			result = append(result, runit)
+		}
+	}
+	return result, nil
+}
+
+// exportStorageInstance returns a description of the storage instance,
+// its attachments, and the volume or filesystem provisioned for it.
+func (st *State) exportStorageInstance(si StorageInstance) (description.StorageInstance, error) {
+	result := description.StorageInstance{
+		Id:    si.StorageTag().Id(),
+		Kind:  storageKindString(si.Kind()),
+		Owner: si.Owner().String(),
+		Name:  si.StorageName(),
+	}
+	attachments, err := st.StorageAttachments(si.StorageTag())
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, att := range attachments {
+		result.Attachments = append(result.Attachments, att.Unit().Id())
+	}
+	if volume, err := st.StorageInstanceVolume(si.StorageTag()); err == nil {
+		if result.Volume, err = st.exportVolume(volume); err != nil {
+			return result, errors.Trace(err)
+		}
+	} else if !errors.IsNotFound(err) {
+		return result, errors.Trace(err)
+	}
+	if filesystem, err := st.StorageInstanceFilesystem(si.StorageTag()); err == nil {
+		if result.Filesystem, err = st.exportFilesystem(filesystem); err != nil {
+			return result, errors.Trace(err)
+		}
+	} else if !errors.IsNotFound(err) {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// exportVolume returns a description of the volume and its machine
+// attachments, or nil if it has not been provisioned.
+func (st *State) exportVolume(volume Volume) (*description.Volume, error) {
+	info, err := volume.Info()
+	if errors.IsNotProvisioned(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := &description.Volume{
+		VolumeId:   info.VolumeId,
+		HardwareId: info.HardwareId,
+		Size:       info.Size,
+		Pool:       info.Pool,
+		Persistent: info.Persistent,
+		Zone:       info.Zone,
+	}
+	attachments, err := st.VolumeAttachments(volume.VolumeTag())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, att := range attachments {
+		info, err := att.Info()
+		if errors.IsNotProvisioned(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result.Attachments = append(result.Attachments, description.VolumeAttachment{
+			Machine:    att.Machine().Id(),
+			DeviceName: info.DeviceName,
+			BusAddress: info.BusAddress,
+			ReadOnly:   info.ReadOnly,
+		})
+	}
+	return result, nil
+}
+
+// exportFilesystem returns a description of the filesystem and its
+// machine attachments, or nil if it has not been provisioned.
+func (st *State) exportFilesystem(filesystem Filesystem) (*description.Filesystem, error) {
+	info, err := filesystem.Info()
+	if errors.IsNotProvisioned(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := &description.Filesystem{
+		FilesystemId: info.FilesystemId,
+		Size:         info.Size,
+		Pool:         info.Pool,
+	}
+	attachments, err := st.FilesystemAttachments(filesystem.FilesystemTag())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, att := range attachments {
+		info, err := att.Info()
+		if errors.IsNotProvisioned(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result.Attachments = append(result.Attachments, description.FilesystemAttachment{
+			Machine:    att.Machine().Id(),
+			MountPoint: info.MountPoint,
+			ReadOnly:   info.ReadOnly,
+		})
+	}
+	return result, nil
+}
+
+// exportMachines returns descriptions of all top level machines, with
+// their containers nested within them.
+func (st *State) exportMachines() ([]description.Machine, error) {
+	machines, err := st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	children := make(map[string][]*Machine)
+	var topLevel []*Machine
+	for _, m := range machines {
+		if parentId, ok := m.ParentId(); ok {
+			children[parentId] = append(children[parentId], m)
+		} else {
+			topLevel = append(topLevel, m)
+		}
+	}
+	var export func(m *Machine) (description.Machine, error)
+	export = func(m *Machine) (description.Machine, error) {
+		result := description.Machine{
+			Id:           m.Id(),
+			Series:       m.Series(),
+			Nonce:        m.doc.Nonce,
+			PasswordHash: m.doc.PasswordHash,
+		}
+		for _, job := range m.Jobs() {
+			result.Jobs = append(result.Jobs, job.String())
+		}
+		if ctype := m.ContainerType(); ctype != "" && ctype != instance.NONE {
+			result.ContainerType = string(ctype)
+		}
+		cons, err := m.Constraints()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		result.Constraints = cons.String()
+		if instId, err := m.InstanceId(); err == nil {
+			result.InstanceId = string(instId)
+			hwc, err := m.HardwareCharacteristics()
+			if err != nil {
+				return result, errors.Trace(err)
+			}
+			result.HardwareCharacteristics = hwc.String()
+		} else if !errors.IsNotProvisioned(err) {
+			return result, errors.Trace(err)
+		}
+		if result.Annotations, err = st.Annotations(m); err != nil {
+			return result, errors.Trace(err)
+		}
+		for _, child := range children[m.Id()] {
+			container, err := export(child)
+			if err != nil {
+				return result, errors.Trace(err)
+			}
+			result.Containers = append(result.Containers, container)
+		}
+		return result, nil
+	}
+	var result []description.Machine
+	for _, m := range topLevel {
+		machine, err := export(m)
+		if err != nil {
+			return nil, errors.Annotatef(err, "machine %s", m.Id())
+		}
+		result = append(result, machine)
+	}
+	return result, nil
+}
+
+// exportServices returns descriptions of all services and their units,
+// and of the charms they use.
+func (st *State) exportServices() ([]description.Service, []description.Charm, error) {
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	leaders, err := st.serviceLeaders()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var result []description.Service
+	var charms []description.Charm
+	seenCharms := make(map[string]bool)
+	addCharm := func(curl *charm.URL) error {
+		if seenCharms[curl.String()] {
+			return nil
+		}
+		seenCharms[curl.String()] = true
+		ch, err := st.Charm(curl)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		charms = append(charms, description.Charm{
+			URL:          curl.String(),
+			StoragePath:  ch.StoragePath(),
+			BundleSha256: ch.BundleSha256(),
+		})
+		return nil
+	}
+	for _, service := range services {
+		curl, _ := service.CharmURL()
+		if err := addCharm(curl); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		svc := description.Service{
+			Name:     service.Name(),
+			Owner:    service.GetOwnerTag(),
+			CharmURL: curl.String(),
+			Exposed:  service.IsExposed(),
+		}
+		cons, err := service.Constraints()
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		svc.Constraints = cons.String()
+		settings, err := service.ConfigSettings()
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if len(settings) > 0 {
+			svc.Settings = settings
+		}
+		storageCons, err := service.StorageConstraints()
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		for name, sc := range storageCons {
+			if svc.StorageConstraints == nil {
+				svc.StorageConstraints = make(map[string]description.StorageConstraints)
+			}
+			svc.StorageConstraints[name] = description.StorageConstraints{
+				Pool:  sc.Pool,
+				Size:  sc.Size,
+				Count: sc.Count,
+			}
+		}
+		if leader, ok := leaders[service.Name()]; ok {
+			svc.Leader = leader
+			leaderSettings, err := st.ReadLeadershipSettings(service.Name())
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			if values := leaderSettings.Map(); len(values) > 0 {
+				svc.LeaderSettings = values
+			}
+		}
+		if svc.Annotations, err = st.Annotations(service); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		for _, unit := range units {
+			u := description.Unit{
+				Name:         unit.Name(),
+				PasswordHash: unit.doc.PasswordHash,
+			}
+			if principal, ok := unit.PrincipalName(); ok {
+				u.Principal = principal
+			} else if machineId, err := unit.AssignedMachineId(); err == nil {
+				u.Machine = machineId
+			} else if !errors.IsNotAssigned(err) {
+				return nil, nil, errors.Trace(err)
+			}
+			if unitCurl, ok := unit.CharmURL(); ok {
+				// The unit may still be running the charm the
+				// service used before an upgrade.
+				if err := addCharm(unitCurl); err != nil {
+					return nil, nil, errors.Trace(err)
+				}
+				u.CharmURL = unitCurl.String()
+			}
+			ports, err := unit.OpenedPorts()
+			if err != nil && !errors.IsNotAssigned(err) {
+				return nil, nil, errors.Trace(err)
+			}
+			for _, port := range ports {
+				u.OpenedPorts = append(u.OpenedPorts, description.PortRange{
+					FromPort: port.FromPort,
+					ToPort:   port.ToPort,
+					Protocol: port.Protocol,
+				})
+			}
+			if u.Annotations, err = st.Annotations(unit); err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			svc.Units = append(svc.Units, u)
+		}
+		result = append(result, svc)
+	}
+	return result, charms, nil
+}
+
+// Import recreates the given model in the environment, which must be
+// newly created. The charms used by the model's services must already
+// have been added to the environment. Machines are added unprovisioned,
+// and units are assigned to the machines corresponding to those they
+// were assigned to in the described model; subordinate units are
+// recreated when their principals enter the scope of their relations,
+// and storage instances when units are added. State server machines
+// are not imported.
+func (st *State) Import(model *description.Model) error {
+	return st.importModel(model, false)
+}
+
+// ImportMigration recreates the given model in the environment, which
+// must be newly created with the UUID of the described environment, as
+// part of migrating the environment from another system. Unlike Import,
+// machine ids and unit names are preserved, machines are marked as
+// provisioned on their existing instances, subordinate units are
+// recreated directly, and agent password hashes are restored, so that
+// the environment's agents can log in to this system. The units'
+// charm URLs, opened ports and relation scopes, the services'
+// leadership, the units' storage with its provisioned volumes and
+// filesystems, and the id sequences are restored too, so that the
+// agents find the environment as they left it. The environment's
+// workers are not run until it is activated with
+// ActivateImportedEnvironment.
+func (st *State) ImportMigration(model *description.Model) error {
+	if model.UUID != st.EnvironUUID() {
+		return errors.Errorf("cannot import environment %q into environment %q", model.UUID, st.EnvironUUID())
+	}
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     st.EnvironUUID(),
+		Assert: isEnvAliveDoc,
+		Update: bson.D{{"$set", bson.D{{"imported", true}, {"importing", true}}}},
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.New("environment is no longer alive")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return st.importModel(model, true)
+}
+
+func (st *State) importModel(model *description.Model, migration bool) error {
+	env, err := st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if model.Constraints != "" {
+		cons, err := constraints.Parse(model.Constraints)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := st.SetEnvironConstraints(cons); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(model.Annotations) > 0 {
+		if err := st.SetAnnotations(env, model.Annotations); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, user := range model.Users {
+		userTag := names.NewUserTag(user.Name)
+		if userTag.Username() == env.Owner().Username() {
+			continue
+		}
+		_, err := st.AddEnvironmentUser(userTag, names.NewUserTag(user.CreatedBy), user.DisplayName)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return errors.Annotatef(err, "cannot import user %q", user.Name)
+		}
+	}
+	imp := &importer{
+		st:         st,
+		migration:  migration,
+		machineIds: make(map[string]string),
+		storage:    make(map[string][]description.StorageInstance),
+	}
+	if migration {
+		for _, si := range model.Storage {
+			owner, err := names.ParseUnitTag(si.Owner)
+			if err != nil {
+				return errors.Errorf("cannot import storage %q owned by %q", si.Id, si.Owner)
+			}
+			imp.storage[owner.Id()] = append(imp.storage[owner.Id()], si)
+		}
+	}
+	for _, m := range sortedMachines(model.Machines) {
+		if err := imp.machine(m, ""); err != nil {
+			return errors.Annotatef(err, "cannot import machine %s", m.Id)
+		}
+	}
+	for _, svc := range model.Services {
+		if err := imp.service(svc); err != nil {
+			return errors.Annotatef(err, "cannot import service %q", svc.Name)
+		}
+	}
+	if migration {
+		for _, svc := range model.Services {
+			if err := imp.subordinateUnits(svc); err != nil {
+				return errors.Annotatef(err, "cannot import service %q", svc.Name)
+			}
+		}
+	}
+	for _, rel := range model.Relations {
+		var eps []Endpoint
+		for _, ep := range rel.Endpoints {
+			service, err := st.Service(ep.Service)
+			if err != nil {
+				return errors.Annotatef(err, "cannot import relation %q", rel.Key)
+			}
+			endpoint, err := service.Endpoint(ep.Name)
+			if err != nil {
+				return errors.Annotatef(err, "cannot import relation %q", rel.Key)
+			}
+			eps = append(eps, endpoint)
+		}
+		if migration {
+			// Relation ids are allocated from a sequence too.
+			if err := st.setSequence("relation", rel.Id); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		relation, err := st.AddRelation(eps...)
+		if err != nil {
+			return errors.Annotatef(err, "cannot import relation %q", rel.Key)
+		}
+		if migration {
+			if relation.Id() != rel.Id {
+				return errors.Errorf("relation %q imported with id %d, not %d", rel.Key, relation.Id(), rel.Id)
+			}
+			if err := imp.relationUnits(relation, rel); err != nil {
+				return errors.Annotatef(err, "cannot import relation %q", rel.Key)
+			}
+		}
+	}
+	if migration {
+		for _, svc := range model.Services {
+			if err := imp.leadership(svc); err != nil {
+				return errors.Annotatef(err, "cannot import service %q", svc.Name)
+			}
+		}
+		// The sequences are restored last, since importing
+		// the model sets and advances them along the way.
+		for name, value := range model.Sequences {
+			if err := st.setSequence(name, value); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+// importer holds the state of an import in progress.
+type importer struct {
+	st *State
+
+	// migration holds whether the identities of machines and units
+	// are to be preserved, as described by ImportMigration.
+	migration bool
+
+	// machineIds maps the ids of the described machines to the ids
+	// of the imported machines.
+	machineIds map[string]string
+
+	// storage maps the names of the described units to the storage
+	// instances they own, when importing a migration.
+	storage map[string][]description.StorageInstance
+}
+
+// machine adds the described machine, inside the machine with the
+// given parent id if it is a container, followed by its containers.
+func (imp *importer) machine(m description.Machine, parentId string) error {
+	st := imp.st
+	template := MachineTemplate{Series: m.Series}
+	for _, jobName := range m.Jobs {
+		job, err := machineJobFromString(jobName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if job == JobManageEnviron {
+			// State server machines belong to the system, not
+			// the environment, so they are not imported; nor
+			// can their containers be, without a parent.
+			if len(m.Containers) > 0 {
+				return errors.Errorf("cannot import containers of state server machine %s", m.Id)
+			}
+			logger.Infof("not importing state server machine %s", m.Id)
+			return nil
+		}
+		template.Jobs = append(template.Jobs, job)
+	}
+	if m.Constraints != "" {
+		cons, err := constraints.Parse(m.Constraints)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		template.Constraints = cons
+	}
+	if imp.migration {
+		// Machine ids are allocated from sequences, so setting
+		// the sequence preserves the described machine's id.
+		seqName, seq, err := machineSequence(m, parentId)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := st.setSequence(seqName, seq); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	var machine *Machine
+	var err error
+	if parentId == "" {
+		machine, err = st.AddOneMachine(template)
+	} else {
+		machine, err = st.AddMachineInsideMachine(template, parentId, instance.ContainerType(m.ContainerType))
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	imp.machineIds[m.Id] = machine.Id()
+	if imp.migration {
+		if machine.Id() != m.Id {
+			return errors.Errorf("machine %s imported as machine %s", m.Id, machine.Id())
+		}
+		if m.InstanceId != "" {
+			var hwc *instance.HardwareCharacteristics
+			if m.HardwareCharacteristics != "" {
+				parsed, err := instance.ParseHardware(m.HardwareCharacteristics)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				hwc = &parsed
+			}
+			if err := machine.SetProvisioned(instance.Id(m.InstanceId), m.Nonce, hwc); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if m.PasswordHash != "" {
+			if err := machine.setPasswordHash(m.PasswordHash); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	if len(m.Annotations) > 0 {
+		if err := st.SetAnnotations(machine, m.Annotations); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, container := range sortedMachines(m.Containers) {
+		if err := imp.machine(container, machine.Id()); err != nil {
+			return errors.Annotatef(err, "container %s", container.Id)
+		}
+	}
+	return nil
+}
+
+// service adds the described service and its principal units,
+// assigning them to the imported machines.
+func (imp *importer) service(svc description.Service) error {
+	st := imp.st
+	curl, err := charm.ParseURL(svc.CharmURL)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return errors.Annotatef(err, "charm %q not available", svc.CharmURL)
+	}
+	var storageCons map[string]StorageConstraints
+	for name, sc := range svc.StorageConstraints {
+		if storageCons == nil {
+			storageCons = make(map[string]StorageConstraints)
+		}
+		storageCons[name] = StorageConstraints{
+			Pool:  sc.Pool,
+			Size:  sc.Size,
+			Count: sc.Count,
+		}
+	}
+	service, err := st.AddService(svc.Name, svc.Owner, ch, nil, storageCons)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(svc.Settings) > 0 {
+		if err := service.UpdateConfigSettings(charm.Settings(svc.Settings)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if svc.Constraints != "" {
+		cons, err := constraints.Parse(svc.Constraints)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := service.SetConstraints(cons); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if svc.Exposed {
+		if err := service.SetExposed(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(svc.Annotations) > 0 {
+		if err := st.SetAnnotations(service, svc.Annotations); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, u := range sortedUnits(svc.Units) {
+		if u.Principal != "" {
+			continue
+		}
+		if err := imp.preserveUnitName(service, u); err != nil {
+			return errors.Trace(err)
+		}
+		unit, err := imp.addUnit(service, curl)
+		if err != nil {
+			return errors.Annotatef(err, "cannot import unit %q", u.Name)
+		}
+		if machineId, ok := imp.machineIds[u.Machine]; ok {
+			machine, err := st.Machine(machineId)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if err := unit.AssignToMachine(machine); err != nil {
+				return errors.Annotatef(err, "cannot import unit %q", u.Name)
+			}
+		} else if u.Machine != "" {
+			logger.Warningf("unit %q not assigned: machine %s was not imported", u.Name, u.Machine)
+		}
+		if imp.migration {
+			if err := imp.unitStorageInfo(unit); err != nil {
+				return errors.Annotatef(err, "cannot import unit %q", u.Name)
+			}
+		}
+		if err := imp.unitCommon(unit, u); err != nil {
+			return errors.Annotatef(err, "cannot import unit %q", u.Name)
+		}
+	}
+	return nil
+}
+
+// subordinateUnits adds the described service's subordinate units to
+// their principals. It is only used by migrations; otherwise
+// subordinate units are created as their principals enter relation
+// scopes.
+func (imp *importer) subordinateUnits(svc description.Service) error {
+	service, err := imp.st.Service(svc.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, u := range sortedUnits(svc.Units) {
+		if u.Principal == "" {
+			continue
+		}
+		if len(imp.storage[u.Name]) > 0 {
+			return errors.Errorf("cannot import storage of subordinate unit %q", u.Name)
+		}
+		if err := imp.preserveUnitName(service, u); err != nil {
+			return errors.Trace(err)
+		}
+		name, ops, err := service.addUnitOps(u.Principal, nil)
+		if err != nil {
+			return errors.Annotatef(err, "cannot import unit %q", u.Name)
+		}
+		if err := imp.st.runTransaction(ops); err != nil {
+			return errors.Annotatef(err, "cannot import unit %q", u.Name)
+		}
+		unit, err := imp.st.Unit(name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := imp.unitCommon(unit, u); err != nil {
+			return errors.Annotatef(err, "cannot import unit %q", u.Name)
+		}
+	}
+	return nil
+}
+
+// preserveUnitName arranges for the next unit added to the service
+// to have the name of the described unit, if importing a migration.
+func (imp *importer) preserveUnitName(service *Service, u description.Unit) error {
+	if !imp.migration {
+		return nil
+	}
+	number, err := unitNumber(u.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return imp.st.setSequence(service.Tag().String(), number)
+}
+
+// unitCommon restores the details common to principal and subordinate
+// units.
+func (imp *importer) unitCommon(unit *Unit, u description.Unit) error {
+	if imp.migration {
+		if unit.Name() != u.Name {
+			return errors.Errorf("imported as unit %q", unit.Name())
+		}
+		if u.PasswordHash != "" {
+			if err := unit.setPasswordHash(u.PasswordHash); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if u.CharmURL != "" {
+			curl, err := charm.ParseURL(u.CharmURL)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if err := unit.SetCharmURL(curl); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		for _, port := range u.OpenedPorts {
+			if err := unit.OpenPorts(port.Protocol, port.FromPort, port.ToPort); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	if len(u.Annotations) > 0 {
+		if err := imp.st.SetAnnotations(unit, u.Annotations); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// addUnit adds a principal unit to the service. When importing a
+// migration, the unit owns the storage instances it owned in the
+// described model, rather than new ones created from the service's
+// storage constraints; this must be so before it is assigned, so
+// that volumes and filesystems are created for them on its machine.
+func (imp *importer) addUnit(service *Service, curl *charm.URL) (*Unit, error) {
+	if !imp.migration {
+		return service.AddUnit()
+	}
+	name, addOps, err := service.addUnitOps("", nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	storageOps, count, err := imp.unitStorageOps(name, curl)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var ops []txn.Op
+	for _, op := range addOps {
+		switch op.C {
+		case storageInstancesC, storageAttachmentsC:
+			continue
+		case unitsC:
+			if doc, ok := op.Insert.(*unitDoc); ok {
+				doc.StorageAttachmentCount = count
+			}
+		}
+		ops = append(ops, op)
+	}
+	ops = append(ops, storageOps...)
+	if err := imp.st.runTransaction(ops); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return imp.st.Unit(name)
+}
+
+// unitStorageOps returns the operations to recreate the described
+// storage instances owned by the named unit, and the number of
+// storage attachments they have.
+func (imp *importer) unitStorageOps(unitName string, curl *charm.URL) ([]txn.Op, int, error) {
+	var ops []txn.Op
+	var count int
+	unitTag := names.NewUnitTag(unitName)
+	for _, si := range imp.storage[unitName] {
+		doc := &storageInstanceDoc{
+			Id:          si.Id,
+			Kind:        storageKindFromString(si.Kind),
+			Owner:       si.Owner,
+			StorageName: si.Name,
+			CharmURL:    curl,
+		}
+		for _, attached := range si.Attachments {
+			// Unit-owned storage can only be attached to
+			// its owner.
+			if attached != unitName {
+				return nil, -1, errors.Errorf("storage %q attached to unit %q", si.Id, attached)
+			}
+			doc.AttachmentCount++
+			ops = append(ops, createStorageAttachmentOp(names.NewStorageTag(si.Id), unitTag))
+		}
+		count += doc.AttachmentCount
+		ops = append(ops, txn.Op{
+			C:      storageInstancesC,
+			Id:     si.Id,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		})
+	}
+	return ops, count, nil
+}
+
+// unitStorageInfo records the provisioned details of the volumes and
+// filesystems of the described storage instances owned by the unit,
+// which were created when the unit was assigned to its machine.
+func (imp *importer) unitStorageInfo(unit *Unit) error {
+	st := imp.st
+	for _, si := range imp.storage[unit.Name()] {
+		storageTag := names.NewStorageTag(si.Id)
+		if v := si.Volume; v != nil {
+			volume, err := st.StorageInstanceVolume(storageTag)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			err = st.SetVolumeInfo(volume.VolumeTag(), VolumeInfo{
+				VolumeId:   v.VolumeId,
+				HardwareId: v.HardwareId,
+				Size:       v.Size,
+				Pool:       v.Pool,
+				Persistent: v.Persistent,
+				Zone:       v.Zone,
+			})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			for _, att := range v.Attachments {
+				err := st.SetVolumeAttachmentInfo(names.NewMachineTag(att.Machine), volume.VolumeTag(), VolumeAttachmentInfo{
+					DeviceName: att.DeviceName,
+					BusAddress: att.BusAddress,
+					ReadOnly:   att.ReadOnly,
+				})
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+		}
+		if f := si.Filesystem; f != nil {
+			filesystem, err := st.StorageInstanceFilesystem(storageTag)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			err = st.SetFilesystemInfo(filesystem.FilesystemTag(), FilesystemInfo{
+				FilesystemId: f.FilesystemId,
+				Size:         f.Size,
+				Pool:         f.Pool,
+			})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			for _, att := range f.Attachments {
+				err := st.SetFilesystemAttachmentInfo(names.NewMachineTag(att.Machine), filesystem.FilesystemTag(), FilesystemAttachmentInfo{
+					MountPoint: att.MountPoint,
+					ReadOnly:   att.ReadOnly,
+				})
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// relationUnits enters the described units into the scopes of the
+// imported relation, with the settings they had published in it.
+func (imp *importer) relationUnits(relation *Relation, rel description.Relation) error {
+	for _, ru := range rel.Units {
+		unit, err := imp.st.Unit(ru.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		relUnit, err := relation.Unit(unit)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := relUnit.EnterScope(ru.Settings); err != nil {
+			return errors.Annotatef(err, "cannot enter unit %q into scope", ru.Name)
+		}
+	}
+	return nil
+}
+
+// importedLeaseDuration is how long the leadership of the described
+// leaders is claimed for when importing a migration, giving their
+// agents time to reconnect and extend it.
+const importedLeaseDuration = 5 * time.Minute
+
+// leadership restores the described service's leader settings, and
+// the leadership of its leader.
+func (imp *importer) leadership(svc description.Service) error {
+	if len(svc.LeaderSettings) > 0 {
+		settings, err := imp.st.ReadLeadershipSettings(svc.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		settings.Update(svc.LeaderSettings)
+		if _, err := settings.Write(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if svc.Leader != "" {
+		err := imp.st.LeadershipClaimer().ClaimLeadership(svc.Name, svc.Leader, importedLeaseDuration)
+		if err != nil {
+			return errors.Annotatef(err, "cannot restore leadership of unit %q", svc.Leader)
+		}
+	}
+	return nil
+}
+
+// machineSequence returns the name of the sequence from which the id
+// of the described machine is allocated, and the sequence value that
+// corresponds to its id.
+func machineSequence(m description.Machine, parentId string) (string, int, error) {
+	seqName := "machine"
+	if parentId != "" {
+		seqName = fmt.Sprintf("machine%s%sContainer", parentId, m.ContainerType)
+	}
+	number, err := idNumber(m.Id)
+	if err != nil {
+		return "", 0, errors.NotValidf("machine id %q", m.Id)
+	}
+	return seqName, number, nil
+}
+
+// unitNumber returns the number of the named unit.
+func unitNumber(unitName string) (int, error) {
+	number, err := idNumber(unitName)
+	if err != nil {
+		return 0, errors.NotValidf("unit name %q", unitName)
+	}
+	return number, nil
+}
+
+// idNumber returns the number following the last slash in a machine
+// id or unit name.
+func idNumber(id string) (int, error) {
+	return strconv.Atoi(id[strings.LastIndex(id, "/")+1:])
+}
+
+// sortedMachines returns the machines ordered by the number at the
+// end of their ids, so that ids can be preserved on import.
+func sortedMachines(machines []description.Machine) []description.Machine {
+	result := make(machinesByNumber, len(machines))
+	copy(result, machines)
+	sort.Sort(result)
+	return result
+}
+
+// sortedUnits returns the units ordered by unit number, so that
+// names can be preserved on import.
+func sortedUnits(units []description.Unit) []description.Unit {
+	result := make(unitsByNumber, len(units))
+	copy(result, units)
+	sort.Sort(result)
+	return result
+}
+
+type machinesByNumber []description.Machine
+
+func (m machinesByNumber) Len() int      { return len(m) }
+func (m machinesByNumber) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m machinesByNumber) Less(i, j int) bool {
+	a, _ := idNumber(m[i].Id)
+	b, _ := idNumber(m[j].Id)
+	return a < b
+}
+
+type unitsByNumber []description.Unit
+
+func (u unitsByNumber) Len() int      { return len(u) }
+func (u unitsByNumber) Swap(i, j int) { u[i], u[j] = u[j], u[i] }
+func (u unitsByNumber) Less(i, j int) bool {
+	a, _ := idNumber(u[i].Name)
+	b, _ := idNumber(u[j].Name)
+	return a < b
+}
+
+// machineJobFromString returns the machine job with the given name.
+func machineJobFromString(name string) (MachineJob, error) {
+	for job, jobName := range jobNames {
+		if string(jobName) == name {
+			return job, nil
+		}
+	}
+	return 0, errors.NotValidf("machine job %q", name)
+}
+
+// storageKindString returns the name of the storage kind used in
+// model descriptions.
+func storageKindString(kind StorageKind) string {
+	switch kind {
+	case StorageKindBlock:
+		return "block"
+	case StorageKindFilesystem:
+		return "filesystem"
+	}
+	return "unknown"
+}
+
+// storageKindFromString returns the storage kind with the given name
+// in model descriptions.
+func storageKindFromString(name string) StorageKind {
+	switch name {
+	case "block":
+		return StorageKindBlock
+	case "filesystem":
+		return StorageKindFilesystem
+	}
+	return StorageKindUnknown
+}
@@ -0,0 +1,302 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/storage/provider"
+	"github.com/juju/juju/storage/provider/registry"
+	"github.com/juju/juju/testing/factory"
+)
+
+type EnvironDescriptionSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&EnvironDescriptionSuite{})
+
+// populate adds a small model to the environment: a machine with a
+// container, two related services with units, and some constraints
+// and annotations.
+func (s *EnvironDescriptionSuite) populate(c *gc.C) {
+	err := s.State.SetEnvironConstraints(constraints.MustParse("mem=4G"))
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetAnnotations(env, map[string]string{"purpose": "testing"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	m0, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, m0.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err = wordpress.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	err = wordpress.SetConstraints(constraints.MustParse("cpu-cores=2"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetAnnotations(wordpress, map[string]string{"gui-x": "10"})
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err := wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(m0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err = mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(container)
+	c.Assert(err, jc.ErrorIsNil)
+
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EnvironDescriptionSuite) TestExport(c *gc.C) {
+	s.populate(c)
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(model.Version, gc.Equals, description.Version)
+	c.Assert(model.UUID, gc.Equals, s.State.EnvironUUID())
+	c.Assert(model.Constraints, gc.Equals, "mem=4096M")
+	c.Assert(model.Annotations, jc.DeepEquals, map[string]string{"purpose": "testing"})
+	c.Assert(model.Config["name"], gc.Equals, "testenv")
+	c.Assert(model.Users, gc.HasLen, 1)
+	c.Assert(model.Users[0].Name, gc.Equals, s.Owner.Username())
+
+	c.Assert(model.Machines, gc.HasLen, 1)
+	c.Assert(model.Machines[0].Id, gc.Equals, "0")
+	c.Assert(model.Machines[0].Jobs, jc.DeepEquals, []string{"JobHostUnits"})
+	c.Assert(model.Machines[0].Containers, gc.HasLen, 1)
+	c.Assert(model.Machines[0].Containers[0].Id, gc.Equals, "0/lxc/0")
+	c.Assert(model.Machines[0].Containers[0].ContainerType, gc.Equals, "lxc")
+
+	c.Assert(model.Charms, gc.HasLen, 2)
+	c.Assert(model.Services, gc.HasLen, 2)
+	services := make(map[string]description.Service)
+	for _, svc := range model.Services {
+		services[svc.Name] = svc
+	}
+	wordpress := services["wordpress"]
+	c.Assert(wordpress.Exposed, jc.IsTrue)
+	c.Assert(wordpress.Constraints, gc.Equals, "cpu-cores=2")
+	c.Assert(wordpress.Annotations, jc.DeepEquals, map[string]string{"gui-x": "10"})
+	c.Assert(wordpress.Units, jc.DeepEquals, []description.Unit{{
+		Name:        "wordpress/0",
+		Machine:     "0",
+		Annotations: map[string]string{},
+	}})
+	c.Assert(services["mysql"].Units[0].Machine, gc.Equals, "0/lxc/0")
+
+	c.Assert(model.Relations, gc.HasLen, 1)
+	c.Assert(model.Relations[0].Key, gc.Equals, "wordpress:db mysql:server")
+	c.Assert(model.Relations[0].Endpoints, gc.HasLen, 2)
+}
+
+func (s *EnvironDescriptionSuite) TestImport(c *gc.C) {
+	s.populate(c)
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.Factory.MakeEnvironment(c, &factory.EnvParams{Owner: s.Owner})
+	defer st.Close()
+	state.AddTestingCharm(c, st, "wordpress")
+	state.AddTestingCharm(c, st, "mysql")
+
+	err = st.Import(model)
+	c.Assert(err, jc.ErrorIsNil)
+
+	imported, err := st.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(imported.Constraints, gc.Equals, model.Constraints)
+	c.Assert(imported.Annotations, jc.DeepEquals, model.Annotations)
+	c.Assert(imported.Machines, jc.DeepEquals, model.Machines)
+	c.Assert(imported.Services, jc.SameContents, model.Services)
+	c.Assert(imported.Relations, jc.DeepEquals, model.Relations)
+}
+
+func (s *EnvironDescriptionSuite) TestImportStateServerContainers(c *gc.C) {
+	m0, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, m0.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	err = st.Import(model)
+	c.Assert(err, gc.ErrorMatches, `cannot import machine 0: cannot import containers of state server machine 0`)
+}
+
+func (s *EnvironDescriptionSuite) TestImportMissingCharm(c *gc.C) {
+	s.populate(c)
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	err = st.Import(model)
+	c.Assert(err, gc.ErrorMatches, `cannot import service "(wordpress|mysql)": charm ".*" not available: .*`)
+}
+
+// populateMigration adds to the model added by populate the details
+// that are only restored by a migration: provisioned machines with
+// agent passwords, unit charm URLs and ports, relation scopes,
+// leadership, provisioned storage, and a gap in the machine ids.
+func (s *EnvironDescriptionSuite) populateMigration(c *gc.C) {
+	s.populate(c)
+	m0, err := s.State.Machine("0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = m0.SetProvisioned("inst-0", "fake-nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m0.SetPassword("machine-password-0")
+	c.Assert(err, jc.ErrorIsNil)
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	curl, _ := wordpress.CharmURL()
+	unit, err := s.State.Unit("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetPassword("unit-password-0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetCharmURL(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.OpenPorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+
+	relation, err := s.State.KeyRelation("wordpress:db mysql:server")
+	c.Assert(err, jc.ErrorIsNil)
+	ru, err := relation.Unit(unit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ru.EnterScope(map[string]interface{}{"host": "wordpress-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	mysqlUnit, err := s.State.Unit("mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	ru, err = relation.Unit(mysqlUnit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.LeadershipClaimer().ClaimLeadership("wordpress", "wordpress/0", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err := s.State.ReadLeadershipSettings("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	settings.Set("secret", "s3kr1t")
+	_, err = settings.Write()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Leave a gap in the machine ids, which must be preserved.
+	m1, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m1.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = m1.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	m2, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = m2.SetProvisioned("inst-2", "fake-nonce-2", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	registry.RegisterEnvironStorageProviders("someprovider", provider.LoopProviderType)
+	storageBlock := s.AddTestingServiceWithStorage(c, "storage-block", s.AddTestingCharm(c, "storage-block"), map[string]state.StorageConstraints{
+		"data": makeStorageCons("loop", 1024, 1),
+	})
+	unit, err = storageBlock.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(m2)
+	c.Assert(err, jc.ErrorIsNil)
+	volume, err := s.State.StorageInstanceVolume(names.NewStorageTag("data/0"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetVolumeInfo(volume.VolumeTag(), state.VolumeInfo{
+		VolumeId: "vol-0",
+		Size:     1024,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetVolumeAttachmentInfo(m2.MachineTag(), volume.VolumeTag(), state.VolumeAttachmentInfo{
+		DeviceName: "loop0",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EnvironDescriptionSuite) TestImportMigration(c *gc.C) {
+	s.populateMigration(c)
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(model.Storage, gc.HasLen, 1)
+	c.Assert(model.Storage[0].Volume, gc.NotNil)
+
+	st := s.Factory.MakeEnvironment(c, &factory.EnvParams{Owner: s.Owner})
+	defer st.Close()
+	state.AddTestingCharm(c, st, "wordpress")
+	state.AddTestingCharm(c, st, "mysql")
+	state.AddTestingCharm(c, st, "storage-block")
+
+	// A migration keeps the environment's UUID; as both environments
+	// are in the same database here, the target's is used instead.
+	model.UUID = st.EnvironUUID()
+	err = st.ImportMigration(model)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The imported environment has the same model as the original,
+	// other than its name and configuration, for the same reason.
+	imported, err := st.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(imported.Name, gc.Not(gc.Equals), model.Name)
+	imported.Name = model.Name
+	imported.Config = model.Config
+	c.Assert(imported, jc.DeepEquals, model)
+
+	m0, err := st.Machine("0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m0.CheckProvisioned("fake-nonce"), jc.IsTrue)
+	c.Assert(m0.PasswordValid("machine-password-0"), jc.IsTrue)
+	_, err = st.Machine("2")
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err := st.Unit("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.PasswordValid("unit-password-0"), jc.IsTrue)
+
+	env, err := st.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Imported(), jc.IsTrue)
+
+	// An aborted migration removes the imported environment without
+	// touching its instances.
+	err = st.RemoveImportedEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.GetEnvironment(st.EnvironTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Machine("0")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EnvironDescriptionSuite) TestImportMigrationWrongEnvironment(c *gc.C) {
+	model, err := s.State.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	err = st.ImportMigration(model)
+	c.Assert(err, gc.ErrorMatches, `cannot import environment ".*" into environment ".*"`)
+}
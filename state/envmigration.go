@@ -0,0 +1,653 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/network"
+)
+
+// MigrationPhase describes the progress of an environment migration.
+type MigrationPhase string
+
+const (
+	// MigrationExport is the initial phase of a migration, in which
+	// the environment's model is exported.
+	MigrationExport MigrationPhase = "EXPORT"
+
+	// MigrationImport is the phase in which the model is imported
+	// into the target system.
+	MigrationImport MigrationPhase = "IMPORT"
+
+	// MigrationRedirect is the phase in which the environment's
+	// agents are told to connect to the target system.
+	MigrationRedirect MigrationPhase = "REDIRECT"
+
+	// MigrationValidation is the phase in which the migration waits
+	// for the environment's agents to connect to the target system.
+	MigrationValidation MigrationPhase = "VALIDATION"
+
+	// MigrationSuccess indicates that the migrated environment is
+	// running on the target system.
+	MigrationSuccess MigrationPhase = "SUCCESS"
+
+	// MigrationDone indicates that the migration has completed, and
+	// the environment is no longer managed by this system.
+	MigrationDone MigrationPhase = "DONE"
+
+	// MigrationAbort indicates that the migration failed, and the
+	// environment is being restored to this system.
+	MigrationAbort MigrationPhase = "ABORT"
+
+	// MigrationAborted indicates that the migration failed, and
+	// the environment has been restored to this system.
+	MigrationAborted MigrationPhase = "ABORTED"
+)
+
+// validMigrationTransitions holds the phases to which a migration in
+// each phase may move.
+var validMigrationTransitions = map[MigrationPhase][]MigrationPhase{
+	MigrationExport:     {MigrationImport, MigrationAbort},
+	MigrationImport:     {MigrationRedirect, MigrationAbort},
+	MigrationRedirect:   {MigrationValidation, MigrationAbort},
+	MigrationValidation: {MigrationSuccess, MigrationAbort},
+	MigrationSuccess:    {MigrationDone},
+	MigrationAbort:      {MigrationAborted},
+}
+
+// CanTransitionTo returns whether a migration in this phase may move
+// to the given phase.
+func (p MigrationPhase) CanTransitionTo(next MigrationPhase) bool {
+	for _, valid := range validMigrationTransitions[p] {
+		if valid == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal returns whether a migration in this phase has finished.
+func (p MigrationPhase) IsTerminal() bool {
+	return p == MigrationDone || p == MigrationAborted
+}
+
+// isRedirected returns whether agents of an environment being migrated
+// in this phase should connect to the target system.
+func (p MigrationPhase) isRedirected() bool {
+	switch p {
+	case MigrationRedirect, MigrationValidation, MigrationSuccess, MigrationDone:
+		return true
+	}
+	return false
+}
+
+// keepsTargetPassword returns whether the target system password is
+// kept while a migration is in this phase. It is only needed to import
+// the environment, so it is discarded once the import is complete.
+func (p MigrationPhase) keepsTargetPassword() bool {
+	return p == MigrationExport || p == MigrationImport
+}
+
+// MigrationTargetInfo holds the details needed to connect to the
+// system an environment is being migrated to.
+type MigrationTargetInfo struct {
+	// Addrs holds the API addresses of the target system.
+	Addrs []string
+
+	// CACert holds the CA certificate of the target system.
+	CACert string
+
+	// AuthTag and Password hold the credentials of the target
+	// system administrator used to import the environment.
+	AuthTag  names.UserTag
+	Password string
+}
+
+// Validate returns an error if the target info is not valid.
+func (info MigrationTargetInfo) Validate() error {
+	if len(info.Addrs) == 0 {
+		return errors.NotValidf("empty Addrs")
+	}
+	if info.CACert == "" {
+		return errors.NotValidf("empty CACert")
+	}
+	if info.AuthTag.Id() == "" {
+		return errors.NotValidf("empty AuthTag")
+	}
+	if info.Password == "" {
+		return errors.NotValidf("empty Password")
+	}
+	return nil
+}
+
+// EnvMigrationSpec holds the arguments for creating an environment
+// migration.
+type EnvMigrationSpec struct {
+	InitiatedBy names.UserTag
+	TargetInfo  MigrationTargetInfo
+}
+
+// envMigrationDoc holds the details of the migration of an
+// environment. There is at most one per environment; it is kept once
+// the migration has finished, and is replaced if a new migration is
+// started after an aborted one.
+type envMigrationDoc struct {
+	EnvUUID          string         `bson:"_id"`
+	InitiatedBy      string         `bson:"initiated-by"`
+	StartTime        time.Time      `bson:"start-time"`
+	Phase            MigrationPhase `bson:"phase"`
+	PhaseChangedTime time.Time      `bson:"phase-changed-time"`
+	StatusMessage    string         `bson:"status-message"`
+	TargetAddrs      []string       `bson:"target-addrs"`
+	TargetCACert     string         `bson:"target-cacert"`
+	TargetAuthTag    string         `bson:"target-entity"`
+	TargetPassword   string         `bson:"target-password"`
+}
+
+// EnvMigration represents the migration of an environment to another
+// system.
+type EnvMigration struct {
+	st  *State
+	doc envMigrationDoc
+}
+
+// EnvironUUID returns the UUID of the environment being migrated.
+func (m *EnvMigration) EnvironUUID() string {
+	return m.doc.EnvUUID
+}
+
+// InitiatedBy returns the user who started the migration.
+func (m *EnvMigration) InitiatedBy() names.UserTag {
+	return names.NewUserTag(m.doc.InitiatedBy)
+}
+
+// StartTime returns the time the migration was started.
+func (m *EnvMigration) StartTime() time.Time {
+	return m.doc.StartTime
+}
+
+// Phase returns the current phase of the migration.
+func (m *EnvMigration) Phase() MigrationPhase {
+	return m.doc.Phase
+}
+
+// PhaseChangedTime returns the time the migration entered its
+// current phase.
+func (m *EnvMigration) PhaseChangedTime() time.Time {
+	return m.doc.PhaseChangedTime
+}
+
+// StatusMessage returns a message describing the progress of the
+// migration, or the reason it was aborted.
+func (m *EnvMigration) StatusMessage() string {
+	return m.doc.StatusMessage
+}
+
+// TargetInfo returns the details needed to connect to the target
+// system. The password is discarded once the environment has been
+// imported into the target system.
+func (m *EnvMigration) TargetInfo() MigrationTargetInfo {
+	return MigrationTargetInfo{
+		Addrs:    m.doc.TargetAddrs,
+		CACert:   m.doc.TargetCACert,
+		AuthTag:  names.NewUserTag(m.doc.TargetAuthTag),
+		Password: m.doc.TargetPassword,
+	}
+}
+
+// Refresh reloads the migration's details from the database.
+func (m *EnvMigration) Refresh() error {
+	migrations, closer := m.st.getCollection(envMigrationsC)
+	defer closer()
+	var doc envMigrationDoc
+	err := migrations.FindId(m.doc.EnvUUID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("migration of environment %q", m.doc.EnvUUID)
+	} else if err != nil {
+		return errors.Annotate(err, "cannot refresh migration")
+	}
+	m.doc = doc
+	return nil
+}
+
+// SetPhase moves the migration to the given phase, which must be a
+// valid next phase for the migration's current phase. Once the
+// environment has been imported, or the migration aborted, the target
+// system password is discarded; when the migration finishes
+// successfully, the environment is marked as migrated, and once it has
+// been aborted, changes to the environment are unblocked.
+func (m *EnvMigration) SetPhase(phase MigrationPhase) error {
+	if m.doc.Phase == phase {
+		return nil
+	}
+	if !m.doc.Phase.CanTransitionTo(phase) {
+		return errors.Errorf("cannot move migration from %s to %s", m.doc.Phase, phase)
+	}
+	now := nowToTheSecond()
+	update := bson.D{
+		{"phase", phase},
+		{"phase-changed-time", now},
+	}
+	if !phase.keepsTargetPassword() {
+		update = append(update, bson.DocElem{"target-password", ""})
+	}
+	ops := []txn.Op{{
+		C:      envMigrationsC,
+		Id:     m.doc.EnvUUID,
+		Assert: bson.D{{"phase", m.doc.Phase}},
+		Update: bson.D{{"$set", update}},
+	}}
+	if phase == MigrationAborted {
+		// The environment is managed by this system again.
+		blockOps, err := removeMigrationBlockOps(m.st)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ops = append(ops, blockOps...)
+	}
+	if phase == MigrationDone {
+		ops = append(ops, txn.Op{
+			C:      environmentsC,
+			Id:     m.doc.EnvUUID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"migrated", true}}}},
+		})
+	}
+	if err := m.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.Errorf("cannot move migration to %s: phase changed concurrently", phase)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot move migration to %s", phase)
+	}
+	m.doc.Phase = phase
+	m.doc.PhaseChangedTime = now
+	if !phase.keepsTargetPassword() {
+		m.doc.TargetPassword = ""
+	}
+	return nil
+}
+
+// SetStatusMessage records a message describing the progress of the
+// migration.
+func (m *EnvMigration) SetStatusMessage(message string) error {
+	ops := []txn.Op{{
+		C:      envMigrationsC,
+		Id:     m.doc.EnvUUID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"status-message", message}}}},
+	}}
+	if err := m.st.runTransaction(ops); err != nil {
+		return errors.Annotate(err, "cannot set migration status message")
+	}
+	m.doc.StatusMessage = message
+	return nil
+}
+
+// CreateEnvMigration starts the migration of the environment to
+// another system. An environment may only have one migration in
+// progress, and may not be migrated again once a migration has
+// succeeded. The state server environment cannot be migrated. Changes
+// to the environment are blocked until the migration is aborted.
+func (st *State) CreateEnvMigration(spec EnvMigrationSpec) (*EnvMigration, error) {
+	if err := spec.TargetInfo.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	ssEnv, err := st.StateServerEnvironment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	envUUID := st.EnvironUUID()
+	if ssEnv.UUID() == envUUID {
+		return nil, errors.New("cannot migrate the state server environment")
+	}
+	now := nowToTheSecond()
+	doc := envMigrationDoc{
+		EnvUUID:          envUUID,
+		InitiatedBy:      spec.InitiatedBy.Username(),
+		StartTime:        now,
+		Phase:            MigrationExport,
+		PhaseChangedTime: now,
+		TargetAddrs:      spec.TargetInfo.Addrs,
+		TargetCACert:     spec.TargetInfo.CACert,
+		TargetAuthTag:    spec.TargetInfo.AuthTag.Username(),
+		TargetPassword:   spec.TargetInfo.Password,
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		env, err := st.Environment()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if env.Life() != Alive {
+			return nil, errors.New("environment is not alive")
+		}
+		if env.Migrated() {
+			return nil, errors.New("environment has already been migrated")
+		}
+		ops := []txn.Op{{
+			C:      environmentsC,
+			Id:     envUUID,
+			Assert: isEnvAliveDoc,
+		}}
+		blockOps, err := addMigrationBlockOps(st)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, blockOps...)
+		existing, err := st.EnvMigration()
+		if errors.IsNotFound(err) {
+			return append(ops, txn.Op{
+				C:      envMigrationsC,
+				Id:     envUUID,
+				Assert: txn.DocMissing,
+				Insert: &doc,
+			}), nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if existing.Phase() != MigrationAborted {
+			return nil, errors.AlreadyExistsf("migration of environment %q", envUUID)
+		}
+		return append(ops, txn.Op{
+			C:      envMigrationsC,
+			Id:     envUUID,
+			Assert: bson.D{{"phase", MigrationAborted}},
+			Update: bson.D{{"$set", bson.D{
+				{"initiated-by", doc.InitiatedBy},
+				{"start-time", doc.StartTime},
+				{"phase", doc.Phase},
+				{"phase-changed-time", doc.PhaseChangedTime},
+				{"status-message", ""},
+				{"target-addrs", doc.TargetAddrs},
+				{"target-cacert", doc.TargetCACert},
+				{"target-entity", doc.TargetAuthTag},
+				{"target-password", doc.TargetPassword},
+			}}},
+		}), nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return nil, errors.Annotate(err, "cannot create environment migration")
+	}
+	return &EnvMigration{st: st, doc: doc}, nil
+}
+
+// migrationBlockMessage is the message of the change block that stops
+// an environment from being changed while it is migrated, since changes
+// made after the environment has been exported would be lost.
+const migrationBlockMessage = "environment is being migrated"
+
+// addMigrationBlockOps returns the operations to block changes to the
+// environment for a migration, unless they are blocked already.
+func addMigrationBlockOps(st *State) ([]txn.Op, error) {
+	_, exists, err := st.GetBlockForType(ChangeBlock)
+	if err != nil || exists {
+		return nil, errors.Trace(err)
+	}
+	return createEnvironmentBlockOps(st, ChangeBlock, migrationBlockMessage)
+}
+
+// removeMigrationBlockOps returns the operations to remove the block
+// added by addMigrationBlockOps, if it is still in place.
+func removeMigrationBlockOps(st *State) ([]txn.Op, error) {
+	block, exists, err := st.GetBlockForType(ChangeBlock)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !exists || block.Message() != migrationBlockMessage {
+		return nil, nil
+	}
+	return []txn.Op{{
+		C:      blocksC,
+		Id:     block.Id(),
+		Remove: true,
+	}}, nil
+}
+
+// EnvMigration returns the latest migration of the environment. It
+// returns an error satisfying errors.IsNotFound if the environment has
+// never been migrated.
+func (st *State) EnvMigration() (*EnvMigration, error) {
+	migrations, closer := st.getCollection(envMigrationsC)
+	defer closer()
+	var doc envMigrationDoc
+	err := migrations.FindId(st.EnvironUUID()).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("migration of environment %q", st.EnvironUUID())
+	} else if err != nil {
+		return nil, errors.Annotate(err, "cannot get environment migration")
+	}
+	return &EnvMigration{st: st, doc: doc}, nil
+}
+
+// WatchEnvMigration returns a watcher that notifies when the
+// environment's migration is created or changes phase.
+func (st *State) WatchEnvMigration() NotifyWatcher {
+	return newEntityWatcher(st, envMigrationsC, st.EnvironUUID())
+}
+
+// agentRedirection returns the API addresses and CA certificate of
+// the system the environment's agents should connect to instead of
+// this one, if any. Agents are redirected to the target system of a
+// migration from this system, and an imported environment's agents
+// are sent back to the system it was migrated from if the migration
+// is aborted.
+func (st *State) agentRedirection() (addrs []string, caCert string, err error) {
+	migration, err := st.EnvMigration()
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, "", errors.Trace(err)
+	}
+	if err == nil && migration.Phase().isRedirected() {
+		target := migration.TargetInfo()
+		return target.Addrs, target.CACert, nil
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	return env.doc.ReturnAddrs, env.doc.ReturnCACert, nil
+}
+
+// AgentAPIHostPorts returns the API addresses that the environment's
+// agents should connect to. These are the addresses of this system,
+// unless the environment's agents have been redirected to another
+// system by a migration.
+func (st *State) AgentAPIHostPorts() ([][]network.HostPort, error) {
+	addrs, _, err := st.agentRedirection()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(addrs) == 0 {
+		return st.APIHostPorts()
+	}
+	var servers [][]network.HostPort
+	for _, addr := range addrs {
+		hostPorts, err := network.ParseHostPorts(addr)
+		if err != nil {
+			return nil, errors.Annotate(err, "invalid migration address")
+		}
+		servers = append(servers, hostPorts)
+	}
+	return servers, nil
+}
+
+// AgentCACert returns the CA certificate that the environment's agents
+// should use to verify the API servers returned by AgentAPIHostPorts.
+func (st *State) AgentCACert() (string, error) {
+	addrs, caCert, err := st.agentRedirection()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(addrs) == 0 {
+		return st.CACert(), nil
+	}
+	return caCert, nil
+}
+
+// WatchAgentAPIHostPorts returns a watcher that notifies when the
+// addresses returned by AgentAPIHostPorts, or the certificate returned
+// by AgentCACert, may have changed.
+func (st *State) WatchAgentAPIHostPorts() NotifyWatcher {
+	return newDocWatcher(st, []docKey{
+		{stateServersC, apiHostPortsKey},
+		{envMigrationsC, st.EnvironUUID()},
+		{environmentsC, st.EnvironUUID()},
+	})
+}
+
+// MissingAgents returns the tags of the environment's agents that
+// should be running but are not connected to this system. Only the
+// agents of machines that have been provisioned, and of units assigned
+// to them, are expected to be running. It is used to check that the
+// agents of a migrated environment have connected to the target
+// system, or have returned to the source system after the migration
+// was aborted.
+func (st *State) MissingAgents() ([]string, error) {
+	missing := []string{}
+	provisioned := make(map[string]bool)
+	machines, err := st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, machine := range machines {
+		if machine.Life() == Dead {
+			continue
+		}
+		if _, err := machine.InstanceId(); errors.IsNotProvisioned(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		provisioned[machine.Id()] = true
+		alive, err := machine.AgentPresence()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !alive {
+			missing = append(missing, machine.Tag().String())
+		}
+	}
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, service := range services {
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, unit := range units {
+			if unit.Life() == Dead {
+				continue
+			}
+			machineId, err := unit.AssignedMachineId()
+			if errors.IsNotAssigned(err) {
+				continue
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if !provisioned[machineId] {
+				continue
+			}
+			alive, err := unit.AgentPresence()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if !alive {
+				missing = append(missing, unit.Tag().String())
+			}
+		}
+	}
+	return missing, nil
+}
+
+// ReturnImportedEnvironmentAgents sends the agents of the environment,
+// which must have been created by importing a migrated environment,
+// back to the system it was migrated from, after the migration has
+// been aborted. The agents are given the API addresses and CA
+// certificate of that system through their API address updaters.
+func (st *State) ReturnImportedEnvironmentAgents(addrs []string, caCert string) error {
+	if len(addrs) == 0 {
+		return errors.NotValidf("empty addrs")
+	}
+	if caCert == "" {
+		return errors.NotValidf("empty CA certificate")
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !env.Imported() {
+		return errors.Errorf("environment %q was not imported by a migration", env.UUID())
+	}
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     st.EnvironUUID(),
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"return-addrs", addrs},
+			{"return-cacert", caCert},
+		}}},
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return errors.Annotate(err, "cannot return agents of imported environment")
+	}
+	return nil
+}
+
+// ActivateImportedEnvironment marks the environment, which must have
+// been created by importing a migrated environment, as managed by this
+// system once the migration has succeeded, so that its workers are run.
+func (st *State) ActivateImportedEnvironment() error {
+	env, err := st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !env.Imported() {
+		return errors.Errorf("environment %q was not imported by a migration", env.UUID())
+	}
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     st.EnvironUUID(),
+		Assert: isEnvAliveDoc,
+		Update: bson.D{{"$unset", bson.D{{"importing", nil}}}},
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.New("cannot activate imported environment: environment is no longer alive")
+	} else if err != nil {
+		return errors.Annotate(err, "cannot activate imported environment")
+	}
+	return nil
+}
+
+// RemoveImportedEnvironment removes the environment, which must have
+// been created by importing a migrated environment, after the migration
+// has been aborted. Unlike destroying the environment, this leaves its
+// machines' instances and other provider resources alone, since they
+// are still in use by the system the environment was migrated from.
+func (st *State) RemoveImportedEnvironment() error {
+	env, err := st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !env.Imported() {
+		return errors.Errorf("environment %q was not imported by a migration", env.UUID())
+	}
+	if env.Life() == Alive {
+		ops := []txn.Op{{
+			C:      environmentsC,
+			Id:     st.EnvironUUID(),
+			Assert: isEnvAliveDoc,
+			Update: bson.D{{"$set", bson.D{{"life", Dying}}}},
+		}}
+		if err := st.runTransaction(ops); err != nil && err != txn.ErrAborted {
+			return errors.Annotate(err, "cannot remove imported environment")
+		}
+	}
+	return errors.Annotate(st.RemoveAllEnvironDocs(), "cannot remove imported environment")
+}
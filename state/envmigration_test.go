@@ -0,0 +1,376 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type EnvMigrationSuite struct {
+	ConnSuite
+	st   *state.State
+	spec state.EnvMigrationSpec
+}
+
+var _ = gc.Suite(&EnvMigrationSuite{})
+
+func (s *EnvMigrationSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.st = s.Factory.MakeEnvironment(c, nil)
+	s.AddCleanup(func(*gc.C) { s.st.Close() })
+	s.spec = state.EnvMigrationSpec{
+		InitiatedBy: names.NewUserTag("admin"),
+		TargetInfo: state.MigrationTargetInfo{
+			Addrs:    []string{"10.0.0.1:17070", "10.0.0.2:17070"},
+			CACert:   coretesting.CACert,
+			AuthTag:  names.NewUserTag("admin"),
+			Password: "secret",
+		},
+	}
+}
+
+func (s *EnvMigrationSuite) TestCreate(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.EnvironUUID(), gc.Equals, s.st.EnvironUUID())
+	c.Assert(migration.InitiatedBy(), gc.Equals, names.NewUserTag("admin"))
+	c.Assert(migration.Phase(), gc.Equals, state.MigrationExport)
+	c.Assert(migration.TargetInfo(), jc.DeepEquals, s.spec.TargetInfo)
+
+	loaded, err := s.st.EnvMigration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(loaded.Phase(), gc.Equals, state.MigrationExport)
+	c.Assert(loaded.TargetInfo(), jc.DeepEquals, s.spec.TargetInfo)
+}
+
+func (s *EnvMigrationSuite) TestCreateInvalidSpec(c *gc.C) {
+	s.spec.TargetInfo.Addrs = nil
+	_, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, gc.ErrorMatches, "empty Addrs not valid")
+}
+
+func (s *EnvMigrationSuite) TestCreateStateServerEnvironment(c *gc.C) {
+	_, err := s.State.CreateEnvMigration(s.spec)
+	c.Assert(err, gc.ErrorMatches, "cannot migrate the state server environment")
+}
+
+func (s *EnvMigrationSuite) TestCreateWhileInProgress(c *gc.C) {
+	_, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *EnvMigrationSuite) TestCreateAfterAbort(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.SetPhase(state.MigrationAbort), jc.ErrorIsNil)
+	c.Assert(migration.SetPhase(state.MigrationAborted), jc.ErrorIsNil)
+	c.Assert(migration.TargetInfo().Password, gc.Equals, "")
+
+	migration, err = s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.Phase(), gc.Equals, state.MigrationExport)
+	c.Assert(migration.Refresh(), jc.ErrorIsNil)
+	c.Assert(migration.TargetInfo().Password, gc.Equals, "secret")
+}
+
+func (s *EnvMigrationSuite) TestCreateBlocksChanges(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	block, found, err := s.st.GetBlockForType(state.ChangeBlock)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+	c.Assert(block.Message(), gc.Equals, "environment is being migrated")
+
+	c.Assert(migration.SetPhase(state.MigrationAbort), jc.ErrorIsNil)
+	_, found, err = s.st.GetBlockForType(state.ChangeBlock)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+
+	c.Assert(migration.SetPhase(state.MigrationAborted), jc.ErrorIsNil)
+	_, found, err = s.st.GetBlockForType(state.ChangeBlock)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsFalse)
+}
+
+func (s *EnvMigrationSuite) TestCreateKeepsExistingBlock(c *gc.C) {
+	err := s.st.SwitchBlockOn(state.ChangeBlock, "frozen")
+	c.Assert(err, jc.ErrorIsNil)
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.SetPhase(state.MigrationAbort), jc.ErrorIsNil)
+	c.Assert(migration.SetPhase(state.MigrationAborted), jc.ErrorIsNil)
+
+	block, found, err := s.st.GetBlockForType(state.ChangeBlock)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+	c.Assert(block.Message(), gc.Equals, "frozen")
+}
+
+func (s *EnvMigrationSuite) TestNotFound(c *gc.C) {
+	_, err := s.st.EnvMigration()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EnvMigrationSuite) TestSetPhase(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, phase := range []state.MigrationPhase{
+		state.MigrationImport,
+		state.MigrationRedirect,
+		state.MigrationValidation,
+		state.MigrationSuccess,
+		state.MigrationDone,
+	} {
+		c.Assert(migration.SetPhase(phase), jc.ErrorIsNil)
+		c.Assert(migration.Refresh(), jc.ErrorIsNil)
+		c.Assert(migration.Phase(), gc.Equals, phase)
+	}
+	env, err := s.st.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Migrated(), jc.IsTrue)
+
+	_, err = s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, gc.ErrorMatches, "cannot create environment migration: environment has already been migrated")
+}
+
+func (s *EnvMigrationSuite) TestSetPhaseDiscardsPasswordAfterImport(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.SetPhase(state.MigrationImport), jc.ErrorIsNil)
+	c.Assert(migration.Refresh(), jc.ErrorIsNil)
+	c.Assert(migration.TargetInfo().Password, gc.Equals, "secret")
+
+	c.Assert(migration.SetPhase(state.MigrationRedirect), jc.ErrorIsNil)
+	c.Assert(migration.TargetInfo().Password, gc.Equals, "")
+	c.Assert(migration.Refresh(), jc.ErrorIsNil)
+	c.Assert(migration.TargetInfo().Password, gc.Equals, "")
+	c.Assert(migration.TargetInfo().CACert, gc.Equals, s.spec.TargetInfo.CACert)
+}
+
+func (s *EnvMigrationSuite) TestSetPhaseInvalid(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	err = migration.SetPhase(state.MigrationDone)
+	c.Assert(err, gc.ErrorMatches, "cannot move migration from EXPORT to DONE")
+}
+
+func (s *EnvMigrationSuite) TestSetPhaseConcurrentChange(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	other, err := s.st.EnvMigration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(other.SetPhase(state.MigrationAbort), jc.ErrorIsNil)
+
+	err = migration.SetPhase(state.MigrationImport)
+	c.Assert(err, gc.ErrorMatches, "cannot move migration to IMPORT: phase changed concurrently")
+}
+
+func (s *EnvMigrationSuite) TestStatusMessage(c *gc.C) {
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.SetStatusMessage("exporting"), jc.ErrorIsNil)
+	c.Assert(migration.Refresh(), jc.ErrorIsNil)
+	c.Assert(migration.StatusMessage(), gc.Equals, "exporting")
+}
+
+func (s *EnvMigrationSuite) TestAgentAPIHostPorts(c *gc.C) {
+	local := [][]network.HostPort{network.NewHostPorts(1234, "0.1.2.3")}
+	err := s.State.SetAPIHostPorts(local)
+	c.Assert(err, jc.ErrorIsNil)
+
+	hostPorts, err := s.st.AgentAPIHostPorts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hostPorts, jc.DeepEquals, local)
+
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.SetPhase(state.MigrationImport), jc.ErrorIsNil)
+	hostPorts, err = s.st.AgentAPIHostPorts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hostPorts, jc.DeepEquals, local)
+
+	c.Assert(migration.SetPhase(state.MigrationRedirect), jc.ErrorIsNil)
+	hostPorts, err = s.st.AgentAPIHostPorts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hostPorts, jc.DeepEquals, [][]network.HostPort{
+		network.NewHostPorts(17070, "10.0.0.1"),
+		network.NewHostPorts(17070, "10.0.0.2"),
+	})
+
+	c.Assert(migration.SetPhase(state.MigrationAbort), jc.ErrorIsNil)
+	hostPorts, err = s.st.AgentAPIHostPorts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hostPorts, jc.DeepEquals, local)
+}
+
+func (s *EnvMigrationSuite) TestAgentCACert(c *gc.C) {
+	cert, err := s.st.AgentCACert()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cert, gc.Equals, s.st.CACert())
+
+	// Use a different certificate for the target, so that it can
+	// be told apart from this system's.
+	s.spec.TargetInfo.CACert = "target-cert"
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(migration.SetPhase(state.MigrationImport), jc.ErrorIsNil)
+	cert, err = s.st.AgentCACert()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cert, gc.Equals, s.st.CACert())
+
+	c.Assert(migration.SetPhase(state.MigrationRedirect), jc.ErrorIsNil)
+	cert, err = s.st.AgentCACert()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cert, gc.Equals, "target-cert")
+
+	c.Assert(migration.SetPhase(state.MigrationAbort), jc.ErrorIsNil)
+	cert, err = s.st.AgentCACert()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cert, gc.Equals, s.st.CACert())
+}
+
+func (s *EnvMigrationSuite) TestWatchEnvMigration(c *gc.C) {
+	w := s.st.WatchEnvMigration()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.st, w)
+	wc.AssertOneChange()
+
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	c.Assert(migration.SetPhase(state.MigrationImport), jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *EnvMigrationSuite) TestWatchAgentAPIHostPorts(c *gc.C) {
+	w := s.st.WatchAgentAPIHostPorts()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.st, w)
+	wc.AssertOneChange()
+
+	err := s.State.SetAPIHostPorts([][]network.HostPort{network.NewHostPorts(1234, "0.1.2.3")})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+	c.Assert(migration.SetPhase(state.MigrationImport), jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *EnvMigrationSuite) TestWatchEnvMigrations(c *gc.C) {
+	w := s.State.WatchEnvMigrations()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	migration, err := s.st.CreateEnvMigration(s.spec)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(s.st.EnvironUUID())
+	wc.AssertNoChange()
+
+	c.Assert(migration.SetPhase(state.MigrationImport), jc.ErrorIsNil)
+	wc.AssertChange(s.st.EnvironUUID())
+	wc.AssertNoChange()
+}
+
+func (s *EnvMigrationSuite) TestWatchImportedEnvironments(c *gc.C) {
+	w := s.State.WatchImportedEnvironments()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange()
+	wc.AssertNoChange()
+
+	s.importEmpty(c)
+	wc.AssertChange(s.st.EnvironUUID())
+	wc.AssertNoChange()
+
+	err := s.st.ActivateImportedEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertChange(s.st.EnvironUUID())
+	wc.AssertNoChange()
+}
+
+func (s *EnvMigrationSuite) TestActivateImportedEnvironment(c *gc.C) {
+	s.importEmpty(c)
+	env, err := s.st.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Importing(), jc.IsTrue)
+
+	err = s.st.ActivateImportedEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Refresh(), jc.ErrorIsNil)
+	c.Assert(env.Importing(), jc.IsFalse)
+	c.Assert(env.Imported(), jc.IsTrue)
+}
+
+func (s *EnvMigrationSuite) TestActivateImportedEnvironmentNotImported(c *gc.C) {
+	err := s.st.ActivateImportedEnvironment()
+	c.Assert(err, gc.ErrorMatches, `environment ".*" was not imported by a migration`)
+}
+
+func (s *EnvMigrationSuite) TestRemoveImportedEnvironmentNotImported(c *gc.C) {
+	err := s.st.RemoveImportedEnvironment()
+	c.Assert(err, gc.ErrorMatches, `environment ".*" was not imported by a migration`)
+}
+
+func (s *EnvMigrationSuite) importEmpty(c *gc.C) {
+	err := s.st.ImportMigration(&description.Model{
+		Version: description.Version,
+		UUID:    s.st.EnvironUUID(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EnvMigrationSuite) TestReturnImportedEnvironmentAgents(c *gc.C) {
+	local := [][]network.HostPort{network.NewHostPorts(1234, "0.1.2.3")}
+	err := s.State.SetAPIHostPorts(local)
+	c.Assert(err, jc.ErrorIsNil)
+	s.importEmpty(c)
+
+	w := s.st.WatchAgentAPIHostPorts()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewNotifyWatcherC(c, s.st, w)
+	wc.AssertOneChange()
+
+	err = s.st.ReturnImportedEnvironmentAgents([]string{"10.0.0.9:17070"}, "source-cert")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	hostPorts, err := s.st.AgentAPIHostPorts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hostPorts, jc.DeepEquals, [][]network.HostPort{
+		network.NewHostPorts(17070, "10.0.0.9"),
+	})
+	cert, err := s.st.AgentCACert()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cert, gc.Equals, "source-cert")
+}
+
+func (s *EnvMigrationSuite) TestReturnImportedEnvironmentAgentsNotImported(c *gc.C) {
+	err := s.st.ReturnImportedEnvironmentAgents([]string{"10.0.0.9:17070"}, "source-cert")
+	c.Assert(err, gc.ErrorMatches, `environment ".*" was not imported by a migration`)
+}
+
+func (s *EnvMigrationSuite) TestReturnImportedEnvironmentAgentsInvalid(c *gc.C) {
+	s.importEmpty(c)
+	err := s.st.ReturnImportedEnvironmentAgents(nil, "source-cert")
+	c.Assert(err, gc.ErrorMatches, "empty addrs not valid")
+	err = s.st.ReturnImportedEnvironmentAgents([]string{"10.0.0.9:17070"}, "")
+	c.Assert(err, gc.ErrorMatches, "empty CA certificate not valid")
+}
@@ -38,6 +38,11 @@ type envUserDoc struct {
 	// It is really informational only as far as everyone except the
 	// api server is concerned.
 	LastConnection *time.Time `bson:"lastconnection"`
+
+	// AccessGroup is set when the user was added because they belong
+	// to one of the environment's external access groups, rather
+	// than by another user.
+	AccessGroup bool `bson:"accessgroup,omitempty"`
 }
 
 // ID returns the ID of the environment user.
@@ -70,6 +75,13 @@ func (e *EnvironmentUser) CreatedBy() string {
 	return e.doc.CreatedBy
 }
 
+// AddedForAccessGroup returns whether the environment user was added
+// because they belong to one of the environment's external access
+// groups.
+func (e *EnvironmentUser) AddedForAccessGroup() bool {
+	return e.doc.AccessGroup
+}
+
 // DateCreated returns the date the environment user was created in UTC.
 func (e *EnvironmentUser) DateCreated() time.Time {
 	return e.doc.DateCreated.UTC()
@@ -131,6 +143,22 @@ func (st *State) EnvironmentUser(user names.UserTag) (*EnvironmentUser, error) {
 
 // AddEnvironmentUser adds a new user to the database.
 func (st *State) AddEnvironmentUser(user, createdBy names.UserTag, displayName string) (*EnvironmentUser, error) {
+	return st.addEnvironmentUser(user, createdBy, displayName, false)
+}
+
+// AddAccessGroupEnvironmentUser adds a new external user to the
+// database, on behalf of createdBy, because they belong to one of the
+// environment's external access groups. Such users may be removed
+// with RemoveAccessGroupEnvironmentUser once they no longer belong
+// to one.
+func (st *State) AddAccessGroupEnvironmentUser(user, createdBy names.UserTag, displayName string) (*EnvironmentUser, error) {
+	if user.IsLocal() {
+		return nil, errors.NotValidf("local user %q in access group", user.Name())
+	}
+	return st.addEnvironmentUser(user, createdBy, displayName, true)
+}
+
+func (st *State) addEnvironmentUser(user, createdBy names.UserTag, displayName string, accessGroup bool) (*EnvironmentUser, error) {
 	// Ensure local user exists in state before adding them as an environment user.
 	if user.IsLocal() {
 		localUser, err := st.User(user)
@@ -151,6 +179,7 @@ func (st *State) AddEnvironmentUser(user, createdBy names.UserTag, displayName s
 
 	envuuid := st.EnvironUUID()
 	op, doc := createEnvUserOpAndDoc(envuuid, user, createdBy, displayName)
+	doc.AccessGroup = accessGroup
 	err := st.runTransaction([]txn.Op{op})
 	if err == txn.ErrAborted {
 		err = errors.AlreadyExistsf("environment user %q", user.Username())
@@ -204,6 +233,23 @@ func (st *State) RemoveEnvironmentUser(user names.UserTag) error {
 	return nil
 }
 
+// RemoveAccessGroupEnvironmentUser removes an environment user that
+// was added by AddAccessGroupEnvironmentUser. An error satisfying
+// errors.IsNotFound is returned if there is no such user.
+func (st *State) RemoveAccessGroupEnvironmentUser(user names.UserTag) error {
+	ops := []txn.Op{{
+		C:      envUsersC,
+		Id:     envUserID(user),
+		Assert: bson.D{{"accessgroup", true}},
+		Remove: true,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.NotFoundf("access group env user %q", user.Username())
+	}
+	return errors.Trace(err)
+}
+
 // UserEnvironment contains information about an environment that a
 // user has access to, along with the last time the user has connected
 // to the environment.
@@ -213,12 +259,11 @@ type UserEnvironment struct {
 }
 
 // EnvironmentsForUser returns a list of enviroments that the user
-// is able to access.
+// is able to access, either as an environment user or as a member of
+// a group that the environment has been shared with.
 func (st *State) EnvironmentsForUser(user names.UserTag) ([]*UserEnvironment, error) {
-	// Since there are no groups at this stage, the simplest way to get all
-	// the environments that a particular user can see is to look through the
-	// environment user collection. A raw collection is required to support
-	// queries across multiple environments.
+	// A raw collection is required to support queries across multiple
+	// environments.
 	envUsers, userCloser := st.getRawCollection(envUsersC)
 	defer userCloser()
 
@@ -230,6 +275,7 @@ func (st *State) EnvironmentsForUser(user names.UserTag) ([]*UserEnvironment, er
 	}
 
 	var result []*UserEnvironment
+	seen := make(map[string]bool)
 	for _, doc := range userSlice {
 		envTag := names.NewEnvironTag(doc.EnvUUID)
 		env, err := st.GetEnvironment(envTag)
@@ -237,13 +283,30 @@ func (st *State) EnvironmentsForUser(user names.UserTag) ([]*UserEnvironment, er
 			return nil, errors.Trace(err)
 		}
 		result = append(result, &UserEnvironment{Environment: env, LastConnection: doc.LastConnection})
+		seen[doc.EnvUUID] = true
+	}
+
+	groupEnvUUIDs, err := st.groupEnvironmentUUIDs(user, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, uuid := range groupEnvUUIDs {
+		if seen[uuid] {
+			continue
+		}
+		env, err := st.GetEnvironment(names.NewEnvironTag(uuid))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result = append(result, &UserEnvironment{Environment: env})
 	}
 
 	return result, nil
 }
 
 // IsSystemAdministrator returns true if the user specified has access to the
-// state server environment (the system environment).
+// state server environment (the system environment), either directly or
+// through a group.
 func (st *State) IsSystemAdministrator(user names.UserTag) (bool, error) {
 	ssinfo, err := st.StateServerInfo()
 	if err != nil {
@@ -262,5 +325,12 @@ func (st *State) IsSystemAdministrator(user names.UserTag) (bool, error) {
 	if err != nil {
 		return false, errors.Trace(err)
 	}
-	return count == 1, nil
+	if count == 1 {
+		return true, nil
+	}
+	uuids, err := st.groupEnvironmentUUIDs(user, bson.D{{"env-uuid", serverUUID}})
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(uuids) > 0, nil
 }
@@ -129,6 +129,33 @@ func (s *EnvUserSuite) TestRemoveEnvironmentUserFails(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *EnvUserSuite) TestAccessGroupEnvironmentUser(c *gc.C) {
+	tag := names.NewUserTag("bob@example")
+	envUser, err := s.State.AddAccessGroupEnvironmentUser(tag, s.Owner, "Bob")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.AddedForAccessGroup(), jc.IsTrue)
+	envUser, err = s.State.EnvironmentUser(tag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.AddedForAccessGroup(), jc.IsTrue)
+
+	err = s.State.RemoveAccessGroupEnvironmentUser(tag)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnvironmentUser(tag)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EnvUserSuite) TestRemoveAccessGroupEnvironmentUserAddedByUser(c *gc.C) {
+	tag := names.NewUserTag("bob@example")
+	envUser, err := s.State.AddEnvironmentUser(tag, s.Owner, "Bob")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.AddedForAccessGroup(), jc.IsFalse)
+
+	err = s.State.RemoveAccessGroupEnvironmentUser(tag)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.EnvironmentUser(tag)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *EnvUserSuite) TestUpdateLastConnection(c *gc.C) {
 	now := state.NowToTheSecond()
 	createdBy := s.Factory.MakeUser(c, &factory.UserParams{Name: "createdby"})
@@ -53,6 +53,9 @@ var (
 	AddVolumeOp            = (*State).addVolumeOp
 	CombineMeterStatus     = combineMeterStatus
 	NewStatusNotFound      = newStatusNotFound
+	APITokenNow            = &apiTokenNow
+	MaxWebhookDeliveries   = &maxWebhookDeliveries
+	CleanupMinRetryDelay   = &cleanupMinRetryDelay
 )
 
 type (
@@ -463,3 +466,8 @@ func WriteLogWithOplog(
 	logs := session.DB("logs").C("logs")
 	return logs.Insert(doc)
 }
+
+// AddCleanup schedules a cleanup of the given kind and prefix.
+func AddCleanup(st *State, kind, prefix string) error {
+	return st.runTransaction([]txn.Op{st.newCleanupOp(cleanupKind(kind), prefix)})
+}
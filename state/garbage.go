@@ -0,0 +1,380 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/charm.v5"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+// GarbageKind identifies the kind of an unused blob.
+type GarbageKind string
+
+const (
+	// GarbageCharm identifies a charm archive that is not used by
+	// any service or unit.
+	GarbageCharm GarbageKind = "charm"
+
+	// GarbageTools identifies a tools tarball that is not used by
+	// any agent.
+	GarbageTools GarbageKind = "tools"
+)
+
+// Garbage describes a blob held by the state server that is no
+// longer used by anything in any environment.
+type Garbage struct {
+	Kind GarbageKind
+
+	// EnvUUID holds the UUID of the environment the charm was added
+	// to. It is empty for tools, which are shared by all
+	// environments.
+	EnvUUID string
+
+	// Id holds the charm URL or tools binary version.
+	Id string
+
+	// Size holds the size of the blob in bytes.
+	Size int64
+
+	// UploadedAt holds when a charm archive was stored. It is zero
+	// for tools, and for charms stored before it was recorded.
+	UploadedAt time.Time
+}
+
+// Key returns a string uniquely identifying the garbage.
+func (g Garbage) Key() string {
+	if g.EnvUUID == "" {
+		return fmt.Sprintf("%s:%s", g.Kind, g.Id)
+	}
+	return fmt.Sprintf("%s:%s:%s", g.Kind, g.EnvUUID, g.Id)
+}
+
+// FindGarbage returns the charm archives that are not used by any
+// service or unit, and the tools tarballs that are not used by any
+// agent nor match the agent version of any environment. Only the
+// state server environment has a complete view, so it should be
+// called on that environment's State.
+func (st *State) FindGarbage() ([]Garbage, error) {
+	charms, err := st.unusedCharms()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot find unused charms")
+	}
+	tools, err := st.unusedTools()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot find unused tools")
+	}
+	return append(charms, tools...), nil
+}
+
+// RemoveGarbage removes the supplied blob and its metadata, after
+// checking that it is still unused. If it is no longer garbage, an
+// error satisfying errors.IsNotFound is returned.
+func (st *State) RemoveGarbage(g Garbage) error {
+	switch g.Kind {
+	case GarbageCharm:
+		return errors.Annotatef(st.removeUnusedCharm(g), "cannot remove charm %q", g.Id)
+	case GarbageTools:
+		return errors.Annotatef(st.removeUnusedTools(g), "cannot remove %v tools", g.Id)
+	}
+	return errors.NotValidf("garbage kind %q", g.Kind)
+}
+
+// garbageCharmDoc holds the subset of charmDoc fields needed to
+// identify an unused charm archive.
+type garbageCharmDoc struct {
+	URL         *charm.URL `bson:"url"`
+	EnvUUID     string     `bson:"env-uuid"`
+	StoragePath string     `bson:"storagepath"`
+	UploadedAt  *time.Time `bson:"uploadedat"`
+}
+
+// unusedCharms returns the uploaded charms in all environments that
+// are not referenced by a service or unit.
+func (st *State) unusedCharms() ([]Garbage, error) {
+	used, err := st.usedCharms()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	charms, closer := st.getRawCollection(charmsC)
+	defer closer()
+
+	query := bson.D{
+		{"placeholder", bson.D{{"$ne", true}}},
+		{"pendingupload", bson.D{{"$ne", true}}},
+		{"storagepath", bson.D{{"$ne", ""}}},
+	}
+	var docs []garbageCharmDoc
+	err = charms.Find(query).Select(bson.D{
+		{"url", 1}, {"env-uuid", 1}, {"storagepath", 1}, {"uploadedat", 1},
+	}).All(&docs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Garbage
+	for _, doc := range docs {
+		if doc.URL == nil || used[doc.EnvUUID+":"+doc.URL.String()] {
+			continue
+		}
+		size, err := st.blobSize(doc.EnvUUID, doc.StoragePath)
+		if errors.IsNotFound(err) {
+			logger.Warningf("archive for charm %q is missing", doc.URL)
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		g := Garbage{
+			Kind:    GarbageCharm,
+			EnvUUID: doc.EnvUUID,
+			Id:      doc.URL.String(),
+			Size:    size,
+		}
+		if doc.UploadedAt != nil {
+			g.UploadedAt = doc.UploadedAt.UTC()
+		}
+		result = append(result, g)
+	}
+	return result, nil
+}
+
+// usedCharms returns the set of charm URLs, prefixed with their
+// environment UUID, referenced by services and units in all
+// environments.
+func (st *State) usedCharms() (map[string]bool, error) {
+	used := make(map[string]bool)
+	for _, name := range []string{servicesC, unitsC} {
+		coll, closer := st.getRawCollection(name)
+		defer closer()
+
+		var doc struct {
+			EnvUUID  string     `bson:"env-uuid"`
+			CharmURL *charm.URL `bson:"charmurl"`
+		}
+		query := bson.D{{"charmurl", bson.D{{"$ne", nil}}}}
+		iter := coll.Find(query).Select(bson.D{{"env-uuid", 1}, {"charmurl", 1}}).Iter()
+		for iter.Next(&doc) {
+			if doc.CharmURL != nil {
+				used[doc.EnvUUID+":"+doc.CharmURL.String()] = true
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return nil, errors.Annotatef(err, "cannot read %s", name)
+		}
+	}
+	return used, nil
+}
+
+// blobSize returns the size of the blob stored at path in the
+// given environment's storage.
+func (st *State) blobSize(envUUID, path string) (int64, error) {
+	stor := storage.NewStorage(envUUID, st.MongoSession())
+	r, size, err := stor.Get(path)
+	if err != nil {
+		return 0, err
+	}
+	r.Close()
+	return size, nil
+}
+
+func (st *State) removeUnusedCharm(g Garbage) error {
+	curl, err := charm.ParseURL(g.Id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	used, err := st.usedCharms()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if used[g.EnvUUID+":"+g.Id] {
+		return errors.NotFoundf("unused charm %q", g.Id)
+	}
+	envSt := st
+	if g.EnvUUID != st.EnvironUUID() {
+		envSt, err = st.ForEnviron(names.NewEnvironTag(g.EnvUUID))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer envSt.Close()
+	}
+
+	charms, closer := envSt.getCollection(charmsC)
+	defer closer()
+	var doc charmDoc
+	err = charms.FindId(curl.String()).Select(bson.D{{"storagepath", 1}}).One(&doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("unused charm %q", g.Id)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	// The ref count guards against a service or unit starting to use
+	// the charm after usedCharms was consulted.
+	ops := []txn.Op{{
+		C:  charmsC,
+		Id: curl.String(),
+		Assert: bson.D{
+			{"storagepath", doc.StoragePath},
+			{"placeholder", bson.D{{"$ne", true}}},
+			{"pendingupload", bson.D{{"$ne", true}}},
+			{"refcount", bson.D{{"$not", bson.D{{"$gt", 0}}}}},
+		},
+		Remove: true,
+	}}
+	if err := envSt.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("unused charm %q", g.Id)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if doc.StoragePath == "" {
+		return nil
+	}
+	stor := storage.NewStorage(g.EnvUUID, st.MongoSession())
+	if err := stor.Remove(doc.StoragePath); err != nil && !errors.IsNotFound(err) {
+		return errors.Annotate(err, "cannot remove archive")
+	}
+	return nil
+}
+
+// unusedTools returns the tools in the catalogue that are not used
+// by any agent, and whose version number is not the agent version of
+// any environment.
+func (st *State) unusedTools() ([]Garbage, error) {
+	usedBinaries, usedNumbers, err := st.usedTools()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	toolsStorage, err := st.ToolsStorage()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer toolsStorage.Close()
+	all, err := toolsStorage.AllMetadata()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []Garbage
+	for _, metadata := range all {
+		if usedBinaries[metadata.Version] || usedNumbers[metadata.Version.Number] {
+			continue
+		}
+		result = append(result, Garbage{
+			Kind: GarbageTools,
+			Id:   metadata.Version.String(),
+			Size: metadata.Size,
+		})
+	}
+	return result, nil
+}
+
+// usedTools returns the tools versions reported by machine and unit
+// agents in all environments, and the agent versions of all
+// environments.
+func (st *State) usedTools() (map[version.Binary]bool, map[version.Number]bool, error) {
+	binaries, numbers, _, err := st.usedToolsWithAsserts()
+	return binaries, numbers, err
+}
+
+// usedToolsWithAsserts is like usedTools, but also returns
+// operations asserting that the agent version of every environment
+// is still the one found.
+func (st *State) usedToolsWithAsserts() (map[version.Binary]bool, map[version.Number]bool, []txn.Op, error) {
+	binaries := make(map[version.Binary]bool)
+	for _, name := range []string{machinesC, unitsC} {
+		coll, closer := st.getRawCollection(name)
+		defer closer()
+
+		var doc struct {
+			Tools *tools.Tools `bson:"tools"`
+		}
+		query := bson.D{{"tools", bson.D{{"$ne", nil}}}}
+		iter := coll.Find(query).Select(bson.D{{"tools", 1}}).Iter()
+		for iter.Next(&doc) {
+			if doc.Tools != nil {
+				binaries[doc.Tools.Version] = true
+			}
+			doc.Tools = nil
+		}
+		if err := iter.Close(); err != nil {
+			return nil, nil, nil, errors.Annotatef(err, "cannot read %s", name)
+		}
+	}
+
+	envs, err := st.AllEnvironments()
+	if err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	ids := make([]string, len(envs))
+	for i, env := range envs {
+		ids[i] = ensureEnvUUID(env.UUID(), environGlobalKey)
+	}
+	settings, closer := st.getRawCollection(settingsC)
+	defer closer()
+	var doc struct {
+		DocID        string `bson:"_id"`
+		AgentVersion string `bson:"agent-version"`
+	}
+	numbers := make(map[version.Number]bool)
+	var asserts []txn.Op
+	query := bson.D{{"_id", bson.D{{"$in", ids}}}}
+	iter := settings.Find(query).Select(bson.D{{"agent-version", 1}}).Iter()
+	for iter.Next(&doc) {
+		if doc.AgentVersion == "" {
+			continue
+		}
+		asserts = append(asserts, txn.Op{
+			C:      settingsC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"agent-version", doc.AgentVersion}},
+		})
+		number, err := version.Parse(doc.AgentVersion)
+		if err != nil {
+			iter.Close()
+			return nil, nil, nil, errors.Annotatef(err, "invalid agent version %q", doc.AgentVersion)
+		}
+		numbers[number] = true
+		doc.DocID, doc.AgentVersion = "", ""
+	}
+	if err := iter.Close(); err != nil {
+		return nil, nil, nil, errors.Annotate(err, "cannot read environment settings")
+	}
+	return binaries, numbers, asserts, nil
+}
+
+func (st *State) removeUnusedTools(g Garbage) error {
+	v, err := version.ParseBinary(g.Id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	toolsStorage, err := st.ToolsStorage()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer toolsStorage.Close()
+	for attempt := 0; attempt < 3; attempt++ {
+		usedBinaries, usedNumbers, asserts, err := st.usedToolsWithAsserts()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if usedBinaries[v] || usedNumbers[v.Number] {
+			return errors.NotFoundf("unused %v tools", v)
+		}
+		// The tools are only removed if no environment's agent
+		// version has changed since it was checked; otherwise an
+		// upgrade to them may have started, so check again.
+		err = toolsStorage.RemoveTools(v, asserts...)
+		if err != txn.ErrAborted {
+			return err
+		}
+	}
+	return jujutxn.ErrExcessiveContention
+}
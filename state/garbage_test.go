@@ -0,0 +1,261 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/blobstore"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	jujutxn "github.com/juju/txn"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v5"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/state/toolstorage"
+	"github.com/juju/juju/testcharms"
+	"github.com/juju/juju/version"
+)
+
+type GarbageSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&GarbageSuite{})
+
+// addStoredCharm adds a charm to the environment of st, with an
+// archive of the given content in storage.
+func (s *GarbageSuite) addStoredCharm(c *gc.C, st *state.State, url, content string) *state.Charm {
+	path := "charms/" + url
+	stor := storage.NewStorage(st.EnvironUUID(), st.MongoSession())
+	err := stor.Put(path, strings.NewReader(content), int64(len(content)))
+	c.Assert(err, jc.ErrorIsNil)
+	ch, err := st.AddCharm(testcharms.Repo.CharmDir("dummy"), charm.MustParseURL(url), path, "sha")
+	c.Assert(err, jc.ErrorIsNil)
+	return ch
+}
+
+func (s *GarbageSuite) addTools(c *gc.C, v string) version.Binary {
+	toolsStorage, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer toolsStorage.Close()
+	metadata := toolstorage.Metadata{Version: version.MustParseBinary(v), Size: 3, SHA256: "hash"}
+	err = toolsStorage.AddTools(strings.NewReader("abc"), metadata)
+	c.Assert(err, jc.ErrorIsNil)
+	return metadata.Version
+}
+
+func (s *GarbageSuite) TestFindGarbageNone(c *gc.C) {
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 0)
+}
+
+func (s *GarbageSuite) TestFindGarbageCharms(c *gc.C) {
+	used := s.addStoredCharm(c, s.State, "local:quantal/dummy-1", "used")
+	s.AddTestingService(c, "dummy", used)
+	before := time.Now().Add(-time.Second)
+	s.addStoredCharm(c, s.State, "local:quantal/dummy-2", "unused")
+
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 1)
+	c.Assert(garbage[0].UploadedAt.After(before), jc.IsTrue)
+	garbage[0].UploadedAt = time.Time{}
+	c.Assert(garbage, jc.DeepEquals, []state.Garbage{{
+		Kind:    state.GarbageCharm,
+		EnvUUID: s.State.EnvironUUID(),
+		Id:      "local:quantal/dummy-2",
+		Size:    6,
+	}})
+}
+
+func (s *GarbageSuite) TestFindGarbageCharmUsedByUnit(c *gc.C) {
+	old := s.addStoredCharm(c, s.State, "local:quantal/dummy-1", "old")
+	svc := s.AddTestingService(c, "dummy", old)
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetCharmURL(old.URL())
+	c.Assert(err, jc.ErrorIsNil)
+
+	newer := s.addStoredCharm(c, s.State, "local:quantal/dummy-2", "new")
+	err = svc.SetCharm(newer, false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 0)
+}
+
+func (s *GarbageSuite) TestFindGarbageOtherEnvironment(c *gc.C) {
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	s.addStoredCharm(c, st, "local:quantal/dummy-1", "other")
+
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 1)
+	garbage[0].UploadedAt = time.Time{}
+	c.Assert(garbage, jc.DeepEquals, []state.Garbage{{
+		Kind:    state.GarbageCharm,
+		EnvUUID: st.EnvironUUID(),
+		Id:      "local:quantal/dummy-1",
+		Size:    5,
+	}})
+
+	err = s.State.RemoveGarbage(garbage[0])
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = st.Charm(charm.MustParseURL("local:quantal/dummy-1"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *GarbageSuite) TestFindGarbageTools(c *gc.C) {
+	s.addTools(c, version.Current.String())
+	s.addTools(c, "1.2.3-quantal-amd64")
+	s.addTools(c, "1.2.4-quantal-amd64")
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetAgentVersion(version.MustParseBinary("1.2.4-quantal-amd64"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, jc.DeepEquals, []state.Garbage{{
+		Kind: state.GarbageTools,
+		Id:   "1.2.3-quantal-amd64",
+		Size: 3,
+	}})
+}
+
+func (s *GarbageSuite) TestRemoveGarbageCharm(c *gc.C) {
+	ch := s.addStoredCharm(c, s.State, "local:quantal/dummy-1", "unused")
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 1)
+
+	err = s.State.RemoveGarbage(garbage[0])
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.Charm(ch.URL())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	stor := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession())
+	_, _, err = stor.Get(ch.StoragePath())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.RemoveGarbage(garbage[0])
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *GarbageSuite) TestRemoveGarbageCharmNowUsed(c *gc.C) {
+	ch := s.addStoredCharm(c, s.State, "local:quantal/dummy-1", "unused")
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 1)
+
+	s.AddTestingService(c, "dummy", ch)
+	err = s.State.RemoveGarbage(garbage[0])
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `cannot remove charm "local:quantal/dummy-1": unused charm "local:quantal/dummy-1" not found`)
+	_, err = s.State.Charm(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *GarbageSuite) TestRemoveGarbageCharmUsedConcurrently(c *gc.C) {
+	ch := s.addStoredCharm(c, s.State, "local:quantal/dummy-1", "unused")
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 1)
+
+	defer state.SetBeforeHooks(c, s.State, func() {
+		s.AddTestingService(c, "dummy", ch)
+	}).Check()
+	err = s.State.RemoveGarbage(garbage[0])
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Charm(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *GarbageSuite) TestRemoveGarbageCharmServiceRemoved(c *gc.C) {
+	ch := s.addStoredCharm(c, s.State, "local:quantal/dummy-1", "unused")
+	svc := s.AddTestingService(c, "dummy", ch)
+	err := svc.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	garbage, err := s.State.FindGarbage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(garbage, gc.HasLen, 1)
+
+	err = s.State.RemoveGarbage(garbage[0])
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.Charm(ch.URL())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *GarbageSuite) TestRemoveGarbageTools(c *gc.C) {
+	v := s.addTools(c, "1.2.3-quantal-amd64")
+	err := s.State.RemoveGarbage(state.Garbage{Kind: state.GarbageTools, Id: v.String()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	toolsStorage, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer toolsStorage.Close()
+	_, err = toolsStorage.Metadata(v)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *GarbageSuite) TestRemoveGarbageToolsNowUsed(c *gc.C) {
+	v := s.addTools(c, "1.2.3-quantal-amd64")
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetAgentVersion(v)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RemoveGarbage(state.Garbage{Kind: state.GarbageTools, Id: v.String()})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+// upgradingToolsStorage changes the environment agent version to the
+// tools being removed just before removing them.
+type upgradingToolsStorage struct {
+	toolstorage.Storage
+	st *state.State
+}
+
+func (s upgradingToolsStorage) RemoveTools(v version.Binary, asserts ...txn.Op) error {
+	if err := s.st.SetEnvironAgentVersion(v.Number); err != nil {
+		return err
+	}
+	return s.Storage.RemoveTools(v, asserts...)
+}
+
+func (s *GarbageSuite) TestRemoveGarbageToolsAgentVersionChanged(c *gc.C) {
+	v := s.addTools(c, "1.2.3-quantal-amd64")
+	newStorage := *state.ToolstorageNewStorage
+	s.PatchValue(state.ToolstorageNewStorage, func(
+		envUUID string,
+		managedStorage blobstore.ManagedStorage,
+		metadataCollection *mgo.Collection,
+		runner jujutxn.Runner,
+	) toolstorage.Storage {
+		stor := newStorage(envUUID, managedStorage, metadataCollection, runner)
+		return upgradingToolsStorage{stor, s.State}
+	})
+
+	err := s.State.RemoveGarbage(state.Garbage{Kind: state.GarbageTools, Id: v.String()})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	toolsStorage, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer toolsStorage.Close()
+	_, err = toolsStorage.Metadata(v)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *GarbageSuite) TestRemoveGarbageInvalidKind(c *gc.C) {
+	err := s.State.RemoveGarbage(state.Garbage{Kind: "bogus", Id: "x"})
+	c.Assert(err, gc.ErrorMatches, `garbage kind "bogus" not valid`)
+}
@@ -56,6 +56,7 @@ var (
 	// _ ActionsWatcher = (*Service)(nil)
 
 	_ ActionReceiver = (*Unit)(nil)
+	_ ActionReceiver = (*Machine)(nil)
 	// TODO(jcw4) - use when Actions can be queued for Services.
 	//_ ActionReceiver = (*Service)(nil)
 
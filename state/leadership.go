@@ -3,6 +3,7 @@ package state
 import (
 	"fmt"
 
+	"github.com/juju/errors"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 
@@ -43,3 +44,23 @@ func (st *State) LeadershipClaimer() leadership.Claimer {
 func (st *State) LeadershipChecker() leadership.Checker {
 	return st.leadershipManager
 }
+
+// serviceLeaders returns the unit holding the leadership lease of each
+// service in the environment, keyed by service name.
+func (st *State) serviceLeaders() (map[string]string, error) {
+	leases, closer := st.getCollection(leasesC)
+	defer closer()
+	var docs []struct {
+		Name   string `bson:"name"`
+		Holder string `bson:"holder"`
+	}
+	query := bson.D{{"type", "lease"}, {"namespace", serviceLeadershipNamespace}}
+	if err := leases.Find(query).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot read leadership leases")
+	}
+	result := make(map[string]string)
+	for _, doc := range docs {
+		result[doc.Name] = doc.Holder
+	}
+	return result, nil
+}
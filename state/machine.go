@@ -20,6 +20,7 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state/multiwatcher"
@@ -1600,3 +1601,46 @@ func (m *Machine) SetMachineBlockDevices(info ...BlockDeviceInfo) error {
 func (m *Machine) VolumeAttachments() ([]VolumeAttachment, error) {
 	return m.st.MachineVolumeAttachments(m.MachineTag())
 }
+
+// AddAction queues an action with the given name and payload for this
+// machine. Machines have no charm to define actions, so the only action
+// that may be queued for them is the predefined juju-run action.
+func (m *Machine) AddAction(name string, payload map[string]interface{}) (*Action, error) {
+	if name != jujurun.ActionName {
+		return nil, errors.Errorf("action %q not defined on machine %q", name, m.Id())
+	}
+	return addJujuRunAction(m.st, m.Tag(), payload)
+}
+
+// CancelAction removes a pending Action from the queue for this
+// machine and marks it as cancelled.
+func (m *Machine) CancelAction(action *Action) (*Action, error) {
+	return action.Finish(ActionResults{Status: ActionCancelled})
+}
+
+// WatchActionNotifications starts and returns a StringsWatcher that
+// notifies when actions with Id prefixes matching this machine are added.
+func (m *Machine) WatchActionNotifications() StringsWatcher {
+	return m.st.watchEnqueuedActionsFilteredBy(m)
+}
+
+// Actions returns a list of actions pending or completed for this machine.
+func (m *Machine) Actions() ([]*Action, error) {
+	return m.st.matchingActions(m)
+}
+
+// CompletedActions returns a list of actions that have finished for
+// this machine.
+func (m *Machine) CompletedActions() ([]*Action, error) {
+	return m.st.matchingActionsCompleted(m)
+}
+
+// PendingActions returns a list of actions pending for this machine.
+func (m *Machine) PendingActions() ([]*Action, error) {
+	return m.st.matchingActionsPending(m)
+}
+
+// RunningActions returns a list of actions running on this machine.
+func (m *Machine) RunningActions() ([]*Action, error) {
+	return m.st.matchingActionsRunning(m)
+}
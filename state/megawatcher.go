@@ -473,6 +473,203 @@ func (a *backingBlock) mongoId() interface{} {
 	return a.DocID
 }
 
+type backingStorageInstance storageInstanceDoc
+
+func (s *backingStorageInstance) updated(st *State, store *multiwatcherStore, id interface{}) error {
+	info := &multiwatcher.StorageInfo{
+		Id:              s.Id,
+		Kind:            storageKindString(s.Kind),
+		Name:            s.StorageName,
+		OwnerTag:        s.Owner,
+		Life:            multiwatcher.Life(s.Life.String()),
+		AttachmentCount: s.AttachmentCount,
+	}
+	store.Update(info)
+	return nil
+}
+
+func (s *backingStorageInstance) removed(st *State, store *multiwatcherStore, id interface{}) {
+	store.Remove(multiwatcher.EntityId{
+		Kind: "storage",
+		Id:   st.localID(id.(string)),
+	})
+}
+
+func (s *backingStorageInstance) mongoId() interface{} {
+	return s.DocID
+}
+
+// storageKindString returns the string representation of the
+// given storage kind, as used in multiwatcher.StorageInfo.
+func storageKindString(kind StorageKind) string {
+	switch kind {
+	case StorageKindBlock:
+		return "block"
+	case StorageKindFilesystem:
+		return "filesystem"
+	}
+	return "unknown"
+}
+
+type backingStorageAttachment storageAttachmentDoc
+
+func (a *backingStorageAttachment) updated(st *State, store *multiwatcherStore, id interface{}) error {
+	info := &multiwatcher.StorageAttachmentInfo{
+		StorageId: a.StorageInstance,
+		UnitName:  a.Unit,
+		Life:      multiwatcher.Life(a.Life.String()),
+	}
+	store.Update(info)
+	return nil
+}
+
+func (a *backingStorageAttachment) removed(st *State, store *multiwatcherStore, id interface{}) {
+	// Storage attachment IDs are of the form "u#<unit>#<storage>".
+	localID := st.localID(id.(string))
+	parts := strings.SplitN(localID, "#", 3)
+	if len(parts) != 3 || parts[0] != "u" {
+		panic(fmt.Errorf("unknown storage attachment id %q in state", localID))
+	}
+	info := &multiwatcher.StorageAttachmentInfo{
+		UnitName:  parts[1],
+		StorageId: parts[2],
+	}
+	store.Remove(info.EntityId())
+}
+
+func (a *backingStorageAttachment) mongoId() interface{} {
+	return a.DocID
+}
+
+type backingVolume volumeDoc
+
+func (v *backingVolume) updated(st *State, store *multiwatcherStore, id interface{}) error {
+	info := &multiwatcher.VolumeInfo{
+		Name:      v.Name,
+		StorageId: v.StorageId,
+		Life:      multiwatcher.Life(v.Life.String()),
+	}
+	if v.Info != nil {
+		info.Provisioned = true
+		info.Pool = v.Info.Pool
+		info.Size = v.Info.Size
+		info.VolumeId = v.Info.VolumeId
+		info.HardwareId = v.Info.HardwareId
+		info.Persistent = v.Info.Persistent
+		info.Zone = v.Info.Zone
+	} else if v.Params != nil {
+		info.Pool = v.Params.Pool
+		info.Size = v.Params.Size
+	}
+	store.Update(info)
+	return nil
+}
+
+func (v *backingVolume) removed(st *State, store *multiwatcherStore, id interface{}) {
+	store.Remove(multiwatcher.EntityId{
+		Kind: "volume",
+		Id:   st.localID(id.(string)),
+	})
+}
+
+func (v *backingVolume) mongoId() interface{} {
+	return v.DocID
+}
+
+type backingVolumeAttachment volumeAttachmentDoc
+
+func (a *backingVolumeAttachment) updated(st *State, store *multiwatcherStore, id interface{}) error {
+	info := &multiwatcher.VolumeAttachmentInfo{
+		VolumeName: a.Volume,
+		MachineId:  a.Machine,
+		Life:       multiwatcher.Life(a.Life.String()),
+	}
+	if a.Info != nil {
+		info.Provisioned = true
+		info.DeviceName = a.Info.DeviceName
+		info.BusAddress = a.Info.BusAddress
+		info.ReadOnly = a.Info.ReadOnly
+	} else if a.Params != nil {
+		info.ReadOnly = a.Params.ReadOnly
+	}
+	store.Update(info)
+	return nil
+}
+
+func (a *backingVolumeAttachment) removed(st *State, store *multiwatcherStore, id interface{}) {
+	store.Remove(multiwatcher.EntityId{
+		Kind: "volumeattachment",
+		Id:   st.localID(id.(string)),
+	})
+}
+
+func (a *backingVolumeAttachment) mongoId() interface{} {
+	return a.DocID
+}
+
+type backingFilesystem filesystemDoc
+
+func (f *backingFilesystem) updated(st *State, store *multiwatcherStore, id interface{}) error {
+	info := &multiwatcher.FilesystemInfo{
+		Name:       f.FilesystemId,
+		StorageId:  f.StorageId,
+		VolumeName: f.VolumeId,
+		Life:       multiwatcher.Life(f.Life.String()),
+	}
+	if f.Info != nil {
+		info.Provisioned = true
+		info.Pool = f.Info.Pool
+		info.Size = f.Info.Size
+		info.FilesystemId = f.Info.FilesystemId
+	} else if f.Params != nil {
+		info.Pool = f.Params.Pool
+		info.Size = f.Params.Size
+	}
+	store.Update(info)
+	return nil
+}
+
+func (f *backingFilesystem) removed(st *State, store *multiwatcherStore, id interface{}) {
+	store.Remove(multiwatcher.EntityId{
+		Kind: "filesystem",
+		Id:   st.localID(id.(string)),
+	})
+}
+
+func (f *backingFilesystem) mongoId() interface{} {
+	return f.DocID
+}
+
+type backingFilesystemAttachment filesystemAttachmentDoc
+
+func (a *backingFilesystemAttachment) updated(st *State, store *multiwatcherStore, id interface{}) error {
+	info := &multiwatcher.FilesystemAttachmentInfo{
+		FilesystemName: a.Filesystem,
+		MachineId:      a.Machine,
+		Life:           multiwatcher.Life(a.Life.String()),
+	}
+	if a.Info != nil {
+		info.Provisioned = true
+		info.MountPoint = a.Info.MountPoint
+		info.ReadOnly = a.Info.ReadOnly
+	} else if a.Params != nil {
+		info.ReadOnly = a.Params.ReadOnly
+	}
+	store.Update(info)
+	return nil
+}
+
+func (a *backingFilesystemAttachment) removed(st *State, store *multiwatcherStore, id interface{}) {
+	store.Remove(multiwatcher.EntityId{
+		Kind: "filesystemattachment",
+		Id:   st.localID(id.(string)),
+	})
+}
+
+func (a *backingFilesystemAttachment) mongoId() interface{} {
+	return a.DocID
+}
+
 type backingStatus statusDoc
 
 func (s *backingStatus) updated(st *State, store *multiwatcherStore, id interface{}) error {
@@ -881,6 +1078,24 @@ func newAllWatcherStateBacking(st *State) Backing {
 	}, {
 		name:     blocksC,
 		infoType: reflect.TypeOf(backingBlock{}),
+	}, {
+		name:     storageInstancesC,
+		infoType: reflect.TypeOf(backingStorageInstance{}),
+	}, {
+		name:     storageAttachmentsC,
+		infoType: reflect.TypeOf(backingStorageAttachment{}),
+	}, {
+		name:     volumesC,
+		infoType: reflect.TypeOf(backingVolume{}),
+	}, {
+		name:     volumeAttachmentsC,
+		infoType: reflect.TypeOf(backingVolumeAttachment{}),
+	}, {
+		name:     filesystemsC,
+		infoType: reflect.TypeOf(backingFilesystem{}),
+	}, {
+		name:     filesystemAttachmentsC,
+		infoType: reflect.TypeOf(backingFilesystemAttachment{}),
 	}, {
 		name:       statusesC,
 		infoType:   reflect.TypeOf(backingStatus{}),
@@ -36,6 +36,12 @@ var (
 	_ backingEntityDoc = (*backingOpenedPorts)(nil)
 	_ backingEntityDoc = (*backingAction)(nil)
 	_ backingEntityDoc = (*backingBlock)(nil)
+	_ backingEntityDoc = (*backingStorageInstance)(nil)
+	_ backingEntityDoc = (*backingStorageAttachment)(nil)
+	_ backingEntityDoc = (*backingVolume)(nil)
+	_ backingEntityDoc = (*backingVolumeAttachment)(nil)
+	_ backingEntityDoc = (*backingFilesystem)(nil)
+	_ backingEntityDoc = (*backingFilesystemAttachment)(nil)
 )
 
 var dottedConfig = `
@@ -596,6 +602,244 @@ func (s *storeManagerStateSuite) TestChangeBlocks(c *gc.C) {
 	s.performChangeTestCases(c, changeTestFuncs)
 }
 
+// TestChangeStorage tests the changing of storage instances
+// and their attachments.
+func (s *storeManagerStateSuite) TestChangeStorage(c *gc.C) {
+	changeTestFuncs := []changeTestFunc{
+		func(c *gc.C, st *State) changeTestCase {
+			return changeTestCase{
+				about: "no storage in state, no storage in store -> do nothing",
+				change: watcher.Change{
+					C:  storageInstancesC,
+					Id: st.docID("data/0"),
+				}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			return changeTestCase{
+				about: "storage is removed if it's not in backing",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.StorageInfo{
+					Id:   "data/0",
+					Kind: "block",
+					Name: "data",
+				}},
+				change: watcher.Change{
+					C:  storageInstancesC,
+					Id: st.docID("data/0"),
+				}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			return changeTestCase{
+				about: "storage attachment is removed if it's not in backing",
+				initialContents: []multiwatcher.EntityInfo{
+					&multiwatcher.StorageAttachmentInfo{
+						StorageId: "data/0",
+						UnitName:  "wordpress/0",
+					},
+					&multiwatcher.StorageAttachmentInfo{
+						StorageId: "data/1",
+						UnitName:  "wordpress/0",
+					},
+				},
+				change: watcher.Change{
+					C:  storageAttachmentsC,
+					Id: st.docID(storageAttachmentId("wordpress/0", "data/0")),
+				},
+				expectContents: []multiwatcher.EntityInfo{
+					&multiwatcher.StorageAttachmentInfo{
+						StorageId: "data/1",
+						UnitName:  "wordpress/0",
+					},
+				}}
+		},
+	}
+	s.performChangeTestCases(c, changeTestFuncs)
+}
+
+// TestChangeVolumes tests the changing of volumes
+// and volume attachments.
+func (s *storeManagerStateSuite) TestChangeVolumes(c *gc.C) {
+	addVolume := func(c *gc.C, st *State) (names.MachineTag, names.VolumeTag) {
+		m, err := st.AddOneMachine(MachineTemplate{
+			Series: "quantal",
+			Jobs:   []MachineJob{JobHostUnits},
+			Volumes: []MachineVolumeParams{{
+				Volume: VolumeParams{Pool: "loop", Size: 1024},
+			}},
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		attachments, err := st.MachineVolumeAttachments(m.MachineTag())
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(attachments, gc.HasLen, 1)
+		return m.MachineTag(), attachments[0].Volume()
+	}
+	changeTestFuncs := []changeTestFunc{
+		func(c *gc.C, st *State) changeTestCase {
+			return changeTestCase{
+				about: "volume is removed if it's not in backing",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.VolumeInfo{
+					Name: "0",
+					Pool: "loop",
+					Size: 1024,
+				}},
+				change: watcher.Change{
+					C:  volumesC,
+					Id: st.docID("0"),
+				}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			_, volumeTag := addVolume(c, st)
+			return changeTestCase{
+				about: "unprovisioned volume is added if it's in backing but not in store",
+				change: watcher.Change{
+					C:  volumesC,
+					Id: st.docID(volumeTag.Id()),
+				},
+				expectContents: []multiwatcher.EntityInfo{&multiwatcher.VolumeInfo{
+					Name: volumeTag.Id(),
+					Life: multiwatcher.Life("alive"),
+					Pool: "loop",
+					Size: 1024,
+				}}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			_, volumeTag := addVolume(c, st)
+			err := st.SetVolumeInfo(volumeTag, VolumeInfo{
+				VolumeId: "vol-123",
+				Size:     2048,
+			})
+			c.Assert(err, jc.ErrorIsNil)
+			return changeTestCase{
+				about: "provisioned volume is updated",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.VolumeInfo{
+					Name: volumeTag.Id(),
+					Life: multiwatcher.Life("alive"),
+					Pool: "loop",
+					Size: 1024,
+				}},
+				change: watcher.Change{
+					C:  volumesC,
+					Id: st.docID(volumeTag.Id()),
+				},
+				expectContents: []multiwatcher.EntityInfo{&multiwatcher.VolumeInfo{
+					Name:        volumeTag.Id(),
+					Life:        multiwatcher.Life("alive"),
+					Pool:        "loop",
+					Size:        2048,
+					Provisioned: true,
+					VolumeId:    "vol-123",
+				}}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			machineTag, volumeTag := addVolume(c, st)
+			return changeTestCase{
+				about: "volume attachment is added if it's in backing but not in store",
+				change: watcher.Change{
+					C:  volumeAttachmentsC,
+					Id: st.docID(volumeAttachmentId(machineTag.Id(), volumeTag.Id())),
+				},
+				expectContents: []multiwatcher.EntityInfo{&multiwatcher.VolumeAttachmentInfo{
+					VolumeName: volumeTag.Id(),
+					MachineId:  machineTag.Id(),
+					Life:       multiwatcher.Life("alive"),
+				}}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			return changeTestCase{
+				about: "volume attachment is removed if it's not in backing",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.VolumeAttachmentInfo{
+					VolumeName: "0/0",
+					MachineId:  "0",
+				}},
+				change: watcher.Change{
+					C:  volumeAttachmentsC,
+					Id: st.docID(volumeAttachmentId("0", "0/0")),
+				}}
+		},
+	}
+	s.performChangeTestCases(c, changeTestFuncs)
+}
+
+// TestChangeFilesystems tests the changing of filesystems
+// and filesystem attachments.
+func (s *storeManagerStateSuite) TestChangeFilesystems(c *gc.C) {
+	addFilesystem := func(c *gc.C, st *State) (names.MachineTag, names.FilesystemTag) {
+		m, err := st.AddOneMachine(MachineTemplate{
+			Series: "quantal",
+			Jobs:   []MachineJob{JobHostUnits},
+			Filesystems: []MachineFilesystemParams{{
+				Filesystem: FilesystemParams{Pool: "rootfs", Size: 1024},
+				Attachment: FilesystemAttachmentParams{Location: "/srv"},
+			}},
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		attachments, err := st.MachineFilesystemAttachments(m.MachineTag())
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(attachments, gc.HasLen, 1)
+		return m.MachineTag(), attachments[0].Filesystem()
+	}
+	changeTestFuncs := []changeTestFunc{
+		func(c *gc.C, st *State) changeTestCase {
+			_, filesystemTag := addFilesystem(c, st)
+			return changeTestCase{
+				about: "filesystem is added if it's in backing but not in store",
+				change: watcher.Change{
+					C:  filesystemsC,
+					Id: st.docID(filesystemTag.Id()),
+				},
+				expectContents: []multiwatcher.EntityInfo{&multiwatcher.FilesystemInfo{
+					Name: filesystemTag.Id(),
+					Life: multiwatcher.Life("alive"),
+					Pool: "rootfs",
+					Size: 1024,
+				}}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			machineTag, filesystemTag := addFilesystem(c, st)
+			m, err := st.Machine(machineTag.Id())
+			c.Assert(err, jc.ErrorIsNil)
+			err = m.SetProvisioned("inst-id", "fake_nonce", nil)
+			c.Assert(err, jc.ErrorIsNil)
+			err = st.SetFilesystemInfo(filesystemTag, FilesystemInfo{FilesystemId: "fs-123"})
+			c.Assert(err, jc.ErrorIsNil)
+			err = st.SetFilesystemAttachmentInfo(machineTag, filesystemTag, FilesystemAttachmentInfo{
+				MountPoint: "/srv",
+			})
+			c.Assert(err, jc.ErrorIsNil)
+			return changeTestCase{
+				about: "provisioned filesystem attachment is updated",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.FilesystemAttachmentInfo{
+					FilesystemName: filesystemTag.Id(),
+					MachineId:      machineTag.Id(),
+					Life:           multiwatcher.Life("alive"),
+				}},
+				change: watcher.Change{
+					C:  filesystemAttachmentsC,
+					Id: st.docID(filesystemAttachmentId(machineTag.Id(), filesystemTag.Id())),
+				},
+				expectContents: []multiwatcher.EntityInfo{&multiwatcher.FilesystemAttachmentInfo{
+					FilesystemName: filesystemTag.Id(),
+					MachineId:      machineTag.Id(),
+					Life:           multiwatcher.Life("alive"),
+					Provisioned:    true,
+					MountPoint:     "/srv",
+				}}}
+		},
+		func(c *gc.C, st *State) changeTestCase {
+			return changeTestCase{
+				about: "filesystem attachment is removed if it's not in backing",
+				initialContents: []multiwatcher.EntityInfo{&multiwatcher.FilesystemAttachmentInfo{
+					FilesystemName: "0/0",
+					MachineId:      "0",
+				}},
+				change: watcher.Change{
+					C:  filesystemAttachmentsC,
+					Id: st.docID(filesystemAttachmentId("0", "0/0")),
+				}}
+		},
+	}
+	s.performChangeTestCases(c, changeTestFuncs)
+}
+
 // TestChangeMachines tests the changing of machines.
 func (s *storeManagerStateSuite) TestChangeMachines(c *gc.C) {
 	changeTestFuncs := []changeTestFunc{
@@ -19,10 +19,19 @@ import (
 type Multiwatcher struct {
 	all *storeManager
 
+	// filter, if non-nil, restricts the changes reported
+	// by the watcher to those for matching entities.
+	filter *multiwatcher.Filter
+
 	// The following fields are maintained by the storeManager
 	// goroutine.
 	revno   int64
 	stopped bool
+
+	// visible holds the ids of the entities that the watcher
+	// has reported as present. It is only used when the
+	// watcher is filtered.
+	visible map[multiwatcher.EntityId]bool
 }
 
 // NewMultiwatcher creates a new watcher that can observe
@@ -33,6 +42,51 @@ func NewMultiwatcher(all *storeManager) *Multiwatcher {
 	}
 }
 
+// NewFilteredMultiwatcher creates a new watcher that can observe
+// changes to the entities in an underlying store manager that
+// match the given filter. When an entity changes so that it no
+// longer matches the filter, the watcher reports it as removed;
+// when an entity changes so that it starts matching the filter,
+// the watcher reports it as if it had just been added.
+func NewFilteredMultiwatcher(all *storeManager, filter multiwatcher.Filter) *Multiwatcher {
+	return &Multiwatcher{
+		all:     all,
+		filter:  &filter,
+		visible: make(map[multiwatcher.EntityId]bool),
+	}
+}
+
+// filterChanges returns the given changes, restricted to those that
+// the watcher should report. It is called by the storeManager
+// goroutine.
+func (w *Multiwatcher) filterChanges(changes []multiwatcher.Delta) []multiwatcher.Delta {
+	if w.filter == nil {
+		return changes
+	}
+	filtered := changes[:0]
+	for _, delta := range changes {
+		id := delta.Entity.EntityId()
+		switch {
+		case delta.Removed:
+			if !w.visible[id] {
+				continue
+			}
+			delete(w.visible, id)
+		case w.filter.Match(delta.Entity):
+			w.visible[id] = true
+		case w.visible[id]:
+			// The entity has moved out of the filter, so as far
+			// as the watcher is concerned it has been removed.
+			delete(w.visible, id)
+			delta.Removed = true
+		default:
+			continue
+		}
+		filtered = append(filtered, delta)
+	}
+	return filtered
+}
+
 // Stop stops the watcher.
 func (w *Multiwatcher) Stop() error {
 	select {
@@ -234,8 +288,15 @@ func (sm *storeManager) respond() {
 		if len(changes) == 0 {
 			continue
 		}
-		req.changes = changes
+		changes = w.filterChanges(changes)
 		w.revno = sm.all.latestRevno
+		if len(changes) == 0 {
+			// None of the changes are of interest to the
+			// watcher, so leave its request outstanding.
+			sm.seen(revno)
+			continue
+		}
+		req.changes = changes
 		req.reply <- true
 		if req := req.next; req == nil {
 			// Last request for this watcher.
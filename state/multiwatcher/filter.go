@@ -0,0 +1,159 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package multiwatcher
+
+import (
+	"fmt"
+
+	"github.com/juju/names"
+)
+
+// Kinds holds the kinds of all entities that may be reported
+// by a multiwatcher.
+var Kinds = []string{
+	"machine",
+	"service",
+	"unit",
+	"action",
+	"relation",
+	"annotation",
+	"block",
+	"storage",
+	"storageattachment",
+	"volume",
+	"volumeattachment",
+	"filesystem",
+	"filesystemattachment",
+}
+
+// Filter restricts the entities that a multiwatcher reports
+// changes to. The zero Filter matches all entities.
+type Filter struct {
+	// Kinds holds the entity kinds to match. If it is empty,
+	// entities of all kinds match.
+	Kinds []string `json:",omitempty"`
+
+	// Services and Machines hold the names of services and the
+	// ids of machines. If either is non-empty, only entities
+	// related to at least one of the given services or machines
+	// match; for example, a unit matches if its service or the
+	// machine it is assigned to is in the filter. Entities that
+	// are not related to any service or machine, such as blocks,
+	// do not match.
+	Services []string `json:",omitempty"`
+	Machines []string `json:",omitempty"`
+}
+
+// Validate checks that the filter holds only known entity kinds,
+// and valid service names and machine ids.
+func (f *Filter) Validate() error {
+	for _, kind := range f.Kinds {
+		if !isKnownKind(kind) {
+			return fmt.Errorf("unknown entity kind %q", kind)
+		}
+	}
+	for _, service := range f.Services {
+		if !names.IsValidService(service) {
+			return fmt.Errorf("invalid service name %q", service)
+		}
+	}
+	for _, machine := range f.Machines {
+		if !names.IsValidMachine(machine) {
+			return fmt.Errorf("invalid machine id %q", machine)
+		}
+	}
+	return nil
+}
+
+func isKnownKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the given entity matches the filter.
+func (f *Filter) Match(info EntityInfo) bool {
+	if len(f.Kinds) > 0 && !contains(f.Kinds, info.EntityId().Kind) {
+		return false
+	}
+	if len(f.Services) == 0 && len(f.Machines) == 0 {
+		return true
+	}
+	services, machines := related(info)
+	for _, service := range services {
+		if contains(f.Services, service) {
+			return true
+		}
+	}
+	for _, machine := range machines {
+		if contains(f.Machines, machine) {
+			return true
+		}
+	}
+	return false
+}
+
+// related returns the names of the services and the ids of the
+// machines that the given entity is related to.
+func related(info EntityInfo) (services, machines []string) {
+	addUnit := func(unitName string) {
+		if service, err := names.UnitService(unitName); err == nil {
+			services = append(services, service)
+		}
+	}
+	addTag := func(tagString string) {
+		tag, err := names.ParseTag(tagString)
+		if err != nil {
+			return
+		}
+		switch tag := tag.(type) {
+		case names.ServiceTag:
+			services = append(services, tag.Id())
+		case names.UnitTag:
+			addUnit(tag.Id())
+		case names.MachineTag:
+			machines = append(machines, tag.Id())
+		}
+	}
+	switch info := info.(type) {
+	case *MachineInfo:
+		machines = append(machines, info.Id)
+	case *ServiceInfo:
+		services = append(services, info.Name)
+	case *UnitInfo:
+		services = append(services, info.Service)
+		if info.MachineId != "" {
+			machines = append(machines, info.MachineId)
+		}
+	case *ActionInfo:
+		addUnit(info.Receiver)
+	case *RelationInfo:
+		for _, ep := range info.Endpoints {
+			services = append(services, ep.ServiceName)
+		}
+	case *AnnotationInfo:
+		addTag(info.Tag)
+	case *StorageInfo:
+		addTag(info.OwnerTag)
+	case *StorageAttachmentInfo:
+		addUnit(info.UnitName)
+	case *VolumeAttachmentInfo:
+		machines = append(machines, info.MachineId)
+	case *FilesystemAttachmentInfo:
+		machines = append(machines, info.MachineId)
+	}
+	return services, machines
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
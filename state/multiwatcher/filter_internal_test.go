@@ -0,0 +1,101 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package multiwatcher
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type FilterSuite struct{}
+
+var _ = gc.Suite(&FilterSuite{})
+
+func (s *FilterSuite) TestMatchAll(c *gc.C) {
+	var filter Filter
+	c.Assert(filter.Match(&BlockInfo{Id: "0"}), jc.IsTrue)
+	c.Assert(filter.Match(&MachineInfo{Id: "0"}), jc.IsTrue)
+}
+
+func (s *FilterSuite) TestMatchKinds(c *gc.C) {
+	filter := Filter{Kinds: []string{"unit", "volume"}}
+	c.Assert(filter.Match(&UnitInfo{Name: "wordpress/0"}), jc.IsTrue)
+	c.Assert(filter.Match(&VolumeInfo{Name: "0"}), jc.IsTrue)
+	c.Assert(filter.Match(&MachineInfo{Id: "0"}), jc.IsFalse)
+}
+
+func (s *FilterSuite) TestMatchServices(c *gc.C) {
+	filter := Filter{Services: []string{"wordpress"}}
+	for i, test := range []struct {
+		info  EntityInfo
+		match bool
+	}{
+		{&ServiceInfo{Name: "wordpress"}, true},
+		{&ServiceInfo{Name: "mysql"}, false},
+		{&UnitInfo{Name: "wordpress/0", Service: "wordpress"}, true},
+		{&UnitInfo{Name: "mysql/0", Service: "mysql"}, false},
+		{&ActionInfo{Id: "1", Receiver: "wordpress/0"}, true},
+		{&RelationInfo{Key: "wordpress:db mysql:server", Endpoints: []Endpoint{
+			{ServiceName: "wordpress"}, {ServiceName: "mysql"},
+		}}, true},
+		{&AnnotationInfo{Tag: "service-wordpress"}, true},
+		{&AnnotationInfo{Tag: "unit-wordpress-0"}, true},
+		{&AnnotationInfo{Tag: "machine-0"}, false},
+		{&StorageInfo{Id: "data/0", OwnerTag: "unit-wordpress-0"}, true},
+		{&StorageAttachmentInfo{StorageId: "data/0", UnitName: "wordpress/0"}, true},
+		{&MachineInfo{Id: "0"}, false},
+		{&BlockInfo{Id: "0"}, false},
+	} {
+		c.Logf("test %d: %#v", i, test.info)
+		c.Check(filter.Match(test.info), gc.Equals, test.match)
+	}
+}
+
+func (s *FilterSuite) TestMatchMachines(c *gc.C) {
+	filter := Filter{Machines: []string{"0"}}
+	for i, test := range []struct {
+		info  EntityInfo
+		match bool
+	}{
+		{&MachineInfo{Id: "0"}, true},
+		{&MachineInfo{Id: "1"}, false},
+		{&UnitInfo{Name: "wordpress/0", MachineId: "0"}, true},
+		{&UnitInfo{Name: "wordpress/1", MachineId: "1"}, false},
+		{&UnitInfo{Name: "wordpress/2"}, false},
+		{&AnnotationInfo{Tag: "machine-0"}, true},
+		{&VolumeAttachmentInfo{VolumeName: "0", MachineId: "0"}, true},
+		{&FilesystemAttachmentInfo{FilesystemName: "0", MachineId: "1"}, false},
+		{&ServiceInfo{Name: "wordpress"}, false},
+	} {
+		c.Logf("test %d: %#v", i, test.info)
+		c.Check(filter.Match(test.info), gc.Equals, test.match)
+	}
+}
+
+func (s *FilterSuite) TestMatchKindsAndServices(c *gc.C) {
+	filter := Filter{Kinds: []string{"unit"}, Services: []string{"wordpress"}}
+	c.Assert(filter.Match(&UnitInfo{Name: "wordpress/0", Service: "wordpress"}), jc.IsTrue)
+	c.Assert(filter.Match(&ServiceInfo{Name: "wordpress"}), jc.IsFalse)
+}
+
+func (s *FilterSuite) TestValidate(c *gc.C) {
+	for i, test := range []struct {
+		filter Filter
+		err    string
+	}{
+		{Filter{}, ""},
+		{Filter{Kinds: []string{"unit", "storage"}, Services: []string{"wordpress"}, Machines: []string{"0/lxc/1"}}, ""},
+		{Filter{Kinds: []string{"widget"}}, `unknown entity kind "widget"`},
+		{Filter{Services: []string{"Wordpress"}}, `invalid service name "Wordpress"`},
+		{Filter{Machines: []string{"machine-0"}}, `invalid machine id "machine-0"`},
+	} {
+		c.Logf("test %d", i)
+		err := test.filter.Validate()
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
@@ -99,6 +99,18 @@ func (d *Delta) UnmarshalJSON(data []byte) error {
 		d.Entity = new(AnnotationInfo)
 	case "block":
 		d.Entity = new(BlockInfo)
+	case "storage":
+		d.Entity = new(StorageInfo)
+	case "storageattachment":
+		d.Entity = new(StorageAttachmentInfo)
+	case "volume":
+		d.Entity = new(VolumeInfo)
+	case "volumeattachment":
+		d.Entity = new(VolumeAttachmentInfo)
+	case "filesystem":
+		d.Entity = new(FilesystemInfo)
+	case "filesystemattachment":
+		d.Entity = new(FilesystemAttachmentInfo)
 	default:
 		return fmt.Errorf("Unexpected entity name %q", entityKind)
 	}
@@ -309,3 +321,124 @@ const (
 	// BlockChange type identifies change blocks.
 	BlockChange BlockType = "BlockChange"
 )
+
+// StorageInfo holds the information about a storage instance
+// that is watched by StateMultiwatcher.
+type StorageInfo struct {
+	Id              string
+	Kind            string
+	Name            string
+	OwnerTag        string
+	Life            Life
+	AttachmentCount int
+}
+
+// EntityId returns the storage instance id.
+func (i *StorageInfo) EntityId() EntityId {
+	return EntityId{
+		Kind: "storage",
+		Id:   i.Id,
+	}
+}
+
+// StorageAttachmentInfo holds the information about the attachment
+// of a storage instance to a unit that is watched by StateMultiwatcher.
+type StorageAttachmentInfo struct {
+	StorageId string
+	UnitName  string
+	Life      Life
+}
+
+// EntityId returns the storage attachment id, made up of
+// the unit name and storage instance id.
+func (i *StorageAttachmentInfo) EntityId() EntityId {
+	return EntityId{
+		Kind: "storageattachment",
+		Id:   i.UnitName + "#" + i.StorageId,
+	}
+}
+
+// VolumeInfo holds the information about a volume
+// that is watched by StateMultiwatcher.
+type VolumeInfo struct {
+	Name        string
+	StorageId   string `json:",omitempty"`
+	Life        Life
+	Pool        string
+	Size        uint64
+	Provisioned bool
+	VolumeId    string `json:",omitempty"`
+	HardwareId  string `json:",omitempty"`
+	Persistent  bool
+	Zone        string `json:",omitempty"`
+}
+
+// EntityId returns the volume name.
+func (i *VolumeInfo) EntityId() EntityId {
+	return EntityId{
+		Kind: "volume",
+		Id:   i.Name,
+	}
+}
+
+// VolumeAttachmentInfo holds the information about the attachment
+// of a volume to a machine that is watched by StateMultiwatcher.
+type VolumeAttachmentInfo struct {
+	VolumeName  string
+	MachineId   string
+	Life        Life
+	Provisioned bool
+	DeviceName  string `json:",omitempty"`
+	BusAddress  string `json:",omitempty"`
+	ReadOnly    bool
+}
+
+// EntityId returns the volume attachment id, made up of
+// the machine id and volume name.
+func (i *VolumeAttachmentInfo) EntityId() EntityId {
+	return EntityId{
+		Kind: "volumeattachment",
+		Id:   i.MachineId + ":" + i.VolumeName,
+	}
+}
+
+// FilesystemInfo holds the information about a filesystem
+// that is watched by StateMultiwatcher.
+type FilesystemInfo struct {
+	Name         string
+	StorageId    string `json:",omitempty"`
+	VolumeName   string `json:",omitempty"`
+	Life         Life
+	Pool         string
+	Size         uint64
+	Provisioned  bool
+	FilesystemId string `json:",omitempty"`
+}
+
+// EntityId returns the filesystem name.
+func (i *FilesystemInfo) EntityId() EntityId {
+	return EntityId{
+		Kind: "filesystem",
+		Id:   i.Name,
+	}
+}
+
+// FilesystemAttachmentInfo holds the information about the attachment
+// of a filesystem to a machine that is watched by StateMultiwatcher.
+type FilesystemAttachmentInfo struct {
+	FilesystemName string
+	MachineId      string
+	Life           Life
+	Provisioned    bool
+	MountPoint     string `json:",omitempty"`
+	ReadOnly       bool
+}
+
+// EntityId returns the filesystem attachment id, made up of
+// the machine id and filesystem name.
+func (i *FilesystemAttachmentInfo) EntityId() EntityId {
+	return EntityId{
+		Kind: "filesystemattachment",
+		Id:   i.MachineId + ":" + i.FilesystemName,
+	}
+}
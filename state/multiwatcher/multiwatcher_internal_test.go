@@ -17,6 +17,12 @@ var (
 	_ EntityInfo = (*RelationInfo)(nil)
 	_ EntityInfo = (*AnnotationInfo)(nil)
 	_ EntityInfo = (*BlockInfo)(nil)
+	_ EntityInfo = (*StorageInfo)(nil)
+	_ EntityInfo = (*StorageAttachmentInfo)(nil)
+	_ EntityInfo = (*VolumeInfo)(nil)
+	_ EntityInfo = (*VolumeAttachmentInfo)(nil)
+	_ EntityInfo = (*FilesystemInfo)(nil)
+	_ EntityInfo = (*FilesystemAttachmentInfo)(nil)
 )
 
 type ConstantsSuite struct{}
@@ -639,6 +639,47 @@ func (*storeManagerSuite) TestRun(c *gc.C) {
 	}, "")
 }
 
+func (*storeManagerSuite) TestRunFiltered(c *gc.C) {
+	b := newTestBacking([]multiwatcher.EntityInfo{
+		&multiwatcher.MachineInfo{Id: "0"},
+		&multiwatcher.MachineInfo{Id: "1"},
+		&multiwatcher.ServiceInfo{Name: "wordpress"},
+		&multiwatcher.UnitInfo{Name: "wordpress/0", Service: "wordpress", MachineId: "0"},
+		&multiwatcher.UnitInfo{Name: "mysql/0", Service: "mysql", MachineId: "1"},
+	})
+	sm := newStoreManager(b)
+	defer func() {
+		c.Check(sm.Stop(), gc.IsNil)
+	}()
+	w := NewFilteredMultiwatcher(sm, multiwatcher.Filter{Machines: []string{"0"}})
+	checkNext(c, w, []multiwatcher.Delta{
+		{Entity: &multiwatcher.MachineInfo{Id: "0"}},
+		{Entity: &multiwatcher.UnitInfo{Name: "wordpress/0", Service: "wordpress", MachineId: "0"}},
+	}, "")
+
+	// Changes to entities that don't match the filter are not
+	// reported; entities that start matching the filter are
+	// reported as added.
+	b.updateEntity(&multiwatcher.MachineInfo{Id: "1", InstanceId: "i-1"})
+	b.updateEntity(&multiwatcher.UnitInfo{Name: "mysql/0", Service: "mysql", MachineId: "0"})
+	checkNext(c, w, []multiwatcher.Delta{
+		{Entity: &multiwatcher.UnitInfo{Name: "mysql/0", Service: "mysql", MachineId: "0"}},
+	}, "")
+
+	// Entities that stop matching the filter are reported as removed.
+	b.updateEntity(&multiwatcher.UnitInfo{Name: "wordpress/0", Service: "wordpress", MachineId: "1"})
+	checkNext(c, w, []multiwatcher.Delta{
+		{Removed: true, Entity: &multiwatcher.UnitInfo{Name: "wordpress/0", Service: "wordpress", MachineId: "1"}},
+	}, "")
+
+	// Removal of entities that were never reported is not reported.
+	b.deleteEntity(multiwatcher.EntityId{"machine", "1"})
+	b.deleteEntity(multiwatcher.EntityId{"machine", "0"})
+	checkNext(c, w, []multiwatcher.Delta{
+		{Removed: true, Entity: &multiwatcher.MachineInfo{Id: "0"}},
+	}, "")
+}
+
 func (*storeManagerSuite) TestMultiwatcherStop(c *gc.C) {
 	sm := newStoreManager(newTestBacking(nil))
 	defer func() {
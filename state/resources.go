@@ -0,0 +1,279 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state/storage"
+)
+
+// resourceDoc records a revision of a charm resource attached to a
+// service. The content itself is held in environment blob storage.
+type resourceDoc struct {
+	DocID       string    `bson:"_id"`
+	EnvUUID     string    `bson:"env-uuid"`
+	Service     string    `bson:"service"`
+	Name        string    `bson:"name"`
+	Revision    int       `bson:"revision"`
+	StoragePath string    `bson:"storagepath"`
+	Size        int64     `bson:"size"`
+	SHA256      string    `bson:"sha256"`
+	Uploaded    time.Time `bson:"uploaded"`
+	UploadedBy  string    `bson:"uploadedby"`
+}
+
+// Resource represents the current revision of a charm resource
+// attached to a service.
+type Resource struct {
+	st  *State
+	doc resourceDoc
+}
+
+// Service returns the name of the service the resource is attached to.
+func (r *Resource) Service() string {
+	return r.doc.Service
+}
+
+// Name returns the name of the resource, as declared by the charm.
+func (r *Resource) Name() string {
+	return r.doc.Name
+}
+
+// Revision returns the revision of the resource. It is incremented
+// every time new content is attached.
+func (r *Resource) Revision() int {
+	return r.doc.Revision
+}
+
+// Size returns the size of the resource content in bytes.
+func (r *Resource) Size() int64 {
+	return r.doc.Size
+}
+
+// SHA256 returns the hex-encoded SHA-256 hash of the resource content.
+func (r *Resource) SHA256() string {
+	return r.doc.SHA256
+}
+
+// Uploaded returns the time at which the resource content was attached.
+func (r *Resource) Uploaded() time.Time {
+	return r.doc.Uploaded
+}
+
+// UploadedBy returns the tag of the user who attached the resource
+// content.
+func (r *Resource) UploadedBy() names.UserTag {
+	return names.NewUserTag(r.doc.UploadedBy)
+}
+
+// resourceKey returns the key of the resource with the given name
+// attached to the given service.
+func resourceKey(service, name string) string {
+	return fmt.Sprintf("%s#%s", serviceGlobalKey(service), name)
+}
+
+func (st *State) resourceStorage() storage.Storage {
+	return storage.NewStorage(st.EnvironUUID(), st.MongoSession())
+}
+
+// Resource returns the resource with the given name attached to the
+// given service.
+func (st *State) Resource(service, name string) (*Resource, error) {
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+
+	var doc resourceDoc
+	err := resources.FindId(resourceKey(service, name)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("resource %q of service %q", name, service)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get resource %q of service %q", name, service)
+	}
+	return &Resource{st, doc}, nil
+}
+
+// ServiceResources returns the resources attached to the given service,
+// sorted by name.
+func (st *State) ServiceResources(service string) ([]*Resource, error) {
+	resources, closer := st.getCollection(resourcesC)
+	defer closer()
+
+	var docs []resourceDoc
+	if err := resources.Find(bson.D{{"service", service}}).Sort("name").All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get resources of service %q", service)
+	}
+	result := make([]*Resource, len(docs))
+	for i, doc := range docs {
+		result[i] = &Resource{st, doc}
+	}
+	return result, nil
+}
+
+// OpenResource returns the resource with the given name attached to the
+// given service, along with a reader for its content. The caller is
+// responsible for closing the reader.
+func (st *State) OpenResource(service, name string) (*Resource, io.ReadCloser, error) {
+	resource, err := st.Resource(service, name)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	reader, _, err := st.resourceStorage().Get(resource.doc.StoragePath)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "cannot read resource %q of service %q", name, service)
+	}
+	return resource, reader, nil
+}
+
+// SetResource stores the given content as a new revision of the named
+// resource of the given service, replacing any previous revision. The
+// service's resources version is incremented, so that its units can
+// react to the change.
+func (st *State) SetResource(service, name string, r io.Reader, size int64, sha256 string, uploadedBy names.UserTag) (_ *Resource, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set resource %q of service %q", name, service)
+
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	storagePath := fmt.Sprintf("resources/%s/%s-%s", service, name, uuid)
+	stor := st.resourceStorage()
+	if err := stor.Put(storagePath, r, size); err != nil {
+		return nil, errors.Annotate(err, "cannot store resource content")
+	}
+	defer func() {
+		if err != nil {
+			if err := stor.Remove(storagePath); err != nil {
+				logger.Errorf("cannot remove unrecorded resource content: %v", err)
+			}
+		}
+	}()
+
+	key := resourceKey(service, name)
+	doc := resourceDoc{
+		DocID:       st.docID(key),
+		EnvUUID:     st.EnvironUUID(),
+		Service:     service,
+		Name:        name,
+		StoragePath: storagePath,
+		Size:        size,
+		SHA256:      sha256,
+		Uploaded:    nowToTheSecond(),
+		UploadedBy:  uploadedBy.Name(),
+	}
+	var previous *Resource
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		svc, err := st.Service(service)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if svc.Life() != Alive {
+			return nil, errors.Errorf("service is not alive")
+		}
+		ops := []txn.Op{{
+			C:      servicesC,
+			Id:     svc.doc.DocID,
+			Assert: isAliveDoc,
+			Update: bson.D{{"$inc", bson.D{{"resourcesversion", 1}}}},
+		}}
+		previous, err = st.Resource(service, name)
+		switch {
+		case errors.IsNotFound(err):
+			doc.Revision = 1
+			return append(ops, txn.Op{
+				C:      resourcesC,
+				Id:     doc.DocID,
+				Assert: txn.DocMissing,
+				Insert: &doc,
+			}), nil
+		case err != nil:
+			return nil, errors.Trace(err)
+		}
+		doc.Revision = previous.doc.Revision + 1
+		return append(ops, txn.Op{
+			C:      resourcesC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"revision", previous.doc.Revision}},
+			Update: bson.D{{"$set", bson.D{
+				{"revision", doc.Revision},
+				{"storagepath", doc.StoragePath},
+				{"size", doc.Size},
+				{"sha256", doc.SHA256},
+				{"uploaded", doc.Uploaded},
+				{"uploadedby", doc.UploadedBy},
+			}}},
+		}), nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if previous != nil {
+		if err := stor.Remove(previous.doc.StoragePath); err != nil {
+			logger.Errorf("cannot remove replaced resource content: %v", err)
+		}
+	}
+	return &Resource{st, doc}, nil
+}
+
+// cleanupResourcesForRemovedService removes the resources attached to
+// the named service, along with their content.
+func (st *State) cleanupResourcesForRemovedService(service string) error {
+	resources, err := st.ServiceResources(service)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	stor := st.resourceStorage()
+	for _, resource := range resources {
+		ops := []txn.Op{{
+			C:      resourcesC,
+			Id:     resource.doc.DocID,
+			Assert: bson.D{{"revision", resource.doc.Revision}},
+			Remove: true,
+		}}
+		if err := st.runTransaction(ops); err != nil && err != txn.ErrAborted {
+			return errors.Annotatef(err, "cannot remove resource %q", resource.Name())
+		}
+		if err := stor.Remove(resource.doc.StoragePath); err != nil && !errors.IsNotFound(err) {
+			return errors.Annotatef(err, "cannot remove content of resource %q", resource.Name())
+		}
+	}
+	return nil
+}
+
+// ResourcesVersion returns a number that is incremented every time a
+// resource attached to the service changes.
+func (s *Service) ResourcesVersion() int {
+	return s.doc.ResourcesVersion
+}
+
+// ResourcesVersion returns the version of the service's resources that
+// the unit last reacted to.
+func (u *Unit) ResourcesVersion() int {
+	return u.doc.ResourcesVersion
+}
+
+// SetResourcesVersion records that the unit has reacted to the given
+// version of the service's resources.
+func (u *Unit) SetResourcesVersion(version int) error {
+	ops := []txn.Op{{
+		C:      unitsC,
+		Id:     u.doc.DocID,
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{{"resourcesversion", version}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return errors.Annotatef(onAbort(err, ErrDead), "cannot set resources version of unit %q", u)
+	}
+	u.doc.ResourcesVersion = version
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type ResourcesSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&ResourcesSuite{})
+
+func (s *ResourcesSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+}
+
+func (s *ResourcesSuite) setResource(c *gc.C, name, content string) *state.Resource {
+	resource, err := s.State.SetResource(
+		"wordpress", name, strings.NewReader(content), int64(len(content)), "sha-"+content, s.Owner,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	return resource
+}
+
+func (s *ResourcesSuite) assertContent(c *gc.C, name, content string) {
+	_, reader, err := s.State.OpenResource("wordpress", name)
+	c.Assert(err, jc.ErrorIsNil)
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, content)
+}
+
+func (s *ResourcesSuite) TestSetResource(c *gc.C) {
+	resource := s.setResource(c, "site", "hello")
+	c.Assert(resource.Service(), gc.Equals, "wordpress")
+	c.Assert(resource.Name(), gc.Equals, "site")
+	c.Assert(resource.Revision(), gc.Equals, 1)
+	c.Assert(resource.Size(), gc.Equals, int64(5))
+	c.Assert(resource.SHA256(), gc.Equals, "sha-hello")
+	c.Assert(resource.UploadedBy(), gc.Equals, s.Owner)
+	c.Assert(resource.Uploaded().IsZero(), jc.IsFalse)
+	s.assertContent(c, "site", "hello")
+
+	err := s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.service.ResourcesVersion(), gc.Equals, 1)
+}
+
+func (s *ResourcesSuite) TestSetResourceReplaces(c *gc.C) {
+	s.setResource(c, "site", "hello")
+	resource := s.setResource(c, "site", "goodbye")
+	c.Assert(resource.Revision(), gc.Equals, 2)
+	s.assertContent(c, "site", "goodbye")
+
+	resource, err := s.State.Resource("wordpress", "site")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resource.Revision(), gc.Equals, 2)
+	c.Assert(resource.SHA256(), gc.Equals, "sha-goodbye")
+
+	err = s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.service.ResourcesVersion(), gc.Equals, 2)
+}
+
+func (s *ResourcesSuite) TestServiceResources(c *gc.C) {
+	s.setResource(c, "tools", "hammer")
+	s.setResource(c, "site", "hello")
+	resources, err := s.State.ServiceResources("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, gc.HasLen, 2)
+	c.Assert(resources[0].Name(), gc.Equals, "site")
+	c.Assert(resources[1].Name(), gc.Equals, "tools")
+}
+
+func (s *ResourcesSuite) TestResourceNotFound(c *gc.C) {
+	_, err := s.State.Resource("wordpress", "site")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `resource "site" of service "wordpress" not found`)
+	_, _, err = s.State.OpenResource("wordpress", "site")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ResourcesSuite) TestSetResourceServiceNotAlive(c *gc.C) {
+	_, err := s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.SetResource("wordpress", "site", strings.NewReader("hello"), 5, "sha", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `cannot set resource "site" of service "wordpress": service is not alive`)
+}
+
+func (s *ResourcesSuite) TestUnitResourcesVersion(c *gc.C) {
+	s.setResource(c, "site", "hello")
+	err := s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// New units start out with the service's current resources.
+	unit, err := s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.ResourcesVersion(), gc.Equals, 1)
+
+	s.setResource(c, "site", "goodbye")
+	err = unit.SetResourcesVersion(2)
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.ResourcesVersion(), gc.Equals, 2)
+}
+
+func (s *ResourcesSuite) TestSetResourceWatchesService(c *gc.C) {
+	w := s.service.Watch()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	s.setResource(c, "site", "hello")
+	wc.AssertOneChange()
+}
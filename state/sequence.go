@@ -38,3 +38,41 @@ func (s *State) sequence(name string) (int, error) {
 	}
 	return result.Counter, nil
 }
+
+// setSequence sets the next value that the named sequence will return.
+func (s *State) setSequence(name string, value int) error {
+	sequences, closer := s.getCollection(sequenceC)
+	defer closer()
+	query := sequences.FindId(name)
+	set := mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"name":     name,
+				"env-uuid": s.EnvironUUID(),
+				"counter":  value,
+			},
+		},
+		Upsert: true,
+	}
+	_, err := query.Apply(set, &sequenceDoc{})
+	if err != nil {
+		return fmt.Errorf("cannot set %q sequence number: %v", name, err)
+	}
+	return nil
+}
+
+// sequences returns the next value of each of the environment's
+// sequences, keyed by name.
+func (s *State) sequences() (map[string]int, error) {
+	sequences, closer := s.getCollection(sequenceC)
+	defer closer()
+	var docs []sequenceDoc
+	if err := sequences.Find(nil).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot read sequences: %v", err)
+	}
+	result := make(map[string]int)
+	for _, doc := range docs {
+		result[doc.Name] = doc.Counter
+	}
+	return result, nil
+}
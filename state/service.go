@@ -46,6 +46,7 @@ type serviceDoc struct {
 	OwnerTag          string     `bson:"ownertag"`
 	TxnRevno          int64      `bson:"txn-revno"`
 	MetricCredentials []byte     `bson:"metric-credentials"`
+	ResourcesVersion  int        `bson:"resourcesversion"`
 }
 
 func newService(st *State, doc *serviceDoc) *Service {
@@ -208,6 +209,16 @@ func (s *Service) destroyOps() ([]txn.Op, error) {
 // removeOps returns the operations required to remove the service. Supplied
 // asserts will be included in the operation on the service document.
 func (s *Service) removeOps(asserts bson.D) []txn.Op {
+	// Resources are only attached to live services. A service that
+	// has never had one attached needs no resources cleanup, as long
+	// as none is attached before it is removed.
+	var resourcesOps []txn.Op
+	if s.doc.ResourcesVersion > 0 {
+		resourcesOps = append(resourcesOps, s.st.newCleanupOp(cleanupResourcesForRemovedService, s.doc.Name))
+	} else {
+		noResources := bson.D{{"resourcesversion", bson.D{{"$not", bson.D{{"$gt", 0}}}}}}
+		asserts = append(noResources, asserts...)
+	}
 	settingsDocID := s.st.docID(s.settingsKey())
 	ops := []txn.Op{
 		{
@@ -224,13 +235,14 @@ func (s *Service) removeOps(asserts bson.D) []txn.Op {
 			Id:     settingsDocID,
 			Remove: true,
 		},
+		charmDecRefOp(s.st, s.doc.CharmURL),
 		removeRequestedNetworksOp(s.st, s.globalKey()),
 		removeStorageConstraintsOp(s.globalKey()),
 		removeConstraintsOp(s.st, s.globalKey()),
 		annotationRemoveOp(s.st, s.globalKey()),
 		removeLeadershipSettingsOp(s.Tag().Id()),
 	}
-	return ops
+	return append(ops, resourcesOps...)
 }
 
 // IsExposed returns whether this service is exposed. The explicitly open
@@ -487,7 +499,7 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 	}
 
 	// Add or create a reference to the new settings doc.
-	incOp, err := settingsIncRefOp(s.st, s.doc.Name, ch.URL(), true)
+	incOps, err := settingsIncRefOps(s.st, s.doc.Name, ch.URL(), true)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -507,19 +519,17 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 		// Old settings shouldn't change (when they exist).
 		ops = append(ops, oldSettings.assertUnchangedOp())
 	}
-	ops = append(ops, []txn.Op{
-		// Create or replace new settings.
-		settingsOp,
-		// Increment the ref count.
-		incOp,
-		// Update the charm URL and force flag (if relevant).
-		{
-			C:      servicesC,
-			Id:     s.doc.DocID,
-			Assert: append(notDeadDoc, differentCharm...),
-			Update: bson.D{{"$set", bson.D{{"charmurl", ch.URL()}, {"forcecharm", force}}}},
-		},
-	}...)
+	// Create or replace new settings.
+	ops = append(ops, settingsOp)
+	// Increment the ref count.
+	ops = append(ops, incOps...)
+	// Update the charm URL and force flag (if relevant).
+	ops = append(ops, txn.Op{
+		C:      servicesC,
+		Id:     s.doc.DocID,
+		Assert: append(notDeadDoc, differentCharm...),
+		Update: bson.D{{"$set", bson.D{{"charmurl", ch.URL()}, {"forcecharm", force}}}},
+	})
 	// Add any extra peer relations that need creation.
 	newPeers := s.extraPeerRelations(ch.Meta())
 	peerOps, err := s.st.addPeerRelationsOps(s.doc.Name, newPeers)
@@ -695,6 +705,7 @@ func (s *Service) addUnitOps(principalName string, asserts bson.D) (string, []tx
 		Life:                   Alive,
 		Principal:              principalName,
 		StorageAttachmentCount: numStorageAttachments,
+		ResourcesVersion:       s.doc.ResourcesVersion,
 	}
 	now := time.Now()
 	agentStatusDoc := statusDoc{
@@ -1045,42 +1056,44 @@ func (s *Service) StorageConstraints() (map[string]StorageConstraints, error) {
 	return readStorageConstraints(s.st, s.globalKey())
 }
 
-// settingsIncRefOp returns an operation that increments the ref count
-// of the service settings identified by serviceName and curl. If
-// canCreate is false, a missing document will be treated as an error;
-// otherwise, it will be created with a ref count of 1.
-func settingsIncRefOp(st *State, serviceName string, curl *charm.URL, canCreate bool) (txn.Op, error) {
+// settingsIncRefOps returns a list of operations that increment the
+// ref count of the service settings identified by serviceName and
+// curl. If canCreate is false, a missing document will be treated as
+// an error; otherwise, it will be created with a ref count of 1, and
+// the ref count of the charm incremented.
+func settingsIncRefOps(st *State, serviceName string, curl *charm.URL, canCreate bool) ([]txn.Op, error) {
 	settingsrefs, closer := st.getCollection(settingsrefsC)
 	defer closer()
 
 	key := serviceSettingsKey(serviceName, curl)
 	if count, err := settingsrefs.FindId(key).Count(); err != nil {
-		return txn.Op{}, err
+		return nil, err
 	} else if count == 0 {
 		if !canCreate {
-			return txn.Op{}, errors.NotFoundf("service %q settings for charm %q", serviceName, curl)
+			return nil, errors.NotFoundf("service %q settings for charm %q", serviceName, curl)
 		}
-		return txn.Op{
+		return []txn.Op{{
 			C:      settingsrefsC,
 			Id:     st.docID(key),
 			Assert: txn.DocMissing,
 			Insert: settingsRefsDoc{
 				RefCount: 1,
 				EnvUUID:  st.EnvironUUID()},
-		}, nil
+		}, charmIncRefOp(st, curl)}, nil
 	}
-	return txn.Op{
+	return []txn.Op{{
 		C:      settingsrefsC,
 		Id:     st.docID(key),
 		Assert: txn.DocExists,
 		Update: bson.D{{"$inc", bson.D{{"refcount", 1}}}},
-	}, nil
+	}}, nil
 }
 
 // settingsDecRefOps returns a list of operations that decrement the
 // ref count of the service settings identified by serviceName and
 // curl. If the ref count is set to zero, the appropriate setting and
-// ref count documents will both be deleted.
+// ref count documents will both be deleted, and the ref count of the
+// charm decremented.
 func settingsDecRefOps(st *State, serviceName string, curl *charm.URL) ([]txn.Op, error) {
 	settingsrefs, closer := st.getCollection(settingsrefsC)
 	defer closer()
@@ -1103,7 +1116,7 @@ func settingsDecRefOps(st *State, serviceName string, curl *charm.URL) ([]txn.Op
 			C:      settingsC,
 			Id:     docID,
 			Remove: true,
-		}}, nil
+		}, charmDecRefOp(st, curl)}, nil
 	}
 	return []txn.Op{{
 		C:      settingsrefsC,
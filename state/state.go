@@ -31,6 +31,7 @@ import (
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state/leadership"
 	"github.com/juju/juju/state/lease"
+	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/state/presence"
 	"github.com/juju/juju/state/watcher"
 	"github.com/juju/juju/version"
@@ -292,12 +293,24 @@ func (st *State) MongoSession() *mgo.Session {
 type closeFunc func()
 
 func (st *State) Watch() *Multiwatcher {
+	return NewMultiwatcher(st.allWatcherManager())
+}
+
+// WatchFiltered returns a watcher that reports changes to
+// the entities in the environment that match the given filter.
+func (st *State) WatchFiltered(filter multiwatcher.Filter) *Multiwatcher {
+	return NewFilteredMultiwatcher(st.allWatcherManager(), filter)
+}
+
+// allWatcherManager returns the store manager shared by
+// the state's multiwatchers, starting it if necessary.
+func (st *State) allWatcherManager() *storeManager {
 	st.mu.Lock()
+	defer st.mu.Unlock()
 	if st.allManager == nil {
 		st.allManager = newStoreManager(newAllWatcherStateBacking(st))
 	}
-	st.mu.Unlock()
-	return NewMultiwatcher(st.allManager)
+	return st.allManager
 }
 
 func (st *State) EnvironConfig() (*config.Config, error) {
@@ -1142,6 +1155,7 @@ func (st *State) AddService(
 				RefCount: 1,
 				EnvUUID:  st.EnvironUUID()},
 		},
+		charmIncRefOp(st, ch.URL()),
 		{
 			C:      servicesC,
 			Id:     serviceID,
@@ -1160,6 +1174,9 @@ func (st *State) AddService(
 		if err := checkEnvLife(st); err != nil {
 			return nil, errors.Trace(err)
 		}
+		if _, err := st.Charm(ch.URL()); err != nil {
+			return nil, errors.Trace(err)
+		}
 		return nil, errors.Errorf("service already exists")
 	} else if err != nil {
 		return nil, errors.Trace(err)
@@ -1808,10 +1825,11 @@ func (st *State) SetAdminMongoPassword(password string) error {
 }
 
 type stateServersDoc struct {
-	Id               string `bson:"_id"`
-	EnvUUID          string `bson:"env-uuid"`
-	MachineIds       []string
-	VotingMachineIds []string
+	Id                 string `bson:"_id"`
+	EnvUUID            string `bson:"env-uuid"`
+	MachineIds         []string
+	VotingMachineIds   []string
+	RemovingMachineIds []string `bson:",omitempty"`
 }
 
 // StateServerInfo holds information about currently
@@ -1831,6 +1849,12 @@ type StateServerInfo struct {
 	// configured to run a state server and to have a vote
 	// in peer election.
 	VotingMachineIds []string
+
+	// RemovingMachineIds holds the ids of state server machines
+	// which have been requested to be removed, but are still
+	// waiting for their vote to be removed from the replica set.
+	// It is a subset of MachineIds.
+	RemovingMachineIds []string
 }
 
 // StateServerInfo returns information about
@@ -1879,9 +1903,10 @@ func readRawStateServerInfo(session *mgo.Session) (*StateServerInfo, error) {
 	}
 
 	return &StateServerInfo{
-		EnvironmentTag:   names.NewEnvironTag(doc.EnvUUID),
-		MachineIds:       doc.MachineIds,
-		VotingMachineIds: doc.VotingMachineIds,
+		EnvironmentTag:     names.NewEnvironTag(doc.EnvUUID),
+		MachineIds:         doc.MachineIds,
+		VotingMachineIds:   doc.VotingMachineIds,
+		RemovingMachineIds: doc.RemovingMachineIds,
 	}, nil
 }
 
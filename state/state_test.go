@@ -3992,6 +3992,157 @@ func (s *StateSuite) TestEnsureAvailabilityRemovesUnavailableMachines(c *gc.C) {
 	c.Assert(m0.IsManager(), jc.IsFalse)
 }
 
+func (s *StateSuite) TestRemoveStateServerReplace(c *gc.C) {
+	changes, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Added, gc.HasLen, 3)
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return true, nil
+	})
+
+	changes, err = s.State.RemoveStateServer("2", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Demoted, gc.DeepEquals, []string{"2"})
+	c.Assert(changes.Added, gc.DeepEquals, []string{"3"})
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"0", "1", "3"}, nil)
+	info, err := s.State.StateServerInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.RemovingMachineIds, gc.DeepEquals, []string{"2"})
+
+	// While machine 2 still holds its vote, it is neither promoted
+	// again by EnsureAvailability nor removed.
+	m2, err := s.State.Machine("2")
+	c.Assert(err, jc.ErrorIsNil)
+	err = m2.SetHasVote(true)
+	c.Assert(err, jc.ErrorIsNil)
+	changes, err = s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Promoted, gc.HasLen, 0)
+	changes, err = s.State.RemoveStateServer("2", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Demoted, gc.DeepEquals, []string{"2"})
+	c.Assert(changes.Removed, gc.HasLen, 0)
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"0", "1", "3"}, nil)
+
+	// Once the peergrouper has removed its vote, the machine's
+	// JobManageEnviron job is removed.
+	err = m2.SetHasVote(false)
+	c.Assert(err, jc.ErrorIsNil)
+	changes, err = s.State.RemoveStateServer("2", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Removed, gc.DeepEquals, []string{"2"})
+	c.Assert(changes.Added, gc.HasLen, 0)
+	s.assertStateServerInfo(c, []string{"0", "1", "3"}, []string{"0", "1", "3"}, nil)
+	info, err = s.State.StateServerInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.RemovingMachineIds, gc.HasLen, 0)
+	err = m2.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m2.IsManager(), jc.IsFalse)
+}
+
+func (s *StateSuite) TestRemoveStateServerAgainLeavesStartingReplacement(c *gc.C) {
+	changes, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Added, gc.HasLen, 3)
+	for _, id := range []string{"0", "1"} {
+		m, err := s.State.Machine(id)
+		c.Assert(err, jc.ErrorIsNil)
+		pinger, err := m.SetAgentPresence()
+		c.Assert(err, jc.ErrorIsNil)
+		defer pinger.Stop()
+		s.State.StartSync()
+		err = m.WaitAgentPresence(testing.LongWait)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	changes, err = s.State.RemoveStateServer("2", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Demoted, gc.DeepEquals, []string{"2"})
+	c.Assert(changes.Added, gc.DeepEquals, []string{"3"})
+	m2, err := s.State.Machine("2")
+	c.Assert(err, jc.ErrorIsNil)
+	err = m2.SetHasVote(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The agent of machine 3 has not started, but removing machine 2
+	// again neither demotes nor replaces it.
+	changes, err = s.State.RemoveStateServer("2", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes, gc.DeepEquals, state.StateServersChanges{Demoted: []string{"2"}})
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"0", "1", "3"}, nil)
+
+	err = m2.SetHasVote(false)
+	c.Assert(err, jc.ErrorIsNil)
+	changes, err = s.State.RemoveStateServer("2", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes, gc.DeepEquals, state.StateServersChanges{Removed: []string{"2"}})
+	s.assertStateServerInfo(c, []string{"0", "1", "3"}, []string{"0", "1", "3"}, nil)
+}
+
+func (s *StateSuite) TestRemoveStateServerReplacePromotes(c *gc.C) {
+	changes, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return m.Id() != "0", nil
+	})
+	changes, err = s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Demoted, gc.DeepEquals, []string{"0"})
+	m0, err := s.State.Machine("0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = m0.SetHasVote(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Machine 0 is available again and not voting, so it is promoted
+	// to replace machine 1.
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return true, nil
+	})
+	changes, err = s.State.RemoveStateServer("1", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Demoted, gc.DeepEquals, []string{"1"})
+	c.Assert(changes.Promoted, gc.DeepEquals, []string{"0"})
+	c.Assert(changes.Added, gc.HasLen, 0)
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"0", "2", "3"}, nil)
+}
+
+func (s *StateSuite) TestRemoveStateServerWithoutReplaceLeavingEvenCount(c *gc.C) {
+	_, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.RemoveStateServer("1", false, constraints.Value{}, "quantal", nil)
+	c.Assert(err, gc.ErrorMatches, "failed to remove state server machine 1: "+
+		"removing machine 1 would leave an even number of voting state servers; use replace instead")
+	s.assertStateServerInfo(c, []string{"0", "1", "2"}, []string{"0", "1", "2"}, nil)
+}
+
+func (s *StateSuite) TestRemoveStateServerNonVoting(c *gc.C) {
+	_, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return m.Id() != "0", nil
+	})
+	_, err = s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStateServerInfo(c, []string{"0", "1", "2", "3"}, []string{"1", "2", "3"}, nil)
+
+	// Machine 0 neither wants nor has a vote, so it is removed at once.
+	changes, err := s.State.RemoveStateServer("0", false, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Removed, gc.DeepEquals, []string{"0"})
+	s.assertStateServerInfo(c, []string{"1", "2", "3"}, []string{"1", "2", "3"}, nil)
+	info, err := s.State.StateServerInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.RemovingMachineIds, gc.HasLen, 0)
+}
+
+func (s *StateSuite) TestRemoveStateServerNotStateServer(c *gc.C) {
+	_, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.RemoveStateServer("42", true, constraints.Value{}, "quantal", nil)
+	c.Assert(err, gc.ErrorMatches, `failed to remove state server machine 42: state server machine "42" not found`)
+}
+
 func (s *StateSuite) TestEnsureAvailabilityMaintainsVoteList(c *gc.C) {
 	changes, err := s.State.EnsureAvailability(5, constraints.Value{}, "quantal", nil)
 	c.Assert(err, jc.ErrorIsNil)
@@ -6,6 +6,8 @@ package toolstorage
 import (
 	"io"
 
+	"gopkg.in/mgo.v2/txn"
+
 	"github.com/juju/juju/version"
 )
 
@@ -35,6 +37,14 @@ type Storage interface {
 	// Metadata returns the Metadata for the specified version
 	// if it exists, else an error satisfying errors.IsNotFound.
 	Metadata(v version.Binary) (Metadata, error)
+
+	// RemoveTools removes the metadata and tools tarball for the
+	// specified version, returning an error satisfying
+	// errors.IsNotFound if no such tools exist. The metadata is
+	// removed in the same transaction as the supplied assertions;
+	// if any of them fail, txn.ErrAborted is returned and nothing
+	// is removed.
+	RemoveTools(v version.Binary, asserts ...txn.Op) error
 }
 
 // StorageCloser extends the Storage interface with a Close method.
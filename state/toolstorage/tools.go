@@ -169,6 +169,43 @@ func (s *toolsStorage) AllMetadata() ([]Metadata, error) {
 	return list, nil
 }
 
+func (s *toolsStorage) RemoveTools(v version.Binary, asserts ...txn.Op) error {
+	var path string
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := s.toolsMetadata(v)
+		if err != nil {
+			return nil, err
+		}
+		if attempt > 0 && doc.Path == path && len(asserts) > 0 {
+			// The tools are unchanged, so it was one of the
+			// caller's assertions that failed.
+			return nil, txn.ErrAborted
+		}
+		path = doc.Path
+		ops := []txn.Op{{
+			C:      s.metadataCollection.Name,
+			Id:     doc.Id,
+			Assert: bson.D{{"path", path}},
+			Remove: true,
+		}}
+		return append(ops, asserts...), nil
+	}
+	if err := s.txnRunner.Run(buildTxn); err != nil {
+		if errors.IsNotFound(err) || err == txn.ErrAborted {
+			return err
+		}
+		return errors.Annotate(err, "cannot remove tools metadata")
+	}
+	if err := s.managedStorage.RemoveForEnvironment(s.envUUID, path); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Debugf("tools blob %q already removed", path)
+			return nil
+		}
+		return errors.Annotate(err, "cannot remove tools tarball")
+	}
+	return nil
+}
+
 type toolsMetadataDoc struct {
 	Id      string         `bson:"_id"`
 	Version version.Binary `bson:"version"`
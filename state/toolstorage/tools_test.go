@@ -19,6 +19,8 @@ import (
 	txntesting "github.com/juju/txn/testing"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
 
 	"github.com/juju/juju/state/toolstorage"
 	"github.com/juju/juju/testing"
@@ -325,6 +327,48 @@ func (s *ToolsSuite) TestAddToolsExcessiveContention(c *gc.C) {
 	s.assertTools(c, metadata[3], "3")
 }
 
+func (s *ToolsSuite) TestRemoveTools(c *gc.C) {
+	err := s.storage.RemoveTools(version.Current)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	metadata := toolstorage.Metadata{Version: version.Current, Size: 3, SHA256: "hash(abc)"}
+	err = s.storage.AddTools(strings.NewReader("abc"), metadata)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.RemoveTools(version.Current)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.storage.Metadata(version.Current)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	path := fmt.Sprintf("tools/%s-%s", metadata.Version, metadata.SHA256)
+	_, _, err = s.managedStorage.GetForEnvironment("my-uuid", path)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ToolsSuite) TestRemoveToolsAssertFails(c *gc.C) {
+	metadata := toolstorage.Metadata{Version: version.Current, Size: 3, SHA256: "hash(abc)"}
+	err := s.storage.AddTools(strings.NewReader("abc"), metadata)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.session.DB("catalogue").C("other").Insert(bson.D{{"_id", "x"}, {"value", 1}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.RemoveTools(version.Current, txn.Op{
+		C:      "other",
+		Id:     "x",
+		Assert: bson.D{{"value", 2}},
+	})
+	c.Assert(err, gc.Equals, txn.ErrAborted)
+	s.assertTools(c, metadata, "abc")
+}
+
+func (s *ToolsSuite) TestRemoveToolsMissingBlob(c *gc.C) {
+	s.addMetadataDoc(c, version.Current, 3, "hash(abc)", "path")
+	err := s.storage.RemoveTools(version.Current)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.storage.Metadata(version.Current)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *ToolsSuite) addMetadataDoc(c *gc.C, v version.Binary, size int64, hash, path string) {
 	doc := struct {
 		Id      string         `bson:"_id"`
@@ -21,6 +21,7 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state/presence"
 	"github.com/juju/juju/tools"
@@ -99,6 +100,7 @@ type unitDoc struct {
 	Life                   Life
 	TxnRevno               int64 `bson:"txn-revno"`
 	PasswordHash           string
+	ResourcesVersion       int `bson:"resourcesversion"`
 
 	// TODO(mue) No longer actively used, only in upgrades.go.
 	// To be removed later.
@@ -1017,21 +1019,19 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 		}
 
 		// Add a reference to the service settings for the new charm.
-		incOp, err := settingsIncRefOp(u.st, u.doc.Service, curl, false)
+		incOps, err := settingsIncRefOps(u.st, u.doc.Service, curl, false)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 
 		// Set the new charm URL.
 		differentCharm := bson.D{{"charmurl", bson.D{{"$ne", curl}}}}
-		ops := []txn.Op{
-			incOp,
-			{
-				C:      unitsC,
-				Id:     u.doc.DocID,
-				Assert: append(notDeadDoc, differentCharm...),
-				Update: bson.D{{"$set", bson.D{{"charmurl", curl}}}},
-			}}
+		ops := append(incOps, txn.Op{
+			C:      unitsC,
+			Id:     u.doc.DocID,
+			Assert: append(notDeadDoc, differentCharm...),
+			Update: bson.D{{"$set", bson.D{{"charmurl", curl}}}},
+		})
 		if u.doc.CharmURL != nil {
 			// Drop the reference to the old charm.
 			decOps, err := settingsDecRefOps(u.st, u.doc.Service, u.doc.CharmURL)
@@ -2000,6 +2000,9 @@ func (u *Unit) AddAction(name string, payload map[string]interface{}) (*Action,
 	if len(name) == 0 {
 		return nil, errors.New("no action name given")
 	}
+	if name == jujurun.ActionName {
+		return addJujuRunAction(u.st, u.Tag(), payload)
+	}
 	specs, err := u.ActionSpecs()
 	if err != nil {
 		return nil, err
@@ -1540,3 +1540,57 @@ func SetHostedEnvironCount(st *State) error {
 
 	return st.runTransaction([]txn.Op{op})
 }
+
+// AddCharmRefCounts is an upgrade step that sets the ref count of each
+// charm to the number of service settings documents that refer to it.
+func AddCharmRefCounts(st *State) error {
+	settingsrefs, closer := st.getRawCollection(settingsrefsC)
+	defer closer()
+
+	refCounts := make(map[string]int)
+	var doc struct {
+		DocID   string `bson:"_id"`
+		EnvUUID string `bson:"env-uuid"`
+	}
+	iter := settingsrefs.Find(nil).Select(bson.D{{"_id", 1}, {"env-uuid", 1}}).Iter()
+	for iter.Next(&doc) {
+		// Service settings keys are "s#<service>#<charm url>".
+		parts := strings.SplitN(strings.TrimPrefix(doc.DocID, doc.EnvUUID+":"), "#", 3)
+		if len(parts) != 3 || parts[0] != "s" {
+			logger.Warningf("ignoring unexpected service settings ref %q", doc.DocID)
+			continue
+		}
+		refCounts[doc.EnvUUID+":"+parts[2]]++
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Annotate(err, "cannot read service settings refs")
+	}
+
+	charms, closer := st.getRawCollection(charmsC)
+	defer closer()
+	var ops []txn.Op
+	var cdoc struct {
+		DocID    string `bson:"_id"`
+		RefCount int    `bson:"refcount"`
+	}
+	iter = charms.Find(nil).Select(bson.D{{"_id", 1}, {"refcount", 1}}).Iter()
+	for iter.Next(&cdoc) {
+		refCount := refCounts[cdoc.DocID]
+		if cdoc.RefCount == refCount {
+			continue
+		}
+		ops = append(ops, txn.Op{
+			C:      charmsC,
+			Id:     cdoc.DocID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"refcount", refCount}}}},
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Annotate(err, "cannot read charms")
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return st.runRawTransaction(ops)
+}
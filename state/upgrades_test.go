@@ -3189,6 +3189,39 @@ func (s *upgradesSuite) TestSetHostedEnvironCountIdempotent(c *gc.C) {
 	c.Assert(HostedEnvironCount(c, s.state), gc.Equals, 3)
 }
 
+func (s *upgradesSuite) TestAddCharmRefCounts(c *gc.C) {
+	uuid := s.state.EnvironUUID()
+	charms, closer := s.state.getRawCollection(charmsC)
+	defer closer()
+	err := charms.Insert(
+		bson.M{"_id": uuid + ":local:quantal/dummy-1", "env-uuid": uuid},
+		bson.M{"_id": uuid + ":local:quantal/dummy-2", "env-uuid": uuid, "refcount": 5},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	settingsrefs, closer := s.state.getRawCollection(settingsrefsC)
+	defer closer()
+	err = settingsrefs.Insert(
+		bson.M{"_id": uuid + ":s#a#local:quantal/dummy-1", "env-uuid": uuid, "refcount": 2},
+		bson.M{"_id": uuid + ":s#b#local:quantal/dummy-1", "env-uuid": uuid, "refcount": 1},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = AddCharmRefCounts(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var doc charmDoc
+	err = charms.FindId(uuid + ":local:quantal/dummy-1").One(&doc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(doc.RefCount, gc.Equals, 2)
+	err = charms.FindId(uuid + ":local:quantal/dummy-2").One(&doc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(doc.RefCount, gc.Equals, 0)
+
+	// The step is idempotent.
+	err = AddCharmRefCounts(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 var index uint32
 
 // We can't use factory.MakeEnvironment due to an import cycle.
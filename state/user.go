@@ -43,6 +43,9 @@ func (st *State) AddUser(name, displayName, password, creator string) (*User, er
 	if !names.IsValidUserName(name) {
 		return nil, errors.Errorf("invalid user name %q", name)
 	}
+	if err := st.validatePassword(password); err != nil {
+		return nil, errors.Trace(err)
+	}
 	salt, err := utils.RandomSalt()
 	if err != nil {
 		return nil, err
@@ -173,6 +176,13 @@ type userDoc struct {
 	// It is really informational only as far as everyone except the
 	// api server is concerned.
 	LastLogin *time.Time `bson:"lastlogin"`
+	// FailedLogins, Lockouts and LockedUntil are likewise updated by
+	// the apiserver without using mgo.txn, to lock the user out after
+	// too many failed logins. They must never appear in any
+	// transaction asserts either.
+	FailedLogins int        `bson:"failedlogins,omitempty"`
+	Lockouts     int        `bson:"lockouts,omitempty"`
+	LockedUntil  *time.Time `bson:"lockeduntil,omitempty"`
 }
 
 // String returns "<name>@local" where <name> is the Name of the user.
@@ -264,8 +274,26 @@ func (u *User) UpdateLastLogin() error {
 	return nil
 }
 
-// SetPassword sets the password associated with the User.
+// SetPassword sets the password associated with the User. The password
+// must follow the password policy.
 func (u *User) SetPassword(password string) error {
+	if err := u.st.validatePassword(password); err != nil {
+		return errors.Trace(err)
+	}
+	return u.setPassword(password)
+}
+
+// validatePassword returns an error if the password does not follow
+// the password policy.
+func (st *State) validatePassword(password string) error {
+	policy, err := st.PasswordPolicy()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return policy.Validate(password)
+}
+
+func (u *User) setPassword(password string) error {
 	salt, err := utils.RandomSalt()
 	if err != nil {
 		return err
@@ -310,7 +338,7 @@ func (u *User) PasswordValid(password string) bool {
 		// fails because we will try again at the next request
 		logger.Debugf("User %s logged in with CompatSalt resetting password for new salt",
 			u.Name())
-		err := u.SetPassword(password)
+		err := u.setPassword(password)
 		if err != nil {
 			logger.Errorf("Cannot set resalted password for user %q", u.Name())
 		}
@@ -0,0 +1,231 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// UserGroup represents a named set of users. Sharing an environment
+// with a group gives each of its members access to the environment.
+// Like users, groups are not specific to any one environment.
+type UserGroup struct {
+	st  *State
+	doc userGroupDoc
+}
+
+type userGroupDoc struct {
+	DocID       string    `bson:"_id"`
+	Name        string    `bson:"name"`
+	CreatedBy   string    `bson:"createdby"`
+	DateCreated time.Time `bson:"datecreated"`
+
+	// Members holds the lower-cased canonical user names
+	// ("bob@local", "alice@example") of the group's members.
+	Members []string `bson:"members"`
+}
+
+// userGroupID returns the document id of the named group.
+func userGroupID(name string) string {
+	return strings.ToLower(name)
+}
+
+// memberID returns the name by which the given user is recorded as
+// a member of groups.
+func memberID(user names.UserTag) string {
+	return strings.ToLower(user.Username())
+}
+
+// Name returns the name of the group.
+func (g *UserGroup) Name() string {
+	return g.doc.Name
+}
+
+// CreatedBy returns the name of the user that created the group.
+func (g *UserGroup) CreatedBy() string {
+	return g.doc.CreatedBy
+}
+
+// DateCreated returns when the group was created in UTC.
+func (g *UserGroup) DateCreated() time.Time {
+	return g.doc.DateCreated.UTC()
+}
+
+// Members returns the users that belong to the group, sorted by name.
+func (g *UserGroup) Members() []names.UserTag {
+	members := make([]string, len(g.doc.Members))
+	copy(members, g.doc.Members)
+	sort.Strings(members)
+	result := make([]names.UserTag, len(members))
+	for i, member := range members {
+		result[i] = names.NewUserTag(member)
+	}
+	return result
+}
+
+// AddMember adds the given user to the group. Local users must exist;
+// adding an existing member is not an error.
+func (g *UserGroup) AddMember(user names.UserTag) error {
+	ops := []txn.Op{{
+		C:      userGroupsC,
+		Id:     g.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$addToSet", bson.D{{"members", memberID(user)}}}},
+	}}
+	if user.IsLocal() {
+		ops = append(ops, txn.Op{
+			C:      usersC,
+			Id:     strings.ToLower(user.Name()),
+			Assert: txn.DocExists,
+		})
+	}
+	err := g.st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if err := g.Refresh(); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.NotFoundf("user %q", user.Username())
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot add %q to group %q", user.Username(), g.Name())
+	}
+	return g.Refresh()
+}
+
+// RemoveMember removes the given user from the group. It returns
+// an error satisfying errors.IsNotFound if the user is not a member.
+func (g *UserGroup) RemoveMember(user names.UserTag) error {
+	member := memberID(user)
+	ops := []txn.Op{{
+		C:      userGroupsC,
+		Id:     g.doc.DocID,
+		Assert: bson.D{{"members", member}},
+		Update: bson.D{{"$pull", bson.D{{"members", member}}}},
+	}}
+	err := g.st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if err := g.Refresh(); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.NotFoundf("user %q in group %q", user.Username(), g.Name())
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot remove %q from group %q", user.Username(), g.Name())
+	}
+	return g.Refresh()
+}
+
+// Refresh refreshes information about the group from the state.
+func (g *UserGroup) Refresh() error {
+	var doc userGroupDoc
+	if err := g.st.getUserGroup(g.doc.Name, &doc); err != nil {
+		return errors.Trace(err)
+	}
+	g.doc = doc
+	return nil
+}
+
+// AddUserGroup adds a group of users with no members to the database.
+// Group names follow the same rules as local user names.
+func (st *State) AddUserGroup(name, creator string) (*UserGroup, error) {
+	if !names.IsValidUserName(name) {
+		return nil, errors.Errorf("invalid group name %q", name)
+	}
+	group := &UserGroup{
+		st: st,
+		doc: userGroupDoc{
+			DocID:       userGroupID(name),
+			Name:        name,
+			CreatedBy:   creator,
+			DateCreated: nowToTheSecond(),
+			Members:     []string{},
+		},
+	}
+	ops := []txn.Op{{
+		C:      userGroupsC,
+		Id:     group.doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &group.doc,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.AlreadyExistsf("group %q", name)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return group, nil
+}
+
+// getUserGroup fetches the group with the given name into the
+// provided userGroupDoc.
+func (st *State) getUserGroup(name string, doc *userGroupDoc) error {
+	groups, closer := st.getCollection(userGroupsC)
+	defer closer()
+
+	err := groups.FindId(userGroupID(name)).One(doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("group %q", name)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// DateCreated is inserted as UTC, but read out as local time. So we
+	// convert it back to UTC here.
+	doc.DateCreated = doc.DateCreated.UTC()
+	return nil
+}
+
+// UserGroup returns the group with the given name.
+func (st *State) UserGroup(name string) (*UserGroup, error) {
+	group := &UserGroup{st: st}
+	if err := st.getUserGroup(name, &group.doc); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return group, nil
+}
+
+// AllUserGroups returns all the groups, sorted by name.
+func (st *State) AllUserGroups() ([]*UserGroup, error) {
+	groups, closer := st.getCollection(userGroupsC)
+	defer closer()
+
+	var docs []userGroupDoc
+	if err := groups.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]*UserGroup, len(docs))
+	for i, doc := range docs {
+		result[i] = &UserGroup{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// userGroupNames returns the lower-cased names of the groups that the
+// given user is a member of.
+func (st *State) userGroupNames(user names.UserTag) ([]string, error) {
+	groups, closer := st.getCollection(userGroupsC)
+	defer closer()
+
+	var docs []struct {
+		DocID string `bson:"_id"`
+	}
+	query := groups.Find(bson.D{{"members", memberID(user)}}).Select(bson.D{{"_id", 1}})
+	if err := query.All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]string, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.DocID
+	}
+	return result, nil
+}
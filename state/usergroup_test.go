@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type UserGroupSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&UserGroupSuite{})
+
+func (s *UserGroupSuite) TestAddUserGroup(c *gc.C) {
+	now := state.NowToTheSecond()
+	group, err := s.State.AddUserGroup("Ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Name(), gc.Equals, "Ops")
+	c.Assert(group.CreatedBy(), gc.Equals, "admin")
+	c.Assert(group.DateCreated().Equal(now) || group.DateCreated().After(now), jc.IsTrue)
+	c.Assert(group.Members(), gc.HasLen, 0)
+
+	group, err = s.State.UserGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Name(), gc.Equals, "Ops")
+}
+
+func (s *UserGroupSuite) TestAddUserGroupTwice(c *gc.C) {
+	_, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddUserGroup("OPS", "admin")
+	c.Assert(err, gc.ErrorMatches, `group "OPS" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *UserGroupSuite) TestAddUserGroupInvalidName(c *gc.C) {
+	_, err := s.State.AddUserGroup("ops@local", "admin")
+	c.Assert(err, gc.ErrorMatches, `invalid group name "ops@local"`)
+}
+
+func (s *UserGroupSuite) TestUserGroupNotFound(c *gc.C) {
+	_, err := s.State.UserGroup("ops")
+	c.Assert(err, gc.ErrorMatches, `group "ops" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *UserGroupSuite) TestAllUserGroups(c *gc.C) {
+	for _, name := range []string{"ops", "dev", "qa"} {
+		_, err := s.State.AddUserGroup(name, "admin")
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	groups, err := s.State.AllUserGroups()
+	c.Assert(err, jc.ErrorIsNil)
+	var groupNames []string
+	for _, group := range groups {
+		groupNames = append(groupNames, group.Name())
+	}
+	c.Assert(groupNames, jc.DeepEquals, []string{"dev", "ops", "qa"})
+}
+
+func (s *UserGroupSuite) TestAddMember(c *gc.C) {
+	group, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	bob := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	remote := names.NewUserTag("Fred@remote")
+
+	err = group.AddMember(remote)
+	c.Assert(err, jc.ErrorIsNil)
+	err = group.AddMember(bob.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	// Adding an existing member is not an error.
+	err = group.AddMember(bob.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected := []names.UserTag{names.NewUserTag("bob@local"), names.NewUserTag("fred@remote")}
+	c.Assert(group.Members(), jc.DeepEquals, expected)
+
+	group, err = s.State.UserGroup("ops")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Members(), jc.DeepEquals, expected)
+}
+
+func (s *UserGroupSuite) TestAddMemberMissingLocalUser(c *gc.C) {
+	group, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	err = group.AddMember(names.NewLocalUserTag("ellie"))
+	c.Assert(err, gc.ErrorMatches, `user "ellie@local" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(group.Members(), gc.HasLen, 0)
+}
+
+func (s *UserGroupSuite) TestRemoveMember(c *gc.C) {
+	group, err := s.State.AddUserGroup("ops", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	remote := names.NewUserTag("fred@remote")
+	err = group.AddMember(remote)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = group.RemoveMember(remote)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(group.Members(), gc.HasLen, 0)
+
+	err = group.RemoveMember(remote)
+	c.Assert(err, gc.ErrorMatches, `user "fred@remote" in group "ops" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
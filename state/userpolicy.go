@@ -0,0 +1,214 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/environs/config"
+)
+
+// maxLoginLockout bounds how long repeated lockouts can lock a user
+// out for.
+const maxLoginLockout = 24 * time.Hour
+
+// PasswordPolicy holds the rules that the passwords of local users
+// must follow.
+type PasswordPolicy struct {
+	// MinLength holds the minimum length of a password.
+	MinLength int
+
+	// RequireComplexity holds whether a password must contain upper
+	// and lower case letters and digits.
+	RequireComplexity bool
+}
+
+// Validate returns an error if the password does not follow the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	if len(password) < p.MinLength {
+		return errors.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+	if !p.RequireComplexity {
+		return nil
+	}
+	var upper, lower, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !upper || !lower || !digit {
+		return errors.New("password must contain upper and lower case letters and digits")
+	}
+	return nil
+}
+
+// LoginPolicy holds the rules for locking out local users after
+// repeated failed logins.
+type LoginPolicy struct {
+	// FailureLimit holds the number of consecutive failed logins
+	// after which a user is locked out. Zero disables lockout.
+	FailureLimit int
+
+	// Lockout holds how long a user is first locked out for. Each
+	// further lockout before a successful login doubles this.
+	Lockout time.Duration
+}
+
+// lockoutDuration returns how long a user that has already been locked
+// out the given number of times is locked out for.
+func (p LoginPolicy) lockoutDuration(lockouts int) time.Duration {
+	lockout := p.Lockout
+	for i := 0; i < lockouts && lockout < maxLoginLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLoginLockout {
+		lockout = maxLoginLockout
+	}
+	return lockout
+}
+
+// PasswordPolicy returns the password policy for local users.
+func (st *State) PasswordPolicy() (PasswordPolicy, error) {
+	cfg, err := st.userPolicyConfig()
+	if err != nil {
+		return PasswordPolicy{}, errors.Trace(err)
+	}
+	return PasswordPolicy{
+		MinLength:         cfg.PasswordMinLength(),
+		RequireComplexity: cfg.PasswordComplexity(),
+	}, nil
+}
+
+// LoginPolicy returns the login lockout policy for local users.
+func (st *State) LoginPolicy() (LoginPolicy, error) {
+	cfg, err := st.userPolicyConfig()
+	if err != nil {
+		return LoginPolicy{}, errors.Trace(err)
+	}
+	return LoginPolicy{
+		FailureLimit: cfg.LoginFailureLimit(),
+		Lockout:      cfg.LoginLockout(),
+	}, nil
+}
+
+// userPolicyConfig returns the configuration of the state server
+// environment. Local users are not specific to any one environment, so
+// the policies that apply to them are taken from there.
+func (st *State) userPolicyConfig() (*config.Config, error) {
+	if st.EnvironUUID() == st.serverTag.Id() {
+		return st.EnvironConfig()
+	}
+	settings, closer := st.getRawCollection(settingsC)
+	defer closer()
+
+	attrs := map[string]interface{}{}
+	id := ensureEnvUUID(st.serverTag.Id(), environGlobalKey)
+	if err := settings.FindId(id).One(attrs); err != nil {
+		return nil, errors.Annotate(err, "cannot read state server environment settings")
+	}
+	cleanSettingsMap(attrs)
+	return config.New(config.NoDefaults, attrs)
+}
+
+// LockedUntil returns when the user's lockout after too many failed
+// logins ends in UTC, or nil if the user is not locked out.
+func (u *User) LockedUntil() *time.Time {
+	if u.doc.LockedUntil == nil || !time.Now().Before(*u.doc.LockedUntil) {
+		return nil
+	}
+	lockedUntil := u.doc.LockedUntil.UTC()
+	return &lockedUntil
+}
+
+// IsLockedOut reports whether the user is locked out after too many
+// failed logins.
+func (u *User) IsLockedOut() bool {
+	return u.LockedUntil() != nil
+}
+
+// RecordFailedLogin counts a failed login for the user, and locks the
+// user out if the policy's failure limit is reached.
+func (u *User) RecordFailedLogin(policy LoginPolicy) error {
+	if policy.FailureLimit <= 0 {
+		return nil
+	}
+	users, closer := u.st.getCollection(usersC)
+	defer closer()
+	// Failed logins are counted with a plain atomic update rather than a
+	// transaction: concurrent failures must all be counted, and running
+	// a transaction per failed login would let anyone guessing passwords
+	// grow the txn log. This is only sound because no transaction ever
+	// writes or asserts the failedlogins, lockouts or lockeduntil fields
+	// once the user document exists, so the two kinds of write never
+	// touch the same data.
+	usersW := users.Writeable()
+
+	var doc userDoc
+	change := mgo.Change{
+		Update:    bson.D{{"$inc", bson.D{{"failedlogins", 1}}}},
+		ReturnNew: true,
+	}
+	if _, err := usersW.Underlying().FindId(u.doc.DocID).Apply(change, &doc); err != nil {
+		return errors.Annotatef(err, "cannot record failed login for user %q", u.Name())
+	}
+	u.doc.FailedLogins = doc.FailedLogins
+	if doc.FailedLogins < policy.FailureLimit {
+		return nil
+	}
+
+	lockedUntil := time.Now().Add(policy.lockoutDuration(doc.Lockouts))
+	update := bson.D{
+		{"$set", bson.D{{"failedlogins", 0}, {"lockeduntil", lockedUntil}}},
+		{"$inc", bson.D{{"lockouts", 1}}},
+	}
+	if err := usersW.UpdateId(u.doc.DocID, update); err != nil {
+		return errors.Annotatef(err, "cannot lock out user %q", u.Name())
+	}
+	u.doc.FailedLogins = 0
+	u.doc.Lockouts = doc.Lockouts + 1
+	u.doc.LockedUntil = &lockedUntil
+	return nil
+}
+
+// ResetFailedLogins forgets the user's failed logins and lockouts
+// after a successful login.
+func (u *User) ResetFailedLogins() error {
+	if u.doc.FailedLogins == 0 && u.doc.Lockouts == 0 && u.doc.LockedUntil == nil {
+		return nil
+	}
+	return u.Unlock()
+}
+
+// Unlock ends any lockout of the user after too many failed logins, and
+// forgets the user's failed logins.
+func (u *User) Unlock() error {
+	users, closer := u.st.getCollection(usersC)
+	defer closer()
+	// The lockout fields are only ever written outside transactions;
+	// see RecordFailedLogin.
+	usersW := users.Writeable()
+
+	update := bson.D{
+		{"$set", bson.D{{"failedlogins", 0}, {"lockouts", 0}}},
+		{"$unset", bson.D{{"lockeduntil", nil}}},
+	}
+	if err := usersW.UpdateId(u.doc.DocID, update); err != nil {
+		return errors.Annotatef(err, "cannot unlock user %q", u.Name())
+	}
+	u.doc.FailedLogins = 0
+	u.doc.Lockouts = 0
+	u.doc.LockedUntil = nil
+	return nil
+}
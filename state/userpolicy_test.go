@@ -0,0 +1,188 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type UserPolicySuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&UserPolicySuite{})
+
+func (s *UserPolicySuite) TestPasswordPolicyValidate(c *gc.C) {
+	for i, test := range []struct {
+		policy   state.PasswordPolicy
+		password string
+		err      string
+	}{{
+		password: "",
+	}, {
+		policy:   state.PasswordPolicy{MinLength: 8},
+		password: "12345678",
+	}, {
+		policy:   state.PasswordPolicy{MinLength: 8},
+		password: "1234567",
+		err:      "password must be at least 8 characters long",
+	}, {
+		policy:   state.PasswordPolicy{RequireComplexity: true},
+		password: "Secret1",
+	}, {
+		policy:   state.PasswordPolicy{RequireComplexity: true},
+		password: "secret1",
+		err:      "password must contain upper and lower case letters and digits",
+	}, {
+		policy:   state.PasswordPolicy{RequireComplexity: true},
+		password: "Secret",
+		err:      "password must contain upper and lower case letters and digits",
+	}} {
+		c.Logf("test %d: %+v %q", i, test.policy, test.password)
+		err := test.policy.Validate(test.password)
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
+
+func (s *UserPolicySuite) TestPolicyDefaults(c *gc.C) {
+	passwordPolicy, err := s.State.PasswordPolicy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(passwordPolicy, gc.Equals, state.PasswordPolicy{})
+
+	loginPolicy, err := s.State.LoginPolicy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(loginPolicy, gc.Equals, state.LoginPolicy{FailureLimit: 5, Lockout: time.Minute})
+}
+
+func (s *UserPolicySuite) TestPolicyFromStateServerEnvironment(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-min-length": 10,
+		"login-failure-limit": 3,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+
+	passwordPolicy, err := st.PasswordPolicy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(passwordPolicy.MinLength, gc.Equals, 10)
+	loginPolicy, err := st.LoginPolicy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(loginPolicy.FailureLimit, gc.Equals, 3)
+}
+
+func (s *UserPolicySuite) TestSetPasswordFollowsPolicy(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-min-length": 10,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = user.SetPassword("short")
+	c.Assert(err, gc.ErrorMatches, "password must be at least 10 characters long")
+	c.Assert(user.PasswordValid("short"), jc.IsFalse)
+
+	err = user.SetPassword("long enough")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.PasswordValid("long enough"), jc.IsTrue)
+}
+
+func (s *UserPolicySuite) TestAddUserFollowsPolicy(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-complexity": true,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.AddUser("bob", "", "password", "admin")
+	c.Assert(err, gc.ErrorMatches, "password must contain upper and lower case letters and digits")
+	_, err = s.State.AddUser("bob", "", "Passw0rd", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *UserPolicySuite) TestRecordFailedLogin(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	policy := state.LoginPolicy{FailureLimit: 3, Lockout: time.Minute}
+	for i := 0; i < 2; i++ {
+		err := user.RecordFailedLogin(policy)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(user.IsLockedOut(), jc.IsFalse)
+	}
+
+	before := time.Now()
+	err := user.RecordFailedLogin(policy)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+	lockedUntil := user.LockedUntil()
+	c.Assert(lockedUntil.After(before.Add(59*time.Second)), jc.IsTrue)
+	c.Assert(lockedUntil.Before(before.Add(61*time.Second)), jc.IsTrue)
+}
+
+func (s *UserPolicySuite) TestRepeatedLockoutsDouble(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	policy := state.LoginPolicy{FailureLimit: 1, Lockout: time.Minute}
+	for i, expect := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		c.Logf("lockout %d", i)
+		before := time.Now()
+		err := user.RecordFailedLogin(policy)
+		c.Assert(err, jc.ErrorIsNil)
+		lockedUntil := user.LockedUntil()
+		c.Assert(lockedUntil, gc.NotNil)
+		c.Assert(lockedUntil.Sub(before) >= expect, jc.IsTrue)
+		c.Assert(lockedUntil.Sub(before) < expect+time.Second, jc.IsTrue)
+	}
+}
+
+func (s *UserPolicySuite) TestLockoutDisabled(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	for i := 0; i < 10; i++ {
+		err := user.RecordFailedLogin(state.LoginPolicy{Lockout: time.Minute})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+}
+
+func (s *UserPolicySuite) TestStateServerOwnerLockedOut(c *gc.C) {
+	env, err := s.State.StateServerEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	user, err := s.State.User(env.Owner())
+	c.Assert(err, jc.ErrorIsNil)
+	err = user.RecordFailedLogin(state.LoginPolicy{FailureLimit: 1, Lockout: time.Minute})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+}
+
+func (s *UserPolicySuite) TestUnlock(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	policy := state.LoginPolicy{FailureLimit: 1, Lockout: time.Minute}
+	err := user.RecordFailedLogin(policy)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+
+	err = user.Unlock()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+
+	// Unlocking forgets earlier lockouts, so the next one is not doubled.
+	before := time.Now()
+	err = user.RecordFailedLogin(policy)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.LockedUntil().Before(before.Add(61*time.Second)), jc.IsTrue)
+}
@@ -124,11 +124,13 @@ type VolumeInfo struct {
 	Pool       string `bson:"pool"`
 	VolumeId   string `bson:"volumeid"`
 	Persistent bool   `bson:"persistent"`
+	Zone       string `bson:"zone,omitempty"`
 }
 
 // VolumeAttachmentInfo describes information about a volume attachment.
 type VolumeAttachmentInfo struct {
 	DeviceName string `bson:"devicename,omitempty"`
+	BusAddress string `bson:"busaddress,omitempty"`
 	ReadOnly   bool   `bson:"read-only"`
 }
 
@@ -970,6 +972,14 @@ func validateVolumeInfoChange(newInfo, oldInfo VolumeInfo) error {
 			oldInfo.VolumeId, newInfo.VolumeId,
 		)
 	}
+	// Volumes provisioned before zones were recorded have no zone,
+	// so the zone may be set, but not subsequently changed.
+	if oldInfo.Zone != "" && newInfo.Zone != oldInfo.Zone {
+		return errors.Errorf(
+			"cannot change zone from %q to %q",
+			oldInfo.Zone, newInfo.Zone,
+		)
+	}
 	return nil
 }
 
@@ -236,6 +236,29 @@ func (s *VolumeStateSuite) TestSetVolumeInfoImmutable(c *gc.C) {
 	s.assertVolumeInfo(c, volumeTag, volumeInfoSet)
 }
 
+func (s *VolumeStateSuite) TestSetVolumeInfoZone(c *gc.C) {
+	_, u, storageTag := s.setupSingleStorage(c, "block", "loop-pool")
+	err := s.State.AssignUnit(u, state.AssignCleanEmpty)
+	c.Assert(err, jc.ErrorIsNil)
+	volume := s.storageInstanceVolume(c, storageTag)
+	volumeTag := volume.VolumeTag()
+
+	// A zone may be recorded for a volume provisioned without one.
+	volumeInfoSet := state.VolumeInfo{Size: 123, VolumeId: "vol-ume"}
+	err = s.State.SetVolumeInfo(volumeTag, volumeInfoSet)
+	c.Assert(err, jc.ErrorIsNil)
+	volumeInfoSet.Pool = "loop-pool"
+	volumeInfoSet.Zone = "az-1"
+	err = s.State.SetVolumeInfo(volumeTag, volumeInfoSet)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertVolumeInfo(c, volumeTag, volumeInfoSet)
+
+	// Once recorded, the zone may not change.
+	volumeInfoSet.Zone = "az-2"
+	err = s.State.SetVolumeInfo(volumeTag, volumeInfoSet)
+	c.Assert(err, gc.ErrorMatches, `cannot set info for volume "0/0": cannot change zone from "az-1" to "az-2"`)
+}
+
 func (s *VolumeStateSuite) TestWatchVolumeAttachment(c *gc.C) {
 	_, u, storageTag := s.setupSingleStorage(c, "block", "loop-pool")
 	err := s.State.AssignUnit(u, state.AssignCleanEmpty)
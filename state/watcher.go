@@ -1827,6 +1827,102 @@ func (w *cleanupWatcher) loop() (err error) {
 	}
 }
 
+// envDocsWatcher notifies of the UUIDs of environments whose documents
+// in a global collection, keyed by environment UUID, change.
+type envDocsWatcher struct {
+	commonWatcher
+	collection string
+	initialIds bson.D
+	out        chan []string
+}
+
+var _ Watcher = (*envDocsWatcher)(nil)
+
+// WatchEnvMigrations returns a StringsWatcher that notifies of the
+// UUIDs of environments whose migrations are created or change phase.
+// The initial event holds the UUIDs of all environments that have a
+// migration.
+func (st *State) WatchEnvMigrations() StringsWatcher {
+	return newEnvDocsWatcher(st, envMigrationsC, nil)
+}
+
+// WatchImportedEnvironments returns a StringsWatcher that notifies of
+// the UUIDs of environments whose documents change, such as when an
+// imported environment is activated. The initial event holds the UUIDs
+// of all environments that were imported by migrations.
+func (st *State) WatchImportedEnvironments() StringsWatcher {
+	return newEnvDocsWatcher(st, environmentsC, bson.D{{"imported", true}})
+}
+
+func newEnvDocsWatcher(st *State, collection string, initialIds bson.D) StringsWatcher {
+	w := &envDocsWatcher{
+		commonWatcher: commonWatcher{st: st},
+		collection:    collection,
+		initialIds:    initialIds,
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *envDocsWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+func (w *envDocsWatcher) initial() (set.Strings, error) {
+	coll, closer := w.st.getCollection(w.collection)
+	defer closer()
+
+	ids := make(set.Strings)
+	var doc struct {
+		Id string `bson:"_id"`
+	}
+	iter := coll.Find(w.initialIds).Select(bson.D{{"_id", 1}}).Iter()
+	for iter.Next(&doc) {
+		ids.Add(doc.Id)
+	}
+	return ids, iter.Close()
+}
+
+func (w *envDocsWatcher) loop() error {
+	in := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.collection, in)
+	defer w.st.watcher.UnwatchCollection(w.collection, in)
+
+	changes, err := w.initial()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			updates, ok := collect(ch, in, w.tomb.Dying())
+			if !ok {
+				return tomb.ErrDying
+			}
+			for id := range updates {
+				if id, ok := id.(string); ok {
+					changes.Add(id)
+				}
+			}
+			out = w.out
+		case out <- changes.SortedValues():
+			changes = make(set.Strings)
+			out = nil
+		}
+	}
+}
+
 // actionStatusWatcher is a StringsWatcher that filters notifications
 // to Action Id's that match the ActionReceiver and ActionStatus set
 // provided.
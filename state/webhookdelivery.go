@@ -0,0 +1,118 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// maxWebhookDeliveries is the number of webhook deliveries that are
+// recorded per environment. Older deliveries are discarded.
+var maxWebhookDeliveries = 100
+
+// WebhookDelivery records the outcome of posting an event to a
+// webhook URL.
+type WebhookDelivery struct {
+	// Event holds the type of the event that was posted.
+	Event string
+
+	// Entity holds the tag of the entity the event relates to.
+	Entity string
+
+	// URL holds the webhook URL that the event was posted to.
+	URL string
+
+	// Time holds the time at which the event occurred.
+	Time time.Time
+
+	// Attempts holds the number of times delivery was attempted. It
+	// is zero if the event was dropped because too many events were
+	// already waiting for delivery to the webhook.
+	Attempts int
+
+	// Delivered reports whether the event was accepted by the
+	// webhook.
+	Delivered bool
+
+	// Error holds the error from the final delivery attempt,
+	// if the event was not delivered.
+	Error string
+}
+
+type webhookDeliveryDoc struct {
+	DocID     string    `bson:"_id"`
+	EnvUUID   string    `bson:"env-uuid"`
+	Seq       int       `bson:"seq"`
+	Event     string    `bson:"event"`
+	Entity    string    `bson:"entity"`
+	URL       string    `bson:"url"`
+	Time      time.Time `bson:"time"`
+	Attempts  int       `bson:"attempts"`
+	Delivered bool      `bson:"delivered"`
+	Error     string    `bson:"error,omitempty"`
+}
+
+// AddWebhookDelivery records the outcome of a webhook delivery. Only
+// the most recent deliveries are kept.
+func (st *State) AddWebhookDelivery(d WebhookDelivery) error {
+	seq, err := st.sequence("webhookdelivery")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	deliveries, closer := st.getCollection(webhookDeliveriesC)
+	defer closer()
+
+	deliveriesW := deliveries.Writeable()
+	err = deliveriesW.Insert(&webhookDeliveryDoc{
+		DocID:     st.docID(strconv.Itoa(seq)),
+		EnvUUID:   st.EnvironUUID(),
+		Seq:       seq,
+		Event:     d.Event,
+		Entity:    d.Entity,
+		URL:       d.URL,
+		Time:      d.Time.UTC(),
+		Attempts:  d.Attempts,
+		Delivered: d.Delivered,
+		Error:     d.Error,
+	})
+	if err != nil {
+		return errors.Annotate(err, "cannot record webhook delivery")
+	}
+	_, err = deliveriesW.RemoveAll(bson.D{
+		{"seq", bson.D{{"$lte", seq - maxWebhookDeliveries}}},
+	})
+	if err != nil {
+		return errors.Annotate(err, "cannot prune webhook deliveries")
+	}
+	return nil
+}
+
+// WebhookDeliveries returns the recorded webhook deliveries for the
+// environment, most recent first.
+func (st *State) WebhookDeliveries() ([]WebhookDelivery, error) {
+	deliveries, closer := st.getCollection(webhookDeliveriesC)
+	defer closer()
+
+	var docs []webhookDeliveryDoc
+	if err := deliveries.Find(nil).Sort("-seq").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get webhook deliveries")
+	}
+	results := make([]WebhookDelivery, len(docs))
+	for i, doc := range docs {
+		results[i] = WebhookDelivery{
+			Event:     doc.Event,
+			Entity:    doc.Entity,
+			URL:       doc.URL,
+			Time:      doc.Time.UTC(),
+			Attempts:  doc.Attempts,
+			Delivered: doc.Delivered,
+			Error:     doc.Error,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type WebhookDeliverySuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&WebhookDeliverySuite{})
+
+func (s *WebhookDeliverySuite) TestNoDeliveries(c *gc.C) {
+	deliveries, err := s.State.WebhookDeliveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deliveries, gc.HasLen, 0)
+}
+
+func (s *WebhookDeliverySuite) TestAddWebhookDelivery(c *gc.C) {
+	now := time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC)
+	delivered := state.WebhookDelivery{
+		Event:     "unit-error",
+		Entity:    "unit-wordpress-0",
+		URL:       "https://example.com/hook",
+		Time:      now,
+		Attempts:  1,
+		Delivered: true,
+	}
+	failed := state.WebhookDelivery{
+		Event:    "machine-error",
+		Entity:   "machine-0",
+		URL:      "https://example.com/hook",
+		Time:     now.Add(time.Minute),
+		Attempts: 5,
+		Error:    "500 Internal Server Error",
+	}
+	err := s.State.AddWebhookDelivery(delivered)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.AddWebhookDelivery(failed)
+	c.Assert(err, jc.ErrorIsNil)
+
+	deliveries, err := s.State.WebhookDeliveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deliveries, jc.DeepEquals, []state.WebhookDelivery{failed, delivered})
+}
+
+func (s *WebhookDeliverySuite) TestWebhookDeliveriesPruned(c *gc.C) {
+	s.PatchValue(state.MaxWebhookDeliveries, 3)
+	for i := 0; i < 5; i++ {
+		err := s.State.AddWebhookDelivery(state.WebhookDelivery{
+			Event:  "unit-error",
+			Entity: fmt.Sprintf("unit-wordpress-%d", i),
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	deliveries, err := s.State.WebhookDeliveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deliveries, gc.HasLen, 3)
+	for i, d := range deliveries {
+		c.Check(d.Entity, gc.Equals, fmt.Sprintf("unit-wordpress-%d", 4-i))
+	}
+}
+
+func (s *WebhookDeliverySuite) TestWebhookDeliveriesPerEnvironment(c *gc.C) {
+	err := s.State.AddWebhookDelivery(state.WebhookDelivery{Event: "unit-error"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	otherState := s.Factory.MakeEnvironment(c, nil)
+	defer otherState.Close()
+	deliveries, err := otherState.WebhookDeliveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(deliveries, gc.HasLen, 0)
+}
@@ -30,6 +30,11 @@ type BlockDevice struct {
 	// name, as the hardware ID is immutable.
 	HardwareId string `yaml:"hardwareid,omitempty"`
 
+	// BusAddress is the bus address of the block device, in the
+	// form "<bus>@<address>" (e.g. "scsi@5:0.0.1"). Not all block
+	// devices have a known bus address, so BusAddress may be empty.
+	BusAddress string `yaml:"busaddress,omitempty"`
+
 	// Size is the size of the block device, in MiB.
 	Size uint64 `yaml:"size"`
 
@@ -30,6 +30,11 @@ type VolumeInfo struct {
 	// Persistent reflects whether the volume is destroyed with the
 	// machine to which it is attached.
 	Persistent bool
+
+	// Zone is the availability zone in which the volume resides, if
+	// the provider has availability zones. A volume may only be
+	// attached to instances in the same zone.
+	Zone string
 }
 
 // VolumeAttachment identifies and describes machine-specific volume
@@ -56,6 +61,12 @@ type VolumeAttachmentInfo struct {
 	// field must be left blank.
 	DeviceName string
 
+	// BusAddress is the bus address at which the volume is exposed
+	// on the machine, in the same form as the BusAddress of a block
+	// device (e.g. "scsi@5:0.0.1"). Where the device name may change,
+	// but the bus address is known, BusAddress should be set.
+	BusAddress string
+
 	// ReadOnly signifies whether the volume is read only or writable.
 	ReadOnly bool
 }
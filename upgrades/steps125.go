@@ -45,6 +45,13 @@ func stateStepsFor125() []Step {
 				return addInstanceTags(env, machines)
 			},
 		},
+		&upgradeStep{
+			description: "add ref counts to charms",
+			targets:     []Target{DatabaseMaster},
+			run: func(context Context) error {
+				return state.AddCharmRefCounts(context.State())
+			},
+		},
 	}
 }
 
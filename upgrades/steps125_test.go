@@ -28,6 +28,7 @@ func (s *steps125Suite) TestStateStepsFor125(c *gc.C) {
 	expected := []string{
 		"set hosted environment count to number of hosted environments",
 		"tag machine instances",
+		"add ref counts to charms",
 	}
 	assertStateSteps(c, version.MustParse("1.25.0"), expected)
 }
@@ -6,6 +6,7 @@ package apiaddressupdater
 import (
 	"fmt"
 
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 
 	"github.com/juju/juju/api/watcher"
@@ -15,6 +16,12 @@ import (
 
 var logger = loggo.GetLogger("juju.worker.apiaddressupdater")
 
+// ErrServersReplaced is returned by the worker when none of the API
+// addresses it last set remain, as happens when an environment is
+// migrated to another system. The agent's API connection is to a
+// server that is no longer listed, and should be reopened.
+var ErrServersReplaced = errors.New("API servers replaced")
+
 // APIAddressUpdater is responsible for propagating API addresses.
 //
 // In practice, APIAddressUpdater is used by a machine agent to watch
@@ -22,12 +29,16 @@ var logger = loggo.GetLogger("juju.worker.apiaddressupdater")
 type APIAddressUpdater struct {
 	addresser APIAddresser
 	setter    APIAddressSetter
+
+	// current holds the addresses most recently set.
+	current [][]network.HostPort
 }
 
 // APIAddresser is an interface that is provided to NewAPIAddressUpdater
 // which can be used to watch for API address changes.
 type APIAddresser interface {
 	APIHostPorts() ([][]network.HostPort, error)
+	CACert() (string, error)
 	WatchAPIHostPorts() (watcher.NotifyWatcher, error)
 }
 
@@ -37,6 +48,16 @@ type APIAddressSetter interface {
 	SetAPIHostPorts(servers [][]network.HostPort) error
 }
 
+// APIDetailsSetter may be implemented by an APIAddressSetter that can
+// also record the CA certificate used to verify the API servers, which
+// changes when an environment is migrated to another system. The
+// addresses and certificate are set together, so that an agent is
+// never left with addresses it cannot verify.
+type APIDetailsSetter interface {
+	APIAddressSetter
+	SetAPIDetails(servers [][]network.HostPort, caCert string) error
+}
+
 // NewAPIAddressUpdater returns a worker.Worker that watches for changes to
 // API addresses and then sets them on the APIAddressSetter.
 func NewAPIAddressUpdater(addresser APIAddresser, setter APIAddressSetter) worker.Worker {
@@ -55,6 +76,10 @@ func (c *APIAddressUpdater) Handle(_ <-chan struct{}) error {
 	if err != nil {
 		return fmt.Errorf("error getting addresses: %v", err)
 	}
+	caCert, err := c.addresser.CACert()
+	if err != nil {
+		return fmt.Errorf("error getting CA certificate: %v", err)
+	}
 	// Filter out any LXC bridge addresses. See LP bug #1416928.
 	hpsToSet := make([][]network.HostPort, 0, len(addresses))
 	for _, hostPorts := range addresses {
@@ -72,13 +97,40 @@ func (c *APIAddressUpdater) Handle(_ <-chan struct{}) error {
 			hpsToSet = append(hpsToSet, hps)
 		}
 	}
-	if err := c.setter.SetAPIHostPorts(hpsToSet); err != nil {
+	if setter, ok := c.setter.(APIDetailsSetter); ok && caCert != "" {
+		err = setter.SetAPIDetails(hpsToSet, caCert)
+	} else {
+		err = c.setter.SetAPIHostPorts(hpsToSet)
+	}
+	if err != nil {
 		return fmt.Errorf("error setting addresses: %v", err)
 	}
 	logger.Infof("API addresses updated to %q", hpsToSet)
+	replaced := len(c.current) > 0 && len(hpsToSet) > 0 && !anyHostPortIn(c.current, hpsToSet)
+	c.current = hpsToSet
+	if replaced {
+		return ErrServersReplaced
+	}
 	return nil
 }
 
+// anyHostPortIn returns whether any of the old addresses are also
+// in the updated ones.
+func anyHostPortIn(old, updated [][]network.HostPort) bool {
+	for _, oldHostPorts := range old {
+		for _, oldHostPort := range oldHostPorts {
+			for _, newHostPorts := range updated {
+				for _, newHostPort := range newHostPorts {
+					if oldHostPort.Value == newHostPort.Value && oldHostPort.Port == newHostPort.Port {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (c *APIAddressUpdater) TearDown() error {
 	return nil
 }
@@ -46,6 +46,16 @@ func (s *apiAddressSetter) SetAPIHostPorts(servers [][]network.HostPort) error {
 	return s.err
 }
 
+type apiDetailsSetter struct {
+	apiAddressSetter
+	caCerts chan string
+}
+
+func (s *apiDetailsSetter) SetAPIDetails(servers [][]network.HostPort, caCert string) error {
+	s.caCerts <- caCert
+	return s.SetAPIHostPorts(servers)
+}
+
 func (s *APIAddressUpdaterSuite) TestStartStop(c *gc.C) {
 	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
 	worker := apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), &apiAddressSetter{})
@@ -75,6 +85,32 @@ func (s *APIAddressUpdaterSuite) TestAddressInitialUpdate(c *gc.C) {
 	}
 }
 
+func (s *APIAddressUpdaterSuite) TestCACertInitialUpdate(c *gc.C) {
+	setter := &apiDetailsSetter{
+		apiAddressSetter: apiAddressSetter{servers: make(chan [][]network.HostPort, 1)},
+		caCerts:          make(chan string, 1),
+	}
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	worker := apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), setter)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	// SetAPIDetails should be called with the addresses and the
+	// certificate served by the environment.
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetAPIDetails to be called")
+	case caCert := <-setter.caCerts:
+		c.Assert(caCert, gc.Equals, s.State.CACert())
+	}
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetAPIHostPorts to be called")
+	case servers := <-setter.servers:
+		c.Assert(servers, gc.HasLen, 0)
+	}
+}
+
 func (s *APIAddressUpdaterSuite) TestAddressChange(c *gc.C) {
 	setter := &apiAddressSetter{servers: make(chan [][]network.HostPort, 1)}
 	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
@@ -104,6 +140,56 @@ func (s *APIAddressUpdaterSuite) TestAddressChange(c *gc.C) {
 	}
 }
 
+func (s *APIAddressUpdaterSuite) TestServersReplaced(c *gc.C) {
+	initialServers := [][]network.HostPort{
+		network.NewHostPorts(1234, "10.0.0.1"),
+	}
+	err := s.State.SetAPIHostPorts(initialServers)
+	c.Assert(err, jc.ErrorIsNil)
+
+	setter := &apiAddressSetter{servers: make(chan [][]network.HostPort, 1)}
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	worker := apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), setter)
+	defer worker.Kill()
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetAPIHostPorts to be called initially")
+	case servers := <-setter.servers:
+		c.Assert(servers, gc.DeepEquals, initialServers)
+	}
+
+	// Adding a server is an ordinary change.
+	updatedServers := [][]network.HostPort{
+		network.NewHostPorts(1234, "10.0.0.1"),
+		network.NewHostPorts(1234, "10.0.0.2"),
+	}
+	err = s.State.SetAPIHostPorts(updatedServers)
+	c.Assert(err, jc.ErrorIsNil)
+	s.BackingState.StartSync()
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetAPIHostPorts to be called after update")
+	case servers := <-setter.servers:
+		c.Assert(servers, gc.DeepEquals, updatedServers)
+	}
+
+	// Replacing all the servers stops the worker, once the new
+	// addresses have been set.
+	replacementServers := [][]network.HostPort{
+		network.NewHostPorts(1234, "10.0.1.1"),
+	}
+	err = s.State.SetAPIHostPorts(replacementServers)
+	c.Assert(err, jc.ErrorIsNil)
+	s.BackingState.StartSync()
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetAPIHostPorts to be called after replacement")
+	case servers := <-setter.servers:
+		c.Assert(servers, gc.DeepEquals, replacementServers)
+	}
+	c.Assert(worker.Wait(), gc.Equals, apiaddressupdater.ErrServersReplaced)
+}
+
 func (s *APIAddressUpdaterSuite) TestLXCBridgeAddressesFiltering(c *gc.C) {
 	lxcFakeNetConfig := filepath.Join(c.MkDir(), "lxc-net")
 	netConf := []byte(`
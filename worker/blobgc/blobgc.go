@@ -0,0 +1,99 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobgc
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.blobgc")
+
+const (
+	// DefaultInterval is how often the worker looks for unused
+	// charm archives and tools.
+	DefaultInterval = time.Hour
+
+	// DefaultGracePeriod is how long a charm archive or tools
+	// tarball must have been continuously unused before it is
+	// removed.
+	DefaultGracePeriod = 24 * time.Hour
+)
+
+// Collector defines the interface for types capable of finding and
+// removing unused charm archives and tools.
+type Collector interface {
+	FindGarbage() ([]state.Garbage, error)
+	RemoveGarbage(state.Garbage) error
+}
+
+// New returns a worker which periodically looks for unused charm
+// archives and tools, and removes those that have been unused for
+// at least the grace period.
+func New(collector Collector, interval, gracePeriod time.Duration) worker.Worker {
+	return worker.NewSimpleWorker(func(stopCh <-chan struct{}) error {
+		// firstSeen records when each item was first found to be
+		// unused. It is kept in memory only, so the grace period
+		// starts again whenever the worker is restarted.
+		firstSeen := make(map[string]time.Time)
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				if err := collect(collector, firstSeen, gracePeriod); err != nil {
+					return errors.Annotate(err, "garbage collection failed, blobgc stopping")
+				}
+				timer.Reset(interval)
+			case <-stopCh:
+				return nil
+			}
+		}
+	})
+}
+
+func collect(collector Collector, firstSeen map[string]time.Time, gracePeriod time.Duration) error {
+	garbage, err := collector.FindGarbage()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	now := time.Now()
+	seen := make(map[string]bool)
+	for _, g := range garbage {
+		key := g.Key()
+		seen[key] = true
+		t, ok := firstSeen[key]
+		if !ok {
+			logger.Debugf("found unused %s %q", g.Kind, g.Id)
+			firstSeen[key] = now
+			continue
+		}
+		if now.Sub(t) < gracePeriod {
+			continue
+		}
+		err := collector.RemoveGarbage(g)
+		if errors.IsNotFound(err) {
+			logger.Debugf("%s %q is in use again", g.Kind, g.Id)
+		} else if err != nil {
+			logger.Errorf("%v", err)
+			continue
+		} else {
+			logger.Infof("removed unused %s %q (%d bytes)", g.Kind, g.Id, g.Size)
+		}
+		delete(firstSeen, key)
+	}
+	// Forget anything that has been used again since it was last
+	// seen, so its grace period starts afresh.
+	for key := range firstSeen {
+		if !seen[key] {
+			delete(firstSeen, key)
+		}
+	}
+	return nil
+}
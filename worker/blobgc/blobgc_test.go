@@ -0,0 +1,137 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobgc_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/blobgc"
+)
+
+type BlobGCSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&BlobGCSuite{})
+
+var (
+	unusedCharm = state.Garbage{Kind: state.GarbageCharm, EnvUUID: "uuid", Id: "cs:quantal/dummy-1", Size: 10}
+	unusedTools = state.Garbage{Kind: state.GarbageTools, Id: "1.2.3-quantal-amd64", Size: 20}
+)
+
+func (s *BlobGCSuite) TestRemovesAfterGracePeriod(c *gc.C) {
+	collector := newFakeCollector(unusedCharm, unusedTools)
+	w := blobgc.New(collector, 10*time.Millisecond, 0)
+	defer w.Kill()
+
+	removed := make(map[string]bool)
+	for len(removed) < 2 {
+		select {
+		case g := <-collector.removeCh:
+			removed[g.Key()] = true
+		case <-time.After(testing.LongWait):
+			c.Fatal("timed out waiting for removal")
+		}
+	}
+	c.Assert(removed, jc.DeepEquals, map[string]bool{
+		unusedCharm.Key(): true,
+		unusedTools.Key(): true,
+	})
+}
+
+func (s *BlobGCSuite) TestKeepsWithinGracePeriod(c *gc.C) {
+	collector := newFakeCollector(unusedCharm)
+	w := blobgc.New(collector, 10*time.Millisecond, time.Hour)
+	defer w.Kill()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-collector.findCh:
+		case <-time.After(testing.LongWait):
+			c.Fatal("timed out waiting for garbage to be found")
+		}
+	}
+	select {
+	case g := <-collector.removeCh:
+		c.Fatalf("unexpected removal of %v", g)
+	case <-time.After(testing.ShortWait):
+	}
+}
+
+func (s *BlobGCSuite) TestFindError(c *gc.C) {
+	collector := newFakeCollector()
+	collector.findErr = errors.New("boom")
+	w := blobgc.New(collector, time.Minute, 0)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "garbage collection failed, blobgc stopping: boom")
+}
+
+func (s *BlobGCSuite) TestStops(c *gc.C) {
+	success := make(chan bool)
+	check := func() {
+		w := blobgc.New(newFakeCollector(), time.Minute, time.Hour)
+		w.Kill()
+		c.Assert(w.Wait(), jc.ErrorIsNil)
+		success <- true
+	}
+	go check()
+
+	select {
+	case <-success:
+	case <-time.After(testing.LongWait):
+		c.Fatal("timed out waiting for worker to stop")
+	}
+}
+
+func newFakeCollector(garbage ...state.Garbage) *fakeCollector {
+	return &fakeCollector{
+		garbage:  garbage,
+		findCh:   make(chan bool, 100),
+		removeCh: make(chan state.Garbage, 100),
+	}
+}
+
+type fakeCollector struct {
+	mu       sync.Mutex
+	garbage  []state.Garbage
+	findErr  error
+	findCh   chan bool
+	removeCh chan state.Garbage
+}
+
+// FindGarbage implements the blobgc.Collector interface.
+func (f *fakeCollector) FindGarbage() ([]state.Garbage, error) {
+	if f.findErr != nil {
+		return nil, f.findErr
+	}
+	select {
+	case f.findCh <- true:
+	default:
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]state.Garbage(nil), f.garbage...), nil
+}
+
+// RemoveGarbage implements the blobgc.Collector interface.
+func (f *fakeCollector) RemoveGarbage(g state.Garbage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var remaining []state.Garbage
+	for _, existing := range f.garbage {
+		if existing.Key() != g.Key() {
+			remaining = append(remaining, existing)
+		}
+	}
+	f.garbage = remaining
+	f.removeCh <- g
+	return nil
+}
@@ -8,6 +8,7 @@ package diskmanager
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
 	"regexp"
@@ -42,6 +43,7 @@ func listBlockDevices() ([]storage.BlockDevice, error) {
 		"FSTYPE",     // filesystem type
 		"TYPE",       // device type
 		"MOUNTPOINT", // moint point
+		"HCTL",       // SCSI host:channel:target:lun
 	}
 
 	logger.Tracef("executing lsblk")
@@ -86,6 +88,8 @@ func listBlockDevices() ([]storage.BlockDevice, error) {
 				deviceType = pair[2]
 			case "MOUNTPOINT":
 				dev.MountPoint = pair[2]
+			case "HCTL":
+				dev.BusAddress = scsiBusAddress(pair[2])
 			default:
 				logger.Debugf("unexpected field from lsblk: %q", pair[1])
 			}
@@ -155,3 +159,20 @@ var blockDeviceInUse = func(dev storage.BlockDevice) (bool, error) {
 	}
 	return false, err
 }
+
+// scsiBusAddress returns the bus address of a SCSI block device,
+// given its address in the host:channel:target:lun form reported by
+// lsblk. An empty string is returned for devices that are not SCSI
+// devices, or whose address cannot be parsed.
+func scsiBusAddress(hctl string) string {
+	parts := strings.Split(hctl, ":")
+	if len(parts) != 4 {
+		return ""
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return ""
+		}
+	}
+	return fmt.Sprintf("scsi@%s:%s.%s.%s", parts[0], parts[1], parts[2], parts[3])
+}
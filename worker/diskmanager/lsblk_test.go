@@ -71,6 +71,30 @@ EOF`)
 	}})
 }
 
+func (s *ListBlockDevicesSuite) TestListBlockDevicesBusAddress(c *gc.C) {
+	testing.PatchExecutable(c, s, "lsblk", `#!/bin/bash --norc
+cat <<EOF
+KNAME="sda" SIZE="240057409536" LABEL="" UUID="" TYPE="disk" HCTL="0:0:0:0"
+KNAME="sdc" SIZE="32017047552" LABEL="" UUID="" TYPE="disk" HCTL="5:0:0:1"
+KNAME="loop0" SIZE="1024" LABEL="" UUID="" TYPE="loop" HCTL=""
+EOF`)
+
+	devices, err := diskmanager.ListBlockDevices()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(devices, jc.SameContents, []storage.BlockDevice{{
+		DeviceName: "sda",
+		BusAddress: "scsi@0:0.0.0",
+		Size:       228936,
+	}, {
+		DeviceName: "sdc",
+		BusAddress: "scsi@5:0.0.1",
+		Size:       30533,
+	}, {
+		DeviceName: "loop0",
+		Size:       0, // truncated
+	}})
+}
+
 func (s *ListBlockDevicesSuite) TestListBlockDevicesLsblkError(c *gc.C) {
 	testing.PatchExecutableThrowError(c, s, "lsblk", 123)
 	devices, err := diskmanager.ListBlockDevices()
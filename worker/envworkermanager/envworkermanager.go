@@ -42,6 +42,8 @@ func NewEnvWorkerManager(
 // funcs. It mainly exists to support testing.
 type InitialState interface {
 	WatchEnvironments() state.StringsWatcher
+	WatchEnvMigrations() state.StringsWatcher
+	WatchImportedEnvironments() state.StringsWatcher
 	ForEnviron(names.EnvironTag) (*state.State, error)
 	GetEnvironment(names.EnvironTag) (*state.Environment, error)
 	EnvironUUID() string
@@ -79,18 +81,28 @@ func (m *envWorkerManager) loop() error {
 	}()
 	w := m.st.WatchEnvironments()
 	defer w.Stop()
+	mw := m.st.WatchEnvMigrations()
+	defer mw.Stop()
+	iw := m.st.WatchImportedEnvironments()
+	defer iw.Stop()
 	for {
+		var uuids []string
 		select {
-		case uuids := <-w.Changes():
+		case uuids = <-w.Changes():
 			// One or more environments have changed.
-			for _, uuid := range uuids {
-				if err := m.envHasChanged(uuid); err != nil {
-					return errors.Trace(err)
-				}
-			}
+		case uuids = <-mw.Changes():
+			// One or more environment migrations have changed.
+		case uuids = <-iw.Changes():
+			// One or more imported environments may have been
+			// activated.
 		case <-m.tomb.Dying():
 			return tomb.ErrDying
 		}
+		for _, uuid := range uuids {
+			if err := m.envHasChanged(uuid); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 }
 
@@ -149,5 +161,8 @@ func (m *envWorkerManager) isEnvAlive(tag names.EnvironTag) (bool, error) {
 	} else if err != nil {
 		return false, errors.Annotatef(err, "error loading environment %s", tag.Id())
 	}
-	return env.Life() == state.Alive, nil
+	// A migrated environment is managed by another system, as is
+	// one being imported until its migration succeeds, so their
+	// workers must not run here.
+	return env.Life() == state.Alive && !env.Migrated() && !env.Importing(), nil
 }
@@ -16,6 +16,7 @@ import (
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
 	statetesting "github.com/juju/juju/state/testing"
 	"github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
@@ -111,6 +112,75 @@ func (s *suite) TestStopsWorkersWhenEnvGoesAway(c *gc.C) {
 	}
 }
 
+func (s *suite) TestStopsWorkersWhenEnvMigrated(c *gc.C) {
+	m := envworkermanager.NewEnvWorkerManager(s.State, s.startEnvWorker)
+	defer m.Kill()
+	s.seeRunnersStart(c, 1)
+
+	otherState := s.makeEnvironment(c)
+	runner := s.seeRunnersStart(c, 1)[0]
+
+	migration, err := otherState.CreateEnvMigration(state.EnvMigrationSpec{
+		InitiatedBy: names.NewUserTag("admin"),
+		TargetInfo: state.MigrationTargetInfo{
+			Addrs:    []string{"10.0.0.1:17070"},
+			CACert:   testing.CACert,
+			AuthTag:  names.NewUserTag("admin"),
+			Password: "secret",
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	for _, phase := range []state.MigrationPhase{
+		state.MigrationImport,
+		state.MigrationRedirect,
+		state.MigrationValidation,
+		state.MigrationSuccess,
+	} {
+		err := migration.SetPhase(phase)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.State.StartSync()
+	select {
+	case <-runner.tomb.Dying():
+		c.Fatal("runner should not die before the migration is done")
+	case <-time.After(testing.ShortWait):
+	}
+
+	err = migration.SetPhase(state.MigrationDone)
+	c.Assert(err, jc.ErrorIsNil)
+	s.State.StartSync()
+	select {
+	case <-runner.tomb.Dying():
+	case <-time.After(testing.LongWait):
+		c.Fatal("timed out waiting for runner to die")
+	}
+}
+
+func (s *suite) TestWorkersDoNotRunWhileEnvImporting(c *gc.C) {
+	m := envworkermanager.NewEnvWorkerManager(s.State, s.startEnvWorker)
+	defer m.Kill()
+	s.seeRunnersStart(c, 1)
+
+	otherState := s.makeEnvironment(c)
+	runner := s.seeRunnersStart(c, 1)[0]
+
+	// Importing a migrated environment stops its workers...
+	err := otherState.ImportMigration(&description.Model{UUID: otherState.EnvironUUID()})
+	c.Assert(err, jc.ErrorIsNil)
+	s.State.StartSync()
+	select {
+	case <-runner.tomb.Dying():
+	case <-time.After(testing.LongWait):
+		c.Fatal("timed out waiting for runner to die")
+	}
+
+	// ...until it is activated.
+	err = otherState.ActivateImportedEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	runner = s.seeRunnersStart(c, 1)[0]
+	c.Assert(runner.envUUID, gc.Equals, otherState.EnvironUUID())
+}
+
 func (s *suite) TestKillPropagates(c *gc.C) {
 	s.makeEnvironment(c)
 
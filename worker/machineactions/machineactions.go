@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package machineactions provides the worker that runs the actions
+// queued for a machine, such as the commands given to "juju run".
+package machineactions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"github.com/juju/utils/exec"
+
+	"github.com/juju/juju/api/machineactions"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/jujurun"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.machineactions")
+
+// Facade exposes the MachineActions API methods used by the worker.
+type Facade interface {
+	WatchActionNotifications() (watcher.StringsWatcher, error)
+	Action(tag names.ActionTag) (*machineactions.Action, error)
+	ActionBegin(tag names.ActionTag) error
+	ActionFinish(tag names.ActionTag, status string, results map[string]interface{}, message string) error
+}
+
+// Lock is the hook execution lock, which serialises the commands run
+// by the worker with hooks and other commands run on the machine.
+type Lock interface {
+	Lock(message string) error
+	Unlock() error
+}
+
+// NewMachineActionsWorker returns a worker that runs the actions
+// queued for the machine, holding the given lock while doing so.
+func NewMachineActionsWorker(facade Facade, lock Lock) worker.Worker {
+	return worker.NewStringsWorker(&handler{
+		facade: facade,
+		lock:   lock,
+	})
+}
+
+// handler implements worker.StringsWatchHandler.
+type handler struct {
+	facade Facade
+	lock   Lock
+}
+
+// SetUp is part of the worker.StringsWatchHandler interface.
+func (h *handler) SetUp() (watcher.StringsWatcher, error) {
+	return h.facade.WatchActionNotifications()
+}
+
+// TearDown is part of the worker.StringsWatchHandler interface.
+func (h *handler) TearDown() error {
+	return nil
+}
+
+// Handle is part of the worker.StringsWatchHandler interface.
+func (h *handler) Handle(actionIds []string) error {
+	for _, actionId := range actionIds {
+		if !names.IsValidAction(actionId) {
+			return errors.Errorf("invalid action id %q", actionId)
+		}
+		if err := h.handleAction(names.NewActionTag(actionId)); err != nil {
+			return errors.Annotatef(err, "running action %q", actionId)
+		}
+	}
+	return nil
+}
+
+func (h *handler) handleAction(tag names.ActionTag) error {
+	action, err := h.facade.Action(tag)
+	if params.IsCodeActionNotAvailable(err) || params.IsCodeNotFound(err) {
+		// The action was cancelled or has already been run.
+		logger.Debugf("action %q no longer available", tag.Id())
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if err := h.facade.ActionBegin(tag); err != nil {
+		return errors.Trace(err)
+	}
+	if action.Name() != jujurun.ActionName {
+		message := fmt.Sprintf("action %q not supported on machines", action.Name())
+		return h.facade.ActionFinish(tag, params.ActionFailed, nil, message)
+	}
+	commands, timeout, err := jujurun.ParseParams(action.Params())
+	if err != nil {
+		return h.facade.ActionFinish(tag, params.ActionFailed, nil, err.Error())
+	}
+
+	logger.Debugf("running action %q", tag.Id())
+	response, err := h.run(commands, timeout)
+	status, message := params.ActionCompleted, ""
+	if err != nil {
+		status, message = params.ActionFailed, err.Error()
+	}
+	var results map[string]interface{}
+	if response != nil {
+		results = jujurun.ActionResults(response)
+	}
+	return h.facade.ActionFinish(tag, status, results, message)
+}
+
+// run runs the given commands while holding the hook execution lock.
+func (h *handler) run(commands string, timeout time.Duration) (*exec.ExecResponse, error) {
+	if err := h.lock.Lock(jujurun.ActionName); err != nil {
+		return nil, errors.Annotate(err, "cannot acquire hook execution lock")
+	}
+	defer h.lock.Unlock()
+
+	command := exec.RunParams{Commands: commands}
+	if err := command.Run(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return jujurun.Wait(&command, timeout)
+}
@@ -0,0 +1,202 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machineactions_test
+
+import (
+	"sync"
+	stdtesting "testing"
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apimachineactions "github.com/juju/juju/api/machineactions"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/jujurun"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/machineactions"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type MachineActionsSuite struct {
+	coretesting.BaseSuite
+	facade *mockFacade
+	lock   *mockLock
+}
+
+var _ = gc.Suite(&MachineActionsSuite{})
+
+const (
+	actionId      = "c5ec8ad4-7bb5-4a2a-8e49-4e3b09bbd2a9"
+	otherActionId = "6a52b7bd-4c47-4e39-8d3a-a8acbfcbcbd7"
+)
+
+func (s *MachineActionsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.facade = &mockFacade{
+		watcher:  newMockStringsWatcher(),
+		actions:  make(map[string]*apimachineactions.Action),
+		finished: make(chan finishedAction, 10),
+	}
+	s.lock = &mockLock{}
+}
+
+func (s *MachineActionsSuite) waitFinished(c *gc.C) finishedAction {
+	select {
+	case finished := <-s.facade.finished:
+		return finished
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for action to finish")
+	}
+	panic("unreachable")
+}
+
+func (s *MachineActionsSuite) TestRunsJujuRunAction(c *gc.C) {
+	s.facade.actions[actionId] = apimachineactions.NewAction(
+		jujurun.ActionName, jujurun.ActionParams("echo hello; exit 2", coretesting.LongWait),
+	)
+	w := machineactions.NewMachineActionsWorker(s.facade, s.lock)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.facade.watcher.changes <- []string{actionId}
+	finished := s.waitFinished(c)
+	c.Assert(finished.tag, gc.Equals, names.NewActionTag(actionId))
+	c.Assert(finished.status, gc.Equals, params.ActionCompleted)
+	c.Assert(finished.message, gc.Equals, "")
+	response, err := jujurun.ParseResults(finished.results)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(response.Code, gc.Equals, 2)
+	c.Assert(string(response.Stdout), gc.Equals, "hello\n")
+
+	c.Assert(s.facade.begun, jc.DeepEquals, []string{actionId})
+	c.Assert(s.lock.calls(), jc.DeepEquals, []string{"Lock juju-run", "Unlock"})
+}
+
+func (s *MachineActionsSuite) TestTimedOutAction(c *gc.C) {
+	s.facade.actions[actionId] = apimachineactions.NewAction(
+		jujurun.ActionName, jujurun.ActionParams("echo hello; exec sleep 60", coretesting.ShortWait),
+	)
+	w := machineactions.NewMachineActionsWorker(s.facade, s.lock)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.facade.watcher.changes <- []string{actionId}
+	finished := s.waitFinished(c)
+	c.Assert(finished.status, gc.Equals, params.ActionFailed)
+	c.Assert(finished.message, gc.Equals, "commands timed out")
+}
+
+func (s *MachineActionsSuite) TestUnsupportedAction(c *gc.C) {
+	s.facade.actions[actionId] = apimachineactions.NewAction("snapshot", nil)
+	w := machineactions.NewMachineActionsWorker(s.facade, s.lock)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.facade.watcher.changes <- []string{actionId}
+	finished := s.waitFinished(c)
+	c.Assert(finished.status, gc.Equals, params.ActionFailed)
+	c.Assert(finished.message, gc.Equals, `action "snapshot" not supported on machines`)
+	c.Assert(s.lock.calls(), gc.HasLen, 0)
+}
+
+func (s *MachineActionsSuite) TestSkipsUnavailableAction(c *gc.C) {
+	s.facade.actions[otherActionId] = apimachineactions.NewAction(
+		jujurun.ActionName, jujurun.ActionParams("true", coretesting.LongWait),
+	)
+	w := machineactions.NewMachineActionsWorker(s.facade, s.lock)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.facade.watcher.changes <- []string{actionId, otherActionId}
+	finished := s.waitFinished(c)
+	c.Assert(finished.tag, gc.Equals, names.NewActionTag(otherActionId))
+	c.Assert(finished.status, gc.Equals, params.ActionCompleted)
+}
+
+type finishedAction struct {
+	tag     names.ActionTag
+	status  string
+	results map[string]interface{}
+	message string
+}
+
+type mockFacade struct {
+	watcher  *mockStringsWatcher
+	actions  map[string]*apimachineactions.Action
+	begun    []string
+	finished chan finishedAction
+}
+
+func (f *mockFacade) WatchActionNotifications() (watcher.StringsWatcher, error) {
+	return f.watcher, nil
+}
+
+func (f *mockFacade) Action(tag names.ActionTag) (*apimachineactions.Action, error) {
+	action, ok := f.actions[tag.Id()]
+	if !ok {
+		return nil, &params.Error{
+			Message: "action no longer available",
+			Code:    params.CodeActionNotAvailable,
+		}
+	}
+	return action, nil
+}
+
+func (f *mockFacade) ActionBegin(tag names.ActionTag) error {
+	f.begun = append(f.begun, tag.Id())
+	return nil
+}
+
+func (f *mockFacade) ActionFinish(tag names.ActionTag, status string, results map[string]interface{}, message string) error {
+	f.finished <- finishedAction{tag, status, results, message}
+	return nil
+}
+
+type mockLock struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (l *mockLock) Lock(message string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.log = append(l.log, "Lock "+message)
+	return nil
+}
+
+func (l *mockLock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.log = append(l.log, "Unlock")
+	return nil
+}
+
+func (l *mockLock) calls() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.log...)
+}
+
+type mockStringsWatcher struct {
+	changes chan []string
+}
+
+func newMockStringsWatcher() *mockStringsWatcher {
+	return &mockStringsWatcher{changes: make(chan []string, 1)}
+}
+
+func (w *mockStringsWatcher) Changes() <-chan []string {
+	return w.changes
+}
+
+func (w *mockStringsWatcher) Stop() error {
+	return nil
+}
+
+func (w *mockStringsWatcher) Err() error {
+	return nil
+}
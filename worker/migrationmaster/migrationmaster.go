@@ -0,0 +1,397 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package migrationmaster implements the worker that carries out the
+// migration of an environment to another system. The migration is
+// driven through its phases: the environment's model is exported and
+// imported into the target system, the environment's agents are
+// redirected to the target, and the migration completes once they have
+// all connected to it, when the environment is activated in the target
+// so that its workers run there. If anything fails before then, the
+// migration is
+// aborted: any agents that were redirected are sent back to this
+// system, and once they have returned the environment imported into
+// the target is removed.
+package migrationmaster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/api/migrationmaster"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.migrationmaster")
+
+// The phases of a migration, as reported by the MigrationMaster facade.
+const (
+	phaseExport     = "EXPORT"
+	phaseImport     = "IMPORT"
+	phaseRedirect   = "REDIRECT"
+	phaseValidation = "VALIDATION"
+	phaseSuccess    = "SUCCESS"
+	phaseDone       = "DONE"
+	phaseAbort      = "ABORT"
+	phaseAborted    = "ABORTED"
+)
+
+var (
+	// validationTimeout is how long the environment's agents are given
+	// to connect to the target system before the migration is aborted.
+	validationTimeout = 15 * time.Minute
+
+	// validationPollInterval is how often the target system is asked
+	// whether the environment's agents have connected, and how often
+	// this system is asked whether they have returned to it after the
+	// migration is aborted.
+	validationPollInterval = 5 * time.Second
+
+	// returnTimeout is how long the environment's agents are given to
+	// return to this system after the migration is aborted, before the
+	// environment imported into the target system is left in place.
+	returnTimeout = 15 * time.Minute
+)
+
+// Facade exposes the functionality of the MigrationMaster API facade
+// used by the worker.
+type Facade interface {
+	Watch() (watcher.NotifyWatcher, error)
+	GetMigrationStatus() (migrationmaster.MigrationStatus, error)
+	SetPhase(phase string) error
+	SetStatusMessage(message string) error
+	Export() (params.SerializedEnvironment, error)
+	SourceInfo() (migrationmaster.SourceInfo, error)
+	MissingAgents() ([]string, error)
+}
+
+// Target represents a connection to the system that an environment is
+// being migrated to.
+type Target interface {
+	// Import recreates the exported environment in the target system.
+	Import(env params.SerializedEnvironment) error
+
+	// MissingAgents returns the tags of the agents of the migrated
+	// environment that have not connected to the target system.
+	MissingAgents(envUUID string) ([]string, error)
+
+	// Activate starts the workers of the migrated environment in the
+	// target system, once the migration has succeeded.
+	Activate(envUUID string) error
+
+	// ReturnAgents sends any agents of the migrated environment that
+	// have connected to the target system back to the system with the
+	// given API addresses and CA certificate.
+	ReturnAgents(envUUID string, addrs []string, caCert string) error
+
+	// RemoveEnvironment removes the environment imported into the
+	// target system, without touching its provider resources.
+	RemoveEnvironment(envUUID string) error
+
+	// Close closes the connection to the target system.
+	Close() error
+}
+
+// OpenTargetFunc connects to the target system of a migration.
+type OpenTargetFunc func(info migrationmaster.TargetInfo) (Target, error)
+
+// migrationMaster is a worker.NotifyWatchHandler that advances the
+// environment's migration whenever it changes.
+type migrationMaster struct {
+	facade     Facade
+	envUUID    string
+	openTarget OpenTargetFunc
+	exported   *params.SerializedEnvironment
+
+	// targetInfo holds the details used to connect to the target
+	// system. The migration only records the target password until
+	// the environment has been imported, so the worker keeps its own
+	// copy for the later phases.
+	targetInfo *migrationmaster.TargetInfo
+}
+
+// New returns a worker that carries out the migration of the
+// environment with the given UUID, when one is initiated.
+func New(facade Facade, envUUID string, openTarget OpenTargetFunc) worker.Worker {
+	return worker.NewNotifyWorker(&migrationMaster{
+		facade:     facade,
+		envUUID:    envUUID,
+		openTarget: openTarget,
+	})
+}
+
+// SetUp is part of the worker.NotifyWatchHandler interface.
+func (m *migrationMaster) SetUp() (watcher.NotifyWatcher, error) {
+	return m.facade.Watch()
+}
+
+// TearDown is part of the worker.NotifyWatchHandler interface.
+func (m *migrationMaster) TearDown() error {
+	return nil
+}
+
+// Handle is part of the worker.NotifyWatchHandler interface. Each
+// phase moves the migration on to the next one, which in turn triggers
+// another call to Handle.
+func (m *migrationMaster) Handle(dying <-chan struct{}) error {
+	status, err := m.facade.GetMigrationStatus()
+	if params.IsCodeNotFound(err) {
+		// No migration has been initiated.
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot get migration status")
+	}
+	logger.Debugf("environment %s migration phase is %s", m.envUUID, status.Phase)
+	if status.TargetInfo.Password != "" {
+		info := status.TargetInfo
+		m.targetInfo = &info
+	}
+
+	switch status.Phase {
+	case phaseExport:
+		err = m.export()
+	case phaseImport:
+		err = m.importEnvironment()
+	case phaseRedirect:
+		// The agents learn of the target system's addresses and CA
+		// certificate through their API address updaters, now that
+		// the phase has changed.
+		return m.setPhase(phaseValidation, "waiting for agents to connect to the target system")
+	case phaseValidation:
+		err = m.validate(dying)
+	case phaseSuccess:
+		return m.activate()
+	case phaseAbort:
+		err = m.abort(dying, status)
+		if err == errDying {
+			return nil
+		}
+		return err
+	default:
+		// The migration is finished.
+		m.finish()
+		return nil
+	}
+	if err == errDying {
+		return nil
+	}
+	if err != nil {
+		logger.Errorf("environment %s migration failed: %v", m.envUUID, err)
+		return m.setPhase(phaseAbort, fmt.Sprintf("aborting: %v", err))
+	}
+	return nil
+}
+
+var errDying = errors.New("worker is dying")
+
+// errNoTargetInfo is returned when the worker has been restarted since
+// the environment was imported, and so no longer knows the password
+// for the target system.
+var errNoTargetInfo = errors.New("target system credentials are no longer available")
+
+// finish discards the state kept for the migration.
+func (m *migrationMaster) finish() {
+	m.exported = nil
+	m.targetInfo = nil
+}
+
+func (m *migrationMaster) setPhase(phase, message string) error {
+	if err := m.facade.SetStatusMessage(message); err != nil {
+		return errors.Annotate(err, "cannot set migration status message")
+	}
+	if err := m.facade.SetPhase(phase); err != nil {
+		return errors.Annotatef(err, "cannot move migration to %s", phase)
+	}
+	return nil
+}
+
+// export exports the environment and moves the migration on to the
+// import phase.
+func (m *migrationMaster) export() error {
+	if err := m.facade.SetStatusMessage("exporting environment"); err != nil {
+		return errors.Annotate(err, "cannot set migration status message")
+	}
+	exported, err := m.facade.Export()
+	if err != nil {
+		return errors.Annotate(err, "cannot export environment")
+	}
+	m.exported = &exported
+	return m.setPhase(phaseImport, "importing environment into the target system")
+}
+
+// importEnvironment imports the exported environment into the target
+// system and moves the migration on to the redirect phase.
+func (m *migrationMaster) importEnvironment() error {
+	if m.targetInfo == nil {
+		return errNoTargetInfo
+	}
+	if m.exported == nil {
+		// The worker was restarted since the environment was
+		// exported, so export it again.
+		exported, err := m.facade.Export()
+		if err != nil {
+			return errors.Annotate(err, "cannot export environment")
+		}
+		m.exported = &exported
+	}
+	target, err := m.openTarget(*m.targetInfo)
+	if err != nil {
+		return errors.Annotate(err, "cannot connect to target system")
+	}
+	defer target.Close()
+	if err := target.Import(*m.exported); err != nil {
+		return errors.Annotate(err, "cannot import environment into target system")
+	}
+	return m.setPhase(phaseRedirect, "redirecting agents to the target system")
+}
+
+// validate waits for all the environment's agents to connect to the
+// target system, and moves the migration on to the success phase.
+func (m *migrationMaster) validate(dying <-chan struct{}) error {
+	if m.targetInfo == nil {
+		return errNoTargetInfo
+	}
+	target, err := m.openTarget(*m.targetInfo)
+	if err != nil {
+		return errors.Annotate(err, "cannot connect to target system")
+	}
+	defer target.Close()
+
+	timeout := time.After(validationTimeout)
+	for {
+		missing, err := target.MissingAgents(m.envUUID)
+		if err != nil {
+			return errors.Annotate(err, "cannot check agents on target system")
+		}
+		if len(missing) == 0 {
+			return m.setPhase(phaseSuccess, "all agents connected to the target system")
+		}
+		select {
+		case <-dying:
+			return errDying
+		case <-timeout:
+			return errors.Errorf(
+				"agents did not connect to the target system within %v: %s",
+				validationTimeout, strings.Join(missing, ", "),
+			)
+		case <-time.After(validationPollInterval):
+		}
+	}
+}
+
+// activate activates the environment imported into the target system,
+// so that its workers run there, and finishes the migration. The
+// migration cannot be aborted once all the agents have connected to the
+// target system, so if the environment cannot be activated it finishes
+// regardless, saying so.
+func (m *migrationMaster) activate() error {
+	message := "migration complete"
+	if err := m.activateTarget(); err != nil {
+		logger.Warningf("cannot activate environment %s on target system: %v", m.envUUID, err)
+		message += fmt.Sprintf(
+			"; cannot activate the environment on the target system: %v; "+
+				"it must be activated manually", err,
+		)
+	}
+	m.finish()
+	return m.setPhase(phaseDone, message)
+}
+
+func (m *migrationMaster) activateTarget() error {
+	if m.targetInfo == nil {
+		return errNoTargetInfo
+	}
+	target, err := m.openTarget(*m.targetInfo)
+	if err != nil {
+		return errors.Annotate(err, "cannot connect to target system")
+	}
+	defer target.Close()
+	return errors.Trace(target.Activate(m.envUUID))
+}
+
+// abort sends any of the environment's agents that were redirected to
+// the target system back to this one, waits for them to return, and
+// then removes the environment imported into the target system, if
+// any, and finishes the migration. If the agents do not return, the
+// imported environment is left in place so that they can still reach
+// the target system.
+func (m *migrationMaster) abort(dying <-chan struct{}, status migrationmaster.MigrationStatus) error {
+	message := status.StatusMessage
+	if message == "" {
+		message = "migration aborted"
+	}
+	if m.targetInfo == nil {
+		logger.Warningf("cannot remove environment %s from target system: %v", m.envUUID, errNoTargetInfo)
+		m.finish()
+		message += "; the environment may need to be removed from the target system manually"
+		return m.setPhase(phaseAborted, message)
+	}
+	target, err := m.openTarget(*m.targetInfo)
+	if err != nil {
+		return errors.Annotate(err, "cannot connect to target system")
+	}
+	defer target.Close()
+
+	source, err := m.facade.SourceInfo()
+	if err != nil {
+		return errors.Annotate(err, "cannot get source system details")
+	}
+	// If the environment was never imported, no agents can have
+	// connected to the target system.
+	var missing []string
+	err = target.ReturnAgents(m.envUUID, source.Addrs, source.CACert)
+	if err != nil && !params.IsCodeNotFound(err) {
+		return errors.Annotate(err, "cannot return agents from target system")
+	} else if err == nil {
+		if missing, err = m.waitForReturn(dying); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(missing) > 0 {
+		logger.Warningf("agents of environment %s did not return from target system: %s",
+			m.envUUID, strings.Join(missing, ", "))
+		m.finish()
+		message += fmt.Sprintf(
+			"; agents did not return from the target system within %v: %s; "+
+				"the environment must be removed from the target system manually",
+			returnTimeout, strings.Join(missing, ", "),
+		)
+		return m.setPhase(phaseAborted, message)
+	}
+	err = target.RemoveEnvironment(m.envUUID)
+	if err != nil && !params.IsCodeNotFound(err) {
+		return errors.Annotate(err, "cannot remove environment from target system")
+	}
+	m.finish()
+	return m.setPhase(phaseAborted, message)
+}
+
+// waitForReturn waits for all the environment's agents to connect to
+// this system, and returns the tags of those that have not done so
+// within returnTimeout.
+func (m *migrationMaster) waitForReturn(dying <-chan struct{}) ([]string, error) {
+	logger.Infof("waiting for agents of environment %s to return from target system", m.envUUID)
+	timeout := time.After(returnTimeout)
+	for {
+		missing, err := m.facade.MissingAgents()
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot check agents on source system")
+		}
+		if len(missing) == 0 {
+			return nil, nil
+		}
+		select {
+		case <-dying:
+			return nil, errDying
+		case <-timeout:
+			return missing, nil
+		case <-time.After(validationPollInterval):
+		}
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/migrationmaster"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	workermigrationmaster "github.com/juju/juju/worker/migrationmaster"
+)
+
+const envUUID = "deadbeef-0bad-400d-8000-4b1d0d06f00d"
+
+type MigrationMasterSuite struct {
+	coretesting.BaseSuite
+	facade *fakeFacade
+	target *fakeTarget
+}
+
+var _ = gc.Suite(&MigrationMasterSuite{})
+
+func (s *MigrationMasterSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.facade = newFakeFacade("EXPORT")
+	s.target = &fakeTarget{}
+	s.PatchValue(workermigrationmaster.ValidationPollInterval, time.Millisecond)
+}
+
+func (s *MigrationMasterSuite) openTarget(info migrationmaster.TargetInfo) (workermigrationmaster.Target, error) {
+	s.target.mu.Lock()
+	defer s.target.mu.Unlock()
+	s.target.info = info
+	s.target.log = s.facade.log
+	return s.target, nil
+}
+
+func (s *MigrationMasterSuite) runUntil(c *gc.C, phase string) {
+	w := workermigrationmaster.New(s.facade, envUUID, s.openTarget)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+	select {
+	case <-s.facade.reached(phase):
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for phase %s", phase)
+	}
+}
+
+func (s *MigrationMasterSuite) TestNoMigration(c *gc.C) {
+	s.facade = newFakeFacade("")
+	w := workermigrationmaster.New(s.facade, envUUID, s.openTarget)
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	c.Assert(s.facade.history(), gc.HasLen, 0)
+	c.Assert(s.target.imported, gc.IsNil)
+}
+
+func (s *MigrationMasterSuite) TestSuccessfulMigration(c *gc.C) {
+	s.runUntil(c, "DONE")
+	c.Assert(s.facade.history(), jc.DeepEquals, []string{
+		"IMPORT", "REDIRECT", "VALIDATION", "SUCCESS", "DONE",
+	})
+	c.Assert(s.target.info.AuthTag, gc.Equals, names.NewUserTag("admin"))
+	c.Assert(s.target.info.Password, gc.Equals, "secret")
+	c.Assert(s.target.imported, gc.NotNil)
+	c.Assert(string(s.target.imported.Model), gc.Equals, "model")
+	c.Assert(s.target.removed, jc.IsFalse)
+	c.Assert(s.target.activated, jc.IsTrue)
+	c.Assert(s.facade.message(), gc.Equals, "migration complete")
+}
+
+func (s *MigrationMasterSuite) TestActivateFailureCompletes(c *gc.C) {
+	s.target.activateErr = errors.New("boom")
+	s.runUntil(c, "DONE")
+	c.Assert(s.facade.history(), jc.DeepEquals, []string{
+		"IMPORT", "REDIRECT", "VALIDATION", "SUCCESS", "DONE",
+	})
+	c.Assert(s.facade.message(), gc.Equals,
+		"migration complete; cannot activate the environment on the target system: boom; "+
+			"it must be activated manually")
+	c.Assert(s.target.activated, jc.IsFalse)
+}
+
+func (s *MigrationMasterSuite) TestImportFailureAborts(c *gc.C) {
+	s.target.importErr = errors.New("boom")
+	s.runUntil(c, "ABORTED")
+	c.Assert(s.facade.history(), jc.DeepEquals, []string{"IMPORT", "ABORT", "ABORTED"})
+	c.Assert(s.facade.message(), gc.Equals, "aborting: cannot import environment into target system: boom")
+	c.Assert(s.target.removed, jc.IsTrue)
+}
+
+func (s *MigrationMasterSuite) TestValidationTimeoutAborts(c *gc.C) {
+	s.PatchValue(workermigrationmaster.ValidationTimeout, 10*time.Millisecond)
+	s.target.missing = []string{"machine-1", "unit-mysql-0"}
+	s.runUntil(c, "ABORTED")
+	c.Assert(s.facade.history(), jc.DeepEquals, []string{
+		"IMPORT", "REDIRECT", "VALIDATION", "ABORT", "ABORTED",
+	})
+	c.Assert(s.facade.message(), gc.Matches,
+		"aborting: agents did not connect to the target system within .*: machine-1, unit-mysql-0")
+	c.Assert(s.target.removed, jc.IsTrue)
+}
+
+func (s *MigrationMasterSuite) TestValidationAfterRestartAborts(c *gc.C) {
+	// The target password is discarded once the environment has been
+	// imported, so a restarted worker cannot validate the migration.
+	s.facade = newFakeFacade("VALIDATION")
+	s.runUntil(c, "ABORTED")
+	c.Assert(s.facade.history(), jc.DeepEquals, []string{"ABORT", "ABORTED"})
+	c.Assert(s.facade.message(), gc.Equals,
+		"aborting: target system credentials are no longer available; "+
+			"the environment may need to be removed from the target system manually")
+	c.Assert(s.target.removed, jc.IsFalse)
+}
+
+func (s *MigrationMasterSuite) TestAbortTargetNotFound(c *gc.C) {
+	s.target.importErr = errors.New("boom")
+	s.target.removeErr = &params.Error{Code: params.CodeNotFound, Message: "not found"}
+	s.runUntil(c, "ABORTED")
+	c.Assert(s.facade.message(), gc.Equals, "aborting: cannot import environment into target system: boom")
+	c.Assert(s.target.removed, jc.IsTrue)
+}
+
+func (s *MigrationMasterSuite) TestAbortFromValidationReturnsAgents(c *gc.C) {
+	s.PatchValue(workermigrationmaster.ValidationTimeout, 10*time.Millisecond)
+	s.target.missing = []string{"machine-1"}
+	s.facade.sourceMissing = []string{"machine-1"}
+	s.facade.returnPolls = 2
+	s.runUntil(c, "ABORTED")
+	c.Assert(s.facade.history(), jc.DeepEquals, []string{
+		"IMPORT", "REDIRECT", "VALIDATION", "ABORT", "ABORTED",
+	})
+	c.Assert(s.facade.message(), gc.Matches,
+		"aborting: agents did not connect to the target system within .*: machine-1")
+
+	// The agents are sent back to this system, and the environment is
+	// only removed from the target once they have all returned.
+	c.Assert(s.target.returnedAddrs, jc.DeepEquals, []string{"10.0.0.5:17070"})
+	c.Assert(s.target.returnedCACert, gc.Equals, "source-cert")
+	c.Assert(s.facade.log.get(), jc.DeepEquals, []string{
+		"ReturnAgents",
+		"MissingAgents", "MissingAgents", "MissingAgents",
+		"RemoveEnvironment",
+	})
+	c.Assert(s.target.removed, jc.IsTrue)
+}
+
+func (s *MigrationMasterSuite) TestAbortAgentsDoNotReturn(c *gc.C) {
+	s.PatchValue(workermigrationmaster.ValidationTimeout, 10*time.Millisecond)
+	s.PatchValue(workermigrationmaster.ReturnTimeout, 10*time.Millisecond)
+	s.target.missing = []string{"machine-1"}
+	s.facade.sourceMissing = []string{"machine-1"}
+	s.facade.returnPolls = -1
+	s.runUntil(c, "ABORTED")
+	c.Assert(s.facade.message(), gc.Matches,
+		"aborting: agents did not connect to the target system within .*: machine-1; "+
+			"agents did not return from the target system within .*: machine-1; "+
+			"the environment must be removed from the target system manually")
+	c.Assert(s.target.returnedAddrs, gc.NotNil)
+	c.Assert(s.target.removed, jc.IsFalse)
+}
+
+// callLog records the calls made by the worker to the facade and the
+// target, so that their order can be checked.
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *callLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+type fakeFacade struct {
+	mu       sync.Mutex
+	phase    string
+	msg      string
+	phases   []string
+	changes  chan struct{}
+	waiters  map[string]chan struct{}
+	watchers []*fakeWatcher
+	log      *callLog
+
+	// sourceMissing holds the agents reported as not connected to
+	// the source system for the first returnPolls calls to
+	// MissingAgents, or for all of them if returnPolls is negative.
+	sourceMissing []string
+	returnPolls   int
+}
+
+func newFakeFacade(phase string) *fakeFacade {
+	return &fakeFacade{
+		phase:   phase,
+		waiters: make(map[string]chan struct{}),
+		log:     &callLog{},
+	}
+}
+
+func (f *fakeFacade) reached(phase string) <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch, ok := f.waiters[phase]
+	if !ok {
+		ch = make(chan struct{})
+		f.waiters[phase] = ch
+		if f.phase == phase {
+			close(ch)
+		}
+	}
+	return ch
+}
+
+func (f *fakeFacade) history() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.phases...)
+}
+
+func (f *fakeFacade) message() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.msg
+}
+
+func (f *fakeFacade) Watch() (watcher.NotifyWatcher, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := newFakeWatcher()
+	f.watchers = append(f.watchers, w)
+	return w, nil
+}
+
+func (f *fakeFacade) GetMigrationStatus() (migrationmaster.MigrationStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.phase == "" {
+		return migrationmaster.MigrationStatus{}, &params.Error{
+			Code:    params.CodeNotFound,
+			Message: "migration not found",
+		}
+	}
+	status := migrationmaster.MigrationStatus{
+		Phase:         f.phase,
+		StatusMessage: f.msg,
+		TargetInfo: migrationmaster.TargetInfo{
+			Addrs:   []string{"10.0.0.1:17070"},
+			CACert:  coretesting.CACert,
+			AuthTag: names.NewUserTag("admin"),
+		},
+	}
+	// Like state, only reveal the password until the environment has
+	// been imported.
+	if f.phase == "EXPORT" || f.phase == "IMPORT" {
+		status.TargetInfo.Password = "secret"
+	}
+	return status, nil
+}
+
+func (f *fakeFacade) SetPhase(phase string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.phase = phase
+	f.phases = append(f.phases, phase)
+	if ch, ok := f.waiters[phase]; ok {
+		close(ch)
+	}
+	for _, w := range f.watchers {
+		w.change()
+	}
+	return nil
+}
+
+func (f *fakeFacade) SetStatusMessage(message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.msg = message
+	return nil
+}
+
+func (f *fakeFacade) Export() (params.SerializedEnvironment, error) {
+	return params.SerializedEnvironment{Model: []byte("model")}, nil
+}
+
+func (f *fakeFacade) SourceInfo() (migrationmaster.SourceInfo, error) {
+	return migrationmaster.SourceInfo{
+		Addrs:  []string{"10.0.0.5:17070"},
+		CACert: "source-cert",
+	}, nil
+}
+
+func (f *fakeFacade) MissingAgents() ([]string, error) {
+	f.log.add("MissingAgents")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.returnPolls == 0 {
+		return nil, nil
+	}
+	if f.returnPolls > 0 {
+		f.returnPolls--
+	}
+	return f.sourceMissing, nil
+}
+
+type fakeTarget struct {
+	mu          sync.Mutex
+	info        migrationmaster.TargetInfo
+	imported    *params.SerializedEnvironment
+	importErr   error
+	missing     []string
+	removed     bool
+	removeErr   error
+	activated   bool
+	activateErr error
+	log         *callLog
+
+	returnedAddrs  []string
+	returnedCACert string
+}
+
+func (t *fakeTarget) Import(env params.SerializedEnvironment) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.importErr != nil {
+		return t.importErr
+	}
+	t.imported = &env
+	return nil
+}
+
+func (t *fakeTarget) MissingAgents(uuid string) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.missing, nil
+}
+
+func (t *fakeTarget) Activate(uuid string) error {
+	t.log.add("Activate")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.activateErr != nil {
+		return t.activateErr
+	}
+	t.activated = true
+	return nil
+}
+
+func (t *fakeTarget) ReturnAgents(uuid string, addrs []string, caCert string) error {
+	t.log.add("ReturnAgents")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.imported == nil {
+		return &params.Error{Code: params.CodeNotFound, Message: "not found"}
+	}
+	t.returnedAddrs = addrs
+	t.returnedCACert = caCert
+	return nil
+}
+
+func (t *fakeTarget) RemoveEnvironment(uuid string) error {
+	t.log.add("RemoveEnvironment")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removed = true
+	return t.removeErr
+}
+
+func (t *fakeTarget) Close() error {
+	return nil
+}
+
+type fakeWatcher struct {
+	watcher.NotifyWatcher
+	changes chan struct{}
+}
+
+func newFakeWatcher() *fakeWatcher {
+	w := &fakeWatcher{changes: make(chan struct{}, 1)}
+	w.change()
+	return w
+}
+
+func (w *fakeWatcher) change() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (w *fakeWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *fakeWatcher) Stop() error {
+	return nil
+}
+
+func (w *fakeWatcher) Err() error {
+	return nil
+}
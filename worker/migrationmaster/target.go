@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/environmentmanager"
+	"github.com/juju/juju/api/migrationmaster"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/description"
+)
+
+var apiOpen = api.Open
+
+// OpenAPITarget connects to the target system of a migration using
+// the API.
+func OpenAPITarget(info migrationmaster.TargetInfo) (Target, error) {
+	target := &apiTarget{info: info}
+	conn, err := target.open(names.EnvironTag{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	target.conn = conn
+	return target, nil
+}
+
+// apiTarget implements Target using an API connection to the target
+// system.
+type apiTarget struct {
+	info migrationmaster.TargetInfo
+	conn *api.State
+}
+
+func (t *apiTarget) open(envTag names.EnvironTag) (*api.State, error) {
+	return apiOpen(&api.Info{
+		Addrs:      t.info.Addrs,
+		CACert:     t.info.CACert,
+		Tag:        t.info.AuthTag,
+		Password:   t.info.Password,
+		EnvironTag: envTag,
+	}, api.DefaultDialOpts())
+}
+
+// Import is part of the Target interface.
+func (t *apiTarget) Import(env params.SerializedEnvironment) error {
+	model, err := description.Deserialize(env.Model)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	client := environmentmanager.NewClient(t.conn)
+	_, err = client.MigrateEnvironment(model.Owner, nil, nil, env)
+	return errors.Trace(err)
+}
+
+// MissingAgents is part of the Target interface.
+func (t *apiTarget) MissingAgents(envUUID string) ([]string, error) {
+	client := environmentmanager.NewClient(t.conn)
+	missing, err := client.MissingAgents(envUUID)
+	return missing, errors.Trace(err)
+}
+
+// Activate is part of the Target interface.
+func (t *apiTarget) Activate(envUUID string) error {
+	client := environmentmanager.NewClient(t.conn)
+	return errors.Trace(client.ActivateImportedEnvironment(envUUID))
+}
+
+// ReturnAgents is part of the Target interface.
+func (t *apiTarget) ReturnAgents(envUUID string, addrs []string, caCert string) error {
+	client := environmentmanager.NewClient(t.conn)
+	return errors.Trace(client.ReturnImportedAgents(envUUID, addrs, caCert))
+}
+
+// RemoveEnvironment is part of the Target interface.
+func (t *apiTarget) RemoveEnvironment(envUUID string) error {
+	client := environmentmanager.NewClient(t.conn)
+	return errors.Trace(client.RemoveImportedEnvironment(envUUID))
+}
+
+// Close is part of the Target interface.
+func (t *apiTarget) Close() error {
+	return t.conn.Close()
+}
@@ -44,8 +44,11 @@ var (
 	MaybeOverrideDefaultLXCNet = maybeOverrideDefaultLXCNet
 	EtcDefaultLXCNetPath       = &etcDefaultLXCNetPath
 	EtcDefaultLXCNet           = etcDefaultLXCNet
+	UpdateInstanceTags         = updateInstanceTags
 )
 
+var ConstructStartInstanceParams = constructStartInstanceParams
+
 const (
 	IPForwardSysctlKey = ipForwardSysctlKey
 	ARPProxySysctlKey  = arpProxySysctlKey
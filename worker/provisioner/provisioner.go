@@ -4,6 +4,7 @@
 package provisioner
 
 import (
+	"reflect"
 	"sync"
 
 	"github.com/juju/errors"
@@ -17,6 +18,7 @@ import (
 	"github.com/juju/juju/environmentserver/authentication"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/tags"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state/watcher"
 	"github.com/juju/juju/utils"
@@ -43,6 +45,10 @@ type environProvisioner struct {
 	provisioner
 	environ environs.Environ
 	configObserver
+
+	// resourceTags holds the resource tags last applied to the
+	// environment's instances.
+	resourceTags map[string]string
 }
 
 // containerProvisioner represents a running provisioning worker for containers
@@ -189,6 +195,22 @@ func (p *environProvisioner) loop() error {
 		return utils.LoggedErrorStack(errors.Trace(err))
 	}
 	p.broker = p.environ
+	p.resourceTags = resourceTags(p.environ.Config())
+
+	// Updating the tags of every instance can take a long time, so it
+	// is done in the background, applying only the latest tags.
+	tagUpdates := make(chan map[string]string, 1)
+	stopTagging := make(chan struct{})
+	var tagging sync.WaitGroup
+	tagging.Add(1)
+	go func() {
+		defer tagging.Done()
+		p.tagLoop(tagUpdates, stopTagging)
+	}()
+	defer func() {
+		close(stopTagging)
+		tagging.Wait()
+	}()
 
 	harvestMode := p.environ.Config().ProvisionerHarvestMode()
 	task, err := p.getStartTask(harvestMode)
@@ -216,6 +238,14 @@ func (p *environProvisioner) loop() error {
 			}
 			if err := p.setConfig(environConfig); err != nil {
 				logger.Errorf("loaded invalid environment configuration: %v", err)
+			} else if newTags := resourceTags(environConfig); !reflect.DeepEqual(newTags, p.resourceTags) {
+				// Replace any update that has not started yet.
+				select {
+				case <-tagUpdates:
+				default:
+				}
+				tagUpdates <- newTags
+				p.resourceTags = newTags
 			}
 			task.SetHarvestMode(environConfig.ProvisionerHarvestMode())
 		}
@@ -240,6 +270,57 @@ func (p *environProvisioner) setConfig(environConfig *config.Config) error {
 	return nil
 }
 
+// resourceTags returns the resource tags that the given environment
+// configuration specifies for instances.
+func resourceTags(cfg *config.Config) map[string]string {
+	uuid, _ := cfg.UUID()
+	return tags.ResourceTags(names.NewEnvironTag(uuid), cfg)
+}
+
+// tagLoop applies the resource tags received on updates to the
+// environment's instances, until stop is closed.
+func (p *environProvisioner) tagLoop(updates <-chan map[string]string, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case tags := <-updates:
+			updateInstanceTags(p.environ, tags, stop)
+		}
+	}
+}
+
+// updateInstanceTags applies the given resource tags to all of the
+// environment's instances, and the volumes attached to them, if the
+// environment supports instance tagging. Tags that have been removed
+// from the configuration are left in place. Failures are logged rather
+// than returned, as they should not stop the provisioner. If abort is
+// closed, no more instances are tagged.
+func updateInstanceTags(env environs.Environ, tags map[string]string, abort <-chan struct{}) {
+	tagger, ok := env.(environs.InstanceTagger)
+	if !ok {
+		logger.Debugf("environment type %q does not support instance tagging", env.Config().Type())
+		return
+	}
+	insts, err := env.AllInstances()
+	if err != nil {
+		logger.Errorf("cannot list instances to update their tags: %v", err)
+		return
+	}
+	logger.Infof("updating tags of %d instances: %v", len(insts), tags)
+	for _, inst := range insts {
+		select {
+		case <-abort:
+			logger.Debugf("aborted updating instance tags")
+			return
+		default:
+		}
+		if err := tagger.TagInstance(inst.Id(), tags); err != nil {
+			logger.Errorf("cannot update tags of instance %v: %v", inst.Id(), err)
+		}
+	}
+}
+
 // NewContainerProvisioner returns a new Provisioner. When new machines
 // are added to the state, it allocates instances from the environment
 // and allocates them to the new machines.
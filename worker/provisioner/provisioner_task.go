@@ -6,6 +6,7 @@ package provisioner
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
@@ -562,6 +563,20 @@ func constructStartInstanceParams(
 		}
 	}
 
+	// Existing volumes can only be attached to instances in their
+	// own zone, so all of them must be in the same one.
+	var availabilityZone string
+	switch len(provisioningInfo.VolumeZones) {
+	case 0:
+	case 1:
+		availabilityZone = provisioningInfo.VolumeZones[0]
+	default:
+		return environs.StartInstanceParams{}, errors.Errorf(
+			"volumes to be attached are in different availability zones: %s",
+			strings.Join(provisioningInfo.VolumeZones, ", "),
+		)
+	}
+
 	return environs.StartInstanceParams{
 		Constraints:       provisioningInfo.Constraints,
 		Tools:             possibleTools,
@@ -569,6 +584,7 @@ func constructStartInstanceParams(
 		Placement:         provisioningInfo.Placement,
 		DistributionGroup: machine.DistributionGroup,
 		Volumes:           volumes,
+		AvailabilityZone:  availabilityZone,
 	}, nil
 }
 
@@ -760,6 +776,7 @@ func volumesToApiserver(volumes []storage.Volume) []params.Volume {
 				v.HardwareId,
 				v.Size,
 				v.Persistent,
+				v.Zone,
 			},
 		}
 	}
@@ -771,6 +788,7 @@ func volumeAttachmentsToApiserver(attachments []storage.VolumeAttachment) map[st
 	for _, a := range attachments {
 		result[a.Volume.String()] = params.VolumeAttachmentInfo{
 			a.DeviceName,
+			a.BusAddress,
 			a.ReadOnly,
 		}
 	}
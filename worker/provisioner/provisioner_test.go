@@ -1573,3 +1573,90 @@ func (f mockToolsFinder) FindTools(number version.Number, series string, arch *s
 	}
 	return coretools.List{&coretools.Tools{Version: v}}, nil
 }
+
+type InstanceTagsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&InstanceTagsSuite{})
+
+func (s *InstanceTagsSuite) TestUpdateInstanceTags(c *gc.C) {
+	env := &mockTaggingEnviron{
+		insts:  []instance.Instance{mockInstance{id: "inst-0"}, mockInstance{id: "inst-1"}},
+		failed: "inst-0",
+	}
+	tags := map[string]string{"cost-centre": "1234"}
+	provisioner.UpdateInstanceTags(env, tags, nil)
+
+	// A failure to tag one instance does not stop the others being tagged.
+	c.Check(env.tagged, jc.DeepEquals, map[instance.Id]map[string]string{
+		"inst-1": tags,
+	})
+}
+
+func (s *InstanceTagsSuite) TestUpdateInstanceTagsAllInstancesFails(c *gc.C) {
+	env := &mockTaggingEnviron{err: errors.New("boom")}
+	provisioner.UpdateInstanceTags(env, map[string]string{"cost-centre": "1234"}, nil)
+	c.Check(env.tagged, gc.HasLen, 0)
+}
+
+func (s *InstanceTagsSuite) TestUpdateInstanceTagsAborted(c *gc.C) {
+	env := &mockTaggingEnviron{
+		insts: []instance.Instance{mockInstance{id: "inst-0"}},
+	}
+	abort := make(chan struct{})
+	close(abort)
+	provisioner.UpdateInstanceTags(env, map[string]string{"cost-centre": "1234"}, abort)
+	c.Check(env.tagged, gc.HasLen, 0)
+}
+
+type mockTaggingEnviron struct {
+	environs.Environ
+	insts  []instance.Instance
+	err    error
+	failed instance.Id
+	tagged map[instance.Id]map[string]string
+}
+
+func (e *mockTaggingEnviron) AllInstances() ([]instance.Instance, error) {
+	return e.insts, e.err
+}
+
+func (e *mockTaggingEnviron) TagInstance(id instance.Id, tags map[string]string) error {
+	if id == e.failed {
+		return errors.New("boom")
+	}
+	if e.tagged == nil {
+		e.tagged = make(map[instance.Id]map[string]string)
+	}
+	e.tagged[id] = tags
+	return nil
+}
+
+type mockInstance struct {
+	instance.Instance
+	id instance.Id
+}
+
+func (i mockInstance) Id() instance.Id {
+	return i.id
+}
+
+type StartInstanceParamsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&StartInstanceParamsSuite{})
+
+func (s *StartInstanceParamsSuite) TestVolumeZone(c *gc.C) {
+	pInfo := &params.ProvisioningInfo{VolumeZones: []string{"az-1"}}
+	args, err := provisioner.ConstructStartInstanceParams(nil, nil, pInfo, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(args.AvailabilityZone, gc.Equals, "az-1")
+}
+
+func (s *StartInstanceParamsSuite) TestVolumeZonesConflict(c *gc.C) {
+	pInfo := &params.ProvisioningInfo{VolumeZones: []string{"az-1", "az-2"}}
+	_, err := provisioner.ConstructStartInstanceParams(nil, nil, pInfo, nil)
+	c.Assert(err, gc.ErrorMatches, "volumes to be attached are in different availability zones: az-1, az-2")
+}
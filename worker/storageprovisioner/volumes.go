@@ -637,6 +637,7 @@ func volumesFromStorage(in []storage.Volume) []params.Volume {
 				v.HardwareId,
 				v.Size,
 				v.Persistent,
+				v.Zone,
 			},
 		}
 	}
@@ -651,6 +652,7 @@ func volumeAttachmentsFromStorage(in []storage.VolumeAttachment) []params.Volume
 			v.Machine.String(),
 			params.VolumeAttachmentInfo{
 				v.DeviceName,
+				v.BusAddress,
 				v.ReadOnly,
 			},
 		}
@@ -670,6 +672,7 @@ func volumeFromParams(in params.Volume) (storage.Volume, error) {
 			in.Info.HardwareId,
 			in.Info.Size,
 			in.Info.Persistent,
+			in.Info.Zone,
 		},
 	}, nil
 }
@@ -688,6 +691,7 @@ func volumeAttachmentFromParams(in params.VolumeAttachment) (storage.VolumeAttac
 		machineTag,
 		storage.VolumeAttachmentInfo{
 			in.Info.DeviceName,
+			in.Info.BusAddress,
 			in.Info.ReadOnly,
 		},
 	}, nil
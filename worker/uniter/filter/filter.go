@@ -50,6 +50,8 @@ type filter struct {
 	outMeterStatusOn    chan struct{}
 	outStorage          chan []names.StorageTag
 	outStorageOn        chan []names.StorageTag
+	outResources        chan int
+	outResourcesOn      chan int
 	// The want* chans are used to indicate that the filter should send
 	// events if it has them available.
 	wantForcedUpgrade  chan bool
@@ -88,6 +90,16 @@ type filter struct {
 	// flag.
 	didClearResolved chan struct{}
 
+	// setResources is used to request that the version of the service's
+	// resources that the unit has reacted to be recorded. This is done
+	// on the filter's goroutine so that the recorded version is used
+	// for all subsequent resources events.
+	setResources chan int
+
+	// didSetResources is used to report back after recording a
+	// resources version.
+	didSetResources chan struct{}
+
 	// The following fields hold state that is collected while running,
 	// and used to detect interesting changes to express as events.
 	unit             *uniter.Unit
@@ -102,6 +114,11 @@ type filter struct {
 	actionsPending   []string
 	nextAction       string
 
+	// resourcesFrom holds the version of the service's resources that
+	// the unit last reacted to, and resourcesAvailable the current one.
+	resourcesFrom      int
+	resourcesAvailable int
+
 	// meterStatusCode and meterStatusInfo reflect the meter status values of the unit.
 	meterStatusCode string
 	meterStatusInfo string
@@ -121,6 +138,7 @@ func NewFilter(st *uniter.State, unitTag names.UnitTag) (Filter, error) {
 		outRelationsOn:        make(chan []int),
 		outMeterStatusOn:      make(chan struct{}),
 		outStorageOn:          make(chan []names.StorageTag),
+		outResourcesOn:        make(chan int),
 		wantForcedUpgrade:     make(chan bool),
 		wantResolved:          make(chan struct{}),
 		wantLeaderSettings:    make(chan bool),
@@ -130,6 +148,8 @@ func NewFilter(st *uniter.State, unitTag names.UnitTag) (Filter, error) {
 		didSetCharm:           make(chan struct{}),
 		clearResolved:         make(chan struct{}),
 		didClearResolved:      make(chan struct{}),
+		setResources:          make(chan int),
+		didSetResources:       make(chan struct{}),
 	}
 	go func() {
 		defer f.tomb.Done()
@@ -207,6 +227,13 @@ func (f *filter) StorageEvents() <-chan []names.StorageTag {
 	return f.outStorageOn
 }
 
+// ResourcesEvents returns a channel that will receive the version of
+// the service's resources whenever it is newer than the version the
+// unit last reacted to.
+func (f *filter) ResourcesEvents() <-chan int {
+	return f.outResourcesOn
+}
+
 // WantUpgradeEvent controls whether the filter will generate upgrade
 // events for unforced service charm changes.
 func (f *filter) WantUpgradeEvent(mustForce bool) {
@@ -244,6 +271,25 @@ func (f *filter) SetCharm(curl *charm.URL) error {
 	}
 }
 
+// SetResourcesVersion notifies the filter that the unit has reacted to
+// the given version of the service's resources. It causes the version
+// to be recorded in state, and resources events will only be generated
+// for later versions. SetResourcesVersion blocks until the version is
+// recorded, returning any error that occurred.
+func (f *filter) SetResourcesVersion(version int) error {
+	select {
+	case <-f.tomb.Dying():
+		return tomb.ErrDying
+	case f.setResources <- version:
+	}
+	select {
+	case <-f.tomb.Dying():
+		return tomb.ErrDying
+	case <-f.didSetResources:
+		return nil
+	}
+}
+
 // WantResolvedEvent indicates that the filter should send a resolved event
 // if one is available.
 func (f *filter) WantResolvedEvent() {
@@ -364,6 +410,7 @@ func (f *filter) loop(unitTag names.UnitTag) (err error) {
 		}
 		configChanges = configw.Changes()
 		f.upgradeFrom.url = curl
+		f.resourcesChanged()
 	} else if err != uniter.ErrNoCharmURLSet {
 		filterLogger.Errorf("unit charm: %v", err)
 		return err
@@ -551,6 +598,9 @@ func (f *filter) loop(unitTag names.UnitTag) (err error) {
 			filterLogger.Debugf("sent storage event")
 			f.outStorage = nil
 			f.storage = nil
+		case f.outResources <- f.resourcesAvailable:
+			filterLogger.Debugf("sent resources event")
+			f.outResources = nil
 
 		// Handle explicit requests.
 		case curl := <-f.setCharm:
@@ -591,6 +641,19 @@ func (f *filter) loop(unitTag names.UnitTag) (err error) {
 			if err = f.upgradeChanged(); err != nil {
 				return err
 			}
+			f.resourcesChanged()
+		case version := <-f.setResources:
+			filterLogger.Debugf("setting resources version to %d", version)
+			if err := f.unit.SetResourcesVersion(version); err != nil {
+				return err
+			}
+			f.resourcesFrom = version
+			select {
+			case <-f.tomb.Dying():
+				return tomb.ErrDying
+			case f.didSetResources <- nothing:
+			}
+			f.resourcesChanged()
 		case force := <-f.wantForcedUpgrade:
 			filterLogger.Debugf("want forced upgrade %v", force)
 			f.upgradeFrom.force = force
@@ -679,6 +742,15 @@ func (f *filter) unitChanged() error {
 			f.outResolved = f.outResolvedOn
 		}
 	}
+	version, err := f.unit.ResourcesVersion()
+	if errors.IsNotImplemented(err) {
+		// The state server predates charm resources.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	f.resourcesFrom = version
+	f.resourcesChanged()
 	return nil
 }
 
@@ -701,6 +773,12 @@ func (f *filter) serviceChanged() error {
 		filterLogger.Infof("service is dead")
 		return worker.ErrTerminateAgent
 	}
+	version, err := f.service.ResourcesVersion()
+	if err != nil && !errors.IsNotImplemented(err) {
+		return err
+	}
+	f.resourcesAvailable = version
+	f.resourcesChanged()
 	return f.upgradeChanged()
 }
 
@@ -733,6 +811,27 @@ func (f *filter) upgradeChanged() (err error) {
 	return nil
 }
 
+// resourcesChanged responds to changes in the version of the service's
+// resources, or in the version the unit last reacted to.
+func (f *filter) resourcesChanged() {
+	if f.life != params.Alive {
+		filterLogger.Debugf("resources check skipped, unit is dying")
+		f.outResources = nil
+		return
+	}
+	if f.upgradeFrom.url == nil {
+		filterLogger.Debugf("resources check skipped, not yet installed.")
+		f.outResources = nil
+		return
+	}
+	if f.resourcesAvailable > f.resourcesFrom {
+		filterLogger.Debugf("preparing new resources event")
+		f.outResources = f.outResourcesOn
+		return
+	}
+	f.outResources = nil
+}
+
 // relationsChanged responds to service relation changes.
 func (f *filter) relationsChanged(changed []int) {
 	ids := set.NewInts(f.relations...)
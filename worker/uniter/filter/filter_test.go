@@ -5,6 +5,7 @@ package filter_test
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/juju/names"
@@ -290,6 +291,41 @@ func (s *FilterSuite) TestCharmUpgradeEvents(c *gc.C) {
 	upgradeC.AssertOneValue(newCharm.URL())
 }
 
+func (s *FilterSuite) TestResourcesEvents(c *gc.C) {
+	f, err := filter.NewFilter(s.uniter, s.unit.Tag().(names.UnitTag))
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertStop(c, f)
+
+	setResource := func(content string) {
+		_, err := s.State.SetResource(
+			"wordpress", "site", strings.NewReader(content), int64(len(content)), "sha-"+content, s.AdminUserTag(c),
+		)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	// No events are sent before the charm is installed.
+	resourcesC := s.contentAsserterC(c, f.ResourcesEvents())
+	setResource("hello")
+	resourcesC.AssertNoReceive()
+
+	// Once the charm is set, the pending change is delivered.
+	err = f.SetCharm(s.wpcharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	resourcesC.AssertOneValue(1)
+
+	// Recording the version silences the event...
+	err = f.SetResourcesVersion(1)
+	c.Assert(err, jc.ErrorIsNil)
+	resourcesC.AssertNoReceive()
+	err = s.unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unit.ResourcesVersion(), gc.Equals, 1)
+
+	// ...until the resources change again.
+	setResource("goodbye")
+	resourcesC.AssertOneValue(2)
+}
+
 func (s *FilterSuite) TestConfigEvents(c *gc.C) {
 	f, err := filter.NewFilter(s.uniter, s.unit.Tag().(names.UnitTag))
 	c.Assert(err, jc.ErrorIsNil)
@@ -61,6 +61,11 @@ type Filter interface {
 	// associated storage instances whose Life status has changed.
 	StorageEvents() <-chan []names.StorageTag
 
+	// ResourcesEvents returns a channel that will receive the version of
+	// the service's resources whenever it is newer than the version the
+	// unit last reacted to.
+	ResourcesEvents() <-chan int
+
 	// WantUpgradeEvent controls whether the filter will generate upgrade
 	// events for unforced service charm changes.
 	WantUpgradeEvent(mustForce bool)
@@ -81,6 +86,13 @@ type Filter interface {
 	// error that occurred.
 	SetCharm(curl *charm.URL) error
 
+	// SetResourcesVersion notifies the filter that the unit has reacted to
+	// the given version of the service's resources. Resources events will
+	// only be generated for later versions. SetResourcesVersion blocks
+	// until the version is recorded in state, returning any error that
+	// occurred.
+	SetResourcesVersion(version int) error
+
 	// WantResolvedEvent indicates that the filter should send a resolved event
 	// if one is available.
 	WantResolvedEvent()
@@ -325,6 +325,15 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 			return modeAbideDyingLoop(u)
 		case curl := <-u.f.UpgradeEvents():
 			return ModeUpgrading(curl), nil
+		case version := <-u.f.ResourcesEvents():
+			// The new resources are recorded as seen before the hook
+			// runs; should the hook fail, it will be retried through
+			// the usual error resolution. Running upgrade-charm queues
+			// config-changed, so let ModeContinue pick up after it.
+			if err := u.f.SetResourcesVersion(version); err != nil {
+				return nil, errors.Trace(err)
+			}
+			return continueAfter(u, newSimpleRunHookOp(hooks.UpgradeCharm))
 		case ids := <-u.f.RelationsEvents():
 			creator = newUpdateRelationsOp(ids)
 		case actionId := <-u.f.ActionEvents():
@@ -53,6 +53,11 @@ func (paths Paths) GetMetricsSpoolDir() string {
 	return paths.State.MetricsSpoolDir
 }
 
+// GetResourcesDir exists to satisfy the runner.Paths interface.
+func (paths Paths) GetResourcesDir() string {
+	return paths.State.ResourcesDir
+}
+
 // RuntimePaths represents the set of paths that are relevant at runtime.
 type RuntimePaths struct {
 
@@ -94,6 +99,9 @@ type StatePaths struct {
 	// MetricsSpoolDir acts as temporary storage for metrics being sent from
 	// the uniter to state.
 	MetricsSpoolDir string
+
+	// ResourcesDir holds the charm resources fetched by resource-get.
+	ResourcesDir string
 }
 
 // NewPaths returns the set of filesystem paths that the supplied unit should
@@ -130,6 +138,7 @@ func NewPaths(dataDir string, unitTag names.UnitTag) Paths {
 			DeployerDir:     join(stateDir, "deployer"),
 			StorageDir:      join(stateDir, "storage"),
 			MetricsSpoolDir: join(stateDir, "spool", "metrics"),
+			ResourcesDir:    join(baseDir, "resources"),
 		},
 	}
 }
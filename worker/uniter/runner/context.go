@@ -166,6 +166,13 @@ type HookContext struct {
 	// This collection will be added to the unit on successful
 	// hook run, so the actual add will happen in a flush.
 	storageAddConstraints map[string][]params.StorageConstraints
+
+	// charmDir is the directory in which the unit's charm is deployed.
+	charmDir string
+
+	// resourcesDir is the directory in which charm resources fetched
+	// by resource-get are stored.
+	resourcesDir string
 }
 
 func (ctx *HookContext) RequestReboot(priority jujuc.RebootPriority) error {
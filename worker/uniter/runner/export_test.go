@@ -134,6 +134,8 @@ func NewHookContext(
 		actionData:         actionData,
 		pendingPorts:       make(map[PortRange]PortRangeInfo),
 		assignedMachineTag: assignedMachineTag,
+		charmDir:           paths.GetCharmDir(),
+		resourcesDir:       paths.GetResourcesDir(),
 	}
 	if canAddMetrics {
 		charmURL, err := unit.CharmURL()
@@ -15,6 +15,7 @@ import (
 
 	"github.com/juju/juju/api/uniter"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/worker/leadership"
 	"github.com/juju/juju/worker/uniter/hook"
 	"github.com/juju/juju/worker/uniter/metrics"
@@ -244,13 +245,19 @@ func (f *factory) NewActionRunner(actionId string) (Runner, error) {
 	}
 
 	name := action.Name()
-	spec, ok := ch.Actions().ActionSpecs[name]
-	if !ok {
-		return nil, &badActionError{name, "not defined"}
-	}
 	params := action.Params()
-	if err := spec.ValidateParams(params); err != nil {
-		return nil, &badActionError{name, err.Error()}
+	if name == jujurun.ActionName {
+		if _, _, err := jujurun.ParseParams(params); err != nil {
+			return nil, &badActionError{name, err.Error()}
+		}
+	} else {
+		spec, ok := ch.Actions().ActionSpecs[name]
+		if !ok {
+			return nil, &badActionError{name, "not defined"}
+		}
+		if err := spec.ValidateParams(params); err != nil {
+			return nil, &badActionError{name, err.Error()}
+		}
 	}
 
 	ctx, err := f.coreContext()
@@ -290,6 +297,8 @@ func (f *factory) coreContext() (*HookContext, error) {
 		definedMetrics:     nil,
 		pendingPorts:       make(map[PortRange]PortRangeInfo),
 		storage:            f.storage,
+		charmDir:           f.paths.GetCharmDir(),
+		resourcesDir:       f.paths.GetResourcesDir(),
 	}
 	if err := f.updateContext(ctx); err != nil {
 		return nil, err
@@ -18,6 +18,7 @@ import (
 	"gopkg.in/juju/charm.v5/hooks"
 
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/storage"
 	"github.com/juju/juju/testcharms"
@@ -583,6 +584,31 @@ func (s *FactorySuite) TestNewActionRunnerGood(c *gc.C) {
 	c.Assert(combined, gc.Matches, `(^|.*\|)JUJU_ACTION_TAG=`+action.Tag().String()+`(\|.*|$)`)
 }
 
+func (s *FactorySuite) TestNewActionRunnerJujuRun(c *gc.C) {
+	s.SetCharm(c, "dummy")
+	action, err := s.unit.AddAction(jujurun.ActionName, jujurun.ActionParams("hostname", time.Minute))
+	c.Assert(err, jc.ErrorIsNil)
+	rnr, err := s.factory.NewActionRunner(action.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := rnr.Context().ActionData()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data.ActionName, gc.Equals, jujurun.ActionName)
+	commands, timeout, err := jujurun.ParseParams(data.ActionParams)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(commands, gc.Equals, "hostname")
+	c.Assert(timeout, gc.Equals, time.Minute)
+}
+
+func (s *FactorySuite) TestNewActionRunnerJujuRunBadParams(c *gc.C) {
+	s.SetCharm(c, "dummy")
+	action, err := s.State.EnqueueAction(s.unit.Tag(), jujurun.ActionName, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	rnr, err := s.factory.NewActionRunner(action.Id())
+	c.Check(rnr, gc.IsNil)
+	c.Check(err, gc.ErrorMatches, `cannot run "juju-run" action: juju-run action without commands not valid`)
+	c.Check(err, jc.Satisfies, runner.IsBadActionError)
+}
+
 func (s *FactorySuite) TestNewActionRunnerBadCharm(c *gc.C) {
 	rnr, err := s.factory.NewActionRunner("irrelevant")
 	c.Assert(rnr, gc.IsNil)
@@ -50,6 +50,7 @@ type HookContext interface {
 	ContextLeadership
 	ContextMetrics
 	ContextStorage
+	ContextResources
 	ContextRelations
 }
 
@@ -196,6 +197,15 @@ type ContextStorage interface {
 	AddUnitStorage(map[string]params.StorageConstraints)
 }
 
+// ContextResources is the part of a hook context related to the charm
+// resources attached to the unit's service.
+type ContextResources interface {
+	// ResourcePath fetches the current content of the named resource,
+	// if it is not already held locally, and returns the path to the
+	// local copy.
+	ResourcePath(name string) (string, error)
+}
+
 // ContextRelations exposes the relations associated with the unit.
 type ContextRelations interface {
 	// Relation returns the relation with the supplied id if it was found, and
@@ -0,0 +1,64 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// ResourceGetCommand implements the resource-get command.
+type ResourceGetCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	Name string
+	out  cmd.Output
+}
+
+// NewResourceGetCommand returns a new ResourceGetCommand with the given
+// context.
+func NewResourceGetCommand(ctx Context) cmd.Command {
+	return &ResourceGetCommand{ctx: ctx}
+}
+
+// Info is part of the cmd.Command interface.
+func (c *ResourceGetCommand) Info() *cmd.Info {
+	doc := `
+resource-get fetches the current content of a resource declared by the
+charm, if it has not already been fetched, and prints the path to the
+local copy. Resources are attached to a service with "juju attach".
+`
+	return &cmd.Info{
+		Name:    "resource-get",
+		Args:    "<name>",
+		Purpose: "print the path to a charm resource",
+		Doc:     doc,
+	}
+}
+
+// SetFlags is part of the cmd.Command interface.
+func (c *ResourceGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+// Init is part of the cmd.Command interface.
+func (c *ResourceGetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no resource name specified")
+	}
+	c.Name = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+// Run is part of the cmd.Command interface.
+func (c *ResourceGetCommand) Run(ctx *cmd.Context) error {
+	path, err := c.ctx.ResourcePath(c.Name)
+	if err != nil {
+		return errors.Annotatef(err, "cannot get resource %q", c.Name)
+	}
+	return c.out.Write(ctx, path)
+}
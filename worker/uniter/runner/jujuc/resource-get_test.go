@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type ResourceGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&ResourceGetSuite{})
+
+func (s *ResourceGetSuite) createCommand(c *gc.C) (cmd.Command, *Context) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.info.SetResourcePath("site", "/path/to/resources/site/site.tgz")
+	com, err := jujuc.NewCommand(hctx, cmdString("resource-get"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com, hctx
+}
+
+func (s *ResourceGetSuite) TestResourceGet(c *gc.C) {
+	com, _ := s.createCommand(c)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"site"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, "/path/to/resources/site/site.tgz\n")
+	s.Stub.CheckCallNames(c, "ResourcePath")
+}
+
+func (s *ResourceGetSuite) TestResourceGetUnknown(c *gc.C) {
+	com, _ := s.createCommand(c)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"tools"})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), gc.Equals, "error: cannot get resource \"tools\": resource \"tools\" not found\n")
+}
+
+func (s *ResourceGetSuite) TestInitErrors(c *gc.C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{nil, "no resource name specified"},
+		{[]string{"site", "extra"}, `unrecognized args: \["extra"\]`},
+	} {
+		com, _ := s.createCommand(c)
+		err := testing.InitCommand(com, t.args)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *ResourceGetSuite) TestHelp(c *gc.C) {
+	com, _ := s.createCommand(c)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"--help"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(bufferString(ctx.Stdout), gc.Equals, `usage: resource-get [options] <name>
+purpose: print the path to a charm resource
+
+options:
+--format  (= smart)
+    specify output format (json|smart|yaml)
+-o, --output (= "")
+    specify an output file
+
+resource-get fetches the current content of a resource declared by the
+charm, if it has not already been fetched, and prints the path to the
+local copy. Resources are attached to a service with "juju attach".
+`)
+}
@@ -52,6 +52,7 @@ var baseCommands = map[string]creator{
 	"juju-reboot" + cmdSuffix:   NewJujuRebootCommand,
 	"status-get" + cmdSuffix:    NewStatusGetCommand,
 	"status-set" + cmdSuffix:    NewStatusSetCommand,
+	"resource-get" + cmdSuffix:  NewResourceGetCommand,
 }
 
 var storageCommands = map[string]creator{
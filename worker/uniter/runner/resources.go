@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/resource"
+)
+
+// ResourcePath is part of the jujuc.ContextResources interface. The
+// resource is stored under the resources directory, in a directory
+// named after the resource and with the filename declared by the
+// charm. The content is only downloaded if it differs from that of
+// the local copy.
+func (ctx *HookContext) ResourcePath(name string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(ctx.charmDir, "metadata.yaml"))
+	if err != nil {
+		return "", errors.Annotate(err, "cannot read charm metadata")
+	}
+	declared, err := resource.ParseMeta(data)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	meta, ok := declared[name]
+	if !ok {
+		return "", errors.NotFoundf("resource %q in charm", name)
+	}
+
+	dir := filepath.Join(ctx.resourcesDir, name)
+	path := filepath.Join(dir, meta.Filename)
+	currentSHA256, _, err := utils.ReadFileSHA256(path)
+	if os.IsNotExist(err) {
+		currentSHA256 = ""
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	reader, expectedSHA256, err := ctx.state.DownloadResource(name, currentSHA256)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if reader == nil {
+		// The local copy is current.
+		return path, nil
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Trace(err)
+	}
+	tempFile, err := ioutil.TempFile(dir, ".download")
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer os.Remove(tempFile.Name())
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tempFile, hash), reader)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot download resource %q", name)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expectedSHA256 {
+		return "", errors.Errorf("resource %q has SHA256 %q, expected %q", name, actual, expectedSHA256)
+	}
+	if err := utils.ReplaceFile(tempFile.Name(), path); err != nil {
+		return "", errors.Trace(err)
+	}
+	logger.Infof("downloaded resource %q to %q", name, path)
+	return path, nil
+}
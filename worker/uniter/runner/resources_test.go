@@ -0,0 +1,122 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package runner_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/worker/uniter/runner"
+)
+
+type ResourcesSuite struct {
+	HookContextSuite
+	paths RealPaths
+}
+
+var _ = gc.Suite(&ResourcesSuite{})
+
+// sha256 of "hello"
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func (s *ResourcesSuite) SetUpTest(c *gc.C) {
+	s.HookContextSuite.SetUpTest(c)
+	s.paths = NewRealPaths(c)
+	metadata := `
+name: wordpress
+summary: "blog"
+description: "blog"
+resources:
+  site:
+    filename: site.tgz
+`
+	err := ioutil.WriteFile(filepath.Join(s.paths.GetCharmDir(), "metadata.yaml"), []byte(metadata), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ResourcesSuite) getContext(c *gc.C) *runner.HookContext {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, jc.ErrorIsNil)
+	ctx, err := runner.NewHookContext(s.apiUnit, s.uniter, "TestCtx", uuid.String(),
+		"test-env-name", -1, "", nil, apiAddrs, names.NewUserTag("owner"),
+		noProxies, false, nil, nil, s.machine.Tag().(names.MachineTag), s.paths)
+	c.Assert(err, jc.ErrorIsNil)
+	return ctx
+}
+
+func (s *ResourcesSuite) setResource(c *gc.C, content, sha256 string) {
+	_, err := s.State.SetResource(
+		"u", "site", strings.NewReader(content), int64(len(content)), sha256, s.AdminUserTag(c),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ResourcesSuite) assertContent(c *gc.C, path, content string) {
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, content)
+}
+
+func (s *ResourcesSuite) TestResourcePath(c *gc.C) {
+	s.setResource(c, "hello", helloSHA256)
+	ctx := s.getContext(c)
+
+	path, err := ctx.ResourcePath("site")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(path, gc.Equals, filepath.Join(s.paths.GetResourcesDir(), "site", "site.tgz"))
+	s.assertContent(c, path, "hello")
+
+	// Fetching again leaves the current copy alone.
+	info, err := os.Stat(path)
+	c.Assert(err, jc.ErrorIsNil)
+	path, err = ctx.ResourcePath("site")
+	c.Assert(err, jc.ErrorIsNil)
+	info2, err := os.Stat(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(os.SameFile(info, info2), jc.IsTrue)
+}
+
+func (s *ResourcesSuite) TestResourcePathReplaces(c *gc.C) {
+	s.setResource(c, "hello", helloSHA256)
+	ctx := s.getContext(c)
+	path, err := ctx.ResourcePath("site")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// sha256 of "goodbye"
+	s.setResource(c, "goodbye", "82e35a63ceba37e9646434c5dd412ea577147f1e4a41ccde1614253187e3dbf9")
+	path, err = ctx.ResourcePath("site")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertContent(c, path, "goodbye")
+}
+
+func (s *ResourcesSuite) TestResourcePathBadHash(c *gc.C) {
+	s.setResource(c, "hello", "bad")
+	ctx := s.getContext(c)
+	_, err := ctx.ResourcePath("site")
+	c.Assert(err, gc.ErrorMatches, `resource "site" has SHA256 "`+helloSHA256+`", expected "bad"`)
+	_, err = os.Stat(filepath.Join(s.paths.GetResourcesDir(), "site", "site.tgz"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *ResourcesSuite) TestResourcePathUndeclared(c *gc.C) {
+	ctx := s.getContext(c)
+	_, err := ctx.ResourcePath("tools")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `resource "tools" in charm not found`)
+}
+
+func (s *ResourcesSuite) TestResourcePathNotAttached(c *gc.C) {
+	ctx := s.getContext(c)
+	_, err := ctx.ResourcePath("site")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `resource "site" of service "u" not found`)
+}
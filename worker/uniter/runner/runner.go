@@ -14,6 +14,7 @@ import (
 	"github.com/juju/loggo"
 	utilexec "github.com/juju/utils/exec"
 
+	"github.com/juju/juju/jujurun"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker/uniter/runner/debug"
 	"github.com/juju/juju/worker/uniter/runner/jujuc"
@@ -66,6 +67,10 @@ type Paths interface {
 	// GetMetricsSpoolDir returns the path to a metrics spool dir, used
 	// to store metrics recorded during a single hook run.
 	GetMetricsSpoolDir() string
+
+	// GetResourcesDir returns the path to the directory in which charm
+	// resources are stored when fetched by hooks.
+	GetResourcesDir() string
 }
 
 // NewRunner returns a Runner backed by the supplied context and paths.
@@ -110,12 +115,46 @@ func (runner *runner) RunCommands(commands string) (*utilexec.ExecResponse, erro
 
 // RunAction exists to satisfy the Runner interface.
 func (runner *runner) RunAction(actionName string) error {
-	if _, err := runner.context.ActionData(); err != nil {
+	data, err := runner.context.ActionData()
+	if err != nil {
 		return errors.Trace(err)
 	}
+	if actionName == jujurun.ActionName {
+		return runner.runJujuRunAction(data)
+	}
 	return runner.runCharmHookWithLocation(actionName, "actions")
 }
 
+// runJujuRunAction runs the commands of a juju-run action in the hook
+// context, recording their output and exit code as the action results.
+func (runner *runner) runJujuRunAction(data *ActionData) error {
+	commands, timeout, err := jujurun.ParseParams(data.ActionParams)
+	if err != nil {
+		return runner.context.FlushContext(jujurun.ActionName, err)
+	}
+	srv, err := runner.startJujucServer()
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	command := utilexec.RunParams{
+		Commands:    commands,
+		WorkingDir:  runner.paths.GetCharmDir(),
+		Environment: runner.context.HookVars(runner.paths),
+	}
+	if err := command.Run(); err != nil {
+		return runner.context.FlushContext(jujurun.ActionName, err)
+	}
+	runner.context.SetProcess(command.Process())
+
+	response, err := jujurun.Wait(&command, timeout)
+	if response != nil {
+		data.ResultsMap = jujurun.ActionResults(response)
+	}
+	return runner.context.FlushContext(jujurun.ActionName, err)
+}
+
 // RunHook exists to satisfy the Runner interface.
 func (runner *runner) RunHook(hookName string) error {
 	return runner.runCharmHookWithLocation(hookName, "hooks")
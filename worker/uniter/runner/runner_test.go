@@ -18,6 +18,8 @@ import (
 	"github.com/juju/utils"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/jujurun"
+	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker/uniter/runner"
 )
 
@@ -293,3 +295,39 @@ func (s *RunMockContextSuite) TestRunCommandsFlushFailure(c *gc.C) {
 	c.Assert(ctx.flushFailure, gc.IsNil) // exit code in _ result, as tested elsewhere
 	s.assertRecordedPid(c, ctx.expectPid)
 }
+
+func (s *RunMockContextSuite) TestRunJujuRunAction(c *gc.C) {
+	ctx := &MockContext{
+		actionData: &runner.ActionData{
+			ActionName:   jujurun.ActionName,
+			ActionParams: jujurun.ActionParams(echoPidScript+"; echo hello; exit 3", coretesting.LongWait),
+			ResultsMap:   map[string]interface{}{},
+		},
+	}
+	err := runner.NewRunner(ctx, s.paths).RunAction(jujurun.ActionName)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.flushBadge, gc.Equals, jujurun.ActionName)
+	c.Assert(ctx.flushFailure, gc.IsNil)
+	s.assertRecordedPid(c, ctx.expectPid)
+
+	response, err := jujurun.ParseResults(ctx.actionData.ResultsMap)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(response.Code, gc.Equals, 3)
+	c.Assert(strings.TrimRight(string(response.Stdout), "\r\n"), gc.Equals, "hello")
+}
+
+func (s *RunMockContextSuite) TestRunJujuRunActionTimeout(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("exec is not available in powershell")
+	}
+	ctx := &MockContext{
+		actionData: &runner.ActionData{
+			ActionName:   jujurun.ActionName,
+			ActionParams: jujurun.ActionParams("echo hello; exec sleep 60", coretesting.ShortWait),
+			ResultsMap:   map[string]interface{}{},
+		},
+	}
+	err := runner.NewRunner(ctx, s.paths).RunAction(jujurun.ActionName)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.flushFailure, gc.Equals, jujurun.ErrTimedOut)
+}
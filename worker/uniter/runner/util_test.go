@@ -56,12 +56,17 @@ func (MockEnvPaths) GetMetricsSpoolDir() string {
 	return "path-to-metrics-spool-dir"
 }
 
+func (MockEnvPaths) GetResourcesDir() string {
+	return "path-to-resources"
+}
+
 // RealPaths implements Paths for tests that do touch the filesystem.
 type RealPaths struct {
 	tools        string
 	charm        string
 	socket       string
 	metricsspool string
+	resources    string
 }
 
 func osDependentSockPath(c *gc.C) string {
@@ -78,6 +83,7 @@ func NewRealPaths(c *gc.C) RealPaths {
 		charm:        c.MkDir(),
 		socket:       osDependentSockPath(c),
 		metricsspool: c.MkDir(),
+		resources:    c.MkDir(),
 	}
 }
 
@@ -97,6 +103,10 @@ func (p RealPaths) GetJujucSocket() string {
 	return p.socket
 }
 
+func (p RealPaths) GetResourcesDir() string {
+	return p.resources
+}
+
 // HookContextSuite contains shared setup for various other test suites. Test
 // methods should not be added to this type, because they'll get run repeatedly.
 type HookContextSuite struct {
@@ -0,0 +1,11 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package webhook
+
+var (
+	AgentPollInterval = &agentPollInterval
+	DeliveryBackoff   = &deliveryBackoff
+	EventQueueSize    = &eventQueueSize
+	Now               = &now
+)
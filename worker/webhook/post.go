@@ -0,0 +1,76 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+const (
+	// EventHeader is the HTTP header holding the type of a posted event.
+	EventHeader = "X-Juju-Event"
+
+	// SignatureHeader is the HTTP header holding the signature of a
+	// posted event, when a webhook secret is configured. It has the
+	// form "sha256=<hex>", where <hex> is the hex-encoded HMAC-SHA256
+	// of the request body, keyed with the secret.
+	SignatureHeader = "X-Juju-Signature"
+)
+
+// statusTooManyRequests is the HTTP status code with which a webhook
+// may ask for deliveries to be slowed down.
+const statusTooManyRequests = 429
+
+// postTimeout is the maximum time taken by a single delivery attempt.
+var postTimeout = 30 * time.Second
+
+// Sign returns the signature of the given body, as sent in the
+// SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postEvent posts the given event body to the given URL. If the
+// post fails, it reports whether it is worth trying again.
+func postEvent(url, secret, eventType string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+	// The default clients are shared, and events are posted to each
+	// webhook concurrently, so take a copy to set the timeout.
+	client := *utils.GetHTTPClient(utils.VerifySSLHostnames)
+	client.Timeout = postTimeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection may be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500, resp.StatusCode == statusTooManyRequests:
+		return true, errors.New(resp.Status)
+	}
+	return false, errors.New(resp.Status)
+}
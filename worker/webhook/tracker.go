@@ -0,0 +1,169 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package webhook
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+// The types of event posted to webhooks.
+const (
+	// UnitError is posted when a unit's workload status becomes error.
+	UnitError = "unit-error"
+
+	// MachineError is posted when a machine's status becomes error.
+	MachineError = "machine-error"
+
+	// MachineAgentLost is posted when the agent of a started machine
+	// stops reporting its presence.
+	MachineAgentLost = "machine-agent-lost"
+
+	// ActionFailed is posted when an action fails.
+	ActionFailed = "action-failed"
+)
+
+// Event is the JSON payload posted to webhooks.
+type Event struct {
+	Type        string    `json:"type"`
+	Environment string    `json:"environment"`
+	Entity      string    `json:"entity"`
+	Message     string    `json:"message,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// eventTracker turns the deltas reported by an allwatcher into
+// events. An event is generated when a condition (such as a unit
+// being in error) becomes true; it is not generated again until the
+// condition has become false and then true again.
+type eventTracker struct {
+	envUUID string
+
+	// seeded records whether the initial deltas have been seen.
+	// Conditions that are already true when the tracker starts
+	// do not generate events.
+	seeded bool
+
+	// active holds the conditions that are currently true, keyed
+	// by event type and entity tag.
+	active map[eventKey]bool
+
+	// started holds the ids of machines whose status is started,
+	// and whose agent presence is therefore polled. The value is
+	// false for machines that were started when the tracker was
+	// seeded, until their agent presence has first been polled;
+	// as with other conditions, agents that are already lost when
+	// the tracker starts do not generate events.
+	started map[string]bool
+}
+
+type eventKey struct {
+	eventType string
+	entity    string
+}
+
+func newEventTracker(envUUID string) *eventTracker {
+	return &eventTracker{
+		envUUID: envUUID,
+		active:  make(map[eventKey]bool),
+		started: make(map[string]bool),
+	}
+}
+
+// update processes the given deltas, and returns any events that
+// they give rise to.
+func (t *eventTracker) update(deltas []multiwatcher.Delta, now time.Time) []Event {
+	var events []Event
+	set := func(eventType, entity string, active bool, message string) {
+		if event, ok := t.set(eventType, entity, active, message, now); ok {
+			events = append(events, event)
+		}
+	}
+	for _, delta := range deltas {
+		switch info := delta.Entity.(type) {
+		case *multiwatcher.MachineInfo:
+			tag := names.NewMachineTag(info.Id).String()
+			set(MachineError, tag,
+				!delta.Removed && info.Status == multiwatcher.StatusError,
+				info.StatusInfo,
+			)
+			if delta.Removed || info.Status != multiwatcher.StatusStarted {
+				delete(t.started, info.Id)
+				t.set(MachineAgentLost, tag, false, "", now)
+			} else if _, ok := t.started[info.Id]; !ok {
+				t.started[info.Id] = t.seeded
+			}
+		case *multiwatcher.UnitInfo:
+			set(UnitError, names.NewUnitTag(info.Name).String(),
+				!delta.Removed && info.WorkloadStatus.Current == multiwatcher.StatusError,
+				info.WorkloadStatus.Message,
+			)
+		case *multiwatcher.ActionInfo:
+			set(ActionFailed, names.NewActionTag(info.Id).String(),
+				!delta.Removed && info.Status == string(state.ActionFailed),
+				info.Message,
+			)
+		}
+	}
+	t.seeded = true
+	return events
+}
+
+// pollAgents checks the presence of the agents of all started
+// machines, and returns an event for each agent that has been lost
+// since the last poll.
+func (t *eventTracker) pollAgents(alive func(machineId string) (bool, error), now time.Time) ([]Event, error) {
+	ids := make([]string, 0, len(t.started))
+	for id := range t.started {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var events []Event
+	for _, id := range ids {
+		isAlive, err := alive(id)
+		if err != nil {
+			return nil, err
+		}
+		event, ok := t.set(
+			MachineAgentLost, names.NewMachineTag(id).String(),
+			!isAlive, "machine agent is not running", now,
+		)
+		if ok && t.started[id] {
+			events = append(events, event)
+		}
+		t.started[id] = true
+	}
+	return events, nil
+}
+
+// set records whether the given condition is active. If it has just
+// become active, and the tracker has been seeded, it returns the
+// corresponding event and true.
+func (t *eventTracker) set(eventType, entity string, active bool, message string, now time.Time) (Event, bool) {
+	key := eventKey{eventType, entity}
+	if !active {
+		delete(t.active, key)
+		return Event{}, false
+	}
+	if t.active[key] {
+		return Event{}, false
+	}
+	t.active[key] = true
+	if !t.seeded {
+		return Event{}, false
+	}
+	return Event{
+		Type:        eventType,
+		Environment: t.envUUID,
+		Entity:      entity,
+		Message:     message,
+		Time:        now,
+	}, true
+}
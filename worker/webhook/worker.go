@@ -0,0 +1,345 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package webhook provides a worker that posts environment events,
+// such as units going into error, to the webhook URLs configured in
+// the environment's webhook-urls setting.
+package webhook
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.webhook")
+
+var (
+	// agentPollInterval is how often the presence of the agents of
+	// started machines is checked.
+	agentPollInterval = time.Minute
+
+	// deliveryBackoff holds the delays between successive attempts
+	// to deliver an event to a webhook. Once they are exhausted,
+	// the delivery is recorded as failed.
+	deliveryBackoff = []time.Duration{
+		time.Second,
+		5 * time.Second,
+		30 * time.Second,
+		2 * time.Minute,
+	}
+
+	// eventQueueSize is the number of events that may be waiting for
+	// delivery to each webhook. Further events for that webhook are
+	// dropped, and recorded as failed deliveries, until its queue
+	// drains.
+	eventQueueSize = 100
+
+	// now returns the current time.
+	now = time.Now
+)
+
+// AllWatcher reports changes to the entities in an environment.
+type AllWatcher interface {
+	Next() ([]multiwatcher.Delta, error)
+	Stop() error
+}
+
+// Backend defines the state methods used by the webhook worker.
+type Backend interface {
+	WatchAll() AllWatcher
+	EnvironConfig() (*config.Config, error)
+	EnvironUUID() string
+	MachineAgentAlive(machineId string) (bool, error)
+	AddWebhookDelivery(state.WebhookDelivery) error
+}
+
+// NewWorker returns a worker that posts the events of the environment
+// held in the given state to the environment's webhooks.
+func NewWorker(st *state.State) worker.Worker {
+	return New(stateBackend{st})
+}
+
+// New returns a worker that posts the events of the given backend's
+// environment to the environment's webhooks.
+func New(backend Backend) worker.Worker {
+	w := &webhookWorker{
+		backend: backend,
+		senders: make(map[string]*sender),
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+		w.sending.Wait()
+	}()
+	return w
+}
+
+type webhookWorker struct {
+	tomb    tomb.Tomb
+	backend Backend
+
+	// senders holds the sender for each configured webhook URL. It
+	// is only used by the loop goroutine.
+	senders map[string]*sender
+
+	// sending tracks the running senders.
+	sending sync.WaitGroup
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *webhookWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *webhookWorker) Wait() error {
+	return w.tomb.Wait()
+}
+
+// loop watches the environment, and queues the events arising from
+// its changes for delivery.
+func (w *webhookWorker) loop() error {
+	watcher := w.backend.WatchAll()
+	defer watcher.Stop()
+
+	// Next blocks, so it's called in its own goroutine. Stopping
+	// the watcher causes Next to return, ending the goroutine.
+	deltasc := make(chan []multiwatcher.Delta)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			deltas, err := watcher.Next()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case deltasc <- deltas:
+			case <-w.tomb.Dying():
+				return
+			}
+		}
+	}()
+
+	poll := time.NewTicker(agentPollInterval)
+	defer poll.Stop()
+	tracker := newEventTracker(w.backend.EnvironUUID())
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case err := <-errc:
+			return errors.Annotate(err, "cannot watch environment")
+		case deltas := <-deltasc:
+			if err := w.queue(tracker.update(deltas, now())); err != nil {
+				return errors.Trace(err)
+			}
+		case <-poll.C:
+			events, err := tracker.pollAgents(w.backend.MachineAgentAlive, now())
+			if err != nil {
+				return errors.Annotate(err, "cannot check machine agents")
+			}
+			if err := w.queue(events); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// queue queues the given events for delivery to each of the
+// configured webhooks that they are wanted by. Each webhook has its
+// own queue and sender, so that a slow webhook cannot hold up either
+// the watcher or the other webhooks; events that do not fit in a
+// webhook's queue are dropped, and recorded as failed deliveries.
+func (w *webhookWorker) queue(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	cfg, err := w.backend.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	urls := cfg.WebhookURLs()
+	w.updateSenders(urls)
+	secret, _ := cfg.WebhookSecret()
+	for _, event := range events {
+		if !wanted(cfg.WebhookEvents(), event.Type) {
+			continue
+		}
+		body, err := json.Marshal(event)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		queued := queuedEvent{event, secret, body}
+		for _, url := range urls {
+			select {
+			case w.senders[url].events <- queued:
+				continue
+			default:
+			}
+			logger.Warningf("webhook event queue for %q full; dropping %s event for %s", url, event.Type, event.Entity)
+			if err := w.backend.AddWebhookDelivery(state.WebhookDelivery{
+				Event:  event.Type,
+				Entity: event.Entity,
+				URL:    url,
+				Time:   event.Time,
+				Error:  "event queue full",
+			}); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+// updateSenders starts a sender for each of the given URLs that does
+// not have one, and stops the senders of URLs that are no longer
+// configured. Events still queued for those URLs are abandoned.
+func (w *webhookWorker) updateSenders(urls []string) {
+	configured := make(map[string]bool)
+	for _, url := range urls {
+		configured[url] = true
+		if w.senders[url] != nil {
+			continue
+		}
+		s := &sender{
+			worker:  w,
+			url:     url,
+			events:  make(chan queuedEvent, eventQueueSize),
+			removed: make(chan struct{}),
+		}
+		w.senders[url] = s
+		w.sending.Add(1)
+		go func() {
+			defer w.sending.Done()
+			if err := s.loop(); err != nil {
+				w.tomb.Kill(err)
+			}
+		}()
+	}
+	for url, s := range w.senders {
+		if !configured[url] {
+			close(s.removed)
+			delete(w.senders, url)
+		}
+	}
+}
+
+// queuedEvent holds an event waiting to be delivered to a webhook,
+// along with what is needed to post it.
+type queuedEvent struct {
+	event  Event
+	secret string
+	body   []byte
+}
+
+// sender delivers the events queued for a single webhook URL.
+type sender struct {
+	worker  *webhookWorker
+	url     string
+	events  chan queuedEvent
+	removed chan struct{}
+}
+
+// loop delivers queued events until the worker dies, or the URL is
+// removed from the configuration.
+func (s *sender) loop() error {
+	for {
+		select {
+		case <-s.worker.tomb.Dying():
+			return nil
+		case <-s.removed:
+			return nil
+		case queued := <-s.events:
+			if err := s.send(queued); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// send delivers the given event to the sender's webhook, and records
+// the outcome.
+func (s *sender) send(queued queuedEvent) error {
+	event := queued.event
+	attempts, err := s.worker.deliver(s.url, queued.secret, event.Type, queued.body)
+	delivery := state.WebhookDelivery{
+		Event:     event.Type,
+		Entity:    event.Entity,
+		URL:       s.url,
+		Time:      event.Time,
+		Attempts:  attempts,
+		Delivered: err == nil,
+	}
+	if err != nil {
+		logger.Warningf("cannot deliver %s event for %s to %q: %v", event.Type, event.Entity, s.url, err)
+		delivery.Error = err.Error()
+	}
+	return errors.Trace(s.worker.backend.AddWebhookDelivery(delivery))
+}
+
+// deliver posts the given event body to the given URL, trying again
+// after each of the deliveryBackoff delays while the failure is
+// temporary. It returns the number of attempts made, and the error
+// from the last attempt.
+func (w *webhookWorker) deliver(url, secret, eventType string, body []byte) (int, error) {
+	for attempts := 1; ; attempts++ {
+		retry, err := postEvent(url, secret, eventType, body)
+		if err == nil || !retry || attempts > len(deliveryBackoff) {
+			return attempts, err
+		}
+		logger.Debugf("delivery to %q failed (attempt %d): %v", url, attempts, err)
+		select {
+		case <-w.tomb.Dying():
+			return attempts, err
+		case <-time.After(deliveryBackoff[attempts-1]):
+		}
+	}
+}
+
+// wanted reports whether events of the given type should be posted,
+// given the configured webhook-events.
+func wanted(eventTypes []string, eventType string) bool {
+	if len(eventTypes) == 0 {
+		return true
+	}
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// stateBackend implements Backend in terms of *state.State.
+type stateBackend struct {
+	*state.State
+}
+
+// WatchAll is part of the Backend interface.
+func (b stateBackend) WatchAll() AllWatcher {
+	return b.State.Watch()
+}
+
+// MachineAgentAlive is part of the Backend interface. A machine that
+// no longer exists is reported as alive, as there's no point in
+// telling anyone that its agent has been lost.
+func (b stateBackend) MachineAgentAlive(machineId string) (bool, error) {
+	m, err := b.State.Machine(machineId)
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return m.AgentPresence()
+}
@@ -0,0 +1,385 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package webhook_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/multiwatcher"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/webhook"
+)
+
+type WorkerSuite struct {
+	coretesting.BaseSuite
+	backend  *mockBackend
+	server   *httptest.Server
+	requests chan request
+
+	// statuses holds the HTTP status codes returned by the server,
+	// in order; once exhausted, the server returns 200.
+	mu       sync.Mutex
+	statuses []int
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+var eventTime = time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(webhook.Now, func() time.Time { return eventTime })
+	s.PatchValue(webhook.DeliveryBackoff, []time.Duration{time.Millisecond, time.Millisecond})
+
+	s.statuses = nil
+	s.requests = make(chan request, 10)
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.AddCleanup(func(*gc.C) { s.server.Close() })
+
+	s.backend = &mockBackend{
+		deltas:     make(chan []multiwatcher.Delta),
+		stopped:    make(chan struct{}),
+		deliveries: make(chan state.WebhookDelivery, 10),
+		alive:      make(map[string]bool),
+	}
+	s.setConfig(c, coretesting.Attrs{})
+}
+
+func (s *WorkerSuite) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	s.requests <- request{r.Header, body}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.statuses) > 0 {
+		w.WriteHeader(s.statuses[0])
+		s.statuses = s.statuses[1:]
+	}
+}
+
+func (s *WorkerSuite) setConfig(c *gc.C, attrs coretesting.Attrs) {
+	s.backend.config = coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+		"webhook-urls":   s.server.URL,
+		"webhook-secret": "s3cret",
+	}.Merge(attrs))
+}
+
+func (s *WorkerSuite) startWorker(c *gc.C, initial ...multiwatcher.EntityInfo) worker.Worker {
+	w := webhook.New(s.backend)
+	s.AddCleanup(func(c *gc.C) { c.Check(worker.Stop(w), jc.ErrorIsNil) })
+	s.sendDeltas(c, initial...)
+	return w
+}
+
+func (s *WorkerSuite) sendDeltas(c *gc.C, infos ...multiwatcher.EntityInfo) {
+	deltas := make([]multiwatcher.Delta, len(infos))
+	for i, info := range infos {
+		deltas[i] = multiwatcher.Delta{Entity: info}
+	}
+	select {
+	case s.backend.deltas <- deltas:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out sending deltas")
+	}
+}
+
+func (s *WorkerSuite) assertRequest(c *gc.C) request {
+	select {
+	case req := <-s.requests:
+		return req
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for webhook request")
+	}
+	panic("unreachable")
+}
+
+func (s *WorkerSuite) assertNoRequest(c *gc.C) {
+	select {
+	case req := <-s.requests:
+		c.Fatalf("unexpected webhook request: %s", req.body)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *WorkerSuite) assertDelivery(c *gc.C) state.WebhookDelivery {
+	select {
+	case d := <-s.backend.deliveries:
+		return d
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for webhook delivery")
+	}
+	panic("unreachable")
+}
+
+func unitInfo(name string, status multiwatcher.Status, message string) *multiwatcher.UnitInfo {
+	return &multiwatcher.UnitInfo{
+		Name: name,
+		WorkloadStatus: multiwatcher.StatusInfo{
+			Current: status,
+			Message: message,
+		},
+	}
+}
+
+func (s *WorkerSuite) TestPostsNewErrors(c *gc.C) {
+	s.startWorker(c,
+		unitInfo("wordpress/0", multiwatcher.StatusError, "already broken"),
+		unitInfo("wordpress/1", multiwatcher.StatusActive, ""),
+	)
+	s.sendDeltas(c, unitInfo("wordpress/1", multiwatcher.StatusError, "hook failed"))
+
+	req := s.assertRequest(c)
+	c.Check(req.header.Get("Content-Type"), gc.Equals, "application/json")
+	c.Check(req.header.Get(webhook.EventHeader), gc.Equals, webhook.UnitError)
+	c.Check(req.header.Get(webhook.SignatureHeader), gc.Equals, webhook.Sign("s3cret", req.body))
+	var event webhook.Event
+	err := json.Unmarshal(req.body, &event)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(event, jc.DeepEquals, webhook.Event{
+		Type:        webhook.UnitError,
+		Environment: coretesting.EnvironmentTag.Id(),
+		Entity:      "unit-wordpress-1",
+		Message:     "hook failed",
+		Time:        eventTime,
+	})
+
+	c.Check(s.assertDelivery(c), jc.DeepEquals, state.WebhookDelivery{
+		Event:     webhook.UnitError,
+		Entity:    "unit-wordpress-1",
+		URL:       s.server.URL,
+		Time:      eventTime,
+		Attempts:  1,
+		Delivered: true,
+	})
+
+	// The same error is not posted twice.
+	s.sendDeltas(c, unitInfo("wordpress/1", multiwatcher.StatusError, "hook failed again"))
+	s.assertNoRequest(c)
+}
+
+func (s *WorkerSuite) TestNoSecret(c *gc.C) {
+	s.setConfig(c, coretesting.Attrs{"webhook-secret": ""})
+	s.startWorker(c)
+	s.sendDeltas(c, &multiwatcher.MachineInfo{Id: "0", Status: multiwatcher.StatusError})
+
+	req := s.assertRequest(c)
+	c.Check(req.header.Get(webhook.EventHeader), gc.Equals, webhook.MachineError)
+	c.Check(req.header.Get(webhook.SignatureHeader), gc.Equals, "")
+}
+
+func (s *WorkerSuite) TestEventFilter(c *gc.C) {
+	s.setConfig(c, coretesting.Attrs{"webhook-events": "action-failed"})
+	s.startWorker(c)
+	s.sendDeltas(c,
+		unitInfo("wordpress/0", multiwatcher.StatusError, ""),
+		&multiwatcher.ActionInfo{
+			Id:      "6ba7b810-9dad-41d1-80b4-00c04fd430c8",
+			Status:  "failed",
+			Message: "oops",
+		},
+	)
+
+	req := s.assertRequest(c)
+	c.Check(req.header.Get(webhook.EventHeader), gc.Equals, webhook.ActionFailed)
+	s.assertNoRequest(c)
+}
+
+func (s *WorkerSuite) TestRetry(c *gc.C) {
+	s.statuses = []int{http.StatusServiceUnavailable, 429}
+	s.startWorker(c)
+	s.sendDeltas(c, unitInfo("wordpress/0", multiwatcher.StatusError, ""))
+
+	for i := 0; i < 3; i++ {
+		s.assertRequest(c)
+	}
+	d := s.assertDelivery(c)
+	c.Check(d.Attempts, gc.Equals, 3)
+	c.Check(d.Delivered, jc.IsTrue)
+}
+
+func (s *WorkerSuite) TestRetriesExhausted(c *gc.C) {
+	s.statuses = []int{500, 500, 500}
+	s.startWorker(c)
+	s.sendDeltas(c, unitInfo("wordpress/0", multiwatcher.StatusError, ""))
+
+	d := s.assertDelivery(c)
+	c.Check(d.Attempts, gc.Equals, 3)
+	c.Check(d.Delivered, jc.IsFalse)
+	c.Check(d.Error, gc.Equals, "500 Internal Server Error")
+}
+
+func (s *WorkerSuite) TestNoRetryOnClientError(c *gc.C) {
+	s.statuses = []int{http.StatusBadRequest}
+	s.startWorker(c)
+	s.sendDeltas(c, unitInfo("wordpress/0", multiwatcher.StatusError, ""))
+
+	d := s.assertDelivery(c)
+	c.Check(d.Attempts, gc.Equals, 1)
+	c.Check(d.Delivered, jc.IsFalse)
+	c.Check(d.Error, gc.Equals, "400 Bad Request")
+}
+
+// startSlowServer starts a webhook server that blocks each request
+// until the returned release channel is closed. The arrival of each
+// request is signalled on the returned arrived channel.
+func (s *WorkerSuite) startSlowServer(c *gc.C) (server *httptest.Server, arrived <-chan struct{}, release chan struct{}) {
+	arrivedc := make(chan struct{}, 10)
+	release = make(chan struct{})
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrivedc <- struct{}{}
+		<-release
+	}))
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	return server, arrivedc, release
+}
+
+func (s *WorkerSuite) TestSlowWebhookDoesNotHoldUpOthers(c *gc.C) {
+	slow, _, release := s.startSlowServer(c)
+	defer close(release)
+	s.setConfig(c, coretesting.Attrs{"webhook-urls": slow.URL + "," + s.server.URL})
+	s.startWorker(c)
+	s.sendDeltas(c, unitInfo("wordpress/0", multiwatcher.StatusError, ""))
+
+	s.assertRequest(c)
+	d := s.assertDelivery(c)
+	c.Check(d.URL, gc.Equals, s.server.URL)
+	c.Check(d.Delivered, jc.IsTrue)
+}
+
+func (s *WorkerSuite) TestQueueFullRecordsFailedDelivery(c *gc.C) {
+	s.PatchValue(webhook.EventQueueSize, 1)
+	slow, arrived, release := s.startSlowServer(c)
+	defer close(release)
+	s.setConfig(c, coretesting.Attrs{"webhook-urls": slow.URL})
+	s.startWorker(c)
+
+	// Once the first event is being delivered, one more fits in the
+	// queue, and the next is dropped.
+	s.sendDeltas(c, unitInfo("wordpress/0", multiwatcher.StatusError, ""))
+	select {
+	case <-arrived:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for webhook request")
+	}
+	s.sendDeltas(c,
+		unitInfo("wordpress/1", multiwatcher.StatusError, ""),
+		unitInfo("wordpress/2", multiwatcher.StatusError, ""),
+	)
+	c.Check(s.assertDelivery(c), jc.DeepEquals, state.WebhookDelivery{
+		Event:  webhook.UnitError,
+		Entity: "unit-wordpress-2",
+		URL:    slow.URL,
+		Time:   eventTime,
+		Error:  "event queue full",
+	})
+}
+
+func (s *WorkerSuite) TestMachineAgentLost(c *gc.C) {
+	s.PatchValue(webhook.AgentPollInterval, 10*time.Millisecond)
+	s.backend.setAlive("0", false)
+	s.backend.setAlive("1", true)
+	s.startWorker(c,
+		&multiwatcher.MachineInfo{Id: "0", Status: multiwatcher.StatusStarted},
+		&multiwatcher.MachineInfo{Id: "1", Status: multiwatcher.StatusStarted},
+	)
+	// Agents already lost when the worker starts are not reported.
+	s.assertNoRequest(c)
+
+	s.backend.setAlive("1", false)
+	req := s.assertRequest(c)
+	c.Check(req.header.Get(webhook.EventHeader), gc.Equals, webhook.MachineAgentLost)
+	var event webhook.Event
+	err := json.Unmarshal(req.body, &event)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(event.Entity, gc.Equals, "machine-1")
+	s.assertNoRequest(c)
+}
+
+func (s *WorkerSuite) TestWatcherError(c *gc.C) {
+	w := webhook.New(s.backend)
+	s.backend.watchErr = errors.New("boom")
+	close(s.backend.stopped)
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot watch environment: boom")
+}
+
+type mockBackend struct {
+	config     *config.Config
+	deltas     chan []multiwatcher.Delta
+	stopped    chan struct{}
+	watchErr   error
+	deliveries chan state.WebhookDelivery
+
+	mu    sync.Mutex
+	alive map[string]bool
+}
+
+func (b *mockBackend) setAlive(machineId string, alive bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.alive[machineId] = alive
+}
+
+func (b *mockBackend) WatchAll() webhook.AllWatcher {
+	return &mockWatcher{b}
+}
+
+func (b *mockBackend) EnvironConfig() (*config.Config, error) {
+	return b.config, nil
+}
+
+func (b *mockBackend) EnvironUUID() string {
+	return coretesting.EnvironmentTag.Id()
+}
+
+func (b *mockBackend) MachineAgentAlive(machineId string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.alive[machineId], nil
+}
+
+func (b *mockBackend) AddWebhookDelivery(d state.WebhookDelivery) error {
+	b.deliveries <- d
+	return nil
+}
+
+type mockWatcher struct {
+	backend *mockBackend
+}
+
+func (w *mockWatcher) Next() ([]multiwatcher.Delta, error) {
+	select {
+	case deltas := <-w.backend.deltas:
+		return deltas, nil
+	case <-w.backend.stopped:
+		if w.backend.watchErr != nil {
+			return nil, w.backend.watchErr
+		}
+		return nil, state.ErrStopped
+	}
+}
+
+func (w *mockWatcher) Stop() error {
+	select {
+	case <-w.backend.stopped:
+	default:
+		close(w.backend.stopped)
+	}
+	return nil
+}